@@ -21,6 +21,7 @@ import (
 	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/server"
 	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/service"
 	"github.com/telhawk-systems/telhawk-stack/common/config"
+	"github.com/telhawk-systems/telhawk-stack/common/instrumentation"
 	"github.com/telhawk-systems/telhawk-stack/common/logging"
 )
 
@@ -40,6 +41,12 @@ func main() {
 	).With(logging.Service("authenticate"))
 	logging.SetDefault(logger)
 
+	shutdownTracing, err := instrumentation.Setup(context.Background(), "authenticate", cfg.Tracing)
+	if err != nil {
+		slog.Error("Failed to initialize tracing", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	slog.Info("Starting Authenticate service",
 		slog.Int("port", cfg.Authenticate.Server.Port),
 		slog.String("log_level", cfg.Logging.Level),
@@ -113,7 +120,11 @@ func main() {
 		ingestClient = audit.NewIngestClient(cfg.Authenticate.Ingest.URL, cfg.Authenticate.Ingest.HECToken)
 	}
 
-	authService := service.NewAuthService(repo, ingestClient)
+	authService, err := service.NewAuthService(repo, ingestClient)
+	if err != nil {
+		slog.Error("Failed to create auth service", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
 
 	// Initialize HTTP handlers and middleware
 	handler := handlers.NewAuthHandler(authService)
@@ -151,6 +162,9 @@ func main() {
 		slog.Error("Server forced to shutdown", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Warn("Failed to flush traces", slog.String("error", err.Error()))
+	}
 
 	slog.Info("Server stopped gracefully")
 }
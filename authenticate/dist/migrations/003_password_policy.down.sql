@@ -0,0 +1,4 @@
+-- Migration 003 DOWN: Remove password policy state
+
+DROP TABLE IF EXISTS user_login_state;
+DROP TABLE IF EXISTS password_history;
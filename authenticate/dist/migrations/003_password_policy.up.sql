@@ -0,0 +1,58 @@
+-- Migration 003: Password policy state (history, expiry, lockout)
+--
+-- Password history is append-only: every password set for a user is
+-- recorded so the policy can reject reuse of the last N hashes.
+--
+-- Login state is operational and changes on every failed attempt, so it
+-- lives in its own table keyed by user_id instead of creating new versions
+-- of the immutable users table.
+
+-- ============================================================================
+-- PASSWORD HISTORY (Append-only)
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS password_history (
+    -- Identity (UUIDv7: timestamp = when the password was set)
+    id UUID PRIMARY KEY,
+
+    user_id UUID NOT NULL,  -- References users(id)
+    password_hash TEXT NOT NULL,
+
+    created_by UUID,  -- References users(id), NULL for self-service change
+    created_from_ip INET,
+    created_source_type SMALLINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_password_history_user_id ON password_history(user_id, id DESC);
+
+COMMENT ON TABLE password_history IS 'Previous password hashes per user (append-only)';
+COMMENT ON COLUMN password_history.id IS 'History entry ID (UUIDv7 timestamp = when the password was set)';
+COMMENT ON COLUMN password_history.created_by IS 'Admin who reset the password, NULL when changed by the user';
+
+-- ============================================================================
+-- USER LOGIN STATE (Mutable operational state)
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS user_login_state (
+    user_id UUID PRIMARY KEY,  -- References users(id)
+
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMPTZ,
+    locked_until TIMESTAMPTZ,
+
+    password_changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_login_state_locked ON user_login_state(locked_until)
+    WHERE locked_until IS NOT NULL;
+
+COMMENT ON TABLE user_login_state IS 'Failed login counters, lockout and password age per user';
+COMMENT ON COLUMN user_login_state.failed_attempts IS 'Consecutive failed logins since the last success';
+COMMENT ON COLUMN user_login_state.locked_until IS 'Logins are refused until this time';
+COMMENT ON COLUMN user_login_state.password_changed_at IS 'When the current password was set (drives max_age expiry)';
+
+-- Existing users start with a fresh password age so that enabling
+-- max_age does not expire every account at once.
+INSERT INTO user_login_state (user_id)
+SELECT DISTINCT id FROM users WHERE deleted_at IS NULL
+ON CONFLICT (user_id) DO NOTHING;
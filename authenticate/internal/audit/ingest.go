@@ -219,6 +219,12 @@ func mapActionToActivityID(action string) int {
 		return 99 // Other
 	case "password_change":
 		return 99 // Other
+	case "password_reset":
+		return 99 // Other
+	case "password_expired":
+		return 1 // Logon (refused until the password is changed)
+	case "account_locked":
+		return 99 // Other
 	default:
 		return 99 // Other
 	}
@@ -228,8 +234,12 @@ func mapResultToSeverity(result, action string) string {
 	if result == "success" {
 		return "Informational"
 	}
+	// Lockouts indicate repeated failures (possible brute force)
+	if action == "account_locked" {
+		return "High"
+	}
 	// Failed logins are more critical
-	if action == "login" {
+	if action == "login" || action == "password_expired" {
 		return "Medium"
 	}
 	return "Low"
@@ -239,7 +249,10 @@ func mapResultToSeverityID(result, action string) int {
 	if result == "success" {
 		return 1 // Informational
 	}
-	if action == "login" {
+	if action == "account_locked" {
+		return 4 // High
+	}
+	if action == "login" || action == "password_expired" {
 		return 3 // Medium
 	}
 	return 2 // Low
@@ -362,6 +362,9 @@ func TestMapActionToActivityID(t *testing.T) {
 		{"user_update", 99},
 		{"user_delete", 99},
 		{"password_change", 99},
+		{"password_reset", 99},
+		{"password_expired", 1},
+		{"account_locked", 99},
 		{"unknown_action", 99},
 	}
 
@@ -386,6 +389,8 @@ func TestMapResultToSeverity(t *testing.T) {
 		{"failure", "login", "Medium"},
 		{"failure", "logout", "Low"},
 		{"failure", "user_update", "Low"},
+		{"failure", "password_expired", "Medium"},
+		{"failure", "account_locked", "High"},
 	}
 
 	for _, tt := range tests {
@@ -410,6 +415,8 @@ func TestMapResultToSeverityID(t *testing.T) {
 		{"failure", "login", 3},
 		{"failure", "logout", 2},
 		{"failure", "user_update", 2},
+		{"failure", "password_expired", 3},
+		{"failure", "account_locked", 4},
 	}
 
 	for _, tt := range tests {
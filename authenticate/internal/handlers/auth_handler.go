@@ -2,13 +2,16 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
 
 	"github.com/telhawk-systems/telhawk-stack/common/httputil"
 
+	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/metrics"
 	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/password"
 	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/service"
 )
 
@@ -101,14 +104,60 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	resp, err := h.service.Login(r.Context(), &req, ipAddress, userAgent)
 	if err != nil {
 		log.Printf("Login failed for user %s: %v", req.Username, err)
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		switch {
+		case errors.Is(err, service.ErrAccountLocked):
+			metrics.LoginsTotal.WithLabelValues("locked").Inc()
+			http.Error(w, "Account locked", http.StatusLocked)
+		case errors.Is(err, service.ErrPasswordChangeRequired):
+			metrics.LoginsTotal.WithLabelValues("password_expired").Inc()
+			http.Error(w, "Password expired: change required", http.StatusForbidden)
+		default:
+			metrics.LoginsTotal.WithLabelValues("invalid_credentials").Inc()
+			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		}
 		return
 	}
 
+	metrics.LoginsTotal.WithLabelValues("success").Inc()
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
+// ChangePassword lets a user set a new password using their current one.
+// Public so that users with an expired password can recover.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ipAddress := httputil.GetClientIP(r)
+	userAgent := r.Header.Get("User-Agent")
+
+	if err := h.service.ChangePassword(r.Context(), &req, ipAddress, userAgent); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		case errors.Is(err, service.ErrAccountLocked):
+			http.Error(w, "Account locked", http.StatusLocked)
+		case errors.Is(err, password.ErrPolicyViolation):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			log.Printf("Password change failed for user %s: %v", req.Username, err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -145,17 +194,20 @@ func (h *AuthHandler) ValidateToken(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.service.ValidateToken(r.Context(), req.Token)
 	if err != nil {
+		metrics.TokenValidationsTotal.WithLabelValues("access", "error").Inc()
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	if resp == nil || !resp.Valid {
+		metrics.TokenValidationsTotal.WithLabelValues("access", "invalid").Inc()
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(resp)
 		return
 	}
 
+	metrics.TokenValidationsTotal.WithLabelValues("access", "valid").Inc()
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
@@ -197,6 +249,7 @@ func (h *AuthHandler) ValidateHECToken(w http.ResponseWriter, r *http.Request) {
 
 	hecToken, err := h.service.ValidateHECToken(r.Context(), req.Token, ipAddress, userAgent)
 	if err != nil {
+		metrics.TokenValidationsTotal.WithLabelValues("hec", "invalid").Inc()
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(models.ValidateHECTokenResponse{
 			Valid: false,
@@ -204,6 +257,7 @@ func (h *AuthHandler) ValidateHECToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	metrics.TokenValidationsTotal.WithLabelValues("hec", "valid").Inc()
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(models.ValidateHECTokenResponse{
 		Valid:     true,
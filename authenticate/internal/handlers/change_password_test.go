@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/repository"
+	"github.com/telhawk-systems/telhawk-stack/common/config"
+)
+
+// TestMain loads the default configuration from an empty config file so
+// tests do not depend on /etc/telhawk.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "telhawk-config")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), nil, 0o600); err != nil {
+		panic(err)
+	}
+	os.Setenv("TELHAWK_CONFIG_DIR", dir)
+	config.MustLoad("authenticate")
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func postChangePassword(handler *AuthHandler, req models.ChangePasswordRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/change-password", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ChangePassword(w, r)
+	return w
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	setupTestConfig()
+	// The in-memory repository tracks password history and lockouts
+	repo := repository.NewInMemoryRepository()
+	svc := newTestService(repo)
+	handler := NewAuthHandler(svc)
+
+	if _, err := svc.CreateUser(context.Background(), &models.CreateUserRequest{
+		Username: "changer",
+		Email:    "changer@example.com",
+		Password: "Original-Pass-1",
+	}, "", "", ""); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		req        models.ChangePasswordRequest
+		wantStatus int
+	}{
+		{
+			name:       "wrong current password",
+			req:        models.ChangePasswordRequest{Username: "changer", CurrentPassword: "Not-The-Pass-1", NewPassword: "Replacement-Pass-2"},
+			wantStatus: http.StatusUnauthorized},
+		{
+			name:       "unknown user",
+			req:        models.ChangePasswordRequest{Username: "nobody", CurrentPassword: "Original-Pass-1", NewPassword: "Replacement-Pass-2"},
+			wantStatus: http.StatusUnauthorized},
+		{
+			name:       "new password violates policy",
+			req:        models.ChangePasswordRequest{Username: "changer", CurrentPassword: "Original-Pass-1", NewPassword: "short"},
+			wantStatus: http.StatusBadRequest},
+		{
+			name:       "new password same as current",
+			req:        models.ChangePasswordRequest{Username: "changer", CurrentPassword: "Original-Pass-1", NewPassword: "Original-Pass-1"},
+			wantStatus: http.StatusBadRequest},
+		{
+			name:       "successful change",
+			req:        models.ChangePasswordRequest{Username: "changer", CurrentPassword: "Original-Pass-1", NewPassword: "Replacement-Pass-2"},
+			wantStatus: http.StatusNoContent},
+		{
+			name:       "old password no longer accepted",
+			req:        models.ChangePasswordRequest{Username: "changer", CurrentPassword: "Original-Pass-1", NewPassword: "Another-Pass-3"},
+			wantStatus: http.StatusUnauthorized},
+		{
+			name:       "reuse of a previous password",
+			req:        models.ChangePasswordRequest{Username: "changer", CurrentPassword: "Replacement-Pass-2", NewPassword: "Original-Pass-1"},
+			wantStatus: http.StatusBadRequest}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postChangePassword(handler, tt.req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestChangePasswordHandler_Locked(t *testing.T) {
+	setupTestConfig()
+	repo := repository.NewInMemoryRepository()
+	svc := newTestService(repo)
+	handler := NewAuthHandler(svc)
+
+	if _, err := svc.CreateUser(context.Background(), &models.CreateUserRequest{
+		Username: "locked",
+		Email:    "locked@example.com",
+		Password: "Original-Pass-1",
+	}, "", "", ""); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	// Wrong current passwords count towards the lockout threshold
+	threshold := config.GetConfig().Authenticate.PasswordPolicy.LockoutThreshold
+	for i := 0; i < threshold; i++ {
+		postChangePassword(handler, models.ChangePasswordRequest{Username: "locked", CurrentPassword: "Not-The-Pass-1", NewPassword: "Replacement-Pass-2"})
+	}
+
+	w := postChangePassword(handler, models.ChangePasswordRequest{Username: "locked", CurrentPassword: "Original-Pass-1", NewPassword: "Replacement-Pass-2"})
+	if w.Code != http.StatusLocked {
+		t.Errorf("Expected status %d, got %d", http.StatusLocked, w.Code)
+	}
+}
+
+func TestChangePasswordHandler_MethodNotAllowed(t *testing.T) {
+	handler := setupHandler()
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/auth/change-password", nil)
+	w := httptest.NewRecorder()
+	handler.ChangePassword(w, r)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
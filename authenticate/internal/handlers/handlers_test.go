@@ -220,10 +220,19 @@ func setupTestConfig() {
 	cfg.Authenticate.Auth.AuditSecret = "test-audit"
 }
 
+// newTestService creates an auth service over repo with the test config.
+func newTestService(repo repository.Repository) *service.AuthService {
+	svc, err := service.NewAuthService(repo, nil)
+	if err != nil {
+		panic(err)
+	}
+	return svc
+}
+
 func setupHandler() *AuthHandler {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	return NewAuthHandler(svc)
 }
 
@@ -845,7 +854,7 @@ func TestGetClientIP(t *testing.T) {
 func TestListHECTokensHandler_AdminUser(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create test users
@@ -913,7 +922,7 @@ func TestListHECTokensHandler_AdminUser(t *testing.T) {
 func TestListHECTokensHandler_RegularUser(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create test user
@@ -996,7 +1005,7 @@ func TestListHECTokensHandler_EmptyResult(t *testing.T) {
 func TestRevokeHECTokenByIDHandler_Success(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create token
@@ -1028,7 +1037,7 @@ func TestRevokeHECTokenByIDHandler_Success(t *testing.T) {
 func TestRevokeHECTokenByIDHandler_Unauthorized(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create token owned by user-1
@@ -1090,7 +1099,7 @@ func TestRevokeHECTokenByIDHandler_NotFound(t *testing.T) {
 func TestValidateHECTokenHandler_ValidToken(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create valid token
@@ -1143,7 +1152,7 @@ func TestValidateHECTokenHandler_InvalidToken(t *testing.T) {
 func TestValidateHECTokenHandler_RevokedToken(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create and revoke token
@@ -1204,7 +1213,7 @@ func TestValidateHECTokenHandler_MissingToken(t *testing.T) {
 func TestLoginHandler_Success(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create a test user with hashed password
@@ -1244,14 +1253,14 @@ func TestLoginHandler_Success(t *testing.T) {
 func TestCreateUserHandler_Success(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create request
 	reqData := models.CreateUserRequest{
 		Username: "newuser",
 		Email:    "newuser@example.com",
-		Password: "password123",
+		Password: "Password12345",
 		Roles:    []string{"viewer"}}
 	body, _ := json.Marshal(reqData)
 	req := httptest.NewRequest("POST", "/api/v1/users/create", bytes.NewReader(body))
@@ -1282,7 +1291,7 @@ func TestCreateUserHandler_Success(t *testing.T) {
 func TestRefreshTokenHandler_Success(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create user and login to get refresh token
@@ -1322,7 +1331,7 @@ func TestRefreshTokenHandler_Success(t *testing.T) {
 func TestValidateTokenHandler_Success(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create user and login to get access token
@@ -1365,7 +1374,7 @@ func TestValidateTokenHandler_Success(t *testing.T) {
 func TestRevokeTokenHandler_Success(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create user and login to get refresh token
@@ -1404,7 +1413,7 @@ func TestRevokeTokenHandler_Success(t *testing.T) {
 func TestGetUserHandler_Success(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create test user
@@ -1439,7 +1448,7 @@ func TestGetUserHandler_Success(t *testing.T) {
 func TestUpdateUserHandler_Success(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create test user
@@ -1479,7 +1488,7 @@ func TestUpdateUserHandler_Success(t *testing.T) {
 func TestDeleteUserHandler_Success(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create test user
@@ -1511,7 +1520,7 @@ func TestDeleteUserHandler_Success(t *testing.T) {
 func TestResetPasswordHandler_Success(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create test user
@@ -1526,7 +1535,7 @@ func TestResetPasswordHandler_Success(t *testing.T) {
 
 	// Reset password
 	resetReq := models.ResetPasswordRequest{
-		NewPassword: "newpassword123"}
+		NewPassword: "NewPassword123"}
 	body, _ := json.Marshal(resetReq)
 	req := httptest.NewRequest("POST", "/reset-password?id=user-123", bytes.NewReader(body))
 	req.Header.Set("X-User-ID", "admin-user")
@@ -1540,7 +1549,7 @@ func TestResetPasswordHandler_Success(t *testing.T) {
 
 	// Verify password was changed using bcrypt
 	updatedUser, _ := repo.GetUserByID(context.Background(), "user-123")
-	err := bcrypt.CompareHashAndPassword([]byte(updatedUser.PasswordHash), []byte("newpassword123"))
+	err := bcrypt.CompareHashAndPassword([]byte(updatedUser.PasswordHash), []byte("NewPassword123"))
 	if err != nil {
 		t.Error("Expected password to be updated")
 	}
@@ -1549,7 +1558,7 @@ func TestResetPasswordHandler_Success(t *testing.T) {
 func TestCreateHECTokenHandler_Success(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create test user
@@ -1595,7 +1604,7 @@ func TestCreateHECTokenHandler_Success(t *testing.T) {
 func TestRevokeHECTokenHandler_Success(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create HEC token
@@ -1630,7 +1639,7 @@ func TestRevokeHECTokenHandler_Success(t *testing.T) {
 func TestUpdateUserHandler_PatchMethod(t *testing.T) {
 	setupTestConfig()
 	repo := newTestRepo()
-	svc := service.NewAuthService(repo, nil)
+	svc := newTestService(repo)
 	handler := NewAuthHandler(svc)
 
 	// Create test user
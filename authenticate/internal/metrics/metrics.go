@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Login metrics
+	LoginsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telhawk_authenticate_logins_total",
+			Help: "Total number of login attempts",
+		},
+		[]string{"outcome"}, // success, invalid_credentials, locked, password_expired
+	)
+
+	// Token validation metrics
+	TokenValidationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telhawk_authenticate_token_validations_total",
+			Help: "Total number of token validations",
+		},
+		[]string{"type", "result"}, // type: access, hec; result: valid, invalid, error
+	)
+)
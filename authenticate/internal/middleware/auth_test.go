@@ -20,7 +20,11 @@ func newTestAuthService(t *testing.T) *service.AuthService {
 	cfg.Authenticate.Auth.AuditSecret = "test-audit-secret"
 
 	repo := repository.NewInMemoryRepository()
-	return service.NewAuthService(repo, nil)
+	svc, err := service.NewAuthService(repo, nil)
+	if err != nil {
+		t.Fatalf("Failed to create auth service: %v", err)
+	}
+	return svc
 }
 
 // createTestUser creates a user and returns a valid token
@@ -51,6 +51,8 @@ const (
 	ActionUserUpdate       = "user_update"
 	ActionUserDelete       = "user_delete"
 	ActionPasswordChange   = "password_change"
+	ActionAccountLocked    = "account_locked"
+	ActionPasswordExpired  = "password_expired"
 )
 
 // ShouldForwardToIngest returns true if this action should be forwarded
@@ -63,6 +63,12 @@ type ResetPasswordRequest struct {
 	NewPassword string `json:"new_password"`
 }
 
+type ChangePasswordRequest struct {
+	Username        string `json:"username"`
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
 type CreateHECTokenRequest struct {
 	Name      string `json:"name"`
 	ClientID  string `json:"client_id"`
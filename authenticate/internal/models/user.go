@@ -203,6 +203,32 @@ func (s *Session) IsActive() bool {
 	return s.RevokedAt == nil && s.ExpiresAt.After(time.Now())
 }
 
+// LoginState tracks failed logins, lockout and password age for a user.
+// Stored separately from the versioned users table (migration 003) because
+// it changes on every failed login attempt.
+type LoginState struct {
+	UserID            string     `json:"user_id"`
+	FailedAttempts    int        `json:"failed_attempts"`
+	LastFailedAt      *time.Time `json:"last_failed_at,omitempty"`
+	LockedUntil       *time.Time `json:"locked_until,omitempty"`
+	PasswordChangedAt time.Time  `json:"password_changed_at"`
+}
+
+// IsLocked returns true if logins are refused at the given time
+func (s *LoginState) IsLocked(now time.Time) bool {
+	return s.LockedUntil != nil && s.LockedUntil.After(now)
+}
+
+// PasswordHistoryEntry records a password previously set for a user
+type PasswordHistoryEntry struct {
+	ID                string  `json:"id"` // UUIDv7 timestamp = when the password was set
+	UserID            string  `json:"user_id"`
+	PasswordHash      string  `json:"-"`
+	CreatedBy         *string `json:"created_by,omitempty"` // NULL for self-service change
+	CreatedFromIP     *string `json:"created_from_ip,omitempty"`
+	CreatedSourceType int     `json:"created_source_type,omitempty"`
+}
+
 // LegacyRole is the old simple role type (kept for backward compatibility)
 type LegacyRole string
 
@@ -0,0 +1,204 @@
+// Package password implements the configurable password policy: complexity
+// rules, a common/breached password list, reuse prevention, maximum age and
+// failed-login lockout with exponential backoff.
+package password
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/telhawk-systems/telhawk-stack/common/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrPolicyViolation is wrapped by every *PolicyError.
+var ErrPolicyViolation = errors.New("password does not meet policy")
+
+// ErrPasswordReused is returned when a new password matches one of the
+// user's recent password hashes.
+var ErrPasswordReused = fmt.Errorf("%w: password was used recently", ErrPolicyViolation)
+
+// PolicyError lists every rule a candidate password failed.
+type PolicyError struct {
+	Violations []string
+}
+
+func (e *PolicyError) Error() string {
+	return ErrPolicyViolation.Error() + ": " + strings.Join(e.Violations, "; ")
+}
+
+func (e *PolicyError) Unwrap() error {
+	return ErrPolicyViolation
+}
+
+// Policy is an immutable, loaded password policy.
+type Policy struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	HistoryCount     int
+	MaxAge           time.Duration
+
+	LockoutThreshold   int
+	LockoutDuration    time.Duration
+	LockoutMaxDuration time.Duration
+
+	common map[string]struct{}
+}
+
+// NewPolicy builds a policy from configuration, loading the common
+// password list from disk when one is configured.
+func NewPolicy(cfg config.PasswordPolicyConfig) (*Policy, error) {
+	p := &Policy{
+		MinLength:          cfg.MinLength,
+		RequireUppercase:   cfg.RequireUppercase,
+		RequireLowercase:   cfg.RequireLowercase,
+		RequireDigit:       cfg.RequireDigit,
+		RequireSymbol:      cfg.RequireSymbol,
+		HistoryCount:       cfg.HistoryCount,
+		MaxAge:             cfg.MaxAge,
+		LockoutThreshold:   cfg.LockoutThreshold,
+		LockoutDuration:    cfg.LockoutDuration,
+		LockoutMaxDuration: cfg.LockoutMaxDuration,
+	}
+
+	if cfg.CommonPasswordsFile != "" {
+		common, err := LoadCommonList(cfg.CommonPasswordsFile)
+		if err != nil {
+			return nil, err
+		}
+		p.common = common
+	}
+
+	return p, nil
+}
+
+// LoadCommonList reads a newline-separated list of common or breached
+// passwords. Blank lines and lines starting with '#' are skipped; entries
+// are compared case-insensitively.
+func LoadCommonList(path string) (map[string]struct{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open common password list: %w", err)
+	}
+	defer f.Close()
+
+	list := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read common password list: %w", err)
+	}
+	return list, nil
+}
+
+// Validate checks a candidate password against the complexity rules and
+// the common password list. The username is rejected as a password.
+func (p *Policy) Validate(password, username string) error {
+	var violations []string
+
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUppercase && !hasUpper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if p.RequireLowercase && !hasLower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, "must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, "must contain a symbol")
+	}
+
+	lower := strings.ToLower(password)
+	if username != "" && strings.Contains(lower, strings.ToLower(username)) {
+		violations = append(violations, "must not contain the username")
+	}
+	if _, found := p.common[lower]; found {
+		violations = append(violations, "is a commonly used or breached password")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// CheckHistory returns ErrPasswordReused if the password matches any of
+// the given previous hashes. Only the most recent HistoryCount hashes are
+// considered; the caller passes them newest first.
+func (p *Policy) CheckHistory(password string, previousHashes []string) error {
+	if p.HistoryCount <= 0 {
+		return nil
+	}
+	if len(previousHashes) > p.HistoryCount {
+		previousHashes = previousHashes[:p.HistoryCount]
+	}
+	for _, hash := range previousHashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// Expired reports whether a password set at changedAt has exceeded the
+// maximum age. A zero MaxAge disables expiry.
+func (p *Policy) Expired(changedAt, now time.Time) bool {
+	if p.MaxAge <= 0 || changedAt.IsZero() {
+		return false
+	}
+	return now.Sub(changedAt) > p.MaxAge
+}
+
+// LockoutFor returns how long an account stays locked after the given
+// number of consecutive failed logins. The first lock lasts
+// LockoutDuration and each further failure doubles it, capped at
+// LockoutMaxDuration. Zero means the account is not locked.
+func (p *Policy) LockoutFor(failedAttempts int) time.Duration {
+	if p.LockoutThreshold <= 0 || failedAttempts < p.LockoutThreshold {
+		return 0
+	}
+
+	d := p.LockoutDuration
+	for i := p.LockoutThreshold; i < failedAttempts; i++ {
+		d *= 2
+		if p.LockoutMaxDuration > 0 && d >= p.LockoutMaxDuration {
+			return p.LockoutMaxDuration
+		}
+	}
+	if p.LockoutMaxDuration > 0 && d > p.LockoutMaxDuration {
+		return p.LockoutMaxDuration
+	}
+	return d
+}
@@ -0,0 +1,195 @@
+package password
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func testPolicy() *Policy {
+	return &Policy{
+		MinLength:          12,
+		RequireUppercase:   true,
+		RequireLowercase:   true,
+		RequireDigit:       true,
+		RequireSymbol:      true,
+		HistoryCount:       3,
+		MaxAge:             90 * 24 * time.Hour,
+		LockoutThreshold:   5,
+		LockoutDuration:    time.Minute,
+		LockoutMaxDuration: 10 * time.Minute,
+		common:             map[string]struct{}{"correcthorse1!a": {}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	policy := testPolicy()
+
+	tests := []struct {
+		name       string
+		password   string
+		username   string
+		violations int
+	}{
+		{"valid password", "Tr0ub4dor&3-xyz", "alice", 0},
+		{"too short", "Ab1!", "alice", 1},
+		{"missing uppercase", "tr0ub4dor&3-xyz", "alice", 1},
+		{"missing lowercase", "TR0UB4DOR&3-XYZ", "alice", 1},
+		{"missing digit", "Troubador&&-xyz", "alice", 1},
+		{"missing symbol", "Tr0ub4dor3xyzab", "alice", 1},
+		{"contains username", "Alice-Pa55word!", "alice", 1},
+		{"common password is case-insensitive", "CorrectHorse1!A", "bob", 1},
+		{"multiple violations", "abc", "alice", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, tt.username)
+			if tt.violations == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var policyErr *PolicyError
+			if !errors.As(err, &policyErr) {
+				t.Fatalf("Expected *PolicyError, got %v", err)
+			}
+			if !errors.Is(err, ErrPolicyViolation) {
+				t.Error("Expected error to wrap ErrPolicyViolation")
+			}
+			if len(policyErr.Violations) != tt.violations {
+				t.Errorf("Expected %d violations, got %d: %v", tt.violations, len(policyErr.Violations), policyErr.Violations)
+			}
+		})
+	}
+}
+
+func TestCheckHistory(t *testing.T) {
+	policy := testPolicy()
+
+	hash := func(p string) string {
+		h, err := bcrypt.GenerateFromPassword([]byte(p), bcrypt.MinCost)
+		if err != nil {
+			t.Fatalf("Failed to hash: %v", err)
+		}
+		return string(h)
+	}
+	history := []string{hash("newest"), hash("middle"), hash("oldest"), hash("expired")}
+
+	tests := []struct {
+		name     string
+		password string
+		reused   bool
+	}{
+		{"newest hash", "newest", true},
+		{"oldest within history count", "oldest", true},
+		{"beyond history count", "expired", false},
+		{"never used", "fresh", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.CheckHistory(tt.password, history)
+			if tt.reused && !errors.Is(err, ErrPasswordReused) {
+				t.Errorf("Expected ErrPasswordReused, got %v", err)
+			}
+			if !tt.reused && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		disabled := testPolicy()
+		disabled.HistoryCount = 0
+		if err := disabled.CheckHistory("newest", history); err != nil {
+			t.Errorf("Expected no error with history disabled, got %v", err)
+		}
+	})
+}
+
+func TestExpired(t *testing.T) {
+	policy := testPolicy()
+	now := time.Now()
+
+	if policy.Expired(now.Add(-89*24*time.Hour), now) {
+		t.Error("Password younger than max age should not be expired")
+	}
+	if !policy.Expired(now.Add(-91*24*time.Hour), now) {
+		t.Error("Password older than max age should be expired")
+	}
+	if policy.Expired(time.Time{}, now) {
+		t.Error("Unknown change time should not be expired")
+	}
+
+	policy.MaxAge = 0
+	if policy.Expired(now.Add(-365*24*time.Hour), now) {
+		t.Error("Zero max age should disable expiry")
+	}
+}
+
+func TestLockoutFor(t *testing.T) {
+	policy := testPolicy()
+
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{0, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{7, 4 * time.Minute},
+		{8, 8 * time.Minute},
+		{9, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := policy.LockoutFor(tt.attempts); got != tt.expected {
+			t.Errorf("LockoutFor(%d) = %v, expected %v", tt.attempts, got, tt.expected)
+		}
+	}
+
+	policy.LockoutThreshold = 0
+	if got := policy.LockoutFor(100); got != 0 {
+		t.Errorf("Expected no lockout when disabled, got %v", got)
+	}
+}
+
+func TestNewPolicyLoadsCommonList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "common.txt")
+	content := "# top passwords\nPassword123!\n\n  letmein  \n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write list: %v", err)
+	}
+
+	policy, err := NewPolicy(config.PasswordPolicyConfig{
+		MinLength:           4,
+		CommonPasswordsFile: path,
+	})
+	if err != nil {
+		t.Fatalf("NewPolicy failed: %v", err)
+	}
+
+	if err := policy.Validate("password123!", ""); err == nil {
+		t.Error("Expected common password to be rejected")
+	}
+	if err := policy.Validate("LETMEIN", ""); err == nil {
+		t.Error("Expected trimmed common password to be rejected")
+	}
+	if err := policy.Validate("# top passwords", ""); err != nil {
+		t.Errorf("Comment lines should be ignored, got %v", err)
+	}
+
+	if _, err := NewPolicy(config.PasswordPolicyConfig{CommonPasswordsFile: filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Error("Expected error for missing common password file")
+	}
+}
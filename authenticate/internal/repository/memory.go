@@ -13,6 +13,8 @@ type InMemoryRepository struct {
 	usersByName map[string]*models.User
 	sessions    map[string]*models.Session
 	hecTokens   map[string]*models.HECToken
+	loginState  map[string]*models.LoginState
+	pwHistory   map[string][]*models.PasswordHistoryEntry
 	mu          sync.RWMutex
 }
 
@@ -22,6 +24,8 @@ func NewInMemoryRepository() *InMemoryRepository {
 		usersByName: make(map[string]*models.User),
 		sessions:    make(map[string]*models.Session),
 		hecTokens:   make(map[string]*models.HECToken),
+		loginState:  make(map[string]*models.LoginState),
+		pwHistory:   make(map[string][]*models.PasswordHistoryEntry),
 	}
 }
 
@@ -286,3 +290,86 @@ func (r *InMemoryRepository) ListClients(ctx context.Context) ([]*models.Client,
 func (r *InMemoryRepository) ListClientsByOrganization(ctx context.Context, orgID string) ([]*models.Client, error) {
 	return nil, nil
 }
+
+// =============================================================================
+// PASSWORD POLICY STATE
+// =============================================================================
+
+// loginStateLocked returns the stored state for a user, creating it if
+// needed. Caller must hold r.mu for writing.
+func (r *InMemoryRepository) loginStateLocked(userID string) *models.LoginState {
+	state, exists := r.loginState[userID]
+	if !exists {
+		state = &models.LoginState{UserID: userID}
+		r.loginState[userID] = state
+	}
+	return state
+}
+
+func (r *InMemoryRepository) GetLoginState(ctx context.Context, userID string) (*models.LoginState, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, exists := r.loginState[userID]
+	if !exists {
+		return &models.LoginState{UserID: userID}, nil
+	}
+	copied := *state
+	return &copied, nil
+}
+
+func (r *InMemoryRepository) RecordFailedLogin(ctx context.Context, userID string, at time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.loginStateLocked(userID)
+	state.FailedAttempts++
+	state.LastFailedAt = &at
+	return state.FailedAttempts, nil
+}
+
+func (r *InMemoryRepository) LockUser(ctx context.Context, userID string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.loginStateLocked(userID).LockedUntil = &until
+	return nil
+}
+
+func (r *InMemoryRepository) ResetFailedLogins(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if state, exists := r.loginState[userID]; exists {
+		state.FailedAttempts = 0
+		state.LastFailedAt = nil
+		state.LockedUntil = nil
+	}
+	return nil
+}
+
+func (r *InMemoryRepository) RecordPasswordChange(ctx context.Context, entry *models.PasswordHistoryEntry, changedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pwHistory[entry.UserID] = append(r.pwHistory[entry.UserID], entry)
+
+	state := r.loginStateLocked(entry.UserID)
+	state.PasswordChangedAt = changedAt
+	state.FailedAttempts = 0
+	state.LastFailedAt = nil
+	state.LockedUntil = nil
+	return nil
+}
+
+func (r *InMemoryRepository) ListPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := r.pwHistory[userID]
+	var hashes []string
+	for i := len(entries) - 1; i >= 0 && len(hashes) < limit; i-- {
+		hashes = append(hashes, entries[i].PasswordHash)
+	}
+	return hashes, nil
+}
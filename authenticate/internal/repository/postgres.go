@@ -977,3 +977,154 @@ func (r *PostgresRepository) ListClientsByOrganization(ctx context.Context, orgI
 
 	return clients, nil
 }
+
+// =============================================================================
+// PASSWORD POLICY STATE (password_history append-only, user_login_state mutable)
+// =============================================================================
+
+func (r *PostgresRepository) GetLoginState(ctx context.Context, userID string) (*models.LoginState, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT user_id, failed_attempts, last_failed_at, locked_until,
+		       password_changed_at
+		FROM user_login_state
+		WHERE user_id = $1
+	`
+
+	var state models.LoginState
+	err := r.pool.QueryRow(ctx, query, userID).Scan(
+		&state.UserID, &state.FailedAttempts, &state.LastFailedAt, &state.LockedUntil,
+		&state.PasswordChangedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &models.LoginState{UserID: userID}, nil
+		}
+		return nil, fmt.Errorf("failed to get login state: %w", err)
+	}
+
+	return &state, nil
+}
+
+func (r *PostgresRepository) RecordFailedLogin(ctx context.Context, userID string, at time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_login_state (user_id, failed_attempts, last_failed_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET failed_attempts = user_login_state.failed_attempts + 1,
+		    last_failed_at = EXCLUDED.last_failed_at
+		RETURNING failed_attempts
+	`
+
+	var attempts int
+	if err := r.pool.QueryRow(ctx, query, userID, at).Scan(&attempts); err != nil {
+		return 0, fmt.Errorf("failed to record failed login: %w", err)
+	}
+
+	return attempts, nil
+}
+
+func (r *PostgresRepository) LockUser(ctx context.Context, userID string, until time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_login_state (user_id, locked_until)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET locked_until = EXCLUDED.locked_until
+	`
+
+	if _, err := r.pool.Exec(ctx, query, userID, until); err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ResetFailedLogins(ctx context.Context, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE user_login_state
+		SET failed_attempts = 0, last_failed_at = NULL, locked_until = NULL
+		WHERE user_id = $1 AND (failed_attempts > 0 OR locked_until IS NOT NULL)
+	`
+
+	if _, err := r.pool.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to reset failed logins: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) RecordPasswordChange(ctx context.Context, entry *models.PasswordHistoryEntry, changedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO password_history (id, user_id, password_hash, created_by, created_from_ip, created_source_type)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, entry.ID, entry.UserID, entry.PasswordHash, entry.CreatedBy, entry.CreatedFromIP, entry.CreatedSourceType)
+	if err != nil {
+		return fmt.Errorf("failed to record password history: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO user_login_state (user_id, password_changed_at)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET password_changed_at = EXCLUDED.password_changed_at,
+		    failed_attempts = 0, last_failed_at = NULL, locked_until = NULL
+	`, entry.UserID, changedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update login state: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit password change: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ListPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT password_hash
+		FROM password_history
+		WHERE user_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list password history: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("failed to scan password history: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, rows.Err()
+}
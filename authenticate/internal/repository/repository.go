@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/models"
 )
@@ -49,3 +50,25 @@ type Repository interface {
 	ListClients(ctx context.Context) ([]*models.Client, error)
 	ListClientsByOrganization(ctx context.Context, orgID string) ([]*models.Client, error)
 }
+
+// PasswordRepository stores the state needed by the password policy:
+// failed login counters, lockout, password age and password history.
+// Implemented by the Postgres and in-memory repositories; the service
+// skips history and lockout enforcement for repositories without it.
+type PasswordRepository interface {
+	// GetLoginState returns the user's login state. Users without stored
+	// state get a zero state with PasswordChangedAt unset.
+	GetLoginState(ctx context.Context, userID string) (*models.LoginState, error)
+	// RecordFailedLogin atomically increments the failed attempt counter
+	// and returns the new count.
+	RecordFailedLogin(ctx context.Context, userID string, at time.Time) (int, error)
+	// LockUser refuses logins for the user until the given time.
+	LockUser(ctx context.Context, userID string, until time.Time) error
+	// ResetFailedLogins clears the failed attempt counter and any lockout.
+	ResetFailedLogins(ctx context.Context, userID string) error
+	// RecordPasswordChange appends the new hash to the user's history,
+	// resets the password age and clears any lockout.
+	RecordPasswordChange(ctx context.Context, entry *models.PasswordHistoryEntry, changedAt time.Time) error
+	// ListPasswordHistory returns up to limit previous hashes, newest first.
+	ListPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error)
+}
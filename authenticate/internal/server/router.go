@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/telhawk-systems/telhawk-stack/common/instrumentation"
 	"github.com/telhawk-systems/telhawk-stack/common/middleware"
 
 	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/handlers"
@@ -17,6 +18,7 @@ func NewRouter(h *handlers.AuthHandler, authMW *authmw.AuthMiddleware) http.Hand
 	// Authentication endpoints (public - no auth required)
 	mux.HandleFunc("/api/v1/auth/login", h.Login)
 	mux.HandleFunc("/api/v1/auth/refresh", h.RefreshToken)
+	mux.HandleFunc("/api/v1/auth/change-password", h.ChangePassword)
 
 	// Service-to-service validation endpoints (internal, no user auth)
 	mux.HandleFunc("/api/v1/auth/validate", h.ValidateToken)
@@ -66,5 +68,8 @@ func NewRouter(h *handlers.AuthHandler, authMW *authmw.AuthMiddleware) http.Hand
 	// Health check (public)
 	mux.HandleFunc("/healthz", h.HealthCheck)
 
-	return middleware.RequestID(mux)
+	// Prometheus metrics
+	mux.Handle("GET /metrics", instrumentation.MetricsHandler())
+
+	return middleware.RequestID(instrumentation.Middleware("authenticate", mux))
 }
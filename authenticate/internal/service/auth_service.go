@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/audit"
 	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/password"
 	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/repository"
 	"github.com/telhawk-systems/telhawk-stack/authenticate/pkg/tokens"
 	"github.com/telhawk-systems/telhawk-stack/common/config"
@@ -18,8 +19,10 @@ import (
 )
 
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrInvalidToken       = errors.New("invalid token")
+	ErrInvalidCredentials     = errors.New("invalid credentials")
+	ErrInvalidToken           = errors.New("invalid token")
+	ErrAccountLocked          = errors.New("account locked")
+	ErrPasswordChangeRequired = errors.New("password expired: change required")
 )
 
 // stringOrEmpty returns empty string if the pointer is nil, otherwise the value.
@@ -45,16 +48,20 @@ func inferSourceType(userAgent string) int {
 
 type AuthService struct {
 	repo     repository.Repository
+	pwRepo   repository.PasswordRepository // nil if the repository does not track password state
+	policy   *password.Policy
 	tokenGen *tokens.TokenGenerator
 	auditLog *audit.Logger
 }
 
-func NewAuthService(repo repository.Repository, ingestClient *audit.IngestClient) *AuthService {
+// NewAuthService creates the auth service. It fails if the configured
+// password policy is invalid or repo cannot store audit events.
+func NewAuthService(repo repository.Repository, ingestClient *audit.IngestClient) (*AuthService, error) {
 	cfg := config.GetConfig()
 	var auditLogger *audit.Logger
 	auditRepo, ok := repo.(audit.Repository)
 	if !ok {
-		panic("repository does not implement audit.Repository interface")
+		return nil, errors.New("repository does not implement audit.Repository interface")
 	}
 
 	if ingestClient != nil {
@@ -63,14 +70,128 @@ func NewAuthService(repo repository.Repository, ingestClient *audit.IngestClient
 		auditLogger = audit.NewLoggerWithRepo(cfg.Authenticate.Auth.AuditSecret, auditRepo)
 	}
 
+	policy, err := password.NewPolicy(cfg.Authenticate.PasswordPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid password policy: %w", err)
+	}
+
+	// History, expiry and lockout need persistent state; repositories
+	// without it only get complexity checks.
+	pwRepo, _ := repo.(repository.PasswordRepository)
+
 	return &AuthService{
 		repo:     repo,
+		pwRepo:   pwRepo,
+		policy:   policy,
 		tokenGen: tokens.NewTokenGenerator(cfg.Authenticate.Auth.JWTSecret, cfg.Authenticate.Auth.JWTRefreshSecret),
 		auditLog: auditLogger,
+	}, nil
+}
+
+// checkNewPassword validates a candidate password against the policy and,
+// for existing users, the user's password history.
+func (s *AuthService) checkNewPassword(ctx context.Context, userID, username, newPassword string) error {
+	if err := s.policy.Validate(newPassword, username); err != nil {
+		return err
+	}
+	if s.pwRepo == nil || userID == "" || s.policy.HistoryCount <= 0 {
+		return nil
+	}
+
+	history, err := s.pwRepo.ListPasswordHistory(ctx, userID, s.policy.HistoryCount)
+	if err != nil {
+		return fmt.Errorf("failed to load password history: %w", err)
+	}
+	return s.policy.CheckHistory(newPassword, history)
+}
+
+// recordPasswordChange appends the new hash to the user's password history
+// and restarts the password age. actorID is empty for self-service changes.
+func (s *AuthService) recordPasswordChange(ctx context.Context, userID, passwordHash, actorID, ipAddress, userAgent string) error {
+	if s.pwRepo == nil {
+		return nil
+	}
+
+	entryID, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("failed to generate password history ID: %w", err)
+	}
+	entry := &models.PasswordHistoryEntry{
+		ID:                entryID.String(),
+		UserID:            userID,
+		PasswordHash:      passwordHash,
+		CreatedSourceType: inferSourceType(userAgent),
+	}
+	if actorID != "" {
+		entry.CreatedBy = &actorID
+	}
+	if ipAddress != "" {
+		entry.CreatedFromIP = &ipAddress
+	}
+
+	return s.pwRepo.RecordPasswordChange(ctx, entry, time.Now())
+}
+
+// recordFailedLogin counts a failed password attempt and locks the account
+// once the policy threshold is reached, backing off exponentially. Callers
+// must fail the request if it returns an error: an attempt that cannot be
+// counted would otherwise let password guessing run past the lockout.
+func (s *AuthService) recordFailedLogin(ctx context.Context, user *models.User, ipAddress, userAgent string) error {
+	if s.pwRepo == nil {
+		return nil
+	}
+
+	now := time.Now()
+	attempts, err := s.pwRepo.RecordFailedLogin(ctx, user.ID, now)
+	if err != nil {
+		return fmt.Errorf("record failed login: %w", err)
+	}
+
+	lockout := s.policy.LockoutFor(attempts)
+	if lockout <= 0 {
+		return nil
+	}
+
+	lockedUntil := now.Add(lockout)
+	if err := s.pwRepo.LockUser(ctx, user.ID, lockedUntil); err != nil {
+		return fmt.Errorf("lock user after %d failed attempts: %w", attempts, err)
+	}
+
+	s.auditLog.Log(
+		models.ActorTypeUser, user.ID, user.Username,
+		models.ActionAccountLocked, "user", user.ID,
+		ipAddress, userAgent,
+		models.ResultFailure, fmt.Sprintf("locked after %d failed attempts", attempts),
+		map[string]interface{}{
+			"failed_attempts":  attempts,
+			"locked_until":     lockedUntil.UTC().Format(time.RFC3339),
+			"lockout_duration": lockout.String(),
+		},
+	)
+	return nil
+}
+
+// loginState returns the user's password policy state, or nil when the
+// repository does not track it.
+func (s *AuthService) loginState(ctx context.Context, userID string) (*models.LoginState, error) {
+	if s.pwRepo == nil {
+		return nil, nil
 	}
+	return s.pwRepo.GetLoginState(ctx, userID)
 }
 
 func (s *AuthService) CreateUser(ctx context.Context, req *models.CreateUserRequest, actorID, ipAddress, userAgent string) (*models.User, error) {
+	if err := s.checkNewPassword(ctx, "", req.Username, req.Password); err != nil {
+		s.auditLog.Log(
+			models.ActorTypeUser, actorID, "",
+			models.ActionUserCreate, "user", "",
+			ipAddress, userAgent,
+			models.ResultFailure, err.Error(),
+			map[string]interface{}{"username": req.Username},
+		)
+		return nil, err
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		s.auditLog.Log(
@@ -121,6 +242,10 @@ func (s *AuthService) CreateUser(ctx context.Context, req *models.CreateUserRequ
 		return nil, err
 	}
 
+	if err := s.recordPasswordChange(ctx, user.ID, user.PasswordHash, actorID, ipAddress, userAgent); err != nil {
+		return nil, err
+	}
+
 	s.auditLog.Log(
 		models.ActorTypeUser, actorID, "",
 		models.ActionUserCreate, "user", user.ID,
@@ -160,6 +285,24 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, ipAdd
 		return nil, ErrInvalidCredentials
 	}
 
+	state, err := s.loginState(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if state != nil && state.IsLocked(time.Now()) {
+		s.auditLog.Log(
+			models.ActorTypeUser, user.ID, user.Username,
+			models.ActionLogin, "session", "",
+			ipAddress, userAgent,
+			models.ResultFailure, "account locked",
+			map[string]interface{}{
+				"locked_until": state.LockedUntil.UTC().Format(time.RFC3339),
+			},
+		)
+		return nil, ErrAccountLocked
+	}
+
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
 		s.auditLog.Log(
 			models.ActorTypeUser, user.ID, user.Username,
@@ -168,9 +311,32 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, ipAdd
 			models.ResultFailure, "invalid password",
 			nil,
 		)
+		if err := s.recordFailedLogin(ctx, user, ipAddress, userAgent); err != nil {
+			return nil, err
+		}
 		return nil, ErrInvalidCredentials
 	}
 
+	if state != nil && state.FailedAttempts > 0 {
+		if err := s.pwRepo.ResetFailedLogins(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if state != nil && s.policy.Expired(state.PasswordChangedAt, time.Now()) {
+		s.auditLog.Log(
+			models.ActorTypeUser, user.ID, user.Username,
+			models.ActionPasswordExpired, "session", "",
+			ipAddress, userAgent,
+			models.ResultFailure, "password expired",
+			map[string]interface{}{
+				"password_changed_at": state.PasswordChangedAt.UTC().Format(time.RFC3339),
+				"max_age":             s.policy.MaxAge.String(),
+			},
+		)
+		return nil, ErrPasswordChangeRequired
+	}
+
 	accessToken, err := s.tokenGen.GenerateAccessToken(
 		user.ID, user.Roles, user.PermissionsVersion,
 		stringOrEmpty(user.PrimaryOrganizationID), stringOrEmpty(user.PrimaryClientID),
@@ -448,6 +614,17 @@ func (s *AuthService) ResetPassword(ctx context.Context, userID string, newPassw
 		return err
 	}
 
+	if err := s.checkNewPassword(ctx, user.ID, user.Username, newPassword); err != nil {
+		s.auditLog.Log(
+			models.ActorTypeUser, actorID, "",
+			models.ActionPasswordReset, "user", userID,
+			ipAddress, userAgent,
+			models.ResultFailure, err.Error(),
+			nil,
+		)
+		return err
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
 		s.auditLog.Log(
@@ -473,6 +650,10 @@ func (s *AuthService) ResetPassword(ctx context.Context, userID string, newPassw
 		return err
 	}
 
+	if err := s.recordPasswordChange(ctx, user.ID, user.PasswordHash, actorID, ipAddress, userAgent); err != nil {
+		return err
+	}
+
 	s.auditLog.Log(
 		models.ActorTypeUser, actorID, "",
 		models.ActionPasswordReset, "user", userID,
@@ -484,6 +665,98 @@ func (s *AuthService) ResetPassword(ctx context.Context, userID string, newPassw
 	return nil
 }
 
+// ChangePassword lets a user replace their own password by proving the
+// current one. It is the only way to clear an expired password, so it
+// does not require a session.
+func (s *AuthService) ChangePassword(ctx context.Context, req *models.ChangePasswordRequest, ipAddress, userAgent string) error {
+	user, err := s.repo.GetUserByUsername(ctx, req.Username)
+	if err != nil || !user.IsActive() {
+		s.auditLog.Log(
+			models.ActorTypeUser, "", req.Username,
+			models.ActionPasswordChange, "user", "",
+			ipAddress, userAgent,
+			models.ResultFailure, "user not found or inactive",
+			nil,
+		)
+		return ErrInvalidCredentials
+	}
+
+	state, err := s.loginState(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if state != nil && state.IsLocked(time.Now()) {
+		s.auditLog.Log(
+			models.ActorTypeUser, user.ID, user.Username,
+			models.ActionPasswordChange, "user", user.ID,
+			ipAddress, userAgent,
+			models.ResultFailure, "account locked",
+			nil,
+		)
+		return ErrAccountLocked
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)); err != nil {
+		s.auditLog.Log(
+			models.ActorTypeUser, user.ID, user.Username,
+			models.ActionPasswordChange, "user", user.ID,
+			ipAddress, userAgent,
+			models.ResultFailure, "invalid current password",
+			nil,
+		)
+		if err := s.recordFailedLogin(ctx, user, ipAddress, userAgent); err != nil {
+			return err
+		}
+		return ErrInvalidCredentials
+	}
+
+	checkErr := s.checkNewPassword(ctx, user.ID, user.Username, req.NewPassword)
+	if checkErr == nil && req.NewPassword == req.CurrentPassword {
+		checkErr = password.ErrPasswordReused
+	}
+	if checkErr != nil {
+		s.auditLog.Log(
+			models.ActorTypeUser, user.ID, user.Username,
+			models.ActionPasswordChange, "user", user.ID,
+			ipAddress, userAgent,
+			models.ResultFailure, checkErr.Error(),
+			nil,
+		)
+		return checkErr
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(hashedPassword)
+
+	if err := s.repo.UpdateUser(ctx, user); err != nil {
+		s.auditLog.Log(
+			models.ActorTypeUser, user.ID, user.Username,
+			models.ActionPasswordChange, "user", user.ID,
+			ipAddress, userAgent,
+			models.ResultFailure, err.Error(),
+			nil,
+		)
+		return err
+	}
+
+	if err := s.recordPasswordChange(ctx, user.ID, user.PasswordHash, "", ipAddress, userAgent); err != nil {
+		return err
+	}
+
+	s.auditLog.Log(
+		models.ActorTypeUser, user.ID, user.Username,
+		models.ActionPasswordChange, "user", user.ID,
+		ipAddress, userAgent,
+		models.ResultSuccess, "",
+		nil,
+	)
+
+	return nil
+}
+
 func (s *AuthService) CreateHECToken(ctx context.Context, userID, clientID, name, expiresIn, ipAddress, userAgent string) (*models.HECToken, error) {
 	if clientID == "" {
 		return nil, fmt.Errorf("client_id is required for HEC token creation")
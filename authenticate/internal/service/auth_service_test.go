@@ -280,7 +280,10 @@ func setupTestService() (*AuthService, *mockRepository) {
 	cfg.Authenticate.Auth.AuditSecret = "test-audit-secret"
 
 	repo := newMockRepository()
-	service := NewAuthService(repo, nil)
+	service, err := NewAuthService(repo, nil)
+	if err != nil {
+		panic(err)
+	}
 	return service, repo
 }
 
@@ -302,7 +305,7 @@ func TestCreateUser(t *testing.T) {
 			request: &models.CreateUserRequest{
 				Username: "testuser",
 				Email:    "test@example.com",
-				Password: "password123",
+				Password: "Password12345",
 				Roles:    []string{"viewer"}},
 			setupRepo:   func(m *mockRepository) {},
 			expectError: false,
@@ -317,7 +320,7 @@ func TestCreateUser(t *testing.T) {
 					t.Errorf("Expected roles [viewer], got %v", user.Roles)
 				}
 				// Verify password was hashed
-				err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("password123"))
+				err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("Password12345"))
 				if err != nil {
 					t.Errorf("Password was not hashed correctly: %v", err)
 				}
@@ -327,7 +330,7 @@ func TestCreateUser(t *testing.T) {
 			request: &models.CreateUserRequest{
 				Username: "testuser",
 				Email:    "test@example.com",
-				Password: "password123"},
+				Password: "Password12345"},
 			setupRepo:   func(m *mockRepository) {},
 			expectError: false,
 			validateUser: func(t *testing.T, user *models.User) {
@@ -340,7 +343,7 @@ func TestCreateUser(t *testing.T) {
 			request: &models.CreateUserRequest{
 				Username: "existing",
 				Email:    "new@example.com",
-				Password: "password123"},
+				Password: "Password12345"},
 			setupRepo: func(m *mockRepository) {
 				// Pre-create a user with same username
 				existingUser := &models.User{
@@ -357,7 +360,7 @@ func TestCreateUser(t *testing.T) {
 			request: &models.CreateUserRequest{
 				Username: "testuser",
 				Email:    "test@example.com",
-				Password: "password123"},
+				Password: "Password12345"},
 			setupRepo: func(m *mockRepository) {
 				m.createUserErr = errors.New("database error")
 			},
@@ -986,7 +989,7 @@ func TestResetPassword(t *testing.T) {
 		{
 			name:        "successful password reset",
 			userID:      "user-123",
-			newPassword: "newpassword456",
+			newPassword: "NewPassword456",
 			setupRepo: func(m *mockRepository) {
 				m.users["user-123"] = &models.User{
 					ID:           "user-123",
@@ -997,7 +1000,7 @@ func TestResetPassword(t *testing.T) {
 		{
 			name:        "user not found",
 			userID:      "nonexistent",
-			newPassword: "newpassword456",
+			newPassword: "NewPassword456",
 			setupRepo:   func(m *mockRepository) {},
 			expectError: true}}
 
@@ -1451,7 +1454,7 @@ func TestResetPassword_UpdateUserError(t *testing.T) {
 	err := service.ResetPassword(
 		context.Background(),
 		"user-123",
-		"newpassword123",
+		"NewPassword123",
 		"admin-id",
 		"192.168.1.1",
 		"test-agent",
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/password"
+	"github.com/telhawk-systems/telhawk-stack/authenticate/internal/repository"
+	"github.com/telhawk-systems/telhawk-stack/common/config"
+)
+
+// TestMain loads the default configuration from an empty config file so
+// tests do not depend on /etc/telhawk.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "telhawk-config")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), nil, 0o600); err != nil {
+		panic(err)
+	}
+	os.Setenv("TELHAWK_CONFIG_DIR", dir)
+	config.MustLoad("authenticate")
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+const (
+	policyTestUser     = "policyuser"
+	policyTestPassword = "Correct-Horse-1"
+)
+
+// setupPolicyService creates a service over the in-memory repository, which
+// tracks login state and password history, with one active user.
+func setupPolicyService(t *testing.T, policy func(*config.PasswordPolicyConfig)) (*AuthService, *repository.InMemoryRepository, *models.User) {
+	t.Helper()
+
+	cfg := config.GetConfig()
+	cfg.Authenticate.Auth.JWTSecret = "test-jwt-secret-that-is-long-enough-for-hs256"
+	cfg.Authenticate.Auth.JWTRefreshSecret = "test-refresh-secret-that-is-long-enough-for-hs256"
+	cfg.Authenticate.Auth.AuditSecret = "test-audit-secret"
+
+	saved := cfg.Authenticate.PasswordPolicy
+	t.Cleanup(func() { cfg.Authenticate.PasswordPolicy = saved })
+	if policy != nil {
+		policy(&cfg.Authenticate.PasswordPolicy)
+	}
+
+	repo := repository.NewInMemoryRepository()
+	svc, err := NewAuthService(repo, nil)
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+
+	user, err := svc.CreateUser(context.Background(), &models.CreateUserRequest{
+		Username: policyTestUser,
+		Email:    "policy@example.com",
+		Password: policyTestPassword,
+	}, "", "", "")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return svc, repo, user
+}
+
+func login(svc *AuthService, pass string) error {
+	_, err := svc.Login(context.Background(), &models.LoginRequest{
+		Username: policyTestUser,
+		Password: pass,
+	}, "127.0.0.1", "test")
+	return err
+}
+
+func TestNewAuthService_InvalidPolicy(t *testing.T) {
+	cfg := config.GetConfig()
+	saved := cfg.Authenticate.PasswordPolicy
+	defer func() { cfg.Authenticate.PasswordPolicy = saved }()
+	cfg.Authenticate.PasswordPolicy.CommonPasswordsFile = filepath.Join(t.TempDir(), "missing.txt")
+
+	svc, err := NewAuthService(repository.NewInMemoryRepository(), nil)
+	if err == nil {
+		t.Fatal("expected an error for an invalid password policy")
+	}
+	if svc != nil {
+		t.Error("expected no service for an invalid password policy")
+	}
+}
+
+func TestLogin_LocksAfterThreshold(t *testing.T) {
+	svc, repo, user := setupPolicyService(t, func(p *config.PasswordPolicyConfig) {
+		p.LockoutThreshold = 3
+		p.LockoutDuration = time.Minute
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := login(svc, "wrong-password"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: err = %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+
+	// The correct password is refused while locked
+	if err := login(svc, policyTestPassword); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("err = %v, want ErrAccountLocked", err)
+	}
+
+	state, err := repo.GetLoginState(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("GetLoginState: %v", err)
+	}
+	if state.FailedAttempts != 3 || state.LockedUntil == nil {
+		t.Errorf("state = %+v, want 3 failed attempts and a lockout", state)
+	}
+}
+
+// failingLockoutRepo fails to record failed logins or to lock users.
+type failingLockoutRepo struct {
+	*repository.InMemoryRepository
+	recordErr error
+	lockErr   error
+}
+
+func (r *failingLockoutRepo) RecordFailedLogin(ctx context.Context, userID string, at time.Time) (int, error) {
+	if r.recordErr != nil {
+		return 0, r.recordErr
+	}
+	return r.InMemoryRepository.RecordFailedLogin(ctx, userID, at)
+}
+
+func (r *failingLockoutRepo) LockUser(ctx context.Context, userID string, until time.Time) error {
+	if r.lockErr != nil {
+		return r.lockErr
+	}
+	return r.InMemoryRepository.LockUser(ctx, userID, until)
+}
+
+func TestLogin_FailsClosedWhenLockoutCannotBeRecorded(t *testing.T) {
+	storeErr := errors.New("database unavailable")
+	tests := []struct {
+		name string
+		repo func(*repository.InMemoryRepository) *failingLockoutRepo
+	}{
+		{"recording the attempt fails", func(r *repository.InMemoryRepository) *failingLockoutRepo {
+			return &failingLockoutRepo{InMemoryRepository: r, recordErr: storeErr}
+		}},
+		{"locking the account fails", func(r *repository.InMemoryRepository) *failingLockoutRepo {
+			return &failingLockoutRepo{InMemoryRepository: r, lockErr: storeErr}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _ := setupPolicyService(t, func(p *config.PasswordPolicyConfig) {
+				p.LockoutThreshold = 1
+				p.LockoutDuration = time.Minute
+			})
+			svc.pwRepo = tt.repo(repo)
+
+			if err := login(svc, "wrong-password"); !errors.Is(err, storeErr) {
+				t.Errorf("Login err = %v, want the repository error", err)
+			}
+			err := svc.ChangePassword(context.Background(), &models.ChangePasswordRequest{
+				Username:        policyTestUser,
+				CurrentPassword: "wrong-password",
+				NewPassword:     "Another-Horse-2",
+			}, "127.0.0.1", "test")
+			if !errors.Is(err, storeErr) {
+				t.Errorf("ChangePassword err = %v, want the repository error", err)
+			}
+		})
+	}
+}
+
+func TestLogin_BelowThresholdDoesNotLock(t *testing.T) {
+	svc, _, _ := setupPolicyService(t, func(p *config.PasswordPolicyConfig) {
+		p.LockoutThreshold = 3
+	})
+
+	for i := 0; i < 2; i++ {
+		_ = login(svc, "wrong-password")
+	}
+	if err := login(svc, policyTestPassword); err != nil {
+		t.Fatalf("login below threshold: %v", err)
+	}
+}
+
+func TestLogin_LockoutExpires(t *testing.T) {
+	svc, repo, user := setupPolicyService(t, func(p *config.PasswordPolicyConfig) {
+		p.LockoutThreshold = 2
+		p.LockoutDuration = time.Minute
+	})
+
+	for i := 0; i < 2; i++ {
+		_ = login(svc, "wrong-password")
+	}
+	if err := login(svc, policyTestPassword); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("err = %v, want ErrAccountLocked", err)
+	}
+
+	// Move the lockout into the past
+	if err := repo.LockUser(context.Background(), user.ID, time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("LockUser: %v", err)
+	}
+
+	if err := login(svc, policyTestPassword); err != nil {
+		t.Fatalf("login after lockout expired: %v", err)
+	}
+
+	state, err := repo.GetLoginState(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("GetLoginState: %v", err)
+	}
+	if state.FailedAttempts != 0 || state.LockedUntil != nil {
+		t.Errorf("state = %+v, want counters reset after a successful login", state)
+	}
+}
+
+func TestChangePassword_RejectsReuse(t *testing.T) {
+	svc, _, _ := setupPolicyService(t, func(p *config.PasswordPolicyConfig) {
+		p.HistoryCount = 3
+	})
+	ctx := context.Background()
+
+	second := "Battery-Staple-2"
+	if err := svc.ChangePassword(ctx, &models.ChangePasswordRequest{
+		Username:        policyTestUser,
+		CurrentPassword: policyTestPassword,
+		NewPassword:     second,
+	}, "", ""); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+
+	// The first password is still in the history
+	err := svc.ChangePassword(ctx, &models.ChangePasswordRequest{
+		Username:        policyTestUser,
+		CurrentPassword: second,
+		NewPassword:     policyTestPassword,
+	}, "", "")
+	if !errors.Is(err, password.ErrPasswordReused) {
+		t.Fatalf("err = %v, want ErrPasswordReused", err)
+	}
+
+	// The rejected change left the current password in place
+	if err := login(svc, second); err != nil {
+		t.Fatalf("login with current password: %v", err)
+	}
+}
+
+func TestLogin_ExpiredPasswordRequiresChange(t *testing.T) {
+	svc, repo, user := setupPolicyService(t, func(p *config.PasswordPolicyConfig) {
+		p.MaxAge = 24 * time.Hour
+	})
+	ctx := context.Background()
+
+	// Age the password past the maximum
+	if err := repo.RecordPasswordChange(ctx, &models.PasswordHistoryEntry{
+		ID:           "backdated",
+		UserID:       user.ID,
+		PasswordHash: user.PasswordHash,
+	}, time.Now().Add(-48*time.Hour)); err != nil {
+		t.Fatalf("RecordPasswordChange: %v", err)
+	}
+
+	if err := login(svc, policyTestPassword); !errors.Is(err, ErrPasswordChangeRequired) {
+		t.Fatalf("err = %v, want ErrPasswordChangeRequired", err)
+	}
+
+	newPassword := "Battery-Staple-2"
+	if err := svc.ChangePassword(ctx, &models.ChangePasswordRequest{
+		Username:        policyTestUser,
+		CurrentPassword: policyTestPassword,
+		NewPassword:     newPassword,
+	}, "", ""); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+
+	if err := login(svc, newPassword); err != nil {
+		t.Fatalf("login after change: %v", err)
+	}
+}
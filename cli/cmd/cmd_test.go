@@ -19,16 +19,19 @@ func TestCommandsRegistered(t *testing.T) {
 	// Check that all main commands are registered
 	commands := rootCmd.Commands()
 	expectedCommands := map[string]bool{
-		"login":  false,
-		"logout": false,
-		"whoami": false,
-		"alerts": false,
-		"rules":  false,
-		"search": false,
-		"ingest": false,
-		"token":  false,
-		"user":   false,
-		"seeder": false,
+		"login":       false,
+		"logout":      false,
+		"whoami":      false,
+		"alerts":      false,
+		"rules":       false,
+		"search":      false,
+		"ingest":      false,
+		"token":       false,
+		"user":        false,
+		"seeder":      false,
+		"findings":    false,
+		"dlq":         false,
+		"normalizers": false,
 	}
 
 	for _, cmd := range commands {
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/client"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/seeder"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/verify"
+	"github.com/telhawk-systems/telhawk-stack/cli/pkg/attacks"
+	"github.com/telhawk-systems/telhawk-stack/cli/pkg/output"
+)
+
+var detectionsCmd = &cobra.Command{
+	Use:   "detections",
+	Short: "Detection coverage commands",
+	Long:  "Validate that detection rules fire on the activity they are written for",
+}
+
+var detectionsVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify detection rules end to end",
+	Long: `Seed events for each detection rule (or an attack scenario), wait for
+correlation, then look for the resulting alerts and report pass/fail per rule
+and ATT&CK technique.
+
+Seeded events carry a unique run marker in metadata.correlation_uid and
+metadata.tags. Rules whose correlation type cannot be generated yet are
+reported as skipped. The command exits non-zero when a detection fails.
+
+Targets:
+  (default)  Send events to HEC and read alerts from the respond service
+  --local    Evaluate rules in process without a running stack
+
+Examples:
+  # Verify all built-in rules against the docker-compose stack
+  thawk detections verify --token <hec-token> --format junit --report verify.xml
+
+  # Verify selected rules without a stack
+  thawk detections verify --local --rule failed_logins,port_scanning
+
+  # Check which techniques of a kill chain are detected
+  thawk detections verify --local --scenario cli/scenarios/finance-workstation-compromise.yaml`,
+	RunE: runDetectionsVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(detectionsCmd)
+	detectionsCmd.AddCommand(detectionsVerifyCmd)
+
+	detectionsVerifyCmd.Flags().String("rules", "alerting/dist/rules", "Directory or file of detection rule JSON")
+	detectionsVerifyCmd.Flags().StringSlice("rule", nil, "Only verify these rule names")
+	detectionsVerifyCmd.Flags().String("scenario", "", "Attack scenario YAML to run instead of per-rule seeding")
+	detectionsVerifyCmd.Flags().Bool("local", false, "Use the in-process stand-in instead of a running stack")
+	detectionsVerifyCmd.Flags().String("hec-url", "", "HEC endpoint URL (default from seeder config)")
+	detectionsVerifyCmd.Flags().StringP("token", "t", "", "HEC authentication token (default from seeder config or HEC_TOKEN)")
+	detectionsVerifyCmd.Flags().Duration("wait", 2*time.Minute, "How long to wait for alerts after seeding")
+	detectionsVerifyCmd.Flags().Duration("poll", 10*time.Second, "Alert polling interval")
+	detectionsVerifyCmd.Flags().Float64("multiplier", 1.5, "Exceed rule thresholds by this factor")
+	detectionsVerifyCmd.Flags().String("format", "json", "Report format: json, junit")
+	detectionsVerifyCmd.Flags().String("report", "", "Write the report to this file instead of stdout")
+}
+
+func runDetectionsVerify(cmd *cobra.Command, args []string) error {
+	rulesPath, _ := cmd.Flags().GetString("rules")
+	ruleNames, _ := cmd.Flags().GetStringSlice("rule")
+	scenarioPath, _ := cmd.Flags().GetString("scenario")
+	local, _ := cmd.Flags().GetBool("local")
+	wait, _ := cmd.Flags().GetDuration("wait")
+	poll, _ := cmd.Flags().GetDuration("poll")
+	multiplier, _ := cmd.Flags().GetFloat64("multiplier")
+	format, _ := cmd.Flags().GetString("format")
+	reportPath, _ := cmd.Flags().GetString("report")
+
+	if format != "json" && format != "junit" {
+		return fmt.Errorf("invalid format %q: must be json or junit", format)
+	}
+
+	rules, err := seeder.LoadRulesFromDirectory(rulesPath)
+	if err != nil {
+		return fmt.Errorf("failed to load rules: %w", err)
+	}
+	if len(ruleNames) > 0 {
+		rules, err = selectRules(rules, ruleNames)
+		if err != nil {
+			return err
+		}
+	}
+
+	harness := &verify.Harness{
+		Rules:        rules,
+		Wait:         wait,
+		PollInterval: poll,
+		Multiplier:   multiplier,
+	}
+
+	if local {
+		harness.Target = verify.NewLocalTarget(rules)
+		harness.Wait = 0
+	} else {
+		target, err := newStackTarget(cmd)
+		if err != nil {
+			return err
+		}
+		harness.Target = target
+	}
+
+	var report *verify.Report
+	if scenarioPath != "" {
+		scenario, err := attacks.LoadScenario(scenarioPath)
+		if err != nil {
+			return err
+		}
+		report, err = harness.VerifyScenario(cmd.Context(), scenario)
+		if err != nil {
+			return err
+		}
+	} else {
+		report, err = harness.VerifyRules(cmd.Context())
+		if err != nil {
+			return err
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if reportPath != "" {
+		f, err := os.Create(reportPath)
+		if err != nil {
+			return fmt.Errorf("failed to create report: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if format == "junit" {
+		err = report.WriteJUnit(w)
+	} else {
+		err = report.WriteJSON(w)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if reportPath != "" {
+		printVerifySummary(report)
+		output.Info("Report written to %s", reportPath)
+	}
+
+	if !report.Passed() {
+		return fmt.Errorf("%d detections failed verification (%d errors)", report.Summary.Failed, report.Summary.Errors)
+	}
+	return nil
+}
+
+// newStackTarget builds a target from the seeder config (HEC) and the CLI
+// profile (alerts)
+func newStackTarget(cmd *cobra.Command) (*verify.StackTarget, error) {
+	profile, _ := cmd.Flags().GetString("profile")
+	p, err := cfg.GetProfile(profile)
+	if err != nil {
+		return nil, fmt.Errorf("not logged in: %w", err)
+	}
+
+	config, err := seeder.LoadConfig("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seeder config: %w", err)
+	}
+	if cmd.Flags().Changed("hec-url") {
+		config.Defaults.HECURL, _ = cmd.Flags().GetString("hec-url")
+	}
+	if cmd.Flags().Changed("token") {
+		config.Defaults.Token, _ = cmd.Flags().GetString("token")
+	}
+	if config.Defaults.Token == "" {
+		config.Defaults.Token = os.Getenv("HEC_TOKEN")
+	}
+	if config.Defaults.Token == "" {
+		return nil, fmt.Errorf("HEC token is required (use --token flag, set HEC_TOKEN env var, or set in seeder config)")
+	}
+
+	alerts := client.NewAlertingClient(cfg.GetAlertingURL(profile))
+	return verify.NewStackTarget(seeder.NewRunner(config), alerts, p.AccessToken), nil
+}
+
+// selectRules keeps the named rules, failing on names that do not exist
+func selectRules(rules []*seeder.DetectionRule, names []string) ([]*seeder.DetectionRule, error) {
+	byName := make(map[string]*seeder.DetectionRule, len(rules))
+	for _, rule := range rules {
+		byName[rule.Name] = rule
+	}
+
+	selected := make([]*seeder.DetectionRule, 0, len(names))
+	for _, name := range names {
+		rule, ok := byName[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("rule %q not found", name)
+		}
+		selected = append(selected, rule)
+	}
+	return selected, nil
+}
+
+func printVerifySummary(report *verify.Report) {
+	table := output.NewTable([]string{"Detection", "Techniques", "Status", "Events", "Message"})
+	for _, result := range report.Results {
+		table.AddRow([]string{
+			result.Name,
+			strings.Join(result.Techniques, ","),
+			string(result.Status),
+			fmt.Sprintf("%d", result.Events),
+			result.Message,
+		})
+	}
+	table.Render()
+
+	output.Info("\nRun %s (%s): %d passed, %d failed, %d skipped, %d errors",
+		report.RunID, report.Target, report.Summary.Passed, report.Summary.Failed,
+		report.Summary.Skipped, report.Summary.Errors)
+}
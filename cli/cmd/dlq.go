@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/client"
+	"github.com/telhawk-systems/telhawk-stack/cli/pkg/output"
+)
+
+var dlqCmd = &cobra.Command{
+	Use:   "dlq",
+	Short: "Ingest dead-letter queue",
+	Long:  "Inspect, replay and purge events that failed ingestion (requires an admin login)",
+}
+
+var dlqListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List failed events",
+	Example: `  thawk dlq list --sourcetype syslog --since 24h
+  thawk dlq list --reason storage_failed --status pending`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, _ := cmd.Flags().GetString("profile")
+		p, err := cfg.GetProfile(profile)
+		if err != nil {
+			return fmt.Errorf("not logged in: %w", err)
+		}
+
+		filters := dlqFilters(cmd)
+		if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 {
+			filters["limit"] = fmt.Sprintf("%d", limit)
+		}
+
+		dlqClient := client.NewDLQClient(cfg.GetIngestURL(profile))
+		events, err := dlqClient.List(p.AccessToken, filters)
+		if err != nil {
+			return err
+		}
+
+		outputFormat, _ := cmd.Flags().GetString("output")
+		if outputFormat == "json" {
+			return output.JSON(events)
+		}
+
+		if len(events) == 0 {
+			output.Info("No failed events found")
+			return nil
+		}
+
+		table := output.NewTable([]string{"ID", "Failed At", "Reason", "Sourcetype", "Client", "Status", "Attempts", "Error"})
+		for _, e := range events {
+			table.AddRow([]string{
+				e.ID,
+				e.Timestamp.Format("2006-01-02 15:04:05"),
+				e.Reason,
+				e.SourceType,
+				e.ClientID,
+				e.Status,
+				fmt.Sprintf("%d", e.Attempts),
+				truncate(e.Error, 60),
+			})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+var dlqShowCmd = &cobra.Command{
+	Use:   "show [id]",
+	Short: "Show a failed event with its raw payload and replay history",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, _ := cmd.Flags().GetString("profile")
+		p, err := cfg.GetProfile(profile)
+		if err != nil {
+			return fmt.Errorf("not logged in: %w", err)
+		}
+
+		dlqClient := client.NewDLQClient(cfg.GetIngestURL(profile))
+		e, err := dlqClient.Get(p.AccessToken, args[0])
+		if err != nil {
+			return err
+		}
+
+		outputFormat, _ := cmd.Flags().GetString("output")
+		if outputFormat == "json" {
+			return output.JSON(e)
+		}
+
+		output.Info("ID: %s", e.ID)
+		output.Info("Failed At: %s", e.Timestamp.Format("2006-01-02 15:04:05"))
+		output.Info("Reason: %s", e.Reason)
+		output.Info("Error: %s", e.Error)
+		output.Info("Status: %s", e.Status)
+		output.Info("Attempts: %d", e.Attempts)
+		output.Info("Sourcetype: %s", e.SourceType)
+		output.Info("Source: %s", e.Source)
+		output.Info("Client: %s", e.ClientID)
+		output.Info("HEC Token: %s", e.HECTokenID)
+
+		if e.Envelope != nil {
+			output.Info("\nPayload:")
+			fmt.Println(prettyPayload(e.Envelope.Payload))
+		}
+
+		if len(e.Replays) > 0 {
+			output.Info("\nReplays:")
+			table := output.NewTable([]string{"When", "Dry Run", "Result", "Error"})
+			for _, r := range e.Replays {
+				result := "failed"
+				if r.Success {
+					result = "ok"
+				}
+				table.AddRow([]string{
+					r.At.Format("2006-01-02 15:04:05"),
+					fmt.Sprintf("%t", r.DryRun),
+					result,
+					truncate(r.Error, 60),
+				})
+			}
+			table.Render()
+		}
+		return nil
+	},
+}
+
+var dlqReplayCmd = &cobra.Command{
+	Use:   "replay [id...]",
+	Short: "Reprocess failed events through the current pipeline",
+	Long: `Replay failed events through the current normalization pipeline and
+store the results. Pass event IDs, or use filters to select pending events.
+Use --dry-run to preview the normalized output and a diff against the
+output of the previous attempt without storing anything. The first replay
+of an event has no earlier output, so only the full result is shown.`,
+	Example: `  thawk dlq replay 5f1c... --dry-run
+  thawk dlq replay --sourcetype syslog --since 24h
+  thawk dlq replay --reason storage_failed --force`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, _ := cmd.Flags().GetString("profile")
+		p, err := cfg.GetProfile(profile)
+		if err != nil {
+			return fmt.Errorf("not logged in: %w", err)
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+		filters := dlqFilters(cmd)
+		if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 {
+			filters["limit"] = fmt.Sprintf("%d", limit)
+		}
+
+		dlqClient := client.NewDLQClient(cfg.GetIngestURL(profile))
+		report, err := dlqClient.Replay(p.AccessToken, client.DLQReplayRequest{
+			IDs:    args,
+			Filter: filters,
+			DryRun: dryRun,
+			Force:  force,
+		})
+		if err != nil {
+			return err
+		}
+
+		outputFormat, _ := cmd.Flags().GetString("output")
+		if outputFormat == "json" {
+			return output.JSON(report)
+		}
+
+		if report.Total == 0 {
+			output.Info("No events matched")
+			return nil
+		}
+
+		table := output.NewTable([]string{"ID", "Status", "Error", "Changes"})
+		for _, o := range report.Outcomes {
+			changes := ""
+			if report.DryRun && o.DiffBase != nil {
+				changes = fmt.Sprintf("%d", len(o.Diff))
+			} else if report.DryRun && o.Event != nil {
+				changes = "first output"
+			}
+			table.AddRow([]string{o.ID, o.Status, truncate(o.Error, 60), changes})
+		}
+		table.Render()
+
+		if report.DryRun {
+			showDiff, _ := cmd.Flags().GetBool("diff")
+			if showDiff {
+				for _, o := range report.Outcomes {
+					if len(o.Diff) == 0 {
+						continue
+					}
+					output.Info("\n%s:", o.ID)
+					for _, c := range o.Diff {
+						fmt.Println(formatFieldChange(c))
+					}
+				}
+			}
+			output.Info("\nDry run: %d would replay, %d would fail, %d skipped", report.Succeeded, report.Failed, report.Skipped)
+			return nil
+		}
+
+		if report.Failed > 0 {
+			output.Warn("\n%d replayed, %d failed, %d skipped", report.Succeeded, report.Failed, report.Skipped)
+			return nil
+		}
+		output.Success("\n%d replayed, %d skipped", report.Succeeded, report.Skipped)
+		return nil
+	},
+}
+
+var dlqPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete failed events",
+	Long:  "Delete failed events matching the filters. Use --all to purge the entire queue.",
+	Example: `  thawk dlq purge --status replayed --force
+  thawk dlq purge --all --force`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		all, _ := cmd.Flags().GetBool("all")
+		filters := dlqFilters(cmd)
+
+		if len(filters) == 0 && !all {
+			return fmt.Errorf("specify filters or --all")
+		}
+		if !force {
+			return fmt.Errorf("use --force to confirm DLQ purge")
+		}
+
+		profile, _ := cmd.Flags().GetString("profile")
+		p, err := cfg.GetProfile(profile)
+		if err != nil {
+			return fmt.Errorf("not logged in: %w", err)
+		}
+
+		dlqClient := client.NewDLQClient(cfg.GetIngestURL(profile))
+		deleted, err := dlqClient.Purge(p.AccessToken, filters, all && len(filters) == 0)
+		if err != nil {
+			return err
+		}
+
+		output.Success("Purged %d events", deleted)
+		return nil
+	},
+}
+
+// dlqFilters collects the shared DLQ filter flags into query parameters.
+func dlqFilters(cmd *cobra.Command) map[string]string {
+	filters := make(map[string]string)
+	for _, name := range []string{"reason", "sourcetype", "token", "client-id", "status", "since", "until"} {
+		if v, _ := cmd.Flags().GetString(name); v != "" {
+			key := name
+			if name == "client-id" {
+				key = "client_id"
+			}
+			filters[key] = v
+		}
+	}
+	return filters
+}
+
+func formatFieldChange(c client.DLQFieldChange) string {
+	switch c.Op {
+	case "added":
+		return fmt.Sprintf("  + %s: %v", c.Path, c.After)
+	case "removed":
+		return fmt.Sprintf("  - %s: %v", c.Path, c.Before)
+	default:
+		return fmt.Sprintf("  ~ %s: %v -> %v", c.Path, c.Before, c.After)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n-3] + "..."
+	}
+	return s
+}
+
+// prettyPayload indents JSON payloads and returns anything else unchanged.
+func prettyPayload(payload string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(payload), &v); err != nil {
+		return payload
+	}
+	pretty, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return payload
+	}
+	return string(pretty)
+}
+
+func init() {
+	rootCmd.AddCommand(dlqCmd)
+	dlqCmd.AddCommand(dlqListCmd)
+	dlqCmd.AddCommand(dlqShowCmd)
+	dlqCmd.AddCommand(dlqReplayCmd)
+	dlqCmd.AddCommand(dlqPurgeCmd)
+
+	for _, c := range []*cobra.Command{dlqListCmd, dlqReplayCmd, dlqPurgeCmd} {
+		c.Flags().String("reason", "", "Filter by failure reason (normalization_failed, storage_failed, ...)")
+		c.Flags().String("sourcetype", "", "Filter by sourcetype")
+		c.Flags().String("token", "", "Filter by HEC token ID")
+		c.Flags().String("client-id", "", "Filter by client ID")
+		c.Flags().String("status", "", "Filter by status (pending, replayed)")
+		c.Flags().String("since", "", "Only events that failed at or after this time (RFC3339 or duration, e.g. 24h)")
+		c.Flags().String("until", "", "Only events that failed before this time (RFC3339 or duration)")
+	}
+
+	dlqListCmd.Flags().IntP("limit", "l", 100, "Maximum events to return (max 1000)")
+
+	dlqReplayCmd.Flags().Bool("dry-run", false, "Normalize without storing and show what would change")
+	dlqReplayCmd.Flags().Bool("diff", false, "With --dry-run, print field-level changes per event")
+	dlqReplayCmd.Flags().Bool("force", false, "Also replay events that were already replayed")
+	dlqReplayCmd.Flags().IntP("limit", "l", 0, "Maximum events to replay when selecting by filter")
+
+	dlqPurgeCmd.Flags().Bool("all", false, "Purge every event in the queue")
+	dlqPurgeCmd.Flags().BoolP("force", "f", false, "Confirm the purge")
+}
@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/client"
+	"github.com/telhawk-systems/telhawk-stack/cli/pkg/output"
+)
+
+var findingsCmd = &cobra.Command{
+	Use:   "findings",
+	Short: "Code-scan findings",
+	Long:  "Query findings reported by code scanners (semgrep, gosec, trivy, CodeQL) per project",
+}
+
+var findingsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List findings",
+	Long: `List findings with optional filters. Results are cursor paginated;
+pass --all to follow the cursor through every page.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, _ := cmd.Flags().GetString("profile")
+		p, err := cfg.GetProfile(profile)
+		if err != nil {
+			return fmt.Errorf("not logged in: %w", err)
+		}
+
+		findingsClient := client.NewFindingsClient(cfg.GetFindingsURL(profile))
+
+		filters := findingsFilters(cmd)
+		all, _ := cmd.Flags().GetBool("all")
+
+		var findings []client.Finding
+		var next string
+		for {
+			page, err := findingsClient.ListFindings(p.AccessToken, filters)
+			if err != nil {
+				return err
+			}
+			findings = append(findings, page.Data...)
+			next = page.NextCursor
+			if !all || next == "" {
+				break
+			}
+			filters["cursor"] = next
+		}
+
+		outputFormat, _ := cmd.Flags().GetString("output")
+		if outputFormat == "json" {
+			return output.JSON(findings)
+		}
+
+		if len(findings) == 0 {
+			output.Info("No findings found")
+			return nil
+		}
+
+		table := output.NewTable([]string{"ID", "Project", "Tool", "Severity", "Status", "Location", "Last Seen"})
+		for _, f := range findings {
+			table.AddRow([]string{
+				f.ID,
+				f.Project,
+				f.Tool,
+				deref(f.Severity),
+				f.Status,
+				findingLocation(f),
+				f.LastSeen.Format("2006-01-02 15:04"),
+			})
+		}
+		table.Render()
+
+		if next != "" {
+			output.Info("\nMore results available: --cursor %s", next)
+		}
+		return nil
+	},
+}
+
+var findingsGetCmd = &cobra.Command{
+	Use:   "get [id]",
+	Short: "Get finding details and history",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, _ := cmd.Flags().GetString("profile")
+		p, err := cfg.GetProfile(profile)
+		if err != nil {
+			return fmt.Errorf("not logged in: %w", err)
+		}
+
+		findingsClient := client.NewFindingsClient(cfg.GetFindingsURL(profile))
+		f, err := findingsClient.GetFinding(p.AccessToken, args[0])
+		if err != nil {
+			return err
+		}
+
+		outputFormat, _ := cmd.Flags().GetString("output")
+		if outputFormat == "json" {
+			return output.JSON(f)
+		}
+
+		output.Info("Finding ID: %s", f.ID)
+		output.Info("Project: %s", f.Project)
+		output.Info("Tool: %s", f.Tool)
+		output.Info("Type: %s", f.SignalType)
+		output.Info("Severity: %s", deref(f.Severity))
+		output.Info("Category: %s", deref(f.Category))
+		output.Info("Status: %s", f.Status)
+		output.Info("Location: %s", findingLocation(f.Finding))
+		output.Info("First Seen: %s", f.FirstSeen.Format("2006-01-02 15:04:05"))
+		output.Info("Last Seen: %s", f.LastSeen.Format("2006-01-02 15:04:05"))
+		if f.ReintroducedCount > 0 {
+			output.Info("Reintroduced: %d times", f.ReintroducedCount)
+		}
+
+		if len(f.History) > 0 {
+			output.Info("\nHistory:")
+			table := output.NewTable([]string{"When", "Event", "Scan", "Commit"})
+			for _, e := range f.History {
+				table.AddRow([]string{
+					e.CreatedAt.Format("2006-01-02 15:04"),
+					e.Event,
+					e.ScanID,
+					deref(e.CommitHash),
+				})
+			}
+			table.Render()
+		}
+		return nil
+	},
+}
+
+var findingsSummaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize open findings per project",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, _ := cmd.Flags().GetString("profile")
+		p, err := cfg.GetProfile(profile)
+		if err != nil {
+			return fmt.Errorf("not logged in: %w", err)
+		}
+
+		findingsClient := client.NewFindingsClient(cfg.GetFindingsURL(profile))
+		summaries, err := findingsClient.Summary(p.AccessToken, findingsFilters(cmd))
+		if err != nil {
+			return err
+		}
+
+		outputFormat, _ := cmd.Flags().GetString("output")
+		if outputFormat == "json" {
+			return output.JSON(summaries)
+		}
+
+		if len(summaries) == 0 {
+			output.Info("No findings found")
+			return nil
+		}
+
+		table := output.NewTable([]string{"Project", "Open", "Critical", "High", "Medium", "Low", "Info", "Resolved", "Top Categories"})
+		for _, s := range summaries {
+			table.AddRow([]string{
+				s.Project,
+				fmt.Sprintf("%d", s.Open),
+				fmt.Sprintf("%d", s.BySeverity["critical"]),
+				fmt.Sprintf("%d", s.BySeverity["high"]),
+				fmt.Sprintf("%d", s.BySeverity["medium"]),
+				fmt.Sprintf("%d", s.BySeverity["low"]),
+				fmt.Sprintf("%d", s.BySeverity["info"]),
+				fmt.Sprintf("%d", s.Resolved),
+				topCounts(s.ByCategory, 3),
+			})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+var findingsTrendCmd = &cobra.Command{
+	Use:   "trend [project]",
+	Short: "Show finding counts over a project's recent scans",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, _ := cmd.Flags().GetString("profile")
+		p, err := cfg.GetProfile(profile)
+		if err != nil {
+			return fmt.Errorf("not logged in: %w", err)
+		}
+
+		tool, _ := cmd.Flags().GetString("tool")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		findingsClient := client.NewFindingsClient(cfg.GetFindingsURL(profile))
+		points, err := findingsClient.Trend(p.AccessToken, args[0], tool, limit)
+		if err != nil {
+			return err
+		}
+
+		outputFormat, _ := cmd.Flags().GetString("output")
+		if outputFormat == "json" {
+			return output.JSON(points)
+		}
+
+		if len(points) == 0 {
+			output.Info("No scans found for project %s", args[0])
+			return nil
+		}
+
+		table := output.NewTable([]string{"Scanned At", "Tool", "Commit", "Open", "New", "Reintroduced", "Resolved"})
+		for _, pt := range points {
+			table.AddRow([]string{
+				pt.ScannedAt.Format("2006-01-02 15:04"),
+				pt.Tool,
+				shortCommit(pt.CommitHash),
+				fmt.Sprintf("%d", pt.Open),
+				fmt.Sprintf("%d", pt.New),
+				fmt.Sprintf("%d", pt.Reintroduced),
+				fmt.Sprintf("%d", pt.Resolved),
+			})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+var findingsScansCmd = &cobra.Command{
+	Use:   "scans",
+	Short: "List scans",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, _ := cmd.Flags().GetString("profile")
+		p, err := cfg.GetProfile(profile)
+		if err != nil {
+			return fmt.Errorf("not logged in: %w", err)
+		}
+
+		project, _ := cmd.Flags().GetString("project")
+		tool, _ := cmd.Flags().GetString("tool")
+		cursor, _ := cmd.Flags().GetString("cursor")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		findingsClient := client.NewFindingsClient(cfg.GetFindingsURL(profile))
+		page, err := findingsClient.ListScans(p.AccessToken, map[string]string{
+			"project": project,
+			"tool":    tool,
+			"cursor":  cursor,
+			"limit":   fmt.Sprintf("%d", limit),
+		})
+		if err != nil {
+			return err
+		}
+
+		outputFormat, _ := cmd.Flags().GetString("output")
+		if outputFormat == "json" {
+			return output.JSON(page.Data)
+		}
+
+		if len(page.Data) == 0 {
+			output.Info("No scans found")
+			return nil
+		}
+
+		table := output.NewTable([]string{"ID", "Project", "Tool", "Version", "Commit", "Signals", "Created"})
+		for _, s := range page.Data {
+			table.AddRow([]string{
+				s.ID,
+				s.Project,
+				s.Tool,
+				deref(s.ToolVersion),
+				shortCommit(deref(s.CommitHash)),
+				fmt.Sprintf("%d", s.SignalCount),
+				s.CreatedAt.Format("2006-01-02 15:04"),
+			})
+		}
+		table.Render()
+
+		if page.NextCursor != "" {
+			output.Info("\nMore results available: --cursor %s", page.NextCursor)
+		}
+		return nil
+	},
+}
+
+// findingsFilters collects the shared finding filter flags into query parameters.
+func findingsFilters(cmd *cobra.Command) map[string]string {
+	filters := make(map[string]string)
+	for _, name := range []string{"project", "tool", "severity", "category", "status", "path", "since", "until", "cursor"} {
+		if v, _ := cmd.Flags().GetString(name); v != "" {
+			filters[name] = v
+		}
+	}
+	if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 {
+		filters["limit"] = fmt.Sprintf("%d", limit)
+	}
+	return filters
+}
+
+func findingLocation(f client.Finding) string {
+	if path := deref(f.FilePath); path != "" {
+		if f.Line != nil && *f.Line > 0 {
+			return fmt.Sprintf("%s:%d", path, *f.Line)
+		}
+		return path
+	}
+	return deref(f.Route)
+}
+
+func topCounts(counts map[string]int, n int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s (%d)", k, counts[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func shortCommit(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
+
+func init() {
+	rootCmd.AddCommand(findingsCmd)
+	findingsCmd.AddCommand(findingsListCmd)
+	findingsCmd.AddCommand(findingsGetCmd)
+	findingsCmd.AddCommand(findingsSummaryCmd)
+	findingsCmd.AddCommand(findingsTrendCmd)
+	findingsCmd.AddCommand(findingsScansCmd)
+
+	for _, c := range []*cobra.Command{findingsListCmd, findingsSummaryCmd} {
+		c.Flags().String("project", "", "Filter by project")
+		c.Flags().String("tool", "", "Filter by scanner (semgrep, gosec, trivy, ...)")
+		c.Flags().StringP("severity", "s", "", "Filter by severity (critical, high, medium, low, info)")
+		c.Flags().String("category", "", "Filter by category")
+		c.Flags().String("path", "", "Filter by file path prefix")
+		c.Flags().String("since", "", "Only findings last seen at or after this time (RFC3339 or YYYY-MM-DD)")
+		c.Flags().String("until", "", "Only findings last seen before this time (RFC3339 or YYYY-MM-DD)")
+	}
+
+	findingsListCmd.Flags().String("status", "", "Filter by status (open, resolved, reintroduced)")
+	findingsListCmd.Flags().String("cursor", "", "Cursor from a previous page")
+	findingsListCmd.Flags().IntP("limit", "l", 100, "Results per page (max 1000)")
+	findingsListCmd.Flags().Bool("all", false, "Follow cursors and fetch every page")
+
+	findingsTrendCmd.Flags().String("tool", "", "Only scans from this scanner")
+	findingsTrendCmd.Flags().IntP("limit", "l", 20, "Number of most recent scans")
+
+	findingsScansCmd.Flags().String("project", "", "Filter by project")
+	findingsScansCmd.Flags().String("tool", "", "Filter by scanner")
+	findingsScansCmd.Flags().String("cursor", "", "Cursor from a previous page")
+	findingsScansCmd.Flags().IntP("limit", "l", 50, "Results per page (max 1000)")
+}
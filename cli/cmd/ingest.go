@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/telhawk-systems/telhawk-stack/cli/internal/client"
 	"github.com/telhawk-systems/telhawk-stack/cli/pkg/output"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/exports"
 )
 
 var ingestCmd = &cobra.Command{
@@ -17,42 +20,51 @@ var ingestCmd = &cobra.Command{
 var ingestSendCmd = &cobra.Command{
 	Use:   "send",
 	Short: "Send an event",
-	Long:  "Send a single event to the ingestion service",
+	Long: `Send a single event to the ingestion service, or load a historical export
+with --file.
+
+Exports may be a JSON array, newline-delimited JSON or CloudTrail log files
+({"Records": [...]}), optionally gzip-compressed. Each record is sent as its
+own event with its original timestamp, so CloudTrail, Okta System Log and
+GitHub audit log exports are normalized exactly like live events.`,
 	Example: `  thawk ingest send --message "Security alert" --severity high
-  thawk ingest send --json '{"event":"login","user":"admin"}'`,
+  thawk ingest send --json '{"event":"login","user":"admin"}'
+
+  # Load historical exports
+  thawk ingest send --file 123456789012_CloudTrail_us-east-1_20260301T1000Z.json.gz --sourcetype aws:cloudtrail
+  thawk ingest send --file okta-system-log.json --sourcetype okta:system
+  thawk ingest send --file audit-log.ndjson --sourcetype github:audit`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		message, _ := cmd.Flags().GetString("message")
 		jsonData, _ := cmd.Flags().GetString("json")
 		source, _ := cmd.Flags().GetString("source")
 		sourcetype, _ := cmd.Flags().GetString("sourcetype")
-		hecToken, _ := cmd.Flags().GetString("token")
+		file, _ := cmd.Flags().GetString("file")
 
-		if message == "" && jsonData == "" {
-			return fmt.Errorf("either --message or --json is required")
+		if message == "" && jsonData == "" && file == "" {
+			return fmt.Errorf("either --message, --json or --file is required")
 		}
 
-		profile, _ := cmd.Flags().GetString("profile")
-
-		// Try to get HEC token from profile if not provided via flag
-		if hecToken == "" {
-			p, err := cfg.GetProfile(profile)
-			if err == nil && p.HECToken != "" {
-				hecToken = p.HECToken
-			}
+		hecToken, err := resolveHECToken(cmd)
+		if err != nil {
+			return err
 		}
 
-		if hecToken == "" {
-			return fmt.Errorf("HEC token is required (use --token flag, store in profile with 'thawk token create', or set in config)")
-		}
+		ingestClient := client.NewIngestClient(resolveIngestURL(cmd))
 
-		ingestURL, _ := cmd.Flags().GetString("ingest-url")
-		// Use config default if not provided via flag
-		if ingestURL == "" || !cmd.Flags().Changed("ingest-url") {
-			ingestURL = cfg.GetIngestURL(profile)
+		if file != "" {
+			batchSize, _ := cmd.Flags().GetInt("batch-size")
+			if !cmd.Flags().Changed("source") {
+				source = file
+			}
+			sent, err := sendExport(ingestClient, hecToken, file, source, sourcetype, batchSize)
+			if err != nil {
+				return fmt.Errorf("failed after sending %d events: %w", sent, err)
+			}
+			output.Success(fmt.Sprintf("Sent %d events from %s", sent, file))
+			return nil
 		}
 
-		ingestClient := client.NewIngestClient(ingestURL)
-
 		var event map[string]interface{}
 		if jsonData != "" {
 			// Parse JSON
@@ -73,6 +85,49 @@ var ingestSendCmd = &cobra.Command{
 	},
 }
 
+// sendExport streams the records of an export file ("-" for stdin) to the
+// ingestion service in batches and returns how many were sent.
+func sendExport(ingestClient *client.IngestClient, hecToken, file, source, sourcetype string, batchSize int) (int, error) {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		r = f
+	}
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	sent := 0
+	batch := make([]client.BatchEvent, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := ingestClient.SendBatch(hecToken, batch, source, sourcetype); err != nil {
+			return err
+		}
+		sent += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := exports.Read(r, func(record exports.Record) error {
+		batch = append(batch, client.BatchEvent{Event: record.Event, Time: record.Time})
+		if len(batch) < batchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	return sent, err
+}
+
 func init() {
 	rootCmd.AddCommand(ingestCmd)
 	ingestCmd.AddCommand(ingestSendCmd)
@@ -83,4 +138,6 @@ func init() {
 	ingestSendCmd.Flags().String("sourcetype", "manual", "Event source type")
 	ingestSendCmd.Flags().StringP("token", "t", "", "HEC token")
 	ingestSendCmd.Flags().String("ingest-url", "", "Ingest service URL (default from config/env)")
+	ingestSendCmd.Flags().StringP("file", "f", "", "Export file to load (JSON array, NDJSON or CloudTrail log file, optionally gzipped; - for stdin)")
+	ingestSendCmd.Flags().Int("batch-size", 500, "Events per request when sending --file")
 }
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/client"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/importer"
+	"github.com/telhawk-systems/telhawk-stack/cli/pkg/output"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/exports"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/parsing"
+)
+
+// dlqCountLimit is the most DLQ events the import summary counts.
+const dlqCountLimit = 1000
+
+var ingestImportCmd = &cobra.Command{
+	Use:   "import [file or glob]...",
+	Short: "Bulk import historical log files and datasets",
+	Long: `Stream local files to the ingestion service through HEC, in concurrent
+batches, with each event's original timestamp.
+
+Files may be JSON (arrays, NDJSON or CloudTrail log files), CSV with a header
+row, or raw text split into events by a parsing profile (--profiles, as for
+'thawk ingest parse-test'; raw text without a profile is one event per line).
+Any of them may be gzip-compressed. The format is detected from the file
+name and content unless --format or the manifest sets it.
+
+Sourcetype, source, host and index apply to every file given as an argument.
+To set them per file or glob, list the files in a --manifest:
+
+  files:
+    - path: cloudtrail/*.json.gz
+      sourcetype: aws:cloudtrail
+    - path: T1003.001/windows-sysmon.log
+      sourcetype: XmlWinEventLog:Microsoft-Windows-Sysmon/Operational
+      host: win-dc-01
+      format: raw
+
+Progress is saved to --checkpoint after every batch, so running the same
+command again after an interruption resumes where it stopped and skips files
+already imported (use --restart to import everything again). The summary
+reports the events HEC accepted and rejected, whether their acks confirmed
+indexing and, when logged in as an admin, how many landed in the DLQ.`,
+	Example: `  thawk ingest import 'exports/okta-*.json.gz' --sourcetype okta:system
+  thawk ingest import users.csv --sourcetype hr:users --host hr-01
+  thawk ingest import app.log --sourcetype java:app --profiles ingest/config.yaml
+  thawk ingest import --manifest attack-data.yaml --concurrency 8`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		manifest, _ := flags.GetString("manifest")
+		if len(args) == 0 && manifest == "" {
+			return fmt.Errorf("files or --manifest required")
+		}
+
+		defaults := importer.Source{}
+		defaults.SourceType, _ = flags.GetString("sourcetype")
+		defaults.Source, _ = flags.GetString("source")
+		defaults.Host, _ = flags.GetString("host")
+		defaults.Index, _ = flags.GetString("index")
+		format, _ := flags.GetString("format")
+		defaults.Format = exports.Format(format)
+
+		var sources []importer.Source
+		for _, arg := range args {
+			sources = append(sources, importer.Source{Path: arg})
+		}
+		if manifest != "" {
+			manifestSources, err := importer.LoadManifest(manifest)
+			if err != nil {
+				return err
+			}
+			sources = append(sources, manifestSources...)
+		}
+		files, err := importer.Expand(sources, defaults)
+		if err != nil {
+			return err
+		}
+
+		profile, _ := flags.GetString("profile")
+		hecToken, err := resolveHECToken(cmd)
+		if err != nil {
+			return err
+		}
+
+		parsers, err := importParsers(cmd)
+		if err != nil {
+			return err
+		}
+
+		im := &importer.Importer{
+			Client:  client.NewIngestClient(resolveIngestURL(cmd)),
+			Token:   hecToken,
+			Parsers: parsers,
+			Log: func(format string, args ...interface{}) {
+				output.Info(format, args...)
+			},
+		}
+		im.BatchSize, _ = flags.GetInt("batch-size")
+		im.Concurrency, _ = flags.GetInt("concurrency")
+		im.MaxRetries, _ = flags.GetInt("retries")
+		im.AckTimeout, _ = flags.GetDuration("ack-timeout")
+
+		checkpointFile, _ := flags.GetString("checkpoint")
+		if checkpointFile != "" {
+			if restart, _ := flags.GetBool("restart"); restart {
+				if err := os.Remove(checkpointFile); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+			if im.Checkpoint, err = importer.LoadCheckpoint(checkpointFile); err != nil {
+				return err
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		started := time.Now()
+		summary, runErr := im.Run(ctx, files)
+		printImportSummary(summary, importDLQCount(profile, files, started))
+
+		if runErr != nil {
+			if checkpointFile != "" {
+				output.Warn("Progress saved to %s; run the same command again to resume", checkpointFile)
+			}
+			if errors.Is(runErr, ctx.Err()) {
+				return fmt.Errorf("import interrupted")
+			}
+			return fmt.Errorf("import failed: %w", runErr)
+		}
+		return nil
+	},
+}
+
+// resolveHECToken returns the --token flag or the profile's HEC token.
+func resolveHECToken(cmd *cobra.Command) (string, error) {
+	hecToken, _ := cmd.Flags().GetString("token")
+	if hecToken == "" {
+		profile, _ := cmd.Flags().GetString("profile")
+		if p, err := cfg.GetProfile(profile); err == nil {
+			hecToken = p.HECToken
+		}
+	}
+	if hecToken == "" {
+		return "", fmt.Errorf("HEC token is required (use --token flag, store in profile with 'thawk token create', or set in config)")
+	}
+	return hecToken, nil
+}
+
+// resolveIngestURL returns the --ingest-url flag or the profile's ingest URL.
+func resolveIngestURL(cmd *cobra.Command) string {
+	if ingestURL, _ := cmd.Flags().GetString("ingest-url"); ingestURL != "" && cmd.Flags().Changed("ingest-url") {
+		return ingestURL
+	}
+	profile, _ := cmd.Flags().GetString("profile")
+	return cfg.GetIngestURL(profile)
+}
+
+// importParsers compiles the parsing profiles of --profiles by sourcetype.
+func importParsers(cmd *cobra.Command) (map[string]*parsing.Parser, error) {
+	profilesFile, _ := cmd.Flags().GetString("profiles")
+	if profilesFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(profilesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+	profiles, err := loadParsingProfiles(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", profilesFile, err)
+	}
+	parsers := make(map[string]*parsing.Parser, len(profiles))
+	for _, p := range profiles {
+		parser, err := parsing.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", profilesFile, err)
+		}
+		parsers[p.SourceType] = parser
+	}
+	return parsers, nil
+}
+
+// importDLQCount counts the DLQ events of the imported sources since the
+// import started. It returns -1 when the DLQ cannot be read (it requires an
+// admin login).
+func importDLQCount(profile string, files []importer.File, since time.Time) int {
+	p, err := cfg.GetProfile(profile)
+	if err != nil || p.AccessToken == "" {
+		return -1
+	}
+	events, err := client.NewDLQClient(cfg.GetIngestURL(profile)).List(p.AccessToken, map[string]string{
+		"since": since.UTC().Format(time.RFC3339),
+		"limit": fmt.Sprintf("%d", dlqCountLimit),
+	})
+	if err != nil {
+		return -1
+	}
+	sources := make(map[string]bool, len(files))
+	for _, file := range files {
+		sources[file.SourceType+"\x00"+file.Source.Source] = true
+	}
+	count := 0
+	for _, event := range events {
+		if sources[event.SourceType+"\x00"+event.Source] {
+			count++
+		}
+	}
+	return count
+}
+
+func printImportSummary(summary *importer.Summary, dlq int) {
+	if summary == nil {
+		return
+	}
+	table := output.NewTable([]string{"Files", "Sent", "Accepted", "Rejected", "DLQ", "Resumed", "Duration"})
+	accepted := "n/a (acks disabled)"
+	if summary.AcksEnabled {
+		accepted = fmt.Sprintf("%d", summary.Acked)
+	}
+	rejected := fmt.Sprintf("%d", summary.Rejected)
+	if summary.Unacked > 0 {
+		rejected = fmt.Sprintf("%d (+%d unacknowledged)", summary.Rejected, summary.Unacked)
+	}
+	dlqCount := "n/a (admin login required)"
+	switch {
+	case dlq >= dlqCountLimit:
+		dlqCount = fmt.Sprintf("%d+", dlq)
+	case dlq >= 0:
+		dlqCount = fmt.Sprintf("%d", dlq)
+	}
+	table.AddRow([]string{
+		fmt.Sprintf("%d", summary.Files),
+		fmt.Sprintf("%d", summary.Sent),
+		accepted,
+		rejected,
+		dlqCount,
+		fmt.Sprintf("%d", summary.Skipped),
+		summary.Duration.Round(time.Millisecond).String(),
+	})
+	table.Render()
+}
+
+func init() {
+	ingestCmd.AddCommand(ingestImportCmd)
+
+	flags := ingestImportCmd.Flags()
+	flags.String("manifest", "", "YAML file listing files or globs with their sourcetype, source, host, index and format")
+	flags.String("sourcetype", "", "Sourcetype of the files (default for manifest entries)")
+	flags.String("source", "", "Event source (default: the file path)")
+	flags.String("host", "", "Event host")
+	flags.String("index", "", "Target index")
+	flags.String("format", "", "File format: json, csv or raw (default: detect)")
+	flags.String("profiles", "", "YAML file with parsing profiles for raw text (e.g. the ingest config)")
+	flags.StringP("token", "t", "", "HEC token")
+	flags.String("ingest-url", "", "Ingest service URL (default from config/env)")
+	flags.Int("batch-size", 500, "Events per request")
+	flags.Int("concurrency", 4, "Requests in flight")
+	flags.Int("retries", 5, "Attempts per batch while the service is busy or unreachable")
+	flags.String("checkpoint", ".thawk-import.checkpoint", "File recording progress for resuming (empty to disable)")
+	flags.Bool("restart", false, "Ignore the checkpoint and import everything again")
+	flags.Duration("ack-timeout", 2*time.Minute, "How long to wait for acks to confirm indexing after the last batch")
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/telhawk-systems/telhawk-stack/cli/pkg/output"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/parsing"
+	"gopkg.in/yaml.v3"
+)
+
+var ingestParseTestCmd = &cobra.Command{
+	Use:   "parse-test [file]",
+	Short: "Preview how a parsing profile splits raw data",
+	Long: `Apply a sourcetype parsing profile to a sample file (or stdin) locally and
+print the resulting events, timestamps and extracted fields. Nothing is sent
+to the ingestion service.
+
+Profiles are read from --profiles, which may be the ingest config file or a
+file with a top-level "profiles" list, and selected with --sourcetype. The
+remaining flags override individual profile settings, so a profile can also
+be built entirely on the command line.`,
+	Example: `  # Try the profiles from the ingest config against a sample
+  thawk ingest parse-test app.log --profiles ingest/config.yaml --sourcetype java:app
+
+  # Build a profile on the command line
+  thawk ingest parse-test app.log --sourcetype java:app \
+    --should-linemerge --break-only-before '^\d{4}-\d{2}-\d{2}' \
+    --time-format '%Y-%m-%d %H:%M:%S,%3N' \
+    --extract '^\S+ \S+ (?P<level>[A-Z]+)'
+
+  # Payloads as ingest would send them to the normalizers
+  tail -n 100 /var/log/syslog | thawk ingest parse-test --sourcetype syslog \
+    --time-format '%b %e %H:%M:%S' --output json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := parseTestProfile(cmd)
+		if err != nil {
+			return err
+		}
+		parser, err := parsing.Compile(profile)
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		if len(args) == 1 {
+			data, err = os.ReadFile(args[0])
+		} else {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read sample: %w", err)
+		}
+
+		events := parser.Parse(data)
+
+		outputFormat, _ := cmd.Flags().GetString("output")
+		if outputFormat == "json" {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			for _, event := range events {
+				if err := enc.Encode(event.Payload()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		out := cmd.OutOrStdout()
+		for i, event := range events {
+			timestamp := "(none)"
+			if !event.Time.IsZero() {
+				timestamp = event.Time.Format(time.RFC3339Nano)
+			}
+			fmt.Fprintf(out, "--- event %d  time=%s\n", i+1, timestamp)
+			keys := make([]string, 0, len(event.Fields))
+			for k := range event.Fields {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Fprintf(out, "    %s=%s\n", k, event.Fields[k])
+			}
+			fmt.Fprintln(out, event.Text)
+		}
+		output.Success("%d events parsed for sourcetype %s", len(events), profile.SourceType)
+		return nil
+	},
+}
+
+// parseTestProfile builds the profile for parse-test from --profiles and the
+// override flags.
+func parseTestProfile(cmd *cobra.Command) (parsing.Profile, error) {
+	flags := cmd.Flags()
+	sourceType, _ := flags.GetString("sourcetype")
+	profilesFile, _ := flags.GetString("profiles")
+
+	profile := parsing.Profile{SourceType: sourceType}
+	if profilesFile != "" {
+		data, err := os.ReadFile(profilesFile)
+		if err != nil {
+			return profile, fmt.Errorf("failed to read profiles: %w", err)
+		}
+		profiles, err := loadParsingProfiles(data)
+		if err != nil {
+			return profile, fmt.Errorf("%s: %w", profilesFile, err)
+		}
+		found := false
+		for _, p := range profiles {
+			if p.SourceType == sourceType {
+				profile, found = p, true
+				break
+			}
+		}
+		if !found {
+			return profile, fmt.Errorf("%s has no profile for sourcetype %q", profilesFile, sourceType)
+		}
+	}
+
+	if flags.Changed("line-breaker") {
+		profile.LineBreaker, _ = flags.GetString("line-breaker")
+	}
+	if flags.Changed("should-linemerge") {
+		profile.ShouldLineMerge, _ = flags.GetBool("should-linemerge")
+	}
+	if flags.Changed("break-only-before") {
+		profile.BreakOnlyBefore, _ = flags.GetString("break-only-before")
+		profile.ShouldLineMerge = true
+	}
+	if flags.Changed("merge-key") {
+		profile.MergeKey, _ = flags.GetString("merge-key")
+	}
+	if flags.Changed("time-prefix") {
+		profile.TimePrefix, _ = flags.GetString("time-prefix")
+	}
+	if flags.Changed("time-format") {
+		profile.TimeFormat, _ = flags.GetString("time-format")
+	}
+	if flags.Changed("timezone") {
+		profile.Timezone, _ = flags.GetString("timezone")
+	}
+	if flags.Changed("lookahead") {
+		profile.MaxTimestampLookahead, _ = flags.GetInt("lookahead")
+	}
+	if flags.Changed("extract") {
+		extractions, _ := flags.GetStringArray("extract")
+		profile.Extractions = append(profile.Extractions, extractions...)
+	}
+	if flags.Changed("kv") {
+		profile.KeyValue, _ = flags.GetBool("kv")
+	}
+	return profile, nil
+}
+
+// loadParsingProfiles reads profiles from either the ingest config file
+// (parsing.profiles) or a file with a top-level profiles list.
+func loadParsingProfiles(data []byte) ([]parsing.Profile, error) {
+	var doc struct {
+		Profiles []parsing.Profile `yaml:"profiles"`
+		Parsing  struct {
+			Profiles []parsing.Profile `yaml:"profiles"`
+		} `yaml:"parsing"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid profiles file: %w", err)
+	}
+	profiles := append(doc.Profiles, doc.Parsing.Profiles...)
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no parsing profiles found")
+	}
+	return profiles, nil
+}
+
+func init() {
+	ingestCmd.AddCommand(ingestParseTestCmd)
+
+	flags := ingestParseTestCmd.Flags()
+	flags.String("profiles", "", "YAML file with parsing profiles (e.g. the ingest config)")
+	flags.String("sourcetype", "", "Sourcetype whose profile to apply")
+	flags.String("line-breaker", "", "Regexp whose first capture group separates events")
+	flags.Bool("should-linemerge", false, "Merge lines into multi-line events")
+	flags.String("break-only-before", "", "Regexp that starts a new multi-line event (implies --should-linemerge)")
+	flags.String("merge-key", "", "Regexp whose first capture group merges consecutive lines with the same value")
+	flags.String("time-prefix", "", "Regexp that precedes the timestamp")
+	flags.String("time-format", "", "strptime-style timestamp format (e.g. %Y-%m-%d %H:%M:%S)")
+	flags.String("timezone", "", "IANA timezone for timestamps without an offset")
+	flags.Int("lookahead", parsing.DefaultMaxTimestampLookahead, "Characters after the time prefix searched for a timestamp")
+	flags.StringArray("extract", nil, "Regexp with named capture groups to extract as fields (repeatable)")
+	flags.Bool("kv", false, "Extract key=value pairs")
+	_ = ingestParseTestCmd.MarkFlagRequired("sourcetype")
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestLoadParsingProfiles(t *testing.T) {
+	tests := map[string]string{
+		"ingest config": `
+server:
+  port: 8088
+parsing:
+  profiles:
+    - sourcetype: java:app
+      time_format: "%Y-%m-%d"
+`,
+		"profiles list": `
+profiles:
+  - sourcetype: java:app
+    time_format: "%Y-%m-%d"
+`,
+	}
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			profiles, err := loadParsingProfiles([]byte(doc))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(profiles) != 1 || profiles[0].SourceType != "java:app" || profiles[0].TimeFormat != "%Y-%m-%d" {
+				t.Errorf("unexpected profiles: %+v", profiles)
+			}
+		})
+	}
+
+	if _, err := loadParsingProfiles([]byte("server:\n  port: 8088\n")); err == nil {
+		t.Error("expected error for a file without profiles")
+	}
+}
+
+func TestParseTestCommandRegistered(t *testing.T) {
+	for _, c := range ingestCmd.Commands() {
+		if c == ingestParseTestCmd {
+			return
+		}
+	}
+	t.Error("expected parse-test to be registered under ingest")
+}
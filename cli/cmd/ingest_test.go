@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/client"
+)
+
+func TestSendExport(t *testing.T) {
+	var requests, events int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		dec := json.NewDecoder(r.Body)
+		for dec.More() {
+			var payload map[string]interface{}
+			if err := dec.Decode(&payload); err != nil {
+				t.Errorf("decode batch: %v", err)
+			}
+			if payload["sourcetype"] != "aws:cloudtrail" {
+				t.Errorf("unexpected sourcetype: %v", payload["sourcetype"])
+			}
+			events++
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var export bytes.Buffer
+	export.WriteString(`{"Records": [`)
+	for i := 0; i < 5; i++ {
+		if i > 0 {
+			export.WriteString(",")
+		}
+		export.WriteString(`{"eventName": "GetObject", "eventTime": "2026-03-01T10:00:00Z"}`)
+	}
+	export.WriteString(`]}`)
+	file := filepath.Join(t.TempDir(), "cloudtrail.json")
+	if err := os.WriteFile(file, export.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := sendExport(client.NewIngestClient(server.URL), "token", file, file, "aws:cloudtrail", 2)
+	if err != nil {
+		t.Fatalf("sendExport failed: %v", err)
+	}
+	if sent != 5 || events != 5 || requests != 3 {
+		t.Errorf("expected 5 events in 3 requests, sent %d, received %d in %d", sent, events, requests)
+	}
+}
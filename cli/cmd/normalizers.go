@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/telhawk-systems/telhawk-stack/cli/pkg/output"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/mapping"
+)
+
+var normalizersCmd = &cobra.Command{
+	Use:   "normalizers",
+	Short: "Declarative normalizer mappings",
+	Long:  "Develop and test the YAML mappings that ingest loads from its mappings directory",
+}
+
+var normalizersTestCmd = &cobra.Command{
+	Use:   "test [sample]",
+	Short: "Run sample payloads through a mapping",
+	Long: `Validate a mapping file (or a directory of them, as ingest loads it) and run
+sample payloads through it locally, printing the resulting OCSF events.
+
+Samples are read from the file argument or stdin and may be a JSON object, a
+JSON array of objects, or newline-delimited JSON. The mapping is selected with
+--name, or by matching --sourcetype the way ingest does.`,
+	Example: `  # Validate and try a single mapping
+  thawk normalizers test --mapping mappings/okta.yaml okta-sample.json
+
+  # Check which mapping in a directory handles a sourcetype
+  thawk normalizers test --mapping /etc/telhawk/mappings --sourcetype okta:auth okta-sample.json
+
+  # OCSF events as a JSON array
+  cat samples.ndjson | thawk normalizers test --mapping okta.yaml --output json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mappingPath, _ := cmd.Flags().GetString("mapping")
+		name, _ := cmd.Flags().GetString("name")
+		sourceType, _ := cmd.Flags().GetString("sourcetype")
+		source, _ := cmd.Flags().GetString("source")
+
+		set, err := loadMappingSet(mappingPath)
+		if err != nil {
+			return err
+		}
+		m, err := selectMapping(set, name, sourceType)
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		if len(args) == 1 {
+			data, err = os.ReadFile(args[0])
+		} else {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read samples: %w", err)
+		}
+		samples, err := decodeSamples(data)
+		if err != nil {
+			return err
+		}
+
+		meta := mapping.Meta{Source: source, SourceType: sourceType, Format: "json", ReceivedAt: time.Now().UTC()}
+		outputFormat, _ := cmd.Flags().GetString("output")
+		var events []*ocsf.Event
+		failed := 0
+		for i, sample := range samples {
+			event, err := m.Apply(sample, meta)
+			if err != nil {
+				failed++
+				output.Error("sample %d: %v", i+1, err)
+				continue
+			}
+			if outputFormat == "json" {
+				events = append(events, event)
+				continue
+			}
+			output.Info("--- sample %d (mapping %s)", i+1, m.Name())
+			if err := output.JSON(event); err != nil {
+				return err
+			}
+		}
+
+		if outputFormat == "json" {
+			if err := output.JSON(events); err != nil {
+				return err
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d samples failed", failed, len(samples))
+		}
+		if outputFormat != "json" {
+			output.Success("%d samples mapped by %s", len(samples), m.Name())
+		}
+		return nil
+	},
+}
+
+// loadMappingSet validates a mapping file or directory.
+func loadMappingSet(path string) (*mapping.Set, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping: %w", err)
+	}
+	if info.IsDir() {
+		return mapping.LoadDir(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping: %w", err)
+	}
+	defs, err := mapping.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return mapping.NewSet(defs)
+}
+
+// selectMapping picks the mapping by name, by sourcetype, or the only one.
+func selectMapping(set *mapping.Set, name, sourceType string) (*mapping.Mapping, error) {
+	switch {
+	case name != "":
+		if m := set.Get(name); m != nil {
+			return m, nil
+		}
+		return nil, fmt.Errorf("no mapping named %s", name)
+	case sourceType != "":
+		if m := set.Find("json", sourceType); m != nil {
+			return m, nil
+		}
+		return nil, fmt.Errorf("no mapping matches sourcetype %s", sourceType)
+	case set.Len() == 1:
+		return set.Mappings()[0], nil
+	}
+	return nil, fmt.Errorf("%d mappings loaded: select one with --name or --sourcetype", set.Len())
+}
+
+// decodeSamples reads JSON objects, arrays of objects, or NDJSON.
+func decodeSamples(data []byte) ([]map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var samples []map[string]interface{}
+	for {
+		var value interface{}
+		err := dec.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid sample JSON: %w", err)
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		for _, item := range items {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("sample %d is not a JSON object", len(samples)+1)
+			}
+			samples = append(samples, obj)
+		}
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples found")
+	}
+	return samples, nil
+}
+
+func init() {
+	rootCmd.AddCommand(normalizersCmd)
+	normalizersCmd.AddCommand(normalizersTestCmd)
+
+	normalizersTestCmd.Flags().String("mapping", "", "Mapping file or directory")
+	normalizersTestCmd.Flags().String("name", "", "Mapping name (when several are loaded)")
+	normalizersTestCmd.Flags().String("sourcetype", "", "Sourcetype used to select the mapping")
+	normalizersTestCmd.Flags().String("source", "thawk-cli", "Event source")
+	_ = normalizersTestCmd.MarkFlagRequired("mapping")
+}
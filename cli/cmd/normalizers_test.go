@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestDecodeSamples(t *testing.T) {
+	tests := map[string]int{
+		`{"a": 1}`:                   1,
+		`[{"a": 1}, {"a": 2}]`:       2,
+		"{\"a\": 1}\n{\"a\": 2}\n":   2,
+		"[{\"a\": 1}]\n{\"a\": 2}\n": 2,
+	}
+	for input, want := range tests {
+		samples, err := decodeSamples([]byte(input))
+		if err != nil {
+			t.Errorf("decodeSamples(%q) failed: %v", input, err)
+			continue
+		}
+		if len(samples) != want {
+			t.Errorf("decodeSamples(%q) returned %d samples, want %d", input, len(samples), want)
+		}
+	}
+
+	for _, input := range []string{"", "[1, 2]", `{"a": `} {
+		if _, err := decodeSamples([]byte(input)); err == nil {
+			t.Errorf("decodeSamples(%q) should fail", input)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/client"
+	"github.com/telhawk-systems/telhawk-stack/cli/pkg/output"
+)
+
+var searchTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Stream events as they are ingested",
+	Long: `Stream events live as ingest stores them, scoped to your client.
+
+Events are filtered on the server with a canonical JSON filter. The server
+caps the stream at --rate events per second and reports events it drops
+because of the rate or because the client is reading too slowly.`,
+	Example: `  # Watch everything
+  thawk search tail
+
+  # Watch failed authentications, a few fields at a time
+  thawk search tail --filter '{"type":"and","conditions":[
+    {"field":".class_uid","operator":"eq","value":3002},
+    {"field":".status_id","operator":"eq","value":2}]}' \
+    --select .time,.actor.user.name,.src_endpoint.ip
+
+  # Stop after 50 events, one JSON event per line
+  thawk search tail --limit 50 --output json | jq .
+
+  # Full JSON query (filter, select, limit) from stdin
+  thawk search tail --raw < tail-query.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, _ := cmd.Flags().GetString("profile")
+		p, err := cfg.GetProfile(profile)
+		if err != nil {
+			return fmt.Errorf("not logged in: %w", err)
+		}
+
+		baseURL := cfg.GetQueryURL(profile)
+		if cmd.Flags().Changed("url") {
+			baseURL, _ = cmd.Flags().GetString("url")
+		}
+
+		query, err := buildTailQuery(cmd)
+		if err != nil {
+			return err
+		}
+		rate, _ := cmd.Flags().GetInt("rate")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		jsonOutput := outputFormat == "json"
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		enc := json.NewEncoder(os.Stdout)
+		count := 0
+		err = client.NewQueryClient(baseURL).Tail(ctx, p.AccessToken, client.TailOptions{Query: query, Rate: rate},
+			func(msg client.TailMessage) error {
+				switch msg.Event {
+				case "ready":
+					if !jsonOutput {
+						output.Info("Tailing live events (Ctrl-C to stop)...")
+					}
+				case "event":
+					count++
+					if jsonOutput {
+						return enc.Encode(msg.Data)
+					}
+					fmt.Printf("%d: %s\n", count, msg.Data)
+				case "dropped":
+					var dropped struct {
+						RateLimited  int64 `json:"rate_limited"`
+						Backpressure int64 `json:"backpressure"`
+					}
+					if err := json.Unmarshal(msg.Data, &dropped); err == nil {
+						fmt.Fprintf(os.Stderr, "dropped %d events over the rate limit, %d while catching up\n",
+							dropped.RateLimited, dropped.Backpressure)
+					}
+				}
+				return nil
+			})
+		if err != nil {
+			return fmt.Errorf("live tail failed: %w", err)
+		}
+		if !jsonOutput {
+			output.Success("Live tail ended: %d events", count)
+		}
+		return nil
+	},
+}
+
+// buildTailQuery assembles the live tail query from --raw stdin or the
+// --filter, --select and --limit flags.
+func buildTailQuery(cmd *cobra.Command) (json.RawMessage, error) {
+	if raw, _ := cmd.Flags().GetBool("raw"); raw {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		if !json.Valid(data) {
+			return nil, fmt.Errorf("stdin is not valid JSON")
+		}
+		return data, nil
+	}
+
+	query := map[string]interface{}{}
+	if filter, _ := cmd.Flags().GetString("filter"); filter != "" {
+		if !json.Valid([]byte(filter)) {
+			return nil, fmt.Errorf("--filter is not valid JSON")
+		}
+		query["filter"] = json.RawMessage(filter)
+	}
+	if fields, _ := cmd.Flags().GetStringSlice("select"); len(fields) > 0 {
+		query["select"] = fields
+	}
+	if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 {
+		query["limit"] = limit
+	}
+	if len(query) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(query)
+}
+
+func init() {
+	searchCmd.AddCommand(searchTailCmd)
+
+	searchTailCmd.Flags().String("filter", "", "JSON filter expression (canonical query filter)")
+	searchTailCmd.Flags().StringSlice("select", nil, "OCSF fields to return (e.g., .time,.actor.user.name)")
+	searchTailCmd.Flags().Int("limit", 0, "Stop after this many events (0 = until interrupted)")
+	searchTailCmd.Flags().Int("rate", 0, "Maximum events per second (server default 100, max 1000)")
+	searchTailCmd.Flags().Bool("raw", false, "Read a full JSON query (filter, select, limit) from stdin")
+	searchTailCmd.Flags().String("url", "", "Web backend URL (default from config/env)")
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/telhawk-systems/telhawk-stack/cli/internal/seeder"
+	"github.com/telhawk-systems/telhawk-stack/cli/pkg/attacks"
 )
 
 var (
@@ -20,6 +21,8 @@ var (
 	seederEventTypes string
 	seederAttacks    string
 	seederFromRules  string
+	seederScenario   string
+	seederNearMiss   bool
 )
 
 var seederCmd = &cobra.Command{
@@ -49,6 +52,13 @@ Examples:
   # Run specific attacks
   thawk seeder run --attack brute_force_admin,credential_stuffing
 
+  # Seed events that must trigger each rule, then near misses that must not
+  thawk seeder run --from-rules alerting/dist/rules
+  thawk seeder run --from-rules alerting/dist/rules --near-miss
+
+  # Run a multi-stage attack scenario
+  thawk seeder run --scenario cli/scenarios/finance-workstation-compromise.yaml
+
   # Use custom config file
   thawk seeder run --config ./custom-seeder.yaml`,
 	RunE: runSeeder,
@@ -183,6 +193,8 @@ func init() {
 	seederRunCmd.Flags().StringVar(&seederEventTypes, "types", "", "Comma-separated event types")
 	seederRunCmd.Flags().StringVarP(&seederAttacks, "attack", "a", "", "Comma-separated attack names from config")
 	seederRunCmd.Flags().StringVar(&seederFromRules, "from-rules", "", "Directory containing detection rule JSON files")
+	seederRunCmd.Flags().StringVar(&seederScenario, "scenario", "", "Attack scenario YAML file to run instead of baseline events")
+	seederRunCmd.Flags().BoolVar(&seederNearMiss, "near-miss", false, "With --from-rules, send near-miss events that must not trigger each rule")
 
 	// Validate command flags
 	seederValidateCmd.Flags().StringVar(&seederCfgFile, "config", "", "config file to validate")
@@ -241,8 +253,21 @@ func runSeeder(cmd *cobra.Command, args []string) error {
 	// Create and run seeder
 	runner := seeder.NewRunner(config)
 
+	// If --scenario is specified, run only the scenario's kill chain
+	if seederScenario != "" {
+		scenario, err := attacks.LoadScenario(seederScenario)
+		if err != nil {
+			return err
+		}
+		if err := runner.RunScenario(scenario); err != nil {
+			return fmt.Errorf("scenario failed: %w", err)
+		}
+		return nil
+	}
+
 	// If --from-rules is specified, load and process rules
 	if seederFromRules != "" {
+		runner.NearMiss = seederNearMiss
 		if err := runner.RunFromRulesDirectory(seederFromRules); err != nil {
 			return fmt.Errorf("failed to run from rules: %w", err)
 		}
@@ -30,7 +30,13 @@ type Alert struct {
 	} `json:"finding_info"`
 	DetectionSchemaID        string `json:"detection_schema_id"`
 	DetectionSchemaVersionID string `json:"detection_schema_version_id"`
-	RawData                  struct {
+	Metadata                 struct {
+		CorrelationUID string   `json:"correlation_uid,omitempty"`
+		Tags           []string `json:"tags,omitempty"`
+	} `json:"metadata"`
+	// MatchedEvents holds the triggering events, either as IDs or documents
+	MatchedEvents []json.RawMessage `json:"matched_events,omitempty"`
+	RawData       struct {
 		EventCount    int      `json:"event_count,omitempty"`
 		DistinctCount int      `json:"distinct_count,omitempty"`
 		GroupKey      string   `json:"group_key,omitempty"`
@@ -0,0 +1,234 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DLQClient talks to the ingest service's dead-letter queue API.
+type DLQClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// DLQEvent is a failed event held in the ingest DLQ.
+type DLQEvent struct {
+	ID          string            `json:"id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Reason      string            `json:"reason"`
+	Error       string            `json:"error"`
+	Status      string            `json:"status"`
+	Attempts    int               `json:"attempts"`
+	LastAttempt time.Time         `json:"last_attempt"`
+	ReplayCount int               `json:"replay_count"`
+	SourceType  string            `json:"sourcetype"`
+	Source      string            `json:"source"`
+	EventID     string            `json:"event_id"`
+	HECTokenID  string            `json:"hec_token_id"`
+	ClientID    string            `json:"client_id"`
+	Envelope    *DLQEnvelope      `json:"envelope,omitempty"`
+	Replays     []DLQReplayRecord `json:"replays,omitempty"`
+}
+
+// DLQEnvelope is the raw event as received by ingest.
+type DLQEnvelope struct {
+	ID         string            `json:"id"`
+	Source     string            `json:"source"`
+	SourceType string            `json:"source_type"`
+	Format     string            `json:"format"`
+	Attributes map[string]string `json:"attributes"`
+	ReceivedAt time.Time         `json:"received_at"`
+	Payload    string            `json:"payload"`
+}
+
+// DLQReplayRecord is a past replay attempt of a DLQ event.
+type DLQReplayRecord struct {
+	At      time.Time `json:"at"`
+	DryRun  bool      `json:"dry_run"`
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// DLQReplayRequest selects events to replay. IDs take precedence over Filter.
+type DLQReplayRequest struct {
+	IDs    []string          `json:"ids,omitempty"`
+	Filter map[string]string `json:"filter,omitempty"`
+	DryRun bool              `json:"dry_run"`
+	Force  bool              `json:"force"`
+}
+
+// DLQFieldChange is one field that differs from the previous replay output.
+type DLQFieldChange struct {
+	Path   string      `json:"path"`
+	Op     string      `json:"op"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// DLQReplayOutcome is the result of replaying one DLQ event.
+type DLQReplayOutcome struct {
+	ID            string                 `json:"id"`
+	Status        string                 `json:"status"`
+	Error         string                 `json:"error,omitempty"`
+	PreviousError string                 `json:"previous_error,omitempty"`
+	Event         map[string]interface{} `json:"event,omitempty"`
+	Diff          []DLQFieldChange       `json:"diff,omitempty"`
+	DiffBase      *time.Time             `json:"diff_base,omitempty"` // Unset when there is no earlier output to diff against
+}
+
+// DLQReplayReport summarizes a replay run.
+type DLQReplayReport struct {
+	DryRun    bool               `json:"dry_run"`
+	Total     int                `json:"total"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Skipped   int                `json:"skipped"`
+	Outcomes  []DLQReplayOutcome `json:"outcomes"`
+}
+
+func NewDLQClient(baseURL string) *DLQClient {
+	return &DLQClient{
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// List returns DLQ events. Filters are passed through as query parameters
+// (reason, sourcetype, token, client_id, status, since, until, limit).
+func (c *DLQClient) List(token string, filters map[string]string) ([]DLQEvent, error) {
+	var response struct {
+		Data []jsonAPIResource `json:"data"`
+	}
+	if err := c.do(http.MethodGet, "/api/v1/dlq", token, filters, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list dlq events: %w", err)
+	}
+
+	events := make([]DLQEvent, 0, len(response.Data))
+	for _, res := range response.Data {
+		var event DLQEvent
+		if err := decodeAttributes(res.Attributes, &event); err != nil {
+			return nil, err
+		}
+		event.ID = res.ID
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Get returns a DLQ event with its raw envelope and replay history.
+func (c *DLQClient) Get(token, id string) (*DLQEvent, error) {
+	var response struct {
+		Data jsonAPIResource `json:"data"`
+	}
+	if err := c.do(http.MethodGet, "/api/v1/dlq/"+url.PathEscape(id), token, nil, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to get dlq event: %w", err)
+	}
+
+	var event DLQEvent
+	if err := decodeAttributes(response.Data.Attributes, &event); err != nil {
+		return nil, err
+	}
+	event.ID = response.Data.ID
+	return &event, nil
+}
+
+// Replay reprocesses DLQ events through the current ingest pipeline.
+func (c *DLQClient) Replay(token string, req DLQReplayRequest) (*DLQReplayReport, error) {
+	var response struct {
+		Data struct {
+			Attributes DLQReplayReport `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := c.do(http.MethodPost, "/api/v1/dlq/replay", token, nil, req, &response); err != nil {
+		return nil, fmt.Errorf("failed to replay dlq events: %w", err)
+	}
+	return &response.Data.Attributes, nil
+}
+
+// Purge deletes DLQ events matching filters. all must be set to purge without filters.
+func (c *DLQClient) Purge(token string, filters map[string]string, all bool) (int, error) {
+	params := make(map[string]string, len(filters)+1)
+	for k, v := range filters {
+		params[k] = v
+	}
+	if all {
+		params["all"] = "true"
+	}
+
+	var response struct {
+		Data struct {
+			Attributes struct {
+				Deleted int `json:"deleted"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := c.do(http.MethodDelete, "/api/v1/dlq", token, params, nil, &response); err != nil {
+		return 0, fmt.Errorf("failed to purge dlq: %w", err)
+	}
+	return response.Data.Attributes.Deleted, nil
+}
+
+func (c *DLQClient) do(method, path, token string, params map[string]string, body, out interface{}) error {
+	q := url.Values{}
+	for k, v := range params {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	u := c.baseURL + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+
+	var bodyReader io.Reader = http.NoBody
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, u, bodyReader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.api+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		var errResp jsonAPIResponse
+		if json.Unmarshal(bodyBytes, &errResp) == nil && len(errResp.Errors) > 0 {
+			return fmt.Errorf("%s: %s", errResp.Errors[0].Title, errResp.Errors[0].Detail)
+		}
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// decodeAttributes converts JSON:API attributes into a typed struct.
+func decodeAttributes(attrs map[string]interface{}, out interface{}) error {
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
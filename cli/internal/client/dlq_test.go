@@ -0,0 +1,134 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDLQClient(t *testing.T) {
+	client := NewDLQClient("http://localhost:8088")
+
+	assert.NotNil(t, client)
+	assert.Equal(t, "http://localhost:8088", client.baseURL)
+	assert.Equal(t, 60*time.Second, client.client.Timeout)
+}
+
+func TestDLQList_PassesFilters(t *testing.T) {
+	testToken := createTestJWT("user-123")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/dlq", r.URL.Path)
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "Bearer "+testToken, r.Header.Get("Authorization"))
+		assert.Equal(t, "syslog", r.URL.Query().Get("sourcetype"))
+		assert.Equal(t, "24h", r.URL.Query().Get("since"))
+		assert.False(t, r.URL.Query().Has("reason"), "empty filters should be omitted")
+
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{{
+				"type": "dlq_event",
+				"id":   "d-1",
+				"attributes": map[string]interface{}{
+					"reason":     "normalization_failed",
+					"status":     "pending",
+					"sourcetype": "syslog",
+					"attempts":   1,
+				},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewDLQClient(server.URL)
+	events, err := client.List(testToken, map[string]string{"sourcetype": "syslog", "since": "24h", "reason": ""})
+
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "d-1", events[0].ID)
+	assert.Equal(t, "syslog", events[0].SourceType)
+	assert.Equal(t, "pending", events[0].Status)
+	assert.Equal(t, 1, events[0].Attempts)
+}
+
+func TestDLQGet_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/dlq/missing", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"errors": []map[string]string{{"status": "404", "title": "Not Found", "detail": "dlq_event missing not found"}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewDLQClient(server.URL)
+	_, err := client.Get("token", "missing")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Not Found")
+}
+
+func TestDLQReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/dlq/replay", r.URL.Path)
+		assert.Equal(t, "POST", r.Method)
+
+		var req DLQReplayRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, []string{"d-1"}, req.IDs)
+		assert.True(t, req.DryRun)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"type": "dlq_replay",
+				"attributes": DLQReplayReport{
+					DryRun:    true,
+					Total:     1,
+					Succeeded: 1,
+					Outcomes: []DLQReplayOutcome{{
+						ID:     "d-1",
+						Status: "would_replay",
+						Diff:   []DLQFieldChange{{Path: "severity", Op: "added", After: "high"}},
+					}},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewDLQClient(server.URL)
+	report, err := client.Replay("token", DLQReplayRequest{IDs: []string{"d-1"}, DryRun: true})
+
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	require.Len(t, report.Outcomes, 1)
+	assert.Equal(t, "would_replay", report.Outcomes[0].Status)
+	assert.Equal(t, "severity", report.Outcomes[0].Diff[0].Path)
+}
+
+func TestDLQPurge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "true", r.URL.Query().Get("all"))
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"type":       "dlq_purge",
+				"attributes": map[string]interface{}{"deleted": 7},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewDLQClient(server.URL)
+	deleted, err := client.Purge("token", nil, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, 7, deleted)
+}
@@ -0,0 +1,192 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// FindingsClient talks to the standalone findings service, which stores
+// code-scan results (SAST, SCA, secrets) per project.
+type FindingsClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// Finding is a deduplicated scanner finding and its lifecycle state.
+type Finding struct {
+	ID                string          `json:"id"`
+	Fingerprint       string          `json:"fingerprint"`
+	ScanID            string          `json:"scan_id"`
+	Tool              string          `json:"tool"`
+	Project           string          `json:"project"`
+	SignalType        string          `json:"signal_type"`
+	Severity          *string         `json:"severity"`
+	Category          *string         `json:"category"`
+	Route             *string         `json:"route"`
+	FilePath          *string         `json:"file_path"`
+	Line              *int            `json:"line"`
+	Data              json.RawMessage `json:"data"`
+	Status            string          `json:"status"`
+	ReintroducedCount int             `json:"reintroduced_count"`
+	FirstSeen         time.Time       `json:"first_seen"`
+	LastSeen          time.Time       `json:"last_seen"`
+	ResolvedAt        *time.Time      `json:"resolved_at"`
+}
+
+// FindingEvent is one entry in a finding's lifecycle history.
+type FindingEvent struct {
+	ID         string    `json:"id"`
+	ScanID     string    `json:"scan_id"`
+	Event      string    `json:"event"`
+	CommitHash *string   `json:"commit_hash"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// FindingDetail is a finding with its lifecycle history.
+type FindingDetail struct {
+	Finding
+	History []FindingEvent `json:"history"`
+}
+
+// Scan is one scanner run against a project.
+type Scan struct {
+	ID          string    `json:"id"`
+	Tool        string    `json:"tool"`
+	Project     string    `json:"project"`
+	CommitHash  *string   `json:"commit_hash"`
+	ToolVersion *string   `json:"tool_version"`
+	SignalCount int       `json:"signal_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// FindingsPage is one cursor-paginated page of findings.
+type FindingsPage struct {
+	Data       []Finding `json:"data"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// ScansPage is one cursor-paginated page of scans.
+type ScansPage struct {
+	Data       []Scan `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// FindingsSummary aggregates one project's findings.
+type FindingsSummary struct {
+	Project    string         `json:"project"`
+	Open       int            `json:"open"`
+	Resolved   int            `json:"resolved"`
+	BySeverity map[string]int `json:"by_severity"`
+	ByCategory map[string]int `json:"by_category"`
+	ByTool     map[string]int `json:"by_tool"`
+}
+
+// FindingsTrendPoint describes a project's findings after one scan.
+type FindingsTrendPoint struct {
+	ScanID       string    `json:"scan_id"`
+	Tool         string    `json:"tool"`
+	CommitHash   string    `json:"commit_hash,omitempty"`
+	ScannedAt    time.Time `json:"scanned_at"`
+	Open         int       `json:"open"`
+	New          int       `json:"new"`
+	Reintroduced int       `json:"reintroduced"`
+	Resolved     int       `json:"resolved"`
+}
+
+func NewFindingsClient(baseURL string) *FindingsClient {
+	return &FindingsClient{
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ListFindings returns one page of findings. Filters are passed through as
+// query parameters (project, tool, severity, category, status, path, since,
+// until, cursor, limit).
+func (c *FindingsClient) ListFindings(token string, filters map[string]string) (*FindingsPage, error) {
+	var page FindingsPage
+	if err := c.get(token, "/api/v1/findings", filters, &page); err != nil {
+		return nil, fmt.Errorf("failed to list findings: %w", err)
+	}
+	return &page, nil
+}
+
+// GetFinding returns a finding and its history.
+func (c *FindingsClient) GetFinding(token, id string) (*FindingDetail, error) {
+	var detail FindingDetail
+	if err := c.get(token, "/api/v1/findings/"+url.PathEscape(id), nil, &detail); err != nil {
+		return nil, fmt.Errorf("failed to get finding: %w", err)
+	}
+	return &detail, nil
+}
+
+// Summary returns per-project counts of open findings.
+func (c *FindingsClient) Summary(token string, filters map[string]string) ([]FindingsSummary, error) {
+	var summaries []FindingsSummary
+	if err := c.get(token, "/api/v1/findings/summary", filters, &summaries); err != nil {
+		return nil, fmt.Errorf("failed to get findings summary: %w", err)
+	}
+	return summaries, nil
+}
+
+// ListScans returns one page of scans.
+func (c *FindingsClient) ListScans(token string, filters map[string]string) (*ScansPage, error) {
+	var page ScansPage
+	if err := c.get(token, "/api/v1/scans", filters, &page); err != nil {
+		return nil, fmt.Errorf("failed to list scans: %w", err)
+	}
+	return &page, nil
+}
+
+// Trend returns per-scan finding counts for a project, oldest scan first.
+func (c *FindingsClient) Trend(token, project, tool string, limit int) ([]FindingsTrendPoint, error) {
+	filters := map[string]string{"project": project, "tool": tool}
+	if limit > 0 {
+		filters["limit"] = fmt.Sprintf("%d", limit)
+	}
+
+	var points []FindingsTrendPoint
+	if err := c.get(token, "/api/v1/scans/trend", filters, &points); err != nil {
+		return nil, fmt.Errorf("failed to get findings trend: %w", err)
+	}
+	return points, nil
+}
+
+func (c *FindingsClient) get(token, path string, params map[string]string, out interface{}) error {
+	q := url.Values{}
+	for k, v := range params {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	u := c.baseURL + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
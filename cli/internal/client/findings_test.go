@@ -0,0 +1,135 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFindingsClient(t *testing.T) {
+	client := NewFindingsClient("http://localhost:8080")
+
+	assert.NotNil(t, client)
+	assert.Equal(t, "http://localhost:8080", client.baseURL)
+	assert.Equal(t, 30*time.Second, client.client.Timeout)
+}
+
+func TestListFindings_PassesFiltersAndCursor(t *testing.T) {
+	testToken := createTestJWT("user-123")
+	severity := "high"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/findings", r.URL.Path)
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "Bearer "+testToken, r.Header.Get("Authorization"))
+		assert.Equal(t, "api", r.URL.Query().Get("project"))
+		assert.Equal(t, "high", r.URL.Query().Get("severity"))
+		assert.Equal(t, "open", r.URL.Query().Get("status"))
+		assert.Equal(t, "cmd/", r.URL.Query().Get("path"))
+		assert.Equal(t, "abc", r.URL.Query().Get("cursor"))
+		assert.False(t, r.URL.Query().Has("tool"), "empty filters should be omitted")
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(FindingsPage{
+			Data:       []Finding{{ID: "f-1", Project: "api", Severity: &severity, Status: "open"}},
+			NextCursor: "f-1",
+		})
+	}))
+	defer server.Close()
+
+	client := NewFindingsClient(server.URL)
+	page, err := client.ListFindings(testToken, map[string]string{
+		"project":  "api",
+		"severity": "high",
+		"status":   "open",
+		"path":     "cmd/",
+		"cursor":   "abc",
+		"tool":     "",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, page.Data, 1)
+	assert.Equal(t, "f-1", page.Data[0].ID)
+	assert.Equal(t, "f-1", page.NextCursor)
+}
+
+func TestGetFinding_IncludesHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/findings/f-1", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": "f-1",
+			"status": "reintroduced",
+			"reintroduced_count": 1,
+			"history": [
+				{"event": "detected", "scan_id": "s-1"},
+				{"event": "resolved", "scan_id": "s-2"},
+				{"event": "reintroduced", "scan_id": "s-3"}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewFindingsClient(server.URL)
+	f, err := client.GetFinding("token", "f-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "reintroduced", f.Status)
+	assert.Equal(t, 1, f.ReintroducedCount)
+	require.Len(t, f.History, 3)
+	assert.Equal(t, "resolved", f.History[1].Event)
+}
+
+func TestFindingsSummaryAndTrend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/findings/summary":
+			json.NewEncoder(w).Encode([]FindingsSummary{{
+				Project:    "api",
+				Open:       3,
+				BySeverity: map[string]int{"high": 2, "low": 1},
+			}})
+		case "/api/v1/scans/trend":
+			assert.Equal(t, "api", r.URL.Query().Get("project"))
+			assert.Equal(t, "5", r.URL.Query().Get("limit"))
+			json.NewEncoder(w).Encode([]FindingsTrendPoint{{ScanID: "s-1", Open: 3, New: 3}})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewFindingsClient(server.URL)
+
+	summaries, err := client.Summary("token", nil)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, 2, summaries[0].BySeverity["high"])
+
+	points, err := client.Trend("token", "api", "", 5)
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	assert.Equal(t, 3, points[0].New)
+}
+
+func TestListFindings_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"error":"invalid filter: status must be one of open, resolved, reintroduced"}`))
+	}))
+	defer server.Close()
+
+	client := NewFindingsClient(server.URL)
+	_, err := client.ListFindings("token", map[string]string{"status": "bogus"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "422")
+	assert.Contains(t, err.Error(), "status must be one of")
+}
@@ -53,3 +53,143 @@ func (c *IngestClient) SendEvent(hecToken string, event map[string]interface{},
 
 	return nil
 }
+
+// BatchEvent is a raw event with the time it occurred.
+type BatchEvent struct {
+	Event json.RawMessage
+	Time  time.Time
+}
+
+// BatchOptions are the HEC metadata and acknowledgement channel of a batch.
+type BatchOptions struct {
+	Source     string
+	SourceType string
+	Host       string
+	Index      string
+	// Channel requests an ack ID, which QueryAcks reports on once the
+	// batch is indexed.
+	Channel string
+}
+
+// IngestError is a batch rejected by the ingestion service.
+type IngestError struct {
+	StatusCode int
+	Text       string
+}
+
+func (e *IngestError) Error() string {
+	if e.Text != "" {
+		return fmt.Sprintf("ingest failed with status %d: %s", e.StatusCode, e.Text)
+	}
+	return fmt.Sprintf("ingest failed with status %d", e.StatusCode)
+}
+
+// Retryable reports whether the service was busy (backpressure), so the
+// batch may succeed if sent again.
+func (e *IngestError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable
+}
+
+// SendBatch posts events to the HEC event endpoint as concatenated HEC
+// events, keeping each event's original time. Events without a time are
+// stamped with the current time.
+func (c *IngestClient) SendBatch(hecToken string, events []BatchEvent, source, sourcetype string) error {
+	_, err := c.PostBatch(hecToken, events, BatchOptions{Source: source, SourceType: sourcetype})
+	return err
+}
+
+// PostBatch is SendBatch with full metadata. With a channel it returns the
+// batch's ack ID (empty if the service has acknowledgements disabled).
+func (c *IngestClient) PostBatch(hecToken string, events []BatchEvent, opts BatchOptions) (string, error) {
+	var body bytes.Buffer
+	now := time.Now()
+	for _, event := range events {
+		eventTime := event.Time
+		if eventTime.IsZero() {
+			eventTime = now
+		}
+		payload := map[string]interface{}{
+			"event":      event.Event,
+			"source":     opts.Source,
+			"sourcetype": opts.SourceType,
+			"time":       float64(eventTime.UnixMilli()) / 1000,
+		}
+		if opts.Host != "" {
+			payload["host"] = opts.Host
+		}
+		if opts.Index != "" {
+			payload["index"] = opts.Index
+		}
+		line, err := json.Marshal(payload)
+		if err != nil {
+			return "", err
+		}
+		body.Write(line)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest("POST", c.baseURL+"/services/collector/event", &body)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Authorization", "Splunk "+hecToken)
+	req.Header.Set("Content-Type", "application/json")
+	if opts.Channel != "" {
+		req.Header.Set("X-Splunk-Request-Channel", opts.Channel)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Text  string `json:"text"`
+		AckID string `json:"ackId"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &IngestError{StatusCode: resp.StatusCode, Text: result.Text}
+	}
+
+	return result.AckID, nil
+}
+
+// QueryAcks reports which ack IDs of a channel have been indexed. IDs the
+// service no longer knows (expired) are missing from the result.
+func (c *IngestClient) QueryAcks(hecToken, channel string, ackIDs []string) (map[string]bool, error) {
+	body, err := json.Marshal(map[string][]string{"acks": ackIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", c.baseURL+"/services/collector/ack", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Splunk "+hecToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Splunk-Request-Channel", channel)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ack query failed with status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Acks map[string]bool `json:"acks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode ack response: %w", err)
+	}
+	return result.Acks, nil
+}
@@ -224,3 +224,107 @@ func TestSendEvent_SourceAndSourcetype(t *testing.T) {
 		})
 	}
 }
+
+func TestSendBatch(t *testing.T) {
+	eventTime := time.Date(2026, 3, 1, 10, 0, 0, 500000000, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/collector/event", r.URL.Path)
+		assert.Equal(t, "Splunk token", r.Header.Get("Authorization"))
+
+		dec := json.NewDecoder(r.Body)
+		var payloads []map[string]interface{}
+		for dec.More() {
+			var payload map[string]interface{}
+			require.NoError(t, dec.Decode(&payload))
+			payloads = append(payloads, payload)
+		}
+
+		require.Len(t, payloads, 2)
+		assert.Equal(t, "aws:cloudtrail", payloads[0]["sourcetype"])
+		assert.Equal(t, "export.json", payloads[0]["source"])
+		assert.Equal(t, 1772359200.5, payloads[0]["time"])
+		assert.Equal(t, "ConsoleLogin", payloads[0]["event"].(map[string]interface{})["eventName"])
+		assert.Greater(t, payloads[1]["time"], 1772359200.5)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewIngestClient(server.URL)
+	err := client.SendBatch("token", []BatchEvent{
+		{Event: json.RawMessage(`{"eventName": "ConsoleLogin"}`), Time: eventTime},
+		{Event: json.RawMessage(`{"eventName": "CreateUser"}`)},
+	}, "export.json", "aws:cloudtrail")
+	assert.NoError(t, err)
+}
+
+func TestPostBatch_MetadataAndAckID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "channel-1", r.Header.Get("X-Splunk-Request-Channel"))
+
+		var payload map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "hr-01", payload["host"])
+		assert.Equal(t, "history", payload["index"])
+		assert.Equal(t, "users.csv", payload["source"])
+		assert.Equal(t, "hr:users", payload["sourcetype"])
+
+		_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":"7"}`))
+	}))
+	defer server.Close()
+
+	client := NewIngestClient(server.URL)
+	ackID, err := client.PostBatch("token", []BatchEvent{{Event: json.RawMessage(`{"user": "alice"}`)}}, BatchOptions{
+		Source:     "users.csv",
+		SourceType: "hr:users",
+		Host:       "hr-01",
+		Index:      "history",
+		Channel:    "channel-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "7", ackID)
+}
+
+func TestPostBatch_IngestError(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"text":"Server is busy","code":9}`))
+	}))
+	defer server.Close()
+
+	client := NewIngestClient(server.URL)
+	events := []BatchEvent{{Event: json.RawMessage(`{}`)}}
+
+	_, err := client.PostBatch("token", events, BatchOptions{})
+	var ingestErr *IngestError
+	require.ErrorAs(t, err, &ingestErr)
+	assert.Equal(t, http.StatusServiceUnavailable, ingestErr.StatusCode)
+	assert.Equal(t, "ingest failed with status 503: Server is busy", err.Error())
+	assert.True(t, ingestErr.Retryable())
+
+	status = http.StatusBadRequest
+	_, err = client.PostBatch("token", events, BatchOptions{})
+	require.ErrorAs(t, err, &ingestErr)
+	assert.False(t, ingestErr.Retryable())
+}
+
+func TestQueryAcks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/collector/ack", r.URL.Path)
+		assert.Equal(t, "channel-1", r.Header.Get("X-Splunk-Request-Channel"))
+
+		var req struct {
+			Acks []string `json:"acks"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, []string{"1", "2"}, req.Acks)
+
+		_, _ = w.Write([]byte(`{"acks":{"1":true,"2":false}}`))
+	}))
+	defer server.Close()
+
+	acks, err := NewIngestClient(server.URL).QueryAcks("token", "channel-1", []string{"1", "2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"1": true, "2": false}, acks)
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TailOptions selects which live events to stream.
+type TailOptions struct {
+	Query json.RawMessage // Canonical JSON query (filter, select, limit); empty streams everything
+	Rate  int             // Maximum events per second; 0 uses the server default
+}
+
+// TailMessage is one server-sent event from a live tail stream.
+type TailMessage struct {
+	Event string          // "ready", "event" or "dropped"
+	Data  json.RawMessage // JSON payload
+}
+
+// Tail streams events as they are ingested, calling handle for each message
+// until the server ends the stream (for example after the query limit), ctx
+// is cancelled, or handle returns an error.
+func (c *QueryClient) Tail(ctx context.Context, accessToken string, opts TailOptions, handle func(TailMessage) error) error {
+	params := url.Values{}
+	if len(opts.Query) > 0 {
+		params.Set("query", string(opts.Query))
+	}
+	if opts.Rate > 0 {
+		params.Set("rate", strconv.Itoa(opts.Rate))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/live-tail/stream?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	// The stream stays open indefinitely, so it cannot use the client timeout
+	streamClient := *c.client
+	streamClient.Timeout = 0
+
+	resp, err := streamClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("live tail failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	var msg TailMessage
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read stream: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			if msg.Event != "" {
+				if err := handle(msg); err != nil {
+					return err
+				}
+			}
+			msg = TailMessage{}
+		case strings.HasPrefix(line, "event:"):
+			msg.Event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			msg.Data = json.RawMessage(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+}
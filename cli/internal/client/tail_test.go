@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTail_StreamsMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/live-tail/stream", r.URL.Path)
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		assert.Equal(t, `{"limit":2}`, r.URL.Query().Get("query"))
+		assert.Equal(t, "10", r.URL.Query().Get("rate"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: ready\ndata: {\"rate\":10}\n\n")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "event: event\ndata: {\"class_uid\":3002}\n\n")
+		fmt.Fprint(w, "event: dropped\ndata: {\"rate_limited\":3,\"backpressure\":0}\n\n")
+	}))
+	defer server.Close()
+
+	var messages []TailMessage
+	err := NewQueryClient(server.URL).Tail(context.Background(), "test-token",
+		TailOptions{Query: []byte(`{"limit":2}`), Rate: 10},
+		func(msg TailMessage) error {
+			messages = append(messages, msg)
+			return nil
+		})
+
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+	assert.Equal(t, "ready", messages[0].Event)
+	assert.Equal(t, "event", messages[1].Event)
+	assert.JSONEq(t, `{"class_uid":3002}`, string(messages[1].Data))
+	assert.Equal(t, "dropped", messages[2].Event)
+}
+
+func TestTail_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "too many live tail streams (max 5)", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	err := NewQueryClient(server.URL).Tail(context.Background(), "test-token", TailOptions{},
+		func(TailMessage) error { return nil })
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "429")
+	assert.Contains(t, err.Error(), "too many live tail streams")
+}
+
+func TestTail_HandlerErrorStopsStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "event: event\ndata: {}\n\nevent: event\ndata: {}\n\n")
+	}))
+	defer server.Close()
+
+	calls := 0
+	err := NewQueryClient(server.URL).Tail(context.Background(), "test-token", TailOptions{},
+		func(TailMessage) error {
+			calls++
+			return fmt.Errorf("stop")
+		})
+
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,120 @@
+package importer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/client"
+)
+
+// ackTracker polls the acks of sent batches on one channel.
+type ackTracker struct {
+	client  *client.IngestClient
+	token   string
+	channel string
+
+	mu          sync.Mutex
+	outstanding map[string]int // Ack ID -> events in the batch
+	acked       int
+	enabled     bool
+}
+
+func newAckTracker(c *client.IngestClient, token, channel string) *ackTracker {
+	return &ackTracker{client: c, token: token, channel: channel, outstanding: make(map[string]int)}
+}
+
+// add tracks a sent batch. Batches without an ack ID (acknowledgements
+// disabled) are not tracked.
+func (t *ackTracker) add(ackID string, events int) {
+	if ackID == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.enabled = true
+	t.outstanding[ackID] += events
+}
+
+// poll checks outstanding acks every interval until ctx is done.
+func (t *ackTracker) poll(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// drain polls until every ack completed or timeout passed without progress.
+func (t *ackTracker) drain(ctx context.Context, interval, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for {
+		before := t.pending()
+		t.check()
+		after := t.pending()
+		if after == 0 {
+			return
+		}
+		if after < before {
+			deadline = time.Now().Add(timeout)
+		}
+		if !time.Now().Before(deadline) {
+			return
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check queries the outstanding acks once. Query errors are retried on the
+// next poll.
+func (t *ackTracker) check() {
+	t.mu.Lock()
+	ids := make([]string, 0, len(t.outstanding))
+	for id := range t.outstanding {
+		ids = append(ids, id)
+	}
+	t.mu.Unlock()
+
+	for len(ids) > 0 {
+		chunk := ids[:min(len(ids), maxAcksPerQuery)]
+		ids = ids[len(chunk):]
+		acks, err := t.client.QueryAcks(t.token, t.channel, chunk)
+		if err != nil {
+			return
+		}
+		t.mu.Lock()
+		for id, indexed := range acks {
+			if events, ok := t.outstanding[id]; ok && indexed {
+				t.acked += events
+				delete(t.outstanding, id)
+			}
+		}
+		t.mu.Unlock()
+	}
+}
+
+func (t *ackTracker) pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.outstanding)
+}
+
+// result returns whether acks were enabled and the events acked and not.
+func (t *ackTracker) result() (bool, int, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	unacked := 0
+	for _, events := range t.outstanding {
+		unacked += events
+	}
+	return t.enabled, t.acked, unacked
+}
@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Checkpoint records how far each file has been imported, so an interrupted
+// import resumes where it stopped. It is saved after every batch.
+type Checkpoint struct {
+	path  string
+	mu    sync.Mutex
+	Files map[string]*FileCheckpoint `json:"files"`
+}
+
+// FileCheckpoint is the progress of one file. Records counts the leading
+// records of the file that were sent (or rejected by the service, which
+// sending again would not change).
+type FileCheckpoint struct {
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	Records  int       `json:"records"`
+	Complete bool      `json:"complete"`
+}
+
+// LoadCheckpoint reads a checkpoint file, starting a new one if it does not
+// exist.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	c := &Checkpoint{path: path, Files: make(map[string]*FileCheckpoint)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	if c.Files == nil {
+		c.Files = make(map[string]*FileCheckpoint)
+	}
+	return c, nil
+}
+
+// progress returns the saved progress of a file, or nil if it has none or
+// the file changed since.
+func (c *Checkpoint) progress(path string, info os.FileInfo) *FileCheckpoint {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fc := c.Files[checkpointKey(path)]
+	if fc == nil || fc.Size != info.Size() || !fc.ModTime.Equal(info.ModTime()) {
+		return nil
+	}
+	saved := *fc
+	return &saved
+}
+
+// update records a file's progress and saves the checkpoint.
+func (c *Checkpoint) update(path string, info os.FileInfo, records int, complete bool) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Files[checkpointKey(path)] = &FileCheckpoint{
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+		Records:  records,
+		Complete: complete,
+	}
+	return c.save()
+}
+
+// save writes the checkpoint through a temporary file, so an interruption
+// never leaves it truncated. Callers hold the lock.
+func (c *Checkpoint) save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// checkpointKey identifies a file regardless of the working directory.
+func checkpointKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,349 @@
+// Package importer bulk loads local log files and datasets into the
+// ingestion service through HEC. Files are read in order and their batches
+// sent concurrently, with each event's original time; progress is
+// checkpointed so an interrupted import resumes, and the service's acks
+// report which events were indexed.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/client"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/exports"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/parsing"
+)
+
+// maxAcksPerQuery bounds the ack IDs sent in one ack query.
+const maxAcksPerQuery = 1000
+
+// Importer sends files to the HEC event endpoint.
+type Importer struct {
+	Client      *client.IngestClient
+	Token       string
+	BatchSize   int
+	Concurrency int
+	MaxRetries  int           // Attempts for batches refused while the service is busy
+	RetryDelay  time.Duration // First retry delay, doubled per attempt
+	AckTimeout  time.Duration // How long to wait for acks after the last batch
+	AckInterval time.Duration // How often acks are polled
+
+	// Parsers split raw text files by sourcetype; sourcetypes without one
+	// get a line per event.
+	Parsers map[string]*parsing.Parser
+	// Checkpoint, when set, skips the records earlier runs sent and is
+	// updated as batches complete.
+	Checkpoint *Checkpoint
+	// Log, when set, receives progress messages.
+	Log func(format string, args ...interface{})
+}
+
+// Summary is the outcome of an import.
+type Summary struct {
+	Files    int
+	Skipped  int // Records skipped because a checkpoint shows them sent
+	Sent     int // Events accepted by HEC
+	Rejected int // Events in batches HEC refused
+	// Acked counts sent events whose batch ack reports them indexed, and
+	// Unacked those whose ack failed or did not complete in time. Both are
+	// zero when the service has acknowledgements disabled.
+	Acked       int
+	Unacked     int
+	AcksEnabled bool
+	Duration    time.Duration
+}
+
+// batch is a run of consecutive records of one file.
+type batch struct {
+	file   *fileState
+	seq    int
+	events []client.BatchEvent
+}
+
+// fileState tracks the batches of a file so the checkpoint only advances
+// over records whose batches have all completed.
+type fileState struct {
+	file File
+	info os.FileInfo
+
+	mu      sync.Mutex
+	records int         // Leading records completed
+	next    int         // Next batch to complete in order
+	done    map[int]int // Completed batches ahead of next, by size
+	batches int         // Total batches, or -1 while the file is being read
+}
+
+// Run imports files and returns what happened to their events. On error or
+// cancellation the checkpoint still holds the completed progress.
+func (im *Importer) Run(ctx context.Context, files []File) (*Summary, error) {
+	start := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := max(im.Concurrency, 1)
+	summary := &Summary{Files: len(files)}
+	tracker := newAckTracker(im.Client, im.Token, uuid.New().String())
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	// Poll acks while sending, since the service forgets them after a TTL
+	pollCtx, stopPolling := context.WithCancel(context.Background())
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		tracker.poll(pollCtx, im.interval())
+	}()
+
+	jobs := make(chan *batch, concurrency)
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for b := range jobs {
+				ackID, err := im.send(ctx, b, tracker.channel)
+				var ingestErr *client.IngestError
+				switch {
+				case err == nil:
+					mu.Lock()
+					summary.Sent += len(b.events)
+					mu.Unlock()
+					tracker.add(ackID, len(b.events))
+				case errors.As(err, &ingestErr) && !ingestErr.Retryable():
+					// Sending the batch again would be refused again
+					im.logf("%s: batch of %d events rejected: %v", b.file.file.Path, len(b.events), err)
+					mu.Lock()
+					summary.Rejected += len(b.events)
+					mu.Unlock()
+				default:
+					fail(fmt.Errorf("%s: %w", b.file.file.Path, err))
+					continue
+				}
+				if err := im.complete(b.file, b.seq, len(b.events)); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	for _, file := range files {
+		skipped, err := im.readFile(ctx, file, jobs)
+		summary.Skipped += skipped
+		if err != nil {
+			fail(err)
+			break
+		}
+	}
+	close(jobs)
+	workers.Wait()
+
+	stopPolling()
+	<-polled
+	if firstErr == nil {
+		tracker.drain(ctx, im.interval(), im.AckTimeout)
+	}
+	summary.AcksEnabled, summary.Acked, summary.Unacked = tracker.result()
+	summary.Duration = time.Since(start)
+
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+	return summary, firstErr
+}
+
+// readFile reads a file into batches, skipping the records a checkpoint
+// shows were sent, and returns how many it skipped.
+func (im *Importer) readFile(ctx context.Context, file File, jobs chan<- *batch) (int, error) {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	skip := 0
+	if saved := im.Checkpoint.progress(file.Path, info); saved != nil {
+		if saved.Complete {
+			im.logf("%s: already imported, skipping", file.Path)
+			return saved.Records, nil
+		}
+		skip = saved.Records
+	}
+
+	format := file.Format
+	if format == "" {
+		if format, err = exports.DetectFormat(file.Path, f); err != nil {
+			return 0, fmt.Errorf("%s: %w", file.Path, err)
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return 0, err
+		}
+	}
+	read, err := im.reader(format, file.SourceType)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", file.Path, err)
+	}
+
+	if skip > 0 {
+		im.logf("%s: resuming after %d records (%s, %s)", file.Path, skip, file.SourceType, format)
+	} else {
+		im.logf("%s: importing (%s, %s)", file.Path, file.SourceType, format)
+	}
+
+	state := &fileState{file: file, info: info, records: skip, done: make(map[int]int), batches: -1}
+	batchSize := max(im.BatchSize, 1)
+	current := &batch{file: state}
+	seq, n := 0, 0
+	err = read(f, func(record exports.Record) error {
+		n++
+		if n <= skip {
+			return nil
+		}
+		current.events = append(current.events, client.BatchEvent{Event: record.Event, Time: record.Time})
+		if len(current.events) < batchSize {
+			return nil
+		}
+		select {
+		case jobs <- current:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		seq++
+		current = &batch{file: state, seq: seq}
+		return nil
+	})
+	if err != nil {
+		return min(n, skip), fmt.Errorf("%s: %w", file.Path, err)
+	}
+	if len(current.events) > 0 {
+		select {
+		case jobs <- current:
+		case <-ctx.Done():
+			return skip, ctx.Err()
+		}
+		seq++
+	}
+
+	state.mu.Lock()
+	state.batches = seq
+	state.mu.Unlock()
+	return min(n, skip), im.complete(state, -1, 0)
+}
+
+// reader returns the export reader of a format.
+func (im *Importer) reader(format exports.Format, sourceType string) (func(io.Reader, func(exports.Record) error) error, error) {
+	switch format {
+	case exports.FormatJSON:
+		return exports.Read, nil
+	case exports.FormatCSV:
+		return exports.ReadCSV, nil
+	case exports.FormatRaw:
+		parser := im.Parsers[sourceType]
+		if parser == nil {
+			var err error
+			if parser, err = parsing.Compile(parsing.Profile{SourceType: sourceType}); err != nil {
+				return nil, err
+			}
+		}
+		return func(r io.Reader, fn func(exports.Record) error) error {
+			return exports.ReadRaw(r, parser, fn)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q (use json, csv or raw)", format)
+}
+
+// send posts a batch on an ack channel, retrying while the service is busy
+// or unreachable.
+func (im *Importer) send(ctx context.Context, b *batch, channel string) (string, error) {
+	opts := client.BatchOptions{
+		Source:     b.file.file.Source.Source,
+		SourceType: b.file.file.SourceType,
+		Host:       b.file.file.Host,
+		Index:      b.file.file.Index,
+		Channel:    channel,
+	}
+
+	delay := im.RetryDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		ackID, err := im.Client.PostBatch(im.Token, b.events, opts)
+		var ingestErr *client.IngestError
+		if err == nil || (errors.As(err, &ingestErr) && !ingestErr.Retryable()) || attempt >= max(im.MaxRetries, 1) {
+			return ackID, err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// complete marks a batch of a file as done (seq -1 only re-checks whether
+// the file is complete) and checkpoints the records completed in order.
+func (im *Importer) complete(state *fileState, seq, records int) error {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if seq >= 0 {
+		state.done[seq] = records
+	}
+	advanced := seq < 0
+	for {
+		n, ok := state.done[state.next]
+		if !ok {
+			break
+		}
+		delete(state.done, state.next)
+		state.records += n
+		state.next++
+		advanced = true
+	}
+	if !advanced {
+		return nil
+	}
+	complete := state.batches >= 0 && state.next == state.batches
+	if complete {
+		im.logf("%s: done", state.file.Path)
+	}
+	return im.Checkpoint.update(state.file.Path, state.info, state.records, complete)
+}
+
+func (im *Importer) interval() time.Duration {
+	if im.AckInterval > 0 {
+		return im.AckInterval
+	}
+	return 2 * time.Second
+}
+
+func (im *Importer) logf(format string, args ...interface{}) {
+	if im.Log != nil {
+		im.Log(format, args...)
+	}
+}
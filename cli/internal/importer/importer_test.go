@@ -0,0 +1,290 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/client"
+)
+
+// hecServer is a fake HEC endpoint with acks.
+type hecServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	events   []map[string]interface{}
+	requests int
+	acks     map[string]bool
+	// respond, when set, overrides the response to the nth event request
+	// (1-based) with a status code.
+	respond func(n int, events []map[string]interface{}) int
+}
+
+func newHECServer(t *testing.T) *hecServer {
+	s := &hecServer{acks: make(map[string]bool)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if r.URL.Path == "/services/collector/ack" {
+			var req struct {
+				Acks []string `json:"acks"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			result := make(map[string]bool)
+			for _, id := range req.Acks {
+				result[id] = s.acks[id]
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"acks": result})
+			return
+		}
+
+		s.requests++
+		var batch []map[string]interface{}
+		dec := json.NewDecoder(r.Body)
+		for dec.More() {
+			var event map[string]interface{}
+			require.NoError(t, dec.Decode(&event))
+			batch = append(batch, event)
+		}
+		if s.respond != nil {
+			if status := s.respond(s.requests, batch); status != http.StatusOK {
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte(`{"text":"refused","code":6}`))
+				return
+			}
+		}
+		s.events = append(s.events, batch...)
+
+		ackID := ""
+		if r.Header.Get("X-Splunk-Request-Channel") != "" {
+			ackID = fmt.Sprintf("ack-%d", s.requests)
+			s.acks[ackID] = true
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"text": "Success", "code": 0, "ackId": ackID})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *hecServer) received() []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]interface{}(nil), s.events...)
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func newImporter(server *hecServer) *Importer {
+	return &Importer{
+		Client:      client.NewIngestClient(server.URL),
+		Token:       "token",
+		BatchSize:   2,
+		Concurrency: 3,
+		MaxRetries:  1,
+		RetryDelay:  time.Millisecond,
+		AckTimeout:  time.Second,
+		AckInterval: 10 * time.Millisecond,
+	}
+}
+
+func TestRun_ImportsFormatsWithMetadata(t *testing.T) {
+	server := newHECServer(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "okta.ndjson", `{"uuid": "a", "published": "2026-03-01T10:00:00Z"}
+{"uuid": "b", "published": "2026-03-01T10:00:01Z"}
+{"uuid": "c", "published": "2026-03-01T10:00:02Z"}
+`)
+	writeFile(t, dir, "users.csv", "_time,user\n1772359200,alice\n1772359260,bob\n")
+	writeFile(t, dir, "app.log", "line one\nline two\n")
+
+	files, err := Expand([]Source{
+		{Path: filepath.Join(dir, "*.ndjson"), SourceType: "okta:system"},
+		{Path: filepath.Join(dir, "users.csv"), SourceType: "hr:users", Host: "hr-01"},
+		{Path: filepath.Join(dir, "*"), Source: "bulk"},
+	}, Source{SourceType: "app:log", Index: "history"})
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	summary, err := newImporter(server).Run(context.Background(), files)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, summary.Files)
+	assert.Equal(t, 7, summary.Sent)
+	assert.True(t, summary.AcksEnabled)
+	assert.Equal(t, 7, summary.Acked)
+	assert.Zero(t, summary.Unacked)
+	assert.Zero(t, summary.Rejected)
+
+	bySourceType := make(map[string][]map[string]interface{})
+	for _, event := range server.received() {
+		st := event["sourcetype"].(string)
+		bySourceType[st] = append(bySourceType[st], event)
+	}
+	require.Len(t, bySourceType["okta:system"], 3)
+	require.Len(t, bySourceType["hr:users"], 2)
+	require.Len(t, bySourceType["app:log"], 2)
+
+	for _, event := range bySourceType["okta:system"] {
+		assert.Equal(t, filepath.Join(dir, "okta.ndjson"), event["source"])
+		assert.Equal(t, "history", event["index"])
+		if event["event"].(map[string]interface{})["uuid"] == "b" {
+			assert.Equal(t, float64(1772359201), event["time"])
+		}
+	}
+	for _, event := range bySourceType["hr:users"] {
+		assert.Equal(t, "hr-01", event["host"])
+	}
+	assert.Equal(t, "bulk", bySourceType["app:log"][0]["source"])
+	messages := []interface{}{
+		bySourceType["app:log"][0]["event"].(map[string]interface{})["message"],
+		bySourceType["app:log"][1]["event"].(map[string]interface{})["message"],
+	}
+	assert.ElementsMatch(t, []interface{}{"line one", "line two"}, messages)
+}
+
+func TestRun_ResumesFromCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	var lines []string
+	for i := 0; i < 9; i++ {
+		lines = append(lines, fmt.Sprintf(`{"n": %d}`, i))
+	}
+	path := writeFile(t, dir, "events.json", strings.Join(lines, "\n"))
+	files, err := Expand([]Source{{Path: path}}, Source{SourceType: "test"})
+	require.NoError(t, err)
+	checkpointFile := filepath.Join(dir, "import.checkpoint")
+
+	// The service goes away after two batches
+	down := newHECServer(t)
+	down.respond = func(n int, _ []map[string]interface{}) int {
+		if n > 2 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	}
+	im := newImporter(down)
+	im.Concurrency = 1
+	im.Checkpoint, err = LoadCheckpoint(checkpointFile)
+	require.NoError(t, err)
+
+	summary, err := im.Run(context.Background(), files)
+	require.Error(t, err)
+	assert.Equal(t, 4, summary.Sent)
+
+	// The next run sends only the rest
+	up := newHECServer(t)
+	im = newImporter(up)
+	im.Checkpoint, err = LoadCheckpoint(checkpointFile)
+	require.NoError(t, err)
+
+	summary, err = im.Run(context.Background(), files)
+	require.NoError(t, err)
+	assert.Equal(t, 4, summary.Skipped)
+	assert.Equal(t, 5, summary.Sent)
+
+	var sent []float64
+	for _, event := range append(down.received(), up.received()...) {
+		sent = append(sent, event["event"].(map[string]interface{})["n"].(float64))
+	}
+	assert.ElementsMatch(t, []float64{0, 1, 2, 3, 4, 5, 6, 7, 8}, sent)
+
+	// A completed file is skipped entirely
+	again := newHECServer(t)
+	im = newImporter(again)
+	im.Checkpoint, err = LoadCheckpoint(checkpointFile)
+	require.NoError(t, err)
+	summary, err = im.Run(context.Background(), files)
+	require.NoError(t, err)
+	assert.Zero(t, summary.Sent)
+	assert.Empty(t, again.received())
+}
+
+func TestRun_RejectedBatchesAreCounted(t *testing.T) {
+	server := newHECServer(t)
+	server.respond = func(_ int, events []map[string]interface{}) int {
+		for _, event := range events {
+			if event["event"].(map[string]interface{})["bad"] != nil {
+				return http.StatusBadRequest
+			}
+		}
+		return http.StatusOK
+	}
+	path := writeFile(t, t.TempDir(), "events.json", `{"ok": 1} {"ok": 2} {"bad": 3} {"ok": 4} {"ok": 5}`)
+	files, err := Expand([]Source{{Path: path}}, Source{SourceType: "test"})
+	require.NoError(t, err)
+
+	summary, err := newImporter(server).Run(context.Background(), files)
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Sent)
+	assert.Equal(t, 2, summary.Rejected)
+	assert.Equal(t, 3, summary.Acked)
+}
+
+func TestRun_UnacknowledgedBatches(t *testing.T) {
+	server := newHECServer(t)
+	path := writeFile(t, t.TempDir(), "events.json", `{"a": 1} {"a": 2} {"a": 3}`)
+	files, err := Expand([]Source{{Path: path}}, Source{SourceType: "test"})
+	require.NoError(t, err)
+
+	// The first batch's ack never completes
+	server.respond = func(n int, _ []map[string]interface{}) int {
+		if n == 2 {
+			server.acks["ack-1"] = false
+		}
+		return http.StatusOK
+	}
+	im := newImporter(server)
+	im.Concurrency = 1
+	im.AckTimeout = 50 * time.Millisecond
+
+	summary, err := im.Run(context.Background(), files)
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Sent)
+	assert.Equal(t, 1, summary.Acked)
+	assert.Equal(t, 2, summary.Unacked)
+}
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.json", "{}")
+	writeFile(t, dir, "b.json", "{}")
+
+	_, err := Expand([]Source{{Path: filepath.Join(dir, "*.json")}}, Source{})
+	assert.ErrorContains(t, err, "no sourcetype")
+
+	_, err = Expand([]Source{{Path: filepath.Join(dir, "*.csv")}}, Source{SourceType: "x"})
+	assert.ErrorContains(t, err, "no files match")
+
+	manifest := writeFile(t, dir, "import.yaml", `files:
+  - path: "*.json"
+    sourcetype: github:audit
+    host: gh
+    format: json
+`)
+	sources, err := LoadManifest(manifest)
+	require.NoError(t, err)
+	files, err := Expand(sources, Source{SourceType: "ignored"})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, filepath.Join(dir, "a.json"), files[0].Path)
+	assert.Equal(t, "github:audit", files[0].SourceType)
+	assert.Equal(t, "gh", files[1].Host)
+	assert.Equal(t, filepath.Join(dir, "b.json"), files[1].Source.Source)
+}
@@ -0,0 +1,105 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/exports"
+	"gopkg.in/yaml.v3"
+)
+
+// Source is a file or glob to import with the HEC metadata of its events.
+// Empty fields are filled from the command line defaults.
+type Source struct {
+	Path       string         `yaml:"path"`
+	SourceType string         `yaml:"sourcetype"`
+	Source     string         `yaml:"source"`
+	Host       string         `yaml:"host"`
+	Index      string         `yaml:"index"`
+	Format     exports.Format `yaml:"format"` // json, csv or raw; empty to detect
+}
+
+// File is one file to import.
+type File struct {
+	Path string
+	Source
+}
+
+// LoadManifest reads sources from a YAML file with a top-level "files"
+// list. Relative paths are resolved against the manifest's directory.
+func LoadManifest(path string) ([]Source, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest struct {
+		Files []Source `yaml:"files"`
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	if len(manifest.Files) == 0 {
+		return nil, fmt.Errorf("manifest %s has no files", path)
+	}
+	for i := range manifest.Files {
+		if manifest.Files[i].Path == "" {
+			return nil, fmt.Errorf("manifest %s: file %d has no path", path, i)
+		}
+		if !filepath.IsAbs(manifest.Files[i].Path) {
+			manifest.Files[i].Path = filepath.Join(filepath.Dir(path), manifest.Files[i].Path)
+		}
+	}
+	return manifest.Files, nil
+}
+
+// Expand resolves the globs of sources into files, applying defaults to
+// empty fields. A file matched by several sources is imported once, with
+// the first source's metadata; the source of each event defaults to its
+// file's path.
+func Expand(sources []Source, defaults Source) ([]File, error) {
+	var files []File
+	seen := make(map[string]bool)
+	for _, src := range sources {
+		matches, err := filepath.Glob(src.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", src.Path, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", src.Path)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || info.IsDir() || seen[match] {
+				continue
+			}
+			seen[match] = true
+
+			file := File{Path: match, Source: src}
+			if file.SourceType == "" {
+				file.SourceType = defaults.SourceType
+			}
+			if file.SourceType == "" {
+				return nil, fmt.Errorf("no sourcetype for %s (use --sourcetype or set it in the manifest)", match)
+			}
+			if file.Source.Source == "" {
+				file.Source.Source = defaults.Source
+			}
+			if file.Source.Source == "" {
+				file.Source.Source = match
+			}
+			if file.Host == "" {
+				file.Host = defaults.Host
+			}
+			if file.Index == "" {
+				file.Index = defaults.Index
+			}
+			if file.Format == "" {
+				file.Format = defaults.Format
+			}
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
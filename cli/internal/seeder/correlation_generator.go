@@ -0,0 +1,337 @@
+package seeder
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// baselineEventCounts is the per-window event count cycle of a generated
+// event_count baseline (mean 4, standard deviation ~0.82)
+var baselineEventCounts = []int{3, 4, 5}
+
+// baselineFieldValues is the per-window value cycle of a generated numeric
+// field baseline (mean 100, standard deviation ~8.2)
+var baselineFieldValues = []float64{90, 100, 110}
+
+// generateForTemporal generates one event per query, in random order, within
+// the time window. The near miss matches one query too few inside the window
+// and the remaining query just outside it.
+func (g *RuleBasedGenerator) generateForTemporal(nearMiss bool) ([]HECEvent, error) {
+	timeWindow, err := g.Rule.GetTimeWindow()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time window: %w", err)
+	}
+
+	queries, err := g.Rule.GetQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queries: %w", err)
+	}
+	minMatches := g.Rule.GetMinMatches(len(queries))
+
+	groupByValues, err := g.groupByValues()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Generating events for rule '%s' (%s):", g.Rule.Name, g.Rule.Model.CorrelationType)
+	log.Printf("  Rule: %d of %d queries in %s", minMatches, len(queries), timeWindow)
+
+	now := time.Now()
+	spread := time.Duration(float64(timeWindow) * 0.8)
+	inWindow := len(queries)
+	if nearMiss {
+		if minMatches < 2 {
+			return nil, fmt.Errorf("no near miss for min_matches %d", minMatches)
+		}
+		inWindow = minMatches - 1
+	}
+
+	events := make([]HECEvent, 0, len(queries))
+	for i, idx := range rand.Perm(len(queries))[:inWindow] {
+		eventTime := g.eventGen.calculateEventTime(now, spread, i, inWindow)
+		events = append(events, g.buildEvent(queries[idx].Filter, groupByValues, eventTime))
+	}
+	if nearMiss {
+		// A query completing the match falls just outside the window
+		var missing RuleQuery
+		for _, q := range queries {
+			if !g.hasEventFor(events, q) {
+				missing = q
+				break
+			}
+		}
+		if missing.Filter == nil {
+			return nil, fmt.Errorf("queries overlap; no near miss possible")
+		}
+		eventTime := now.Add(-spread - timeWindow - time.Minute)
+		events = append(events, g.buildEvent(missing.Filter, groupByValues, eventTime))
+		log.Printf("  Generating: %d queries in window, '%s' outside it (near miss)", inWindow, missing.Name)
+	}
+
+	log.Printf("  ✓ Generated %d events", len(events))
+	return events, nil
+}
+
+// hasEventFor reports whether any event matches the query
+func (g *RuleBasedGenerator) hasEventFor(events []HECEvent, query RuleQuery) bool {
+	for _, event := range events {
+		if MatchesFilter(event.Event, query.Filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateForTemporalOrdered generates one event per sequence step, in step
+// order and within max_gap of each other. The near miss swaps the last two
+// steps.
+func (g *RuleBasedGenerator) generateForTemporalOrdered(nearMiss bool) ([]HECEvent, error) {
+	timeWindow, err := g.Rule.GetTimeWindow()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time window: %w", err)
+	}
+
+	steps, err := g.Rule.GetSequence()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sequence: %w", err)
+	}
+
+	maxGap, err := g.Rule.GetMaxGap()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get max_gap: %w", err)
+	}
+
+	groupByValues, err := g.groupByValues()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Generating events for rule '%s' (%s):", g.Rule.Name, g.Rule.Model.CorrelationType)
+	log.Printf("  Rule: %d steps in %s, at most %s apart", len(steps), timeWindow, maxGap)
+
+	// Half the allowed gap between steps keeps the sequence well inside both
+	// max_gap and the time window
+	gap := min(maxGap, timeWindow/time.Duration(len(steps))) / 2
+	now := time.Now()
+	times := make([]time.Time, len(steps))
+	for i := range steps {
+		times[i] = now.Add(-time.Duration(len(steps)-1-i) * gap)
+	}
+	if nearMiss {
+		last := len(times) - 1
+		times[last-1], times[last] = times[last], times[last-1]
+		log.Printf("  Generating: steps '%s' and '%s' out of order (near miss)", steps[last-1].Name, steps[last].Name)
+	}
+
+	events := make([]HECEvent, len(steps))
+	for i, step := range steps {
+		events[i] = g.buildEvent(step.Filter, groupByValues, times[i])
+	}
+
+	log.Printf("  ✓ Generated %d events", len(events))
+	return events, nil
+}
+
+// generateForJoin generates a left and a right event sharing the join field
+// values, the right one after the left. The near miss gives the right event
+// a different value for the first join field.
+func (g *RuleBasedGenerator) generateForJoin(nearMiss bool) ([]HECEvent, error) {
+	timeWindow, err := g.Rule.GetTimeWindow()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time window: %w", err)
+	}
+
+	left, right, conditions, err := g.Rule.GetJoin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get join: %w", err)
+	}
+
+	groupByValues, err := g.groupByValues()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Generating events for rule '%s' (%s):", g.Rule.Name, g.Rule.Model.CorrelationType)
+	log.Printf("  Rule: '%s' joined with '%s' on %d fields in %s", left.Name, right.Name, len(conditions), timeWindow)
+
+	now := time.Now()
+	leftEvent := g.buildEvent(left.Filter, groupByValues, now.Add(-timeWindow/2))
+	rightEvent := g.buildEvent(right.Filter, groupByValues, now.Add(-timeWindow/4))
+
+	for i, cond := range conditions {
+		// Prefer a value the left query already requires
+		value := getFieldValue(leftEvent.Event, cond.LeftField)
+		if value == nil {
+			value = g.fieldGen.generateValueForField(cond.LeftField)
+			setFieldValue(leftEvent.Event, cond.LeftField, value)
+		}
+		if nearMiss && i == 0 {
+			value = g.differentValue(cond.RightField, value)
+			log.Printf("  Generating: mismatched %s (near miss)", cond.RightField)
+		}
+		setFieldValue(rightEvent.Event, cond.RightField, value)
+	}
+
+	log.Printf("  ✓ Generated 2 events")
+	return []HECEvent{leftEvent, rightEvent}, nil
+}
+
+// differentValue generates a value for field that differs from value
+func (g *RuleBasedGenerator) differentValue(field string, value interface{}) interface{} {
+	for i := 0; i < 10; i++ {
+		if candidate := g.fieldGen.generateValueForField(field); fmt.Sprint(candidate) != fmt.Sprint(value) {
+			return candidate
+		}
+	}
+	return fmt.Sprintf("%v-other", value)
+}
+
+// generateForBaselineDeviation generates min_baseline_samples comparison
+// windows of steady history across the baseline window, then a current
+// window above the deviation threshold. The near miss keeps the current
+// window at the edge of the normal range.
+func (g *RuleBasedGenerator) generateForBaselineDeviation(nearMiss bool) ([]HECEvent, error) {
+	params, err := g.Rule.GetBaseline()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get baseline parameters: %w", err)
+	}
+
+	filter, err := g.Rule.GetQueryFilter()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query filter: %w", err)
+	}
+
+	groupByValues, err := g.groupByValues()
+	if err != nil {
+		return nil, err
+	}
+
+	// History windows are spaced a whole number of comparison windows apart
+	windows := int(params.BaselineWindow / params.ComparisonWindow)
+	spacing := (windows - 1) / params.MinSamples
+	if spacing < 1 {
+		return nil, fmt.Errorf("baseline_window %s is too short for %d samples of %s",
+			params.BaselineWindow, params.MinSamples, params.ComparisonWindow)
+	}
+
+	countBased := params.Field == "event_count"
+	var history []float64
+	if countBased {
+		for i := 0; i < params.MinSamples; i++ {
+			history = append(history, float64(baselineEventCounts[i%len(baselineEventCounts)]))
+		}
+	} else {
+		for i := 0; i < params.MinSamples; i++ {
+			history = append(history, baselineFieldValues[i%len(baselineFieldValues)])
+		}
+	}
+	mean, stddev := meanStdDev(history)
+	limit := mean + params.DeviationThreshold*stddev
+
+	current := math.Floor(limit) + 1
+	if scaled := math.Ceil(mean + params.DeviationThreshold*stddev*g.Multiplier); scaled > current {
+		current = scaled
+	}
+	if nearMiss {
+		current = math.Floor(limit)
+	}
+
+	log.Printf("Generating events for rule '%s' (%s):", g.Rule.Name, g.Rule.Model.CorrelationType)
+	log.Printf("  Rule: %s more than %.1fσ from a %s baseline (%d samples of %s)",
+		params.Field, params.DeviationThreshold, params.BaselineWindow, params.MinSamples, params.ComparisonWindow)
+	log.Printf("  Generating: baseline %.1f±%.1f, current %.0f", mean, stddev, current)
+
+	now := time.Now()
+	var events []HECEvent
+	addWindow := func(index int, value float64) {
+		// Window index 0 is the current comparison window, counting back from now
+		end := now.Add(-time.Duration(index) * params.ComparisonWindow)
+		span := time.Duration(float64(params.ComparisonWindow) * 0.8)
+		if !countBased {
+			event := g.buildEvent(filter, groupByValues, end.Add(-params.ComparisonWindow/2))
+			setFieldValue(event.Event, params.Field, value)
+			events = append(events, event)
+			return
+		}
+		count := int(value)
+		for i := 0; i < count; i++ {
+			eventTime := g.eventGen.calculateEventTime(end.Add(-params.ComparisonWindow/10), span, i, count)
+			events = append(events, g.buildEvent(filter, groupByValues, eventTime))
+		}
+	}
+
+	for i, value := range history {
+		addWindow((i+1)*spacing, value)
+	}
+	addWindow(0, current)
+
+	log.Printf("  ✓ Generated %d events", len(events))
+	return events, nil
+}
+
+// generateForMissingEvent generates regular heartbeats for one entity that
+// stop long enough to count as alert_after_missing missed intervals. The
+// near miss stops just short of that.
+func (g *RuleBasedGenerator) generateForMissingEvent(nearMiss bool) ([]HECEvent, error) {
+	params, err := g.Rule.GetMissingEvent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get missing_event parameters: %w", err)
+	}
+
+	filter, err := g.Rule.GetQueryFilter()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query filter: %w", err)
+	}
+
+	entityValues := g.fieldGen.generateGroupByValues([]string{params.EntityField})
+
+	silence := time.Duration(params.AlertAfterMissing)*params.ExpectedInterval + params.GracePeriod
+	if nearMiss {
+		silence -= params.ExpectedInterval / 10
+	} else {
+		silence += params.ExpectedInterval / 10
+	}
+
+	log.Printf("Generating events for rule '%s' (%s):", g.Rule.Name, g.Rule.Model.CorrelationType)
+	log.Printf("  Rule: %d missed %s intervals of %s (grace %s)",
+		params.AlertAfterMissing, params.ExpectedInterval, params.EntityField, params.GracePeriod)
+	log.Printf("  Generating: heartbeats, then %s of silence", silence)
+
+	now := time.Now()
+	beats := params.AlertAfterMissing + 3
+	events := make([]HECEvent, beats)
+	for i := 0; i < beats; i++ {
+		eventTime := now.Add(-silence - time.Duration(beats-1-i)*params.ExpectedInterval)
+		events[i] = g.buildEvent(filter, entityValues, eventTime)
+	}
+
+	log.Printf("  ✓ Generated %d events", len(events))
+	return events, nil
+}
+
+// groupByValues generates consistent values for the rule's group_by fields
+func (g *RuleBasedGenerator) groupByValues() (map[string]interface{}, error) {
+	groupByFields, err := g.Rule.GetGroupByFields()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group_by fields: %w", err)
+	}
+	return g.fieldGen.generateGroupByValues(groupByFields), nil
+}
+
+// meanStdDev returns the mean and population standard deviation of values
+func meanStdDev(values []float64) (mean, stddev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		stddev += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(stddev / float64(len(values)))
+}
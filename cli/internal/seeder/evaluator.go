@@ -0,0 +1,404 @@
+package seeder
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EvaluateRule approximates the correlation engine over in-memory events and
+// returns the group keys (group_by values joined with "|") for which the rule
+// triggers at now. It is used to check generated events before sending them
+// and as an in-process stand-in for the stack.
+func EvaluateRule(rule *DetectionRule, events []HECEvent, now time.Time) ([]string, error) {
+	if supported, reason := rule.IsSupported(); !supported {
+		return nil, fmt.Errorf("%s", reason)
+	}
+
+	var triggered []string
+	var err error
+	switch rule.Model.CorrelationType {
+	case "event_count", "value_count":
+		triggered, err = evaluateCount(rule, events)
+	case "temporal":
+		triggered, err = evaluateTemporal(rule, events)
+	case "temporal_ordered":
+		triggered, err = evaluateTemporalOrdered(rule, events)
+	case "join":
+		triggered, err = evaluateJoin(rule, events)
+	case "baseline_deviation":
+		triggered, err = evaluateBaselineDeviation(rule, events, now)
+	case "missing_event":
+		triggered, err = evaluateMissingEvent(rule, events, now)
+	default:
+		return nil, fmt.Errorf("unsupported correlation type: %s", rule.Model.CorrelationType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(triggered)
+	return triggered, nil
+}
+
+// groupKey joins an event's correlation field values
+func groupKey(rule *DetectionRule, event map[string]interface{}) string {
+	return strings.Join(rule.GroupValues(event), "|")
+}
+
+// groupMatching groups the events matching filter by group key, sorted by time
+func groupMatching(rule *DetectionRule, events []HECEvent, filter *QueryFilter) map[string][]HECEvent {
+	groups := make(map[string][]HECEvent)
+	for _, event := range events {
+		if MatchesFilter(event.Event, filter) {
+			key := groupKey(rule, event.Event)
+			groups[key] = append(groups[key], event)
+		}
+	}
+	for _, group := range groups {
+		sort.Slice(group, func(i, j int) bool { return group[i].Time < group[j].Time })
+	}
+	return groups
+}
+
+// evaluateCount compares the count (or distinct value count) within any
+// window-sized span of each group against the threshold
+func evaluateCount(rule *DetectionRule, events []HECEvent) ([]string, error) {
+	filter, err := rule.GetQueryFilter()
+	if err != nil {
+		return nil, err
+	}
+	window, err := rule.GetTimeWindow()
+	if err != nil {
+		return nil, err
+	}
+	threshold, operator, err := rule.GetThreshold()
+	if err != nil {
+		return nil, err
+	}
+	var countField string
+	if rule.Model.CorrelationType == "value_count" {
+		if countField, err = rule.GetValueCountField(); err != nil {
+			return nil, err
+		}
+	}
+
+	var triggered []string
+	for key, group := range groupMatching(rule, events, filter) {
+		if thresholdMet(maxWindowCount(group, window, countField), threshold, operator) {
+			triggered = append(triggered, key)
+		}
+	}
+	return triggered, nil
+}
+
+// maxWindowCount returns the highest number of events (or distinct
+// countField values) within any window-sized span of time-sorted events
+func maxWindowCount(events []HECEvent, window time.Duration, countField string) int {
+	best := 0
+	for start := range events {
+		end := events[start].Time + window.Seconds()
+		distinct := make(map[string]bool)
+		count := 0
+		for _, event := range events[start:] {
+			if event.Time > end {
+				break
+			}
+			count++
+			if countField != "" {
+				if value := getFieldValue(event.Event, countField); value != nil {
+					distinct[fmt.Sprint(value)] = true
+				}
+			}
+		}
+		if countField != "" {
+			count = len(distinct)
+		}
+		best = max(best, count)
+	}
+	return best
+}
+
+func thresholdMet(count int, threshold float64, operator string) bool {
+	value := float64(count)
+	switch operator {
+	case "gt":
+		return value > threshold
+	case "gte":
+		return value >= threshold
+	case "lt":
+		return value < threshold
+	case "lte":
+		return value <= threshold
+	case "eq":
+		return math.Abs(value-threshold) < 1e-9
+	default:
+		return false
+	}
+}
+
+// queryEvent is an event matched by one query of a multi-query rule
+type queryEvent struct {
+	query int
+	time  float64
+	event HECEvent
+}
+
+// matchQueries returns, per group, the events matching each query in time
+// order
+func matchQueries(rule *DetectionRule, events []HECEvent, queries []RuleQuery) map[string][]queryEvent {
+	groups := make(map[string][]queryEvent)
+	for _, event := range events {
+		for i, q := range queries {
+			if MatchesFilter(event.Event, q.Filter) {
+				key := groupKey(rule, event.Event)
+				groups[key] = append(groups[key], queryEvent{query: i, time: event.Time, event: event})
+			}
+		}
+	}
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool { return group[i].time < group[j].time })
+	}
+	return groups
+}
+
+// evaluateTemporal triggers when min_matches distinct queries match within
+// one time window
+func evaluateTemporal(rule *DetectionRule, events []HECEvent) ([]string, error) {
+	window, err := rule.GetTimeWindow()
+	if err != nil {
+		return nil, err
+	}
+	queries, err := rule.GetQueries()
+	if err != nil {
+		return nil, err
+	}
+	minMatches := rule.GetMinMatches(len(queries))
+
+	var triggered []string
+	for key, group := range matchQueries(rule, events, queries) {
+		for start := range group {
+			end := group[start].time + window.Seconds()
+			distinct := make(map[int]bool)
+			for _, m := range group[start:] {
+				if m.time > end {
+					break
+				}
+				distinct[m.query] = true
+			}
+			if len(distinct) >= minMatches {
+				triggered = append(triggered, key)
+				break
+			}
+		}
+	}
+	return triggered, nil
+}
+
+// evaluateTemporalOrdered triggers when the sequence steps match in order,
+// each within max_gap of the previous and all within the time window
+func evaluateTemporalOrdered(rule *DetectionRule, events []HECEvent) ([]string, error) {
+	window, err := rule.GetTimeWindow()
+	if err != nil {
+		return nil, err
+	}
+	steps, err := rule.GetSequence()
+	if err != nil {
+		return nil, err
+	}
+	maxGap, err := rule.GetMaxGap()
+	if err != nil {
+		return nil, err
+	}
+
+	var triggered []string
+	for key, group := range matchQueries(rule, events, steps) {
+		// reached maps each event matching the current step to the latest
+		// start of a valid partial sequence ending at it
+		type partial struct{ time, start float64 }
+		var reached []partial
+		for _, m := range group {
+			if m.query == 0 {
+				reached = append(reached, partial{m.time, m.time})
+			}
+		}
+		for step := 1; step < len(steps) && len(reached) > 0; step++ {
+			var next []partial
+			for _, m := range group {
+				if m.query != step {
+					continue
+				}
+				best, ok := 0.0, false
+				for _, p := range reached {
+					if m.time > p.time && m.time-p.time <= maxGap.Seconds() && m.time-p.start <= window.Seconds() {
+						if !ok || p.start > best {
+							best, ok = p.start, true
+						}
+					}
+				}
+				if ok {
+					next = append(next, partial{m.time, best})
+				}
+			}
+			reached = next
+		}
+		if len(reached) > 0 {
+			triggered = append(triggered, key)
+		}
+	}
+	return triggered, nil
+}
+
+// evaluateJoin triggers when a right event follows a left event within the
+// time window (either order when controller.detection.order is "any") and
+// every join condition holds. Group keys are the joined values.
+func evaluateJoin(rule *DetectionRule, events []HECEvent) ([]string, error) {
+	window, err := rule.GetTimeWindow()
+	if err != nil {
+		return nil, err
+	}
+	left, right, conditions, err := rule.GetJoin()
+	if err != nil {
+		return nil, err
+	}
+	anyOrder := rule.Controller.Detection.Order == "any"
+
+	var lefts, rights []HECEvent
+	for _, event := range events {
+		if MatchesFilter(event.Event, left.Filter) {
+			lefts = append(lefts, event)
+		}
+		if MatchesFilter(event.Event, right.Filter) {
+			rights = append(rights, event)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var triggered []string
+	for _, l := range lefts {
+		for _, r := range rights {
+			delta := r.Time - l.Time
+			if anyOrder {
+				delta = math.Abs(delta)
+			}
+			if delta < 0 || delta > window.Seconds() {
+				continue
+			}
+
+			values := make([]string, 0, len(conditions))
+			joined := true
+			for _, cond := range conditions {
+				lv := getFieldValue(l.Event, cond.LeftField)
+				rv := getFieldValue(r.Event, cond.RightField)
+				if lv == nil || rv == nil || fmt.Sprint(lv) != fmt.Sprint(rv) {
+					joined = false
+					break
+				}
+				values = append(values, fmt.Sprint(lv))
+			}
+			if key := strings.Join(values, "|"); joined && !seen[key] {
+				seen[key] = true
+				triggered = append(triggered, key)
+			}
+		}
+	}
+	return triggered, nil
+}
+
+// evaluateBaselineDeviation splits the baseline window into comparison
+// windows counting back from now, and triggers when the current window's
+// value is more than deviation_threshold standard deviations above the mean
+// of the non-empty baseline windows
+func evaluateBaselineDeviation(rule *DetectionRule, events []HECEvent, now time.Time) ([]string, error) {
+	params, err := rule.GetBaseline()
+	if err != nil {
+		return nil, err
+	}
+	filter, err := rule.GetQueryFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	windows := int(params.BaselineWindow / params.ComparisonWindow)
+	nowSecs := float64(now.UnixNano()) / 1e9
+
+	var triggered []string
+	for key, group := range groupMatching(rule, events, filter) {
+		// Per comparison window: event count, or sum and count of field values
+		sums := make(map[int]float64)
+		counts := make(map[int]int)
+		for _, event := range group {
+			age := nowSecs - event.Time
+			if age < 0 {
+				continue
+			}
+			index := int(age / params.ComparisonWindow.Seconds())
+			if index > windows {
+				continue
+			}
+			if params.Field == "event_count" {
+				counts[index]++
+				continue
+			}
+			if value, ok := toFloat64(getFieldValue(event.Event, params.Field)); ok {
+				sums[index] += value
+				counts[index]++
+			}
+		}
+		if counts[0] == 0 {
+			continue
+		}
+
+		windowValue := func(index int) float64 {
+			if params.Field == "event_count" {
+				return float64(counts[index])
+			}
+			return sums[index] / float64(counts[index])
+		}
+
+		var samples []float64
+		for index := range counts {
+			if index > 0 {
+				samples = append(samples, windowValue(index))
+			}
+		}
+		if len(samples) < params.MinSamples {
+			continue
+		}
+
+		mean, stddev := meanStdDev(samples)
+		current := windowValue(0)
+		if (stddev == 0 && current > mean) || (stddev > 0 && (current-mean)/stddev > params.DeviationThreshold) {
+			triggered = append(triggered, key)
+		}
+	}
+	return triggered, nil
+}
+
+// evaluateMissingEvent triggers for entities whose last matching event is
+// alert_after_missing expected intervals (plus the grace period) old
+func evaluateMissingEvent(rule *DetectionRule, events []HECEvent, now time.Time) ([]string, error) {
+	params, err := rule.GetMissingEvent()
+	if err != nil {
+		return nil, err
+	}
+	filter, err := rule.GetQueryFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	nowSecs := float64(now.UnixNano()) / 1e9
+	var triggered []string
+	for key, group := range groupMatching(rule, events, filter) {
+		if key == "" {
+			continue // no entity
+		}
+		silence := nowSecs - group[len(group)-1].Time - params.GracePeriod.Seconds()
+		if missed := int(silence / params.ExpectedInterval.Seconds()); silence > 0 && missed >= params.AlertAfterMissing {
+			triggered = append(triggered, key)
+		}
+	}
+	return triggered, nil
+}
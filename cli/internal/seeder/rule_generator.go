@@ -37,8 +37,21 @@ func NewRuleBasedGenerator(rule *DetectionRule, multiplier float64, params map[s
 	}
 }
 
-// GenerateEvents creates events based on the rule type
+// GenerateEvents creates events that should trigger the rule
 func (g *RuleBasedGenerator) GenerateEvents() ([]HECEvent, error) {
+	return g.generate(false)
+}
+
+// GenerateNearMiss creates events that come as close as possible to
+// triggering the rule without doing so (one event under the threshold, an
+// out-of-order sequence, a mismatched join key, ...), so rule authors can
+// check that the rule stays quiet
+func (g *RuleBasedGenerator) GenerateNearMiss() ([]HECEvent, error) {
+	return g.generate(true)
+}
+
+// generate dispatches on the rule's correlation type
+func (g *RuleBasedGenerator) generate(nearMiss bool) ([]HECEvent, error) {
 	// Check if rule is supported
 	if supported, reason := g.Rule.IsSupported(); !supported {
 		return nil, fmt.Errorf("rule not supported: %s", reason)
@@ -46,16 +59,28 @@ func (g *RuleBasedGenerator) GenerateEvents() ([]HECEvent, error) {
 
 	switch g.Rule.Model.CorrelationType {
 	case "event_count":
-		return g.generateForEventCount()
+		return g.generateForEventCount(nearMiss)
 	case "value_count":
-		return g.generateForValueCount()
+		return g.generateForValueCount(nearMiss)
+	case "temporal":
+		return g.generateForTemporal(nearMiss)
+	case "temporal_ordered":
+		return g.generateForTemporalOrdered(nearMiss)
+	case "join":
+		return g.generateForJoin(nearMiss)
+	case "baseline_deviation":
+		return g.generateForBaselineDeviation(nearMiss)
+	case "missing_event":
+		return g.generateForMissingEvent(nearMiss)
 	default:
 		return nil, fmt.Errorf("unsupported correlation type: %s", g.Rule.Model.CorrelationType)
 	}
 }
 
-// generateForEventCount generates events for event_count correlation type
-func (g *RuleBasedGenerator) generateForEventCount() ([]HECEvent, error) {
+// generateForEventCount generates events for event_count correlation type.
+// The near miss is one event short of the threshold, or a single event
+// failing one filter condition when one event is enough to trigger.
+func (g *RuleBasedGenerator) generateForEventCount(nearMiss bool) ([]HECEvent, error) {
 	// Extract rule parameters
 	threshold, operator, err := g.Rule.GetThreshold()
 	if err != nil {
@@ -79,10 +104,20 @@ func (g *RuleBasedGenerator) generateForEventCount() ([]HECEvent, error) {
 
 	// Calculate number of events to generate
 	eventCount := g.calculateEventCount(threshold, operator)
+	if nearMiss {
+		if eventCount, err = nearMissCount(threshold, operator); err != nil {
+			// A single event triggers the rule; come close on the filter instead
+			return g.generateFilterNearMiss(filter, groupByFields)
+		}
+	}
 
 	log.Printf("Generating events for rule '%s' (%s):", g.Rule.Name, g.Rule.Model.CorrelationType)
 	log.Printf("  Rule threshold: %.0f events in %s", threshold, timeWindow)
-	log.Printf("  Generating: %d events (%.1fx multiplier)", eventCount, g.Multiplier)
+	if nearMiss {
+		log.Printf("  Generating: %d events (near miss)", eventCount)
+	} else {
+		log.Printf("  Generating: %d events (%.1fx multiplier)", eventCount, g.Multiplier)
+	}
 
 	// Generate events
 	events := make([]HECEvent, eventCount)
@@ -128,8 +163,10 @@ func (g *RuleBasedGenerator) generateForEventCount() ([]HECEvent, error) {
 	return events, nil
 }
 
-// generateForValueCount generates events for value_count correlation type
-func (g *RuleBasedGenerator) generateForValueCount() ([]HECEvent, error) {
+// generateForValueCount generates events for value_count correlation type.
+// The near miss sends as many events, but repeats values so one distinct
+// value short of the threshold is seen.
+func (g *RuleBasedGenerator) generateForValueCount(nearMiss bool) ([]HECEvent, error) {
 	// Extract rule parameters
 	threshold, operator, err := g.Rule.GetThreshold()
 	if err != nil {
@@ -157,22 +194,32 @@ func (g *RuleBasedGenerator) generateForValueCount() ([]HECEvent, error) {
 	}
 
 	// Calculate number of unique values to generate
-	uniqueValueCount := g.calculateEventCount(threshold, operator)
+	eventCount := g.calculateEventCount(threshold, operator)
+	uniqueValueCount := eventCount
+	if nearMiss {
+		if uniqueValueCount, err = nearMissCount(threshold, operator); err != nil {
+			return nil, err
+		}
+	}
 
 	log.Printf("Generating events for rule '%s' (%s):", g.Rule.Name, g.Rule.Model.CorrelationType)
 	log.Printf("  Rule threshold: %.0f unique values in %s", threshold, timeWindow)
-	log.Printf("  Generating: %d unique values (%.1fx multiplier)", uniqueValueCount, g.Multiplier)
+	if nearMiss {
+		log.Printf("  Generating: %d events with %d unique values (near miss)", eventCount, uniqueValueCount)
+	} else {
+		log.Printf("  Generating: %d unique values (%.1fx multiplier)", uniqueValueCount, g.Multiplier)
+	}
 
 	// Generate events with varying values
-	events := make([]HECEvent, uniqueValueCount)
+	events := make([]HECEvent, eventCount)
 	now := time.Now()
 
 	// Generate consistent values for group_by fields
 	groupByValues := g.fieldGen.generateGroupByValues(groupByFields)
 
-	for i := 0; i < uniqueValueCount; i++ {
+	for i := 0; i < eventCount; i++ {
 		// Calculate event time with jitter
-		eventTime := g.eventGen.calculateEventTime(now, timeWindow, i, uniqueValueCount)
+		eventTime := g.eventGen.calculateEventTime(now, timeWindow, i, eventCount)
 
 		// Create base event matching the filter
 		event := g.eventGen.createEventMatchingFilter(filter)
@@ -181,7 +228,7 @@ func (g *RuleBasedGenerator) generateForValueCount() ([]HECEvent, error) {
 		g.eventGen.applyGroupByValues(event, groupByValues)
 
 		// Generate unique value for the count field
-		uniqueValue := g.fieldGen.generateUniqueValueForField(countField, i)
+		uniqueValue := g.fieldGen.generateUniqueValueForField(countField, i%uniqueValueCount)
 		setFieldValue(event, countField, uniqueValue)
 
 		// Enrich network events with required OCSF fields
@@ -207,7 +254,7 @@ func (g *RuleBasedGenerator) generateForValueCount() ([]HECEvent, error) {
 		}
 	}
 
-	log.Printf("  ✓ Generated %d events with unique %s values", len(events), countField)
+	log.Printf("  ✓ Generated %d events with %d unique %s values", len(events), uniqueValueCount, countField)
 
 	return events, nil
 }
@@ -233,3 +280,63 @@ func (g *RuleBasedGenerator) calculateEventCount(threshold float64, operator str
 
 	return int(math.Ceil(baseCount))
 }
+
+// nearMissCount returns the highest count that still fails the threshold
+func nearMissCount(threshold float64, operator string) (int, error) {
+	var count int
+	switch operator {
+	case "gt":
+		count = int(math.Floor(threshold))
+	case "gte", "eq":
+		count = int(math.Ceil(threshold)) - 1
+	default:
+		return 0, fmt.Errorf("no near miss for threshold operator %s", operator)
+	}
+	if count < 1 {
+		return 0, fmt.Errorf("no near miss below threshold %.0f", threshold)
+	}
+	return count, nil
+}
+
+// generateFilterNearMiss generates one event that meets every condition of
+// an "and" filter but the last, for rules a single matching event triggers
+func (g *RuleBasedGenerator) generateFilterNearMiss(filter *QueryFilter, groupByFields []string) ([]HECEvent, error) {
+	groupByValues := g.fieldGen.generateGroupByValues(groupByFields)
+	event := g.buildEvent(filter, groupByValues, time.Now().Add(-time.Minute))
+
+	failing := filter
+	if filter.Type == "and" {
+		for i := len(filter.Conditions) - 1; i >= 0; i-- {
+			if filter.Conditions[i].Type != "not" {
+				failing = &filter.Conditions[i]
+				break
+			}
+		}
+	}
+	g.eventGen.applyNonMatchingFilter(event.Event, failing)
+	if MatchesFilter(event.Event, filter) {
+		return nil, fmt.Errorf("no near miss: could not generate an event failing the filter")
+	}
+
+	log.Printf("  Generating: 1 event failing one filter condition (near miss)")
+	return []HECEvent{event}, nil
+}
+
+// buildEvent creates an enriched event at t matching filter and carrying the
+// correlated group values
+func (g *RuleBasedGenerator) buildEvent(filter *QueryFilter, groupByValues map[string]interface{}, t time.Time) HECEvent {
+	event := g.eventGen.createEventMatchingFilterWithSeed(filter, groupByValues)
+	g.eventGen.applyGroupByValues(event, groupByValues)
+	g.ocsfEnrich.enrichEvent(event)
+
+	return HECEvent{
+		Time:       hecTime(t),
+		Event:      event,
+		SourceType: g.eventGen.determineSourceType(event),
+	}
+}
+
+// hecTime converts a time to HEC's fractional epoch seconds
+func hecTime(t time.Time) float64 {
+	return float64(t.Unix()) + float64(t.Nanosecond())/1e9
+}
@@ -0,0 +1,249 @@
+package seeder
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	failedLoginFilter = `{"type": "and", "conditions": [
+		{"field": ".class_uid", "operator": "eq", "value": 3002},
+		{"field": ".status_id", "operator": "eq", "value": 2}
+	]}`
+	processFilter = `{"field": ".class_uid", "operator": "eq", "value": 1007}`
+	networkFilter = `{"field": ".class_uid", "operator": "eq", "value": 4001}`
+)
+
+var generatorRules = map[string]string{
+	"event_count": `{
+		"name": "failed_logins",
+		"model": {"correlation_type": "event_count", "parameters": {
+			"time_window": "5m",
+			"query": {"filter": ` + failedLoginFilter + `},
+			"threshold": {"value": 5, "operator": "gt"},
+			"group_by": [".actor.user.name"]
+		}}
+	}`,
+	"value_count": `{
+		"name": "port_scanning",
+		"model": {"correlation_type": "value_count", "parameters": {
+			"time_window": "1m",
+			"query": {"filter": ` + networkFilter + `},
+			"field": ".dst_endpoint.port",
+			"threshold": {"value": 20, "operator": "gt"},
+			"group_by": [".src_endpoint.ip"]
+		}}
+	}`,
+	"temporal": `{
+		"name": "recon_activity",
+		"model": {"correlation_type": "temporal", "parameters": {
+			"time_window": "10m",
+			"min_matches": 3,
+			"group_by": [".actor.user.name"],
+			"queries": [
+				{"name": "failed_login", "query": {"filter": ` + failedLoginFilter + `}},
+				{"name": "process", "query": {"filter": ` + processFilter + `}},
+				{"name": "network", "query": {"filter": ` + networkFilter + `}}
+			]
+		}}
+	}`,
+	"temporal_ordered": `{
+		"name": "privilege_escalation_after_failed_login",
+		"model": {"correlation_type": "temporal_ordered", "parameters": {
+			"time_window": "15m",
+			"max_gap": "10m",
+			"group_by": [".actor.user.name"],
+			"sequence": [
+				{"step": 2, "name": "escalation", "query": {"filter": ` + processFilter + `}},
+				{"step": 1, "name": "failed_login", "query": {"filter": ` + failedLoginFilter + `}}
+			]
+		}}
+	}`,
+	"join": `{
+		"name": "login_then_connection",
+		"model": {"correlation_type": "join", "parameters": {
+			"time_window": "10m",
+			"left_query": {"name": "login", "query": {"filter": {"field": ".class_uid", "operator": "eq", "value": 3002}}},
+			"right_query": {"name": "connection", "query": {"filter": ` + networkFilter + `}},
+			"join_conditions": [{"left_field": ".src_endpoint.ip", "right_field": ".src_endpoint.ip", "operator": "eq"}]
+		}}
+	}`,
+	"baseline_deviation": `{
+		"name": "login_spike",
+		"model": {"correlation_type": "baseline_deviation", "parameters": {
+			"baseline_window": "1d",
+			"comparison_window": "5m",
+			"field": "event_count",
+			"sensitivity": "low",
+			"min_baseline_samples": 50,
+			"group_by": [".actor.user.name"]
+		}},
+		"controller": {"detection": {"query": {"filter": {"field": ".class_uid", "operator": "eq", "value": 3002}}}}
+	}`,
+	"baseline_deviation_field": `{
+		"name": "large_transfers",
+		"model": {"correlation_type": "baseline_deviation", "parameters": {
+			"baseline_window": "7d",
+			"comparison_window": "1h",
+			"field": ".traffic.bytes_out",
+			"deviation_threshold": 2.5,
+			"min_baseline_samples": 30,
+			"group_by": [".src_endpoint.ip"]
+		}},
+		"controller": {"detection": {"query": {"filter": ` + networkFilter + `}}}
+	}`,
+	"missing_event": `{
+		"name": "agent_heartbeat",
+		"model": {"correlation_type": "missing_event", "parameters": {
+			"expected_interval": "5m",
+			"grace_period": "30s",
+			"entity_field": ".device.hostname",
+			"alert_after_missing": 2
+		}},
+		"controller": {"detection": {"query": {"filter": ` + processFilter + `}}}
+	}`,
+}
+
+func parseTestRule(t *testing.T, data string) *DetectionRule {
+	t.Helper()
+	var rule DetectionRule
+	require.NoError(t, json.Unmarshal([]byte(data), &rule))
+	return &rule
+}
+
+func TestRuleBasedGenerator_PositiveAndNearMiss(t *testing.T) {
+	for name, data := range generatorRules {
+		t.Run(name, func(t *testing.T) {
+			rule := parseTestRule(t, data)
+			generator := NewRuleBasedGenerator(rule, 1.5, nil)
+
+			positive, err := generator.GenerateEvents()
+			require.NoError(t, err)
+			triggered, err := EvaluateRule(rule, positive, time.Now())
+			require.NoError(t, err)
+			assert.Len(t, triggered, 1, "positive set triggers once")
+			assert.NoError(t, ValidateEventsMatchRule(rule, positive))
+
+			nearMiss, err := generator.GenerateNearMiss()
+			require.NoError(t, err)
+			require.NotEmpty(t, nearMiss)
+			triggered, err = EvaluateRule(rule, nearMiss, time.Now())
+			require.NoError(t, err)
+			assert.Empty(t, triggered, "near miss must not trigger")
+			assert.NoError(t, ValidateNearMiss(rule, nearMiss))
+		})
+	}
+}
+
+func TestRuleBasedGenerator_TemporalOrderedNearMissIsOutOfOrder(t *testing.T) {
+	rule := parseTestRule(t, generatorRules["temporal_ordered"])
+
+	events, err := NewRuleBasedGenerator(rule, 1.5, nil).GenerateNearMiss()
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	steps, err := rule.GetSequence()
+	require.NoError(t, err)
+	assert.Equal(t, "failed_login", steps[0].Name, "steps are sorted by step number")
+	assert.True(t, MatchesFilter(events[0].Event, steps[0].Filter))
+	assert.True(t, MatchesFilter(events[1].Event, steps[1].Filter))
+	assert.Greater(t, events[0].Time, events[1].Time, "escalation precedes the failed login")
+}
+
+func TestRuleBasedGenerator_SingleEventNearMiss(t *testing.T) {
+	rule := parseTestRule(t, `{
+		"name": "lsass_access",
+		"model": {"correlation_type": "event_count", "parameters": {
+			"time_window": "5m",
+			"query": {"filter": {"type": "and", "conditions": [
+				{"field": ".class_uid", "operator": "eq", "value": 1007},
+				{"field": ".process.name", "operator": "eq", "value": "lsass.exe"}
+			]}},
+			"threshold": {"value": 1, "operator": "gte"}
+		}}
+	}`)
+
+	events, err := NewRuleBasedGenerator(rule, 1.5, nil).GenerateNearMiss()
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, float64(1007), events[0].Event["class_uid"], "earlier conditions still hold")
+	assert.NotEqual(t, "lsass.exe", FieldValue(events[0].Event, ".process.name"))
+	assert.NoError(t, ValidateNearMiss(rule, events))
+}
+
+func TestEvaluateRule_TimeWindow(t *testing.T) {
+	rule := parseTestRule(t, generatorRules["event_count"])
+	event := func(offset time.Duration, user string) HECEvent {
+		return HECEvent{
+			Time: float64(time.Unix(1700000000, 0).Add(offset).Unix()),
+			Event: map[string]interface{}{
+				"class_uid": float64(3002),
+				"status_id": float64(2),
+				"actor":     map[string]interface{}{"user": map[string]interface{}{"name": user}},
+			},
+		}
+	}
+
+	// Six failures for bob, but spread over 10 minutes; six for eve within a minute
+	var events []HECEvent
+	for i := 0; i < 6; i++ {
+		events = append(events, event(time.Duration(i)*2*time.Minute, "bob"))
+		events = append(events, event(time.Duration(i)*10*time.Second, "eve"))
+	}
+
+	groups, err := EvaluateRule(rule, events, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"eve"}, groups)
+}
+
+func TestEvaluateRule_TemporalOrderedMaxGap(t *testing.T) {
+	rule := parseTestRule(t, generatorRules["temporal_ordered"])
+	base := time.Unix(1700000000, 0)
+	event := func(offset time.Duration, classUID float64) HECEvent {
+		return HECEvent{
+			Time: float64(base.Add(offset).Unix()),
+			Event: map[string]interface{}{
+				"class_uid": classUID,
+				"status_id": float64(2),
+				"actor":     map[string]interface{}{"user": map[string]interface{}{"name": "alice"}},
+			},
+		}
+	}
+
+	// The first failure is too far from the escalation, but the second is
+	// within max_gap
+	events := []HECEvent{event(0, 3002), event(9*time.Minute, 3002), event(12*time.Minute, 1007)}
+	groups, err := EvaluateRule(rule, events, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, groups)
+
+	groups, err = EvaluateRule(rule, events[:1:1], time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	groups, err = EvaluateRule(rule, []HECEvent{events[0], events[2]}, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, groups, "12m apart exceeds max_gap")
+}
+
+func TestDetectionRule_GetBaseline(t *testing.T) {
+	rule := parseTestRule(t, generatorRules["baseline_deviation"])
+
+	params, err := rule.GetBaseline()
+	require.NoError(t, err)
+	assert.Equal(t, &BaselineParams{
+		BaselineWindow:     24 * time.Hour,
+		ComparisonWindow:   5 * time.Minute,
+		Field:              "event_count",
+		DeviationThreshold: 3.0,
+		MinSamples:         50,
+	}, params)
+
+	filter, err := rule.GetQueryFilter()
+	require.NoError(t, err)
+	assert.Equal(t, ".class_uid", filter.Field, "query falls back to controller.detection")
+}
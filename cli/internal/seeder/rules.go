@@ -5,10 +5,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// ruleIDNamespace is the UUID v5 namespace for built-in rule IDs (see
+// alerting/dist/rules/RULE_ID_MANAGEMENT.md)
+var ruleIDNamespace = uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+
 // DetectionRule represents a complete detection rule from JSON
 type DetectionRule struct {
 	Name        string         `json:"name"`
@@ -48,7 +56,11 @@ type RuleController struct {
 
 // RuleDetectionConfig contains detection-specific settings
 type RuleDetectionConfig struct {
-	SuppressionWindow string `json:"suppression_window"`
+	SuppressionWindow  string                 `json:"suppression_window"`
+	Query              map[string]interface{} `json:"query,omitempty"`               // baseline_deviation, missing_event
+	MinMatches         int                    `json:"min_matches,omitempty"`         // temporal
+	Order              string                 `json:"order,omitempty"`               // join: right_after_left (default) or any
+	DeviationThreshold float64                `json:"deviation_threshold,omitempty"` // baseline_deviation
 }
 
 // RuleResponseConfig contains response actions
@@ -57,6 +69,41 @@ type RuleResponseConfig struct {
 	SeverityThreshold string        `json:"severity_threshold"`
 }
 
+// RuleQuery is one named query of a multi-query rule (temporal query,
+// temporal_ordered step or join side)
+type RuleQuery struct {
+	Name   string
+	Step   int
+	Filter *QueryFilter
+}
+
+// JoinCondition matches a field of the left event against the right event
+type JoinCondition struct {
+	LeftField  string
+	RightField string
+	Operator   string
+}
+
+// BaselineParams configures a baseline_deviation rule
+type BaselineParams struct {
+	BaselineWindow     time.Duration
+	ComparisonWindow   time.Duration
+	Field              string // "event_count" or a numeric field to average
+	DeviationThreshold float64
+	MinSamples         int
+}
+
+// MissingEventParams configures a missing_event rule
+type MissingEventParams struct {
+	ExpectedInterval  time.Duration
+	GracePeriod       time.Duration
+	EntityField       string
+	AlertAfterMissing int
+}
+
+// baselineSensitivity maps sensitivity levels to standard deviations
+var baselineSensitivity = map[string]float64{"low": 3.0, "medium": 2.0, "high": 1.5}
+
 // QueryFilter represents a filter in the rule's query
 type QueryFilter struct {
 	Type       string        `json:"type,omitempty"` // "and", "or", or empty for simple filter
@@ -131,17 +178,22 @@ func LoadRulesFromDirectory(path string) ([]*DetectionRule, error) {
 	return rules, nil
 }
 
+// ID returns the rule's deterministic detection schema ID
+func (r *DetectionRule) ID() string {
+	return uuid.NewSHA1(ruleIDNamespace, []byte("telhawk:builtin:"+r.Name)).String()
+}
+
 // IsSupported checks if the rule's correlation type is supported for event generation
 func (r *DetectionRule) IsSupported() (bool, string) {
 	supported := map[string]bool{
 		"event_count":        true,
 		"value_count":        true,
-		"temporal":           false, // Phase 2
-		"temporal_ordered":   false, // Phase 2
-		"join":               false, // Phase 2
-		"suppression":        false, // Skip
-		"baseline_deviation": false, // Skip - needs historical data
-		"missing_event":      false, // Skip - inverse logic
+		"temporal":           true,
+		"temporal_ordered":   true,
+		"join":               true,
+		"suppression":        false, // Skip - throttles alerts rather than detecting
+		"baseline_deviation": true,
+		"missing_event":      true,
 	}
 
 	isSupported, exists := supported[r.Model.CorrelationType]
@@ -191,13 +243,22 @@ func (r *DetectionRule) GetThreshold() (value float64, operator string, err erro
 	return value, operator, nil
 }
 
-// GetQueryFilter extracts the query filter from rule parameters
+// GetQueryFilter extracts the query filter from rule parameters, falling back
+// to the controller's detection query (baseline_deviation, missing_event)
 func (r *DetectionRule) GetQueryFilter() (*QueryFilter, error) {
 	queryMap, ok := r.Model.Parameters["query"].(map[string]interface{})
 	if !ok {
+		queryMap = r.Controller.Detection.Query
+	}
+	if queryMap == nil {
 		return nil, fmt.Errorf("query not found or not a map")
 	}
 
+	return parseQueryMap(queryMap)
+}
+
+// parseQueryMap parses a {"filter": {...}} query object
+func parseQueryMap(queryMap map[string]interface{}) (*QueryFilter, error) {
 	filterMap, ok := queryMap["filter"].(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf("filter not found in query")
@@ -206,6 +267,229 @@ func (r *DetectionRule) GetQueryFilter() (*QueryFilter, error) {
 	return parseQueryFilter(filterMap)
 }
 
+// parseRuleQuery parses a named query object ({"name", "step", "query"})
+func parseRuleQuery(raw interface{}) (RuleQuery, error) {
+	queryObj, ok := raw.(map[string]interface{})
+	if !ok {
+		return RuleQuery{}, fmt.Errorf("query entry is not a map")
+	}
+
+	rq := RuleQuery{}
+	rq.Name, _ = queryObj["name"].(string)
+	if step, ok := queryObj["step"].(float64); ok {
+		rq.Step = int(step)
+	}
+
+	queryMap, ok := queryObj["query"].(map[string]interface{})
+	if !ok {
+		return RuleQuery{}, fmt.Errorf("query %q has no query object", rq.Name)
+	}
+	filter, err := parseQueryMap(queryMap)
+	if err != nil {
+		return RuleQuery{}, fmt.Errorf("query %q: %w", rq.Name, err)
+	}
+	rq.Filter = filter
+
+	return rq, nil
+}
+
+// parseRuleQueries parses an array parameter of named queries
+func (r *DetectionRule) parseRuleQueries(param string) ([]RuleQuery, error) {
+	rawList, ok := r.Model.Parameters[param].([]interface{})
+	if !ok || len(rawList) == 0 {
+		return nil, fmt.Errorf("%s not found or empty", param)
+	}
+
+	queries := make([]RuleQuery, 0, len(rawList))
+	for _, raw := range rawList {
+		rq, err := parseRuleQuery(raw)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, rq)
+	}
+	return queries, nil
+}
+
+// GetQueries extracts the queries of a temporal rule
+func (r *DetectionRule) GetQueries() ([]RuleQuery, error) {
+	return r.parseRuleQueries("queries")
+}
+
+// GetMinMatches returns how many temporal queries must match (default: all)
+func (r *DetectionRule) GetMinMatches(queryCount int) int {
+	if v, ok := r.Model.Parameters["min_matches"].(float64); ok && v > 0 {
+		return min(int(v), queryCount)
+	}
+	if v := r.Controller.Detection.MinMatches; v > 0 {
+		return min(v, queryCount)
+	}
+	return queryCount
+}
+
+// GetSequence extracts the steps of a temporal_ordered rule, in step order
+func (r *DetectionRule) GetSequence() ([]RuleQuery, error) {
+	steps, err := r.parseRuleQueries("sequence")
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) < 2 {
+		return nil, fmt.Errorf("sequence needs at least 2 steps")
+	}
+
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Step < steps[j].Step })
+	return steps, nil
+}
+
+// GetMaxGap returns the maximum time between consecutive sequence steps
+// (default: the time window)
+func (r *DetectionRule) GetMaxGap() (time.Duration, error) {
+	if gap, ok := r.Model.Parameters["max_gap"].(string); ok {
+		return time.ParseDuration(gap)
+	}
+	return r.GetTimeWindow()
+}
+
+// GetJoin extracts the left and right queries and join conditions of a join
+// rule
+func (r *DetectionRule) GetJoin() (left, right RuleQuery, conditions []JoinCondition, err error) {
+	if left, err = parseRuleQuery(r.Model.Parameters["left_query"]); err != nil {
+		return left, right, nil, fmt.Errorf("left_query: %w", err)
+	}
+	if right, err = parseRuleQuery(r.Model.Parameters["right_query"]); err != nil {
+		return left, right, nil, fmt.Errorf("right_query: %w", err)
+	}
+
+	rawConditions, ok := r.Model.Parameters["join_conditions"].([]interface{})
+	if !ok || len(rawConditions) == 0 {
+		return left, right, nil, fmt.Errorf("join_conditions not found or empty")
+	}
+	for _, raw := range rawConditions {
+		condMap, ok := raw.(map[string]interface{})
+		if !ok {
+			return left, right, nil, fmt.Errorf("join condition is not a map")
+		}
+		cond := JoinCondition{Operator: "eq"}
+		cond.LeftField = fieldPath(condMap["left_field"])
+		cond.RightField = fieldPath(condMap["right_field"])
+		if op, ok := condMap["operator"].(string); ok {
+			cond.Operator = op
+		}
+		if cond.LeftField == "" || cond.RightField == "" {
+			return left, right, nil, fmt.Errorf("join condition needs left_field and right_field")
+		}
+		if cond.Operator != "eq" {
+			return left, right, nil, fmt.Errorf("join operator %q not supported", cond.Operator)
+		}
+		conditions = append(conditions, cond)
+	}
+
+	return left, right, conditions, nil
+}
+
+// GetBaseline extracts the parameters of a baseline_deviation rule
+func (r *DetectionRule) GetBaseline() (*BaselineParams, error) {
+	params := &BaselineParams{Field: "event_count", MinSamples: 100}
+
+	var err error
+	baselineWindow, _ := r.Model.Parameters["baseline_window"].(string)
+	if params.BaselineWindow, err = parseDayDuration(baselineWindow); err != nil {
+		return nil, fmt.Errorf("invalid baseline_window: %w", err)
+	}
+	comparisonWindow, _ := r.Model.Parameters["comparison_window"].(string)
+	if params.ComparisonWindow, err = parseDayDuration(comparisonWindow); err != nil {
+		return nil, fmt.Errorf("invalid comparison_window: %w", err)
+	}
+	if params.ComparisonWindow <= 0 || params.ComparisonWindow >= params.BaselineWindow {
+		return nil, fmt.Errorf("comparison_window must be positive and shorter than baseline_window")
+	}
+
+	if field, ok := r.Model.Parameters["field"].(string); ok && field != "" {
+		params.Field = field
+	}
+	if samples, ok := r.Model.Parameters["min_baseline_samples"].(float64); ok && samples > 0 {
+		params.MinSamples = int(samples)
+	}
+
+	// Threshold: parameter, controller, then sensitivity (default medium)
+	switch {
+	case r.Model.Parameters["deviation_threshold"] != nil:
+		threshold, ok := r.Model.Parameters["deviation_threshold"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("deviation_threshold is not a number")
+		}
+		params.DeviationThreshold = threshold
+	case r.Controller.Detection.DeviationThreshold > 0:
+		params.DeviationThreshold = r.Controller.Detection.DeviationThreshold
+	default:
+		sensitivity, _ := r.Model.Parameters["sensitivity"].(string)
+		if sensitivity == "" {
+			sensitivity = "medium"
+		}
+		threshold, ok := baselineSensitivity[sensitivity]
+		if !ok {
+			return nil, fmt.Errorf("unknown sensitivity %q", sensitivity)
+		}
+		params.DeviationThreshold = threshold
+	}
+	if params.DeviationThreshold <= 0 {
+		return nil, fmt.Errorf("deviation_threshold must be positive")
+	}
+
+	return params, nil
+}
+
+// GetMissingEvent extracts the parameters of a missing_event rule
+func (r *DetectionRule) GetMissingEvent() (*MissingEventParams, error) {
+	params := &MissingEventParams{AlertAfterMissing: 1}
+
+	interval, _ := r.Model.Parameters["expected_interval"].(string)
+	var err error
+	if params.ExpectedInterval, err = parseDayDuration(interval); err != nil {
+		return nil, fmt.Errorf("invalid expected_interval: %w", err)
+	}
+	if params.ExpectedInterval <= 0 {
+		return nil, fmt.Errorf("expected_interval must be positive")
+	}
+	if grace, ok := r.Model.Parameters["grace_period"].(string); ok {
+		if params.GracePeriod, err = time.ParseDuration(grace); err != nil {
+			return nil, fmt.Errorf("invalid grace_period: %w", err)
+		}
+	}
+
+	params.EntityField = fieldPath(r.Model.Parameters["entity_field"])
+	if params.EntityField == "" {
+		return nil, fmt.Errorf("entity_field not found or not a string")
+	}
+	if missing, ok := r.Model.Parameters["alert_after_missing"].(float64); ok && missing >= 1 {
+		params.AlertAfterMissing = int(missing)
+	}
+
+	return params, nil
+}
+
+// fieldPath normalizes a field parameter to the leading-dot form used by
+// filters (".actor.user.name"); non-strings yield ""
+func fieldPath(raw interface{}) string {
+	field, _ := raw.(string)
+	if field == "" {
+		return ""
+	}
+	return "." + strings.TrimPrefix(field, ".")
+}
+
+// parseDayDuration parses a duration that may use a "d" (days) suffix
+func parseDayDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
 // parseQueryFilter recursively parses a filter map into a QueryFilter struct
 func parseQueryFilter(filterMap map[string]interface{}) (*QueryFilter, error) {
 	filter := &QueryFilter{}
@@ -273,6 +557,33 @@ func (r *DetectionRule) GetGroupByFields() ([]string, error) {
 	return fields, nil
 }
 
+// correlationFields returns the fields that identify a correlated entity:
+// group_by, or entity_field for missing_event rules
+func (r *DetectionRule) correlationFields() []string {
+	if r.Model.CorrelationType == "missing_event" {
+		if field, ok := r.Model.Parameters["entity_field"].(string); ok {
+			return []string{field}
+		}
+		return nil
+	}
+	fields, _ := r.GetGroupByFields()
+	return fields
+}
+
+// GroupValues returns an event's values for the rule's group_by fields (the
+// entity field for missing_event rules), in order
+func (r *DetectionRule) GroupValues(event map[string]interface{}) []string {
+	fields := r.correlationFields()
+
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if value := getFieldValue(event, field); value != nil {
+			values = append(values, fmt.Sprint(value))
+		}
+	}
+	return values
+}
+
 // GetValueCountField extracts the field to count distinct values for (value_count rules)
 func (r *DetectionRule) GetValueCountField() (string, error) {
 	if r.Model.CorrelationType != "value_count" {
@@ -333,6 +644,11 @@ func MatchesFilter(event map[string]interface{}, filter *QueryFilter) bool {
 	return compareValues(fieldValue, filter.Operator, filter.Value)
 }
 
+// FieldValue extracts a nested field value using dot notation
+func FieldValue(event map[string]interface{}, fieldPath string) interface{} {
+	return getFieldValue(event, fieldPath)
+}
+
 // getFieldValue extracts a nested field value using dot notation (e.g., ".actor.user.name")
 func getFieldValue(event map[string]interface{}, fieldPath string) interface{} {
 	// Remove leading dot if present
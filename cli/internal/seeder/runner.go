@@ -18,6 +18,7 @@ import (
 type Runner struct {
 	Config     *Config
 	HTTPClient *http.Client
+	NearMiss   bool // Rule-based runs send near-miss sets, which must not trigger, instead of positive sets
 }
 
 // NewRunner creates a new seeder runner
@@ -147,17 +148,7 @@ func (r *Runner) runAttacks(selectedAttacks []string) (successCount, failCount i
 				end = len(attackEvents)
 			}
 
-			// Convert attacks.HECEvent to seeder.HECEvent
-			batch := make([]HECEvent, end-i)
-			for j, ae := range attackEvents[i:end] {
-				batch[j] = HECEvent{
-					Time:       ae.Time,
-					Event:      ae.Event,
-					SourceType: ae.SourceType,
-					Index:      ae.Index,
-				}
-			}
-
+			batch := FromAttackEvents(attackEvents[i:end])
 			if err := r.sendBatch(batch); err != nil {
 				log.Printf("Failed to send attack batch: %v", err)
 				failCount += len(batch)
@@ -172,6 +163,84 @@ func (r *Runner) runAttacks(selectedAttacks []string) (successCount, failCount i
 	return successCount, failCount
 }
 
+// RunScenario generates a multi-stage attack scenario ending now and sends
+// its events in time order
+func (r *Runner) RunScenario(scenario *attacks.Scenario) error {
+	gofakeit.Seed(time.Now().UnixNano())
+
+	run, err := scenario.Generate(time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to generate scenario %s: %w", scenario.Name, err)
+	}
+
+	log.Printf("Running scenario: %s", scenario.Name)
+	if scenario.Description != "" {
+		log.Printf("  %s", scenario.Description)
+	}
+	log.Printf("  Run ID: %s", run.ID)
+	log.Printf("  Window: %s - %s", run.Start.Format(time.RFC3339), run.End.Format(time.RFC3339))
+	for _, stage := range run.Stages {
+		log.Printf("  %-24s %-10s %s +%v  %d events", stage.Name, stage.Pattern,
+			stage.Start.Format("15:04:05"), stage.End.Sub(stage.Start), len(stage.Events))
+	}
+
+	events := run.Events()
+	successCount := 0
+	failCount := 0
+	for i := 0; i < len(events); i += r.Config.Defaults.BatchSize {
+		end := i + r.Config.Defaults.BatchSize
+		if end > len(events) {
+			end = len(events)
+		}
+
+		batch := FromAttackEvents(events[i:end])
+		if err := r.sendBatch(batch); err != nil {
+			log.Printf("Failed to send scenario batch: %v", err)
+			failCount += len(batch)
+		} else {
+			successCount += len(batch)
+		}
+	}
+
+	log.Printf("\nScenario complete:")
+	log.Printf("  Success: %d events", successCount)
+	log.Printf("  Failed: %d events", failCount)
+	log.Printf("  Search: metadata.correlation_uid:%s", run.ID)
+
+	if failCount > 0 {
+		return fmt.Errorf("%d scenario events failed to send", failCount)
+	}
+	return nil
+}
+
+// SendEvents sends events to HEC in batches of the configured size
+func (r *Runner) SendEvents(events []HECEvent) error {
+	for i := 0; i < len(events); i += r.Config.Defaults.BatchSize {
+		end := i + r.Config.Defaults.BatchSize
+		if end > len(events) {
+			end = len(events)
+		}
+		if err := r.sendBatch(events[i:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FromAttackEvents converts attack pattern events to seeder events
+func FromAttackEvents(events []attacks.HECEvent) []HECEvent {
+	converted := make([]HECEvent, len(events))
+	for i, ae := range events {
+		converted[i] = HECEvent{
+			Time:       ae.Time,
+			Event:      ae.Event,
+			SourceType: ae.SourceType,
+			Index:      ae.Index,
+		}
+	}
+	return converted
+}
+
 // sendBatch sends a batch of events to HEC
 func (r *Runner) sendBatch(events []HECEvent) error {
 	var buf bytes.Buffer
@@ -247,15 +316,19 @@ func (r *Runner) RunFromRulesDirectory(rulesDir string) error {
 
 		// Generate events
 		generator := NewRuleBasedGenerator(rule, 1.5, nil)
-		events, err := generator.GenerateEvents()
+		generate, validate := generator.GenerateEvents, ValidateEventsMatchRule
+		if r.NearMiss {
+			generate, validate = generator.GenerateNearMiss, ValidateNearMiss
+		}
+		events, err := generate()
 		if err != nil {
 			log.Printf("\n⚠ WARN: Failed to generate events for rule '%s': %v", rule.Name, err)
 			warningCount++
 			continue
 		}
 
-		// Validate events match rule
-		if err := ValidateEventsMatchRule(rule, events); err != nil {
+		// Validate events match rule (or, for near misses, do not trigger it)
+		if err := validate(rule, events); err != nil {
 			log.Printf("\n⚠ WARN: Events for rule '%s' failed validation: %v", rule.Name, err)
 			warningCount++
 			continue
@@ -3,6 +3,7 @@ package seeder
 import (
 	"fmt"
 	"log"
+	"time"
 )
 
 // ValidateEventsMatchRule validates that generated events match the rule criteria
@@ -13,6 +14,21 @@ func ValidateEventsMatchRule(rule *DetectionRule, events []HECEvent) error {
 		return fmt.Errorf("no events generated")
 	}
 
+	// Multi-query and stateful types are checked by evaluating the rule
+	switch rule.Model.CorrelationType {
+	case "event_count", "value_count":
+	default:
+		triggered, err := EvaluateRule(rule, events, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to evaluate rule: %w", err)
+		}
+		if len(triggered) == 0 {
+			return fmt.Errorf("events do not trigger the rule")
+		}
+		log.Printf("  ✓ Validation: %d events trigger the rule", len(events))
+		return nil
+	}
+
 	// Get filter to validate against
 	filter, err := rule.GetQueryFilter()
 	if err != nil {
@@ -46,6 +62,24 @@ func ValidateEventsMatchRule(rule *DetectionRule, events []HECEvent) error {
 	}
 }
 
+// ValidateNearMiss checks that a near-miss event set does not trigger the rule
+func ValidateNearMiss(rule *DetectionRule, events []HECEvent) error {
+	if len(events) == 0 {
+		return fmt.Errorf("no events generated")
+	}
+
+	triggered, err := EvaluateRule(rule, events, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to evaluate rule: %w", err)
+	}
+	if len(triggered) > 0 {
+		return fmt.Errorf("near-miss events trigger the rule for %v", triggered)
+	}
+
+	log.Printf("  ✓ Validation: %d near-miss events do not trigger the rule", len(events))
+	return nil
+}
+
 // validateEventCount validates event_count rule requirements
 func validateEventCount(rule *DetectionRule, events []HECEvent) error {
 	threshold, operator, err := rule.GetThreshold()
@@ -206,40 +240,104 @@ func ValidateRuleCanBeGenerated(rule *DetectionRule) error {
 		return fmt.Errorf("rule not supported: %s", reason)
 	}
 
-	// Validate required parameters exist
-	if _, err := rule.GetTimeWindow(); err != nil {
-		return fmt.Errorf("invalid time_window: %w", err)
-	}
+	ocsfVal := newOCSFValidator()
 
-	if _, _, err := rule.GetThreshold(); err != nil {
-		return fmt.Errorf("invalid threshold: %w", err)
-	}
+	// Type-specific validation
+	switch rule.Model.CorrelationType {
+	case "event_count", "value_count":
+		if _, err := rule.GetTimeWindow(); err != nil {
+			return fmt.Errorf("invalid time_window: %w", err)
+		}
 
-	filter, err := rule.GetQueryFilter()
-	if err != nil {
-		return fmt.Errorf("invalid query filter: %w", err)
-	}
+		if _, _, err := rule.GetThreshold(); err != nil {
+			return fmt.Errorf("invalid threshold: %w", err)
+		}
 
-	// Validate OCSF field paths in the filter
-	ocsfVal := newOCSFValidator()
-	if fieldErrors := ocsfVal.ValidateFilterFields(filter); len(fieldErrors) > 0 {
-		errMsg := "OCSF schema validation failed:\n"
-		for _, e := range fieldErrors {
-			errMsg += fmt.Sprintf("  - %s\n", e.Error())
+		if err := validateQueryFilter(ocsfVal, rule); err != nil {
+			return err
 		}
-		return fmt.Errorf("%s", errMsg)
-	}
 
-	// Type-specific validation
-	switch rule.Model.CorrelationType {
-	case "value_count":
-		countField, err := rule.GetValueCountField()
+		if rule.Model.CorrelationType == "value_count" {
+			countField, err := rule.GetValueCountField()
+			if err != nil {
+				return fmt.Errorf("invalid value count field: %w", err)
+			}
+			// Validate the value count field path
+			if err := ocsfVal.ValidateFieldPath(countField); err != nil {
+				return fmt.Errorf("invalid value count field path '%s': %w", countField, err)
+			}
+		}
+
+	case "temporal", "temporal_ordered":
+		if _, err := rule.GetTimeWindow(); err != nil {
+			return fmt.Errorf("invalid time_window: %w", err)
+		}
+
+		var queries []RuleQuery
+		var err error
+		if rule.Model.CorrelationType == "temporal" {
+			queries, err = rule.GetQueries()
+		} else {
+			queries, err = rule.GetSequence()
+			if err == nil {
+				_, err = rule.GetMaxGap()
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("invalid queries: %w", err)
+		}
+		for _, q := range queries {
+			if err := validateFilterFields(ocsfVal, q.Filter); err != nil {
+				return fmt.Errorf("query '%s': %w", q.Name, err)
+			}
+		}
+
+	case "join":
+		if _, err := rule.GetTimeWindow(); err != nil {
+			return fmt.Errorf("invalid time_window: %w", err)
+		}
+
+		left, right, conditions, err := rule.GetJoin()
 		if err != nil {
-			return fmt.Errorf("invalid value count field: %w", err)
+			return fmt.Errorf("invalid join: %w", err)
+		}
+		for _, q := range []RuleQuery{left, right} {
+			if err := validateFilterFields(ocsfVal, q.Filter); err != nil {
+				return fmt.Errorf("query '%s': %w", q.Name, err)
+			}
+		}
+		for _, cond := range conditions {
+			for _, field := range []string{cond.LeftField, cond.RightField} {
+				if err := ocsfVal.ValidateFieldPath(field); err != nil {
+					return fmt.Errorf("invalid join field path '%s': %w", field, err)
+				}
+			}
 		}
-		// Validate the value count field path
-		if err := ocsfVal.ValidateFieldPath(countField); err != nil {
-			return fmt.Errorf("invalid value count field path '%s': %w", countField, err)
+
+	case "baseline_deviation":
+		params, err := rule.GetBaseline()
+		if err != nil {
+			return fmt.Errorf("invalid baseline parameters: %w", err)
+		}
+		if err := validateQueryFilter(ocsfVal, rule); err != nil {
+			return err
+		}
+		if params.Field != "event_count" {
+			if err := ocsfVal.ValidateFieldPath(params.Field); err != nil {
+				return fmt.Errorf("invalid baseline field path '%s': %w", params.Field, err)
+			}
+		}
+
+	case "missing_event":
+		params, err := rule.GetMissingEvent()
+		if err != nil {
+			return fmt.Errorf("invalid missing_event parameters: %w", err)
+		}
+		if err := validateQueryFilter(ocsfVal, rule); err != nil {
+			return err
+		}
+		if err := ocsfVal.ValidateFieldPath(params.EntityField); err != nil {
+			return fmt.Errorf("invalid entity field path '%s': %w", params.EntityField, err)
 		}
 	}
 
@@ -255,3 +353,25 @@ func ValidateRuleCanBeGenerated(rule *DetectionRule) error {
 
 	return nil
 }
+
+// validateQueryFilter checks the rule's single query filter against the OCSF
+// schema
+func validateQueryFilter(ocsfVal *ocsfValidator, rule *DetectionRule) error {
+	filter, err := rule.GetQueryFilter()
+	if err != nil {
+		return fmt.Errorf("invalid query filter: %w", err)
+	}
+	return validateFilterFields(ocsfVal, filter)
+}
+
+// validateFilterFields validates OCSF field paths in a filter
+func validateFilterFields(ocsfVal *ocsfValidator, filter *QueryFilter) error {
+	if fieldErrors := ocsfVal.ValidateFilterFields(filter); len(fieldErrors) > 0 {
+		errMsg := "OCSF schema validation failed:\n"
+		for _, e := range fieldErrors {
+			errMsg += fmt.Sprintf("  - %s\n", e.Error())
+		}
+		return fmt.Errorf("%s", errMsg)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package verify
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Status is the outcome of verifying one detection
+type Status string
+
+const (
+	StatusPassed  Status = "passed"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+	StatusError   Status = "error"
+)
+
+// Result is the outcome for one rule, or one stage of a scenario
+type Result struct {
+	Name       string        `json:"name"`
+	RuleID     string        `json:"rule_id,omitempty"`
+	Title      string        `json:"title,omitempty"`
+	Techniques []string      `json:"techniques,omitempty"`
+	Status     Status        `json:"status"`
+	Message    string        `json:"message,omitempty"`
+	Events     int           `json:"events"`
+	AlertID    string        `json:"alert_id,omitempty"`
+	Latency    time.Duration `json:"latency_ns,omitempty"`
+}
+
+// TechniqueCoverage rolls results up per ATT&CK technique. A technique
+// passes when at least one of its detections fired.
+type TechniqueCoverage struct {
+	ID         string   `json:"id"`
+	Status     Status   `json:"status"`
+	Detections []string `json:"detections"`
+}
+
+// Summary counts results by status
+type Summary struct {
+	Total   int `json:"total"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+	Errors  int `json:"errors"`
+}
+
+// Report is the coverage report of one verification run
+type Report struct {
+	RunID      string              `json:"run_id"`
+	Target     string              `json:"target"`
+	Scenario   string              `json:"scenario,omitempty"`
+	StartedAt  time.Time           `json:"started_at"`
+	Duration   time.Duration       `json:"duration_ns"`
+	Summary    Summary             `json:"summary"`
+	Results    []*Result           `json:"results"`
+	Techniques []TechniqueCoverage `json:"techniques"`
+}
+
+// Passed reports whether no detection failed or errored
+func (r *Report) Passed() bool {
+	return r.Summary.Failed == 0 && r.Summary.Errors == 0
+}
+
+// finish computes the summary and technique coverage
+func (r *Report) finish() *Report {
+	r.Duration = time.Since(r.StartedAt)
+	r.Summary = Summary{Total: len(r.Results)}
+
+	coverage := make(map[string]*TechniqueCoverage)
+	for _, result := range r.Results {
+		switch result.Status {
+		case StatusPassed:
+			r.Summary.Passed++
+		case StatusFailed:
+			r.Summary.Failed++
+		case StatusSkipped:
+			r.Summary.Skipped++
+		case StatusError:
+			r.Summary.Errors++
+		}
+
+		for _, id := range result.Techniques {
+			tc, ok := coverage[id]
+			if !ok {
+				tc = &TechniqueCoverage{ID: id, Status: StatusSkipped}
+				coverage[id] = tc
+			}
+			tc.Detections = append(tc.Detections, result.Name)
+			tc.Status = mergeStatus(tc.Status, result.Status)
+		}
+	}
+
+	r.Techniques = make([]TechniqueCoverage, 0, len(coverage))
+	for _, tc := range coverage {
+		r.Techniques = append(r.Techniques, *tc)
+	}
+	sort.Slice(r.Techniques, func(i, j int) bool {
+		return r.Techniques[i].ID < r.Techniques[j].ID
+	})
+	return r
+}
+
+// mergeStatus combines detection outcomes for a technique: any pass wins,
+// then failures, then errors
+func mergeStatus(current, next Status) Status {
+	rank := map[Status]int{StatusSkipped: 0, StatusError: 1, StatusFailed: 2, StatusPassed: 3}
+	if rank[next] > rank[current] {
+		return next
+	}
+	return current
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     float64          `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the report as JUnit XML with one suite for detections
+// and one for ATT&CK technique coverage
+func (r *Report) WriteJUnit(w io.Writer) error {
+	detectionSuite := "detections"
+	if r.Scenario != "" {
+		detectionSuite = "scenario." + r.Scenario
+	}
+
+	detections := junitTestSuite{Name: detectionSuite, Timestamp: r.StartedAt.UTC().Format(time.RFC3339)}
+	for _, result := range r.Results {
+		tc := junitTestCase{Name: result.Name, ClassName: detectionSuite, Time: result.Latency.Seconds()}
+		setJUnitOutcome(&tc, &detections, result.Status, result.Message)
+		detections.Cases = append(detections.Cases, tc)
+	}
+
+	techniques := junitTestSuite{Name: "attack.techniques"}
+	for _, coverage := range r.Techniques {
+		tc := junitTestCase{Name: coverage.ID, ClassName: techniques.Name}
+		message := fmt.Sprintf("no detection fired (%d checked)", len(coverage.Detections))
+		if coverage.Status == StatusSkipped {
+			message = "no detection could be seeded"
+		}
+		setJUnitOutcome(&tc, &techniques, coverage.Status, message)
+		techniques.Cases = append(techniques.Cases, tc)
+	}
+
+	suites := junitTestSuites{
+		Name:   "thawk detections verify " + r.RunID,
+		Time:   r.Duration.Seconds(),
+		Suites: []junitTestSuite{detections, techniques},
+	}
+	for _, suite := range suites.Suites {
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func setJUnitOutcome(tc *junitTestCase, suite *junitTestSuite, status Status, message string) {
+	suite.Tests++
+	switch status {
+	case StatusFailed:
+		suite.Failures++
+		tc.Failure = &junitMessage{Message: message}
+	case StatusError:
+		suite.Errors++
+		tc.Error = &junitMessage{Message: message}
+	case StatusSkipped:
+		suite.Skipped++
+		tc.Skipped = &junitMessage{Message: message}
+	}
+}
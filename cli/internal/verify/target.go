@@ -0,0 +1,189 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/client"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/seeder"
+)
+
+// StackTarget sends events to HEC and reads alerts from the respond service,
+// e.g. a docker-compose stack
+type StackTarget struct {
+	runner *seeder.Runner
+	alerts *client.AlertingClient
+	token  string
+}
+
+// NewStackTarget creates a target for a running stack. token is the access
+// token used to list alerts.
+func NewStackTarget(runner *seeder.Runner, alerts *client.AlertingClient, token string) *StackTarget {
+	return &StackTarget{runner: runner, alerts: alerts, token: token}
+}
+
+func (t *StackTarget) Name() string {
+	return "stack"
+}
+
+func (t *StackTarget) Send(ctx context.Context, events []seeder.HECEvent) error {
+	return t.runner.SendEvents(events)
+}
+
+// stackAlertPages bounds how many pages of alerts are read per poll
+const stackAlertPages = 10
+
+func (t *StackTarget) Alerts(ctx context.Context, since time.Time) ([]Alert, error) {
+	const limit = 100
+	filters := map[string]string{"from": since.UTC().Format(time.RFC3339)}
+
+	var alerts []Alert
+	for page := 1; page <= stackAlertPages; page++ {
+		resp, err := t.alerts.ListAlerts(t.token, page, limit, filters)
+		if err != nil {
+			return alerts, err
+		}
+		for _, a := range resp.Alerts {
+			alerts = append(alerts, Alert{
+				ID:          a.ID,
+				RuleID:      a.DetectionSchemaID,
+				Title:       a.DetectionName(),
+				GroupKey:    a.RawData.GroupKey,
+				TriggeredAt: a.TriggeredAt(),
+				Markers:     alertMarkers(&a),
+			})
+		}
+		if len(resp.Alerts) < limit {
+			break
+		}
+	}
+	return alerts, nil
+}
+
+// runMetadata is the part of an event or alert that carries run markers
+type runMetadata struct {
+	Metadata struct {
+		CorrelationUID string   `json:"correlation_uid"`
+		Tags           []string `json:"tags"`
+	} `json:"metadata"`
+}
+
+// alertMarkers collects the run markers of an alert and of the matched events
+// it embeds. Matched events referenced only by ID contribute nothing.
+func alertMarkers(a *client.Alert) []string {
+	markers := eventMarkers(a.Metadata.CorrelationUID, a.Metadata.Tags)
+	for _, raw := range a.MatchedEvents {
+		var event runMetadata
+		if json.Unmarshal(raw, &event) != nil {
+			continue
+		}
+		markers = append(markers, eventMarkers(event.Metadata.CorrelationUID, event.Metadata.Tags)...)
+	}
+	return markers
+}
+
+func eventMarkers(correlationUID string, tags []string) []string {
+	markers := append([]string(nil), tags...)
+	if correlationUID != "" {
+		markers = append(markers, correlationUID)
+	}
+	return markers
+}
+
+// LocalTarget is an in-process stand-in for the stack. It keeps sent events
+// in memory and evaluates rules over them with seeder.EvaluateRule, which
+// approximates the correlation engine for every generatable correlation type.
+// Rules are evaluated over the events sharing each run marker, and the
+// resulting alerts carry that marker.
+type LocalTarget struct {
+	rules []*seeder.DetectionRule
+
+	mu     sync.Mutex
+	events []seeder.HECEvent
+}
+
+// NewLocalTarget creates an in-process target evaluating rules
+func NewLocalTarget(rules []*seeder.DetectionRule) *LocalTarget {
+	return &LocalTarget{rules: rules}
+}
+
+func (t *LocalTarget) Name() string {
+	return "local"
+}
+
+// Send stores events as the stack would see them after a JSON round trip,
+// so numbers compare the same way they do against ingested documents
+func (t *LocalTarget) Send(ctx context.Context, events []seeder.HECEvent) error {
+	ingested := make([]seeder.HECEvent, len(events))
+	for i, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+		if err := json.Unmarshal(data, &ingested[i]); err != nil {
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, ingested...)
+	return nil
+}
+
+func (t *LocalTarget) Alerts(ctx context.Context, since time.Time) ([]Alert, error) {
+	t.mu.Lock()
+	events := append([]seeder.HECEvent(nil), t.events...)
+	t.mu.Unlock()
+
+	var markers []string
+	byMarker := make(map[string][]seeder.HECEvent)
+	for _, event := range events {
+		for _, marker := range localEventMarkers(event) {
+			if _, seen := byMarker[marker]; !seen {
+				markers = append(markers, marker)
+			}
+			byMarker[marker] = append(byMarker[marker], event)
+		}
+	}
+
+	now := time.Now()
+	var alerts []Alert
+	for _, rule := range t.rules {
+		for _, marker := range markers {
+			groups, err := seeder.EvaluateRule(rule, byMarker[marker], now)
+			if err != nil {
+				break
+			}
+			for _, groupKey := range groups {
+				alerts = append(alerts, Alert{
+					ID:          fmt.Sprintf("local-%s-%d", rule.Name, len(alerts)+1),
+					RuleID:      rule.ID(),
+					Title:       rule.View.Title,
+					GroupKey:    groupKey,
+					TriggeredAt: now,
+					Markers:     []string{marker},
+				})
+			}
+		}
+	}
+	return alerts, nil
+}
+
+// localEventMarkers reads the run markers of an event stored by Send
+func localEventMarkers(event seeder.HECEvent) []string {
+	metadata, _ := event.Event["metadata"].(map[string]interface{})
+	correlationUID, _ := metadata["correlation_uid"].(string)
+
+	var tags []string
+	list, _ := metadata["tags"].([]interface{})
+	for _, tag := range list {
+		if s, ok := tag.(string); ok {
+			tags = append(tags, s)
+		}
+	}
+	return eventMarkers(correlationUID, tags)
+}
@@ -0,0 +1,312 @@
+// Package verify checks end to end that detection rules fire: it seeds
+// events for each rule or attack scenario, waits for correlation and looks
+// for the resulting alerts.
+package verify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/seeder"
+	"github.com/telhawk-systems/telhawk-stack/cli/pkg/attacks"
+)
+
+// Target is where seeded events are sent and alerts are read back from
+type Target interface {
+	// Name identifies the target in reports
+	Name() string
+	// Send ingests events
+	Send(ctx context.Context, events []seeder.HECEvent) error
+	// Alerts returns alerts triggered at or after since
+	Alerts(ctx context.Context, since time.Time) ([]Alert, error)
+}
+
+// Alert is the part of a detection alert needed to attribute it to a rule
+// and to a verification run
+type Alert struct {
+	ID          string
+	RuleID      string
+	Title       string
+	GroupKey    string
+	TriggeredAt time.Time
+	// Markers are the correlation UIDs and tags carried by the alert or the
+	// events that triggered it
+	Markers []string
+}
+
+// Harness seeds events and checks that the expected detections fire
+type Harness struct {
+	Target       Target
+	Rules        []*seeder.DetectionRule
+	Wait         time.Duration // How long to wait for alerts after seeding
+	PollInterval time.Duration // How often to query for alerts while waiting
+	Multiplier   float64       // Threshold multiplier for rule-based generation
+}
+
+// expectation is a detection the harness is waiting for
+type expectation struct {
+	result      *Result
+	runID       string
+	ruleIDs     map[string]bool
+	groupValues []string
+	started     time.Time
+}
+
+// VerifyRules seeds events for every rule and reports which rules fired
+func (h *Harness) VerifyRules(ctx context.Context) (*Report, error) {
+	report := h.newReport("")
+	var pending []*expectation
+
+	for _, rule := range h.Rules {
+		result := &Result{
+			Name:       rule.Name,
+			RuleID:     rule.ID(),
+			Title:      rule.View.Title,
+			Techniques: TechniqueIDs(rule.View.MITREAttack.Techniques),
+		}
+		report.Results = append(report.Results, result)
+
+		if err := seeder.ValidateRuleCanBeGenerated(rule); err != nil {
+			result.Status = StatusSkipped
+			result.Message = err.Error()
+			continue
+		}
+
+		events, err := seeder.NewRuleBasedGenerator(rule, h.Multiplier, nil).GenerateEvents()
+		if err != nil {
+			result.Status = StatusSkipped
+			result.Message = fmt.Sprintf("failed to generate events: %v", err)
+			continue
+		}
+		markEvents(events, report.RunID, rule.Name)
+
+		started := time.Now()
+		if err := h.Target.Send(ctx, events); err != nil {
+			result.Status = StatusError
+			result.Message = fmt.Sprintf("failed to send events: %v", err)
+			continue
+		}
+		result.Events = len(events)
+
+		pending = append(pending, &expectation{
+			result:      result,
+			runID:       report.RunID,
+			ruleIDs:     map[string]bool{result.RuleID: true},
+			groupValues: rule.GroupValues(events[0].Event),
+			started:     started,
+		})
+	}
+
+	if err := h.await(ctx, report.StartedAt, pending); err != nil {
+		return nil, err
+	}
+	return report.finish(), nil
+}
+
+// VerifyScenario runs an attack scenario and reports, per stage, whether a
+// rule mapped to the stage's ATT&CK technique fired
+func (h *Harness) VerifyScenario(ctx context.Context, scenario *attacks.Scenario) (*Report, error) {
+	report := h.newReport(scenario.Name)
+
+	run, err := scenario.Generate(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate scenario %s: %w", scenario.Name, err)
+	}
+
+	events := seeder.FromAttackEvents(run.Events())
+	markEvents(events, report.RunID, "")
+	started := time.Now()
+	sendErr := h.Target.Send(ctx, events)
+
+	var pending []*expectation
+	for _, stage := range run.Stages {
+		result := &Result{
+			Name:       stage.Name,
+			Techniques: []string{stage.Pattern},
+			Events:     len(stage.Events),
+		}
+		report.Results = append(report.Results, result)
+
+		rules := h.rulesForTechnique(stage.Pattern)
+		switch {
+		case sendErr != nil:
+			result.Status = StatusError
+			result.Message = fmt.Sprintf("failed to send events: %v", sendErr)
+		case len(rules) == 0:
+			result.Status = StatusFailed
+			result.Message = fmt.Sprintf("no detection rule maps to %s", stage.Pattern)
+		default:
+			ruleIDs := make(map[string]bool, len(rules))
+			for _, rule := range rules {
+				ruleIDs[rule.ID()] = true
+			}
+			pending = append(pending, &expectation{result: result, runID: report.RunID, ruleIDs: ruleIDs, started: started})
+		}
+	}
+
+	if err := h.await(ctx, report.StartedAt, pending); err != nil {
+		return nil, err
+	}
+	return report.finish(), nil
+}
+
+// await polls the target for alerts until every expectation is met or the
+// wait expires
+func (h *Harness) await(ctx context.Context, since time.Time, pending []*expectation) error {
+	if len(pending) == 0 {
+		return nil
+	}
+
+	log.Printf("Waiting up to %v for %d detections", h.Wait, len(pending))
+	deadline := time.Now().Add(h.Wait)
+	for {
+		alerts, err := h.Target.Alerts(ctx, since)
+		if err != nil {
+			log.Printf("WARN: failed to query alerts: %v", err)
+		}
+
+		remaining := pending[:0]
+		for _, exp := range pending {
+			if alert, ok := exp.match(alerts); ok {
+				exp.result.Status = StatusPassed
+				exp.result.AlertID = alert.ID
+				exp.result.Latency = alert.TriggeredAt.Sub(exp.started)
+				if exp.result.Latency < 0 {
+					exp.result.Latency = 0
+				}
+				continue
+			}
+			remaining = append(remaining, exp)
+		}
+		pending = remaining
+
+		if len(pending) == 0 || !time.Now().Before(deadline) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(h.PollInterval):
+		}
+	}
+
+	message := "no alert"
+	if h.Wait > 0 {
+		message = fmt.Sprintf("no alert within %v", h.Wait)
+	}
+	for _, exp := range pending {
+		exp.result.Status = StatusFailed
+		exp.result.Message = message
+	}
+	return nil
+}
+
+// match finds an alert from one of the expected rules raised from events of
+// this run, so alerts for the same rule from other traffic or earlier runs
+// are ignored. When the rule groups events the seeded group values must also
+// appear in the alert's group key.
+func (e *expectation) match(alerts []Alert) (Alert, bool) {
+	for _, alert := range alerts {
+		if !e.ruleIDs[alert.RuleID] || !hasMarker(alert, e.runID) {
+			continue
+		}
+		if alert.GroupKey != "" && !containsAll(alert.GroupKey, e.groupValues) {
+			continue
+		}
+		return alert, true
+	}
+	return Alert{}, false
+}
+
+// rulesForTechnique returns the rules mapped to a technique, its parent or
+// one of its sub-techniques
+func (h *Harness) rulesForTechnique(technique string) []*seeder.DetectionRule {
+	parent, _, _ := strings.Cut(technique, ".")
+
+	var rules []*seeder.DetectionRule
+	for _, rule := range h.Rules {
+		for _, id := range TechniqueIDs(rule.View.MITREAttack.Techniques) {
+			if id == technique || id == parent || strings.HasPrefix(id, technique+".") {
+				rules = append(rules, rule)
+				break
+			}
+		}
+	}
+	return rules
+}
+
+func (h *Harness) newReport(scenario string) *Report {
+	return &Report{
+		RunID:     "verify-" + uuid.NewString()[:8],
+		Target:    h.Target.Name(),
+		Scenario:  scenario,
+		StartedAt: time.Now(),
+	}
+}
+
+// markEvents stamps the run marker on seeded events so they can be found
+// in search and told apart from other traffic
+func markEvents(events []seeder.HECEvent, runID, ruleName string) {
+	for _, event := range events {
+		metadata, ok := event.Event["metadata"].(map[string]interface{})
+		if !ok {
+			metadata = make(map[string]interface{})
+			event.Event["metadata"] = metadata
+		}
+		if _, exists := metadata["correlation_uid"]; !exists {
+			metadata["correlation_uid"] = runID
+		}
+
+		tags := []string{runID}
+		if ruleName != "" {
+			tags = append(tags, "rule:"+ruleName)
+		}
+		switch existing := metadata["tags"].(type) {
+		case []string:
+			metadata["tags"] = append(existing, tags...)
+		case []interface{}:
+			for _, tag := range tags {
+				existing = append(existing, tag)
+			}
+			metadata["tags"] = existing
+		default:
+			metadata["tags"] = tags
+		}
+	}
+}
+
+// TechniqueIDs extracts ATT&CK IDs from rule technique labels such as
+// "T1110.003 - Brute Force: Password Spraying"
+func TechniqueIDs(techniques []string) []string {
+	ids := make([]string, 0, len(techniques))
+	for _, technique := range techniques {
+		id, _, _ := strings.Cut(technique, " ")
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func hasMarker(alert Alert, runID string) bool {
+	for _, marker := range alert.Markers {
+		if marker == runID {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(s string, values []string) bool {
+	for _, value := range values {
+		if !strings.Contains(s, value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,322 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/client"
+	"github.com/telhawk-systems/telhawk-stack/cli/internal/seeder"
+	"github.com/telhawk-systems/telhawk-stack/cli/pkg/attacks"
+)
+
+const failedLoginsRule = `{
+  "name": "failed_logins",
+  "model": {
+    "correlation_type": "event_count",
+    "parameters": {
+      "time_window": "5m",
+      "query": {"filter": {"type": "and", "conditions": [
+        {"field": ".class_uid", "operator": "eq", "value": 3002},
+        {"field": ".status_id", "operator": "eq", "value": 2}
+      ]}},
+      "threshold": {"value": 5, "operator": "gt"},
+      "group_by": [".actor.user.name"]
+    }
+  },
+  "view": {
+    "title": "Multiple Failed Login Attempts",
+    "severity": "medium",
+    "mitre_attack": {"techniques": ["T1110.001 - Brute Force: Password Guessing"]}
+  }
+}`
+
+const escalationRule = `{
+  "name": "login_then_escalation",
+  "model": {
+    "correlation_type": "temporal_ordered",
+    "parameters": {
+      "time_window": "15m",
+      "max_gap": "10m",
+      "group_by": [".actor.user.name"],
+      "sequence": [
+        {"step": 1, "name": "failed_login", "query": {"filter": {"type": "and", "conditions": [
+          {"field": ".class_uid", "operator": "eq", "value": 3002},
+          {"field": ".status_id", "operator": "eq", "value": 2}
+        ]}}},
+        {"step": 2, "name": "escalation", "query": {"filter": {"field": ".class_uid", "operator": "eq", "value": 1007}}}
+      ]
+    }
+  },
+  "view": {"title": "Escalation", "mitre_attack": {"techniques": ["T1068 - Exploitation for Privilege Escalation"]}}
+}`
+
+const suppressionRule = `{
+  "name": "alert_throttle",
+  "model": {"correlation_type": "suppression", "parameters": {}},
+  "view": {"title": "Throttle", "mitre_attack": {"techniques": ["T1562 - Impair Defenses"]}}
+}`
+
+func parseRule(t *testing.T, data string) *seeder.DetectionRule {
+	t.Helper()
+	var rule seeder.DetectionRule
+	require.NoError(t, json.Unmarshal([]byte(data), &rule))
+	return &rule
+}
+
+// silentTarget accepts events but never produces alerts
+type silentTarget struct {
+	sent int
+}
+
+func (t *silentTarget) Name() string { return "silent" }
+
+func (t *silentTarget) Send(ctx context.Context, events []seeder.HECEvent) error {
+	t.sent += len(events)
+	return nil
+}
+
+func (t *silentTarget) Alerts(ctx context.Context, since time.Time) ([]Alert, error) {
+	return nil, nil
+}
+
+func TestVerifyRules_Local(t *testing.T) {
+	rules := []*seeder.DetectionRule{
+		parseRule(t, failedLoginsRule),
+		parseRule(t, escalationRule),
+		parseRule(t, suppressionRule),
+	}
+	harness := &Harness{Target: NewLocalTarget(rules), Rules: rules, Multiplier: 1.5}
+
+	report, err := harness.VerifyRules(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, StatusPassed, report.Results[0].Status, report.Results[0].Message)
+	assert.Equal(t, rules[0].ID(), report.Results[0].RuleID)
+	assert.Equal(t, 9, report.Results[0].Events)
+	assert.NotEmpty(t, report.Results[0].AlertID)
+	assert.Equal(t, StatusPassed, report.Results[1].Status, report.Results[1].Message)
+	assert.Equal(t, 2, report.Results[1].Events)
+	assert.Equal(t, StatusSkipped, report.Results[2].Status)
+	assert.Contains(t, report.Results[2].Message, "suppression")
+
+	assert.Equal(t, Summary{Total: 3, Passed: 2, Skipped: 1}, report.Summary)
+	assert.True(t, report.Passed())
+	assert.Equal(t, []TechniqueCoverage{
+		{ID: "T1068", Status: StatusPassed, Detections: []string{"login_then_escalation"}},
+		{ID: "T1110.001", Status: StatusPassed, Detections: []string{"failed_logins"}},
+		{ID: "T1562", Status: StatusSkipped, Detections: []string{"alert_throttle"}},
+	}, report.Techniques)
+}
+
+func TestVerifyRules_NoAlert(t *testing.T) {
+	rules := []*seeder.DetectionRule{parseRule(t, failedLoginsRule)}
+	target := &silentTarget{}
+	harness := &Harness{Target: target, Rules: rules, Wait: 20 * time.Millisecond, PollInterval: 5 * time.Millisecond}
+
+	report, err := harness.VerifyRules(context.Background())
+	require.NoError(t, err)
+
+	assert.Greater(t, target.sent, 0)
+	assert.Equal(t, StatusFailed, report.Results[0].Status)
+	assert.Equal(t, "no alert within 20ms", report.Results[0].Message)
+	assert.False(t, report.Passed())
+	assert.Equal(t, "silent", report.Target)
+}
+
+func TestVerifyScenario_Local(t *testing.T) {
+	attacks.Register(&attacks.T1110{})
+	attacks.Register(&attacks.T1071{})
+
+	scenario, err := attacks.ParseScenario([]byte(`
+name: brute-then-beacon
+vars:
+  user: alice
+stages:
+  - name: brute-force
+    pattern: T1110.001
+    duration: 4m
+    params:
+      target-user: ${user}
+      attempts-per-ip: 10
+      ip-count: 1
+  - name: c2
+    pattern: T1071.001
+    at: 5m
+    duration: 10m
+    params:
+      beacon-count: 5
+`))
+	require.NoError(t, err)
+
+	rules := []*seeder.DetectionRule{parseRule(t, failedLoginsRule)}
+	harness := &Harness{Target: NewLocalTarget(rules), Rules: rules}
+
+	report, err := harness.VerifyScenario(context.Background(), scenario)
+	require.NoError(t, err)
+
+	assert.Equal(t, "brute-then-beacon", report.Scenario)
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, StatusPassed, report.Results[0].Status)
+	assert.Equal(t, StatusFailed, report.Results[1].Status)
+	assert.Equal(t, "no detection rule maps to T1071.001", report.Results[1].Message)
+}
+
+func TestMarkEvents(t *testing.T) {
+	events := []seeder.HECEvent{
+		{Event: map[string]interface{}{}},
+		{Event: map[string]interface{}{"metadata": map[string]interface{}{
+			"correlation_uid": "scenario-run",
+			"tags":            []string{"attack-simulation"},
+		}}},
+	}
+
+	markEvents(events, "verify-1234", "failed_logins")
+
+	first := events[0].Event["metadata"].(map[string]interface{})
+	assert.Equal(t, "verify-1234", first["correlation_uid"])
+	assert.Equal(t, []string{"verify-1234", "rule:failed_logins"}, first["tags"])
+
+	second := events[1].Event["metadata"].(map[string]interface{})
+	assert.Equal(t, "scenario-run", second["correlation_uid"], "existing correlation kept")
+	assert.Equal(t, []string{"attack-simulation", "verify-1234", "rule:failed_logins"}, second["tags"])
+}
+
+func TestExpectationMatch(t *testing.T) {
+	exp := &expectation{runID: "verify-1234", ruleIDs: map[string]bool{"rule-1": true}, groupValues: []string{"10.0.0.5"}}
+	run := []string{"verify-1234"}
+
+	_, ok := exp.match([]Alert{{RuleID: "rule-2", GroupKey: "10.0.0.5", Markers: run}})
+	assert.False(t, ok, "other rule")
+
+	_, ok = exp.match([]Alert{{RuleID: "rule-1", GroupKey: "10.0.0.9", Markers: run}})
+	assert.False(t, ok, "other group")
+
+	_, ok = exp.match([]Alert{{RuleID: "rule-1", GroupKey: "src_endpoint.ip=10.0.0.5"}})
+	assert.False(t, ok, "same rule without the run marker")
+
+	_, ok = exp.match([]Alert{{RuleID: "rule-1", GroupKey: "src_endpoint.ip=10.0.0.5", Markers: []string{"verify-5678"}}})
+	assert.False(t, ok, "same rule from another run")
+
+	alert, ok := exp.match([]Alert{{ID: "a1", RuleID: "rule-1", GroupKey: "src_endpoint.ip=10.0.0.5", Markers: run}})
+	assert.True(t, ok)
+	assert.Equal(t, "a1", alert.ID)
+
+	_, ok = exp.match([]Alert{{RuleID: "rule-1", Markers: run}})
+	assert.True(t, ok, "alerts without a group key match on rule")
+}
+
+func TestVerifyRules_IgnoresOtherTraffic(t *testing.T) {
+	rules := []*seeder.DetectionRule{parseRule(t, failedLoginsRule)}
+	target := NewLocalTarget(rules)
+
+	// Events for the same rule from another run fire an alert that
+	// carries none of this run's markers
+	events, err := seeder.NewRuleBasedGenerator(rules[0], 1.5, nil).GenerateEvents()
+	require.NoError(t, err)
+	markEvents(events, "other-run", "")
+	require.NoError(t, target.Send(context.Background(), events))
+
+	alerts, err := target.Alerts(context.Background(), time.Time{})
+	require.NoError(t, err)
+	require.NotEmpty(t, alerts)
+	exp := &expectation{runID: "verify-1234", ruleIDs: map[string]bool{rules[0].ID(): true}}
+	_, ok := exp.match(alerts)
+	assert.False(t, ok, "alerts from other traffic must not satisfy the run")
+}
+
+func TestAlertMarkers(t *testing.T) {
+	var alert client.Alert
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"_id": "a1",
+		"metadata": {"correlation_uid": "alert-uid"},
+		"matched_events": [
+			"event-id",
+			{"metadata": {"correlation_uid": "scenario-run", "tags": ["verify-1234", "rule:failed_logins"]}}
+		]
+	}`), &alert))
+
+	assert.Equal(t, []string{"alert-uid", "verify-1234", "rule:failed_logins", "scenario-run"}, alertMarkers(&alert))
+}
+
+func TestTechniqueIDs(t *testing.T) {
+	assert.Equal(t, []string{"T1087", "T1110.003", "T1059"},
+		TechniqueIDs([]string{"T1087 - Account Discovery", "T1110.003 - Brute Force: Password Spraying", "T1059", " "}))
+}
+
+func TestRulesForTechnique(t *testing.T) {
+	rule := func(name string, techniques ...string) *seeder.DetectionRule {
+		r := &seeder.DetectionRule{Name: name}
+		r.View.MITREAttack.Techniques = techniques
+		return r
+	}
+	harness := &Harness{Rules: []*seeder.DetectionRule{
+		rule("parent", "T1087 - Account Discovery"),
+		rule("exact", "T1087.002 - Domain Account"),
+		rule("sibling", "T1087.001 - Local Account"),
+		rule("other", "T1110"),
+	}}
+
+	var names []string
+	for _, r := range harness.rulesForTechnique("T1087.002") {
+		names = append(names, r.Name)
+	}
+	assert.Equal(t, []string{"parent", "exact"}, names)
+	assert.Len(t, harness.rulesForTechnique("T1087"), 3, "sub-techniques cover their parent")
+}
+
+func TestReport_WriteJUnit(t *testing.T) {
+	report := (&Report{
+		RunID:     "verify-abcd",
+		StartedAt: time.Now(),
+		Results: []*Result{
+			{Name: "failed_logins", Techniques: []string{"T1110.001"}, Status: StatusPassed},
+			{Name: "port_scanning", Techniques: []string{"T1046"}, Status: StatusFailed, Message: "no alert within 2m0s"},
+			{Name: "beaconing", Techniques: []string{"T1071"}, Status: StatusSkipped, Message: "not supported"},
+			{Name: "ssh_brute_force", Techniques: []string{"T1110.001"}, Status: StatusError, Message: "send failed"},
+		},
+	}).finish()
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJUnit(&buf))
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	assert.Equal(t, 7, suites.Tests)
+	assert.Equal(t, 2, suites.Failures)
+	assert.Equal(t, 1, suites.Errors)
+	assert.Equal(t, 2, suites.Skipped)
+
+	require.Len(t, suites.Suites, 2)
+	detections := suites.Suites[0]
+	assert.Equal(t, "detections", detections.Name)
+	require.NotNil(t, detections.Cases[1].Failure)
+	assert.Equal(t, "no alert within 2m0s", detections.Cases[1].Failure.Message)
+	assert.Nil(t, detections.Cases[0].Failure)
+
+	techniques := suites.Suites[1]
+	assert.Equal(t, "attack.techniques", techniques.Name)
+	assert.Equal(t, []string{"T1046", "T1071", "T1110.001"},
+		[]string{techniques.Cases[0].Name, techniques.Cases[1].Name, techniques.Cases[2].Name})
+	assert.Nil(t, techniques.Cases[2].Failure, "one passing detection covers the technique")
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	report := (&Report{RunID: "verify-abcd", Target: "local", StartedAt: time.Now(), Results: []*Result{
+		{Name: "failed_logins", Status: StatusPassed, Events: 9},
+	}}).finish()
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "verify-abcd", decoded["run_id"])
+	assert.Equal(t, float64(1), decoded["summary"].(map[string]interface{})["passed"])
+}
@@ -1,6 +1,7 @@
 package attacks
 
 import (
+	"sort"
 	"strconv"
 	"time"
 )
@@ -53,12 +54,13 @@ func Get(name string) (Pattern, bool) {
 	return p, ok
 }
 
-// List returns all registered attack pattern names
+// List returns all registered attack pattern names, sorted
 func List() []string {
 	names := make([]string, 0, len(Registry))
 	for name := range Registry {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
 
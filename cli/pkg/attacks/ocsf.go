@@ -0,0 +1,192 @@
+package attacks
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// Shared parameter names. Patterns that involve the same kind of entity use
+// the same name so scenarios can thread one actor/host/IP through every stage.
+const (
+	ParamUser        = "user"         // Compromised account
+	ParamDomain      = "domain"       // Windows/AD domain of the account
+	ParamHost        = "host"         // Compromised host
+	ParamHostIP      = "host-ip"      // IP of the compromised host
+	ParamTargetHosts = "target-hosts" // Hosts reached from the compromised host
+	ParamTargetIPs   = "target-ips"   // IPs of target-hosts (same order)
+	ParamDCHost      = "dc-host"      // Domain controller
+	ParamDCIP        = "dc-ip"        // Domain controller IP
+	ParamC2Domain    = "c2-domain"    // Command and control domain
+	ParamC2IP        = "c2-ip"        // Command and control IP
+	ParamExfilIP     = "exfil-ip"     // Exfiltration destination
+)
+
+// Technique identifies the ATT&CK technique and tactic an event simulates.
+type Technique struct {
+	UID       string // e.g. "T1021.002"
+	Name      string
+	TacticUID string // e.g. "TA0008"
+	Tactic    string
+}
+
+// OCSF sourcetypes used by attack patterns.
+const (
+	SourceTypeAuthentication   = "ocsf:authentication"
+	SourceTypeAuthorizeSession = "ocsf:authorize_session"
+	SourceTypeProcess          = "ocsf:process_activity"
+	SourceTypeFile             = "ocsf:file_activity"
+	SourceTypeScheduledJob     = "ocsf:scheduled_job_activity"
+	SourceTypeNetwork          = "ocsf:network_activity"
+	SourceTypeDNS              = "ocsf:dns_activity"
+	SourceTypeHTTP             = "ocsf:http_activity"
+)
+
+// newOCSFEvent returns the base fields shared by every simulated event:
+// classification, severity, ATT&CK mapping and seeder metadata.
+func newOCSFEvent(technique Technique, classUID int, className string, categoryUID, activityID int, activityName string, severityID int, tags ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"class_uid":     classUID,
+		"class_name":    className,
+		"category_uid":  categoryUID,
+		"activity_id":   activityID,
+		"activity_name": activityName,
+		"type_uid":      classUID*100 + activityID,
+		"severity_id":   severityID,
+		"attacks": []map[string]interface{}{{
+			"technique": map[string]interface{}{"uid": technique.UID, "name": technique.Name},
+			"tactic":    map[string]interface{}{"uid": technique.TacticUID, "name": technique.Tactic},
+			"version":   "14.1",
+		}},
+		"metadata": map[string]interface{}{
+			"product": map[string]interface{}{
+				"vendor_name": "TelHawk",
+				"name":        "Event Seeder - Attack Pattern " + technique.UID,
+				"version":     "1.0.0",
+			},
+			"tags": append([]string{"attack-simulation", technique.UID}, tags...),
+		},
+	}
+}
+
+// toHECEvent wraps an OCSF event for HEC submission.
+func toHECEvent(t time.Time, sourceType string, event map[string]interface{}) HECEvent {
+	return HECEvent{
+		Time:       float64(t.Unix()) + float64(t.Nanosecond())/1e9,
+		Event:      event,
+		SourceType: sourceType,
+	}
+}
+
+func userObject(name, domain string) map[string]interface{} {
+	user := map[string]interface{}{
+		"name": name,
+		"uid":  userUID(name),
+	}
+	if domain != "" {
+		user["domain"] = domain
+	}
+	return user
+}
+
+func deviceObject(host, ip string) map[string]interface{} {
+	return map[string]interface{}{
+		"hostname": host,
+		"ip":       ip,
+		"type_id":  1, // Server/workstation
+		"os": map[string]interface{}{
+			"name":    "Windows",
+			"type_id": 100,
+		},
+	}
+}
+
+func processObject(name, cmdLine string, pid int, user map[string]interface{}, parent map[string]interface{}) map[string]interface{} {
+	process := map[string]interface{}{
+		"pid":      pid,
+		"name":     name,
+		"cmd_line": cmdLine,
+		"uid":      gofakeit.UUID(),
+	}
+	if user != nil {
+		process["user"] = user
+	}
+	if parent != nil {
+		process["parent_process"] = parent
+	}
+	return process
+}
+
+func endpoint(host, ip string, port int) map[string]interface{} {
+	ep := map[string]interface{}{"ip": ip}
+	if host != "" {
+		ep["hostname"] = host
+	}
+	if port > 0 {
+		ep["port"] = port
+	}
+	return ep
+}
+
+// userUID derives a stable SID-like identifier so the same user name maps to
+// the same uid across stages.
+func userUID(name string) string {
+	var h uint32 = 2166136261
+	for i := 0; i < len(name); i++ {
+		h ^= uint32(name[i])
+		h *= 16777619
+	}
+	return fmt.Sprintf("S-1-5-21-3623811015-3361044348-30300820-%d", 1000+h%90000)
+}
+
+func randomPID() int {
+	return rand.Intn(60000) + 1000
+}
+
+func ephemeralPort() int {
+	return rand.Intn(65535-49152) + 49152
+}
+
+// internalIP returns a random RFC 1918 address.
+func internalIP() string {
+	return fmt.Sprintf("10.%d.%d.%d", rand.Intn(256), rand.Intn(256), rand.Intn(253)+1)
+}
+
+// sequenceTime spreads the steps of a multi-event pattern across the time
+// window while keeping them in order.
+func sequenceTime(cfg *Config, index, total int) time.Time {
+	return calculateJitteredTime(cfg.Now, cfg.TimeSpread, index, total)
+}
+
+// GetStringListParam extracts a list parameter given as a YAML list or a
+// comma-separated string
+func GetStringListParam(cfg *Config, key string, defaultValue []string) []string {
+	if cfg.Params == nil {
+		return defaultValue
+	}
+
+	switch val := cfg.Params[key].(type) {
+	case []string:
+		return val
+	case []interface{}:
+		list := make([]string, 0, len(val))
+		for _, item := range val {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list
+	case string:
+		if val == "" {
+			return defaultValue
+		}
+		list := strings.Split(val, ",")
+		for i := range list {
+			list[i] = strings.TrimSpace(list[i])
+		}
+		return list
+	}
+
+	return defaultValue
+}
@@ -0,0 +1,180 @@
+package attacks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// builtinPatterns lists the multi-class patterns; tests register them
+// explicitly because other tests replace the global Registry.
+func builtinPatterns() []Pattern {
+	return []Pattern{&T1110{}, &T1021{}, &T1053{}, &T1087{}, &T1068{}, &T1048{}, &T1071{}}
+}
+
+func registerBuiltinPatterns() {
+	for _, pattern := range builtinPatterns() {
+		Register(pattern)
+	}
+}
+
+func TestPatterns_Generate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	spread := 30 * time.Minute
+
+	tests := []struct {
+		pattern     Pattern
+		wantCount   int
+		sourceTypes []string
+		tactic      string
+	}{
+		{&T1021{}, 9, []string{SourceTypeNetwork, SourceTypeAuthentication, SourceTypeProcess}, "TA0008"},
+		{&T1053{}, 6, []string{SourceTypeFile, SourceTypeProcess, SourceTypeScheduledJob}, "TA0003"},
+		{&T1087{}, 13, []string{SourceTypeProcess, SourceTypeNetwork}, "TA0007"},
+		{&T1068{}, 6, []string{SourceTypeProcess, SourceTypeAuthorizeSession}, "TA0004"},
+		{&T1048{}, 8, []string{SourceTypeProcess, SourceTypeFile, SourceTypeDNS, SourceTypeNetwork}, "TA0010"},
+		{&T1071{}, 61, []string{SourceTypeDNS, SourceTypeHTTP}, "TA0011"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern.Name(), func(t *testing.T) {
+			assert.NotEmpty(t, tt.pattern.Description())
+
+			events, err := tt.pattern.Generate(&Config{Now: now, TimeSpread: spread, Params: tt.pattern.DefaultParams()})
+			require.NoError(t, err)
+			assert.Len(t, events, tt.wantCount)
+
+			seenTypes := make(map[string]bool)
+			for i, event := range events {
+				seenTypes[event.SourceType] = true
+
+				eventTime := time.Unix(0, int64(event.Time*1e9))
+				assert.False(t, eventTime.After(now.Add(time.Second)), "event %d after window", i)
+				assert.False(t, eventTime.Before(now.Add(-spread-time.Second)), "event %d before window", i)
+				if i > 0 {
+					assert.GreaterOrEqual(t, event.Time, events[i-1].Time-float64(spread/time.Second)*0.1, "steps stay roughly ordered")
+				}
+
+				assert.Equal(t, event.Event["class_uid"].(int)*100+event.Event["activity_id"].(int), event.Event["type_uid"])
+				attacks := event.Event["attacks"].([]map[string]interface{})
+				assert.Equal(t, tt.pattern.Name(), attacks[0]["technique"].(map[string]interface{})["uid"])
+				assert.Equal(t, tt.tactic, attacks[0]["tactic"].(map[string]interface{})["uid"])
+				tags := event.Event["metadata"].(map[string]interface{})["tags"].([]string)
+				assert.Contains(t, tags, tt.pattern.Name())
+			}
+			for _, sourceType := range tt.sourceTypes {
+				assert.True(t, seenTypes[sourceType], "expected %s events", sourceType)
+			}
+		})
+	}
+}
+
+func TestPatterns_SharedParams(t *testing.T) {
+	params := map[string]interface{}{
+		ParamUser:   "alice",
+		ParamDomain: "ACME",
+		ParamHost:   "WS-ALICE",
+		ParamHostIP: "10.1.2.3",
+	}
+
+	for _, pattern := range []Pattern{&T1053{}, &T1087{}, &T1068{}, &T1048{}} {
+		t.Run(pattern.Name(), func(t *testing.T) {
+			events, err := pattern.Generate(&Config{Now: time.Now(), TimeSpread: time.Hour, Params: params})
+			require.NoError(t, err)
+
+			for _, event := range events {
+				if device, ok := event.Event["device"].(map[string]interface{}); ok {
+					assert.Equal(t, "WS-ALICE", device["hostname"])
+					assert.Equal(t, "10.1.2.3", device["ip"])
+				}
+				if src, ok := event.Event["src_endpoint"].(map[string]interface{}); ok {
+					assert.Equal(t, "10.1.2.3", src["ip"])
+				}
+			}
+			actor := events[0].Event["actor"].(map[string]interface{})
+			assert.Equal(t, "alice", actor["user"].(map[string]interface{})["name"])
+			assert.Equal(t, "ACME", actor["user"].(map[string]interface{})["domain"])
+		})
+	}
+}
+
+func TestT1021_TargetHosts(t *testing.T) {
+	events, err := (&T1021{}).Generate(&Config{
+		Now:        time.Now(),
+		TimeSpread: time.Hour,
+		Params: map[string]interface{}{
+			ParamHostIP:      "10.1.2.3",
+			ParamTargetHosts: []interface{}{"SRV-A", "SRV-B"},
+			ParamTargetIPs:   "10.9.9.1, 10.9.9.2",
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 6)
+
+	dst := events[3].Event["dst_endpoint"].(map[string]interface{})
+	assert.Equal(t, "SRV-B", dst["hostname"])
+	assert.Equal(t, "10.9.9.2", dst["ip"])
+	assert.Equal(t, 445, dst["port"])
+	assert.Equal(t, "10.1.2.3", events[3].Event["src_endpoint"].(map[string]interface{})["ip"])
+}
+
+func TestT1071_BeaconsArePeriodic(t *testing.T) {
+	now := time.Now()
+	events, err := (&T1071{}).Generate(&Config{
+		Now:        now,
+		TimeSpread: 100 * time.Minute,
+		Params:     map[string]interface{}{"beacon-count": 9, "jitter-pct": 0, ParamC2IP: "203.0.113.9"},
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 10)
+
+	for i := 2; i < len(events); i++ {
+		assert.InDelta(t, 600, events[i].Time-events[i-1].Time, 0.001, "beacon interval")
+		assert.Equal(t, "203.0.113.9", events[i].Event["dst_endpoint"].(map[string]interface{})["ip"])
+	}
+}
+
+func TestPatterns_InvalidParams(t *testing.T) {
+	cfg := func(key string, value interface{}) *Config {
+		return &Config{Now: time.Now(), Params: map[string]interface{}{key: value}}
+	}
+
+	_, err := (&T1021{}).Generate(cfg("target-count", 0))
+	assert.Error(t, err)
+	_, err = (&T1053{}).Generate(cfg("executions", -1))
+	assert.Error(t, err)
+	_, err = (&T1048{}).Generate(cfg("chunks", 0))
+	assert.Error(t, err)
+	_, err = (&T1071{}).Generate(cfg("beacon-count", 0))
+	assert.Error(t, err)
+}
+
+func TestT1110_SourceIPs(t *testing.T) {
+	events, err := (&T1110{}).Generate(&Config{
+		Now:    time.Now(),
+		Params: map[string]interface{}{"source-ips": "198.51.100.7", "attempts-per-ip": 4},
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	for _, event := range events {
+		assert.Equal(t, "198.51.100.7", event.Event["src_endpoint"].(map[string]interface{})["ip"])
+	}
+}
+
+func TestGetStringListParam(t *testing.T) {
+	cfg := &Config{Params: map[string]interface{}{
+		"yaml":   []interface{}{"a", "b"},
+		"slice":  []string{"c"},
+		"csv":    "d, e",
+		"number": 42,
+	}}
+
+	assert.Equal(t, []string{"a", "b"}, GetStringListParam(cfg, "yaml", nil))
+	assert.Equal(t, []string{"c"}, GetStringListParam(cfg, "slice", nil))
+	assert.Equal(t, []string{"d", "e"}, GetStringListParam(cfg, "csv", nil))
+	assert.Equal(t, []string{"x"}, GetStringListParam(cfg, "number", []string{"x"}))
+	assert.Equal(t, []string{"x"}, GetStringListParam(cfg, "missing", []string{"x"}))
+	assert.Nil(t, GetStringListParam(&Config{}, "any", nil))
+}
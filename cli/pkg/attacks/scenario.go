@@ -0,0 +1,279 @@
+package attacks
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario chains attack patterns into a timed kill chain. Stages share the
+// scenario's variables, so the same actor, hosts and IPs appear throughout.
+//
+// Variables are passed to every stage as parameters (so a variable named
+// "user" or "host-ip" reaches every pattern that understands it) and can be
+// referenced from stage parameters as ${name}. A value of the form
+// "auto:<kind>" is generated once per run; kinds are public_ip, internal_ip,
+// user, host and domain.
+type Scenario struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Vars        map[string]string `yaml:"vars"`
+	Stages      []Stage           `yaml:"stages"`
+}
+
+// Stage runs one attack pattern in a window relative to the scenario start.
+type Stage struct {
+	Name     string                 `yaml:"name"`
+	Pattern  string                 `yaml:"pattern"`  // ATT&CK technique ID of a registered pattern
+	At       time.Duration          `yaml:"at"`       // Offset of the stage window from the scenario start
+	Duration time.Duration          `yaml:"duration"` // Length of the stage window
+	Params   map[string]interface{} `yaml:"params"`
+}
+
+// ScenarioRun is the generated output of one scenario execution.
+type ScenarioRun struct {
+	ID       string
+	Scenario string
+	Start    time.Time
+	End      time.Time
+	Vars     map[string]string
+	Stages   []StageRun
+}
+
+// StageRun holds the events generated for one stage.
+type StageRun struct {
+	Name    string
+	Pattern string
+	Start   time.Time
+	End     time.Time
+	Events  []HECEvent
+}
+
+var varRefPattern = regexp.MustCompile(`\$\{([A-Za-z0-9_.-]+)\}`)
+
+// LoadScenario reads and validates a scenario file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	return ParseScenario(data)
+}
+
+// ParseScenario parses and validates a YAML scenario
+func ParseScenario(data []byte) (*Scenario, error) {
+	var scenario Scenario
+	if err := yaml.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+// Validate checks that every stage references a registered pattern and
+// defined variables
+func (s *Scenario) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("scenario name is required")
+	}
+	if len(s.Stages) == 0 {
+		return fmt.Errorf("scenario %s: at least one stage is required", s.Name)
+	}
+
+	for name, value := range s.Vars {
+		if kind, ok := strings.CutPrefix(value, "auto:"); ok {
+			if _, err := generateVar(kind); err != nil {
+				return fmt.Errorf("scenario %s: var %s: %w", s.Name, name, err)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := range s.Stages {
+		stage := &s.Stages[i]
+		if stage.Name == "" {
+			stage.Name = stage.Pattern
+		}
+		if seen[stage.Name] {
+			return fmt.Errorf("scenario %s: duplicate stage name %s", s.Name, stage.Name)
+		}
+		seen[stage.Name] = true
+
+		if _, ok := Get(stage.Pattern); !ok {
+			return fmt.Errorf("scenario %s: stage %s: unknown attack pattern %q", s.Name, stage.Name, stage.Pattern)
+		}
+		if stage.At < 0 || stage.Duration < 0 {
+			return fmt.Errorf("scenario %s: stage %s: at and duration must not be negative", s.Name, stage.Name)
+		}
+		for key, value := range stage.Params {
+			for _, ref := range varRefs(value) {
+				if _, ok := s.Vars[ref]; !ok {
+					return fmt.Errorf("scenario %s: stage %s: param %s references undefined var %s", s.Name, stage.Name, key, ref)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Span returns the time from the start of the first stage window to the end
+// of the last one
+func (s *Scenario) Span() time.Duration {
+	var span time.Duration
+	for _, stage := range s.Stages {
+		if end := stage.At + stage.Duration; end > span {
+			span = end
+		}
+	}
+	return span
+}
+
+// Generate runs every stage so that the kill chain ends at end. Events are
+// tagged with the scenario and stage name and share a correlation ID.
+func (s *Scenario) Generate(end time.Time) (*ScenarioRun, error) {
+	vars := make(map[string]string, len(s.Vars))
+	for name, value := range s.Vars {
+		if kind, ok := strings.CutPrefix(value, "auto:"); ok {
+			generated, err := generateVar(kind)
+			if err != nil {
+				return nil, fmt.Errorf("var %s: %w", name, err)
+			}
+			value = generated
+		}
+		vars[name] = value
+	}
+
+	run := &ScenarioRun{
+		ID:       gofakeit.UUID(),
+		Scenario: s.Name,
+		Start:    end.Add(-s.Span()),
+		End:      end,
+		Vars:     vars,
+	}
+
+	for _, stage := range s.Stages {
+		pattern, ok := Get(stage.Pattern)
+		if !ok {
+			return nil, fmt.Errorf("stage %s: unknown attack pattern %q", stage.Name, stage.Pattern)
+		}
+
+		// Scenario vars first, then the stage's own (substituted) params
+		params := make(map[string]interface{}, len(vars)+len(stage.Params))
+		for name, value := range vars {
+			params[name] = value
+		}
+		for key, value := range stage.Params {
+			params[key] = substituteVars(value, vars)
+		}
+
+		stageStart := run.Start.Add(stage.At)
+		stageEnd := stageStart.Add(stage.Duration)
+		events, err := pattern.Generate(&Config{
+			Now:        stageEnd,
+			TimeSpread: stage.Duration,
+			Params:     params,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("stage %s: %w", stage.Name, err)
+		}
+
+		for _, event := range events {
+			tagScenarioEvent(event.Event, run.ID, s.Name, stage.Name)
+		}
+
+		run.Stages = append(run.Stages, StageRun{
+			Name:    stage.Name,
+			Pattern: stage.Pattern,
+			Start:   stageStart,
+			End:     stageEnd,
+			Events:  events,
+		})
+	}
+
+	return run, nil
+}
+
+// Events returns the events of all stages in time order
+func (r *ScenarioRun) Events() []HECEvent {
+	var events []HECEvent
+	for _, stage := range r.Stages {
+		events = append(events, stage.Events...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time < events[j].Time
+	})
+	return events
+}
+
+// tagScenarioEvent records which scenario run and stage produced an event
+func tagScenarioEvent(event map[string]interface{}, runID, scenario, stage string) {
+	metadata, ok := event["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = make(map[string]interface{})
+		event["metadata"] = metadata
+	}
+	metadata["correlation_uid"] = runID
+
+	tags, _ := metadata["tags"].([]string)
+	metadata["tags"] = append(tags, "scenario:"+scenario, "stage:"+stage)
+}
+
+// substituteVars replaces ${name} references in strings and string lists
+func substituteVars(value interface{}, vars map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return varRefPattern.ReplaceAllStringFunc(v, func(ref string) string {
+			return vars[varRefPattern.FindStringSubmatch(ref)[1]]
+		})
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = substituteVars(item, vars)
+		}
+		return list
+	}
+	return value
+}
+
+// varRefs returns the variable names referenced by a parameter value
+func varRefs(value interface{}) []string {
+	var refs []string
+	switch v := value.(type) {
+	case string:
+		for _, match := range varRefPattern.FindAllStringSubmatch(v, -1) {
+			refs = append(refs, match[1])
+		}
+	case []interface{}:
+		for _, item := range v {
+			refs = append(refs, varRefs(item)...)
+		}
+	}
+	return refs
+}
+
+// generateVar produces a value for an "auto:<kind>" variable
+func generateVar(kind string) (string, error) {
+	switch kind {
+	case "public_ip":
+		return gofakeit.IPv4Address(), nil
+	case "internal_ip":
+		return internalIP(), nil
+	case "user":
+		return strings.ToLower(gofakeit.FirstName()[:1] + gofakeit.LastName()), nil
+	case "host":
+		return fmt.Sprintf("WS-%04d", gofakeit.Number(1, 9999)), nil
+	case "domain":
+		return strings.ToLower(gofakeit.Word()+"-"+gofakeit.Word()) + ".com", nil
+	default:
+		return "", fmt.Errorf("unknown auto var kind %q", kind)
+	}
+}
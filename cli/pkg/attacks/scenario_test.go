@@ -0,0 +1,145 @@
+package attacks
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testScenario = `
+name: test-chain
+vars:
+  user: alice
+  host: WS-ALICE
+  host-ip: 10.1.2.3
+  attacker: auto:public_ip
+stages:
+  - name: brute-force
+    pattern: T1110.001
+    at: 0s
+    duration: 10m
+    params:
+      target-user: ${user}
+      source-ips: ${attacker}
+      attempts-per-ip: 5
+  - pattern: T1087.002
+    at: 15m
+    duration: 5m
+  - name: c2
+    pattern: T1071.001
+    at: 5m
+    duration: 30m
+    params:
+      beacon-count: 10
+`
+
+func TestParseScenario(t *testing.T) {
+	registerBuiltinPatterns()
+
+	scenario, err := ParseScenario([]byte(testScenario))
+	require.NoError(t, err)
+
+	assert.Equal(t, "test-chain", scenario.Name)
+	require.Len(t, scenario.Stages, 3)
+	assert.Equal(t, 10*time.Minute, scenario.Stages[0].Duration)
+	assert.Equal(t, "T1087.002", scenario.Stages[1].Name, "name defaults to the pattern")
+	assert.Equal(t, 35*time.Minute, scenario.Span())
+}
+
+func TestParseScenario_Invalid(t *testing.T) {
+	registerBuiltinPatterns()
+
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"missing name", "stages: [{pattern: T1068}]"},
+		{"no stages", "name: x"},
+		{"unknown pattern", "name: x\nstages: [{pattern: T9999}]"},
+		{"duplicate stage", "name: x\nstages: [{pattern: T1068}, {pattern: T1068}]"},
+		{"undefined var", "name: x\nstages: [{pattern: T1068, params: {exploit: '${missing}'}}]"},
+		{"unknown auto kind", "name: x\nvars: {a: 'auto:nope'}\nstages: [{pattern: T1068}]"},
+		{"negative offset", "name: x\nstages: [{pattern: T1068, at: -5m}]"},
+		{"bad yaml", "name: [x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseScenario([]byte(tt.yaml))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestScenario_Generate(t *testing.T) {
+	registerBuiltinPatterns()
+
+	scenario, err := ParseScenario([]byte(testScenario))
+	require.NoError(t, err)
+
+	end := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	run, err := scenario.Generate(end)
+	require.NoError(t, err)
+
+	assert.Equal(t, end.Add(-35*time.Minute), run.Start)
+	require.Len(t, run.Stages, 3)
+	assert.Equal(t, run.Start.Add(15*time.Minute), run.Stages[1].Start)
+	assert.Equal(t, run.Start.Add(20*time.Minute), run.Stages[1].End)
+
+	attacker := run.Vars["attacker"]
+	require.NotEmpty(t, attacker)
+
+	// Stage params resolve vars; scenario vars reach every stage
+	for _, event := range run.Stages[0].Events {
+		assert.Equal(t, attacker, event.Event["src_endpoint"].(map[string]interface{})["ip"])
+		user := event.Event["actor"].(map[string]interface{})["user"].(map[string]interface{})
+		assert.Equal(t, "alice", user["name"])
+	}
+	for _, event := range run.Stages[1].Events {
+		if device, ok := event.Event["device"].(map[string]interface{}); ok {
+			assert.Equal(t, "WS-ALICE", device["hostname"])
+		}
+	}
+
+	// Events stay inside their stage windows
+	for _, stage := range run.Stages {
+		for _, event := range stage.Events {
+			eventTime := time.Unix(0, int64(event.Time*1e9))
+			assert.False(t, eventTime.Before(stage.Start.Add(-time.Second)), "%s event before window", stage.Name)
+			assert.False(t, eventTime.After(stage.End.Add(time.Second)), "%s event after window", stage.Name)
+		}
+	}
+
+	events := run.Events()
+	assert.Len(t, events, 5+13+11)
+	for i, event := range events {
+		if i > 0 {
+			assert.GreaterOrEqual(t, event.Time, events[i-1].Time, "events are time ordered")
+		}
+		metadata := event.Event["metadata"].(map[string]interface{})
+		assert.Equal(t, run.ID, metadata["correlation_uid"])
+		assert.Contains(t, metadata["tags"], "scenario:test-chain")
+	}
+}
+
+func TestExampleScenarios(t *testing.T) {
+	registerBuiltinPatterns()
+
+	files, err := filepath.Glob("../../scenarios/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			scenario, err := LoadScenario(file)
+			require.NoError(t, err)
+
+			run, err := scenario.Generate(time.Now())
+			require.NoError(t, err)
+			assert.NotEmpty(t, run.Events())
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package attacks
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// T1021 implements MITRE ATT&CK T1021.002 - Remote Services: SMB/Windows Admin Shares
+// Generates SMB connections, network logons and remote service execution
+// (PsExec-style) from one compromised host to several targets
+type T1021 struct{}
+
+var t1021Technique = Technique{
+	UID:       "T1021.002",
+	Name:      "Remote Services: SMB/Windows Admin Shares",
+	TacticUID: "TA0008",
+	Tactic:    "Lateral Movement",
+}
+
+func init() {
+	Register(&T1021{})
+}
+
+func (a *T1021) Name() string {
+	return t1021Technique.UID
+}
+
+func (a *T1021) Description() string {
+	return "Lateral Movement: SMB/Admin Shares - Network logons and remote service execution across hosts"
+}
+
+func (a *T1021) DefaultParams() map[string]interface{} {
+	return map[string]interface{}{
+		ParamUser:      "svc_backup", // Account used to move laterally
+		ParamDomain:    "CORP",
+		ParamHost:      "WS-0142", // Source of the lateral movement
+		ParamHostIP:    "10.10.1.42",
+		"target-count": 3, // Used when target-hosts is not set
+	}
+}
+
+func (a *T1021) Generate(cfg *Config) ([]HECEvent, error) {
+	user := GetStringParam(cfg, ParamUser, "svc_backup")
+	domain := GetStringParam(cfg, ParamDomain, "CORP")
+	host := GetStringParam(cfg, ParamHost, "WS-0142")
+	hostIP := GetStringParam(cfg, ParamHostIP, "10.10.1.42")
+
+	targets := GetStringListParam(cfg, ParamTargetHosts, nil)
+	if len(targets) == 0 {
+		count := GetIntParam(cfg, "target-count", 3)
+		if count < 1 {
+			return nil, fmt.Errorf("target-count must be at least 1")
+		}
+		for i := 0; i < count; i++ {
+			targets = append(targets, fmt.Sprintf("SRV-%s%02d", []string{"FILE", "DB", "APP", "WEB"}[i%4], i+1))
+		}
+	}
+	targetIPs := GetStringListParam(cfg, ParamTargetIPs, nil)
+
+	const stepsPerTarget = 3
+	total := len(targets) * stepsPerTarget
+	events := make([]HECEvent, 0, total)
+	actorUser := userObject(user, domain)
+	serviceName := fmt.Sprintf("PSEXESVC-%04X", rand.Intn(0xFFFF))
+
+	for i, target := range targets {
+		targetIP := internalIP()
+		if i < len(targetIPs) {
+			targetIP = targetIPs[i]
+		}
+		srcPort := ephemeralPort()
+
+		// 1. SMB session to the admin share
+		network := newOCSFEvent(t1021Technique, 4001, "Network Activity", 4, 1, "Open", 3, "lateral-movement", "smb")
+		network["src_endpoint"] = endpoint(host, hostIP, srcPort)
+		network["dst_endpoint"] = endpoint(target, targetIP, 445)
+		network["connection_info"] = map[string]interface{}{
+			"protocol_name": "tcp",
+			"direction":     "Lateral",
+			"direction_id":  3,
+			"boundary":      "internal",
+		}
+		network["app_name"] = "SMB"
+		network["message"] = fmt.Sprintf("SMB connection from %s to \\\\%s\\ADMIN$", host, target)
+		events = append(events, toHECEvent(sequenceTime(cfg, i*stepsPerTarget, total), SourceTypeNetwork, network))
+
+		// 2. Successful network logon on the target
+		auth := newOCSFEvent(t1021Technique, 3002, "Authentication", 3, 1, "Logon", 3, "lateral-movement")
+		auth["status_id"] = 1
+		auth["status"] = "Success"
+		auth["logon_type_id"] = 3
+		auth["logon_type"] = "Network"
+		auth["auth_protocol"] = "NTLM"
+		auth["is_remote"] = true
+		auth["user"] = actorUser
+		auth["actor"] = map[string]interface{}{"user": actorUser}
+		auth["src_endpoint"] = endpoint(host, hostIP, srcPort)
+		auth["dst_endpoint"] = endpoint(target, targetIP, 445)
+		auth["device"] = deviceObject(target, targetIP)
+		auth["message"] = fmt.Sprintf("Network logon for %s\\%s from %s", domain, user, hostIP)
+		events = append(events, toHECEvent(sequenceTime(cfg, i*stepsPerTarget+1, total), SourceTypeAuthentication, auth))
+
+		// 3. Remote service spawns a shell on the target
+		services := processObject("services.exe", "C:\\Windows\\system32\\services.exe", 700+rand.Intn(100), nil, nil)
+		svc := processObject(serviceName+".exe", "C:\\Windows\\"+serviceName+".exe", randomPID(),
+			userObject("SYSTEM", "NT AUTHORITY"), services)
+		process := newOCSFEvent(t1021Technique, 1007, "Process Activity", 1, 1, "Launch", 4, "lateral-movement", "psexec")
+		process["process"] = processObject("cmd.exe", "cmd.exe /c \"whoami && hostname\"", randomPID(),
+			userObject("SYSTEM", "NT AUTHORITY"), svc)
+		process["actor"] = map[string]interface{}{"user": actorUser, "process": svc}
+		process["device"] = deviceObject(target, targetIP)
+		process["message"] = fmt.Sprintf("Remote service %s launched cmd.exe on %s", serviceName, target)
+		events = append(events, toHECEvent(sequenceTime(cfg, i*stepsPerTarget+2, total), SourceTypeProcess, process))
+	}
+
+	return events, nil
+}
@@ -0,0 +1,127 @@
+package attacks
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// T1048 implements MITRE ATT&CK T1048.003 - Exfiltration Over Unencrypted Non-C2 Protocol
+// Generates data staging (archive creation), a DNS lookup of the drop site
+// and large outbound FTP transfers split into chunks
+type T1048 struct{}
+
+var t1048Technique = Technique{
+	UID:       "T1048.003",
+	Name:      "Exfiltration Over Alternative Protocol: Exfiltration Over Unencrypted Non-C2 Protocol",
+	TacticUID: "TA0010",
+	Tactic:    "Exfiltration",
+}
+
+func init() {
+	Register(&T1048{})
+}
+
+func (a *T1048) Name() string {
+	return t1048Technique.UID
+}
+
+func (a *T1048) Description() string {
+	return "Exfiltration: Unencrypted Protocol - Archive staging followed by large outbound FTP transfers"
+}
+
+func (a *T1048) DefaultParams() map[string]interface{} {
+	return map[string]interface{}{
+		ParamUser:      "jsmith",
+		ParamDomain:    "CORP",
+		ParamHost:      "WS-0142",
+		ParamHostIP:    "10.10.1.42",
+		"exfil-domain": "files.quickshare-cdn.net",
+		"chunks":       5,   // Number of outbound transfers
+		"total-mb":     750, // Total data exfiltrated
+	}
+}
+
+func (a *T1048) Generate(cfg *Config) ([]HECEvent, error) {
+	user := GetStringParam(cfg, ParamUser, "jsmith")
+	domain := GetStringParam(cfg, ParamDomain, "CORP")
+	host := GetStringParam(cfg, ParamHost, "WS-0142")
+	hostIP := GetStringParam(cfg, ParamHostIP, "10.10.1.42")
+	exfilDomain := GetStringParam(cfg, "exfil-domain", "files.quickshare-cdn.net")
+	exfilIP := GetStringParam(cfg, ParamExfilIP, "")
+	if exfilIP == "" {
+		exfilIP = gofakeit.IPv4Address()
+	}
+	chunks := GetIntParam(cfg, "chunks", 5)
+	totalMB := GetIntParam(cfg, "total-mb", 750)
+	if chunks < 1 || totalMB < 1 {
+		return nil, fmt.Errorf("chunks and total-mb must be at least 1")
+	}
+
+	total := 3 + chunks
+	events := make([]HECEvent, 0, total)
+	actorUser := userObject(user, domain)
+	device := deviceObject(host, hostIP)
+	archive := fmt.Sprintf("C:\\Users\\%s\\AppData\\Local\\Temp\\backup_%d.7z", user, rand.Intn(9000)+1000)
+	totalBytes := int64(totalMB) << 20
+	shell := processObject("cmd.exe", "cmd.exe", randomPID(), actorUser, nil)
+
+	// 1. Staging: sensitive shares compressed into a password-protected archive
+	archiveCmd := fmt.Sprintf("7z.exe a -t7z -mhe=on -p******** %s \\\\SRV-FILE01\\Finance\\*", archive)
+	process := newOCSFEvent(t1048Technique, 1007, "Process Activity", 1, 1, "Launch", 3, "exfiltration", "T1560.001")
+	process["process"] = processObject("7z.exe", archiveCmd, randomPID(), actorUser, shell)
+	process["actor"] = map[string]interface{}{"user": actorUser, "process": shell}
+	process["device"] = device
+	process["message"] = archiveCmd
+	events = append(events, toHECEvent(sequenceTime(cfg, 0, total), SourceTypeProcess, process))
+
+	file := newOCSFEvent(t1048Technique, 1001, "File System Activity", 1, 1, "Create", 3, "exfiltration", "T1560.001")
+	file["file"] = map[string]interface{}{
+		"path":    archive,
+		"name":    baseName(archive),
+		"type_id": 1,
+		"size":    totalBytes,
+	}
+	file["actor"] = map[string]interface{}{"user": actorUser}
+	file["device"] = device
+	file["message"] = fmt.Sprintf("Archive %s created (%d MB)", archive, totalMB)
+	events = append(events, toHECEvent(sequenceTime(cfg, 1, total), SourceTypeFile, file))
+
+	// 2. Drop site lookup
+	dns := newOCSFEvent(t1048Technique, 4003, "DNS Activity", 4, 1, "Query", 2, "exfiltration")
+	dns["query"] = map[string]interface{}{"hostname": exfilDomain, "type": "A", "class": "IN"}
+	dns["answers"] = []map[string]interface{}{{"type": "A", "rdata": exfilIP, "ttl": 60}}
+	dns["rcode"] = "NoError"
+	dns["rcode_id"] = 0
+	dns["src_endpoint"] = endpoint(host, hostIP, ephemeralPort())
+	dns["message"] = fmt.Sprintf("DNS query for %s", exfilDomain)
+	events = append(events, toHECEvent(sequenceTime(cfg, 2, total), SourceTypeDNS, dns))
+
+	// 3. Outbound transfers
+	chunkBytes := totalBytes / int64(chunks)
+	for i := 0; i < chunks; i++ {
+		sent := chunkBytes + int64(rand.Intn(1<<20))
+		network := newOCSFEvent(t1048Technique, 4001, "Network Activity", 4, 6, "Traffic", 4, "exfiltration", "ftp")
+		network["src_endpoint"] = endpoint(host, hostIP, ephemeralPort())
+		network["dst_endpoint"] = endpoint(exfilDomain, exfilIP, 21)
+		network["connection_info"] = map[string]interface{}{
+			"protocol_name": "tcp",
+			"direction":     "Outbound",
+			"direction_id":  2,
+			"boundary":      "external",
+		}
+		network["app_name"] = "FTP"
+		network["traffic"] = map[string]interface{}{
+			"bytes_out":   sent,
+			"bytes_in":    rand.Intn(20000) + 2000,
+			"bytes":       sent,
+			"packets_out": sent / 1400,
+		}
+		network["actor"] = map[string]interface{}{"user": actorUser}
+		network["message"] = fmt.Sprintf("Outbound FTP transfer of %d bytes from %s to %s", sent, host, exfilDomain)
+		events = append(events, toHECEvent(sequenceTime(cfg, 3+i, total), SourceTypeNetwork, network))
+	}
+
+	return events, nil
+}
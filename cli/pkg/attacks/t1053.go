@@ -0,0 +1,121 @@
+package attacks
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// T1053 implements MITRE ATT&CK T1053.005 - Scheduled Task/Job: Scheduled Task
+// Generates a payload drop, schtasks.exe task registration running as SYSTEM
+// and the recurring executions of the payload
+type T1053 struct{}
+
+var t1053Technique = Technique{
+	UID:       "T1053.005",
+	Name:      "Scheduled Task/Job: Scheduled Task",
+	TacticUID: "TA0003",
+	Tactic:    "Persistence",
+}
+
+func init() {
+	Register(&T1053{})
+}
+
+func (a *T1053) Name() string {
+	return t1053Technique.UID
+}
+
+func (a *T1053) Description() string {
+	return "Persistence: Scheduled Task - Payload registered as a recurring SYSTEM task"
+}
+
+func (a *T1053) DefaultParams() map[string]interface{} {
+	return map[string]interface{}{
+		ParamUser:    "jsmith",
+		ParamDomain:  "CORP",
+		ParamHost:    "WS-0142",
+		ParamHostIP:  "10.10.1.42",
+		"task-name":  "MicrosoftEdgeUpdateCheck",
+		"payload":    "C:\\ProgramData\\Microsoft\\EdgeUpdate\\msedgeupd.exe",
+		"executions": 3, // Task runs after registration
+	}
+}
+
+func (a *T1053) Generate(cfg *Config) ([]HECEvent, error) {
+	user := GetStringParam(cfg, ParamUser, "jsmith")
+	domain := GetStringParam(cfg, ParamDomain, "CORP")
+	host := GetStringParam(cfg, ParamHost, "WS-0142")
+	hostIP := GetStringParam(cfg, ParamHostIP, "10.10.1.42")
+	taskName := GetStringParam(cfg, "task-name", "MicrosoftEdgeUpdateCheck")
+	payload := GetStringParam(cfg, "payload", "C:\\ProgramData\\Microsoft\\EdgeUpdate\\msedgeupd.exe")
+	executions := GetIntParam(cfg, "executions", 3)
+	if executions < 0 {
+		return nil, fmt.Errorf("executions must not be negative")
+	}
+
+	total := 3 + executions
+	events := make([]HECEvent, 0, total)
+	actorUser := userObject(user, domain)
+	system := userObject("SYSTEM", "NT AUTHORITY")
+	device := deviceObject(host, hostIP)
+	shell := processObject("powershell.exe", "powershell.exe -nop -w hidden", randomPID(), actorUser, nil)
+	taskCmd := fmt.Sprintf("schtasks.exe /create /tn \"%s\" /tr \"%s\" /sc minute /mo 30 /ru SYSTEM /f", taskName, payload)
+
+	// 1. Payload dropped to disk
+	file := newOCSFEvent(t1053Technique, 1001, "File System Activity", 1, 1, "Create", 3, "persistence")
+	file["file"] = map[string]interface{}{
+		"path":    payload,
+		"name":    baseName(payload),
+		"type_id": 1,
+		"size":    rand.Intn(400000) + 100000,
+	}
+	file["actor"] = map[string]interface{}{"user": actorUser, "process": shell}
+	file["device"] = device
+	file["message"] = fmt.Sprintf("File %s created by %s", payload, user)
+	events = append(events, toHECEvent(sequenceTime(cfg, 0, total), SourceTypeFile, file))
+
+	// 2. schtasks.exe registers the task
+	schtasks := processObject("schtasks.exe", taskCmd, randomPID(), actorUser, shell)
+	process := newOCSFEvent(t1053Technique, 1007, "Process Activity", 1, 1, "Launch", 4, "persistence", "schtasks")
+	process["process"] = schtasks
+	process["actor"] = map[string]interface{}{"user": actorUser, "process": shell}
+	process["device"] = device
+	process["message"] = taskCmd
+	events = append(events, toHECEvent(sequenceTime(cfg, 1, total), SourceTypeProcess, process))
+
+	// 3. Task scheduler records the new job
+	job := newOCSFEvent(t1053Technique, 1006, "Scheduled Job Activity", 1, 1, "Create", 4, "persistence")
+	job["job"] = map[string]interface{}{
+		"name":     taskName,
+		"cmd_line": payload,
+		"file":     map[string]interface{}{"path": "C:\\Windows\\System32\\Tasks\\" + taskName, "name": taskName},
+		"user":     system,
+	}
+	job["actor"] = map[string]interface{}{"user": actorUser, "process": schtasks}
+	job["device"] = device
+	job["message"] = fmt.Sprintf("Scheduled task %s created to run %s as SYSTEM", taskName, payload)
+	events = append(events, toHECEvent(sequenceTime(cfg, 2, total), SourceTypeScheduledJob, job))
+
+	// 4. Recurring executions under the task scheduler
+	svchost := processObject("svchost.exe", "C:\\Windows\\system32\\svchost.exe -k netsvcs -p -s Schedule", 1000+rand.Intn(500), system, nil)
+	for i := 0; i < executions; i++ {
+		run := newOCSFEvent(t1053Technique, 1007, "Process Activity", 1, 1, "Launch", 3, "persistence")
+		run["process"] = processObject(baseName(payload), payload, randomPID(), system, svchost)
+		run["actor"] = map[string]interface{}{"user": system, "process": svchost}
+		run["device"] = device
+		run["message"] = fmt.Sprintf("Scheduled task %s executed %s", taskName, payload)
+		events = append(events, toHECEvent(sequenceTime(cfg, 3+i, total), SourceTypeProcess, run))
+	}
+
+	return events, nil
+}
+
+// baseName returns the file name of a Windows or POSIX path
+func baseName(path string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '\\' || path[i] == '/' {
+			return path[i+1:]
+		}
+	}
+	return path
+}
@@ -0,0 +1,100 @@
+package attacks
+
+import (
+	"fmt"
+)
+
+// T1068 implements MITRE ATT&CK T1068 - Exploitation for Privilege Escalation
+// Generates an unprivileged process spawning a SYSTEM shell, the privileges
+// assigned to the new session, and follow-up commands run as SYSTEM
+type T1068 struct{}
+
+var t1068Technique = Technique{
+	UID:       "T1068",
+	Name:      "Exploitation for Privilege Escalation",
+	TacticUID: "TA0004",
+	Tactic:    "Privilege Escalation",
+}
+
+func init() {
+	Register(&T1068{})
+}
+
+func (a *T1068) Name() string {
+	return t1068Technique.UID
+}
+
+func (a *T1068) Description() string {
+	return "Privilege Escalation: Exploitation - Unprivileged process spawns a SYSTEM shell"
+}
+
+func (a *T1068) DefaultParams() map[string]interface{} {
+	return map[string]interface{}{
+		ParamUser:   "jsmith",
+		ParamDomain: "CORP",
+		ParamHost:   "WS-0142",
+		ParamHostIP: "10.10.1.42",
+		"exploit":   "C:\\Users\\Public\\Downloads\\PrintNotifyPotato.exe",
+	}
+}
+
+func (a *T1068) Generate(cfg *Config) ([]HECEvent, error) {
+	user := GetStringParam(cfg, ParamUser, "jsmith")
+	domain := GetStringParam(cfg, ParamDomain, "CORP")
+	host := GetStringParam(cfg, ParamHost, "WS-0142")
+	hostIP := GetStringParam(cfg, ParamHostIP, "10.10.1.42")
+	exploitPath := GetStringParam(cfg, "exploit", "C:\\Users\\Public\\Downloads\\PrintNotifyPotato.exe")
+
+	followUp := []struct{ name, cmdLine string }{
+		{"whoami.exe", "whoami /priv"},
+		{"net.exe", "net user backdoor P@ssw0rd! /add"},
+		{"net.exe", "net localgroup administrators backdoor /add"},
+	}
+	total := 3 + len(followUp)
+	events := make([]HECEvent, 0, total)
+	actorUser := userObject(user, domain)
+	system := userObject("SYSTEM", "NT AUTHORITY")
+	device := deviceObject(host, hostIP)
+
+	// 1. Exploit launched by the unprivileged user
+	explorer := processObject("explorer.exe", "C:\\Windows\\explorer.exe", randomPID(), actorUser, nil)
+	exploit := processObject(baseName(exploitPath), exploitPath+" -c cmd.exe", randomPID(), actorUser, explorer)
+	exploit["integrity"] = "Medium"
+	launch := newOCSFEvent(t1068Technique, 1007, "Process Activity", 1, 1, "Launch", 3, "privilege-escalation")
+	launch["process"] = exploit
+	launch["actor"] = map[string]interface{}{"user": actorUser, "process": explorer}
+	launch["device"] = device
+	launch["message"] = fmt.Sprintf("%s launched %s", user, exploitPath)
+	events = append(events, toHECEvent(sequenceTime(cfg, 0, total), SourceTypeProcess, launch))
+
+	// 2. Exploit spawns a SYSTEM shell
+	shell := processObject("cmd.exe", "cmd.exe", randomPID(), system, exploit)
+	shell["integrity"] = "System"
+	escalate := newOCSFEvent(t1068Technique, 1007, "Process Activity", 1, 1, "Launch", 5, "privilege-escalation")
+	escalate["process"] = shell
+	escalate["actor"] = map[string]interface{}{"user": actorUser, "process": exploit}
+	escalate["device"] = device
+	escalate["message"] = fmt.Sprintf("Process %s running as %s spawned cmd.exe as NT AUTHORITY\\SYSTEM", baseName(exploitPath), user)
+	events = append(events, toHECEvent(sequenceTime(cfg, 1, total), SourceTypeProcess, escalate))
+
+	// 3. Special privileges assigned to the new session
+	authz := newOCSFEvent(t1068Technique, 3003, "Authorize Session", 3, 1, "Assign Privileges", 4, "privilege-escalation")
+	authz["user"] = system
+	authz["privileges"] = []string{"SeDebugPrivilege", "SeImpersonatePrivilege", "SeTcbPrivilege", "SeAssignPrimaryTokenPrivilege"}
+	authz["actor"] = map[string]interface{}{"user": actorUser, "process": exploit}
+	authz["device"] = device
+	authz["message"] = "Special privileges assigned to new logon (SYSTEM)"
+	events = append(events, toHECEvent(sequenceTime(cfg, 2, total), SourceTypeAuthorizeSession, authz))
+
+	// 4. Commands run with the stolen token
+	for i, command := range followUp {
+		process := newOCSFEvent(t1068Technique, 1007, "Process Activity", 1, 1, "Launch", 4, "privilege-escalation")
+		process["process"] = processObject(command.name, command.cmdLine, randomPID(), system, shell)
+		process["actor"] = map[string]interface{}{"user": system, "process": shell}
+		process["device"] = device
+		process["message"] = fmt.Sprintf("SYSTEM executed %s on %s", command.cmdLine, host)
+		events = append(events, toHECEvent(sequenceTime(cfg, 3+i, total), SourceTypeProcess, process))
+	}
+
+	return events, nil
+}
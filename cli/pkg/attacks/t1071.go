@@ -0,0 +1,115 @@
+package attacks
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// T1071 implements MITRE ATT&CK T1071.001 - Application Layer Protocol: Web Protocols
+// Generates a C2 domain lookup followed by periodic HTTPS beacons with low
+// jitter and a fixed user agent, with occasional larger result uploads
+type T1071 struct{}
+
+var t1071Technique = Technique{
+	UID:       "T1071.001",
+	Name:      "Application Layer Protocol: Web Protocols",
+	TacticUID: "TA0011",
+	Tactic:    "Command and Control",
+}
+
+const beaconUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; Trident/7.0; rv:11.0) like Gecko"
+
+func init() {
+	Register(&T1071{})
+}
+
+func (a *T1071) Name() string {
+	return t1071Technique.UID
+}
+
+func (a *T1071) Description() string {
+	return "Command and Control: Web Protocols - Periodic HTTPS beacons to a C2 domain"
+}
+
+func (a *T1071) DefaultParams() map[string]interface{} {
+	return map[string]interface{}{
+		ParamUser:       "jsmith",
+		ParamHost:       "WS-0142",
+		ParamHostIP:     "10.10.1.42",
+		ParamC2Domain:   "cdn-telemetry-update.com",
+		"beacon-count":  60,
+		"jitter-pct":    10, // Beacon interval jitter
+		"task-interval": 10, // Every Nth beacon uploads tasking results
+	}
+}
+
+func (a *T1071) Generate(cfg *Config) ([]HECEvent, error) {
+	user := GetStringParam(cfg, ParamUser, "jsmith")
+	host := GetStringParam(cfg, ParamHost, "WS-0142")
+	hostIP := GetStringParam(cfg, ParamHostIP, "10.10.1.42")
+	c2Domain := GetStringParam(cfg, ParamC2Domain, "cdn-telemetry-update.com")
+	c2IP := GetStringParam(cfg, ParamC2IP, "")
+	if c2IP == "" {
+		c2IP = gofakeit.IPv4Address()
+	}
+	beacons := GetIntParam(cfg, "beacon-count", 60)
+	jitterPct := GetIntParam(cfg, "jitter-pct", 10)
+	taskInterval := GetIntParam(cfg, "task-interval", 10)
+	if beacons < 1 {
+		return nil, fmt.Errorf("beacon-count must be at least 1")
+	}
+
+	events := make([]HECEvent, 0, beacons+1)
+	start := cfg.Now.Add(-cfg.TimeSpread)
+	interval := cfg.TimeSpread / time.Duration(beacons+1)
+	implantID := gofakeit.UUID()[:8]
+	srcPort := ephemeralPort()
+
+	// 1. C2 domain resolution
+	dns := newOCSFEvent(t1071Technique, 4003, "DNS Activity", 4, 1, "Query", 2, "c2")
+	dns["query"] = map[string]interface{}{"hostname": c2Domain, "type": "A", "class": "IN"}
+	dns["answers"] = []map[string]interface{}{{"type": "A", "rdata": c2IP, "ttl": 300}}
+	dns["rcode"] = "NoError"
+	dns["rcode_id"] = 0
+	dns["src_endpoint"] = endpoint(host, hostIP, ephemeralPort())
+	dns["message"] = fmt.Sprintf("DNS query for %s", c2Domain)
+	events = append(events, toHECEvent(start, SourceTypeDNS, dns))
+
+	// 2. Beacons at a fixed interval (unlike the jittered spread of other patterns)
+	for i := 1; i <= beacons; i++ {
+		t := start.Add(time.Duration(i) * interval)
+		if jitterPct > 0 && interval > 0 {
+			jitter := float64(interval) * float64(jitterPct) / 100
+			t = t.Add(time.Duration((rand.Float64()*2 - 1) * jitter))
+		}
+
+		method, path, reqLen, respLen := "GET", "/api/v2/check", 0, rand.Intn(200)+64
+		if taskInterval > 0 && i%taskInterval == 0 {
+			method, path, reqLen, respLen = "POST", "/api/v2/submit", rand.Intn(200000)+20000, 48
+		}
+
+		http := newOCSFEvent(t1071Technique, 4002, "HTTP Activity", 4, 1, "Connect", 3, "c2", "beacon")
+		http["http_request"] = map[string]interface{}{
+			"http_method": method,
+			"url": map[string]interface{}{
+				"scheme":       "https",
+				"hostname":     c2Domain,
+				"path":         path,
+				"query_string": "id=" + implantID,
+			},
+			"user_agent": beaconUserAgent,
+			"length":     reqLen,
+		}
+		http["http_response"] = map[string]interface{}{"code": 200, "length": respLen}
+		http["src_endpoint"] = endpoint(host, hostIP, srcPort)
+		http["dst_endpoint"] = endpoint(c2Domain, c2IP, 443)
+		http["actor"] = map[string]interface{}{"user": userObject(user, "")}
+		http["message"] = fmt.Sprintf("%s https://%s%s from %s", method, c2Domain, path, host)
+		events = append(events, toHECEvent(t, SourceTypeHTTP, http))
+	}
+
+	return events, nil
+}
@@ -0,0 +1,109 @@
+package attacks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// T1087 implements MITRE ATT&CK T1087.002 - Account Discovery: Domain Account
+// Generates the burst of built-in enumeration commands (net, nltest, whoami)
+// and LDAP queries to the domain controller typical of post-compromise recon
+type T1087 struct{}
+
+var t1087Technique = Technique{
+	UID:       "T1087.002",
+	Name:      "Account Discovery: Domain Account",
+	TacticUID: "TA0007",
+	Tactic:    "Discovery",
+}
+
+func init() {
+	Register(&T1087{})
+}
+
+func (a *T1087) Name() string {
+	return t1087Technique.UID
+}
+
+func (a *T1087) Description() string {
+	return "Discovery: Domain Account - Enumeration commands and LDAP queries against the domain controller"
+}
+
+func (a *T1087) DefaultParams() map[string]interface{} {
+	return map[string]interface{}{
+		ParamUser:   "jsmith",
+		ParamDomain: "CORP",
+		ParamHost:   "WS-0142",
+		ParamHostIP: "10.10.1.42",
+		ParamDCHost: "DC01",
+		ParamDCIP:   "10.10.0.10",
+	}
+}
+
+func (a *T1087) Generate(cfg *Config) ([]HECEvent, error) {
+	user := GetStringParam(cfg, ParamUser, "jsmith")
+	domain := GetStringParam(cfg, ParamDomain, "CORP")
+	host := GetStringParam(cfg, ParamHost, "WS-0142")
+	hostIP := GetStringParam(cfg, ParamHostIP, "10.10.1.42")
+	dcHost := GetStringParam(cfg, ParamDCHost, "DC01")
+	dcIP := GetStringParam(cfg, ParamDCIP, "10.10.0.10")
+
+	commands := []string{
+		"whoami /all",
+		"net user /domain",
+		"net group \"Domain Admins\" /domain",
+		"net group \"Enterprise Admins\" /domain",
+		"net localgroup administrators",
+		"nltest /dclist:" + strings.ToLower(domain),
+		"nltest /domain_trusts /all_trusts",
+		"ipconfig /all",
+	}
+
+	// Domain commands are followed by their LDAP query to the DC
+	queriesDC := func(command string) bool {
+		return strings.Contains(command, "/domain") || strings.HasPrefix(command, "nltest")
+	}
+	total := len(commands)
+	for _, command := range commands {
+		if queriesDC(command) {
+			total++
+		}
+	}
+	events := make([]HECEvent, 0, total)
+	actorUser := userObject(user, domain)
+	device := deviceObject(host, hostIP)
+	cmd := processObject("cmd.exe", "C:\\Windows\\system32\\cmd.exe", randomPID(), actorUser, nil)
+
+	index := 0
+	for _, command := range commands {
+		name := strings.Fields(command)[0] + ".exe"
+		process := newOCSFEvent(t1087Technique, 1007, "Process Activity", 1, 1, "Launch", 2, "discovery")
+		process["process"] = processObject(name, command, randomPID(), actorUser, cmd)
+		process["actor"] = map[string]interface{}{"user": actorUser, "process": cmd}
+		process["device"] = device
+		process["message"] = fmt.Sprintf("%s executed %s on %s", user, command, host)
+		events = append(events, toHECEvent(sequenceTime(cfg, index, total), SourceTypeProcess, process))
+		index++
+
+		if !queriesDC(command) {
+			continue
+		}
+
+		ldap := newOCSFEvent(t1087Technique, 4001, "Network Activity", 4, 1, "Open", 2, "discovery", "ldap")
+		ldap["src_endpoint"] = endpoint(host, hostIP, ephemeralPort())
+		ldap["dst_endpoint"] = endpoint(dcHost, dcIP, 389)
+		ldap["connection_info"] = map[string]interface{}{
+			"protocol_name": "tcp",
+			"direction":     "Outbound",
+			"direction_id":  2,
+			"boundary":      "internal",
+		}
+		ldap["app_name"] = "LDAP"
+		ldap["actor"] = map[string]interface{}{"user": actorUser}
+		ldap["message"] = fmt.Sprintf("LDAP query from %s to %s (%s)", host, dcHost, command)
+		events = append(events, toHECEvent(sequenceTime(cfg, index, total), SourceTypeNetwork, ldap))
+		index++
+	}
+
+	return events, nil
+}
@@ -38,15 +38,18 @@ func (a *T1110) Generate(cfg *Config) ([]HECEvent, error) {
 	attemptsPerIP := GetIntParam(cfg, "attempts-per-ip", 3)
 	targetUser := GetStringParam(cfg, "target-user", "admin")
 
-	totalEvents := ipCount * attemptsPerIP
-	events := make([]HECEvent, 0, totalEvents)
-
-	// Generate unique source IPs
-	sourceIPs := make([]string, ipCount)
-	for i := 0; i < ipCount; i++ {
-		sourceIPs[i] = gofakeit.IPv4Address()
+	// Fixed source IPs (e.g. a scenario's attacker) take precedence over ip-count
+	sourceIPs := GetStringListParam(cfg, "source-ips", nil)
+	if len(sourceIPs) == 0 {
+		sourceIPs = make([]string, ipCount)
+		for i := 0; i < ipCount; i++ {
+			sourceIPs[i] = gofakeit.IPv4Address()
+		}
 	}
 
+	totalEvents := len(sourceIPs) * attemptsPerIP
+	events := make([]HECEvent, 0, totalEvents)
+
 	eventIndex := 0
 	for _, sourceIP := range sourceIPs {
 		for attempt := 0; attempt < attemptsPerIP; attempt++ {
@@ -21,6 +21,7 @@ func LoadCLI() (*CLIConfig, error) {
 	v.SetDefault("defaults.query_url", "http://localhost:3000")
 	v.SetDefault("defaults.rules_url", "http://localhost:3000")
 	v.SetDefault("defaults.alerting_url", "http://localhost:3000")
+	v.SetDefault("defaults.findings_url", "http://localhost:8080")
 
 	// Determine config directory for CLI
 	configDir := os.Getenv("TELHAWK_CONFIG_DIR")
@@ -48,6 +49,7 @@ func LoadCLI() (*CLIConfig, error) {
 	_ = v.BindEnv("defaults.query_url", "THAWK_QUERY_URL")
 	_ = v.BindEnv("defaults.rules_url", "THAWK_RULES_URL")
 	_ = v.BindEnv("defaults.alerting_url", "THAWK_ALERTING_URL")
+	_ = v.BindEnv("defaults.findings_url", "THAWK_FINDINGS_URL")
 
 	// Also try alternate format (dots replaced with underscores)
 	_ = v.BindEnv("defaults.auth_url", "THAWK_DEFAULTS_AUTH_URL")
@@ -55,6 +57,7 @@ func LoadCLI() (*CLIConfig, error) {
 	_ = v.BindEnv("defaults.query_url", "THAWK_DEFAULTS_QUERY_URL")
 	_ = v.BindEnv("defaults.rules_url", "THAWK_DEFAULTS_RULES_URL")
 	_ = v.BindEnv("defaults.alerting_url", "THAWK_DEFAULTS_ALERTING_URL")
+	_ = v.BindEnv("defaults.findings_url", "THAWK_DEFAULTS_FINDINGS_URL")
 
 	// Read config file - don't fail if file doesn't exist
 	_ = v.ReadInConfig() // Ignore errors - file may not exist yet
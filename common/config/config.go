@@ -34,14 +34,16 @@ type Config struct {
 	NATS       NATSConfig       `mapstructure:"nats"`
 	Redis      RedisConfig      `mapstructure:"redis"`
 	Logging    LoggingConfig    `mapstructure:"logging"`
+	Tracing    TracingConfig    `mapstructure:"tracing"`
 }
 
 // AuthenticateConfig holds authenticate service configuration
 type AuthenticateConfig struct {
-	Server   ServerConfig    `mapstructure:"server"`
-	Auth     AuthConfig      `mapstructure:"auth"`
-	Ingest   IngestFwdConfig `mapstructure:"ingest"`
-	Database DatabaseConfig  `mapstructure:"database"`
+	Server         ServerConfig         `mapstructure:"server"`
+	Auth           AuthConfig           `mapstructure:"auth"`
+	PasswordPolicy PasswordPolicyConfig `mapstructure:"password_policy"`
+	Ingest         IngestFwdConfig      `mapstructure:"ingest"`
+	Database       DatabaseConfig       `mapstructure:"database"`
 }
 
 // AuthConfig holds JWT and token configuration
@@ -53,6 +55,21 @@ type AuthConfig struct {
 	RefreshTokenTTL  time.Duration `mapstructure:"refresh_token_ttl"`
 }
 
+// PasswordPolicyConfig holds password complexity, history, expiry and lockout rules
+type PasswordPolicyConfig struct {
+	MinLength           int           `mapstructure:"min_length"`
+	RequireUppercase    bool          `mapstructure:"require_uppercase"`
+	RequireLowercase    bool          `mapstructure:"require_lowercase"`
+	RequireDigit        bool          `mapstructure:"require_digit"`
+	RequireSymbol       bool          `mapstructure:"require_symbol"`
+	CommonPasswordsFile string        `mapstructure:"common_passwords_file"` // Newline-separated list of rejected passwords
+	HistoryCount        int           `mapstructure:"history_count"`         // Reject reuse of the last N passwords (0 = disabled)
+	MaxAge              time.Duration `mapstructure:"max_age"`               // Force change at next login after this age (0 = never)
+	LockoutThreshold    int           `mapstructure:"lockout_threshold"`     // Failed logins before lockout (0 = disabled)
+	LockoutDuration     time.Duration `mapstructure:"lockout_duration"`      // First lockout; doubles with each further failure
+	LockoutMaxDuration  time.Duration `mapstructure:"lockout_max_duration"`
+}
+
 // IngestFwdConfig holds ingest forwarding configuration
 type IngestFwdConfig struct {
 	URL      string `mapstructure:"url"`
@@ -67,6 +84,12 @@ type IngestConfig struct {
 	Ingestion    IngestionConfig       `mapstructure:"ingestion"`
 	Ack          AckConfig             `mapstructure:"ack"`
 	DLQ          DLQConfig             `mapstructure:"dlq"`
+	OTLP         OTLPConfig            `mapstructure:"otlp"`
+	Bulk         BulkConfig            `mapstructure:"bulk"`
+	Tail         TailConfig            `mapstructure:"tail"`
+	Parsing      ParsingConfig         `mapstructure:"parsing"`
+	Mappings     MappingsConfig        `mapstructure:"mappings"`
+	Transforms   TransformsConfig      `mapstructure:"transforms"`
 }
 
 // AuthenticateURLConfig holds authenticate service URL and caching config
@@ -77,10 +100,12 @@ type AuthenticateURLConfig struct {
 
 // IngestionConfig holds ingestion pipeline configuration
 type IngestionConfig struct {
-	MaxEventSize      int           `mapstructure:"max_event_size"`
-	RateLimitEnabled  bool          `mapstructure:"rate_limit_enabled"`
-	RateLimitRequests int           `mapstructure:"rate_limit_requests"`
-	RateLimitWindow   time.Duration `mapstructure:"rate_limit_window"`
+	MaxEventSize          int           `mapstructure:"max_event_size"`
+	MaxBodySize           int64         `mapstructure:"max_body_size"`            // Decompressed HEC request size limit
+	AllowQueryStringToken bool          `mapstructure:"allow_query_string_token"` // Accept ?token= on HEC endpoints
+	RateLimitEnabled      bool          `mapstructure:"rate_limit_enabled"`
+	RateLimitRequests     int           `mapstructure:"rate_limit_requests"`
+	RateLimitWindow       time.Duration `mapstructure:"rate_limit_window"`
 }
 
 // AckConfig holds acknowledgment configuration
@@ -97,12 +122,82 @@ type DLQConfig struct {
 	NatsURL  string `mapstructure:"nats_url"`  // Only used for jetstream backend
 }
 
+// OTLPConfig holds the OTLP/HTTP logs receiver configuration
+type OTLPConfig struct {
+	Enabled           bool   `mapstructure:"enabled"`
+	RouteAttribute    string `mapstructure:"route_attribute"`    // Log attribute whose value selects the sourcetype
+	DefaultSourceType string `mapstructure:"default_sourcetype"` // Used when the route attribute is absent
+}
+
+// ParsingConfig holds per-sourcetype parsing profiles for the raw endpoint
+type ParsingConfig struct {
+	Profiles []ParsingProfileConfig `mapstructure:"profiles"`
+}
+
+// ParsingProfileConfig describes how raw payloads of one sourcetype are split
+// into events and how timestamps and fields are extracted
+type ParsingProfileConfig struct {
+	SourceType            string   `mapstructure:"sourcetype"`
+	LineBreaker           string   `mapstructure:"line_breaker"`      // Regexp; first capture group is discarded between events
+	ShouldLineMerge       bool     `mapstructure:"should_linemerge"`  // Merge lines into multi-line events
+	BreakOnlyBefore       string   `mapstructure:"break_only_before"` // Regexp starting a new event when merging
+	MaxEventLines         int      `mapstructure:"max_event_lines"`
+	TimePrefix            string   `mapstructure:"time_prefix"` // Regexp preceding the timestamp
+	TimeFormat            string   `mapstructure:"time_format"` // strptime-style, e.g. "%Y-%m-%d %H:%M:%S"
+	Timezone              string   `mapstructure:"timezone"`    // IANA zone for timestamps without an offset
+	MaxTimestampLookahead int      `mapstructure:"max_timestamp_lookahead"`
+	Extractions           []string `mapstructure:"extractions"` // Regexps with named capture groups
+	KeyValue              bool     `mapstructure:"key_value"`   // Also extract key=value pairs
+}
+
+// TransformsConfig holds the per-client transform rules applied before storage
+type TransformsConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"` // How often rules changed by other replicas are picked up
+	HashKey         string        `mapstructure:"hash_key"`         // HMAC key for hash redactions; empty uses plain SHA-256
+}
+
+// MappingsConfig holds the declarative normalizer mappings loaded at runtime
+type MappingsConfig struct {
+	Dir            string        `mapstructure:"dir"`             // Directory of *.yaml mapping files; empty disables mappings
+	ReloadInterval time.Duration `mapstructure:"reload_interval"` // How often the directory is checked for changes; 0 disables reloading
+}
+
+// BulkConfig holds the Elasticsearch-compatible bulk endpoint configuration
+type BulkConfig struct {
+	Enabled     bool             `mapstructure:"enabled"`
+	IndexRoutes []BulkIndexRoute `mapstructure:"index_routes"` // Evaluated in order; unmatched indices use the index name as sourcetype
+}
+
+// BulkIndexRoute maps bulk index names to a sourcetype
+type BulkIndexRoute struct {
+	Pattern    string `mapstructure:"pattern"` // Glob, e.g. "filebeat-*"
+	SourceType string `mapstructure:"sourcetype"`
+}
+
+// TailConfig holds live tail publishing configuration. Stored events are
+// published to NATS for the web backend's live tail endpoint.
+type TailConfig struct {
+	Enabled     bool     `mapstructure:"enabled"`
+	SampleRate  float64  `mapstructure:"sample_rate"` // Fraction of stored events published, (0, 1]
+	SourceTypes []string `mapstructure:"sourcetypes"` // Only publish these sourcetypes (empty = all)
+	Filter      string   `mapstructure:"filter"`      // JSON query filter events must match (empty = all)
+	BufferSize  int      `mapstructure:"buffer_size"` // Events queued for publishing; further events are dropped
+}
+
 // SearchConfig holds search service configuration
 type SearchConfig struct {
-	Server      ServerConfig   `mapstructure:"server"`
-	Alerting    AlertingConfig `mapstructure:"alerting"`
-	DatabaseURL string         `mapstructure:"database_url"`
-	AuthURL     string         `mapstructure:"auth_url"`
+	Server      ServerConfig     `mapstructure:"server"`
+	Alerting    AlertingConfig   `mapstructure:"alerting"`
+	Pagination  PaginationConfig `mapstructure:"pagination"`
+	DatabaseURL string           `mapstructure:"database_url"`
+	AuthURL     string           `mapstructure:"auth_url"`
+}
+
+// PaginationConfig holds cursor pagination settings for canonical queries
+type PaginationConfig struct {
+	CursorSecret string        `mapstructure:"cursor_secret"` // HMAC key signing cursors; must be shared by replicas (empty uses a random key per process)
+	KeepAlive    time.Duration `mapstructure:"keep_alive"`    // How long a point in time stays open between pages
 }
 
 // AlertingConfig holds alert scheduler and notification settings
@@ -209,19 +304,53 @@ type PostgresConfig struct {
 
 // OpenSearchConfig holds OpenSearch connection settings
 type OpenSearchConfig struct {
-	URL             string        `mapstructure:"url"`
-	Username        string        `mapstructure:"username"`
-	Password        string        `mapstructure:"password"`
-	TLSSkipVerify   bool          `mapstructure:"tls_skip_verify"`
-	Insecure        bool          `mapstructure:"insecure"`
-	IndexPrefix     string        `mapstructure:"index_prefix"`
-	Index           string        `mapstructure:"index"`
-	ShardCount      int           `mapstructure:"shard_count"`
-	ReplicaCount    int           `mapstructure:"replica_count"`
-	RefreshInterval string        `mapstructure:"refresh_interval"`
-	RetentionDays   int           `mapstructure:"retention_days"`
-	RolloverSizeGB  int           `mapstructure:"rollover_size_gb"`
-	RolloverAge     time.Duration `mapstructure:"rollover_age"`
+	URL             string          `mapstructure:"url"`
+	Username        string          `mapstructure:"username"`
+	Password        string          `mapstructure:"password"`
+	TLSSkipVerify   bool            `mapstructure:"tls_skip_verify"`
+	Insecure        bool            `mapstructure:"insecure"`
+	IndexPrefix     string          `mapstructure:"index_prefix"`
+	Index           string          `mapstructure:"index"`
+	ShardCount      int             `mapstructure:"shard_count"`
+	ReplicaCount    int             `mapstructure:"replica_count"`
+	RefreshInterval string          `mapstructure:"refresh_interval"`
+	RetentionDays   int             `mapstructure:"retention_days"`
+	RolloverSizeGB  int             `mapstructure:"rollover_size_gb"`
+	RolloverAge     time.Duration   `mapstructure:"rollover_age"`
+	Lifecycle       LifecycleConfig `mapstructure:"lifecycle"`
+	// RoutingMode is "shared" (one index series, client_id filter) or "client"
+	// (an alias and index series per client). Ingest, search and respond must agree.
+	RoutingMode string `mapstructure:"routing_mode"`
+}
+
+// LifecycleConfig holds the ISM tiers indices move through before deletion.
+// Ages are days since index creation; a tier with after_days 0 is disabled.
+type LifecycleConfig struct {
+	Warm               WarmTierConfig          `mapstructure:"warm"`
+	Cold               ColdTierConfig          `mapstructure:"cold"`
+	SnapshotRepository string                  `mapstructure:"snapshot_repository"` // Registered repository to snapshot into before delete
+	ClientRetention    []ClientRetentionConfig `mapstructure:"client_retention"`
+}
+
+// WarmTierConfig reduces replicas, force-merges and makes indices read-only
+type WarmTierConfig struct {
+	AfterDays          int `mapstructure:"after_days"`
+	Replicas           int `mapstructure:"replicas"`
+	ForceMergeSegments int `mapstructure:"force_merge_segments"`
+}
+
+// ColdTierConfig moves indices to nodes with a matching node attribute
+type ColdTierConfig struct {
+	AfterDays      int    `mapstructure:"after_days"`
+	NodeAttribute  string `mapstructure:"node_attribute"`
+	AttributeValue string `mapstructure:"attribute_value"`
+	Replicas       int    `mapstructure:"replicas"`
+}
+
+// ClientRetentionConfig overrides retention_days for one client
+type ClientRetentionConfig struct {
+	ClientID      string `mapstructure:"client_id"`
+	RetentionDays int    `mapstructure:"retention_days"`
 }
 
 // NATSConfig holds NATS message broker configuration
@@ -251,6 +380,13 @@ type LoggingConfig struct {
 	Format string `mapstructure:"format"`
 }
 
+// TracingConfig holds OpenTelemetry trace export settings
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Endpoint    string  `mapstructure:"endpoint"`     // OTLP/HTTP collector URL; empty uses OTEL_EXPORTER_OTLP_ENDPOINT or http://localhost:4318
+	SampleRatio float64 `mapstructure:"sample_ratio"` // Fraction of new traces sampled; traces started upstream follow the caller's decision
+}
+
 // CLI-specific configuration structures
 
 // CLIConfig holds CLI tool configuration (profiles, tokens, etc.)
@@ -268,6 +404,7 @@ type CLIProfile struct {
 	QueryURL     string `yaml:"query_url" mapstructure:"query_url"`
 	RulesURL     string `yaml:"rules_url" mapstructure:"rules_url"`
 	AlertingURL  string `yaml:"alerting_url" mapstructure:"alerting_url"`
+	FindingsURL  string `yaml:"findings_url" mapstructure:"findings_url"`
 	AccessToken  string `yaml:"access_token" mapstructure:"access_token"`
 	RefreshToken string `yaml:"refresh_token" mapstructure:"refresh_token"`
 	HECToken     string `yaml:"hec_token" mapstructure:"hec_token"` // Optional default HEC token for ingestion
@@ -280,6 +417,7 @@ type CLIDefaults struct {
 	QueryURL    string `yaml:"query_url" mapstructure:"query_url"`
 	RulesURL    string `yaml:"rules_url" mapstructure:"rules_url"`
 	AlertingURL string `yaml:"alerting_url" mapstructure:"alerting_url"`
+	FindingsURL string `yaml:"findings_url" mapstructure:"findings_url"`
 }
 
 // MustLoad loads the configuration and panics on error.
@@ -353,6 +491,17 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("authenticate.auth.audit_secret", "change-this-in-production")
 	v.SetDefault("authenticate.auth.access_token_ttl", "15m")
 	v.SetDefault("authenticate.auth.refresh_token_ttl", "168h")
+	v.SetDefault("authenticate.password_policy.min_length", 12)
+	v.SetDefault("authenticate.password_policy.require_uppercase", true)
+	v.SetDefault("authenticate.password_policy.require_lowercase", true)
+	v.SetDefault("authenticate.password_policy.require_digit", true)
+	v.SetDefault("authenticate.password_policy.require_symbol", false)
+	v.SetDefault("authenticate.password_policy.common_passwords_file", "")
+	v.SetDefault("authenticate.password_policy.history_count", 5)
+	v.SetDefault("authenticate.password_policy.max_age", "0s")
+	v.SetDefault("authenticate.password_policy.lockout_threshold", 5)
+	v.SetDefault("authenticate.password_policy.lockout_duration", "1m")
+	v.SetDefault("authenticate.password_policy.lockout_max_duration", "1h")
 	v.SetDefault("authenticate.ingest.enabled", false)
 	v.SetDefault("authenticate.ingest.url", "http://ingest:8088")
 	v.SetDefault("authenticate.database.type", "postgres")
@@ -364,6 +513,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("ingest.authenticate.url", "http://authenticate:8080")
 	v.SetDefault("ingest.authenticate.token_validation_cache_ttl", "5m")
 	v.SetDefault("ingest.ingestion.max_event_size", 1048576)
+	v.SetDefault("ingest.ingestion.max_body_size", 104857600)
+	v.SetDefault("ingest.ingestion.allow_query_string_token", false)
 	v.SetDefault("ingest.ingestion.rate_limit_enabled", true)
 	v.SetDefault("ingest.ingestion.rate_limit_requests", 10000)
 	v.SetDefault("ingest.ingestion.rate_limit_window", "1m")
@@ -373,6 +524,18 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("ingest.dlq.backend", "jetstream")
 	v.SetDefault("ingest.dlq.base_path", "/var/lib/telhawk/dlq")
 	v.SetDefault("ingest.dlq.nats_url", "nats://nats:4222")
+	v.SetDefault("ingest.otlp.enabled", true)
+	v.SetDefault("ingest.otlp.route_attribute", "event.domain")
+	v.SetDefault("ingest.otlp.default_sourcetype", "otlp")
+	v.SetDefault("ingest.bulk.enabled", true)
+	v.SetDefault("ingest.tail.enabled", true)
+	v.SetDefault("ingest.tail.sample_rate", 1.0)
+	v.SetDefault("ingest.tail.buffer_size", 10000)
+	v.SetDefault("ingest.mappings.dir", "")
+	v.SetDefault("ingest.mappings.reload_interval", "30s")
+	v.SetDefault("ingest.transforms.enabled", true)
+	v.SetDefault("ingest.transforms.refresh_interval", "30s")
+	v.SetDefault("ingest.transforms.hash_key", "")
 
 	// Search service defaults
 	v.SetDefault("search.alerting.enabled", false)
@@ -380,6 +543,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("search.alerting.webhook_url", "")
 	v.SetDefault("search.alerting.slack_webhook_url", "")
 	v.SetDefault("search.alerting.notification_timeout_seconds", 10)
+	v.SetDefault("search.pagination.cursor_secret", "")
+	v.SetDefault("search.pagination.keep_alive", "1m")
 	v.SetDefault("search.database_url", "")
 	v.SetDefault("search.auth_url", "http://authenticate:8080")
 
@@ -425,6 +590,15 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("opensearch.retention_days", 30)
 	v.SetDefault("opensearch.rollover_size_gb", 50)
 	v.SetDefault("opensearch.rollover_age", "24h")
+	v.SetDefault("opensearch.routing_mode", "shared")
+	v.SetDefault("opensearch.lifecycle.warm.after_days", 0)
+	v.SetDefault("opensearch.lifecycle.warm.replicas", 0)
+	v.SetDefault("opensearch.lifecycle.warm.force_merge_segments", 1)
+	v.SetDefault("opensearch.lifecycle.cold.after_days", 0)
+	v.SetDefault("opensearch.lifecycle.cold.node_attribute", "temp")
+	v.SetDefault("opensearch.lifecycle.cold.attribute_value", "cold")
+	v.SetDefault("opensearch.lifecycle.cold.replicas", 0)
+	v.SetDefault("opensearch.lifecycle.snapshot_repository", "")
 
 	// NATS defaults
 	v.SetDefault("nats.url", "nats://nats:4222")
@@ -441,6 +615,11 @@ func setDefaults(v *viper.Viper) {
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.format", "json")
+
+	// Tracing defaults
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.endpoint", "")
+	v.SetDefault("tracing.sample_ratio", 1.0)
 }
 
 // CLI-specific helper methods
@@ -457,6 +636,7 @@ func DefaultCLI() *CLIConfig {
 			QueryURL:    "http://localhost:3000",
 			RulesURL:    "http://localhost:3000",
 			AlertingURL: "http://localhost:3000",
+			FindingsURL: "http://localhost:8080", // Findings is a standalone service
 		},
 	}
 }
@@ -602,3 +782,13 @@ func (c *CLIConfig) GetAlertingURL(profile string) string {
 	}
 	return c.Defaults.AlertingURL
 }
+
+// GetFindingsURL returns the findings service URL from profile or defaults
+func (c *CLIConfig) GetFindingsURL(profile string) string {
+	if profile != "" {
+		if p, err := c.GetProfile(profile); err == nil && p.FindingsURL != "" {
+			return p.FindingsURL
+		}
+	}
+	return c.Defaults.FindingsURL
+}
@@ -15,6 +15,10 @@ var ValidFields = map[string]FieldInfo{
 	".time":       {Type: "date", Description: "Event timestamp"},
 	".@timestamp": {Type: "date", Description: "Event timestamp (alternative)"},
 
+	// Ingestion context
+	".client_id":    {Type: "keyword", Description: "Client the event belongs to (data isolation)"},
+	".hec_token_id": {Type: "keyword", Description: "HEC token that ingested the event"},
+
 	// Event classification
 	".class_uid":     {Type: "integer", Description: "OCSF event class identifier"},
 	".class":         {Type: "keyword", Description: "OCSF event class name"},
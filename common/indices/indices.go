@@ -0,0 +1,90 @@
+// Package indices resolves which OpenSearch indices hold a client's data.
+// Ingest writes with these names and search and respond read with them, so
+// both sides must agree on the routing mode.
+package indices
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Routing modes for event indices.
+const (
+	// RoutingShared writes every client's events to one index series and
+	// relies on a client_id filter for isolation.
+	RoutingShared = "shared"
+	// RoutingClient writes each client's events to its own alias and index
+	// series, <prefix>-<client>.-*.
+	RoutingClient = "client"
+)
+
+// clientIDPattern matches client IDs usable as an index name segment as-is,
+// e.g. UUIDs. Other IDs are rejected rather than rewritten, so two clients
+// never share a series.
+var clientIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+var invalidIndexChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// routeSegment starts a routed series name under a base prefix. Client IDs
+// cannot start with "_", so a route never names a client's series.
+const routeSegment = "_"
+
+// segmentEnd ends the client or route segment of a series prefix. Neither
+// segment may contain it, so the <prefix>-* pattern of one series never
+// matches another whose segment merely starts the same way ("acme" and
+// "acme-eu"): their index templates, ISM policies and search patterns stay
+// disjoint.
+const segmentEnd = "."
+
+// ValidateRoutingMode returns an error for an unknown routing mode. An empty
+// mode means RoutingShared.
+func ValidateRoutingMode(mode string) error {
+	switch mode {
+	case "", RoutingShared, RoutingClient:
+		return nil
+	}
+	return fmt.Errorf("unknown index routing mode %q (want %q or %q)", mode, RoutingShared, RoutingClient)
+}
+
+// ValidateClientID returns an error if a client ID cannot name its own index
+// series: OpenSearch index names are lowercase without most punctuation.
+func ValidateClientID(clientID string) error {
+	if !clientIDPattern.MatchString(clientID) {
+		return fmt.Errorf("client ID %q cannot name an index series (want up to 64 lowercase letters, digits, '-' or '_', not starting with '-' or '_')", clientID)
+	}
+	return nil
+}
+
+// ClientPrefix returns the index prefix for a client's own index series,
+// <prefix>-<client>.
+func ClientPrefix(prefix, clientID string) (string, error) {
+	if err := ValidateClientID(clientID); err != nil {
+		return "", err
+	}
+	return prefix + "-" + clientID + segmentEnd, nil
+}
+
+// RoutePrefix returns the prefix of a routed index series under base, the
+// shared prefix or a client's prefix. Routed series stay under base so that
+// SearchPattern covers them, in a segment no client ID can take.
+func RoutePrefix(base, route string) string {
+	return base + "-" + routeSegment + invalidIndexChars.ReplaceAllString(strings.ToLower(route), "_") + segmentEnd
+}
+
+// SearchPattern returns the index pattern a caller may search. Callers
+// without a client (platform users) search every index under prefix. With
+// client routing a client searches only its own series, so events stored
+// in the shared series, such as those written before switching to client
+// routing, are not visible to it. Callers must still filter on client_id,
+// since in shared mode every client's events are in one series.
+func SearchPattern(prefix, mode, clientID string) (string, error) {
+	if mode == RoutingClient && clientID != "" {
+		clientPrefix, err := ClientPrefix(prefix, clientID)
+		if err != nil {
+			return "", err
+		}
+		return clientPrefix + "-*", nil
+	}
+	return prefix + "*", nil
+}
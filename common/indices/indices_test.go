@@ -0,0 +1,128 @@
+package indices
+
+import (
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestClientPrefix(t *testing.T) {
+	tests := []struct {
+		clientID string
+		want     string
+	}{
+		{"acme", "telhawk-events-acme."},
+		{"acme_corp", "telhawk-events-acme_corp."},
+		{"6f1c2a7e-0b1d-4e0b-9d52-8a2c1e5f9a10", "telhawk-events-6f1c2a7e-0b1d-4e0b-9d52-8a2c1e5f9a10."},
+	}
+	for _, tt := range tests {
+		got, err := ClientPrefix("telhawk-events", tt.clientID)
+		if err != nil {
+			t.Errorf("ClientPrefix(%q) error = %v", tt.clientID, err)
+		} else if got != tt.want {
+			t.Errorf("ClientPrefix(%q) = %q, want %q", tt.clientID, got, tt.want)
+		}
+	}
+}
+
+func TestClientPrefix_RejectsIDsThatWouldCollide(t *testing.T) {
+	// Each would otherwise have to be rewritten, e.g. "ACME" and "acme"
+	// to the same series
+	for _, clientID := range []string{"", "ACME", "acme corp", "acme/*", "_audit", "-acme", strings.Repeat("a", 65)} {
+		if _, err := ClientPrefix("telhawk-events", clientID); err == nil {
+			t.Errorf("ClientPrefix(%q) expected an error", clientID)
+		}
+	}
+}
+
+func TestRoutePrefix(t *testing.T) {
+	if got := RoutePrefix("telhawk-events-acme.", "Audit"); got != "telhawk-events-acme.-_audit." {
+		t.Errorf("RoutePrefix() = %q, want %q", got, "telhawk-events-acme.-_audit.")
+	}
+
+	// A route never takes the name of a client's series
+	route := RoutePrefix("telhawk-events", "acme")
+	client, _ := ClientPrefix("telhawk-events", "acme")
+	if route == client {
+		t.Errorf("RoutePrefix() = ClientPrefix() = %q", route)
+	}
+}
+
+func TestSearchPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		clientID string
+		want     string
+	}{
+		{"shared client", RoutingShared, "acme", "telhawk-events*"},
+		{"default mode", "", "acme", "telhawk-events*"},
+		{"routed client", RoutingClient, "acme", "telhawk-events-acme.-*"},
+		{"routed platform user", RoutingClient, "", "telhawk-events*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SearchPattern("telhawk-events", tt.mode, tt.clientID)
+			if err != nil {
+				t.Fatalf("SearchPattern() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SearchPattern() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSeriesPatternsAreDisjoint checks that no series pattern covers the
+// indices of another series whose client ID or route starts the same way.
+// Routed series nest under their base on purpose and are skipped.
+func TestSeriesPatternsAreDisjoint(t *testing.T) {
+	var prefixes []string
+	for _, clientID := range []string{"acme", "acme-eu", "acme_eu"} {
+		prefix, err := ClientPrefix("telhawk-events", clientID)
+		if err != nil {
+			t.Fatalf("ClientPrefix(%q) error = %v", clientID, err)
+		}
+		prefixes = append(prefixes, prefix)
+		for _, route := range []string{"audit", "audit-eu"} {
+			prefixes = append(prefixes, RoutePrefix(prefix, route))
+		}
+	}
+	for _, route := range []string{"audit", "audit-eu"} {
+		prefixes = append(prefixes, RoutePrefix("telhawk-events", route))
+	}
+
+	for _, a := range prefixes {
+		for _, b := range prefixes {
+			if a == b || strings.HasPrefix(b, a+"-") {
+				continue
+			}
+			for _, index := range []string{b + "-write", b + "-2026.01.02-000001"} {
+				if matched, _ := path.Match(a+"-*", index); matched {
+					t.Errorf("pattern %q of one series matches %q of another", a+"-*", index)
+				}
+			}
+		}
+	}
+}
+
+func TestSearchPattern_InvalidClientID(t *testing.T) {
+	if _, err := SearchPattern("telhawk-events", RoutingClient, "ACME"); err == nil {
+		t.Error("Expected an error for a client ID that cannot name a series")
+	}
+	// Shared mode does not derive index names from the client ID
+	if _, err := SearchPattern("telhawk-events", RoutingShared, "ACME"); err != nil {
+		t.Errorf("SearchPattern() error = %v", err)
+	}
+}
+
+func TestValidateRoutingMode(t *testing.T) {
+	for _, mode := range []string{"", RoutingShared, RoutingClient} {
+		if err := ValidateRoutingMode(mode); err != nil {
+			t.Errorf("ValidateRoutingMode(%q) = %v", mode, err)
+		}
+	}
+	if err := ValidateRoutingMode("per-tenant"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
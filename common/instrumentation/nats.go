@@ -0,0 +1,137 @@
+package instrumentation
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// NATS messaging metrics
+	NATSMessagesPublished = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telhawk_nats_messages_published_total",
+			Help: "Total number of NATS messages published",
+		},
+		[]string{"subject", "status"},
+	)
+
+	NATSMessagesConsumed = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telhawk_nats_messages_consumed_total",
+			Help: "Total number of NATS messages handled by subscribers",
+		},
+		[]string{"subject", "status"},
+	)
+
+	NATSHandlerDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "telhawk_nats_handler_duration_seconds",
+			Help:    "NATS message handler duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"subject"},
+	)
+)
+
+// StartPublish starts a producer span for a message published on subject and
+// injects its W3C trace context into header. The returned function ends the
+// span and records the outcome of the publish.
+func StartPublish(ctx context.Context, subject string, header map[string][]string) (context.Context, func(error)) {
+	ctx, span := tracer().Start(ctx, "publish "+subjectLabel(subject), trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "nats"),
+			attribute.String("messaging.destination.name", subject),
+		))
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(header))
+
+	return ctx, func(err error) {
+		NATSMessagesPublished.WithLabelValues(subjectLabel(subject), statusLabel(err)).Inc()
+		endSpan(span, err)
+	}
+}
+
+// StartConsume starts a consumer span for a message received on subject,
+// continuing the trace context carried in header. The returned function ends
+// the span and records the outcome and duration of the handler.
+func StartConsume(ctx context.Context, subject string, header map[string][]string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier(header))
+	ctx, span := tracer().Start(ctx, "process "+subjectLabel(subject), trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "nats"),
+			attribute.String("messaging.destination.name", subject),
+		))
+
+	return ctx, func(err error) {
+		label := subjectLabel(subject)
+		NATSMessagesConsumed.WithLabelValues(label, statusLabel(err)).Inc()
+		NATSHandlerDuration.WithLabelValues(label).Observe(time.Since(start).Seconds())
+		endSpan(span, err)
+	}
+}
+
+// subjectLabel truncates subject to its {domain}.{action}.{resource} tokens,
+// dropping per-job and per-client suffixes such as search.results.query.<id>.
+// Request-reply inboxes are random per request and collapse to one label.
+func subjectLabel(subject string) string {
+	if strings.HasPrefix(subject, "_INBOX.") {
+		return "_INBOX"
+	}
+	tokens := strings.SplitN(subject, ".", 4)
+	if len(tokens) > 3 {
+		tokens = tokens[:3]
+	}
+	return strings.Join(tokens, ".")
+}
+
+func statusLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// headerCarrier adapts NATS message headers to a TextMapCarrier. NATS header
+// keys are case-sensitive, so keys are written as given (lowercase W3C names)
+// and read case-insensitively to accept headers set by other clients.
+type headerCarrier map[string][]string
+
+func (h headerCarrier) Get(key string) string {
+	if v := h[key]; len(v) > 0 {
+		return v[0]
+	}
+	for k, v := range h {
+		if len(v) > 0 && strings.EqualFold(k, key) {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h[key] = []string{value}
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,54 @@
+package instrumentation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestStartPublish_PropagatesToConsumer(t *testing.T) {
+	rec := recordSpans(t)
+
+	ctx, parent := StartSpan(context.Background(), "submit")
+	header := map[string][]string{}
+	_, done := StartPublish(ctx, "search.jobs.correlate", header)
+	done(nil)
+	parent.End()
+	require.Contains(t, header, "traceparent")
+
+	var consumed trace.SpanContext
+	ctx, done = StartConsume(context.Background(), "search.jobs.correlate", header)
+	consumed = trace.SpanContextFromContext(ctx)
+	done(errors.New("boom"))
+
+	assert.Equal(t, parent.SpanContext().TraceID(), consumed.TraceID())
+	spans := rec.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "publish search.jobs.correlate", spans[0].Name())
+	assert.Equal(t, "process search.jobs.correlate", spans[2].Name())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), spans[2].Parent().SpanID())
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(NATSMessagesPublished.WithLabelValues("search.jobs.correlate", "ok")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(NATSMessagesConsumed.WithLabelValues("search.jobs.correlate", "error")))
+}
+
+func TestStartConsume_AcceptsCanonicalHeaderKeys(t *testing.T) {
+	recordSpans(t)
+
+	header := map[string][]string{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}
+	ctx, done := StartConsume(context.Background(), "respond.alerts.created", header)
+	defer done(nil)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.SpanContextFromContext(ctx).TraceID().String())
+}
+
+func TestSubjectLabel(t *testing.T) {
+	assert.Equal(t, "search.results.query", subjectLabel("search.results.query.3f2a"))
+	assert.Equal(t, "ingest.events.tail", subjectLabel("ingest.events.tail.client.x"))
+	assert.Equal(t, "respond.alerts.created", subjectLabel("respond.alerts.created"))
+	assert.Equal(t, "_INBOX", subjectLabel("_INBOX.Kp3xQ9.17"))
+}
@@ -0,0 +1,67 @@
+// Package instrumentation provides the Prometheus metrics and OpenTelemetry
+// tracing shared by TelHawk services: HTTP middleware recording RED metrics
+// per route, W3C trace context propagation over HTTP and NATS, and OTLP trace
+// export.
+package instrumentation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/telhawk-systems/telhawk-stack/common/config"
+)
+
+// tracerName is the instrumentation scope of spans started by this package.
+const tracerName = "github.com/telhawk-systems/telhawk-stack/common/instrumentation"
+
+// Setup installs the W3C trace context propagator and, when tracing is
+// enabled, a tracer provider exporting spans of service over OTLP/HTTP.
+// The returned function flushes pending spans and must be called on shutdown.
+func Setup(ctx context.Context, service string, cfg config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(strings.TrimSuffix(cfg.Endpoint, "/")+"/v1/traces"))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP trace exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", service))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// StartSpan starts an internal span named name as a child of the span in ctx.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// tracer returns the tracer of the current global provider, so spans follow
+// a provider installed after package initialization.
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
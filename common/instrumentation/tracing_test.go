@@ -0,0 +1,110 @@
+package instrumentation
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/telhawk-systems/telhawk-stack/common/config"
+)
+
+// collector is an OTLP/HTTP trace receiver standing in for an OpenTelemetry
+// collector.
+type collector struct {
+	mu       sync.Mutex
+	services []string
+	spans    []*tracepb.Span
+}
+
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	t.Helper()
+	c := &collector{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var req coltracepb.ExportTraceServiceRequest
+		require.NoError(t, proto.Unmarshal(body, &req))
+
+		c.mu.Lock()
+		for _, rs := range req.ResourceSpans {
+			for _, attr := range rs.GetResource().GetAttributes() {
+				if attr.Key == "service.name" {
+					c.services = append(c.services, attr.GetValue().GetStringValue())
+				}
+			}
+			for _, ss := range rs.ScopeSpans {
+				c.spans = append(c.spans, ss.Spans...)
+			}
+		}
+		c.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		out, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+		_, _ = w.Write(out)
+	}))
+	t.Cleanup(srv.Close)
+	return c, srv
+}
+
+// setupTracing installs a tracer provider exporting to endpoint and restores
+// the previous global provider when the test ends.
+func setupTracing(t *testing.T, endpoint string) func(context.Context) error {
+	t.Helper()
+	prev := otel.GetTracerProvider()
+	shutdown, err := Setup(context.Background(), "test-service", config.TracingConfig{
+		Enabled:     true,
+		Endpoint:    endpoint,
+		SampleRatio: 1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return shutdown
+}
+
+func TestSetup_ExportsSpansOverOTLP(t *testing.T) {
+	c, srv := newCollector(t)
+	shutdown := setupTracing(t, srv.URL+"/")
+
+	ctx, parent := StartSpan(context.Background(), "correlate")
+	_, child := StartSpan(ctx, "query")
+	child.End()
+	parent.End()
+
+	// Shutdown flushes the batch span processor
+	require.NoError(t, shutdown(context.Background()))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	assert.Contains(t, c.services, "test-service")
+	require.Len(t, c.spans, 2)
+	byName := map[string]*tracepb.Span{}
+	for _, s := range c.spans {
+		byName[s.Name] = s
+	}
+	require.Contains(t, byName, "correlate")
+	require.Contains(t, byName, "query")
+	assert.Equal(t, byName["correlate"].TraceId, byName["query"].TraceId)
+	assert.Equal(t, byName["correlate"].SpanId, byName["query"].ParentSpanId)
+}
+
+func TestSetup_Disabled(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	shutdown, err := Setup(context.Background(), "test-service", config.TracingConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, prev, otel.GetTracerProvider(), "disabled tracing must not install a provider")
+	assert.NoError(t, shutdown(context.Background()))
+}
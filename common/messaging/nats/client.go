@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/telhawk-systems/telhawk-stack/common/instrumentation"
 	"github.com/telhawk-systems/telhawk-stack/common/messaging"
 )
 
@@ -96,10 +97,7 @@ func NewClient(cfg Config) (*Client, error) {
 
 // Publish sends a message to the specified subject.
 func (c *Client) Publish(ctx context.Context, subject string, data []byte) error {
-	if err := ctx.Err(); err != nil {
-		return err
-	}
-	return c.conn.Publish(subject, data)
+	return c.PublishMsg(ctx, &messaging.Message{Subject: subject, Data: data})
 }
 
 // PublishJSON marshals data to JSON and publishes to the subject.
@@ -122,17 +120,17 @@ func (c *Client) PublishMsg(ctx context.Context, msg *messaging.Message) error {
 		Subject: msg.Subject,
 		Data:    msg.Data,
 		Reply:   msg.Reply,
+		Header:  make(nats.Header),
 	}
-
-	// Add headers if present
-	if len(msg.Metadata) > 0 {
-		natsMsg.Header = make(nats.Header)
-		for k, v := range msg.Metadata {
-			natsMsg.Header.Set(k, v)
-		}
+	for k, v := range msg.Metadata {
+		natsMsg.Header.Set(k, v)
 	}
 
-	return c.conn.PublishMsg(natsMsg)
+	// Carry the trace context so subscribers continue the publisher's trace
+	_, done := instrumentation.StartPublish(ctx, msg.Subject, natsMsg.Header)
+	err := c.conn.PublishMsg(natsMsg)
+	done(err)
+	return err
 }
 
 // Request sends a message and waits for a response.
@@ -141,7 +139,10 @@ func (c *Client) Request(ctx context.Context, subject string, data []byte, timeo
 		return nil, err
 	}
 
-	resp, err := c.conn.Request(subject, data, timeout)
+	req := &nats.Msg{Subject: subject, Data: data, Header: make(nats.Header)}
+	_, done := instrumentation.StartPublish(ctx, subject, req.Header)
+	resp, err := c.conn.RequestMsg(req, timeout)
+	done(err)
 	if err != nil {
 		return nil, err
 	}
@@ -152,9 +153,10 @@ func (c *Client) Request(ctx context.Context, subject string, data []byte, timeo
 // Subscribe creates a subscription to the specified subject.
 func (c *Client) Subscribe(subject string, handler messaging.MessageHandler) (messaging.Subscription, error) {
 	sub, err := c.conn.Subscribe(subject, func(msg *nats.Msg) {
-		ctx := context.Background()
-		if err := handler(ctx, natsToMessage(msg)); err != nil {
-			// Handler error - could add metrics/logging hook here
+		ctx, done := instrumentation.StartConsume(context.Background(), msg.Subject, msg.Header)
+		err := handler(ctx, natsToMessage(msg))
+		done(err)
+		if err != nil {
 			fmt.Printf("Handler error for %s: %v\n", subject, err)
 		}
 	})
@@ -173,8 +175,10 @@ func (c *Client) Subscribe(subject string, handler messaging.MessageHandler) (me
 // QueueSubscribe creates a queue subscription for load-balanced message processing.
 func (c *Client) QueueSubscribe(subject, queue string, handler messaging.MessageHandler) (messaging.Subscription, error) {
 	sub, err := c.conn.QueueSubscribe(subject, queue, func(msg *nats.Msg) {
-		ctx := context.Background()
-		if err := handler(ctx, natsToMessage(msg)); err != nil {
+		ctx, done := instrumentation.StartConsume(context.Background(), msg.Subject, msg.Header)
+		err := handler(ctx, natsToMessage(msg))
+		done(err)
+		if err != nil {
 			fmt.Printf("Handler error for %s (queue: %s): %v\n", subject, queue, err)
 		}
 	})
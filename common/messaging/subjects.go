@@ -1,12 +1,19 @@
 // Package messaging defines standard subject names for TelHawk message bus.
 package messaging
 
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
 // Subject constants for TelHawk message bus.
 // Follow the pattern: {domain}.{action}.{resource}
 const (
 	// Search job subjects - requests for query/correlation execution
 	SubjectSearchJobsQuery     = "search.jobs.query"     // Ad-hoc search requests
 	SubjectSearchJobsCorrelate = "search.jobs.correlate" // Correlation evaluation requests
+	SubjectSearchJobsCancel    = "search.jobs.cancel"    // Cancel a running search (fan-out to all workers)
 
 	// Search result subjects - responses from search service
 	SubjectSearchResultsQuery     = "search.results.query"     // Ad-hoc query results (append .{id} for specific query)
@@ -20,8 +27,14 @@ const (
 	SubjectRespondCasesCreated  = "respond.cases.created"  // New case opened
 	SubjectRespondCasesUpdated  = "respond.cases.updated"  // Case status changed
 	SubjectRespondCasesAssigned = "respond.cases.assigned" // Case assigned to analyst
+
+	// Live tail subjects - stored events published by ingest
+	SubjectIngestEventsTail = "ingest.events.tail" // Tail batches (append .{client_id}; subscribe to .> for all tenants)
 )
 
+// unscopedTailToken is the subject token for events without a client ID.
+const unscopedTailToken = "_unscoped"
+
 // Queue group names for load-balanced consumers.
 // Workers in the same queue group share messages (each message processed once).
 const (
@@ -35,3 +48,19 @@ const (
 func SearchQueryResultSubject(queryID string) string {
 	return SubjectSearchResultsQuery + "." + queryID
 }
+
+// IngestTailSubject returns the live tail subject for a client's events.
+// Client IDs with characters that have special meaning in subjects are
+// rejected rather than rewritten, so two clients never share a subject.
+// Example: ingest.events.tail.abc123
+func IngestTailSubject(clientID string) (string, error) {
+	if clientID == "" {
+		return SubjectIngestEventsTail + "." + unscopedTailToken, nil
+	}
+	if clientID == unscopedTailToken || strings.IndexFunc(clientID, func(r rune) bool {
+		return r == '.' || r == '*' || r == '>' || unicode.IsSpace(r)
+	}) >= 0 {
+		return "", fmt.Errorf("client ID %q cannot name a live tail subject", clientID)
+	}
+	return SubjectIngestEventsTail + "." + clientID, nil
+}
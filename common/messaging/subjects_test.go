@@ -10,6 +10,7 @@ func TestSubjectConstants_Defined(t *testing.T) {
 	subjects := map[string]string{
 		"SubjectSearchJobsQuery":        SubjectSearchJobsQuery,
 		"SubjectSearchJobsCorrelate":    SubjectSearchJobsCorrelate,
+		"SubjectSearchJobsCancel":       SubjectSearchJobsCancel,
 		"SubjectSearchResultsQuery":     SubjectSearchResultsQuery,
 		"SubjectSearchResultsCorrelate": SubjectSearchResultsCorrelate,
 		"SubjectRespondAlertsCreated":   SubjectRespondAlertsCreated,
@@ -17,6 +18,7 @@ func TestSubjectConstants_Defined(t *testing.T) {
 		"SubjectRespondCasesCreated":    SubjectRespondCasesCreated,
 		"SubjectRespondCasesUpdated":    SubjectRespondCasesUpdated,
 		"SubjectRespondCasesAssigned":   SubjectRespondCasesAssigned,
+		"SubjectIngestEventsTail":       SubjectIngestEventsTail,
 	}
 
 	for name, value := range subjects {
@@ -31,6 +33,7 @@ func TestSubjectConstants_FollowNamingConvention(t *testing.T) {
 	subjects := []string{
 		SubjectSearchJobsQuery,
 		SubjectSearchJobsCorrelate,
+		SubjectSearchJobsCancel,
 		SubjectSearchResultsQuery,
 		SubjectSearchResultsCorrelate,
 		SubjectRespondAlertsCreated,
@@ -38,6 +41,7 @@ func TestSubjectConstants_FollowNamingConvention(t *testing.T) {
 		SubjectRespondCasesCreated,
 		SubjectRespondCasesUpdated,
 		SubjectRespondCasesAssigned,
+		SubjectIngestEventsTail,
 	}
 
 	for _, subject := range subjects {
@@ -163,3 +167,44 @@ func TestSearchQueryResultSubject_ContainsQueryID(t *testing.T) {
 		t.Errorf("SearchQueryResultSubject result %q should contain query ID %q", result, queryID)
 	}
 }
+
+func TestIngestTailSubject(t *testing.T) {
+	tests := []struct {
+		name     string
+		clientID string
+		expected string
+	}{
+		{
+			name:     "UUID client ID",
+			clientID: "550e8400-e29b-41d4-a716-446655440000",
+			expected: "ingest.events.tail.550e8400-e29b-41d4-a716-446655440000",
+		},
+		{
+			name:     "empty client ID",
+			clientID: "",
+			expected: "ingest.events.tail._unscoped",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := IngestTailSubject(tt.clientID)
+			if err != nil {
+				t.Fatalf("IngestTailSubject(%q) error = %v", tt.clientID, err)
+			}
+			if result != tt.expected {
+				t.Errorf("IngestTailSubject(%q) = %q, want %q", tt.clientID, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIngestTailSubject_RejectsUnsafeClientIDs(t *testing.T) {
+	// Each of these would otherwise name another client's subject, several
+	// subjects, or the unscoped events.
+	for _, clientID := range []string{"acme.eu", "acme*", "acme>", "acme eu", "acme\tb", "_unscoped"} {
+		if subject, err := IngestTailSubject(clientID); err == nil {
+			t.Errorf("IngestTailSubject(%q) = %q, want an error", clientID, subject)
+		}
+	}
+}
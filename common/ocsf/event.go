@@ -1,6 +1,8 @@
 package ocsf
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/telhawk-systems/telhawk-stack/common/ocsf/objects"
@@ -56,6 +58,43 @@ type Event struct {
 
 	// Risk score (hoisted from finding events):
 	RiskScore int `json:"risk_score,omitempty"` // 0-100 risk score
+
+	// ClassAttributes holds class-specific attributes that Event has no
+	// field for, such as traffic, connection_info, query or finding_info.
+	// Normalizers that build class events set them here so they survive the
+	// pipeline; Document stores them at the top level of the event.
+	ClassAttributes map[string]interface{} `json:"-"`
+}
+
+// Document returns the event as a storage document: its JSON fields plus
+// its ClassAttributes, which never replace a base field.
+func (e *Event) Document() (map[string]interface{}, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(e.ClassAttributes) == 0 {
+		return doc, nil
+	}
+
+	data, err = json.Marshal(e.ClassAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("class attributes: %w", err)
+	}
+	var attrs map[string]interface{}
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		return nil, fmt.Errorf("class attributes: %w", err)
+	}
+	for key, value := range attrs {
+		if _, ok := doc[key]; !ok && value != nil {
+			doc[key] = value
+		}
+	}
+	return doc, nil
 }
 
 // Metadata describes the event producer and OCSF schema information.
@@ -106,6 +145,12 @@ func (e *Event) Clone() *Event {
 			dup.Properties[k] = v
 		}
 	}
+	if e.ClassAttributes != nil {
+		dup.ClassAttributes = make(map[string]interface{}, len(e.ClassAttributes))
+		for k, v := range e.ClassAttributes {
+			dup.ClassAttributes[k] = v
+		}
+	}
 	// Note: Actor is a pointer to objects.Actor, shallow copy is sufficient
 	// for most use cases. For deep copy, caller should clone the Actor separately.
 
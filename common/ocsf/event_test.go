@@ -0,0 +1,40 @@
+package ocsf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+)
+
+// TestEventDocument checks class attributes are stored at the top level
+// without replacing base fields
+func TestEventDocument(t *testing.T) {
+	event := &ocsf.Event{
+		ClassUID: ocsf.ClassNetworkActivity,
+		Class:    "network_activity",
+		Time:     time.Now(),
+		ClassAttributes: map[string]interface{}{
+			"traffic": map[string]int{"bytes_in": 10},
+			"class":   "overridden",
+			"url":     nil,
+		},
+	}
+
+	doc, err := event.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	if traffic, ok := doc["traffic"].(map[string]interface{}); !ok || traffic["bytes_in"] != float64(10) {
+		t.Errorf("expected traffic.bytes_in 10, got %v", doc["traffic"])
+	}
+	if doc["class"] != "network_activity" {
+		t.Errorf("class attribute replaced a base field: %v", doc["class"])
+	}
+	if _, ok := doc["url"]; ok {
+		t.Errorf("nil class attribute was stored")
+	}
+	if _, ok := doc["ClassAttributes"]; ok {
+		t.Errorf("ClassAttributes field was marshaled")
+	}
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	pickle "github.com/telhawk-systems/telhawk-stack/findings/app/http"
 	"github.com/telhawk-systems/telhawk-stack/findings/app/models"
+	"github.com/telhawk-systems/telhawk-stack/findings/app/services"
 
 	"github.com/google/uuid"
 )
@@ -11,49 +12,51 @@ type FindingController struct {
 	pickle.Controller
 }
 
+// FindingWithHistory is a finding together with its lifecycle events
+// (detected, seen, resolved, reintroduced) in the order they were recorded.
+type FindingWithHistory struct {
+	*models.Finding
+	History []models.FindingEvent `json:"history"`
+}
+
 func (c FindingController) Index(ctx *pickle.Context) pickle.Response {
 	authID, err := uuid.Parse(ctx.Auth().UserID)
 	if err != nil {
 		return ctx.Unauthorized("invalid auth")
 	}
 
-	// Get scan IDs owned by this user
-	scans, err := models.QueryScan().WhereUserID(authID).All()
+	filter, err := services.ParseFindingFilter(ctx.Query)
 	if err != nil {
-		return ctx.Error(err)
-	}
-	if len(scans) == 0 {
-		return ctx.JSON(200, []models.Finding{})
+		return ctx.JSON(422, map[string]string{"error": err.Error()})
 	}
 
-	scanIDs := make([]uuid.UUID, len(scans))
-	for i, s := range scans {
-		scanIDs[i] = s.ID
+	page, err := services.ListFindings(authID, filter)
+	if err != nil {
+		return ctx.Error(err)
 	}
 
-	q := models.QueryFinding().
-		WhereScanIDIn(scanIDs).
-		Limit(100)
+	return ctx.JSON(200, page)
+}
 
-	if tool := ctx.Query("tool"); tool != "" {
-		q = q.WhereTool(tool)
-	}
-	if severity := ctx.Query("severity"); severity != "" {
-		q = q.WhereSeverity(&severity)
-	}
-	if signalType := ctx.Query("signal_type"); signalType != "" {
-		q = q.WhereSignalType(signalType)
+// Summary returns per-project counts of open findings by severity, category
+// and tool. It accepts the same filters as Index.
+func (c FindingController) Summary(ctx *pickle.Context) pickle.Response {
+	authID, err := uuid.Parse(ctx.Auth().UserID)
+	if err != nil {
+		return ctx.Unauthorized("invalid auth")
 	}
-	if category := ctx.Query("category"); category != "" {
-		q = q.WhereCategory(&category)
+
+	filter, err := services.ParseFindingFilter(ctx.Query)
+	if err != nil {
+		return ctx.JSON(422, map[string]string{"error": err.Error()})
 	}
 
-	findings, err := q.All()
+	summaries, err := services.SummarizeFindings(authID, filter)
 	if err != nil {
 		return ctx.Error(err)
 	}
 
-	return ctx.JSON(200, findings)
+	return ctx.JSON(200, summaries)
 }
 
 func (c FindingController) Show(ctx *pickle.Context) pickle.Response {
@@ -67,19 +70,22 @@ func (c FindingController) Show(ctx *pickle.Context) pickle.Response {
 		return ctx.Unauthorized("invalid auth")
 	}
 
-	finding, err := models.QueryFinding().WhereID(id).First()
+	finding, err := models.QueryFinding().
+		WhereID(id).
+		WhereUserID(&authID).
+		First()
 	if err != nil {
 		return ctx.NotFound("finding not found")
 	}
 
-	// Verify ownership via scan
-	_, err = models.QueryScan().
-		WhereID(finding.ScanID).
-		WhereUserID(authID).
-		First()
+	history, err := models.QueryFindingEvent().
+		WhereFindingID(finding.ID).
+		OrderBy("created_at", "ASC").
+		Limit(500).
+		All()
 	if err != nil {
-		return ctx.NotFound("finding not found")
+		return ctx.Error(err)
 	}
 
-	return ctx.JSON(200, finding)
+	return ctx.JSON(200, FindingWithHistory{Finding: finding, History: history})
 }
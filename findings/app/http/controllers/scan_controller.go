@@ -1,11 +1,13 @@
 package controllers
 
 import (
-	"encoding/json"
+	"errors"
+	"net/http"
 
 	pickle "github.com/telhawk-systems/telhawk-stack/findings/app/http"
 	"github.com/telhawk-systems/telhawk-stack/findings/app/http/requests"
 	"github.com/telhawk-systems/telhawk-stack/findings/app/models"
+	"github.com/telhawk-systems/telhawk-stack/findings/app/services"
 
 	"github.com/google/uuid"
 )
@@ -20,15 +22,46 @@ func (c ScanController) Index(ctx *pickle.Context) pickle.Response {
 		return ctx.Unauthorized("invalid auth")
 	}
 
-	scans, err := models.QueryScan().
-		WhereUserID(authID).
-		Limit(100).
-		All()
+	cursor, err := services.ParseCursor(ctx.Query("cursor"))
+	if err != nil {
+		return ctx.JSON(422, map[string]string{"error": err.Error()})
+	}
+	limit, err := services.ParseLimit(ctx.Query("limit"))
+	if err != nil {
+		return ctx.JSON(422, map[string]string{"error": err.Error()})
+	}
+
+	page, err := services.ListScans(authID, ctx.Query("project"), ctx.Query("tool"), cursor, limit)
+	if err != nil {
+		return ctx.Error(err)
+	}
+
+	return ctx.JSON(200, page)
+}
+
+// Trend returns the open/new/reintroduced/resolved counts after each of a
+// project's most recent scans, oldest first.
+func (c ScanController) Trend(ctx *pickle.Context) pickle.Response {
+	authID, err := uuid.Parse(ctx.Auth().UserID)
+	if err != nil {
+		return ctx.Unauthorized("invalid auth")
+	}
+
+	project := ctx.Query("project")
+	if project == "" {
+		return ctx.JSON(422, map[string]string{"error": "project is required"})
+	}
+	limit, err := services.ParseLimit(ctx.Query("limit"))
+	if err != nil {
+		return ctx.JSON(422, map[string]string{"error": err.Error()})
+	}
+
+	points, err := services.ScanTrend(authID, project, ctx.Query("tool"), limit)
 	if err != nil {
 		return ctx.Error(err)
 	}
 
-	return ctx.JSON(200, scans)
+	return ctx.JSON(200, points)
 }
 
 func (c ScanController) Show(ctx *pickle.Context) pickle.Response {
@@ -72,34 +105,66 @@ func (c ScanController) Store(ctx *pickle.Context) pickle.Response {
 		ToolVersion: &req.ToolVersion,
 		SignalCount: len(req.Signals),
 	}
-	if err := models.QueryScan().Create(scan); err != nil {
+	result, err := services.RecordScan(scan, req.Signals)
+	if err != nil {
 		return ctx.Error(err)
 	}
+	services.ForwardScan(result)
 
-	for _, sig := range req.Signals {
-		dataBytes, err := json.Marshal(sig.Data)
-		if err != nil {
-			return ctx.Error(err)
-		}
+	return ctx.JSON(201, result)
+}
 
-		finding := &models.Finding{
-			Fingerprint: sig.Fingerprint,
-			ScanID:      scan.ID,
-			Tool:        req.Tool,
-			SignalType:  sig.SignalType,
-			Severity:    &sig.Severity,
-			Category:    &sig.Category,
-			Route:       &sig.Route,
-			FilePath:    &sig.FilePath,
-			Line:        &sig.Line,
-			Data:        json.RawMessage(dataBytes),
+// maxSarifUploadBytes caps a single SARIF upload. The body is streamed, so
+// this bounds total work rather than memory.
+const maxSarifUploadBytes = 256 << 20
+
+// StoreSarif imports a SARIF 2.1 log. Each run becomes its own scan, named
+// after the run's tool driver, under the project given in the query string.
+func (c ScanController) StoreSarif(ctx *pickle.Context) pickle.Response {
+	authID, err := uuid.Parse(ctx.Auth().UserID)
+	if err != nil {
+		return ctx.Unauthorized("invalid auth")
+	}
+
+	project := ctx.Query("project")
+	if project == "" || len(project) > 255 {
+		return ctx.JSON(422, map[string]string{"error": "project is required and must be at most 255 characters"})
+	}
+	commitHash := ctx.Query("commit_hash")
+	if len(commitHash) > 64 {
+		return ctx.JSON(422, map[string]string{"error": "commit_hash must be at most 64 characters"})
+	}
+
+	body := http.MaxBytesReader(nil, ctx.Request().Body, maxSarifUploadBytes)
+	defer body.Close()
+
+	// The whole upload is one transaction, so a malformed or oversized file
+	// leaves no partially recorded runs behind.
+	var results []*services.ScanResult
+	parseErr := models.Transaction(func(tx *models.Tx) error {
+		imp := services.NewSarifImport(tx, authID, project, commitHash)
+		if err := services.ParseSarif(body, imp); err != nil {
+			return err
+		}
+		var err error
+		results, err = imp.Finish()
+		return err
+	})
+	if parseErr != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(parseErr, &tooLarge) {
+			return ctx.JSON(413, map[string]string{"error": "sarif upload too large"})
 		}
-		if err := models.QueryFinding().Create(finding); err != nil {
-			return ctx.Error(err)
+		if errors.Is(parseErr, services.ErrInvalidSarif) {
+			return ctx.JSON(400, map[string]string{"error": parseErr.Error()})
 		}
+		return ctx.Error(parseErr)
 	}
 
-	return ctx.JSON(201, scan)
+	for _, result := range results {
+		services.ForwardScan(result)
+	}
+	return ctx.JSON(201, results)
 }
 
 func (c ScanController) Destroy(ctx *pickle.Context) pickle.Response {
@@ -121,18 +186,7 @@ func (c ScanController) Destroy(ctx *pickle.Context) pickle.Response {
 		return ctx.NotFound("scan not found")
 	}
 
-	// Delete findings for this scan first, then the scan
-	findings, err := models.QueryFinding().WhereScanID(scan.ID).All()
-	if err != nil {
-		return ctx.Error(err)
-	}
-	for _, f := range findings {
-		if err := models.QueryFinding().Delete(&f); err != nil {
-			return ctx.Error(err)
-		}
-	}
-
-	if err := models.QueryScan().Delete(scan); err != nil {
+	if err := services.DeleteScan(scan); err != nil {
 		return ctx.Error(err)
 	}
 
@@ -0,0 +1,353 @@
+// Package services holds the findings domain logic shared by the HTTP
+// controllers: scan recording, finding deduplication and lifecycle tracking.
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/telhawk-systems/telhawk-stack/findings/app/http/requests"
+	"github.com/telhawk-systems/telhawk-stack/findings/app/models"
+)
+
+// Finding statuses.
+const (
+	StatusOpen         = "open"
+	StatusResolved     = "resolved"
+	StatusReintroduced = "reintroduced"
+)
+
+// Finding history events recorded in finding_events.
+const (
+	EventDetected     = "detected"
+	EventSeen         = "seen"
+	EventResolved     = "resolved"
+	EventReintroduced = "reintroduced"
+)
+
+// ScanResult summarises what a recorded scan did to the stored findings.
+type ScanResult struct {
+	Scan         *models.Scan      `json:"scan"`
+	New          int               `json:"new"`
+	Seen         int               `json:"seen"`
+	Reintroduced int               `json:"reintroduced"`
+	Resolved     int               `json:"resolved"`
+	Changed      []*models.Finding `json:"-"`
+}
+
+// RecordScan creates scan and upserts every signal into the scan owner's
+// findings, keyed by (fingerprint, tool). New fingerprints are inserted as
+// open, known ones get last_seen bumped, and previously resolved ones are
+// flagged as reintroduced. The owner's open findings for the same tool and
+// project that the scan did not report are marked resolved.
+//
+// The scan, all finding changes and their history events are written in one
+// transaction, so a failure never leaves a scan without its findings or
+// findings without the events that explain them.
+func RecordScan(scan *models.Scan, signals []requests.Signal) (*ScanResult, error) {
+	var result *ScanResult
+	err := models.Transaction(func(tx *models.Tx) error {
+		if err := models.QueryScan().WithTx(tx).Create(scan); err != nil {
+			return err
+		}
+		rec := NewScanRecorder(tx, scan)
+		for _, sig := range signals {
+			if err := rec.Record(sig); err != nil {
+				return err
+			}
+		}
+		var err error
+		result, err = rec.Finish()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteScan deletes scan and its history events in one transaction. The
+// findings whose latest sighting was scan are deleted with their history
+// only if no other scan has seen them; otherwise they are moved to the
+// latest other scan that did, so that deleting a scan never loses findings
+// other scans still report.
+func DeleteScan(scan *models.Scan) error {
+	return models.Transaction(func(tx *models.Tx) error {
+		findings, err := models.QueryFinding().WithTx(tx).WhereScanID(scan.ID).All()
+		if err != nil {
+			return err
+		}
+		for i := range findings {
+			f := &findings[i]
+			history, err := models.QueryFindingEvent().WithTx(tx).WhereFindingID(f.ID).All()
+			if err != nil {
+				return err
+			}
+
+			if other, ok := latestOtherSighting(history, scan.ID); ok {
+				f.ScanID = other
+				if err := models.QueryFinding().WithTx(tx).Update(f); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for j := range history {
+				if err := models.QueryFindingEvent().WithTx(tx).Delete(&history[j]); err != nil {
+					return err
+				}
+			}
+			if err := models.QueryFinding().WithTx(tx).Delete(f); err != nil {
+				return err
+			}
+		}
+
+		events, err := models.QueryFindingEvent().WithTx(tx).WhereScanID(scan.ID).All()
+		if err != nil {
+			return err
+		}
+		for i := range events {
+			if err := models.QueryFindingEvent().WithTx(tx).Delete(&events[i]); err != nil {
+				return err
+			}
+		}
+
+		return models.QueryScan().WithTx(tx).Delete(scan)
+	})
+}
+
+// latestOtherSighting returns the scan of the most recent event in history
+// that saw the finding, ignoring scanID's own events. A scan that only
+// resolved the finding did not see it.
+func latestOtherSighting(history []models.FindingEvent, scanID uuid.UUID) (uuid.UUID, bool) {
+	var latest *models.FindingEvent
+	for i := range history {
+		e := &history[i]
+		if e.ScanID == scanID || e.Event == EventResolved {
+			continue
+		}
+		if latest == nil || e.CreatedAt.After(latest.CreatedAt) {
+			latest = e
+		}
+	}
+	if latest == nil {
+		return uuid.UUID{}, false
+	}
+	return latest.ScanID, true
+}
+
+// ScanRecorder records the signals of one scan as they arrive, for uploads
+// too large to collect first. Finish must be called once every signal has
+// been recorded; it resolves the findings the scan did not report.
+type ScanRecorder struct {
+	tx     *models.Tx
+	scan   *models.Scan
+	seenAt time.Time
+	seen   map[string]bool
+	result *ScanResult
+}
+
+// NewScanRecorder starts recording scan within tx.
+func NewScanRecorder(tx *models.Tx, scan *models.Scan) *ScanRecorder {
+	return newScanRecorder(tx, scan, map[string]bool{})
+}
+
+// newScanRecorder starts recording scan into seen, which other runs of the
+// same tool and project may share so that only one of them resolves.
+func newScanRecorder(tx *models.Tx, scan *models.Scan, seen map[string]bool) *ScanRecorder {
+	// Findings are dated by the scan rather than the request, so that an
+	// older scan finishing late cannot resolve what a newer scan has seen.
+	seenAt := scan.CreatedAt
+	if seenAt.IsZero() {
+		seenAt = time.Now().UTC()
+	}
+	return &ScanRecorder{
+		tx:     tx,
+		scan:   scan,
+		seenAt: seenAt,
+		seen:   seen,
+		result: &ScanResult{Scan: scan},
+	}
+}
+
+// Record upserts one signal. Repeated fingerprints within a scan, or within
+// the runs sharing its seen set, are ignored.
+func (r *ScanRecorder) Record(sig requests.Signal) error {
+	if r.seen[sig.Fingerprint] {
+		return nil
+	}
+	r.seen[sig.Fingerprint] = true
+
+	finding, event, err := upsertFinding(r.tx, r.scan, sig, r.seenAt)
+	if err != nil {
+		return err
+	}
+	if err := recordEvent(r.tx, finding, r.scan, event); err != nil {
+		return err
+	}
+
+	switch event {
+	case EventDetected:
+		r.result.New++
+		r.result.Changed = append(r.result.Changed, finding)
+	case EventReintroduced:
+		r.result.Reintroduced++
+		r.result.Changed = append(r.result.Changed, finding)
+	default:
+		r.result.Seen++
+	}
+	return nil
+}
+
+// Result returns what the scan has changed so far, before resolving. Finish
+// updates the same result.
+func (r *ScanRecorder) Result() *ScanResult {
+	return r.result
+}
+
+// Finish resolves the scan owner's findings that the scan did not report
+// and returns what the scan changed.
+func (r *ScanRecorder) Finish() (*ScanResult, error) {
+	resolved, err := resolveMissing(r.tx, r.scan, r.seen, r.seenAt)
+	if err != nil {
+		return nil, err
+	}
+	r.result.Resolved = len(resolved)
+	r.result.Changed = append(r.result.Changed, resolved...)
+	return r.result, nil
+}
+
+func upsertFinding(tx *models.Tx, scan *models.Scan, sig requests.Signal, seenAt time.Time) (*models.Finding, string, error) {
+	dataBytes, err := json.Marshal(sig.Data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Only the scan owner's findings are candidates, so a finding is never
+	// moved to another user's scan.
+	existing, err := models.QueryFinding().
+		WithTx(tx).
+		WhereUserID(&scan.UserID).
+		WhereFingerprint(sig.Fingerprint).
+		WhereTool(scan.Tool).
+		First()
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, "", err
+	}
+	if existing == nil {
+		finding := newFinding(scan, sig, dataBytes, seenAt)
+		if err := models.QueryFinding().WithTx(tx).Create(finding); err != nil {
+			return nil, "", err
+		}
+		return finding, EventDetected, nil
+	}
+
+	event := sightFinding(existing, scan, sig, dataBytes, seenAt)
+	if err := models.QueryFinding().WithTx(tx).Update(existing); err != nil {
+		return nil, "", err
+	}
+	return existing, event, nil
+}
+
+// newFinding returns the open finding for a signal seen for the first time.
+func newFinding(scan *models.Scan, sig requests.Signal, data []byte, seenAt time.Time) *models.Finding {
+	return &models.Finding{
+		Fingerprint: sig.Fingerprint,
+		UserID:      &scan.UserID,
+		ScanID:      scan.ID,
+		Tool:        scan.Tool,
+		Project:     scan.Project,
+		SignalType:  sig.SignalType,
+		Severity:    &sig.Severity,
+		Category:    &sig.Category,
+		Route:       &sig.Route,
+		FilePath:    &sig.FilePath,
+		Line:        &sig.Line,
+		Data:        json.RawMessage(data),
+		Status:      StatusOpen,
+		FirstSeen:   seenAt,
+		LastSeen:    seenAt,
+	}
+}
+
+// sightFinding updates an existing finding that scan reported again and
+// returns the history event: seen, or reintroduced if it had been resolved.
+func sightFinding(existing *models.Finding, scan *models.Scan, sig requests.Signal, data []byte, seenAt time.Time) string {
+	event := EventSeen
+	if existing.Status == StatusResolved {
+		event = EventReintroduced
+		existing.Status = StatusReintroduced
+		existing.ReintroducedCount++
+		existing.ResolvedAt = nil
+	}
+
+	existing.ScanID = scan.ID
+	existing.Project = scan.Project
+	existing.SignalType = sig.SignalType
+	existing.Severity = &sig.Severity
+	existing.Category = &sig.Category
+	existing.Route = &sig.Route
+	existing.FilePath = &sig.FilePath
+	existing.Line = &sig.Line
+	existing.Data = json.RawMessage(data)
+	if seenAt.After(existing.LastSeen) {
+		existing.LastSeen = seenAt
+	}
+	return event
+}
+
+// resolveMissing marks every unresolved finding of the scan owner for the
+// scan's tool and project that was not part of the scan as resolved.
+func resolveMissing(tx *models.Tx, scan *models.Scan, seen map[string]bool, seenAt time.Time) ([]*models.Finding, error) {
+	candidates, err := models.QueryFinding().
+		WithTx(tx).
+		WhereUserID(&scan.UserID).
+		WhereTool(scan.Tool).
+		WhereProject(scan.Project).
+		WhereStatusIn([]string{StatusOpen, StatusReintroduced}).
+		All()
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved []*models.Finding
+	for i := range candidates {
+		f := &candidates[i]
+		if !resolveUnseen(f, seen, seenAt) {
+			continue
+		}
+		if err := models.QueryFinding().WithTx(tx).Update(f); err != nil {
+			return nil, err
+		}
+		if err := recordEvent(tx, f, scan, EventResolved); err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, f)
+	}
+	return resolved, nil
+}
+
+// resolveUnseen marks an unresolved finding resolved at seenAt unless the
+// scan saw it. Only a newer scan may resolve a finding: one last seen by a
+// scan taken after this one is still present.
+func resolveUnseen(f *models.Finding, seen map[string]bool, seenAt time.Time) bool {
+	if seen[f.Fingerprint] || f.LastSeen.After(seenAt) {
+		return false
+	}
+	f.Status = StatusResolved
+	f.ResolvedAt = &seenAt
+	return true
+}
+
+func recordEvent(tx *models.Tx, finding *models.Finding, scan *models.Scan, event string) error {
+	return models.QueryFindingEvent().WithTx(tx).Create(&models.FindingEvent{
+		FindingID:  finding.ID,
+		ScanID:     scan.ID,
+		Event:      event,
+		CommitHash: scan.CommitHash,
+	})
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/telhawk-systems/telhawk-stack/findings/app/http/requests"
+	"github.com/telhawk-systems/telhawk-stack/findings/app/models"
+)
+
+func TestFindingLifecycle(t *testing.T) {
+	userID := uuid.New()
+	base := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	scanAt := func(hours int) *models.Scan {
+		return &models.Scan{ID: uuid.New(), UserID: userID, Tool: "semgrep", Project: "api", CreatedAt: base.Add(time.Duration(hours) * time.Hour)}
+	}
+	sig := requests.Signal{Fingerprint: "fp-1", SignalType: "sast", Severity: "high", FilePath: "src/db.go", Line: 12}
+
+	// Detected by the first scan
+	first := scanAt(0)
+	f := newFinding(first, sig, []byte(`{}`), first.CreatedAt)
+	if f.Status != StatusOpen || !f.FirstSeen.Equal(first.CreatedAt) || !f.LastSeen.Equal(first.CreatedAt) || f.ScanID != first.ID {
+		t.Fatalf("new finding = %+v", f)
+	}
+
+	// Seen again: the same finding, moved to the newer scan
+	second := scanAt(1)
+	sig.Line = 14
+	if event := sightFinding(f, second, sig, []byte(`{}`), second.CreatedAt); event != EventSeen {
+		t.Errorf("event = %q, want %q", event, EventSeen)
+	}
+	if f.Status != StatusOpen || !f.LastSeen.Equal(second.CreatedAt) || !f.FirstSeen.Equal(first.CreatedAt) || f.ScanID != second.ID || *f.Line != 14 {
+		t.Errorf("seen finding = %+v", f)
+	}
+
+	// An older scan finishing late neither resolves it nor moves last seen back
+	late := scanAt(-1)
+	if resolveUnseen(f, map[string]bool{}, late.CreatedAt) {
+		t.Error("an older scan resolved a finding a newer scan has seen")
+	}
+	sightFinding(f, late, sig, []byte(`{}`), late.CreatedAt)
+	if !f.LastSeen.Equal(second.CreatedAt) {
+		t.Errorf("last seen = %v, want it kept at %v", f.LastSeen, second.CreatedAt)
+	}
+
+	// A scan that reports it is not resolving it
+	third := scanAt(2)
+	if resolveUnseen(f, map[string]bool{"fp-1": true}, third.CreatedAt) {
+		t.Error("a scan resolved a finding it reported")
+	}
+
+	// Missing from a newer scan: resolved
+	if !resolveUnseen(f, map[string]bool{"fp-2": true}, third.CreatedAt) {
+		t.Fatal("a newer scan did not resolve a missing finding")
+	}
+	if f.Status != StatusResolved || f.ResolvedAt == nil || !f.ResolvedAt.Equal(third.CreatedAt) {
+		t.Errorf("resolved finding = %+v", f)
+	}
+
+	// Back again: reintroduced, twice
+	for i, hours := range []int{3, 5} {
+		scan := scanAt(hours)
+		if event := sightFinding(f, scan, sig, []byte(`{}`), scan.CreatedAt); event != EventReintroduced {
+			t.Errorf("event = %q, want %q", event, EventReintroduced)
+		}
+		if f.Status != StatusReintroduced || f.ResolvedAt != nil || f.ReintroducedCount != i+1 {
+			t.Errorf("reintroduced finding = %+v", f)
+		}
+		if !resolveUnseen(f, map[string]bool{}, scanAt(hours+1).CreatedAt) {
+			t.Error("a reintroduced finding was not resolved")
+		}
+	}
+}
+
+func TestLatestOtherSighting(t *testing.T) {
+	deleted, older, newer, resolver := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	base := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	event := func(scanID uuid.UUID, kind string, hours int) models.FindingEvent {
+		return models.FindingEvent{ScanID: scanID, Event: kind, CreatedAt: base.Add(time.Duration(hours) * time.Hour)}
+	}
+
+	tests := []struct {
+		name    string
+		history []models.FindingEvent
+		want    uuid.UUID
+		wantOK  bool
+	}{
+		{"only the deleted scan", []models.FindingEvent{event(deleted, EventDetected, 0)}, uuid.UUID{}, false},
+		{"another scan only resolved it", []models.FindingEvent{
+			event(deleted, EventDetected, 0),
+			event(resolver, EventResolved, 1),
+			event(deleted, EventReintroduced, 2),
+		}, uuid.UUID{}, false},
+		{"seen by other scans", []models.FindingEvent{
+			event(older, EventDetected, 0),
+			event(newer, EventSeen, 1),
+			event(resolver, EventResolved, 2),
+			event(deleted, EventReintroduced, 3),
+		}, newer, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := latestOtherSighting(tt.history, deleted)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("latestOtherSighting() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
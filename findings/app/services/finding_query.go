@@ -0,0 +1,434 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/findings/app/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// ErrInvalidFilter is wrapped by errors caused by malformed query parameters.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+var validSeverities = map[string]bool{"critical": true, "high": true, "medium": true, "low": true, "info": true}
+
+var validStatuses = map[string]bool{StatusOpen: true, StatusResolved: true, StatusReintroduced: true}
+
+// FindingFilter narrows a findings listing. Empty fields match everything.
+type FindingFilter struct {
+	Project    string
+	Tool       string
+	Severity   string
+	Category   string
+	SignalType string
+	Status     string
+	PathPrefix string
+	Since      *time.Time
+	Until      *time.Time
+	Cursor     *uuid.UUID
+	Limit      int
+}
+
+// Page is one page of a cursor-paginated listing. NextCursor is empty on the
+// last page.
+type Page[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ParseFindingFilter builds a filter from query parameters, rejecting
+// unknown enum values and malformed dates, cursors and limits.
+func ParseFindingFilter(query func(string) string) (FindingFilter, error) {
+	f := FindingFilter{
+		Project:    query("project"),
+		Tool:       query("tool"),
+		Severity:   query("severity"),
+		Category:   query("category"),
+		SignalType: query("signal_type"),
+		Status:     query("status"),
+		PathPrefix: query("path"),
+	}
+
+	if f.Severity != "" && !validSeverities[f.Severity] {
+		return f, fmt.Errorf("%w: severity must be one of critical, high, medium, low, info", ErrInvalidFilter)
+	}
+	if f.Status != "" && !validStatuses[f.Status] {
+		return f, fmt.Errorf("%w: status must be one of open, resolved, reintroduced", ErrInvalidFilter)
+	}
+
+	var err error
+	if f.Since, err = parseTime(query("since")); err != nil {
+		return f, fmt.Errorf("%w: since: %w", ErrInvalidFilter, err)
+	}
+	if f.Until, err = parseTime(query("until")); err != nil {
+		return f, fmt.Errorf("%w: until: %w", ErrInvalidFilter, err)
+	}
+	if f.Cursor, err = ParseCursor(query("cursor")); err != nil {
+		return f, err
+	}
+	if f.Limit, err = ParseLimit(query("limit")); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// ListFindings returns one page of the user's findings matching the filter,
+// ordered by id so that the cursor (the last id returned) is stable while
+// new findings are being recorded.
+func ListFindings(userID uuid.UUID, f FindingFilter) (*Page[models.Finding], error) {
+	q := models.QueryFinding().
+		WhereUserID(&userID).
+		OrderBy("id", "ASC").
+		Limit(f.Limit + 1)
+
+	if f.Project != "" {
+		q = q.WhereProject(f.Project)
+	}
+	if f.Tool != "" {
+		q = q.WhereTool(f.Tool)
+	}
+	if f.Severity != "" {
+		q = q.WhereSeverity(&f.Severity)
+	}
+	if f.Category != "" {
+		q = q.WhereCategory(&f.Category)
+	}
+	if f.SignalType != "" {
+		q = q.WhereSignalType(f.SignalType)
+	}
+	if f.Status != "" {
+		q = q.WhereStatus(f.Status)
+	}
+	if f.PathPrefix != "" {
+		q = q.WhereFilePathLike(escapeLike(f.PathPrefix) + "%")
+	}
+	if f.Since != nil {
+		q = q.WhereLastSeenAfter(*f.Since)
+	}
+	if f.Until != nil {
+		q = q.WhereLastSeenBefore(*f.Until)
+	}
+	if f.Cursor != nil {
+		q = q.WhereIDGreaterThan(*f.Cursor)
+	}
+
+	findings, err := q.All()
+	if err != nil {
+		return nil, err
+	}
+	return newPage(findings, f.Limit, func(f models.Finding) uuid.UUID { return f.ID }), nil
+}
+
+// ListScans returns one page of the user's scans, optionally narrowed to a
+// project and tool.
+func ListScans(userID uuid.UUID, project, tool string, cursor *uuid.UUID, limit int) (*Page[models.Scan], error) {
+	q := models.QueryScan().
+		WhereUserID(userID).
+		OrderBy("id", "ASC").
+		Limit(limit + 1)
+
+	if project != "" {
+		q = q.WhereProject(project)
+	}
+	if tool != "" {
+		q = q.WhereTool(tool)
+	}
+	if cursor != nil {
+		q = q.WhereIDGreaterThan(*cursor)
+	}
+
+	scans, err := q.All()
+	if err != nil {
+		return nil, err
+	}
+	return newPage(scans, limit, func(s models.Scan) uuid.UUID { return s.ID }), nil
+}
+
+// newPage builds a page from up to limit+1 rows ordered by id: the extra row
+// only tells that there is a next page, which starts after the last id kept.
+func newPage[T any](rows []T, limit int, id func(T) uuid.UUID) *Page[T] {
+	page := &Page[T]{Data: rows}
+	if len(rows) > limit {
+		page.Data = rows[:limit]
+		page.NextCursor = id(page.Data[limit-1]).String()
+	}
+	if page.Data == nil {
+		page.Data = []T{}
+	}
+	return page
+}
+
+// ProjectSummary aggregates the unresolved findings of one project.
+type ProjectSummary struct {
+	Project    string         `json:"project"`
+	Open       int            `json:"open"`
+	Resolved   int            `json:"resolved"`
+	BySeverity map[string]int `json:"by_severity"`
+	ByCategory map[string]int `json:"by_category"`
+	ByTool     map[string]int `json:"by_tool"`
+}
+
+// summaryRow is one group of the summary query.
+type summaryRow struct {
+	Project  string
+	Status   string
+	Severity string
+	Category string
+	Tool     string
+	Count    int
+}
+
+// SummarizeFindings counts the user's findings per project. Open and
+// reintroduced findings are broken down by severity, category and tool;
+// resolved findings are only counted. The filter's status, cursor and limit
+// are ignored. The counting is done by the database in one grouped query.
+func SummarizeFindings(userID uuid.UUID, f FindingFilter) ([]ProjectSummary, error) {
+	where, args := summaryFilter(userID, f)
+	rows, err := models.DB.Query(`SELECT project, status, COALESCE(severity, ''), COALESCE(category, ''), tool, COUNT(*)
+		FROM findings
+		WHERE `+where+`
+		GROUP BY project, status, severity, category, tool`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []summaryRow
+	for rows.Next() {
+		var g summaryRow
+		if err := rows.Scan(&g.Project, &g.Status, &g.Severity, &g.Category, &g.Tool, &g.Count); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return summarize(groups), nil
+}
+
+// summaryFilter renders the filter fields SummarizeFindings honours as a
+// WHERE clause with numbered placeholders, matching ListFindings.
+func summaryFilter(userID uuid.UUID, f FindingFilter) (string, []any) {
+	conds := []string{"user_id = $1"}
+	args := []any{userID}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Project != "" {
+		add("project = $%d", f.Project)
+	}
+	if f.Tool != "" {
+		add("tool = $%d", f.Tool)
+	}
+	if f.Severity != "" {
+		add("severity = $%d", f.Severity)
+	}
+	if f.Category != "" {
+		add("category = $%d", f.Category)
+	}
+	if f.SignalType != "" {
+		add("signal_type = $%d", f.SignalType)
+	}
+	if f.PathPrefix != "" {
+		add(`file_path LIKE $%d ESCAPE '\'`, escapeLike(f.PathPrefix)+"%")
+	}
+	if f.Since != nil {
+		add("last_seen > $%d", *f.Since)
+	}
+	if f.Until != nil {
+		add("last_seen < $%d", *f.Until)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// summarize folds grouped counts into one summary per project, ordered by
+// project.
+func summarize(groups []summaryRow) []ProjectSummary {
+	byProject := map[string]*ProjectSummary{}
+	for _, g := range groups {
+		s, ok := byProject[g.Project]
+		if !ok {
+			s = &ProjectSummary{
+				Project:    g.Project,
+				BySeverity: map[string]int{},
+				ByCategory: map[string]int{},
+				ByTool:     map[string]int{},
+			}
+			byProject[g.Project] = s
+		}
+
+		if g.Status == StatusResolved {
+			s.Resolved += g.Count
+			continue
+		}
+		s.Open += g.Count
+		s.BySeverity[orDefault(g.Severity, "unknown")] += g.Count
+		s.ByCategory[orDefault(g.Category, "uncategorized")] += g.Count
+		s.ByTool[g.Tool] += g.Count
+	}
+
+	summaries := make([]ProjectSummary, 0, len(byProject))
+	for _, s := range byProject {
+		summaries = append(summaries, *s)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Project < summaries[j].Project })
+	return summaries
+}
+
+// TrendPoint describes the findings picture after one scan.
+type TrendPoint struct {
+	ScanID       uuid.UUID `json:"scan_id"`
+	Tool         string    `json:"tool"`
+	CommitHash   string    `json:"commit_hash,omitempty"`
+	ScannedAt    time.Time `json:"scanned_at"`
+	Open         int       `json:"open"`
+	New          int       `json:"new"`
+	Reintroduced int       `json:"reintroduced"`
+	Resolved     int       `json:"resolved"`
+}
+
+// ScanTrend returns one point per scan of a project in scan order, derived
+// from the finding_events each scan recorded. Since a scan reports every
+// finding it still sees, its open count is the number of findings it
+// detected, saw again or reintroduced.
+func ScanTrend(userID uuid.UUID, project, tool string, limit int) ([]TrendPoint, error) {
+	q := models.QueryScan().
+		WhereUserID(userID).
+		WhereProject(project).
+		OrderBy("created_at", "DESC").
+		Limit(limit)
+	if tool != "" {
+		q = q.WhereTool(tool)
+	}
+
+	scans, err := q.All()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scans) == 0 {
+		return []TrendPoint{}, nil
+	}
+
+	// Count every scan's events in one grouped query rather than one query
+	// per scan.
+	placeholders := make([]string, len(scans))
+	args := make([]any, len(scans))
+	for i, scan := range scans {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = scan.ID
+	}
+	rows, err := models.DB.Query(`SELECT scan_id, event, COUNT(*)
+		FROM finding_events
+		WHERE scan_id IN (`+strings.Join(placeholders, ", ")+`)
+		GROUP BY scan_id, event`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[uuid.UUID]map[string]int{}
+	for rows.Next() {
+		var scanID uuid.UUID
+		var event string
+		var n int
+		if err := rows.Scan(&scanID, &event, &n); err != nil {
+			return nil, err
+		}
+		if counts[scanID] == nil {
+			counts[scanID] = map[string]int{}
+		}
+		counts[scanID][event] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return trendPoints(scans, counts), nil
+}
+
+// trendPoints turns per-scan event counts into trend points, oldest scan
+// first; scans arrive newest first.
+func trendPoints(scans []models.Scan, counts map[uuid.UUID]map[string]int) []TrendPoint {
+	points := make([]TrendPoint, 0, len(scans))
+	for i := len(scans) - 1; i >= 0; i-- {
+		scan := scans[i]
+		events := counts[scan.ID]
+		points = append(points, TrendPoint{
+			ScanID:       scan.ID,
+			Tool:         scan.Tool,
+			CommitHash:   deref(scan.CommitHash),
+			ScannedAt:    scan.CreatedAt,
+			Open:         events[EventDetected] + events[EventSeen] + events[EventReintroduced],
+			New:          events[EventDetected],
+			Reintroduced: events[EventReintroduced],
+			Resolved:     events[EventResolved],
+		})
+	}
+	return points
+}
+
+func parseTime(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, errors.New("expected RFC3339 timestamp or YYYY-MM-DD date")
+	}
+	return &t, nil
+}
+
+// ParseCursor validates an opaque cursor taken from a previous page.
+func ParseCursor(v string) (*uuid.UUID, error) {
+	if v == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrInvalidFilter)
+	}
+	return &id, nil
+}
+
+// ParseLimit validates a page size, defaulting to 100 and capping at 1000.
+func ParseLimit(v string) (int, error) {
+	if v == "" {
+		return defaultPageSize, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%w: limit must be a positive integer", ErrInvalidFilter)
+	}
+	if n > maxPageSize {
+		n = maxPageSize
+	}
+	return n, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/telhawk-systems/telhawk-stack/findings/app/models"
+)
+
+func TestParseFindingFilter(t *testing.T) {
+	cursor := uuid.New()
+	query := url.Values{
+		"project":  {"api"},
+		"severity": {"high"},
+		"status":   {StatusReintroduced},
+		"path":     {"src/"},
+		"since":    {"2026-10-01"},
+		"until":    {"2026-10-18T12:00:00Z"},
+		"cursor":   {cursor.String()},
+		"limit":    {"5000"},
+	}
+
+	f, err := ParseFindingFilter(query.Get)
+	if err != nil {
+		t.Fatalf("ParseFindingFilter: %v", err)
+	}
+	if f.Project != "api" || f.Severity != "high" || f.Status != StatusReintroduced || f.PathPrefix != "src/" {
+		t.Errorf("filter = %+v", f)
+	}
+	if !f.Since.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) || !f.Until.Equal(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("since = %v, until = %v", f.Since, f.Until)
+	}
+	if f.Cursor == nil || *f.Cursor != cursor {
+		t.Errorf("cursor = %v, want %v", f.Cursor, cursor)
+	}
+	if f.Limit != maxPageSize {
+		t.Errorf("limit = %d, want it capped at %d", f.Limit, maxPageSize)
+	}
+
+	defaults, err := ParseFindingFilter(url.Values{}.Get)
+	if err != nil {
+		t.Fatalf("ParseFindingFilter: %v", err)
+	}
+	if defaults.Limit != defaultPageSize || defaults.Cursor != nil || defaults.Since != nil {
+		t.Errorf("defaults = %+v", defaults)
+	}
+}
+
+func TestParseFindingFilter_Invalid(t *testing.T) {
+	for name, query := range map[string]url.Values{
+		"severity": {"severity": {"urgent"}},
+		"status":   {"status": {"closed"}},
+		"since":    {"since": {"yesterday"}},
+		"cursor":   {"cursor": {"42"}},
+		"limit":    {"limit": {"0"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseFindingFilter(query.Get); !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("err = %v, want ErrInvalidFilter", err)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	id := func(u uuid.UUID) uuid.UUID { return u }
+
+	page := newPage(ids, 2, id)
+	if len(page.Data) != 2 || page.NextCursor != ids[1].String() {
+		t.Errorf("page = %d rows, next %q; want 2 rows, next after the last kept", len(page.Data), page.NextCursor)
+	}
+
+	last := newPage(ids[:2], 2, id)
+	if len(last.Data) != 2 || last.NextCursor != "" {
+		t.Errorf("last page = %d rows, next %q; want no next cursor", len(last.Data), last.NextCursor)
+	}
+
+	empty := newPage[uuid.UUID](nil, 2, id)
+	if empty.Data == nil || len(empty.Data) != 0 {
+		t.Errorf("empty page data = %#v, want an empty slice", empty.Data)
+	}
+}
+
+func TestSummaryFilter(t *testing.T) {
+	userID := uuid.New()
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	cursor := uuid.New()
+
+	where, args := summaryFilter(userID, FindingFilter{
+		Tool:       "semgrep",
+		PathPrefix: "src/100%_",
+		Since:      &since,
+		Status:     StatusOpen,
+		Cursor:     &cursor,
+		Limit:      1,
+	})
+
+	wantWhere := `user_id = $1 AND tool = $2 AND file_path LIKE $3 ESCAPE '\' AND last_seen > $4`
+	if where != wantWhere {
+		t.Errorf("where = %q, want %q", where, wantWhere)
+	}
+	wantArgs := []any{userID, "semgrep", `src/100\%\_%`, since}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	summaries := summarize([]summaryRow{
+		{Project: "web", Status: StatusOpen, Severity: "low", Category: "xss", Tool: "semgrep", Count: 1},
+		{Project: "api", Status: StatusOpen, Severity: "high", Category: "injection", Tool: "semgrep", Count: 3},
+		{Project: "api", Status: StatusReintroduced, Severity: "high", Category: "", Tool: "codeql", Count: 2},
+		{Project: "api", Status: StatusOpen, Severity: "", Category: "injection", Tool: "codeql", Count: 1},
+		{Project: "api", Status: StatusResolved, Severity: "critical", Category: "secrets", Tool: "gitleaks", Count: 4},
+	})
+
+	if len(summaries) != 2 || summaries[0].Project != "api" || summaries[1].Project != "web" {
+		t.Fatalf("summaries = %+v, want api then web", summaries)
+	}
+	api := summaries[0]
+	if api.Open != 6 || api.Resolved != 4 {
+		t.Errorf("api open = %d, resolved = %d; want 6, 4", api.Open, api.Resolved)
+	}
+	if want := map[string]int{"high": 5, "unknown": 1}; !reflect.DeepEqual(api.BySeverity, want) {
+		t.Errorf("by severity = %v, want %v", api.BySeverity, want)
+	}
+	if want := map[string]int{"injection": 4, "uncategorized": 2}; !reflect.DeepEqual(api.ByCategory, want) {
+		t.Errorf("by category = %v, want %v", api.ByCategory, want)
+	}
+	if want := map[string]int{"semgrep": 3, "codeql": 3}; !reflect.DeepEqual(api.ByTool, want) {
+		t.Errorf("by tool = %v, want %v (resolved findings are not broken down)", api.ByTool, want)
+	}
+}
+
+func TestTrendPoints(t *testing.T) {
+	base := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	newer := models.Scan{ID: uuid.New(), Tool: "semgrep", CommitHash: ptr("def456"), CreatedAt: base.Add(time.Hour)}
+	older := models.Scan{ID: uuid.New(), Tool: "semgrep", CreatedAt: base}
+	empty := models.Scan{ID: uuid.New(), Tool: "semgrep", CreatedAt: base.Add(2 * time.Hour)}
+
+	points := trendPoints([]models.Scan{empty, newer, older}, map[uuid.UUID]map[string]int{
+		older.ID: {EventDetected: 3},
+		newer.ID: {EventDetected: 1, EventSeen: 2, EventReintroduced: 1, EventResolved: 1},
+	})
+
+	want := []TrendPoint{
+		{ScanID: older.ID, Tool: "semgrep", ScannedAt: older.CreatedAt, Open: 3, New: 3},
+		{ScanID: newer.ID, Tool: "semgrep", CommitHash: "def456", ScannedAt: newer.CreatedAt, Open: 4, New: 1, Reintroduced: 1, Resolved: 1},
+		{ScanID: empty.ID, Tool: "semgrep", ScannedAt: empty.CreatedAt},
+	}
+	if !reflect.DeepEqual(points, want) {
+		t.Errorf("points = %+v\nwant %+v", points, want)
+	}
+}
@@ -0,0 +1,486 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/telhawk-systems/telhawk-stack/findings/app/http/requests"
+)
+
+// ErrInvalidSarif is wrapped by every error caused by a malformed upload, as
+// opposed to failures while storing the parsed runs.
+var ErrInvalidSarif = errors.New("invalid sarif")
+
+// SarifSignalType is the signal_type given to findings imported from SARIF.
+const SarifSignalType = "sast"
+
+// SarifRun describes one SARIF run in the findings service's own scan shape:
+// the tool that produced it. Its results are delivered separately as signals.
+type SarifRun struct {
+	Tool        string
+	ToolVersion string
+	CommitHash  string
+}
+
+// SarifHandler receives a SARIF log as it is parsed. StartRun is called
+// before the first result of a run, Result once per result and EndRun after
+// the run object has been read completely.
+type SarifHandler interface {
+	StartRun(run *SarifRun) error
+	Result(sig requests.Signal) error
+	EndRun(run *SarifRun) error
+}
+
+type sarifTool struct {
+	Driver struct {
+		Name            string      `json:"name"`
+		Version         string      `json:"version"`
+		SemanticVersion string      `json:"semanticVersion"`
+		Rules           []sarifRule `json:"rules"`
+	} `json:"driver"`
+}
+
+type sarifRule struct {
+	ID               string         `json:"id"`
+	Name             string         `json:"name"`
+	ShortDescription *sarifMessage  `json:"shortDescription,omitempty"`
+	FullDescription  *sarifMessage  `json:"fullDescription,omitempty"`
+	HelpURI          string         `json:"helpUri,omitempty"`
+	Properties       map[string]any `json:"properties,omitempty"`
+	DefaultConfig    *struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string               `json:"ruleId"`
+	RuleIndex           *int                 `json:"ruleIndex"`
+	Rule                *struct{ ID string } `json:"rule"`
+	Level               string               `json:"level"`
+	Kind                string               `json:"kind"`
+	Message             sarifMessage         `json:"message"`
+	Locations           []sarifLocation      `json:"locations"`
+	PartialFingerprints map[string]string    `json:"partialFingerprints"`
+	Fingerprints        map[string]string    `json:"fingerprints"`
+	Properties          map[string]any       `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine   int `json:"startLine"`
+			StartColumn int `json:"startColumn"`
+			EndLine     int `json:"endLine"`
+			Snippet     *struct {
+				Text string `json:"text"`
+			} `json:"snippet"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+type sarifVersionControl struct {
+	RevisionID string `json:"revisionId"`
+}
+
+// ParseSarif streams a SARIF 2.1 log into h. Results are decoded and handed
+// over one at a time, so the upload is never held in memory as a whole. Rules
+// are resolved from the run's tool.driver.rules, which must precede results.
+func ParseSarif(r io.Reader, h SarifHandler) error {
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	sawRuns := false
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return err
+		}
+		if key != "runs" {
+			if err := skipValue(dec); err != nil {
+				return err
+			}
+			continue
+		}
+
+		sawRuns = true
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			if err := parseRun(dec, h); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	if !sawRuns {
+		return fmt.Errorf("%w: missing runs", ErrInvalidSarif)
+	}
+	return expectDelim(dec, '}')
+}
+
+// runParser holds the state of the run being parsed.
+type runParser struct {
+	h       SarifHandler
+	tool    *sarifTool
+	run     *SarifRun
+	rules   []sarifRule
+	byID    map[string]*sarifRule
+	started bool
+}
+
+func parseRun(dec *json.Decoder, h SarifHandler) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	p := &runParser{h: h, run: &SarifRun{}}
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "tool":
+			var tool sarifTool
+			if err := dec.Decode(&tool); err != nil {
+				return fmt.Errorf("%w: decode tool: %w", ErrInvalidSarif, err)
+			}
+			if err := p.setTool(&tool); err != nil {
+				return err
+			}
+		case "versionControlProvenance":
+			var vcs []sarifVersionControl
+			if err := dec.Decode(&vcs); err != nil {
+				return fmt.Errorf("%w: decode versionControlProvenance: %w", ErrInvalidSarif, err)
+			}
+			for _, v := range vcs {
+				if v.RevisionID != "" {
+					p.run.CommitHash = truncate(v.RevisionID, 64)
+					break
+				}
+			}
+		case "results":
+			if err := p.parseResults(dec); err != nil {
+				return err
+			}
+		default:
+			if err := skipValue(dec); err != nil {
+				return err
+			}
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	// A run without results still resolves the tool's open findings
+	if err := p.start(); err != nil {
+		return err
+	}
+	return h.EndRun(p.run)
+}
+
+func (p *runParser) setTool(tool *sarifTool) error {
+	if tool.Driver.Name == "" {
+		return fmt.Errorf("%w: run is missing tool.driver.name", ErrInvalidSarif)
+	}
+	p.tool = tool
+	p.run.Tool = truncate(strings.ToLower(tool.Driver.Name), 50)
+	p.run.ToolVersion = truncate(firstNonEmpty(tool.Driver.SemanticVersion, tool.Driver.Version), 50)
+	p.rules = tool.Driver.Rules
+	p.byID = make(map[string]*sarifRule, len(p.rules))
+	for i := range p.rules {
+		p.byID[p.rules[i].ID] = &p.rules[i]
+	}
+	return nil
+}
+
+func (p *runParser) start() error {
+	if p.started {
+		return nil
+	}
+	if p.tool == nil {
+		return fmt.Errorf("%w: run is missing tool.driver.name", ErrInvalidSarif)
+	}
+	p.started = true
+	return p.h.StartRun(p.run)
+}
+
+func (p *runParser) parseResults(dec *json.Decoder) error {
+	if p.tool == nil {
+		return fmt.Errorf("%w: run results must follow tool", ErrInvalidSarif)
+	}
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	if err := p.start(); err != nil {
+		return err
+	}
+	for dec.More() {
+		var res sarifResult
+		if err := dec.Decode(&res); err != nil {
+			return fmt.Errorf("%w: decode result: %w", ErrInvalidSarif, err)
+		}
+		rule := lookupRule(res, p.rules, p.byID)
+		if err := p.h.Result(resultToSignal(res, rule)); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func lookupRule(res sarifResult, ordered []sarifRule, byID map[string]*sarifRule) *sarifRule {
+	if res.RuleIndex != nil && *res.RuleIndex >= 0 && *res.RuleIndex < len(ordered) {
+		return &ordered[*res.RuleIndex]
+	}
+	if r, ok := byID[resultRuleID(res)]; ok {
+		return r
+	}
+	return nil
+}
+
+func resultRuleID(res sarifResult) string {
+	if res.RuleID != "" {
+		return res.RuleID
+	}
+	if res.Rule != nil {
+		return res.Rule.ID
+	}
+	return ""
+}
+
+func resultToSignal(res sarifResult, rule *sarifRule) requests.Signal {
+	ruleID := resultRuleID(res)
+	if ruleID == "" && rule != nil {
+		ruleID = rule.ID
+	}
+
+	var filePath, snippet string
+	var line int
+	if len(res.Locations) > 0 {
+		loc := res.Locations[0].PhysicalLocation
+		filePath = loc.ArtifactLocation.URI
+		line = loc.Region.StartLine
+		if loc.Region.Snippet != nil {
+			snippet = loc.Region.Snippet.Text
+		}
+	}
+
+	level := res.Level
+	if level == "" && rule != nil && rule.DefaultConfig != nil {
+		level = rule.DefaultConfig.Level
+	}
+	if level == "" {
+		level = "warning"
+	}
+
+	data := map[string]any{
+		"rule_id": ruleID,
+		"level":   level,
+		"message": res.Message.Text,
+	}
+	if res.Kind != "" {
+		data["kind"] = res.Kind
+	}
+	if len(res.Locations) > 1 {
+		data["locations"] = res.Locations
+	}
+	if len(res.PartialFingerprints) > 0 {
+		data["partial_fingerprints"] = res.PartialFingerprints
+	}
+	if len(res.Properties) > 0 {
+		data["properties"] = res.Properties
+	}
+	if rule != nil {
+		data["rule"] = ruleSummary(rule)
+	}
+
+	return requests.Signal{
+		Fingerprint: sarifFingerprint(ruleID, res, filePath, line, snippet),
+		SignalType:  SarifSignalType,
+		Severity:    sarifSeverity(level, rule),
+		Category:    truncate(sarifCategory(rule), 100),
+		FilePath:    truncate(filePath, 500),
+		Line:        line,
+		Data:        data,
+	}
+}
+
+// ruleSummary is the part of a rule stored with each of its findings. Full
+// descriptions and properties stay in the tool's rule catalogue.
+func ruleSummary(rule *sarifRule) map[string]any {
+	summary := map[string]any{"id": rule.ID}
+	if rule.Name != "" {
+		summary["name"] = rule.Name
+	}
+	if rule.ShortDescription != nil && rule.ShortDescription.Text != "" {
+		summary["short_description"] = rule.ShortDescription.Text
+	}
+	if rule.HelpURI != "" {
+		summary["help_uri"] = rule.HelpURI
+	}
+	return summary
+}
+
+// sarifFingerprint derives a stable 64-character fingerprint. Tool-provided
+// fingerprints are preferred since they survive unrelated edits to the file;
+// otherwise the rule, file and snippet (or line when no snippet is present)
+// identify the result.
+func sarifFingerprint(ruleID string, res sarifResult, filePath string, line int, snippet string) string {
+	h := sha256.New()
+	h.Write([]byte(ruleID))
+
+	fps := res.PartialFingerprints
+	if len(fps) == 0 {
+		fps = res.Fingerprints
+	}
+	if len(fps) > 0 {
+		keys := make([]string, 0, len(fps))
+		for k := range fps {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			h.Write([]byte{0})
+			h.Write([]byte(k + "=" + fps[k]))
+		}
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	h.Write([]byte{0})
+	h.Write([]byte(filePath))
+	h.Write([]byte{0})
+	if s := strings.TrimSpace(snippet); s != "" {
+		h.Write([]byte(s))
+	} else {
+		h.Write([]byte(strconv.Itoa(line)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// sarifSeverity maps a result to critical/high/medium/low/info. A rule's
+// numeric security-severity (CVSS-style, as emitted by CodeQL and trivy)
+// wins over the coarse SARIF level.
+func sarifSeverity(level string, rule *sarifRule) string {
+	if rule != nil {
+		if score, ok := securitySeverity(rule.Properties); ok {
+			switch {
+			case score >= 9.0:
+				return "critical"
+			case score >= 7.0:
+				return "high"
+			case score >= 4.0:
+				return "medium"
+			case score > 0:
+				return "low"
+			default:
+				return "info"
+			}
+		}
+	}
+
+	switch level {
+	case "error":
+		return "high"
+	case "warning":
+		return "medium"
+	case "note":
+		return "low"
+	default:
+		return "info"
+	}
+}
+
+func securitySeverity(props map[string]any) (float64, bool) {
+	switch v := props["security-severity"].(type) {
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func sarifCategory(rule *sarifRule) string {
+	if rule == nil {
+		return ""
+	}
+	if c, ok := rule.Properties["category"].(string); ok && c != "" {
+		return c
+	}
+	if tags, ok := rule.Properties["tags"].([]any); ok {
+		for _, t := range tags {
+			if s, ok := t.(string); ok && s != "" && s != "security" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSarif, err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("%w: expected %q, got %v", ErrInvalidSarif, want, tok)
+	}
+	return nil
+}
+
+func readKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidSarif, err)
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: expected object key, got %v", ErrInvalidSarif, tok)
+	}
+	return key, nil
+}
+
+func skipValue(dec *json.Decoder) error {
+	var discard json.RawMessage
+	if err := dec.Decode(&discard); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSarif, err)
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/telhawk-systems/telhawk-stack/findings/app/http/requests"
+	"github.com/telhawk-systems/telhawk-stack/findings/app/models"
+)
+
+// runRecorder records the signals of one run. ScanRecorder implements it.
+type runRecorder interface {
+	Record(sig requests.Signal) error
+	Result() *ScanResult
+	Finish() (*ScanResult, error)
+}
+
+// SarifImport records a SARIF upload as it is parsed, each run as its own
+// scan. A log may hold several runs of the same tool, e.g. one per language:
+// they share one set of seen fingerprints, and the findings none of them
+// reported are resolved once, by the tool's last run, when the upload is
+// finished. A run therefore never resolves what an earlier run of the same
+// upload has just recorded.
+type SarifImport struct {
+	tx         *models.Tx
+	userID     uuid.UUID
+	project    string
+	commitHash string
+
+	scan    *models.Scan
+	run     runRecorder
+	seen    map[string]map[string]bool // fingerprints seen per tool
+	last    map[string]runRecorder     // last run per tool
+	tools   []string                   // tools in the order of their first run
+	results []*ScanResult
+
+	// createScan, updateScan and newRecorder are replaced in tests.
+	createScan  func(scan *models.Scan) error
+	updateScan  func(scan *models.Scan) error
+	newRecorder func(scan *models.Scan, seen map[string]bool) runRecorder
+}
+
+// NewSarifImport starts importing a SARIF upload for userID within tx. A
+// non-empty commitHash overrides the revision each run reports.
+func NewSarifImport(tx *models.Tx, userID uuid.UUID, project, commitHash string) *SarifImport {
+	return &SarifImport{
+		tx:         tx,
+		userID:     userID,
+		project:    project,
+		commitHash: commitHash,
+		seen:       map[string]map[string]bool{},
+		last:       map[string]runRecorder{},
+		createScan: func(scan *models.Scan) error {
+			return models.QueryScan().WithTx(tx).Create(scan)
+		},
+		updateScan: func(scan *models.Scan) error {
+			return models.QueryScan().WithTx(tx).Update(scan)
+		},
+		newRecorder: func(scan *models.Scan, seen map[string]bool) runRecorder {
+			return newScanRecorder(tx, scan, seen)
+		},
+	}
+}
+
+func (i *SarifImport) StartRun(run *SarifRun) error {
+	hash := i.commitHash
+	if hash == "" {
+		hash = run.CommitHash
+	}
+
+	i.scan = &models.Scan{
+		UserID:      i.userID,
+		Tool:        run.Tool,
+		Project:     i.project,
+		CommitHash:  &hash,
+		ToolVersion: &run.ToolVersion,
+	}
+	if err := i.createScan(i.scan); err != nil {
+		return err
+	}
+
+	seen, ok := i.seen[run.Tool]
+	if !ok {
+		seen = map[string]bool{}
+		i.seen[run.Tool] = seen
+		i.tools = append(i.tools, run.Tool)
+	}
+	i.run = i.newRecorder(i.scan, seen)
+	i.last[run.Tool] = i.run
+	return nil
+}
+
+func (i *SarifImport) Result(sig requests.Signal) error {
+	i.scan.SignalCount++
+	return i.run.Record(sig)
+}
+
+func (i *SarifImport) EndRun(run *SarifRun) error {
+	// versionControlProvenance may follow the results
+	if i.commitHash == "" && run.CommitHash != "" {
+		i.scan.CommitHash = &run.CommitHash
+	}
+	if err := i.updateScan(i.scan); err != nil {
+		return err
+	}
+	i.results = append(i.results, i.run.Result())
+	return nil
+}
+
+// Finish resolves, for each tool in the upload, the findings none of its
+// runs reported, and returns one result per run in upload order.
+func (i *SarifImport) Finish() ([]*ScanResult, error) {
+	for _, tool := range i.tools {
+		if _, err := i.last[tool].Finish(); err != nil {
+			return nil, err
+		}
+	}
+	return i.results, nil
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/telhawk-systems/telhawk-stack/findings/app/http/requests"
+	"github.com/telhawk-systems/telhawk-stack/findings/app/models"
+)
+
+// recordingHandler collects what ParseSarif hands over, in order.
+type recordingHandler struct {
+	calls   []string
+	runs    []SarifRun
+	signals []requests.Signal
+}
+
+func (h *recordingHandler) StartRun(run *SarifRun) error {
+	h.calls = append(h.calls, "start:"+run.Tool)
+	return nil
+}
+
+func (h *recordingHandler) Result(sig requests.Signal) error {
+	h.calls = append(h.calls, "result")
+	h.signals = append(h.signals, sig)
+	return nil
+}
+
+func (h *recordingHandler) EndRun(run *SarifRun) error {
+	h.calls = append(h.calls, "end:"+run.Tool)
+	h.runs = append(h.runs, *run)
+	return nil
+}
+
+func TestParseSarif_MultipleRuns(t *testing.T) {
+	log := `{
+		"version": "2.1.0",
+		"runs": [
+			{
+				"tool": {"driver": {"name": "CodeQL", "semanticVersion": "2.15.0",
+					"rules": [{"id": "js/sql-injection", "properties": {"security-severity": "9.8", "tags": ["security", "injection"]}}]}},
+				"versionControlProvenance": [{"revisionId": "abc123"}],
+				"results": [
+					{"ruleId": "js/sql-injection", "ruleIndex": 0, "message": {"text": "query built from input"},
+					 "locations": [{"physicalLocation": {"artifactLocation": {"uri": "src/db.js"}, "region": {"startLine": 12}}}]},
+					{"ruleId": "js/sql-injection", "ruleIndex": 0, "message": {"text": "again"},
+					 "locations": [{"physicalLocation": {"artifactLocation": {"uri": "src/api.js"}, "region": {"startLine": 40}}}]}
+				]
+			},
+			{
+				"tool": {"driver": {"name": "Semgrep"}},
+				"results": []
+			}
+		]
+	}`
+
+	h := &recordingHandler{}
+	if err := ParseSarif(strings.NewReader(log), h); err != nil {
+		t.Fatalf("ParseSarif: %v", err)
+	}
+
+	want := []string{"start:codeql", "result", "result", "end:codeql", "start:semgrep", "end:semgrep"}
+	if strings.Join(h.calls, ",") != strings.Join(want, ",") {
+		t.Fatalf("calls = %v, want %v", h.calls, want)
+	}
+	if h.runs[0].ToolVersion != "2.15.0" || h.runs[0].CommitHash != "abc123" {
+		t.Errorf("run 0 = %+v", h.runs[0])
+	}
+
+	sig := h.signals[0]
+	if sig.Severity != "critical" || sig.Category != "injection" || sig.FilePath != "src/db.js" || sig.Line != 12 {
+		t.Errorf("signal = %+v", sig)
+	}
+	if sig.Fingerprint == h.signals[1].Fingerprint {
+		t.Error("results in different files share a fingerprint")
+	}
+	if rule, ok := sig.Data["rule"].(map[string]any); !ok || rule["id"] != "js/sql-injection" || rule["properties"] != nil {
+		t.Errorf("rule summary = %#v", sig.Data["rule"])
+	}
+}
+
+func TestParseSarif_MissingRuleIndex(t *testing.T) {
+	log := `{"runs": [{
+		"tool": {"driver": {"name": "gosec", "rules": [
+			{"id": "G101", "defaultConfiguration": {"level": "note"}},
+			{"id": "G404", "defaultConfiguration": {"level": "error"}}
+		]}},
+		"results": [
+			{"ruleId": "G404", "message": {"text": "weak random"}},
+			{"ruleId": "G999", "message": {"text": "unknown rule"}}
+		]
+	}]}`
+
+	h := &recordingHandler{}
+	if err := ParseSarif(strings.NewReader(log), h); err != nil {
+		t.Fatalf("ParseSarif: %v", err)
+	}
+	if len(h.signals) != 2 {
+		t.Fatalf("got %d signals, want 2", len(h.signals))
+	}
+	// Resolved by rule ID: the rule's default level applies
+	if h.signals[0].Severity != "high" || h.signals[0].Data["level"] != "error" {
+		t.Errorf("G404 signal = %+v", h.signals[0])
+	}
+	// Unknown rule: SARIF's default level, no rule attached
+	if h.signals[1].Severity != "medium" || h.signals[1].Data["rule"] != nil {
+		t.Errorf("G999 signal = %+v", h.signals[1])
+	}
+}
+
+func TestParseSarif_ResultsBeforeTool(t *testing.T) {
+	log := `{"runs": [{"results": [], "tool": {"driver": {"name": "x"}}}]}`
+	err := ParseSarif(strings.NewReader(log), &recordingHandler{})
+	if !errors.Is(err, ErrInvalidSarif) {
+		t.Fatalf("err = %v, want ErrInvalidSarif", err)
+	}
+}
+
+func TestParseSarif_Oversized(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`{"runs": [{"tool": {"driver": {"name": "x"}}, "results": [`)
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(`{"ruleId": "r", "message": {"text": "m"}}`)
+	}
+	b.WriteString(`]}]}`)
+
+	body := http.MaxBytesReader(nil, nopCloser{strings.NewReader(b.String())}, 4096)
+	h := &recordingHandler{}
+	err := ParseSarif(body, h)
+
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("err = %v, want *http.MaxBytesError", err)
+	}
+	if len(h.runs) != 0 {
+		t.Error("a truncated run was reported as complete")
+	}
+	if len(h.signals) == 0 || len(h.signals) >= 1000 {
+		t.Errorf("got %d signals, want results streamed before the limit", len(h.signals))
+	}
+}
+
+type nopCloser struct{ *strings.Reader }
+
+func (nopCloser) Close() error { return nil }
+
+// fakeRun stands in for a ScanRecorder, remembering what it was given.
+type fakeRun struct {
+	scan     *models.Scan
+	seen     map[string]bool
+	recorded []string
+	finished int
+	result   *ScanResult
+}
+
+func (r *fakeRun) Record(sig requests.Signal) error {
+	r.recorded = append(r.recorded, sig.Fingerprint)
+	r.seen[sig.Fingerprint] = true
+	return nil
+}
+
+func (r *fakeRun) Result() *ScanResult { return r.result }
+
+func (r *fakeRun) Finish() (*ScanResult, error) {
+	r.finished++
+	return r.result, nil
+}
+
+func TestSarifImport_SameToolRuns(t *testing.T) {
+	log := `{"runs": [
+		{"tool": {"driver": {"name": "CodeQL"}}, "results": [
+			{"ruleId": "js/xss", "message": {"text": "a"}, "partialFingerprints": {"primaryLocationLineHash": "js-1"}}
+		]},
+		{"tool": {"driver": {"name": "CodeQL"}}, "results": [
+			{"ruleId": "py/sqli", "message": {"text": "b"}, "partialFingerprints": {"primaryLocationLineHash": "py-1"}}
+		]},
+		{"tool": {"driver": {"name": "Semgrep"}}, "results": []}
+	]}`
+
+	imp := NewSarifImport(nil, uuid.New(), "api", "")
+	var runs []*fakeRun
+	imp.createScan = func(*models.Scan) error { return nil }
+	imp.updateScan = func(*models.Scan) error { return nil }
+	imp.newRecorder = func(scan *models.Scan, seen map[string]bool) runRecorder {
+		run := &fakeRun{scan: scan, seen: seen, result: &ScanResult{Scan: scan}}
+		runs = append(runs, run)
+		return run
+	}
+
+	if err := ParseSarif(strings.NewReader(log), imp); err != nil {
+		t.Fatalf("ParseSarif: %v", err)
+	}
+	for _, run := range runs {
+		if run.finished != 0 {
+			t.Fatalf("%s run resolved before the upload was finished", run.scan.Tool)
+		}
+	}
+	results, err := imp.Finish()
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+
+	if len(runs) != 3 || len(results) != 3 {
+		t.Fatalf("got %d runs and %d results, want 3 each", len(runs), len(results))
+	}
+	first, second, semgrep := runs[0], runs[1], runs[2]
+	if first.finished != 0 || second.finished != 1 || semgrep.finished != 1 {
+		t.Errorf("finished = %d, %d, %d; want only the last run of each tool to resolve",
+			first.finished, second.finished, semgrep.finished)
+	}
+	// The resolving run must know what the earlier run of its tool recorded.
+	if len(second.seen) != 2 || !second.seen[first.recorded[0]] {
+		t.Errorf("codeql seen = %v, want both runs' fingerprints", second.seen)
+	}
+	if len(semgrep.seen) != 0 {
+		t.Errorf("semgrep seen = %v, want its own empty set", semgrep.seen)
+	}
+	for i, run := range runs {
+		if results[i].Scan != run.scan {
+			t.Errorf("result %d is not for run %d", i, i)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/findings/app/models"
+	"github.com/telhawk-systems/telhawk-stack/findings/config"
+)
+
+// OCSF finding classes the findings service publishes.
+const (
+	classVulnerabilityFinding      = 2002
+	classComplianceFinding         = 2003
+	classAppSecurityPostureFinding = 2007
+
+	categoryFindings = 2
+
+	activityCreate = 1
+	activityUpdate = 2
+	activityClose  = 3
+
+	findingStatusNew      = 1
+	findingStatusResolved = 4
+)
+
+// Forward splits findings into requests of at most hecMaxBatchEvents events
+// and hecMaxBatchBytes bytes. A request that fails with a 429, a 5xx or a
+// connection error is tried up to hecMaxAttempts times, waiting
+// hecRetryBackoff before the first retry and twice as long before each next.
+const (
+	hecMaxBatchEvents = 500
+	hecMaxBatchBytes  = 1 << 20
+	hecMaxAttempts    = 4
+	hecRetryBackoff   = 500 * time.Millisecond
+)
+
+var classNames = map[int]string{
+	classVulnerabilityFinding:      "vulnerability_finding",
+	classComplianceFinding:         "compliance_finding",
+	classAppSecurityPostureFinding: "application_security_posture_finding",
+}
+
+var severityIDs = map[string]int{
+	"info":     1,
+	"low":      2,
+	"medium":   3,
+	"high":     4,
+	"critical": 5,
+}
+
+// ForwardScan publishes the findings a scan created, reintroduced or
+// resolved to ingest as OCSF events. It runs in the background so that a
+// slow or unavailable SIEM never fails a scan upload; failures are logged.
+func ForwardScan(result *ScanResult) {
+	cfg := config.SIEM()
+	if cfg.HECURL == "" || result == nil || len(result.Changed) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+		defer cancel()
+
+		if err := NewHECForwarder(cfg).Forward(ctx, result.Scan, result.Changed); err != nil {
+			slog.Warn("failed to forward findings to SIEM",
+				"scan_id", result.Scan.ID,
+				"findings", len(result.Changed),
+				"error", err)
+		}
+	}()
+}
+
+// HECForwarder sends OCSF finding events to ingest's HEC endpoint. Events
+// use an "ocsf:<class>" sourcetype so ingest stores them as-is through its
+// OCSF passthrough normalizer.
+type HECForwarder struct {
+	url     string
+	token   string
+	index   string
+	client  *http.Client
+	backoff time.Duration
+}
+
+// NewHECForwarder creates a forwarder for the given SIEM configuration.
+func NewHECForwarder(cfg config.SIEMConfig) *HECForwarder {
+	url := strings.TrimRight(cfg.HECURL, "/")
+	if !strings.HasSuffix(url, "/services/collector/event") {
+		url += "/services/collector/event"
+	}
+	return &HECForwarder{
+		url:     url,
+		token:   cfg.HECToken,
+		index:   cfg.Index,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		backoff: hecRetryBackoff,
+	}
+}
+
+// Forward posts an event per finding as newline-delimited HEC batches. A
+// batch that cannot be delivered stops forwarding; earlier batches stay
+// delivered.
+func (f *HECForwarder) Forward(ctx context.Context, scan *models.Scan, findings []*models.Finding) error {
+	var batch bytes.Buffer
+	events := 0
+
+	for _, finding := range findings {
+		line, err := f.encodeEvent(scan, finding)
+		if err != nil {
+			return err
+		}
+		if events > 0 && (events == hecMaxBatchEvents || batch.Len()+len(line) > hecMaxBatchBytes) {
+			if err := f.post(ctx, batch.Bytes()); err != nil {
+				return err
+			}
+			batch.Reset()
+			events = 0
+		}
+		batch.Write(line)
+		events++
+	}
+
+	if events == 0 {
+		return nil
+	}
+	return f.post(ctx, batch.Bytes())
+}
+
+func (f *HECForwarder) encodeEvent(scan *models.Scan, finding *models.Finding) ([]byte, error) {
+	event := BuildOCSFFinding(scan, finding)
+	hecEvent := map[string]any{
+		"time":       float64(event["time"].(int64)) / 1000,
+		"host":       "findings",
+		"source":     "telhawk:findings:" + scan.Tool,
+		"sourcetype": "ocsf:" + classNames[event["class_uid"].(int)],
+		"event":      event,
+	}
+	if f.index != "" {
+		hecEvent["index"] = f.index
+	}
+	line, err := json.Marshal(hecEvent)
+	if err != nil {
+		return nil, fmt.Errorf("encode finding %s: %w", finding.ID, err)
+	}
+	return append(line, '\n'), nil
+}
+
+// post sends one batch, retrying rate limited and server errors.
+func (f *HECForwarder) post(ctx context.Context, body []byte) error {
+	backoff := f.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := f.postOnce(ctx, body)
+		if err == nil || !retry || attempt == hecMaxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (giving up: %w)", err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (f *HECForwarder) postOnce(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Splunk "+f.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("post to HEC: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("HEC returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return false, nil
+}
+
+// BuildOCSFFinding renders a stored finding as an OCSF finding event. The
+// class is chosen from the finding's signal type and category; project,
+// tool, fingerprint and lifecycle status travel in properties so detection
+// rules can join code-scan results with runtime events.
+func BuildOCSFFinding(scan *models.Scan, finding *models.Finding) map[string]any {
+	classUID := findingClass(finding)
+	activityID, statusID, status := findingActivity(finding)
+
+	severity := strings.ToLower(deref(finding.Severity))
+	severityID, ok := severityIDs[severity]
+	if !ok {
+		severityID = 0
+		severity = "unknown"
+	}
+
+	var data map[string]any
+	_ = json.Unmarshal(finding.Data, &data)
+
+	title := stringFrom(data, "message", "title", "rule_id")
+	if title == "" {
+		title = finding.SignalType
+	}
+
+	info := map[string]any{
+		"uid":             finding.ID.String(),
+		"title":           title,
+		"types":           []string{finding.SignalType},
+		"first_seen_time": finding.FirstSeen.UnixMilli(),
+		"last_seen_time":  finding.LastSeen.UnixMilli(),
+		"product_uid":     scan.Tool,
+	}
+	if desc := stringFrom(data, "description"); desc != "" {
+		info["desc"] = desc
+	}
+
+	properties := map[string]string{
+		"project":        finding.Project,
+		"tool":           finding.Tool,
+		"fingerprint":    finding.Fingerprint,
+		"signal_type":    finding.SignalType,
+		"finding_status": finding.Status,
+		"scan_id":        scan.ID.String(),
+	}
+	if c := deref(scan.CommitHash); c != "" {
+		properties["commit_hash"] = c
+	}
+	if c := deref(finding.Category); c != "" {
+		properties["category"] = c
+	}
+	if r := deref(finding.Route); r != "" {
+		properties["route"] = r
+	}
+	if finding.Line != nil && *finding.Line > 0 {
+		properties["line"] = strconv.Itoa(*finding.Line)
+	}
+
+	event := map[string]any{
+		"category_uid": categoryFindings,
+		"class_uid":    classUID,
+		"activity_id":  activityID,
+		"type_uid":     classUID*100 + activityID,
+		"time":         finding.LastSeen.UnixMilli(),
+		"severity_id":  severityID,
+		"severity":     titleCase(severity),
+		"status_id":    statusID,
+		"status":       status,
+		"metadata": map[string]any{
+			"version": "1.1.0",
+			"product": map[string]any{
+				"name":        scan.Tool,
+				"vendor_name": "TelHawk Systems",
+				"version":     deref(scan.ToolVersion),
+				"feature":     "findings",
+			},
+			"log_provider": "findings",
+		},
+		"finding_info": info,
+		"properties":   properties,
+	}
+
+	file := fileObject(finding)
+	if file != nil {
+		event["file"] = file
+	}
+
+	switch classUID {
+	case classVulnerabilityFinding:
+		vuln := map[string]any{
+			"title":           title,
+			"severity":        titleCase(severity),
+			"first_seen_time": finding.FirstSeen.UnixMilli(),
+			"last_seen_time":  finding.LastSeen.UnixMilli(),
+		}
+		if c := deref(finding.Category); c != "" {
+			vuln["category"] = c
+		}
+		if cve := stringFrom(data, "cve", "cve_id"); cve != "" {
+			vuln["cve"] = map[string]any{"uid": cve}
+		}
+		if file != nil {
+			code := map[string]any{"file": file}
+			if finding.Line != nil && *finding.Line > 0 {
+				code["start_line"] = *finding.Line
+			}
+			if rule := stringFrom(data, "rule_id"); rule != "" {
+				code["rule"] = map[string]any{"uid": rule, "name": rule}
+			}
+			vuln["affected_code"] = []any{code}
+		}
+		event["vulnerabilities"] = []any{vuln}
+	case classComplianceFinding:
+		compliance := map[string]any{
+			"control": stringFrom(data, "rule_id", "control"),
+			"status":  complianceStatus(finding),
+		}
+		if c := deref(finding.Category); c != "" {
+			compliance["category"] = c
+		}
+		event["compliance"] = compliance
+	case classAppSecurityPostureFinding:
+		if r := deref(finding.Route); r != "" {
+			event["application"] = map[string]any{"name": finding.Project, "uid": r}
+		}
+	}
+
+	return event
+}
+
+// findingClass maps a finding onto the closest OCSF finding class:
+// policy and configuration checks are compliance findings, route-level
+// findings without source locations describe application posture, and
+// everything else (code, dependency and secret scans) is a vulnerability.
+func findingClass(finding *models.Finding) int {
+	kind := strings.ToLower(finding.SignalType + " " + deref(finding.Category))
+	for _, marker := range []string{"compliance", "policy", "iac", "misconfig", "config"} {
+		if strings.Contains(kind, marker) {
+			return classComplianceFinding
+		}
+	}
+	if deref(finding.Route) != "" && deref(finding.FilePath) == "" {
+		return classAppSecurityPostureFinding
+	}
+	return classVulnerabilityFinding
+}
+
+func findingActivity(finding *models.Finding) (activityID, statusID int, status string) {
+	switch finding.Status {
+	case StatusResolved:
+		return activityClose, findingStatusResolved, "Resolved"
+	case StatusReintroduced:
+		return activityUpdate, findingStatusNew, "New"
+	default:
+		return activityCreate, findingStatusNew, "New"
+	}
+}
+
+func complianceStatus(finding *models.Finding) string {
+	if finding.Status == StatusResolved {
+		return "Pass"
+	}
+	return "Fail"
+}
+
+func fileObject(finding *models.Finding) map[string]any {
+	path := deref(finding.FilePath)
+	if path == "" {
+		return nil
+	}
+	name := path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		name = path[i+1:]
+	}
+	return map[string]any{"name": name, "path": path, "type_id": 1}
+}
+
+func stringFrom(data map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := data[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
+
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
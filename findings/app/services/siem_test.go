@@ -0,0 +1,275 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/telhawk-systems/telhawk-stack/findings/app/models"
+	"github.com/telhawk-systems/telhawk-stack/findings/config"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestFindingClass(t *testing.T) {
+	tests := []struct {
+		name    string
+		finding models.Finding
+		want    int
+	}{
+		{"sast", models.Finding{SignalType: "sast", Category: ptr("injection"), FilePath: ptr("src/db.js")}, classVulnerabilityFinding},
+		{"dependency", models.Finding{SignalType: "sca"}, classVulnerabilityFinding},
+		{"iac signal", models.Finding{SignalType: "iac", FilePath: ptr("main.tf")}, classComplianceFinding},
+		{"policy category", models.Finding{SignalType: "dast", Category: ptr("Policy")}, classComplianceFinding},
+		{"misconfiguration", models.Finding{SignalType: "cspm", Category: ptr("misconfiguration")}, classComplianceFinding},
+		{"route without file", models.Finding{SignalType: "dast", Route: ptr("/api/users")}, classAppSecurityPostureFinding},
+		{"route with file", models.Finding{SignalType: "dast", Route: ptr("/api/users"), FilePath: ptr("api.go")}, classVulnerabilityFinding},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findingClass(&tt.finding); got != tt.want {
+				t.Errorf("findingClass() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindingActivity(t *testing.T) {
+	tests := []struct {
+		status       string
+		wantActivity int
+		wantStatusID int
+		wantStatus   string
+	}{
+		{StatusOpen, activityCreate, findingStatusNew, "New"},
+		{StatusReintroduced, activityUpdate, findingStatusNew, "New"},
+		{StatusResolved, activityClose, findingStatusResolved, "Resolved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			activity, statusID, status := findingActivity(&models.Finding{Status: tt.status})
+			if activity != tt.wantActivity || statusID != tt.wantStatusID || status != tt.wantStatus {
+				t.Errorf("findingActivity() = %d, %d, %q; want %d, %d, %q",
+					activity, statusID, status, tt.wantActivity, tt.wantStatusID, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func testScan() *models.Scan {
+	return &models.Scan{ID: uuid.New(), Tool: "codeql", Project: "api", CommitHash: ptr("abc123"), ToolVersion: ptr("2.15.0")}
+}
+
+func testFinding(status string) *models.Finding {
+	seen := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	return &models.Finding{
+		ID:          uuid.New(),
+		Fingerprint: "fp-1",
+		Tool:        "codeql",
+		Project:     "api",
+		SignalType:  "sast",
+		Severity:    ptr("High"),
+		Category:    ptr("injection"),
+		FilePath:    ptr("src/db.js"),
+		Line:        ptr(12),
+		Data:        json.RawMessage(`{"message": "query built from input", "rule_id": "js/sql-injection"}`),
+		Status:      status,
+		FirstSeen:   seen.Add(-time.Hour),
+		LastSeen:    seen,
+	}
+}
+
+func TestBuildOCSFFinding(t *testing.T) {
+	scan := testScan()
+	finding := testFinding(StatusResolved)
+
+	event := BuildOCSFFinding(scan, finding)
+
+	if event["class_uid"] != classVulnerabilityFinding || event["type_uid"] != classVulnerabilityFinding*100+activityClose {
+		t.Errorf("class_uid = %v, type_uid = %v", event["class_uid"], event["type_uid"])
+	}
+	if event["severity_id"] != 4 || event["severity"] != "High" || event["status"] != "Resolved" {
+		t.Errorf("severity = %v %v, status = %v", event["severity_id"], event["severity"], event["status"])
+	}
+	if event["time"] != finding.LastSeen.UnixMilli() {
+		t.Errorf("time = %v, want last seen", event["time"])
+	}
+	info := event["finding_info"].(map[string]any)
+	if info["uid"] != finding.ID.String() || info["title"] != "query built from input" {
+		t.Errorf("finding_info = %v", info)
+	}
+	props := event["properties"].(map[string]string)
+	if props["fingerprint"] != "fp-1" || props["commit_hash"] != "abc123" || props["line"] != "12" || props["scan_id"] != scan.ID.String() {
+		t.Errorf("properties = %v", props)
+	}
+	vulns := event["vulnerabilities"].([]any)
+	code := vulns[0].(map[string]any)["affected_code"].([]any)[0].(map[string]any)
+	if code["start_line"] != 12 || code["file"].(map[string]any)["name"] != "db.js" {
+		t.Errorf("affected_code = %v", code)
+	}
+}
+
+func TestBuildOCSFFinding_UnknownSeverity(t *testing.T) {
+	finding := testFinding(StatusOpen)
+	finding.Severity = ptr("bogus")
+
+	event := BuildOCSFFinding(testScan(), finding)
+	if event["severity_id"] != 0 || event["severity"] != "Unknown" {
+		t.Errorf("severity = %v %v, want 0 Unknown", event["severity_id"], event["severity"])
+	}
+}
+
+// hecServer records the events of every request and answers with the
+// scripted statuses in turn, then 200.
+type hecServer struct {
+	mu       sync.Mutex
+	statuses []int
+	requests [][]map[string]any
+	auth     string
+}
+
+func (s *hecServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var events []map[string]any
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(nil, 4<<20)
+	for scanner.Scan() {
+		var event map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		events = append(events, event)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auth = r.Header.Get("Authorization")
+	s.requests = append(s.requests, events)
+	if len(s.statuses) > 0 {
+		status := s.statuses[0]
+		s.statuses = s.statuses[1:]
+		http.Error(w, http.StatusText(status), status)
+	}
+}
+
+func newTestForwarder(t *testing.T, hec *hecServer) *HECForwarder {
+	t.Helper()
+	server := httptest.NewServer(hec)
+	t.Cleanup(server.Close)
+
+	f := NewHECForwarder(config.SIEMConfig{HECURL: server.URL + "/", HECToken: "token", Index: "findings", Timeout: 5 * time.Second})
+	f.backoff = time.Millisecond
+	return f
+}
+
+func TestHECForwarder_Forward(t *testing.T) {
+	hec := &hecServer{}
+	f := newTestForwarder(t, hec)
+	scan := testScan()
+
+	if err := f.Forward(context.Background(), scan, []*models.Finding{testFinding(StatusOpen), testFinding(StatusResolved)}); err != nil {
+		t.Fatalf("Forward: %v", err)
+	}
+
+	if len(hec.requests) != 1 || len(hec.requests[0]) != 2 {
+		t.Fatalf("requests = %d, want one with 2 events", len(hec.requests))
+	}
+	if hec.auth != "Splunk token" {
+		t.Errorf("Authorization = %q", hec.auth)
+	}
+	event := hec.requests[0][0]
+	if event["sourcetype"] != "ocsf:vulnerability_finding" || event["index"] != "findings" || event["source"] != "telhawk:findings:codeql" {
+		t.Errorf("HEC envelope = %v", event)
+	}
+}
+
+func TestHECForwarder_Chunks(t *testing.T) {
+	t.Run("by event count", func(t *testing.T) {
+		hec := &hecServer{}
+		f := newTestForwarder(t, hec)
+
+		findings := make([]*models.Finding, hecMaxBatchEvents+1)
+		for i := range findings {
+			findings[i] = testFinding(StatusOpen)
+		}
+		if err := f.Forward(context.Background(), testScan(), findings); err != nil {
+			t.Fatalf("Forward: %v", err)
+		}
+		if len(hec.requests) != 2 || len(hec.requests[0]) != hecMaxBatchEvents || len(hec.requests[1]) != 1 {
+			t.Errorf("got %d requests, want %d events then 1", len(hec.requests), hecMaxBatchEvents)
+		}
+	})
+
+	t.Run("by size", func(t *testing.T) {
+		hec := &hecServer{}
+		f := newTestForwarder(t, hec)
+
+		description, _ := json.Marshal(strings.Repeat("x", hecMaxBatchBytes/3))
+		findings := make([]*models.Finding, 5)
+		for i := range findings {
+			findings[i] = testFinding(StatusOpen)
+			findings[i].Data = json.RawMessage(`{"description": ` + string(description) + `}`)
+		}
+		if err := f.Forward(context.Background(), testScan(), findings); err != nil {
+			t.Fatalf("Forward: %v", err)
+		}
+		total := 0
+		for _, events := range hec.requests {
+			total += len(events)
+		}
+		if len(hec.requests) != 3 || total != 5 {
+			t.Errorf("got %d requests with %d events, want 3 requests of at most 2", len(hec.requests), total)
+		}
+	})
+}
+
+func TestHECForwarder_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantRequests int
+	}{
+		{"server error then success", []int{http.StatusServiceUnavailable}, false, 2},
+		{"rate limited then success", []int{http.StatusTooManyRequests, http.StatusBadGateway}, false, 3},
+		{"client error is not retried", []int{http.StatusBadRequest}, true, 1},
+		{"gives up after max attempts", []int{500, 500, 500, 500, 500}, true, hecMaxAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hec := &hecServer{statuses: tt.statuses}
+			f := newTestForwarder(t, hec)
+
+			err := f.Forward(context.Background(), testScan(), []*models.Finding{testFinding(StatusOpen)})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Forward() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(hec.requests) != tt.wantRequests {
+				t.Errorf("got %d requests, want %d", len(hec.requests), tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestHECForwarder_StopsRetryingWhenCancelled(t *testing.T) {
+	hec := &hecServer{statuses: []int{503, 503, 503, 503}}
+	f := newTestForwarder(t, hec)
+	f.backoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := f.Forward(ctx, testScan(), []*models.Finding{testFinding(StatusOpen)}); err == nil {
+		t.Fatal("Forward() succeeded, want an error")
+	}
+	if len(hec.requests) != 1 {
+		t.Errorf("got %d requests, want 1", len(hec.requests))
+	}
+}
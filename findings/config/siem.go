@@ -0,0 +1,27 @@
+package config
+
+import "time"
+
+// SIEMConfig controls forwarding of findings into the TelHawk SIEM through
+// ingest's HTTP Event Collector. Forwarding is disabled when HECURL is empty.
+type SIEMConfig struct {
+	HECURL   string
+	HECToken string
+	Index    string
+	Timeout  time.Duration
+}
+
+// SIEM returns the findings-to-SIEM forwarding settings.
+func SIEM() SIEMConfig {
+	timeout, err := time.ParseDuration(Env("SIEM_HEC_TIMEOUT", "10s"))
+	if err != nil {
+		timeout = 10 * time.Second
+	}
+
+	return SIEMConfig{
+		HECURL:   Env("SIEM_HEC_URL", ""),
+		HECToken: Env("SIEM_HEC_TOKEN", ""),
+		Index:    Env("SIEM_HEC_INDEX", ""),
+		Timeout:  timeout,
+	}
+}
@@ -0,0 +1,27 @@
+package migrations
+
+type AddLifecycleToFindingsTable_2026_04_05_120000 struct {
+	Migration
+}
+
+func (m *AddLifecycleToFindingsTable_2026_04_05_120000) Up() {
+	m.AlterTable("findings", func(t *Table) {
+		t.String("project", 255).NotNull().Default("''")
+		t.String("status", 20).NotNull().Default("'open'")
+		t.Integer("reintroduced_count").NotNull().Default("0")
+		t.Timestamp("resolved_at").Nullable()
+	})
+
+	m.AddIndex("findings", "tool", "project", "status")
+}
+
+func (m *AddLifecycleToFindingsTable_2026_04_05_120000) Down() {
+	m.DropIndex("findings", "tool", "project", "status")
+
+	m.AlterTable("findings", func(t *Table) {
+		t.DropColumn("resolved_at")
+		t.DropColumn("reintroduced_count")
+		t.DropColumn("status")
+		t.DropColumn("project")
+	})
+}
@@ -0,0 +1,23 @@
+package migrations
+
+type CreateFindingEventsTable_2026_04_05_120001 struct {
+	Migration
+}
+
+func (m *CreateFindingEventsTable_2026_04_05_120001) Up() {
+	m.CreateTable("finding_events", func(t *Table) {
+		t.UUID("id").PrimaryKey().Default("gen_random_uuid()")
+		t.UUID("finding_id").NotNull().ForeignKey("findings", "id")
+		t.UUID("scan_id").NotNull().ForeignKey("scans", "id")
+		t.String("event", 20).NotNull()
+		t.String("commit_hash", 64).Nullable()
+		t.Timestamps()
+	})
+
+	m.AddIndex("finding_events", "finding_id")
+	m.AddIndex("finding_events", "scan_id")
+}
+
+func (m *CreateFindingEventsTable_2026_04_05_120001) Down() {
+	m.DropTableIfExists("finding_events")
+}
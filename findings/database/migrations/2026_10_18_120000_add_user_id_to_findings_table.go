@@ -0,0 +1,30 @@
+package migrations
+
+type AddUserIDToFindingsTable_2026_10_18_120000 struct {
+	Migration
+}
+
+// Up gives each finding an owner so that users scanning the same code keep
+// separate findings and histories. Existing findings belong to the owner of
+// the scan that last saw them.
+func (m *AddUserIDToFindingsTable_2026_10_18_120000) Up() {
+	m.AlterTable("findings", func(t *Table) {
+		t.UUID("user_id").Nullable().ForeignKey("users", "id")
+	})
+
+	m.Exec(`UPDATE findings SET user_id = scans.user_id FROM scans WHERE scans.id = findings.scan_id`)
+
+	m.DropIndex("findings", "fingerprint", "tool")
+	m.AddUniqueIndex("findings", "user_id", "fingerprint", "tool")
+	m.AddIndex("findings", "user_id", "tool", "project", "status")
+}
+
+func (m *AddUserIDToFindingsTable_2026_10_18_120000) Down() {
+	m.DropIndex("findings", "user_id", "tool", "project", "status")
+	m.DropIndex("findings", "user_id", "fingerprint", "tool")
+	m.AddUniqueIndex("findings", "fingerprint", "tool")
+
+	m.AlterTable("findings", func(t *Table) {
+		t.DropColumn("user_id")
+	})
+}
@@ -10,10 +10,13 @@ var API = pickle.Routes(func(r *pickle.Router) {
 	r.Group("/api/v1", func(r *pickle.Router) {
 		r.Get("/scans", controllers.ScanController{}.Index)
 		r.Post("/scans", controllers.ScanController{}.Store)
+		r.Post("/scans/sarif", controllers.ScanController{}.StoreSarif)
+		r.Get("/scans/trend", controllers.ScanController{}.Trend)
 		r.Get("/scans/:id", controllers.ScanController{}.Show)
 		r.Delete("/scans/:id", controllers.ScanController{}.Destroy)
 
 		r.Get("/findings", controllers.FindingController{}.Index)
+		r.Get("/findings/summary", controllers.FindingController{}.Summary)
 		r.Get("/findings/:id", controllers.FindingController{}.Show)
 	}, middleware.Auth)
 })
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -14,20 +15,27 @@ import (
 
 	"github.com/telhawk-systems/telhawk-stack/common/config"
 	"github.com/telhawk-systems/telhawk-stack/common/hecstats"
+	"github.com/telhawk-systems/telhawk-stack/common/indices"
 	"github.com/telhawk-systems/telhawk-stack/common/logging"
 	natsclient "github.com/telhawk-systems/telhawk-stack/common/messaging/nats"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/ack"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/authclient"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/dlq"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/esbulk"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/handlers"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/normalizer"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/normalizer/generated"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/otlp"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/pipeline"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/ratelimit"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/server"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/service"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/storage"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/tail"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/transform"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/validator"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/parsing"
+	"github.com/telhawk-systems/telhawk-stack/search/pkg/model"
 )
 
 func main() {
@@ -116,7 +124,7 @@ func main() {
 	}
 
 	// Initialize Dead Letter Queue
-	var dlqWriter dlq.Writer
+	var dlqStore dlq.Store
 	if cfg.Ingest.DLQ.Enabled {
 		switch cfg.Ingest.DLQ.Backend {
 		case "jetstream", "":
@@ -131,7 +139,7 @@ func main() {
 			if err != nil {
 				log.Fatalf("Failed to initialize JetStream DLQ: %v", err)
 			}
-			dlqWriter = jsDLQ
+			dlqStore = jsDLQ
 			log.Printf("Dead Letter Queue enabled (backend: jetstream, nats: %s)", cfg.Ingest.DLQ.NatsURL)
 		case "file":
 			// File backend (single instance only, for development)
@@ -139,7 +147,7 @@ func main() {
 			if err != nil {
 				log.Fatalf("Failed to initialize file DLQ: %v", err)
 			}
-			dlqWriter = fileDLQ
+			dlqStore = fileDLQ
 			log.Printf("Dead Letter Queue enabled (backend: file, path: %s)", cfg.Ingest.DLQ.BasePath)
 			log.Println("WARNING: File-based DLQ does not support multiple ingest instances")
 		default:
@@ -155,6 +163,32 @@ func main() {
 		&normalizer.OCSFPassthroughNormalizer{},
 	}
 
+	// Declarative mappings take precedence over the generated normalizers so
+	// that a source can be added or fixed without regenerating code
+	if cfg.Ingest.Mappings.Dir != "" {
+		mappingNormalizer, err := normalizer.NewMappingNormalizer(cfg.Ingest.Mappings.Dir)
+		if err != nil {
+			log.Fatalf("Invalid normalizer mappings in %s: %v", cfg.Ingest.Mappings.Dir, err)
+		}
+		normalizers = append(normalizers, mappingNormalizer)
+		log.Printf("Loaded %d normalizer mappings from %s", mappingNormalizer.Len(), cfg.Ingest.Mappings.Dir)
+
+		if cfg.Ingest.Mappings.ReloadInterval > 0 {
+			watchCtx, stopWatch := context.WithCancel(context.Background())
+			defer stopWatch()
+			go mappingNormalizer.Watch(watchCtx, cfg.Ingest.Mappings.ReloadInterval)
+		}
+	}
+
+	// Network sensors whose logs span several OCSF classes
+	normalizers = append(normalizers, &normalizer.ZeekNormalizer{}, &normalizer.SuricataNormalizer{})
+
+	// Cloud and SaaS audit logs
+	normalizers = append(normalizers, &normalizer.CloudTrailNormalizer{}, &normalizer.OktaNormalizer{}, &normalizer.GitHubNormalizer{})
+
+	// Endpoint process, file and registry telemetry
+	normalizers = append(normalizers, &normalizer.SysmonNormalizer{}, &normalizer.AuditdNormalizer{})
+
 	// Add all generated normalizers (77 normalizers for OCSF event classes)
 	normalizers = append(normalizers, generated.AllNormalizers()...)
 
@@ -181,6 +215,12 @@ func main() {
 	authClient := authclient.New(cfg.Ingest.Authenticate.URL, 5*time.Second, cfg.Ingest.Authenticate.TokenValidationCacheTTL)
 
 	// Initialize direct OpenSearch client (replaces storage service)
+	if err := indices.ValidateRoutingMode(cfg.OpenSearch.RoutingMode); err != nil {
+		log.Fatalf("Invalid OpenSearch configuration: %v", err)
+	}
+	if len(cfg.OpenSearch.Lifecycle.ClientRetention) > 0 && cfg.OpenSearch.RoutingMode != indices.RoutingClient {
+		log.Fatalf("Invalid OpenSearch configuration: lifecycle.client_retention requires routing_mode %q", indices.RoutingClient)
+	}
 	openSearchConfig := storage.Config{
 		URL:             cfg.OpenSearch.URL,
 		Username:        cfg.OpenSearch.Username,
@@ -193,6 +233,8 @@ func main() {
 		RetentionDays:   cfg.OpenSearch.RetentionDays,
 		RolloverSizeGB:  cfg.OpenSearch.RolloverSizeGB,
 		RolloverAge:     cfg.OpenSearch.RolloverAge,
+		Lifecycle:       newLifecycleConfig(cfg.OpenSearch.Lifecycle),
+		RoutingMode:     cfg.OpenSearch.RoutingMode,
 	}
 
 	storageClient, err := storage.NewClient(openSearchConfig)
@@ -209,16 +251,84 @@ func main() {
 	cancel()
 
 	// Initialize ingestion service with pipeline
-	ingestService := service.NewIngestService(normalizationPipeline, dlqWriter, storageClient, authClient)
+	ingestService := service.NewIngestService(normalizationPipeline, dlqStore, storageClient, authClient)
 
 	// Configure ack manager if enabled
 	if ackManager != nil {
 		ingestService.SetAckManager(ackManager)
 	}
 
+	// Sourcetype parsing profiles for the raw endpoint
+	if len(cfg.Ingest.Parsing.Profiles) > 0 {
+		parsers, err := parsing.NewSet(newParsingProfiles(cfg.Ingest.Parsing.Profiles))
+		if err != nil {
+			log.Fatalf("Invalid parsing profiles: %v", err)
+		}
+		ingestService.SetParsingProfiles(parsers)
+		log.Printf("Loaded %d raw parsing profiles", parsers.Len())
+	}
+
+	// Per-client transform rules, applied to normalized events before storage
+	var transformHandler *handlers.TransformRuleHandler
+	if cfg.Ingest.Transforms.Enabled {
+		transforms, err := newTransformEngine(cfg)
+		if err != nil {
+			log.Fatalf("Failed to initialize transform rules: %v", err)
+		}
+		ingestService.SetTransforms(transforms)
+		transformHandler = handlers.NewTransformRuleHandler(transforms, authClient)
+
+		if cfg.Ingest.Transforms.RefreshInterval > 0 {
+			refreshCtx, stopRefresh := context.WithCancel(context.Background())
+			defer stopRefresh()
+			go transforms.Watch(refreshCtx, cfg.Ingest.Transforms.RefreshInterval)
+		}
+	} else {
+		log.Println("Transform rules disabled")
+	}
+
+	// Initialize live tail publishing (best effort: ingestion continues
+	// without it)
+	if cfg.Ingest.Tail.Enabled && cfg.NATS.Enabled {
+		tailPublisher, err := newTailPublisher(cfg)
+		if err != nil {
+			log.Printf("Warning: Live tail disabled: %v", err)
+		} else {
+			ingestService.SetTailPublisher(tailPublisher)
+			defer tailPublisher.Close()
+			log.Printf("Live tail publishing enabled (nats: %s, sample rate: %g)", cfg.NATS.URL, cfg.Ingest.Tail.SampleRate)
+		}
+	} else {
+		log.Println("Live tail publishing disabled")
+	}
+
 	// Initialize HTTP handlers
 	handler := handlers.NewHECHandler(ingestService, rateLimiter, statsCollector)
-	router := server.NewRouter(handler)
+	handler.SetMaxBodySize(cfg.Ingest.Ingestion.MaxBodySize)
+	handler.SetAllowQueryStringToken(cfg.Ingest.Ingestion.AllowQueryStringToken)
+	handler.SetOTLPConverter(otlp.NewConverter(cfg.Ingest.OTLP.RouteAttribute, cfg.Ingest.OTLP.DefaultSourceType))
+	bulkRoutes := make([]esbulk.Route, 0, len(cfg.Ingest.Bulk.IndexRoutes))
+	for _, route := range cfg.Ingest.Bulk.IndexRoutes {
+		bulkRoutes = append(bulkRoutes, esbulk.Route{Pattern: route.Pattern, SourceType: route.SourceType})
+	}
+	bulkRouter, err := esbulk.NewRouter(bulkRoutes)
+	if err != nil {
+		log.Fatalf("Invalid bulk index routes: %v", err)
+	}
+	handler.SetBulkRouter(bulkRouter)
+	dlqHandler := handlers.NewDLQHandler(service.NewDLQService(dlqStore, ingestService), authClient)
+	var tenantHandler *handlers.TenantHandler
+	if cfg.OpenSearch.RoutingMode == indices.RoutingClient {
+		tenantHandler = handlers.NewTenantHandler(storageClient, authClient)
+	}
+	router := server.NewRouterWithConfig(server.RouterConfig{
+		HEC:            handler,
+		DLQ:            dlqHandler,
+		Tenants:        tenantHandler,
+		TransformRules: transformHandler,
+		OTLPLogs:       cfg.Ingest.OTLP.Enabled,
+		Bulk:           cfg.Ingest.Bulk.Enabled,
+	})
 
 	// Create server with config values
 	srv := &http.Server{
@@ -252,3 +362,105 @@ func main() {
 
 	log.Println("Server stopped")
 }
+
+// newLifecycleConfig maps the ISM tier configuration onto the storage client's.
+func newLifecycleConfig(lc config.LifecycleConfig) storage.LifecycleConfig {
+	out := storage.LifecycleConfig{
+		Warm: storage.WarmTier{
+			AfterDays:          lc.Warm.AfterDays,
+			Replicas:           lc.Warm.Replicas,
+			ForceMergeSegments: lc.Warm.ForceMergeSegments,
+		},
+		Cold: storage.ColdTier{
+			AfterDays:      lc.Cold.AfterDays,
+			NodeAttribute:  lc.Cold.NodeAttribute,
+			AttributeValue: lc.Cold.AttributeValue,
+			Replicas:       lc.Cold.Replicas,
+		},
+		Snapshot: storage.SnapshotConfig{Repository: lc.SnapshotRepository},
+	}
+	for _, cr := range lc.ClientRetention {
+		out.ClientRetention = append(out.ClientRetention, storage.ClientRetention{
+			ClientID:      cr.ClientID,
+			RetentionDays: cr.RetentionDays,
+		})
+	}
+	return out
+}
+
+// newParsingProfiles maps the configured parsing profiles onto the parser's.
+func newParsingProfiles(profiles []config.ParsingProfileConfig) []parsing.Profile {
+	out := make([]parsing.Profile, 0, len(profiles))
+	for _, p := range profiles {
+		out = append(out, parsing.Profile{
+			SourceType:            p.SourceType,
+			LineBreaker:           p.LineBreaker,
+			ShouldLineMerge:       p.ShouldLineMerge,
+			BreakOnlyBefore:       p.BreakOnlyBefore,
+			MaxEventLines:         p.MaxEventLines,
+			TimePrefix:            p.TimePrefix,
+			TimeFormat:            p.TimeFormat,
+			Timezone:              p.Timezone,
+			MaxTimestampLookahead: p.MaxTimestampLookahead,
+			Extractions:           p.Extractions,
+			KeyValue:              p.KeyValue,
+		})
+	}
+	return out
+}
+
+// newTransformEngine loads the transform rules from Redis, or keeps them in
+// memory when Redis is disabled.
+func newTransformEngine(cfg *config.Config) (*transform.Engine, error) {
+	var store transform.Store
+	if cfg.Redis.Enabled {
+		redisStore, err := transform.NewRedisStore(cfg.Redis.URL)
+		if err != nil {
+			return nil, err
+		}
+		store = redisStore
+		log.Printf("Transform rules enabled (store: redis)")
+	} else {
+		store = transform.NewMemoryStore()
+		log.Println("Transform rules enabled (store: memory)")
+		log.Println("WARNING: In-memory transform rules are lost on restart and not shared between ingest instances")
+	}
+
+	engine := transform.NewEngine(store, cfg.Ingest.Transforms.HashKey)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := engine.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// newTailPublisher connects to NATS and creates the live tail publisher.
+// An invalid tail filter is a configuration error and stops the service.
+func newTailPublisher(cfg *config.Config) (*tail.Publisher, error) {
+	tailCfg := tail.Config{
+		SampleRate:  cfg.Ingest.Tail.SampleRate,
+		SourceTypes: cfg.Ingest.Tail.SourceTypes,
+		BufferSize:  cfg.Ingest.Tail.BufferSize,
+	}
+	if cfg.Ingest.Tail.Filter != "" {
+		var filter model.FilterExpr
+		if err := json.Unmarshal([]byte(cfg.Ingest.Tail.Filter), &filter); err != nil {
+			log.Fatalf("Invalid live tail filter: %v", err)
+		}
+		matcher, err := model.CompileFilter(&filter)
+		if err != nil {
+			log.Fatalf("Invalid live tail filter: %v", err)
+		}
+		tailCfg.Filter = matcher
+	}
+
+	natsCfg := natsclient.DefaultConfig()
+	natsCfg.URL = cfg.NATS.URL
+	natsCfg.Name = "telhawk-ingest-tail"
+	client, err := natsclient.NewClient(natsCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS at %s: %w", cfg.NATS.URL, err)
+	}
+	return tail.NewPublisher(client, tailCfg), nil
+}
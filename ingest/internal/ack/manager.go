@@ -21,6 +21,11 @@ type Ack struct {
 	Status    Status
 	Timestamp time.Time
 	EventIDs  []string
+
+	// Batch acks complete once sealed and every event has completed
+	batch   bool
+	sealed  bool
+	pending int
 }
 
 type Manager struct {
@@ -63,17 +68,62 @@ func (m *Manager) Create(eventIDs []string) string {
 	return ackID
 }
 
-// Complete marks an acknowledgement as successful
+// CreateBatch creates an acknowledgement shared by the events of one
+// request, as Splunk acknowledges a HEC batch with a single ID. Events join
+// with Add, and the ack succeeds once Seal has been called and every event
+// has completed. A failed event fails the whole batch.
+func (m *Manager) CreateBatch() string {
+	ackID := m.Create(nil)
+
+	m.mu.Lock()
+	m.acks[ackID].batch = true
+	m.mu.Unlock()
+
+	return ackID
+}
+
+// Add adds an event to a batch acknowledgement
+func (m *Manager) Add(ackID, eventID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ack, exists := m.acks[ackID]; exists && ack.batch && !ack.sealed {
+		ack.EventIDs = append(ack.EventIDs, eventID)
+		ack.pending++
+	}
+}
+
+// Seal marks a batch acknowledgement as having all its events, completing
+// it if they have all completed already
+func (m *Manager) Seal(ackID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ack, exists := m.acks[ackID]; exists && ack.batch && !ack.sealed {
+		ack.sealed = true
+		if ack.pending == 0 {
+			m.finish(ack, StatusSuccess)
+		}
+	}
+}
+
+// Complete marks an acknowledgement as successful. For a batch, it marks
+// one of its events as successful.
 func (m *Manager) Complete(ackID string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if ack, exists := m.acks[ackID]; exists {
-		ack.Status = StatusSuccess
-		ack.Timestamp = time.Now()
-		metrics.AcksPending.Dec()
-		metrics.AcksCompleted.Inc()
+	ack, exists := m.acks[ackID]
+	if !exists {
+		return
 	}
+	if ack.batch {
+		ack.pending--
+		if ack.pending > 0 || !ack.sealed {
+			return
+		}
+	}
+	m.finish(ack, StatusSuccess)
 }
 
 // Fail marks an acknowledgement as failed
@@ -82,9 +132,21 @@ func (m *Manager) Fail(ackID string) {
 	defer m.mu.Unlock()
 
 	if ack, exists := m.acks[ackID]; exists {
-		ack.Status = StatusFailed
-		ack.Timestamp = time.Now()
-		metrics.AcksPending.Dec()
+		m.finish(ack, StatusFailed)
+	}
+}
+
+// finish moves a pending acknowledgement to its final status. Callers hold
+// the lock.
+func (m *Manager) finish(ack *Ack, status Status) {
+	if ack.Status != StatusPending {
+		return
+	}
+	ack.Status = status
+	ack.Timestamp = time.Now()
+	metrics.AcksPending.Dec()
+	if status == StatusSuccess {
+		metrics.AcksCompleted.Inc()
 	}
 }
 
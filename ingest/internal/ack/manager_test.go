@@ -135,6 +135,69 @@ func TestFail_NonExistentAck(t *testing.T) {
 	manager.Fail("nonexistent-ack-id")
 }
 
+func TestBatch(t *testing.T) {
+	manager := NewManager(10 * time.Minute)
+	defer manager.Close()
+
+	ackID := manager.CreateBatch()
+	manager.Add(ackID, "event-1")
+	manager.Add(ackID, "event-2")
+
+	// Completed events don't complete an unsealed batch
+	manager.Complete(ackID)
+	manager.Complete(ackID)
+	if manager.Query([]string{ackID})[ackID] {
+		t.Error("unsealed batch reported as complete")
+	}
+
+	manager.Add(ackID, "event-3")
+	manager.Seal(ackID)
+	if manager.Query([]string{ackID})[ackID] {
+		t.Error("batch complete before its last event")
+	}
+
+	manager.Complete(ackID)
+	if !manager.Query([]string{ackID})[ackID] {
+		t.Error("batch not complete after all events completed")
+	}
+	if count := manager.GetPending(); count != 0 {
+		t.Errorf("GetPending() = %d, want 0", count)
+	}
+}
+
+func TestBatch_SealAfterEventsComplete(t *testing.T) {
+	manager := NewManager(10 * time.Minute)
+	defer manager.Close()
+
+	ackID := manager.CreateBatch()
+	manager.Add(ackID, "event-1")
+	manager.Complete(ackID)
+	manager.Seal(ackID)
+
+	if !manager.Query([]string{ackID})[ackID] {
+		t.Error("batch not complete after sealing")
+	}
+}
+
+func TestBatch_FailedEventFailsBatch(t *testing.T) {
+	manager := NewManager(10 * time.Minute)
+	defer manager.Close()
+
+	ackID := manager.CreateBatch()
+	manager.Add(ackID, "event-1")
+	manager.Add(ackID, "event-2")
+	manager.Seal(ackID)
+	manager.Fail(ackID)
+	manager.Complete(ackID)
+
+	manager.mu.RLock()
+	status := manager.acks[ackID].Status
+	manager.mu.RUnlock()
+	if status != StatusFailed {
+		t.Errorf("ack.Status = %v, want %v", status, StatusFailed)
+	}
+}
+
 func TestQuery(t *testing.T) {
 	manager := NewManager(10 * time.Minute)
 	defer manager.Close()
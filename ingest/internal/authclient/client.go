@@ -28,6 +28,19 @@ type ValidateHECTokenResponse struct {
 	ClientID  string `json:"client_id,omitempty"` // Client for data isolation
 }
 
+type ValidateTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// ValidateTokenResponse is the authenticate service's answer for a user access token.
+type ValidateTokenResponse struct {
+	Valid          bool     `json:"valid"`
+	UserID         string   `json:"user_id,omitempty"`
+	Roles          []string `json:"roles,omitempty"`
+	OrganizationID string   `json:"organization_id,omitempty"`
+	ClientID       string   `json:"client_id,omitempty"`
+}
+
 type tokenCache struct {
 	mu      sync.RWMutex
 	entries map[string]*cacheEntry
@@ -107,6 +120,42 @@ func (c *Client) ValidateHECToken(ctx context.Context, token string) (*ValidateH
 	return &result, nil
 }
 
+// ValidateToken validates a user access token (JWT) for management endpoints.
+// Results are not cached so revocations take effect immediately.
+func (c *Client) ValidateToken(ctx context.Context, token string) (*ValidateTokenResponse, error) {
+	if c == nil {
+		return nil, fmt.Errorf("auth client not configured")
+	}
+
+	bodyBytes, err := json.Marshal(ValidateTokenRequest{Token: token})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/auth/validate", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &ValidateTokenResponse{Valid: false}, nil
+	}
+
+	var result ValidateTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
 func (tc *tokenCache) get(token string) *ValidateHECTokenResponse {
 	tc.mu.RLock()
 	defer tc.mu.RUnlock()
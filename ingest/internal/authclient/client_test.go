@@ -137,6 +137,59 @@ func TestValidateHECToken_NilClient(t *testing.T) {
 	}
 }
 
+func TestValidateToken_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/auth/validate" {
+			t.Errorf("Expected path /api/v1/auth/validate, got %s", r.URL.Path)
+		}
+
+		var req ValidateTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.Token != "access-token" {
+			t.Errorf("Expected token access-token, got %s", req.Token)
+		}
+
+		json.NewEncoder(w).Encode(ValidateTokenResponse{
+			Valid:  true,
+			UserID: "user-123",
+			Roles:  []string{"admin"},
+		})
+	}))
+	defer server.Close()
+
+	client := New(server.URL, 5*time.Second, 1*time.Minute)
+
+	result, err := client.ValidateToken(context.Background(), "access-token")
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if !result.Valid || result.UserID != "user-123" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(result.Roles) != 1 || result.Roles[0] != "admin" {
+		t.Errorf("Roles = %v, want [admin]", result.Roles)
+	}
+}
+
+func TestValidateToken_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := New(server.URL, 5*time.Second, 1*time.Minute)
+
+	result, err := client.ValidateToken(context.Background(), "expired-token")
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if result.Valid {
+		t.Error("Expected Valid = false when authenticate rejects the token")
+	}
+}
+
 func TestValidateHECToken_ServerError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
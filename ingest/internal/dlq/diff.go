@@ -0,0 +1,66 @@
+package dlq
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// FieldChange describes one field that differs between two normalized events.
+type FieldChange struct {
+	Path   string      `json:"path"`
+	Op     string      `json:"op"` // added, removed, changed
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Diff compares two normalized events field by field using dotted paths.
+// A nil before map reports every field of after as added.
+func Diff(before, after map[string]interface{}) []FieldChange {
+	flatBefore := make(map[string]interface{})
+	flatAfter := make(map[string]interface{})
+	flatten("", before, flatBefore)
+	flatten("", after, flatAfter)
+
+	var changes []FieldChange
+	for path, a := range flatAfter {
+		b, existed := flatBefore[path]
+		switch {
+		case !existed:
+			changes = append(changes, FieldChange{Path: path, Op: "added", After: a})
+		case !reflect.DeepEqual(a, b):
+			changes = append(changes, FieldChange{Path: path, Op: "changed", Before: b, After: a})
+		}
+	}
+	for path, b := range flatBefore {
+		if _, exists := flatAfter[path]; !exists {
+			changes = append(changes, FieldChange{Path: path, Op: "removed", Before: b})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// flatten writes leaf values of nested maps into out keyed by dotted path.
+// Arrays are indexed as path[i].
+func flatten(prefix string, value interface{}, out map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flatten(path, child, out)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), child, out)
+		}
+	default:
+		if prefix != "" {
+			out[prefix] = v
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
 )
 
@@ -20,12 +21,33 @@ type Writer interface {
 
 // FailedEvent captures normalization failure details for replay.
 type FailedEvent struct {
+	ID          string                   `json:"id"`
 	Timestamp   time.Time                `json:"timestamp"`
 	Envelope    *models.RawEventEnvelope `json:"envelope"`
 	Error       string                   `json:"error"`
 	Reason      string                   `json:"reason"`
 	Attempts    int                      `json:"attempts"`
 	LastAttempt time.Time                `json:"last_attempt"`
+	Status      string                   `json:"status,omitempty"`
+	Replays     []ReplayAttempt          `json:"replays,omitempty"`
+
+	file string // file backend: path of the entry on disk
+	seq  uint64 // jetstream backend: stream sequence of the entry
+}
+
+// newFailedEvent builds a pending DLQ entry for an envelope that failed processing.
+func newFailedEvent(envelope *models.RawEventEnvelope, err error, reason string) FailedEvent {
+	now := time.Now().UTC()
+	return FailedEvent{
+		ID:          uuid.NewString(),
+		Timestamp:   now,
+		Envelope:    envelope,
+		Error:       err.Error(),
+		Reason:      reason,
+		Attempts:    1,
+		LastAttempt: now,
+		Status:      StatusPending,
+	}
 }
 
 // Queue writes failed normalization events to disk for later analysis/replay.
@@ -59,14 +81,7 @@ func (q *Queue) Write(ctx context.Context, envelope *models.RawEventEnvelope, er
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	failed := FailedEvent{
-		Timestamp:   time.Now().UTC(),
-		Envelope:    envelope,
-		Error:       err.Error(),
-		Reason:      reason,
-		Attempts:    1,
-		LastAttempt: time.Now().UTC(),
-	}
+	failed := newFailedEvent(envelope, err, reason)
 
 	// Create timestamped filename
 	filename := fmt.Sprintf("failed_%d_%d.json",
@@ -3,11 +3,13 @@ package dlq
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync/atomic"
 	"time"
 
+	natsgo "github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/telhawk-systems/telhawk-stack/common/messaging/nats"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
@@ -47,14 +49,7 @@ func (q *JetStreamQueue) Write(ctx context.Context, envelope *models.RawEventEnv
 		return nil
 	}
 
-	failed := FailedEvent{
-		Timestamp:   time.Now().UTC(),
-		Envelope:    envelope,
-		Error:       err.Error(),
-		Reason:      reason,
-		Attempts:    1,
-		LastAttempt: time.Now().UTC(),
-	}
+	failed := newFailedEvent(envelope, err, reason)
 
 	data, marshalErr := json.Marshal(failed)
 	if marshalErr != nil {
@@ -166,3 +161,197 @@ func (q *JetStreamQueue) Purge(ctx context.Context) error {
 	log.Printf("DLQ: purged all messages from stream")
 	return nil
 }
+
+// readAll loads every entry in the DLQ stream using an ephemeral ordered consumer.
+func (q *JetStreamQueue) readAll(ctx context.Context) ([]FailedEvent, error) {
+	info, err := q.stream.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get dlq stream info: %w", err)
+	}
+	if info.State.Msgs == 0 {
+		return nil, nil
+	}
+
+	consumer, err := q.stream.OrderedConsumer(ctx, jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{"ingest.dlq.>"},
+		DeliverPolicy:  jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create read consumer: %w", err)
+	}
+
+	var events []FailedEvent
+	for {
+		msgs, err := consumer.Fetch(256, jetstream.FetchMaxWait(2*time.Second))
+		if err != nil {
+			return nil, fmt.Errorf("fetch messages: %w", err)
+		}
+
+		received := 0
+		var pending uint64
+		for msg := range msgs.Messages() {
+			received++
+			meta, err := msg.Metadata()
+			if err != nil {
+				continue
+			}
+			pending = meta.NumPending
+
+			var failed FailedEvent
+			if err := json.Unmarshal(msg.Data(), &failed); err != nil {
+				log.Printf("ERROR: failed to parse DLQ message %d: %v", meta.Sequence.Stream, err)
+				continue
+			}
+			// Entries written before IDs existed are addressed by sequence
+			if failed.ID == "" {
+				failed.ID = fmt.Sprintf("seq-%d", meta.Sequence.Stream)
+			}
+			failed.seq = meta.Sequence.Stream
+			events = append(events, failed)
+		}
+		if msgs.Error() != nil && !errors.Is(msgs.Error(), natsgo.ErrTimeout) {
+			log.Printf("WARN: fetch completed with error: %v", msgs.Error())
+		}
+
+		if received == 0 || pending == 0 {
+			break
+		}
+	}
+
+	return events, nil
+}
+
+func (q *JetStreamQueue) find(ctx context.Context, id string) (*FailedEvent, error) {
+	events, err := q.readAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		if events[i].ID == id {
+			return &events[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Query returns entries matching the filter, oldest first.
+func (q *JetStreamQueue) Query(ctx context.Context, filter Filter) ([]FailedEvent, error) {
+	if q == nil {
+		return nil, fmt.Errorf("dlq not enabled")
+	}
+
+	events, err := q.readAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return applyFilter(events, filter), nil
+}
+
+// Get returns a single entry by ID.
+func (q *JetStreamQueue) Get(ctx context.Context, id string) (*FailedEvent, error) {
+	if q == nil {
+		return nil, fmt.Errorf("dlq not enabled")
+	}
+	return q.find(ctx, id)
+}
+
+// Update replaces an entry. Stream messages are immutable, so the updated
+// entry is republished under the same ID and the old message is deleted.
+// Entries returned by Query and Get carry their stream sequence, so updating
+// them does not read the stream again.
+func (q *JetStreamQueue) Update(ctx context.Context, event *FailedEvent) error {
+	if q == nil {
+		return fmt.Errorf("dlq not enabled")
+	}
+
+	seq := event.seq
+	if seq == 0 {
+		existing, err := q.find(ctx, event.ID)
+		if err != nil {
+			return err
+		}
+		seq = existing.seq
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal dlq entry: %w", err)
+	}
+	ack, err := q.js.PublishSync(ctx, fmt.Sprintf("ingest.dlq.%s", event.Reason), data)
+	if err != nil {
+		return fmt.Errorf("publish dlq entry: %w", err)
+	}
+	if err := q.stream.DeleteMsg(ctx, seq); err != nil {
+		// The entry was removed or replaced since it was read; drop the copy
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			if delErr := q.stream.DeleteMsg(ctx, ack.Sequence); delErr != nil {
+				log.Printf("ERROR: failed to delete DLQ message %d: %v", ack.Sequence, delErr)
+			}
+			return ErrNotFound
+		}
+		return fmt.Errorf("delete superseded dlq entry: %w", err)
+	}
+	event.seq = ack.Sequence
+	return nil
+}
+
+// Remove deletes a single entry by ID.
+func (q *JetStreamQueue) Remove(ctx context.Context, id string) error {
+	if q == nil {
+		return fmt.Errorf("dlq not enabled")
+	}
+
+	existing, err := q.find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := q.stream.DeleteMsg(ctx, existing.seq); err != nil {
+		return fmt.Errorf("delete dlq entry: %w", err)
+	}
+	return nil
+}
+
+// PurgeMatching deletes every entry matching the filter and returns how many were removed.
+func (q *JetStreamQueue) PurgeMatching(ctx context.Context, filter Filter) (int, error) {
+	if q == nil {
+		return 0, fmt.Errorf("dlq not enabled")
+	}
+
+	// Whole-stream purge is a single server-side operation
+	if filter.IsZero() {
+		info, err := q.stream.Info(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("get dlq stream info: %w", err)
+		}
+		if err := q.stream.Purge(ctx); err != nil {
+			return 0, fmt.Errorf("purge dlq stream: %w", err)
+		}
+		log.Printf("DLQ: purged %d events from stream", info.State.Msgs)
+		return int(info.State.Msgs), nil
+	}
+
+	events, err := q.readAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for i := range events {
+		if !filter.Matches(&events[i]) {
+			continue
+		}
+		if err := q.stream.DeleteMsg(ctx, events[i].seq); err != nil {
+			log.Printf("ERROR: failed to delete DLQ message %d: %v", events[i].seq, err)
+			continue
+		}
+		deleted++
+	}
+
+	log.Printf("DLQ: purged %d events from stream", deleted)
+	return deleted, nil
+}
+
+// Info returns DLQ metrics from JetStream.
+func (q *JetStreamQueue) Info(ctx context.Context) map[string]interface{} {
+	return q.Stats(ctx)
+}
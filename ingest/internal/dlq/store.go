@@ -0,0 +1,339 @@
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a DLQ entry does not exist.
+var ErrNotFound = errors.New("dlq event not found")
+
+// Entry statuses.
+const (
+	StatusPending  = "pending"  // Waiting for replay
+	StatusReplayed = "replayed" // Successfully reprocessed and stored
+)
+
+// maxReplayHistory bounds the replay attempts kept on an entry.
+const maxReplayHistory = 10
+
+// ReplayAttempt records the outcome of reprocessing a DLQ entry.
+type ReplayAttempt struct {
+	At      time.Time              `json:"at"`
+	DryRun  bool                   `json:"dry_run"`
+	Success bool                   `json:"success"`
+	Error   string                 `json:"error,omitempty"`
+	Event   map[string]interface{} `json:"event,omitempty"` // Normalized output, used to diff later attempts
+}
+
+// RecordReplay appends a replay attempt to the entry. Non-dry-run attempts
+// count towards Attempts, and a successful one marks the entry replayed.
+func (e *FailedEvent) RecordReplay(attempt ReplayAttempt) {
+	e.Replays = append(e.Replays, attempt)
+	if len(e.Replays) > maxReplayHistory {
+		e.Replays = e.Replays[len(e.Replays)-maxReplayHistory:]
+	}
+	if attempt.DryRun {
+		return
+	}
+	e.Attempts++
+	e.LastAttempt = attempt.At
+	if attempt.Success {
+		e.Status = StatusReplayed
+	}
+}
+
+// LastOutput returns the most recent replay attempt that recorded a
+// normalized output, or nil if no replay has produced one.
+func (e *FailedEvent) LastOutput() *ReplayAttempt {
+	for i := len(e.Replays) - 1; i >= 0; i-- {
+		if e.Replays[i].Event != nil {
+			return &e.Replays[i]
+		}
+	}
+	return nil
+}
+
+// Attribute returns an envelope attribute, or "" if absent.
+func (e *FailedEvent) Attribute(key string) string {
+	if e.Envelope == nil {
+		return ""
+	}
+	return e.Envelope.Attributes[key]
+}
+
+// SourceType returns the sourcetype of the failed envelope.
+func (e *FailedEvent) SourceType() string {
+	if e.Envelope == nil {
+		return ""
+	}
+	return e.Envelope.SourceType
+}
+
+// Filter selects DLQ entries. Zero-valued fields match everything.
+type Filter struct {
+	IDs        []string // Entry IDs; empty matches any ID
+	Reason     string
+	SourceType string
+	TokenID    string // HEC token ID the event was ingested with
+	ClientID   string
+	Status     string
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+}
+
+// IsZero reports whether the filter matches every entry.
+func (f Filter) IsZero() bool {
+	return len(f.IDs) == 0 && f.Reason == "" && f.SourceType == "" && f.TokenID == "" && f.ClientID == "" &&
+		f.Status == "" && f.Since.IsZero() && f.Until.IsZero()
+}
+
+// Matches reports whether the entry satisfies the filter (Limit is ignored).
+func (f Filter) Matches(e *FailedEvent) bool {
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, e.ID) {
+		return false
+	}
+	if f.Reason != "" && e.Reason != f.Reason {
+		return false
+	}
+	if f.SourceType != "" && e.SourceType() != f.SourceType {
+		return false
+	}
+	if f.TokenID != "" && e.Attribute("hec_token_id") != f.TokenID {
+		return false
+	}
+	if f.ClientID != "" && e.Attribute("client_id") != f.ClientID {
+		return false
+	}
+	if f.Status != "" && e.status() != f.Status {
+		return false
+	}
+	if !f.Since.IsZero() && e.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Timestamp.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// status treats entries written before statuses existed as pending.
+func (e *FailedEvent) status() string {
+	if e.Status == "" {
+		return StatusPending
+	}
+	return e.Status
+}
+
+// applyFilter filters entries ordered oldest first and applies the limit.
+func applyFilter(events []FailedEvent, f Filter) []FailedEvent {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	var matched []FailedEvent
+	for i := range events {
+		if !f.Matches(&events[i]) {
+			continue
+		}
+		matched = append(matched, events[i])
+		if f.Limit > 0 && len(matched) >= f.Limit {
+			break
+		}
+	}
+	return matched
+}
+
+// Store is a DLQ backend that supports inspection and replay.
+// Implemented by Queue (file) and JetStreamQueue.
+type Store interface {
+	Writer
+	Query(ctx context.Context, filter Filter) ([]FailedEvent, error)
+	Get(ctx context.Context, id string) (*FailedEvent, error)
+	Update(ctx context.Context, event *FailedEvent) error
+	Remove(ctx context.Context, id string) error
+	PurgeMatching(ctx context.Context, filter Filter) (int, error)
+	Info(ctx context.Context) map[string]interface{}
+}
+
+var (
+	_ Store = (*Queue)(nil)
+	_ Store = (*JetStreamQueue)(nil)
+)
+
+// readAllLocked loads every entry from disk. Caller must hold q.mu.
+func (q *Queue) readAllLocked() ([]FailedEvent, error) {
+	files, err := os.ReadDir(q.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("read dlq directory: %w", err)
+	}
+
+	var events []FailedEvent
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		filePath := filepath.Join(q.basePath, file.Name())
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			log.Printf("ERROR: failed to read DLQ file %s: %v", file.Name(), err)
+			continue
+		}
+
+		var failed FailedEvent
+		if err := json.Unmarshal(data, &failed); err != nil {
+			log.Printf("ERROR: failed to parse DLQ file %s: %v", file.Name(), err)
+			continue
+		}
+
+		// Entries written before IDs existed are addressed by file name
+		if failed.ID == "" {
+			failed.ID = strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		}
+		failed.file = filePath
+		events = append(events, failed)
+	}
+
+	return events, nil
+}
+
+// findLocked returns the entry with the given ID. Caller must hold q.mu.
+func (q *Queue) findLocked(id string) (*FailedEvent, error) {
+	events, err := q.readAllLocked()
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		if events[i].ID == id {
+			return &events[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Query returns entries matching the filter, oldest first.
+func (q *Queue) Query(ctx context.Context, filter Filter) ([]FailedEvent, error) {
+	if q == nil {
+		return nil, fmt.Errorf("dlq not enabled")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events, err := q.readAllLocked()
+	if err != nil {
+		return nil, err
+	}
+	return applyFilter(events, filter), nil
+}
+
+// Get returns a single entry by ID.
+func (q *Queue) Get(ctx context.Context, id string) (*FailedEvent, error) {
+	if q == nil {
+		return nil, fmt.Errorf("dlq not enabled")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.findLocked(id)
+}
+
+// Update rewrites an existing entry (e.g. after recording a replay attempt).
+func (q *Queue) Update(ctx context.Context, event *FailedEvent) error {
+	if q == nil {
+		return fmt.Errorf("dlq not enabled")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Entries returned by Query and Get know their file; others are looked up
+	path := event.file
+	if path == "" {
+		existing, err := q.findLocked(event.ID)
+		if err != nil {
+			return err
+		}
+		path = existing.file
+	} else if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	data, err := json.MarshalIndent(event, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal dlq entry: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write dlq entry: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes a single entry by ID.
+func (q *Queue) Remove(ctx context.Context, id string) error {
+	if q == nil {
+		return fmt.Errorf("dlq not enabled")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	existing, err := q.findLocked(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(existing.file); err != nil {
+		return fmt.Errorf("delete dlq file: %w", err)
+	}
+	log.Printf("DLQ: deleted %s", filepath.Base(existing.file))
+	return nil
+}
+
+// PurgeMatching deletes every entry matching the filter and returns how many were removed.
+func (q *Queue) PurgeMatching(ctx context.Context, filter Filter) (int, error) {
+	if q == nil {
+		return 0, fmt.Errorf("dlq not enabled")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events, err := q.readAllLocked()
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for i := range events {
+		if !filter.Matches(&events[i]) {
+			continue
+		}
+		if err := os.Remove(events[i].file); err != nil {
+			log.Printf("ERROR: failed to delete DLQ file %s: %v", filepath.Base(events[i].file), err)
+			continue
+		}
+		deleted++
+	}
+
+	log.Printf("DLQ: purged %d events", deleted)
+	return deleted, nil
+}
+
+// Info returns DLQ metrics.
+func (q *Queue) Info(ctx context.Context) map[string]interface{} {
+	stats := q.Stats()
+	stats["backend"] = "file"
+	return stats
+}
@@ -0,0 +1,206 @@
+package dlq_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/dlq"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+func writeTestEvent(t *testing.T, queue *dlq.Queue, sourceType, tokenID, reason string) {
+	t.Helper()
+	envelope := &models.RawEventEnvelope{
+		ID:         "evt-" + sourceType,
+		Format:     "json",
+		SourceType: sourceType,
+		Source:     "test-source",
+		Payload:    []byte(`{"user":"alice"}`),
+		Attributes: map[string]string{
+			"hec_token_id": tokenID,
+			"client_id":    "client-1",
+		},
+		ReceivedAt: time.Now(),
+	}
+	require.NoError(t, queue.Write(context.Background(), envelope, errors.New("boom"), reason))
+}
+
+func TestQueue_Write_AssignsIDAndStatus(t *testing.T) {
+	queue, err := dlq.NewQueue(t.TempDir())
+	require.NoError(t, err)
+
+	writeTestEvent(t, queue, "json", "tok-1", "normalization_failed")
+
+	events, err := queue.Query(context.Background(), dlq.Filter{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.NotEmpty(t, events[0].ID)
+	assert.Equal(t, dlq.StatusPending, events[0].Status)
+}
+
+func TestQueue_Query_Filters(t *testing.T) {
+	queue, err := dlq.NewQueue(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	writeTestEvent(t, queue, "json", "tok-1", "normalization_failed")
+	writeTestEvent(t, queue, "syslog", "tok-1", "normalization_failed")
+	writeTestEvent(t, queue, "json", "tok-2", "storage_failed")
+
+	all, err := queue.Query(ctx, dlq.Filter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+
+	tests := []struct {
+		name   string
+		filter dlq.Filter
+		want   int
+	}{
+		{"no filter", dlq.Filter{}, 3},
+		{"by reason", dlq.Filter{Reason: "storage_failed"}, 1},
+		{"by sourcetype", dlq.Filter{SourceType: "json"}, 2},
+		{"by token", dlq.Filter{TokenID: "tok-1"}, 2},
+		{"by client", dlq.Filter{ClientID: "client-1"}, 3},
+		{"by status", dlq.Filter{Status: dlq.StatusReplayed}, 0},
+		{"since future", dlq.Filter{Since: time.Now().Add(time.Hour)}, 0},
+		{"until future", dlq.Filter{Until: time.Now().Add(time.Hour)}, 3},
+		{"combined", dlq.Filter{SourceType: "json", TokenID: "tok-1"}, 1},
+		{"limit", dlq.Filter{Limit: 2}, 2},
+		{"by ids", dlq.Filter{IDs: []string{all[0].ID, all[2].ID, "missing"}}, 2},
+		{"ids and client", dlq.Filter{IDs: []string{all[0].ID}, ClientID: "client-2"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := queue.Query(ctx, tt.filter)
+			require.NoError(t, err)
+			assert.Len(t, events, tt.want)
+		})
+	}
+}
+
+func TestQueue_GetUpdateRemove(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := dlq.NewQueue(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	writeTestEvent(t, queue, "json", "tok-1", "normalization_failed")
+	events, err := queue.Query(ctx, dlq.Filter{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	id := events[0].ID
+
+	event, err := queue.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "json", event.SourceType())
+	assert.Equal(t, "tok-1", event.Attribute("hec_token_id"))
+
+	event.RecordReplay(dlq.ReplayAttempt{At: time.Now(), Success: true, Event: map[string]interface{}{"class_uid": 3002}})
+	require.NoError(t, queue.Update(ctx, event))
+
+	updated, err := queue.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, dlq.StatusReplayed, updated.Status)
+	assert.Equal(t, 2, updated.Attempts)
+	require.Len(t, updated.Replays, 1)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "update must rewrite the existing file")
+
+	require.NoError(t, queue.Remove(ctx, id))
+	_, err = queue.Get(ctx, id)
+	assert.ErrorIs(t, err, dlq.ErrNotFound)
+	assert.ErrorIs(t, queue.Update(ctx, updated), dlq.ErrNotFound, "updating a removed entry must not recreate it")
+	assert.ErrorIs(t, queue.Remove(ctx, id), dlq.ErrNotFound)
+}
+
+func TestQueue_PurgeMatching(t *testing.T) {
+	queue, err := dlq.NewQueue(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	writeTestEvent(t, queue, "json", "tok-1", "normalization_failed")
+	writeTestEvent(t, queue, "syslog", "tok-1", "normalization_failed")
+	writeTestEvent(t, queue, "json", "tok-2", "storage_failed")
+
+	deleted, err := queue.PurgeMatching(ctx, dlq.Filter{SourceType: "json"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	remaining, err := queue.Query(ctx, dlq.Filter{})
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "syslog", remaining[0].SourceType())
+}
+
+func TestFailedEvent_RecordReplay(t *testing.T) {
+	t.Run("dry run does not count as attempt", func(t *testing.T) {
+		event := &dlq.FailedEvent{Attempts: 1, Status: dlq.StatusPending}
+		event.RecordReplay(dlq.ReplayAttempt{At: time.Now(), DryRun: true, Success: true})
+
+		assert.Equal(t, 1, event.Attempts)
+		assert.Equal(t, dlq.StatusPending, event.Status)
+		assert.Len(t, event.Replays, 1)
+	})
+
+	t.Run("failed replay stays pending", func(t *testing.T) {
+		event := &dlq.FailedEvent{Attempts: 1, Status: dlq.StatusPending}
+		event.RecordReplay(dlq.ReplayAttempt{At: time.Now(), Error: "still broken"})
+
+		assert.Equal(t, 2, event.Attempts)
+		assert.Equal(t, dlq.StatusPending, event.Status)
+	})
+
+	t.Run("history is bounded", func(t *testing.T) {
+		event := &dlq.FailedEvent{}
+		for i := 0; i < 25; i++ {
+			event.RecordReplay(dlq.ReplayAttempt{At: time.Now(), DryRun: true})
+		}
+		assert.Len(t, event.Replays, 10)
+	})
+
+	t.Run("last output skips attempts without event", func(t *testing.T) {
+		event := &dlq.FailedEvent{}
+		event.RecordReplay(dlq.ReplayAttempt{DryRun: true, Event: map[string]interface{}{"a": 1}})
+		event.RecordReplay(dlq.ReplayAttempt{DryRun: true, Error: "failed"})
+
+		last := event.LastOutput()
+		require.NotNil(t, last)
+		assert.Equal(t, map[string]interface{}{"a": 1}, last.Event)
+	})
+}
+
+func TestDiff(t *testing.T) {
+	before := map[string]interface{}{
+		"class_uid": 3002,
+		"user":      map[string]interface{}{"name": "alice", "uid": "1"},
+		"tags":      []interface{}{"a", "b"},
+	}
+	after := map[string]interface{}{
+		"class_uid": 3002,
+		"user":      map[string]interface{}{"name": "bob"},
+		"tags":      []interface{}{"a", "b", "c"},
+		"severity":  "high",
+	}
+
+	changes := dlq.Diff(before, after)
+
+	assert.Equal(t, []dlq.FieldChange{
+		{Path: "severity", Op: "added", After: "high"},
+		{Path: "tags[2]", Op: "added", After: "c"},
+		{Path: "user.name", Op: "changed", Before: "alice", After: "bob"},
+		{Path: "user.uid", Op: "removed", Before: "1"},
+	}, changes)
+
+	t.Run("nil before reports all fields added", func(t *testing.T) {
+		changes := dlq.Diff(nil, map[string]interface{}{"a": 1})
+		assert.Equal(t, []dlq.FieldChange{{Path: "a", Op: "added", After: 1}}, changes)
+	})
+}
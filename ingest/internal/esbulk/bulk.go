@@ -0,0 +1,199 @@
+// Package esbulk implements the subset of the Elasticsearch bulk API used by
+// log shippers (Filebeat, Fluent Bit, Vector, Logstash): NDJSON request
+// parsing, index to sourcetype routing and bulk response bodies.
+package esbulk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+)
+
+// Bulk actions.
+const (
+	ActionIndex  = "index"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Operation is one action/source pair from a bulk request.
+type Operation struct {
+	Action string
+	Index  string // From the action metadata, or the index in the request path
+	ID     string
+	Source []byte // Nil for delete
+}
+
+// ParseError is a malformed request; Elasticsearch rejects the whole request.
+type ParseError struct {
+	Line   int
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("Malformed action/metadata line [%d], %s", e.Line, e.Reason)
+}
+
+// Parse reads NDJSON action/source pairs. defaultIndex is used for actions
+// without _index (the {index}/_bulk form).
+func Parse(body []byte, defaultIndex string) ([]Operation, error) {
+	lines := bytes.Split(body, []byte("\n"))
+
+	var ops []Operation
+	for i := 0; i < len(lines); i++ {
+		line := bytes.TrimSpace(lines[i])
+		if len(line) == 0 {
+			continue
+		}
+		lineNo := i + 1
+
+		var action map[string]struct {
+			Index string `json:"_index"`
+			ID    string `json:"_id"`
+		}
+		if err := json.Unmarshal(line, &action); err != nil {
+			return nil, &ParseError{Line: lineNo, Reason: "expected a simple value for field [action] but found invalid JSON"}
+		}
+		if len(action) != 1 {
+			return nil, &ParseError{Line: lineNo, Reason: fmt.Sprintf("expected exactly one action but found [%d]", len(action))}
+		}
+
+		var op Operation
+		for name, meta := range action {
+			op = Operation{Action: name, Index: meta.Index, ID: meta.ID}
+		}
+		if op.Index == "" {
+			op.Index = defaultIndex
+		}
+
+		switch op.Action {
+		case ActionIndex, ActionCreate, ActionUpdate:
+			// The next line is the document (or partial document for update)
+			i++
+			for i < len(lines) && len(bytes.TrimSpace(lines[i])) == 0 {
+				i++
+			}
+			if i >= len(lines) {
+				return nil, &ParseError{Line: lineNo, Reason: "expected a source line after the action line"}
+			}
+			op.Source = bytes.TrimSpace(lines[i])
+		case ActionDelete:
+		default:
+			return nil, &ParseError{Line: lineNo, Reason: fmt.Sprintf("expected one of [create, delete, index, update] but found [%s]", op.Action)}
+		}
+
+		ops = append(ops, op)
+	}
+
+	return ops, nil
+}
+
+// Route maps indices matching Pattern (path.Match syntax, e.g. "filebeat-*")
+// to a sourcetype.
+type Route struct {
+	Pattern    string `mapstructure:"pattern"`
+	SourceType string `mapstructure:"sourcetype"`
+}
+
+// Router resolves an index name to the sourcetype used for normalizer selection.
+type Router struct {
+	routes []Route
+}
+
+// NewRouter validates the route patterns. Routes are evaluated in order.
+func NewRouter(routes []Route) (*Router, error) {
+	for _, r := range routes {
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid index pattern %q: %w", r.Pattern, err)
+		}
+		if r.SourceType == "" {
+			return nil, fmt.Errorf("index pattern %q has no sourcetype", r.Pattern)
+		}
+	}
+	return &Router{routes: routes}, nil
+}
+
+// dateSuffix matches the date shippers append to daily index names
+// (filebeat-8.17.0-2026.01.02, logs-2026-01).
+var dateSuffix = regexp.MustCompile(`-\d{4}[.-]\d{2}([.-]\d{2})?$`)
+
+// SourceType returns the sourcetype of the first matching route. When no
+// route matches, the index name without its date suffix is the sourcetype,
+// so daily indices of one shipper share a normalizer and DLQ filters.
+func (r *Router) SourceType(index string) string {
+	if r != nil {
+		for _, route := range r.routes {
+			if ok, _ := path.Match(route.Pattern, index); ok {
+				return route.SourceType
+			}
+		}
+	}
+	return dateSuffix.ReplaceAllString(index, "")
+}
+
+// Response is the body of a bulk response.
+type Response struct {
+	Took   int64                    `json:"took"`
+	Errors bool                     `json:"errors"`
+	Items  []map[string]*ItemResult `json:"items"`
+}
+
+// ItemResult is the per-operation result, keyed by action in Response.Items.
+type ItemResult struct {
+	Index       string      `json:"_index"`
+	ID          string      `json:"_id"`
+	Version     int         `json:"_version,omitempty"`
+	Result      string      `json:"result,omitempty"`
+	Shards      *ShardInfo  `json:"_shards,omitempty"`
+	SeqNo       *int64      `json:"_seq_no,omitempty"`
+	PrimaryTerm int         `json:"_primary_term,omitempty"`
+	Status      int         `json:"status"`
+	Error       *ErrorCause `json:"error,omitempty"`
+}
+
+// ShardInfo mirrors the _shards object of a successful item.
+type ShardInfo struct {
+	Total      int `json:"total"`
+	Successful int `json:"successful"`
+	Failed     int `json:"failed"`
+}
+
+// ErrorCause is an Elasticsearch error object.
+type ErrorCause struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// ErrorResponse is a request-level Elasticsearch error.
+type ErrorResponse struct {
+	Error  ErrorCause `json:"error"`
+	Status int        `json:"status"`
+}
+
+// Created returns a successful item result.
+func Created(index, id string) *ItemResult {
+	seqNo := int64(0)
+	return &ItemResult{
+		Index:       index,
+		ID:          id,
+		Version:     1,
+		Result:      "created",
+		Shards:      &ShardInfo{Total: 1, Successful: 1},
+		SeqNo:       &seqNo,
+		PrimaryTerm: 1,
+		Status:      201,
+	}
+}
+
+// Failed returns an item result with an error.
+func Failed(index, id string, status int, errType, reason string) *ItemResult {
+	return &ItemResult{
+		Index:  index,
+		ID:     id,
+		Status: status,
+		Error:  &ErrorCause{Type: errType, Reason: reason},
+	}
+}
@@ -0,0 +1,82 @@
+package esbulk_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/esbulk"
+)
+
+func TestParse(t *testing.T) {
+	body := `{"index":{"_index":"logs-app","_id":"1"}}
+{"message":"one"}
+
+{"create":{}}
+{"message":"two"}
+{"delete":{"_index":"logs-app","_id":"1"}}
+{"update":{"_id":"2"}}
+{"doc":{"message":"three"}}`
+
+	ops, err := esbulk.Parse([]byte(body), "default-index")
+	require.NoError(t, err)
+	require.Len(t, ops, 4)
+
+	assert.Equal(t, esbulk.Operation{Action: "index", Index: "logs-app", ID: "1", Source: []byte(`{"message":"one"}`)}, ops[0])
+	assert.Equal(t, "create", ops[1].Action)
+	assert.Equal(t, "default-index", ops[1].Index)
+	assert.Equal(t, `{"message":"two"}`, string(ops[1].Source))
+	assert.Equal(t, "delete", ops[2].Action)
+	assert.Nil(t, ops[2].Source)
+	assert.Equal(t, "update", ops[3].Action)
+	assert.Equal(t, `{"doc":{"message":"three"}}`, string(ops[3].Source))
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		line int
+	}{
+		{"invalid json", "{\"index\":{}}\n{}\nnot json\n", 3},
+		{"unknown action", `{"upsert":{}}` + "\n{}\n", 1},
+		{"two actions", `{"index":{},"create":{}}` + "\n{}\n", 1},
+		{"missing source", `{"index":{}}` + "\n", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := esbulk.Parse([]byte(tt.body), "")
+			var parseErr *esbulk.ParseError
+			require.ErrorAs(t, err, &parseErr)
+			assert.Equal(t, tt.line, parseErr.Line)
+		})
+	}
+}
+
+func TestRouter_SourceType(t *testing.T) {
+	router, err := esbulk.NewRouter([]esbulk.Route{
+		{Pattern: "filebeat-*", SourceType: "hec"},
+		{Pattern: "logs-okta*", SourceType: "okta"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "hec", router.SourceType("filebeat-8.17.0-2026.01.02"))
+	assert.Equal(t, "okta", router.SourceType("logs-okta.system-default"))
+	assert.Equal(t, "nginx", router.SourceType("nginx"), "unrouted index is its own sourcetype")
+	assert.Equal(t, "nginx", router.SourceType("nginx-2026.01.02"), "daily suffix is not part of the sourcetype")
+	assert.Equal(t, "app-logs", router.SourceType("app-logs-2026-01"))
+	assert.Equal(t, "metricbeat-8.17.0", router.SourceType("metricbeat-8.17.0-2026.01.02"))
+
+	var nilRouter *esbulk.Router
+	assert.Equal(t, "nginx", nilRouter.SourceType("nginx"))
+}
+
+func TestNewRouter_Invalid(t *testing.T) {
+	_, err := esbulk.NewRouter([]esbulk.Route{{Pattern: "[", SourceType: "x"}})
+	assert.Error(t, err)
+
+	_, err = esbulk.NewRouter([]esbulk.Route{{Pattern: "logs-*"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/telhawk-systems/telhawk-stack/common/httputil"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/esbulk"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/metrics"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/service"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/hec"
+)
+
+const (
+	// maxBulkBodySize bounds the decompressed size of a bulk request
+	// (Elasticsearch's default http.max_content_length).
+	maxBulkBodySize = 100 << 20
+
+	// esCompatVersion is the Elasticsearch version reported to shippers that
+	// probe the cluster before sending (Filebeat refuses older versions).
+	esCompatVersion = "8.17.0"
+)
+
+// SetBulkRouter configures how bulk index names map to sourcetypes.
+func (h *HECHandler) SetBulkRouter(router *esbulk.Router) {
+	h.bulkRouter = router
+}
+
+// HandleBulk handles POST/PUT /_bulk and /{index}/_bulk (Elasticsearch bulk
+// API). Shippers authenticate with a HEC token as the basic auth password,
+// an ApiKey header, or the usual HEC Authorization schemes. The documents
+// are normalized and stored in one storage request before the per-item
+// results are reported: documents that cannot be normalized get a 400 item
+// (and land in the DLQ), storage failures get a retryable 503 item.
+func (h *HECHandler) HandleBulk(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	sourceIP := httputil.GetClientIP(r)
+
+	// Apply IP-based rate limiting BEFORE expensive operations
+	if h.rateLimiter != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+		defer cancel()
+
+		allowed, err := h.rateLimiter.Allow(ctx, "ip:"+sourceIP)
+		if err != nil {
+			log.Printf("rate limit check error: %v", err)
+		} else if !allowed {
+			metrics.EventsTotal.WithLabelValues("bulk", "rate_limited").Inc()
+			h.sendBulkError(w, http.StatusTooManyRequests, "es_rejected_execution_exception", "rate limit exceeded")
+			return
+		}
+	}
+
+	token := extractBulkToken(r)
+	if token == "" {
+		h.sendBulkError(w, http.StatusUnauthorized, "security_exception", "missing authentication credentials for REST request [/_bulk]")
+		return
+	}
+
+	tokenInfo, err := h.service.ValidateHECToken(r.Context(), token)
+	if err != nil {
+		log.Printf("HEC token validation failed: %v", err)
+		h.sendBulkError(w, http.StatusUnauthorized, "security_exception", "unable to authenticate with provided credentials")
+		return
+	}
+
+	// Optional: Apply per-token rate limiting after authentication
+	if h.rateLimiter != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+		defer cancel()
+
+		allowed, err := h.rateLimiter.Allow(ctx, "token:"+token)
+		if err != nil {
+			log.Printf("rate limit check error: %v", err)
+		} else if !allowed {
+			metrics.EventsTotal.WithLabelValues("bulk", "token_rate_limited").Inc()
+			h.sendBulkError(w, http.StatusTooManyRequests, "es_rejected_execution_exception", "rate limit exceeded")
+			return
+		}
+	}
+
+	body, err := readRequestBody(r, maxBulkBodySize)
+	if err != nil {
+		if errors.Is(err, errRequestTooLarge) {
+			h.sendBulkError(w, http.StatusRequestEntityTooLarge, "content_too_long_exception", err.Error())
+			return
+		}
+		h.sendBulkError(w, http.StatusBadRequest, "parse_exception", err.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	ops, err := esbulk.Parse(body, r.PathValue("index"))
+	if err != nil {
+		h.sendBulkError(w, http.StatusBadRequest, "illegal_argument_exception", err.Error())
+		return
+	}
+	if len(ops) == 0 {
+		h.sendBulkError(w, http.StatusBadRequest, "action_request_validation_exception", "Validation Failed: 1: no requests added;")
+		return
+	}
+
+	results := h.ingestBulkOperations(r.Context(), ops, sourceIP, tokenInfo)
+
+	resp := esbulk.Response{Items: make([]map[string]*esbulk.ItemResult, 0, len(ops))}
+	var accepted int64
+	for i, op := range ops {
+		if results[i].Error != nil {
+			resp.Errors = true
+		} else {
+			accepted++
+		}
+		resp.Items = append(resp.Items, map[string]*esbulk.ItemResult{op.Action: results[i]})
+	}
+
+	if h.statsCollector != nil && tokenInfo != nil && accepted > 0 {
+		h.statsCollector.Record(tokenInfo.TokenID, accepted, net.ParseIP(sourceIP))
+	}
+
+	resp.Took = time.Since(start).Milliseconds()
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	httputil.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ingestBulkOperations ingests the documents of a bulk request in one
+// storage request and maps each outcome to Elasticsearch item semantics:
+// 4xx items are dropped by shippers (or sent to their own DLQ), 429/5xx
+// items are retried.
+func (h *HECHandler) ingestBulkOperations(ctx context.Context, ops []esbulk.Operation, sourceIP string, tokenInfo *service.TokenInfo) []*esbulk.ItemResult {
+	results := make([]*esbulk.ItemResult, len(ops))
+	ids := make([]string, len(ops))
+	var docs []service.Document
+	var docOps []int
+
+	for i, op := range ops {
+		// Documents without an _id get one, reported back and stored under it
+		ids[i] = op.ID
+		if ids[i] == "" {
+			ids[i] = uuid.New().String()
+		}
+
+		switch {
+		case op.Action == esbulk.ActionUpdate || op.Action == esbulk.ActionDelete:
+			metrics.EventsTotal.WithLabelValues("bulk", "unsupported_action").Inc()
+			results[i] = esbulk.Failed(op.Index, ids[i], http.StatusBadRequest, "action_request_validation_exception",
+				fmt.Sprintf("bulk action [%s] is not supported; use index or create", op.Action))
+		case op.Index == "":
+			results[i] = esbulk.Failed(op.Index, ids[i], http.StatusBadRequest, "action_request_validation_exception", "index is missing")
+		default:
+			docs = append(docs, service.Document{
+				ID:         ids[i],
+				Create:     op.Action == esbulk.ActionCreate,
+				Index:      op.Index,
+				SourceType: h.bulkRouter.SourceType(op.Index),
+				Source:     op.Source,
+			})
+			docOps = append(docOps, i)
+		}
+	}
+
+	if len(docs) == 0 {
+		return results
+	}
+
+	errs := h.service.IngestDocuments(ctx, docs, sourceIP, tokenInfo)
+	for j, i := range docOps {
+		op := ops[i]
+		err := errs[j]
+		switch {
+		case err == nil:
+			results[i] = esbulk.Created(op.Index, ids[i])
+		case errors.Is(err, service.ErrDocumentExists):
+			results[i] = esbulk.Failed(op.Index, ids[i], http.StatusConflict, "version_conflict_engine_exception",
+				fmt.Sprintf("[%s]: version conflict, document already exists (current version [1])", ids[i]))
+		case errors.Is(err, service.ErrInvalidDocument), errors.Is(err, service.ErrNormalizationFailed):
+			results[i] = esbulk.Failed(op.Index, ids[i], http.StatusBadRequest, "document_parsing_exception", err.Error())
+		default:
+			log.Printf("failed to ingest bulk document for index %s: %v", op.Index, err)
+			results[i] = esbulk.Failed(op.Index, ids[i], http.StatusServiceUnavailable, "unavailable_shards_exception", err.Error())
+		}
+	}
+	return results
+}
+
+// BulkInfo handles GET / so shippers that check the cluster version before
+// sending (Filebeat, Logstash, Vector) accept the endpoint.
+func (h *HECHandler) BulkInfo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	httputil.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"name":         "telhawk-ingest",
+		"cluster_name": "telhawk",
+		"version": map[string]interface{}{
+			"number":                              esCompatVersion,
+			"build_flavor":                        "default",
+			"minimum_wire_compatibility_version":  "7.17.0",
+			"minimum_index_compatibility_version": "7.0.0",
+		},
+		"tagline": "You Know, for Search",
+	})
+}
+
+// BulkClusterHealth handles GET /_cluster/health for shipper health checks.
+func (h *HECHandler) BulkClusterHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	httputil.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"cluster_name": "telhawk",
+		"status":       "green",
+	})
+}
+
+func (h *HECHandler) sendBulkError(w http.ResponseWriter, status int, errType, reason string) {
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	if status == http.StatusUnauthorized {
+		w.Header().Add("WWW-Authenticate", `Basic realm="security" charset="UTF-8"`)
+		w.Header().Add("WWW-Authenticate", "ApiKey")
+	}
+	httputil.WriteJSON(w, status, esbulk.ErrorResponse{
+		Error:  esbulk.ErrorCause{Type: errType, Reason: reason},
+		Status: status,
+	})
+}
+
+// extractBulkToken reads the HEC token from Elasticsearch-style credentials:
+// basic auth (token as password, or as username with an empty password),
+// "ApiKey base64(id:token)" / "ApiKey token", or the HEC schemes.
+func extractBulkToken(r *http.Request) string {
+	if username, password, ok := r.BasicAuth(); ok {
+		if password != "" {
+			return password
+		}
+		return username
+	}
+
+	auth := r.Header.Get("Authorization")
+	scheme, value, found := strings.Cut(auth, " ")
+	if found && strings.EqualFold(scheme, "ApiKey") {
+		value = strings.TrimSpace(value)
+		if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+			if _, key, ok := strings.Cut(string(decoded), ":"); ok && key != "" {
+				return key
+			}
+		}
+		return value
+	}
+
+	return hec.ExtractToken(auth)
+}
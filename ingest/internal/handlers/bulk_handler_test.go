@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/esbulk"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/service"
+)
+
+const bulkTestBody = `{"index":{"_index":"filebeat-8.17.0","_id":"doc-1"}}
+{"@timestamp":"2026-01-02T03:04:05Z","message":"user login"}
+{"create":{}}
+{"message":"second"}
+`
+
+func newBulkRequest(path, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.SetBasicAuth("elastic", "test-token")
+	// Set the {index} wildcard the router would populate
+	if index, ok := strings.CutSuffix(strings.TrimPrefix(path, "/"), "/_bulk"); ok {
+		req.SetPathValue("index", index)
+	}
+	return req
+}
+
+func decodeBulkResponse(t *testing.T, rr *httptest.ResponseRecorder) esbulk.Response {
+	t.Helper()
+	var resp esbulk.Response
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode bulk response: %v: %s", err, rr.Body.String())
+	}
+	return resp
+}
+
+func TestHandleBulk_Success(t *testing.T) {
+	mockService := &mockIngestService{}
+	handler := NewHECHandler(mockService, nil, nil)
+	router, _ := esbulk.NewRouter([]esbulk.Route{{Pattern: "filebeat-*", SourceType: "hec"}})
+	handler.SetBulkRouter(router)
+
+	rr := httptest.NewRecorder()
+	handler.HandleBulk(rr, newBulkRequest("/app-logs/_bulk", bulkTestBody))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("X-Elastic-Product") != "Elasticsearch" {
+		t.Error("expected X-Elastic-Product header")
+	}
+
+	resp := decodeBulkResponse(t, rr)
+	if resp.Errors {
+		t.Errorf("expected no errors: %s", rr.Body.String())
+	}
+	if len(resp.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(resp.Items))
+	}
+	first := resp.Items[0]["index"]
+	if first == nil || first.Status != http.StatusCreated || first.ID != "doc-1" || first.Result != "created" {
+		t.Errorf("unexpected first item: %+v", first)
+	}
+	second := resp.Items[1]["create"]
+	if second == nil || second.ID == "" || second.Index != "app-logs" {
+		t.Errorf("expected generated id and path index, got %+v", second)
+	}
+
+	if mockService.validatedToken != "test-token" {
+		t.Errorf("expected basic auth password as token, got %q", mockService.validatedToken)
+	}
+	if len(mockService.documents) != 2 {
+		t.Fatalf("expected 2 documents ingested, got %d", len(mockService.documents))
+	}
+	if mockService.documentBatches != 1 {
+		t.Errorf("expected documents ingested in one batch, got %d batches", mockService.documentBatches)
+	}
+	if got := mockService.documents[0]; got.sourceType != "hec" || got.index != "filebeat-8.17.0" || got.id != "doc-1" {
+		t.Errorf("expected routed sourcetype and bulk _id, got %+v", got)
+	}
+	if got := mockService.documents[1]; got.sourceType != "app-logs" || got.id != second.ID || !got.create {
+		t.Errorf("expected unrouted index as sourcetype, the reported _id and a create, got %+v", got)
+	}
+	if mockService.documents[0].create {
+		t.Error("index action must not be ingested as a create")
+	}
+}
+
+func TestHandleBulk_ItemErrors(t *testing.T) {
+	mockService := &mockIngestService{
+		ingestDocumentFn: func(source []byte, sourceType, index string) error {
+			switch index {
+			case "bad":
+				return fmt.Errorf("%w: no normalizer", service.ErrNormalizationFailed)
+			case "down":
+				return fmt.Errorf("%w: connection refused", service.ErrStorageFailed)
+			case "taken":
+				return fmt.Errorf("%w: [dup-1]", service.ErrDocumentExists)
+			}
+			return nil
+		},
+	}
+	handler := NewHECHandler(mockService, nil, nil)
+
+	body := `{"index":{"_index":"ok"}}
+{"message":"fine"}
+{"index":{"_index":"bad"}}
+{"message":"unparseable"}
+{"index":{"_index":"down"}}
+{"message":"retry me"}
+{"create":{"_index":"taken","_id":"dup-1"}}
+{"message":"already there"}
+{"update":{"_index":"ok","_id":"1"}}
+{"doc":{"message":"x"}}
+{"delete":{"_index":"ok","_id":"1"}}
+{"index":{}}
+{"message":"no index"}
+`
+	rr := httptest.NewRecorder()
+	handler.HandleBulk(rr, newBulkRequest("/_bulk", body))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	resp := decodeBulkResponse(t, rr)
+	if !resp.Errors {
+		t.Error("expected errors flag")
+	}
+
+	want := []struct {
+		action string
+		status int
+		errTyp string
+	}{
+		{"index", http.StatusCreated, ""},
+		{"index", http.StatusBadRequest, "document_parsing_exception"},
+		{"index", http.StatusServiceUnavailable, "unavailable_shards_exception"},
+		{"create", http.StatusConflict, "version_conflict_engine_exception"},
+		{"update", http.StatusBadRequest, "action_request_validation_exception"},
+		{"delete", http.StatusBadRequest, "action_request_validation_exception"},
+		{"index", http.StatusBadRequest, "action_request_validation_exception"},
+	}
+	if len(resp.Items) != len(want) {
+		t.Fatalf("expected %d items, got %d", len(want), len(resp.Items))
+	}
+	for i, w := range want {
+		item := resp.Items[i][w.action]
+		if item == nil {
+			t.Fatalf("item %d: expected action %q, got %v", i, w.action, resp.Items[i])
+		}
+		if item.Status != w.status {
+			t.Errorf("item %d: expected status %d, got %d", i, w.status, item.Status)
+		}
+		if w.errTyp == "" && item.Error != nil {
+			t.Errorf("item %d: unexpected error %+v", i, item.Error)
+		}
+		if w.errTyp != "" && (item.Error == nil || item.Error.Type != w.errTyp) {
+			t.Errorf("item %d: expected error %q, got %+v", i, w.errTyp, item.Error)
+		}
+	}
+
+	if len(mockService.documents) != 4 {
+		t.Errorf("expected only index and create actions with an index to be ingested, got %d", len(mockService.documents))
+	}
+}
+
+func TestHandleBulk_Gzip(t *testing.T) {
+	mockService := &mockIngestService{}
+	handler := NewHECHandler(mockService, nil, nil)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(bulkTestBody))
+	gz.Close()
+
+	req := newBulkRequest("/app-logs/_bulk", buf.String())
+	req.Header.Set("Content-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.HandleBulk(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(mockService.documents) != 2 {
+		t.Errorf("expected 2 documents ingested, got %d", len(mockService.documents))
+	}
+}
+
+func TestHandleBulk_RequestErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		service     *mockIngestService
+		rateLimiter *mockRateLimiter
+		body        string
+		auth        func(r *http.Request)
+		wantStatus  int
+		wantType    string
+	}{
+		{"missing credentials", &mockIngestService{}, nil, bulkTestBody, func(r *http.Request) {}, http.StatusUnauthorized, "security_exception"},
+		{"invalid token", &mockIngestService{validateTokenErr: errors.New("invalid")}, nil, bulkTestBody, nil, http.StatusUnauthorized, "security_exception"},
+		{"malformed action line", &mockIngestService{}, nil, "not json\n", nil, http.StatusBadRequest, "illegal_argument_exception"},
+		{"unknown action", &mockIngestService{}, nil, `{"upsert":{}}` + "\n{}\n", nil, http.StatusBadRequest, "illegal_argument_exception"},
+		{"missing source line", &mockIngestService{}, nil, `{"index":{"_index":"x"}}` + "\n", nil, http.StatusBadRequest, "illegal_argument_exception"},
+		{"empty request", &mockIngestService{}, nil, "\n", nil, http.StatusBadRequest, "action_request_validation_exception"},
+		{"rate limited", &mockIngestService{}, &mockRateLimiter{allowFunc: func(ctx context.Context, key string) (bool, error) { return false, nil }}, bulkTestBody, nil, http.StatusTooManyRequests, "es_rejected_execution_exception"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handler *HECHandler
+			if tt.rateLimiter != nil {
+				handler = NewHECHandler(tt.service, tt.rateLimiter, nil)
+			} else {
+				handler = NewHECHandler(tt.service, nil, nil)
+			}
+
+			req := newBulkRequest("/_bulk", tt.body)
+			if tt.auth != nil {
+				req.Header.Del("Authorization")
+				tt.auth(req)
+			}
+			rr := httptest.NewRecorder()
+			handler.HandleBulk(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			var resp esbulk.ErrorResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("expected Elasticsearch error body: %v", err)
+			}
+			if resp.Error.Type != tt.wantType || resp.Status != tt.wantStatus {
+				t.Errorf("expected %s/%d, got %+v", tt.wantType, tt.wantStatus, resp)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected WWW-Authenticate header")
+			}
+			if len(tt.service.documents) != 0 {
+				t.Errorf("expected no documents ingested, got %d", len(tt.service.documents))
+			}
+		})
+	}
+}
+
+func TestExtractBulkToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *http.Request)
+		want  string
+	}{
+		{"basic password", func(r *http.Request) { r.SetBasicAuth("elastic", "tok") }, "tok"},
+		{"basic username only", func(r *http.Request) { r.SetBasicAuth("tok", "") }, "tok"},
+		{"api key id:key", func(r *http.Request) {
+			r.Header.Set("Authorization", "ApiKey "+base64.StdEncoding.EncodeToString([]byte("key-id:tok")))
+		}, "tok"},
+		{"api key raw", func(r *http.Request) { r.Header.Set("Authorization", "ApiKey tok") }, "tok"},
+		{"splunk scheme", func(r *http.Request) { r.Header.Set("Authorization", "Splunk tok") }, "tok"},
+		{"none", func(r *http.Request) {}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/_bulk", nil)
+			tt.setup(req)
+			if got := extractBulkToken(req); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBulkInfo(t *testing.T) {
+	handler := NewHECHandler(&mockIngestService{}, nil, nil)
+
+	rr := httptest.NewRecorder()
+	handler.BulkInfo(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var info struct {
+		Version struct {
+			Number string `json:"number"`
+		} `json:"version"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &info); err != nil {
+		t.Fatalf("failed to decode info: %v", err)
+	}
+	if info.Version.Number != esCompatVersion {
+		t.Errorf("expected version %s, got %q", esCompatVersion, info.Version.Number)
+	}
+	if rr.Header().Get("X-Elastic-Product") != "Elasticsearch" {
+		t.Error("expected X-Elastic-Product header")
+	}
+}
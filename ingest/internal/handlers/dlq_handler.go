@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/httputil"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/authclient"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/dlq"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/service"
+)
+
+const (
+	dlqDefaultLimit = 100
+	dlqMaxLimit     = 1000
+)
+
+// DLQServiceInterface is the subset of service.DLQService used by DLQHandler.
+type DLQServiceInterface interface {
+	List(ctx context.Context, filter dlq.Filter) ([]dlq.FailedEvent, error)
+	Get(ctx context.Context, id string) (*dlq.FailedEvent, error)
+	Stats(ctx context.Context) map[string]interface{}
+	Purge(ctx context.Context, filter dlq.Filter) (int, error)
+	Replay(ctx context.Context, req service.ReplayRequest) (*service.ReplayReport, error)
+}
+
+// UserTokenValidator validates user access tokens with the authenticate service.
+type UserTokenValidator interface {
+	ValidateToken(ctx context.Context, token string) (*authclient.ValidateTokenResponse, error)
+}
+
+// DLQHandler serves the dead-letter queue inspection and replay API.
+// Every endpoint requires an admin access token: DLQ entries hold raw
+// events. Admins scoped to a client only see and act on that client's
+// entries; backend stats are for platform admins only.
+type DLQHandler struct {
+	service DLQServiceInterface
+	auth    UserTokenValidator
+}
+
+func NewDLQHandler(service DLQServiceInterface, auth UserTokenValidator) *DLQHandler {
+	return &DLQHandler{
+		service: service,
+		auth:    auth,
+	}
+}
+
+// requireAdmin validates the bearer token and checks for the admin role,
+// returning the caller. It writes the error response and returns false if
+// the caller is not allowed.
+func (h *DLQHandler) requireAdmin(w http.ResponseWriter, r *http.Request) (*authclient.ValidateTokenResponse, bool) {
+	return authorizeAdmin(w, r, h.auth, "DLQ")
+}
+
+// scopeFilter restricts filter to the caller's client when the caller is an
+// admin scoped to one. A filter naming another client gets 403; it writes the
+// error response and returns false then.
+func scopeFilter(w http.ResponseWriter, caller *authclient.ValidateTokenResponse, filter *dlq.Filter) bool {
+	if caller.ClientID == "" {
+		return true
+	}
+	if filter.ClientID != "" && filter.ClientID != caller.ClientID {
+		httputil.WriteJSONAPIForbiddenError(w, "cannot access another client's DLQ entries")
+		return false
+	}
+	filter.ClientID = caller.ClientID
+	return true
+}
+
+// requireAdmin validates the bearer token with auth and checks for the admin
+// role, writing the error response when the caller is not allowed.
+func requireAdmin(w http.ResponseWriter, r *http.Request, auth UserTokenValidator, api string) bool {
+	_, ok := authorizeAdmin(w, r, auth, api)
+	return ok
+}
+
+// authorizeAdmin is requireAdmin, also returning the validated token so
+// callers can scope the request to the caller's client.
+func authorizeAdmin(w http.ResponseWriter, r *http.Request, auth UserTokenValidator, api string) (*authclient.ValidateTokenResponse, bool) {
+	authz := r.Header.Get("Authorization")
+	if !strings.HasPrefix(strings.ToLower(authz), "bearer ") {
+		httputil.WriteJSONAPIUnauthorizedError(w, "bearer token required")
+		return nil, false
+	}
+	token := strings.TrimSpace(authz[len("Bearer "):])
+
+	resp, err := auth.ValidateToken(r.Context(), token)
+	if err != nil {
+		log.Printf("%s: token validation failed: %v", api, err)
+		httputil.WriteJSONAPIUnauthorizedError(w, "token validation failed")
+		return nil, false
+	}
+	if !resp.Valid {
+		httputil.WriteJSONAPIUnauthorizedError(w, "invalid or expired token")
+		return nil, false
+	}
+
+	for _, role := range resp.Roles {
+		if role == "admin" {
+			return resp, true
+		}
+	}
+	httputil.WriteJSONAPIForbiddenError(w, "admin role required")
+	return nil, false
+}
+
+// List handles GET /api/v1/dlq
+func (h *DLQHandler) List(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := parseDLQFilter(r.URL.Query())
+	if err != nil {
+		httputil.WriteJSONAPIValidationError(w, err.Error())
+		return
+	}
+	if !scopeFilter(w, caller, &filter) {
+		return
+	}
+
+	events, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	items := make([]map[string]interface{}, len(events))
+	for i := range events {
+		items[i] = map[string]interface{}{
+			"id":         events[i].ID,
+			"attributes": dlqEventSummary(&events[i]),
+		}
+	}
+	httputil.WriteJSONAPICollection(w, http.StatusOK, "dlq_event", items, nil)
+}
+
+// Show handles GET /api/v1/dlq/{id}
+func (h *DLQHandler) Show(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	id := r.PathValue("id")
+	event, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, dlq.ErrNotFound) {
+			httputil.WriteJSONAPINotFoundError(w, "dlq_event", id)
+			return
+		}
+		h.writeServiceError(w, err)
+		return
+	}
+	// Other clients' entries do not exist for a scoped admin
+	if caller.ClientID != "" && event.Attribute("client_id") != caller.ClientID {
+		httputil.WriteJSONAPINotFoundError(w, "dlq_event", id)
+		return
+	}
+
+	attrs := dlqEventSummary(event)
+	attrs["replays"] = event.Replays
+	if event.Envelope != nil {
+		attrs["envelope"] = map[string]interface{}{
+			"id":          event.Envelope.ID,
+			"source":      event.Envelope.Source,
+			"source_type": event.Envelope.SourceType,
+			"format":      event.Envelope.Format,
+			"attributes":  event.Envelope.Attributes,
+			"received_at": event.Envelope.ReceivedAt,
+			"payload":     string(event.Envelope.Payload),
+		}
+	}
+	httputil.WriteJSONAPIResource(w, http.StatusOK, "dlq_event", event.ID, attrs)
+}
+
+// Stats handles GET /api/v1/dlq/stats
+func (h *DLQHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	if caller.ClientID != "" {
+		httputil.WriteJSONAPIForbiddenError(w, "DLQ stats cover every client")
+		return
+	}
+	httputil.WriteJSONAPIResource(w, http.StatusOK, "dlq_stats", "dlq", h.service.Stats(r.Context()))
+}
+
+// replayRequest is the body of POST /api/v1/dlq/replay.
+type replayRequest struct {
+	IDs    []string          `json:"ids,omitempty"`
+	Filter map[string]string `json:"filter,omitempty"` // Same keys as the list query parameters
+	DryRun bool              `json:"dry_run"`
+	Force  bool              `json:"force"`
+}
+
+// Replay handles POST /api/v1/dlq/replay
+func (h *DLQHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var body replayRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		httputil.WriteJSONAPIValidationError(w, "invalid request body")
+		return
+	}
+
+	query := url.Values{}
+	for key, value := range body.Filter {
+		query.Set(key, value)
+	}
+	filter, err := parseDLQFilter(query)
+	if err != nil {
+		httputil.WriteJSONAPIValidationError(w, err.Error())
+		return
+	}
+	// Scopes the IDs too, so a scoped admin's other-client IDs are not found
+	if !scopeFilter(w, caller, &filter) {
+		return
+	}
+
+	report, err := h.service.Replay(r.Context(), service.ReplayRequest{
+		IDs:    body.IDs,
+		Filter: filter,
+		DryRun: body.DryRun,
+		Force:  body.Force,
+	})
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	httputil.WriteJSONAPIResource(w, http.StatusOK, "dlq_replay", "", report)
+}
+
+// Purge handles DELETE /api/v1/dlq. Purging without any filter requires all=true.
+func (h *DLQHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	caller, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter, err := parseDLQFilter(query)
+	if err != nil {
+		httputil.WriteJSONAPIValidationError(w, err.Error())
+		return
+	}
+	filter.Limit = 0
+	// For a scoped admin, all=true purges every entry of their client
+	unfiltered := filter.IsZero()
+	if !scopeFilter(w, caller, &filter) {
+		return
+	}
+	if unfiltered && query.Get("all") != "true" {
+		httputil.WriteJSONAPIValidationError(w, "refusing to purge the entire DLQ without all=true")
+		return
+	}
+
+	deleted, err := h.service.Purge(r.Context(), filter)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	httputil.WriteJSONAPIResource(w, http.StatusOK, "dlq_purge", "", map[string]interface{}{
+		"deleted": deleted,
+	})
+}
+
+func (h *DLQHandler) writeServiceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrDLQDisabled) {
+		httputil.WriteJSONAPIError(w, http.StatusServiceUnavailable, "dlq_disabled", "DLQ Disabled", "dead letter queue is not enabled")
+		return
+	}
+	log.Printf("DLQ: request failed: %v", err)
+	httputil.WriteJSONAPIInternalError(w, err.Error())
+}
+
+// parseDLQFilter reads reason, sourcetype, token, client_id, status, since,
+// until and limit. since/until accept RFC3339 timestamps or a duration
+// relative to now (e.g. "24h").
+func parseDLQFilter(q url.Values) (dlq.Filter, error) {
+	filter := dlq.Filter{
+		Reason:     q.Get("reason"),
+		SourceType: q.Get("sourcetype"),
+		TokenID:    q.Get("token"),
+		ClientID:   q.Get("client_id"),
+		Status:     q.Get("status"),
+		Limit:      httputil.ParseIntParam(q.Get("limit"), dlqDefaultLimit),
+	}
+
+	switch filter.Status {
+	case "", dlq.StatusPending, dlq.StatusReplayed:
+	default:
+		return filter, fmt.Errorf("invalid status %q (expected %s or %s)", filter.Status, dlq.StatusPending, dlq.StatusReplayed)
+	}
+
+	if filter.Limit <= 0 || filter.Limit > dlqMaxLimit {
+		filter.Limit = dlqMaxLimit
+	}
+
+	var err error
+	if filter.Since, err = parseDLQTime(q.Get("since")); err != nil {
+		return filter, fmt.Errorf("invalid since: %w", err)
+	}
+	if filter.Until, err = parseDLQTime(q.Get("until")); err != nil {
+		return filter, fmt.Errorf("invalid until: %w", err)
+	}
+	return filter, nil
+}
+
+func parseDLQTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 timestamp or duration, got %q", value)
+	}
+	return time.Now().Add(-d), nil
+}
+
+func dlqEventSummary(e *dlq.FailedEvent) map[string]interface{} {
+	status := e.Status
+	if status == "" {
+		status = dlq.StatusPending
+	}
+	attrs := map[string]interface{}{
+		"timestamp":    e.Timestamp,
+		"reason":       e.Reason,
+		"error":        e.Error,
+		"status":       status,
+		"attempts":     e.Attempts,
+		"last_attempt": e.LastAttempt,
+		"replay_count": len(e.Replays),
+		"sourcetype":   e.SourceType(),
+		"hec_token_id": e.Attribute("hec_token_id"),
+		"client_id":    e.Attribute("client_id"),
+	}
+	if e.Envelope != nil {
+		attrs["source"] = e.Envelope.Source
+		attrs["event_id"] = e.Envelope.ID
+	}
+	return attrs
+}
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/authclient"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/dlq"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/service"
+)
+
+type mockUserValidator struct {
+	resp *authclient.ValidateTokenResponse
+	err  error
+}
+
+func (m *mockUserValidator) ValidateToken(ctx context.Context, token string) (*authclient.ValidateTokenResponse, error) {
+	return m.resp, m.err
+}
+
+type mockDLQService struct {
+	events      []dlq.FailedEvent
+	lastFilter  dlq.Filter
+	lastReplay  service.ReplayRequest
+	purgeCalled bool
+	err         error
+}
+
+func (m *mockDLQService) List(ctx context.Context, filter dlq.Filter) ([]dlq.FailedEvent, error) {
+	m.lastFilter = filter
+	return m.events, m.err
+}
+
+func (m *mockDLQService) Get(ctx context.Context, id string) (*dlq.FailedEvent, error) {
+	for i := range m.events {
+		if m.events[i].ID == id {
+			return &m.events[i], nil
+		}
+	}
+	return nil, dlq.ErrNotFound
+}
+
+func (m *mockDLQService) Stats(ctx context.Context) map[string]interface{} {
+	return map[string]interface{}{"enabled": true}
+}
+
+func (m *mockDLQService) Purge(ctx context.Context, filter dlq.Filter) (int, error) {
+	m.purgeCalled = true
+	m.lastFilter = filter
+	return 3, m.err
+}
+
+func (m *mockDLQService) Replay(ctx context.Context, req service.ReplayRequest) (*service.ReplayReport, error) {
+	m.lastReplay = req
+	return &service.ReplayReport{DryRun: req.DryRun}, m.err
+}
+
+func adminValidator() *mockUserValidator {
+	return &mockUserValidator{resp: &authclient.ValidateTokenResponse{Valid: true, UserID: "u1", Roles: []string{"admin"}}}
+}
+
+func TestDLQHandler_RequiresAdmin(t *testing.T) {
+	tests := []struct {
+		name       string
+		authHeader string
+		validator  *mockUserValidator
+		wantStatus int
+	}{
+		{"missing token", "", adminValidator(), http.StatusUnauthorized},
+		{"invalid token", "Bearer bad", &mockUserValidator{resp: &authclient.ValidateTokenResponse{Valid: false}}, http.StatusUnauthorized},
+		{"non-admin", "Bearer user", &mockUserValidator{resp: &authclient.ValidateTokenResponse{Valid: true, Roles: []string{"analyst"}}}, http.StatusForbidden},
+		{"admin", "Bearer admin", adminValidator(), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewDLQHandler(&mockDLQService{}, tt.validator)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/dlq", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+
+			h.List(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestDLQHandler_List_ParsesFilter(t *testing.T) {
+	svc := &mockDLQService{events: []dlq.FailedEvent{{ID: "a", Reason: "normalization_failed", Timestamp: time.Now()}}}
+	h := NewDLQHandler(svc, adminValidator())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/dlq?reason=normalization_failed&sourcetype=syslog&token=tok-1&since=1h&limit=5", nil)
+	req.Header.Set("Authorization", "Bearer admin")
+	w := httptest.NewRecorder()
+
+	h.List(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	f := svc.lastFilter
+	if f.Reason != "normalization_failed" || f.SourceType != "syslog" || f.TokenID != "tok-1" || f.Limit != 5 {
+		t.Errorf("unexpected filter: %+v", f)
+	}
+	if f.Since.IsZero() || time.Since(f.Since) < 59*time.Minute {
+		t.Errorf("expected since about 1h ago, got %v", f.Since)
+	}
+	if !strings.Contains(w.Body.String(), `"dlq_event"`) {
+		t.Errorf("expected dlq_event resources, got %s", w.Body.String())
+	}
+}
+
+func TestDLQHandler_List_InvalidFilter(t *testing.T) {
+	h := NewDLQHandler(&mockDLQService{}, adminValidator())
+
+	for _, query := range []string{"status=bogus", "since=yesterday"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/dlq?"+query, nil)
+		req.Header.Set("Authorization", "Bearer admin")
+		w := httptest.NewRecorder()
+
+		h.List(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestDLQHandler_List_Disabled(t *testing.T) {
+	h := NewDLQHandler(&mockDLQService{err: service.ErrDLQDisabled}, adminValidator())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/dlq", nil)
+	req.Header.Set("Authorization", "Bearer admin")
+	w := httptest.NewRecorder()
+
+	h.List(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", w.Code)
+	}
+}
+
+func TestDLQHandler_Show_NotFound(t *testing.T) {
+	h := NewDLQHandler(&mockDLQService{}, adminValidator())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/dlq/missing", nil)
+	req.SetPathValue("id", "missing")
+	req.Header.Set("Authorization", "Bearer admin")
+	w := httptest.NewRecorder()
+
+	h.Show(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestDLQHandler_Replay(t *testing.T) {
+	svc := &mockDLQService{}
+	h := NewDLQHandler(svc, adminValidator())
+
+	body := `{"filter":{"sourcetype":"syslog","reason":"normalization_failed"},"dry_run":true}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/dlq/replay", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer admin")
+	w := httptest.NewRecorder()
+
+	h.Replay(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !svc.lastReplay.DryRun {
+		t.Error("expected dry run to be passed through")
+	}
+	if svc.lastReplay.Filter.SourceType != "syslog" || svc.lastReplay.Filter.Reason != "normalization_failed" {
+		t.Errorf("unexpected replay filter: %+v", svc.lastReplay.Filter)
+	}
+}
+
+func TestDLQHandler_Purge_RequiresAll(t *testing.T) {
+	svc := &mockDLQService{}
+	h := NewDLQHandler(svc, adminValidator())
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/dlq", nil)
+	req.Header.Set("Authorization", "Bearer admin")
+	w := httptest.NewRecorder()
+	h.Purge(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 without all=true, got %d", w.Code)
+	}
+	if svc.purgeCalled {
+		t.Error("purge must not run without a filter or all=true")
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/dlq?sourcetype=syslog", nil)
+	req.Header.Set("Authorization", "Bearer admin")
+	w = httptest.NewRecorder()
+	h.Purge(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 with filter, got %d", w.Code)
+	}
+	if svc.lastFilter.Limit != 0 {
+		t.Errorf("purge filter must not be limited, got %d", svc.lastFilter.Limit)
+	}
+}
+
+func TestDLQHandler_ClientScopedAdmin(t *testing.T) {
+	entry := func(id, clientID string) dlq.FailedEvent {
+		return dlq.FailedEvent{ID: id, Envelope: &models.RawEventEnvelope{
+			ID:         id,
+			Attributes: map[string]string{"client_id": clientID},
+			Payload:    []byte("card=4111111111111111"),
+		}}
+	}
+	svc := &mockDLQService{events: []dlq.FailedEvent{entry("own", "acme"), entry("other", "globex")}}
+	h := NewDLQHandler(svc, &mockUserValidator{resp: &authclient.ValidateTokenResponse{
+		Valid: true, UserID: "u2", Roles: []string{"admin"}, ClientID: "acme",
+	}})
+
+	do := func(handler http.HandlerFunc, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if id, ok := strings.CutPrefix(target, "/api/v1/dlq/"); ok {
+			req.SetPathValue("id", id)
+		}
+		req.Header.Set("Authorization", "Bearer scoped")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	t.Run("list is scoped to the caller's client", func(t *testing.T) {
+		if w := do(h.List, http.MethodGet, "/api/v1/dlq", ""); w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		if svc.lastFilter.ClientID != "acme" {
+			t.Errorf("expected filter scoped to acme, got %q", svc.lastFilter.ClientID)
+		}
+		if w := do(h.List, http.MethodGet, "/api/v1/dlq?client_id=globex", ""); w.Code != http.StatusForbidden {
+			t.Errorf("expected status 403 for another client, got %d", w.Code)
+		}
+	})
+
+	t.Run("show hides other clients' entries", func(t *testing.T) {
+		if w := do(h.Show, http.MethodGet, "/api/v1/dlq/own", ""); w.Code != http.StatusOK {
+			t.Errorf("expected status 200 for own entry, got %d", w.Code)
+		}
+		w := do(h.Show, http.MethodGet, "/api/v1/dlq/other", "")
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 for another client's entry, got %d", w.Code)
+		}
+		if strings.Contains(w.Body.String(), "4111111111111111") {
+			t.Error("another client's payload was served")
+		}
+	})
+
+	t.Run("replay is scoped to the caller's client", func(t *testing.T) {
+		if w := do(h.Replay, http.MethodPost, "/api/v1/dlq/replay", `{"ids":["own","other"]}`); w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		if svc.lastReplay.Filter.ClientID != "acme" {
+			t.Errorf("expected replay scoped to acme, got %q", svc.lastReplay.Filter.ClientID)
+		}
+	})
+
+	t.Run("purge is scoped to the caller's client", func(t *testing.T) {
+		if w := do(h.Purge, http.MethodDelete, "/api/v1/dlq", ""); w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 without all=true, got %d", w.Code)
+		}
+		if w := do(h.Purge, http.MethodDelete, "/api/v1/dlq?all=true", ""); w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		if svc.lastFilter.ClientID != "acme" {
+			t.Errorf("expected purge scoped to acme, got %q", svc.lastFilter.ClientID)
+		}
+	})
+
+	t.Run("stats are for platform admins", func(t *testing.T) {
+		if w := do(h.Stats, http.MethodGet, "/api/v1/dlq/stats", ""); w.Code != http.StatusForbidden {
+			t.Errorf("expected status 403, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/hec"
+)
+
+// defaultHECMaxBodySize bounds the decompressed size of a HEC request.
+const defaultHECMaxBodySize = 100 << 20
+
+// hecBody is a request body opened for reading: gzip is decompressed and the
+// decompressed size is capped, so a small compressed body cannot expand past
+// the limit.
+type hecBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *hecBody) Close() error {
+	var err error
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		if cerr := b.closers[i].Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// openHECBody opens the request body, decompressing it if Content-Encoding is
+// gzip. Reads fail with errRequestTooLarge past maxSize bytes, compressed or
+// decompressed.
+func openHECBody(w http.ResponseWriter, r *http.Request, maxSize int64) (*hecBody, error) {
+	raw := http.MaxBytesReader(w, r.Body, maxSize)
+	body := &hecBody{Reader: raw, closers: []io.Closer{raw}}
+
+	switch strings.ToLower(r.Header.Get("Content-Encoding")) {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(raw)
+		if err != nil {
+			body.Close()
+			if isTooLarge(err) {
+				return nil, errRequestTooLarge
+			}
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		body.Reader = gz
+		body.closers = append(body.closers, gz)
+	default:
+		body.Close()
+		return nil, fmt.Errorf("unsupported content encoding %q", r.Header.Get("Content-Encoding"))
+	}
+
+	body.Reader = &cappedReader{r: body.Reader, remaining: maxSize}
+	return body, nil
+}
+
+// cappedReader fails with errRequestTooLarge once more than remaining bytes
+// have been read.
+type cappedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.remaining < 0 {
+		return 0, errRequestTooLarge
+	}
+	if int64(len(p)) > c.remaining+1 {
+		p = p[:c.remaining+1]
+	}
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+	if c.remaining < 0 {
+		return n, errRequestTooLarge
+	}
+	return n, err
+}
+
+func isTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.Is(err, errRequestTooLarge) || errors.As(err, &maxBytesErr)
+}
+
+// hecEventDecoder reads HEC events from a body one at a time. It accepts a
+// single event object, NDJSON, and events concatenated with no separator
+// (what Splunk forwarders and most HEC libraries send).
+type hecEventDecoder struct {
+	dec *json.Decoder
+}
+
+func newHECEventDecoder(r io.Reader) *hecEventDecoder {
+	return &hecEventDecoder{dec: json.NewDecoder(r)}
+}
+
+// hecWireEvent is an event as sent on the wire. Time and event are kept raw
+// so a string timestamp is accepted and a missing event is told apart from
+// a blank one.
+type hecWireEvent struct {
+	Time       json.RawMessage        `json:"time"`
+	Host       string                 `json:"host"`
+	Source     string                 `json:"source"`
+	SourceType string                 `json:"sourcetype"`
+	Index      string                 `json:"index"`
+	Event      json.RawMessage        `json:"event"`
+	Fields     map[string]interface{} `json:"fields"`
+}
+
+// Next returns the next event, or io.EOF after the last one. Malformed events
+// return a *hec.HECError; body read failures return the read error.
+func (d *hecEventDecoder) Next() (*models.HECEvent, error) {
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, hec.ErrInvalidEvent
+		}
+		return nil, err
+	}
+
+	var wire hecWireEvent
+	if err := json.Unmarshal(raw, &wire); err != nil {
+		return nil, hec.ErrInvalidEvent
+	}
+
+	event := &models.HECEvent{
+		Host:       wire.Host,
+		Source:     wire.Source,
+		SourceType: wire.SourceType,
+		Index:      wire.Index,
+		Fields:     wire.Fields,
+	}
+
+	if len(wire.Event) == 0 || string(wire.Event) == "null" {
+		return nil, hec.ErrEventRequired
+	}
+	if err := json.Unmarshal(wire.Event, &event.Event); err != nil {
+		return nil, hec.ErrInvalidEvent
+	}
+	if s, ok := event.Event.(string); ok && strings.TrimSpace(s) == "" {
+		return nil, hec.ErrEventBlank
+	}
+
+	if len(wire.Time) > 0 && string(wire.Time) != "null" {
+		t, err := parseHECTime(wire.Time)
+		if err != nil {
+			return nil, hec.ErrInvalidEvent
+		}
+		event.Time = &t
+	}
+
+	// Indexed fields are flat: strings, numbers or arrays of them
+	for _, v := range wire.Fields {
+		if !isIndexedFieldValue(v, true) {
+			return nil, hec.ErrIndexedFields
+		}
+	}
+
+	return event, nil
+}
+
+// parseHECTime accepts epoch seconds as a JSON number or a numeric string.
+func parseHECTime(raw json.RawMessage) (float64, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return 0, err
+		}
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	}
+	return strconv.ParseFloat(string(raw), 64)
+}
+
+func isIndexedFieldValue(v interface{}, allowArray bool) bool {
+	switch val := v.(type) {
+	case string, float64, bool:
+		return true
+	case []interface{}:
+		if !allowArray {
+			return false
+		}
+		for _, item := range val {
+			if !isIndexedFieldValue(item, false) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+func newHECRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/services/collector/event", strings.NewReader(body))
+	req.Header.Set("Authorization", "Splunk test-token")
+	return req
+}
+
+func decodeHECResponse(t *testing.T, rr *httptest.ResponseRecorder) models.HECResponse {
+	t.Helper()
+	var resp models.HECResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rr.Body.String(), err)
+	}
+	return resp
+}
+
+func gzipString(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestHandleEvent_ConcatenatedBatch(t *testing.T) {
+	mockService := &mockIngestService{}
+	handler := NewHECHandler(mockService, nil, nil)
+
+	// Splunk forwarders send events back to back with no separator
+	body := `{"event":"one","time":1700000000.5}{"event":{"msg":"two"},"time":"1700000001"}  {"event":"three"}`
+	rr := httptest.NewRecorder()
+	handler.HandleEvent(rr, newHECRequest(body))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(mockService.events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(mockService.events))
+	}
+	if got := mockService.events[0].Event; got != "one" {
+		t.Errorf("Expected first event %q, got %v", "one", got)
+	}
+	if mockService.events[1].Time == nil || *mockService.events[1].Time != 1700000001 {
+		t.Errorf("Expected string time to be parsed, got %v", mockService.events[1].Time)
+	}
+	if mockService.events[2].Time != nil {
+		t.Errorf("Expected no time on third event, got %v", *mockService.events[2].Time)
+	}
+}
+
+func TestHandleEvent_GzipBody(t *testing.T) {
+	mockService := &mockIngestService{}
+	handler := NewHECHandler(mockService, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/services/collector/event",
+		bytes.NewReader(gzipString(t, `{"event":"one"}`+"\n"+`{"event":"two"}`)))
+	req.Header.Set("Authorization", "Splunk test-token")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	rr := httptest.NewRecorder()
+	handler.HandleEvent(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(mockService.events) != 2 {
+		t.Errorf("Expected 2 events, got %d", len(mockService.events))
+	}
+}
+
+func TestHandleEvent_InvalidEventNumber(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		code        int
+		eventNumber int
+		ingested    int
+	}{
+		{"malformed JSON", `{"event":"one"}{"event":`, 6, 1, 1},
+		{"not an object", `{"event":"one"}{"event":"two"}[1,2]`, 6, 2, 2},
+		{"missing event", `{"event":"one"}{"host":"web-1"}`, 12, 1, 1},
+		{"null event", `{"event":null}`, 12, 0, 0},
+		{"blank event", `{"event":"one"}{"event":"  "}`, 13, 1, 1},
+		{"bad time", `{"event":"one","time":"yesterday"}`, 6, 0, 0},
+		{"nested indexed field", `{"event":"one","fields":{"user":{"name":"bob"}}}`, 15, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockIngestService{}
+			handler := NewHECHandler(mockService, nil, nil)
+
+			rr := httptest.NewRecorder()
+			handler.HandleEvent(rr, newHECRequest(tt.body))
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", rr.Code)
+			}
+			resp := decodeHECResponse(t, rr)
+			if resp.Code != tt.code {
+				t.Errorf("Expected code %d, got %d (%s)", tt.code, resp.Code, resp.Text)
+			}
+			if resp.InvalidEventNumber == nil || *resp.InvalidEventNumber != tt.eventNumber {
+				t.Errorf("Expected invalid-event-number %d, got %s", tt.eventNumber, rr.Body.String())
+			}
+			// Events before the invalid one are kept, as with Splunk
+			if len(mockService.events) != tt.ingested {
+				t.Errorf("Expected %d events ingested, got %d", tt.ingested, len(mockService.events))
+			}
+		})
+	}
+}
+
+func TestHandleEvent_IndexedFields(t *testing.T) {
+	mockService := &mockIngestService{}
+	handler := NewHECHandler(mockService, nil, nil)
+
+	body := `{"event":"one","fields":{"env":"prod","port":443,"tags":["a","b"]}}`
+	rr := httptest.NewRecorder()
+	handler.HandleEvent(rr, newHECRequest(body))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := mockService.events[0].Fields["env"]; got != "prod" {
+		t.Errorf("Expected env field %q, got %v", "prod", got)
+	}
+}
+
+func TestHandleEvent_NoData(t *testing.T) {
+	for name, body := range map[string][]byte{
+		"whitespace": []byte(" \n\n "),
+		"empty gzip": gzipString(t, ""),
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := NewHECHandler(&mockIngestService{}, nil, nil)
+			req := httptest.NewRequest(http.MethodPost, "/services/collector/event", bytes.NewReader(body))
+			req.Header.Set("Authorization", "Splunk test-token")
+			if name == "empty gzip" {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+
+			rr := httptest.NewRecorder()
+			handler.HandleEvent(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", rr.Code)
+			}
+			if resp := decodeHECResponse(t, rr); resp.Code != 5 {
+				t.Errorf("Expected code 5 (No data), got %d", resp.Code)
+			}
+		})
+	}
+}
+
+func TestHandleEvent_MaxBodySize(t *testing.T) {
+	event := `{"event":"` + strings.Repeat("x", 100) + `"}`
+	body := strings.Repeat(event, 20)
+
+	t.Run("plain", func(t *testing.T) {
+		mockService := &mockIngestService{}
+		handler := NewHECHandler(mockService, nil, nil)
+		handler.SetMaxBodySize(1024)
+
+		rr := httptest.NewRecorder()
+		handler.HandleEvent(rr, newHECRequest(body))
+
+		if rr.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status 413, got %d", rr.Code)
+		}
+	})
+
+	t.Run("gzip expands past limit", func(t *testing.T) {
+		mockService := &mockIngestService{}
+		handler := NewHECHandler(mockService, nil, nil)
+		handler.SetMaxBodySize(1024)
+
+		compressed := gzipString(t, body)
+		if len(compressed) >= 1024 {
+			t.Fatalf("test body should compress below the limit, got %d bytes", len(compressed))
+		}
+		req := httptest.NewRequest(http.MethodPost, "/services/collector/event", bytes.NewReader(compressed))
+		req.Header.Set("Authorization", "Splunk test-token")
+		req.Header.Set("Content-Encoding", "gzip")
+
+		rr := httptest.NewRecorder()
+		handler.HandleEvent(rr, req)
+
+		if rr.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status 413, got %d", rr.Code)
+		}
+	})
+}
+
+func TestHandleEvent_TokenErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		auth   string
+		query  string
+		allow  bool
+		status int
+		code   int
+	}{
+		{"no token", "", "", false, http.StatusUnauthorized, 2},
+		{"malformed header", "Basic abc", "", false, http.StatusUnauthorized, 3},
+		{"query token disabled", "", "?token=test-token", false, http.StatusBadRequest, 16},
+		{"query token enabled", "", "?token=test-token", true, http.StatusOK, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockIngestService{}
+			handler := NewHECHandler(mockService, nil, nil)
+			handler.SetAllowQueryStringToken(tt.allow)
+
+			req := httptest.NewRequest(http.MethodPost, "/services/collector/event"+tt.query, strings.NewReader(`{"event":"one"}`))
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rr := httptest.NewRecorder()
+			handler.HandleEvent(rr, req)
+
+			if rr.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rr.Code)
+			}
+			if resp := decodeHECResponse(t, rr); resp.Code != tt.code {
+				t.Errorf("Expected code %d, got %d (%s)", tt.code, resp.Code, resp.Text)
+			}
+			if tt.status == http.StatusOK && mockService.validatedToken != "test-token" {
+				t.Errorf("Expected query token to be validated, got %q", mockService.validatedToken)
+			}
+		})
+	}
+}
+
+func TestHandleRaw_GzipBody(t *testing.T) {
+	mockService := &mockIngestService{}
+	handler := NewHECHandler(mockService, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/services/collector/raw", bytes.NewReader(gzipString(t, "raw line")))
+	req.Header.Set("Authorization", "Splunk test-token")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	rr := httptest.NewRecorder()
+	handler.HandleRaw(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
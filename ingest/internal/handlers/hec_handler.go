@@ -3,18 +3,20 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/telhawk-systems/telhawk-stack/common/hecstats"
 	"github.com/telhawk-systems/telhawk-stack/common/httputil"
 
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/esbulk"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/metrics"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/otlp"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/ratelimit"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/service"
 	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/hec"
@@ -23,6 +25,8 @@ import (
 type IngestServiceInterface interface {
 	IngestEvent(ctx context.Context, event *models.HECEvent, sourceIP string, tokenInfo *service.TokenInfo) (string, error)
 	IngestRaw(ctx context.Context, data []byte, sourceIP string, tokenInfo *service.TokenInfo, source, sourceType, host string) (string, error)
+	IngestOTLPLog(ctx context.Context, record *otlp.LogRecord, sourceIP string, tokenInfo *service.TokenInfo) (string, error)
+	IngestDocuments(ctx context.Context, docs []service.Document, sourceIP string, tokenInfo *service.TokenInfo) []error
 	ValidateHECToken(ctx context.Context, token string) (*service.TokenInfo, error)
 	GetStats() models.IngestionStats
 	QueryAcks(ackIDs []string) map[string]bool
@@ -32,6 +36,11 @@ type HECHandler struct {
 	service        IngestServiceInterface
 	rateLimiter    ratelimit.RateLimiter
 	statsCollector *hecstats.Collector
+	otlpConverter  *otlp.Converter
+	bulkRouter     *esbulk.Router
+
+	maxBodySize           int64
+	allowQueryStringToken bool
 }
 
 func NewHECHandler(service IngestServiceInterface, rateLimiter ratelimit.RateLimiter, statsCollector *hecstats.Collector) *HECHandler {
@@ -39,9 +48,29 @@ func NewHECHandler(service IngestServiceInterface, rateLimiter ratelimit.RateLim
 		service:        service,
 		rateLimiter:    rateLimiter,
 		statsCollector: statsCollector,
+		maxBodySize:    defaultHECMaxBodySize,
+	}
+}
+
+// SetMaxBodySize bounds the decompressed size of HEC event and raw requests.
+func (h *HECHandler) SetMaxBodySize(n int64) {
+	if n > 0 {
+		h.maxBodySize = n
 	}
 }
 
+// SetAllowQueryStringToken accepts the HEC token in the token query
+// parameter, for clients that cannot set headers. Tokens in URLs end up in
+// access logs, so Splunk (and TelHawk) disable this by default.
+func (h *HECHandler) SetAllowQueryStringToken(allow bool) {
+	h.allowQueryStringToken = allow
+}
+
+// SetOTLPConverter configures how OTLP log records are routed to normalizers.
+func (h *HECHandler) SetOTLPConverter(converter *otlp.Converter) {
+	h.otlpConverter = converter
+}
+
 func (h *HECHandler) HandleEvent(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		h.sendError(w, hec.ErrInvalidEvent, http.StatusMethodNotAllowed)
@@ -70,9 +99,9 @@ func (h *HECHandler) HandleEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Authenticate HEC token
-	token := hec.ExtractToken(r.Header.Get("Authorization"))
-	if token == "" {
-		h.sendError(w, hec.ErrUnauthorized, http.StatusUnauthorized)
+	token, tokenErr, status := h.hecToken(r)
+	if tokenErr != nil {
+		h.sendError(w, tokenErr, status)
 		return
 	}
 
@@ -80,7 +109,7 @@ func (h *HECHandler) HandleEvent(w http.ResponseWriter, r *http.Request) {
 	tokenInfo, err := h.service.ValidateHECToken(r.Context(), token)
 	if err != nil {
 		log.Printf("HEC token validation failed: %v", err)
-		h.sendError(w, hec.ErrUnauthorized, http.StatusUnauthorized)
+		h.sendError(w, hec.ErrInvalidToken, http.StatusForbidden)
 		return
 	}
 
@@ -102,71 +131,61 @@ func (h *HECHandler) HandleEvent(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Read body
-	body, err := io.ReadAll(r.Body)
+	body, err := openHECBody(w, r, h.maxBodySize)
 	if err != nil {
-		h.sendError(w, hec.ErrInvalidEvent, http.StatusBadRequest)
-		return
-	}
-	defer r.Body.Close()
-
-	if len(body) == 0 {
-		h.sendError(w, hec.ErrNoData, http.StatusBadRequest)
+		h.sendBodyError(w, err, 0)
 		return
 	}
-
-	// Try to parse as single event or batch
-	var events []models.HECEvent
-
-	// Try single event first
-	var singleEvent models.HECEvent
-	if err := json.Unmarshal(body, &singleEvent); err == nil {
-		events = append(events, singleEvent)
-	} else {
-		// Try as newline-delimited JSON (NDJSON)
-		lines := strings.Split(string(body), "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
-			var event models.HECEvent
-			if err := json.Unmarshal([]byte(line), &event); err != nil {
-				h.sendError(w, hec.ErrInvalidEvent, http.StatusBadRequest)
-				return
-			}
-			events = append(events, event)
+	defer body.Close()
+
+	// Events are ingested as they are decoded. As with Splunk, events before
+	// an invalid one are kept and the response names the invalid event.
+	// The batch's events share one ack ID, as with Splunk.
+	decoder := newHECEventDecoder(body)
+	ctx, batch := service.NewAckBatch(r.Context())
+	defer batch.Seal()
+	var ackID string
+	var count, failedCount int
+	for {
+		event, err := decoder.Next()
+		if err == io.EOF {
+			break
 		}
-	}
+		if err != nil {
+			h.recordStats(tokenInfo, count-failedCount, sourceIP)
+			h.sendBodyError(w, err, count)
+			return
+		}
+		count++
 
-	// Ingest events
-	var ackID string
-	var failedCount int
-	for _, event := range events {
-		eventAckID, err := h.service.IngestEvent(r.Context(), &event, sourceIP, tokenInfo)
+		eventAckID, err := h.service.IngestEvent(ctx, event, sourceIP, tokenInfo)
 		if err != nil {
 			log.Printf("failed to ingest event in batch: %v", err)
 			failedCount++
 			continue
 		}
-		// Use the first ackID for the response (in batch, all share same ack)
 		if ackID == "" {
 			ackID = eventAckID
 		}
 	}
+	if batchAckID := batch.Seal(); batchAckID != "" {
+		ackID = batchAckID
+	}
 
-	if failedCount == len(events) {
+	if count == 0 {
+		h.sendError(w, hec.ErrNoData, http.StatusBadRequest)
+		return
+	}
+	if failedCount == count {
 		h.sendError(w, hec.ErrServerBusy, http.StatusServiceUnavailable)
 		return
 	}
 	if failedCount > 0 {
-		log.Printf("batch partially failed: %d/%d events failed", failedCount, len(events))
+		log.Printf("batch partially failed: %d/%d events failed", failedCount, count)
 	}
 
 	// Record HEC token usage stats
-	if h.statsCollector != nil && tokenInfo != nil {
-		h.statsCollector.Record(tokenInfo.TokenID, int64(len(events)), net.ParseIP(sourceIP))
-	}
+	h.recordStats(tokenInfo, count-failedCount, sourceIP)
 
 	// Check if client requested acknowledgement
 	channelID := r.Header.Get("X-Splunk-Request-Channel")
@@ -204,9 +223,9 @@ func (h *HECHandler) HandleRaw(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Authenticate HEC token
-	token := hec.ExtractToken(r.Header.Get("Authorization"))
-	if token == "" {
-		h.sendError(w, hec.ErrUnauthorized, http.StatusUnauthorized)
+	token, tokenErr, status := h.hecToken(r)
+	if tokenErr != nil {
+		h.sendError(w, tokenErr, status)
 		return
 	}
 
@@ -214,7 +233,7 @@ func (h *HECHandler) HandleRaw(w http.ResponseWriter, r *http.Request) {
 	tokenInfo, err := h.service.ValidateHECToken(r.Context(), token)
 	if err != nil {
 		log.Printf("HEC token validation failed: %v", err)
-		h.sendError(w, hec.ErrUnauthorized, http.StatusUnauthorized)
+		h.sendError(w, hec.ErrInvalidToken, http.StatusForbidden)
 		return
 	}
 
@@ -250,12 +269,18 @@ func (h *HECHandler) HandleRaw(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Read raw data
-	body, err := io.ReadAll(r.Body)
+	rawBody, err := openHECBody(w, r, h.maxBodySize)
 	if err != nil {
-		h.sendError(w, hec.ErrInvalidEvent, http.StatusBadRequest)
+		h.sendBodyError(w, err, 0)
+		return
+	}
+	defer rawBody.Close()
+
+	body, err := io.ReadAll(rawBody)
+	if err != nil {
+		h.sendBodyError(w, err, 0)
 		return
 	}
-	defer r.Body.Close()
 
 	if len(body) == 0 {
 		h.sendError(w, hec.ErrNoData, http.StatusBadRequest)
@@ -270,9 +295,7 @@ func (h *HECHandler) HandleRaw(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Record HEC token usage stats (raw endpoint = 1 event)
-	if h.statsCollector != nil && tokenInfo != nil {
-		h.statsCollector.Record(tokenInfo.TokenID, 1, net.ParseIP(sourceIP))
-	}
+	h.recordStats(tokenInfo, 1, sourceIP)
 
 	// Check if client requested acknowledgement
 	channelID := r.Header.Get("X-Splunk-Request-Channel")
@@ -340,6 +363,58 @@ func (h *HECHandler) sendSuccessWithAck(w http.ResponseWriter, ackID string) {
 	})
 }
 
+// hecToken returns the request's HEC token from the Authorization header or,
+// when enabled, the token query parameter. On failure it returns the Splunk
+// error and HTTP status to send.
+func (h *HECHandler) hecToken(r *http.Request) (string, *hec.HECError, int) {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		token := hec.ExtractToken(auth)
+		if token == "" {
+			return "", hec.ErrUnauthorized, http.StatusUnauthorized
+		}
+		return token, nil, http.StatusOK
+	}
+	if token := r.URL.Query().Get("token"); token != "" {
+		if !h.allowQueryStringToken {
+			return "", hec.ErrQueryStringAuthDisabled, http.StatusBadRequest
+		}
+		return token, nil, http.StatusOK
+	}
+	return "", hec.ErrTokenRequired, http.StatusUnauthorized
+}
+
+func (h *HECHandler) recordStats(tokenInfo *service.TokenInfo, events int, sourceIP string) {
+	if h.statsCollector != nil && tokenInfo != nil && events > 0 {
+		h.statsCollector.Record(tokenInfo.TokenID, int64(events), net.ParseIP(sourceIP))
+	}
+}
+
+// sendBodyError reports a failure reading or decoding the body at event
+// number eventNumber (0-based).
+func (h *HECHandler) sendBodyError(w http.ResponseWriter, err error, eventNumber int) {
+	var hecErr *hec.HECError
+	switch {
+	case errors.As(err, &hecErr):
+		h.sendEventError(w, hecErr, http.StatusBadRequest, eventNumber)
+	case errors.Is(err, io.EOF):
+		h.sendError(w, hec.ErrNoData, http.StatusBadRequest)
+	case isTooLarge(err):
+		h.sendEventError(w, hec.ErrInvalidEvent, http.StatusRequestEntityTooLarge, eventNumber)
+	default:
+		log.Printf("failed to read HEC body: %v", err)
+		h.sendEventError(w, hec.ErrInvalidEvent, http.StatusBadRequest, eventNumber)
+	}
+}
+
+func (h *HECHandler) sendEventError(w http.ResponseWriter, hecErr *hec.HECError, httpStatus int, eventNumber int) {
+	w.Header().Set("Content-Type", "application/json")
+	httputil.WriteJSON(w, httpStatus, models.HECResponse{
+		Text:               hecErr.Text,
+		Code:               hecErr.Code,
+		InvalidEventNumber: &eventNumber,
+	})
+}
+
 func (h *HECHandler) sendError(w http.ResponseWriter, hecErr *hec.HECError, httpStatus int) {
 	w.Header().Set("Content-Type", "application/json")
 	httputil.WriteJSON(w, httpStatus, models.HECResponse{
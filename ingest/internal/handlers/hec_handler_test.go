@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/otlp"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/service"
 )
 
@@ -21,19 +22,54 @@ type mockIngestService struct {
 	validateTokenInfo *service.TokenInfo
 	ingestEventAckID  string
 	ingestEventErr    error
+	events            []*models.HECEvent
 	ingestRawAckID    string
 	ingestRawErr      error
+	ingestOTLPAckID   string
+	ingestOTLPErr     error
+	otlpRecords       []*otlp.LogRecord
+	ingestDocumentFn  func(source []byte, sourceType, index string) error
+	documents         []ingestedDocument
+	documentBatches   int
+	validatedToken    string
 }
 
-func (m *mockIngestService) IngestEvent(event *models.HECEvent, sourceIP string, tokenInfo *service.TokenInfo) (string, error) {
+type ingestedDocument struct {
+	id         string
+	source     string
+	sourceType string
+	index      string
+	create     bool
+}
+
+func (m *mockIngestService) IngestEvent(ctx context.Context, event *models.HECEvent, sourceIP string, tokenInfo *service.TokenInfo) (string, error) {
+	m.events = append(m.events, event)
 	return m.ingestEventAckID, m.ingestEventErr
 }
 
-func (m *mockIngestService) IngestRaw(data []byte, sourceIP string, tokenInfo *service.TokenInfo, source, sourceType, host string) (string, error) {
+func (m *mockIngestService) IngestRaw(ctx context.Context, data []byte, sourceIP string, tokenInfo *service.TokenInfo, source, sourceType, host string) (string, error) {
 	return m.ingestRawAckID, m.ingestRawErr
 }
 
+func (m *mockIngestService) IngestOTLPLog(ctx context.Context, record *otlp.LogRecord, sourceIP string, tokenInfo *service.TokenInfo) (string, error) {
+	m.otlpRecords = append(m.otlpRecords, record)
+	return m.ingestOTLPAckID, m.ingestOTLPErr
+}
+
+func (m *mockIngestService) IngestDocuments(ctx context.Context, docs []service.Document, sourceIP string, tokenInfo *service.TokenInfo) []error {
+	m.documentBatches++
+	errs := make([]error, len(docs))
+	for i, doc := range docs {
+		m.documents = append(m.documents, ingestedDocument{id: doc.ID, source: string(doc.Source), sourceType: doc.SourceType, index: doc.Index, create: doc.Create})
+		if m.ingestDocumentFn != nil {
+			errs[i] = m.ingestDocumentFn(doc.Source, doc.SourceType, doc.Index)
+		}
+	}
+	return errs
+}
+
 func (m *mockIngestService) ValidateHECToken(ctx context.Context, token string) (*service.TokenInfo, error) {
+	m.validatedToken = token
 	if m.validateTokenErr != nil {
 		return nil, m.validateTokenErr
 	}
@@ -403,8 +439,14 @@ func TestHandleEvent_TokenValidationFailed(t *testing.T) {
 	rr := httptest.NewRecorder()
 	handler.HandleEvent(rr, req)
 
-	if rr.Code != http.StatusUnauthorized {
-		t.Errorf("Expected status 401, got %d", rr.Code)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", rr.Code)
+	}
+
+	var response models.HECResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	if response.Code != 4 {
+		t.Errorf("Expected code 4 (Invalid token), got %d", response.Code)
 	}
 }
 
@@ -462,8 +504,14 @@ func TestHandleRaw_TokenValidationFailed(t *testing.T) {
 	rr := httptest.NewRecorder()
 	handler.HandleRaw(rr, req)
 
-	if rr.Code != http.StatusUnauthorized {
-		t.Errorf("Expected status 401, got %d", rr.Code)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", rr.Code)
+	}
+
+	var response models.HECResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	if response.Code != 4 {
+		t.Errorf("Expected code 4 (Invalid token), got %d", response.Code)
 	}
 }
 
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+
+	"github.com/telhawk-systems/telhawk-stack/common/httputil"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/metrics"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/otlp"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/service"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/hec"
+)
+
+// maxOTLPBodySize bounds the decompressed size of an OTLP export request.
+const maxOTLPBodySize = 16 << 20
+
+var errRequestTooLarge = errors.New("request body too large")
+
+// HandleOTLPLogs handles POST /v1/logs (OTLP/HTTP, protobuf or JSON).
+// Exporters authenticate with a HEC token in the Authorization header.
+// Rejected records are reported through partial_success; retryable failures
+// return 429/503 so exporters back off and retry.
+func (h *HECHandler) HandleOTLPLogs(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+
+	if r.Method != http.MethodPost {
+		h.sendOTLPError(w, contentType, http.StatusMethodNotAllowed, codes.Unimplemented, "method not allowed")
+		return
+	}
+
+	switch otlp.MediaType(contentType) {
+	case otlp.ContentTypeProtobuf, otlp.ContentTypeJSON:
+	default:
+		h.sendOTLPError(w, otlp.ContentTypeProtobuf, http.StatusUnsupportedMediaType, codes.InvalidArgument,
+			"content type must be application/x-protobuf or application/json")
+		return
+	}
+
+	sourceIP := httputil.GetClientIP(r)
+
+	// Apply IP-based rate limiting BEFORE expensive operations
+	if h.rateLimiter != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+		defer cancel()
+
+		allowed, err := h.rateLimiter.Allow(ctx, "ip:"+sourceIP)
+		if err != nil {
+			log.Printf("rate limit check error: %v", err)
+		} else if !allowed {
+			metrics.EventsTotal.WithLabelValues("otlp", "rate_limited").Inc()
+			h.sendOTLPError(w, contentType, http.StatusTooManyRequests, codes.ResourceExhausted, "rate limit exceeded")
+			return
+		}
+	}
+
+	// Authenticate HEC token
+	token := hec.ExtractToken(r.Header.Get("Authorization"))
+	if token == "" {
+		h.sendOTLPError(w, contentType, http.StatusUnauthorized, codes.Unauthenticated, "missing HEC token")
+		return
+	}
+
+	tokenInfo, err := h.service.ValidateHECToken(r.Context(), token)
+	if err != nil {
+		log.Printf("HEC token validation failed: %v", err)
+		h.sendOTLPError(w, contentType, http.StatusUnauthorized, codes.Unauthenticated, "invalid HEC token")
+		return
+	}
+
+	// Optional: Apply per-token rate limiting after authentication
+	if h.rateLimiter != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+		defer cancel()
+
+		allowed, err := h.rateLimiter.Allow(ctx, "token:"+token)
+		if err != nil {
+			log.Printf("rate limit check error: %v", err)
+		} else if !allowed {
+			metrics.EventsTotal.WithLabelValues("otlp", "token_rate_limited").Inc()
+			h.sendOTLPError(w, contentType, http.StatusTooManyRequests, codes.ResourceExhausted, "rate limit exceeded")
+			return
+		}
+	}
+
+	body, err := readRequestBody(r, maxOTLPBodySize)
+	if err != nil {
+		if isTooLarge(err) {
+			h.sendOTLPError(w, contentType, http.StatusRequestEntityTooLarge, codes.ResourceExhausted, err.Error())
+			return
+		}
+		h.sendOTLPError(w, contentType, http.StatusBadRequest, codes.InvalidArgument, err.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	req, err := otlp.DecodeLogs(body, contentType)
+	if err != nil {
+		h.sendOTLPError(w, contentType, http.StatusBadRequest, codes.InvalidArgument, err.Error())
+		return
+	}
+
+	converter := h.otlpConverter
+	if converter == nil {
+		converter = otlp.NewConverter("", "")
+	}
+	records := converter.Convert(req)
+
+	ctx, batch := service.NewAckBatch(r.Context())
+	defer batch.Seal()
+	var ackID string
+	var rejected int
+	for i := range records {
+		recordAckID, err := h.service.IngestOTLPLog(ctx, &records[i], sourceIP, tokenInfo)
+		if err != nil {
+			log.Printf("failed to ingest OTLP log record: %v", err)
+			rejected++
+			continue
+		}
+		if ackID == "" {
+			ackID = recordAckID
+		}
+	}
+	if batchAckID := batch.Seal(); batchAckID != "" {
+		ackID = batchAckID
+	}
+
+	// Nothing accepted: ask the exporter to retry the whole request
+	if len(records) > 0 && rejected == len(records) {
+		h.sendOTLPError(w, contentType, http.StatusServiceUnavailable, codes.Unavailable, "server is busy")
+		return
+	}
+
+	if h.statsCollector != nil && tokenInfo != nil {
+		h.statsCollector.Record(tokenInfo.TokenID, int64(len(records)-rejected), net.ParseIP(sourceIP))
+	}
+
+	resp := &collogspb.ExportLogsServiceResponse{}
+	if rejected > 0 {
+		log.Printf("OTLP export partially failed: %d/%d records rejected", rejected, len(records))
+		resp.PartialSuccess = &collogspb.ExportLogsPartialSuccess{
+			RejectedLogRecords: int64(rejected),
+			ErrorMessage:       "event queue full",
+		}
+	}
+
+	// Same acknowledgement channel as HEC: query the ID at /services/collector/ack
+	if r.Header.Get("X-Splunk-Request-Channel") != "" && ackID != "" {
+		w.Header().Set("X-Telhawk-Ack-Id", ackID)
+	}
+
+	h.sendOTLPResponse(w, contentType, http.StatusOK, resp)
+}
+
+// readRequestBody reads up to maxSize bytes of the request body,
+// decompressing gzip if requested.
+func readRequestBody(r *http.Request, maxSize int64) ([]byte, error) {
+	var reader io.Reader = r.Body
+	switch strings.ToLower(r.Header.Get("Content-Encoding")) {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", r.Header.Get("Content-Encoding"))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	if int64(len(body)) > maxSize {
+		return nil, errRequestTooLarge
+	}
+	return body, nil
+}
+
+func (h *HECHandler) sendOTLPResponse(w http.ResponseWriter, contentType string, status int, resp *collogspb.ExportLogsServiceResponse) {
+	data, err := otlp.EncodeResponse(resp, contentType)
+	if err != nil {
+		log.Printf("failed to encode OTLP response: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", responseContentType(contentType))
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// sendOTLPError writes a google.rpc.Status body as required by OTLP/HTTP.
+func (h *HECHandler) sendOTLPError(w http.ResponseWriter, contentType string, status int, code codes.Code, message string) {
+	data, err := otlp.EncodeResponse(&spb.Status{Code: int32(code), Message: message}, contentType)
+	if err != nil {
+		log.Printf("failed to encode OTLP error: %v", err)
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", responseContentType(contentType))
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func responseContentType(requestContentType string) string {
+	if otlp.MediaType(requestContentType) == otlp.ContentTypeJSON {
+		return otlp.ContentTypeJSON
+	}
+	return otlp.ContentTypeProtobuf
+}
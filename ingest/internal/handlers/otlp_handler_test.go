@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/otlp"
+)
+
+func otlpTestRequest(records int) *collogspb.ExportLogsServiceRequest {
+	logRecords := make([]*logspb.LogRecord, records)
+	for i := range logRecords {
+		logRecords[i] = &logspb.LogRecord{
+			Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "user login"}},
+			Attributes: []*commonpb.KeyValue{
+				{Key: "event.domain", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "authentication"}}},
+			},
+		}
+	}
+	return &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+				{Key: "service.name", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "billing"}}},
+			}},
+			ScopeLogs: []*logspb.ScopeLogs{{LogRecords: logRecords}},
+		}},
+	}
+}
+
+func newOTLPRequest(t *testing.T, body []byte, contentType string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Splunk test-token")
+	return req
+}
+
+func TestHandleOTLPLogs_Protobuf(t *testing.T) {
+	mockService := &mockIngestService{}
+	handler := NewHECHandler(mockService, nil, nil)
+
+	body, _ := proto.Marshal(otlpTestRequest(2))
+	rr := httptest.NewRecorder()
+	handler.HandleOTLPLogs(rr, newOTLPRequest(t, body, "application/x-protobuf"))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/x-protobuf" {
+		t.Errorf("expected protobuf response, got %q", ct)
+	}
+	var resp collogspb.ExportLogsServiceResponse
+	if err := proto.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.GetPartialSuccess() != nil {
+		t.Errorf("expected no partial success, got %v", resp.GetPartialSuccess())
+	}
+	if len(mockService.otlpRecords) != 2 {
+		t.Fatalf("expected 2 records ingested, got %d", len(mockService.otlpRecords))
+	}
+	record := mockService.otlpRecords[0]
+	if record.SourceType != "authentication" || record.Source != "billing" {
+		t.Errorf("unexpected record routing: sourcetype=%q source=%q", record.SourceType, record.Source)
+	}
+}
+
+func TestHandleOTLPLogs_JSONWithCustomRoute(t *testing.T) {
+	mockService := &mockIngestService{}
+	handler := NewHECHandler(mockService, nil, nil)
+	handler.SetOTLPConverter(otlp.NewConverter("log.kind", "generic"))
+
+	body, _ := protojson.Marshal(otlpTestRequest(1))
+	rr := httptest.NewRecorder()
+	handler.HandleOTLPLogs(rr, newOTLPRequest(t, body, "application/json"))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON response, got %q", ct)
+	}
+	if got := mockService.otlpRecords[0].SourceType; got != "generic" {
+		t.Errorf("expected default sourcetype, got %q", got)
+	}
+}
+
+func TestHandleOTLPLogs_Gzip(t *testing.T) {
+	mockService := &mockIngestService{}
+	handler := NewHECHandler(mockService, nil, nil)
+
+	body, _ := proto.Marshal(otlpTestRequest(1))
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(body)
+	gz.Close()
+
+	req := newOTLPRequest(t, buf.Bytes(), "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.HandleOTLPLogs(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if len(mockService.otlpRecords) != 1 {
+		t.Errorf("expected 1 record ingested, got %d", len(mockService.otlpRecords))
+	}
+}
+
+func TestHandleOTLPLogs_Errors(t *testing.T) {
+	validBody, _ := proto.Marshal(otlpTestRequest(1))
+
+	tests := []struct {
+		name        string
+		service     *mockIngestService
+		rateLimiter *mockRateLimiter
+		body        []byte
+		contentType string
+		auth        string
+		wantStatus  int
+	}{
+		{"missing token", &mockIngestService{}, nil, validBody, "application/x-protobuf", "", http.StatusUnauthorized},
+		{"invalid token", &mockIngestService{validateTokenErr: errors.New("invalid")}, nil, validBody, "application/x-protobuf", "Splunk bad", http.StatusUnauthorized},
+		{"unsupported content type", &mockIngestService{}, nil, validBody, "text/plain", "Splunk test-token", http.StatusUnsupportedMediaType},
+		{"malformed body", &mockIngestService{}, nil, []byte{0xff, 0xff}, "application/x-protobuf", "Splunk test-token", http.StatusBadRequest},
+		{"queue full", &mockIngestService{ingestOTLPErr: errors.New("event queue full")}, nil, validBody, "application/x-protobuf", "Splunk test-token", http.StatusServiceUnavailable},
+		{"rate limited", &mockIngestService{}, &mockRateLimiter{allowFunc: func(ctx context.Context, key string) (bool, error) { return false, nil }}, validBody, "application/x-protobuf", "Splunk test-token", http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handler *HECHandler
+			if tt.rateLimiter != nil {
+				handler = NewHECHandler(tt.service, tt.rateLimiter, nil)
+			} else {
+				handler = NewHECHandler(tt.service, nil, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/logs", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rr := httptest.NewRecorder()
+			handler.HandleOTLPLogs(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			var status spb.Status
+			if err := proto.Unmarshal(rr.Body.Bytes(), &status); err != nil {
+				t.Fatalf("expected google.rpc.Status body: %v", err)
+			}
+			if status.GetMessage() == "" {
+				t.Error("expected status message")
+			}
+		})
+	}
+}
+
+func TestHandleOTLPLogs_TooLarge(t *testing.T) {
+	// Compresses to a few KB but decompresses past the limit
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(make([]byte, maxOTLPBodySize+1))
+	gz.Close()
+
+	service := &mockIngestService{}
+	handler := NewHECHandler(service, nil, nil)
+
+	req := newOTLPRequest(t, buf.Bytes(), "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.HandleOTLPLogs(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d", http.StatusRequestEntityTooLarge, rr.Code)
+	}
+	var status spb.Status
+	if err := proto.Unmarshal(rr.Body.Bytes(), &status); err != nil {
+		t.Fatalf("expected google.rpc.Status body: %v", err)
+	}
+}
+
+func TestHandleOTLPLogs_AckHeader(t *testing.T) {
+	mockService := &mockIngestService{ingestOTLPAckID: "ack-1"}
+	handler := NewHECHandler(mockService, nil, nil)
+
+	body, _ := proto.Marshal(otlpTestRequest(1))
+	req := newOTLPRequest(t, body, "application/x-protobuf")
+	req.Header.Set("X-Splunk-Request-Channel", "channel-1")
+	rr := httptest.NewRecorder()
+	handler.HandleOTLPLogs(rr, req)
+
+	if got := rr.Header().Get("X-Telhawk-Ack-Id"); got != "ack-1" {
+		t.Errorf("expected ack header ack-1, got %q", got)
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/telhawk-systems/telhawk-stack/common/httputil"
+	"github.com/telhawk-systems/telhawk-stack/common/indices"
+)
+
+// ClientOnboarder creates a client's own index series (storage.Client).
+type ClientOnboarder interface {
+	OnboardClient(ctx context.Context, clientID string) error
+	ClientWriteAlias(clientID string) (string, error)
+}
+
+// TenantHandler serves tenant onboarding for per-client index routing.
+// Onboarding also happens on a client's first event; this endpoint lets
+// operators provision the index series (and catch OpenSearch errors) up front.
+type TenantHandler struct {
+	storage ClientOnboarder
+	auth    UserTokenValidator
+}
+
+func NewTenantHandler(storage ClientOnboarder, auth UserTokenValidator) *TenantHandler {
+	return &TenantHandler{
+		storage: storage,
+		auth:    auth,
+	}
+}
+
+// Onboard handles POST /api/v1/tenants/{client_id}/onboard
+func (h *TenantHandler) Onboard(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r, h.auth, "Tenants") {
+		return
+	}
+
+	clientID := r.PathValue("client_id")
+	if clientID == "" {
+		httputil.WriteJSONAPIValidationError(w, "client_id is required")
+		return
+	}
+	if err := indices.ValidateClientID(clientID); err != nil {
+		httputil.WriteJSONAPIValidationError(w, err.Error())
+		return
+	}
+
+	if err := h.storage.OnboardClient(r.Context(), clientID); err != nil {
+		log.Printf("Tenants: failed to onboard client %s: %v", clientID, err)
+		httputil.WriteJSONAPIInternalError(w, "failed to onboard client")
+		return
+	}
+	writeAlias, err := h.storage.ClientWriteAlias(clientID)
+	if err != nil {
+		log.Printf("Tenants: failed to resolve write alias for client %s: %v", clientID, err)
+		httputil.WriteJSONAPIInternalError(w, "failed to onboard client")
+		return
+	}
+
+	httputil.WriteJSONAPIResource(w, http.StatusOK, "tenant", clientID, map[string]interface{}{
+		"client_id":   clientID,
+		"write_alias": writeAlias,
+	})
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockOnboarder struct {
+	onboarded []string
+	err       error
+}
+
+func (m *mockOnboarder) OnboardClient(ctx context.Context, clientID string) error {
+	m.onboarded = append(m.onboarded, clientID)
+	return m.err
+}
+
+func (m *mockOnboarder) ClientWriteAlias(clientID string) (string, error) {
+	return "telhawk-events-" + clientID + "-write", nil
+}
+
+func onboardRequest(h *TenantHandler, clientID, authHeader string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/tenants/{client_id}/onboard", h.Onboard)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tenants/"+clientID+"/onboard", nil)
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestTenantHandler_Onboard(t *testing.T) {
+	storage := &mockOnboarder{}
+	w := onboardRequest(NewTenantHandler(storage, adminValidator()), "acme", "Bearer admin")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(storage.onboarded) != 1 || storage.onboarded[0] != "acme" {
+		t.Errorf("expected acme to be onboarded, got %v", storage.onboarded)
+	}
+	if !strings.Contains(w.Body.String(), `"write_alias":"telhawk-events-acme-write"`) {
+		t.Errorf("expected write alias in response, got %s", w.Body.String())
+	}
+}
+
+func TestTenantHandler_RequiresAdmin(t *testing.T) {
+	storage := &mockOnboarder{}
+	w := onboardRequest(NewTenantHandler(storage, adminValidator()), "acme", "")
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", w.Code)
+	}
+	if len(storage.onboarded) != 0 {
+		t.Errorf("expected no onboarding without a token, got %v", storage.onboarded)
+	}
+}
+
+func TestTenantHandler_InvalidClientID(t *testing.T) {
+	storage := &mockOnboarder{}
+	w := onboardRequest(NewTenantHandler(storage, adminValidator()), "ACME", "Bearer admin")
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+	if len(storage.onboarded) != 0 {
+		t.Errorf("expected no onboarding for an invalid client ID, got %v", storage.onboarded)
+	}
+}
+
+func TestTenantHandler_OnboardFailure(t *testing.T) {
+	storage := &mockOnboarder{err: errors.New("opensearch unavailable")}
+	w := onboardRequest(NewTenantHandler(storage, adminValidator()), "acme", "Bearer admin")
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/telhawk-systems/telhawk-stack/common/httputil"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/transform"
+)
+
+// TransformRuleService manages per-client transform rules (transform.Engine).
+type TransformRuleService interface {
+	Rules(ctx context.Context, clientID string) ([]transform.Rule, error)
+	Rule(ctx context.Context, clientID, id string) (*transform.Rule, error)
+	Create(ctx context.Context, clientID string, rule transform.Rule) error
+	Update(ctx context.Context, clientID string, rule transform.Rule) error
+	Delete(ctx context.Context, clientID, id string) error
+}
+
+// TransformRuleHandler serves the per-client transform rule API. Every
+// endpoint requires an admin access token; admins of a client may only
+// manage that client's rules.
+type TransformRuleHandler struct {
+	service TransformRuleService
+	auth    UserTokenValidator
+}
+
+func NewTransformRuleHandler(service TransformRuleService, auth UserTokenValidator) *TransformRuleHandler {
+	return &TransformRuleHandler{
+		service: service,
+		auth:    auth,
+	}
+}
+
+// authorize checks the caller may manage the rules of the client in the path
+// and returns that client ID.
+func (h *TransformRuleHandler) authorize(w http.ResponseWriter, r *http.Request) (string, bool) {
+	resp, ok := authorizeAdmin(w, r, h.auth, "Transform rules")
+	if !ok {
+		return "", false
+	}
+	clientID := r.PathValue("client_id")
+	if clientID == "" {
+		httputil.WriteJSONAPIValidationError(w, "client_id is required")
+		return "", false
+	}
+	if resp.ClientID != "" && resp.ClientID != clientID {
+		httputil.WriteJSONAPIForbiddenError(w, "cannot manage another client's transform rules")
+		return "", false
+	}
+	return clientID, true
+}
+
+// List handles GET /api/v1/clients/{client_id}/transform-rules
+func (h *TransformRuleHandler) List(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	rules, err := h.service.Rules(r.Context(), clientID)
+	if err != nil {
+		h.writeServiceError(w, err, "")
+		return
+	}
+
+	items := make([]map[string]interface{}, len(rules))
+	for i := range rules {
+		items[i] = map[string]interface{}{
+			"id":         rules[i].ID,
+			"attributes": rules[i],
+		}
+	}
+	httputil.WriteJSONAPICollection(w, http.StatusOK, "transform_rule", items, nil)
+}
+
+// Show handles GET /api/v1/clients/{client_id}/transform-rules/{rule_id}
+func (h *TransformRuleHandler) Show(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	id := r.PathValue("rule_id")
+	rule, err := h.service.Rule(r.Context(), clientID, id)
+	if err != nil {
+		h.writeServiceError(w, err, id)
+		return
+	}
+	httputil.WriteJSONAPIResource(w, http.StatusOK, "transform_rule", rule.ID, rule)
+}
+
+// Create handles POST /api/v1/clients/{client_id}/transform-rules. The rule
+// is appended to the client's rules.
+func (h *TransformRuleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	var rule transform.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		httputil.WriteJSONAPIValidationError(w, "invalid request body")
+		return
+	}
+
+	if err := h.service.Create(r.Context(), clientID, rule); err != nil {
+		h.writeServiceError(w, err, rule.ID)
+		return
+	}
+	httputil.WriteJSONAPIResource(w, http.StatusCreated, "transform_rule", rule.ID, rule)
+}
+
+// Update handles PUT /api/v1/clients/{client_id}/transform-rules/{rule_id}
+func (h *TransformRuleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	id := r.PathValue("rule_id")
+	var rule transform.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		httputil.WriteJSONAPIValidationError(w, "invalid request body")
+		return
+	}
+	if rule.ID != "" && rule.ID != id {
+		httputil.WriteJSONAPIValidationError(w, "rule id does not match the URL")
+		return
+	}
+	rule.ID = id
+
+	if err := h.service.Update(r.Context(), clientID, rule); err != nil {
+		h.writeServiceError(w, err, id)
+		return
+	}
+	httputil.WriteJSONAPIResource(w, http.StatusOK, "transform_rule", rule.ID, rule)
+}
+
+// Delete handles DELETE /api/v1/clients/{client_id}/transform-rules/{rule_id}
+func (h *TransformRuleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := h.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	id := r.PathValue("rule_id")
+	if err := h.service.Delete(r.Context(), clientID, id); err != nil {
+		h.writeServiceError(w, err, id)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TransformRuleHandler) writeServiceError(w http.ResponseWriter, err error, id string) {
+	var validationErr *transform.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		httputil.WriteJSONAPIValidationError(w, validationErr.Err.Error())
+	case errors.Is(err, transform.ErrNotFound):
+		httputil.WriteJSONAPINotFoundError(w, "transform_rule", id)
+	case errors.Is(err, transform.ErrConflict):
+		httputil.WriteJSONAPIError(w, http.StatusConflict, "conflict", "Conflict", "a transform rule with this id already exists")
+	default:
+		log.Printf("Transform rules: request failed: %v", err)
+		httputil.WriteJSONAPIInternalError(w, "transform rule request failed")
+	}
+}
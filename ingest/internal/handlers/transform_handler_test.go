@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/authclient"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/transform"
+)
+
+func transformRuleMux(h *TransformRuleHandler) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/clients/{client_id}/transform-rules", h.List)
+	mux.HandleFunc("POST /api/v1/clients/{client_id}/transform-rules", h.Create)
+	mux.HandleFunc("GET /api/v1/clients/{client_id}/transform-rules/{rule_id}", h.Show)
+	mux.HandleFunc("PUT /api/v1/clients/{client_id}/transform-rules/{rule_id}", h.Update)
+	mux.HandleFunc("DELETE /api/v1/clients/{client_id}/transform-rules/{rule_id}", h.Delete)
+	return mux
+}
+
+func transformRuleRequest(mux *http.ServeMux, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer admin")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestTransformRuleHandler_CRUD(t *testing.T) {
+	mux := transformRuleMux(NewTransformRuleHandler(transform.NewEngine(transform.NewMemoryStore(), ""), adminValidator()))
+	base := "/api/v1/clients/acme/transform-rules"
+
+	w := transformRuleRequest(mux, http.MethodPost, base, `{"id": "drop-debug", "sourcetypes": ["app:*"], "action": "drop"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = transformRuleRequest(mux, http.MethodPost, base, `{"id": "drop-debug", "action": "drop"}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("duplicate create: expected status 409, got %d", w.Code)
+	}
+
+	w = transformRuleRequest(mux, http.MethodPut, base+"/drop-debug", `{"action": "sample", "sample_rate": 10}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = transformRuleRequest(mux, http.MethodGet, base, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"sample_rate":10`) {
+		t.Errorf("list: expected the updated rule, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = transformRuleRequest(mux, http.MethodGet, "/api/v1/clients/globex/transform-rules/drop-debug", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("show: expected rules to be per client, got %d", w.Code)
+	}
+
+	w = transformRuleRequest(mux, http.MethodDelete, base+"/drop-debug", "")
+	if w.Code != http.StatusNoContent {
+		t.Errorf("delete: expected status 204, got %d", w.Code)
+	}
+	w = transformRuleRequest(mux, http.MethodDelete, base+"/drop-debug", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second delete: expected status 404, got %d", w.Code)
+	}
+}
+
+func TestTransformRuleHandler_RejectsInvalidRules(t *testing.T) {
+	mux := transformRuleMux(NewTransformRuleHandler(transform.NewEngine(transform.NewMemoryStore(), ""), adminValidator()))
+
+	tests := map[string]struct {
+		method, path, body string
+	}{
+		"malformed body": {http.MethodPost, "/api/v1/clients/acme/transform-rules", `{`},
+		"invalid rule":   {http.MethodPost, "/api/v1/clients/acme/transform-rules", `{"id": "r", "action": "sample"}`},
+		"mismatched id":  {http.MethodPut, "/api/v1/clients/acme/transform-rules/a", `{"id": "b", "action": "drop"}`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := transformRuleRequest(mux, tt.method, tt.path, tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTransformRuleHandler_ScopesClientAdmins(t *testing.T) {
+	validator := &mockUserValidator{resp: &authclient.ValidateTokenResponse{Valid: true, Roles: []string{"admin"}, ClientID: "acme"}}
+	mux := transformRuleMux(NewTransformRuleHandler(transform.NewEngine(transform.NewMemoryStore(), ""), validator))
+
+	if w := transformRuleRequest(mux, http.MethodGet, "/api/v1/clients/acme/transform-rules", ""); w.Code != http.StatusOK {
+		t.Errorf("own client: expected status 200, got %d", w.Code)
+	}
+	if w := transformRuleRequest(mux, http.MethodGet, "/api/v1/clients/globex/transform-rules", ""); w.Code != http.StatusForbidden {
+		t.Errorf("other client: expected status 403, got %d", w.Code)
+	}
+}
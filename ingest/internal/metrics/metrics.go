@@ -92,4 +92,31 @@ var (
 			Help: "Total number of completed acknowledgements",
 		},
 	)
+
+	// DLQ replay metrics
+	DLQReplaysTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telhawk_ingest_dlq_replays_total",
+			Help: "Total number of DLQ entries replayed through the pipeline",
+		},
+		[]string{"result"},
+	)
+
+	// Live tail metrics
+	TailEventsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telhawk_ingest_tail_events_total",
+			Help: "Total number of stored events offered to live tail",
+		},
+		[]string{"status"}, // published, sampled_out, filtered, dropped
+	)
+
+	// Transform rule metrics
+	TransformRuleEventsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telhawk_ingest_transform_rule_events_total",
+			Help: "Total number of events matched by ingest transform rules",
+		},
+		[]string{"client_id", "rule", "result"}, // dropped, sampled_out, sampled_in, redacted, unchanged, routed
+	)
 )
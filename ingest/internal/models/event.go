@@ -26,7 +26,7 @@ type HECResponse struct {
 	Text               string `json:"text"`
 	Code               int    `json:"code"`
 	AckID              string `json:"ackId,omitempty"`
-	InvalidEventNumber int    `json:"invalid-event-number,omitempty"`
+	InvalidEventNumber *int   `json:"invalid-event-number,omitempty"` // Set for per-event errors; 0 is the first event
 }
 
 // Internal event representation
@@ -44,8 +44,9 @@ type Event struct {
 	HECTokenID string                 `json:"hec_token_id"`
 	ClientID   string                 `json:"client_id"` // Client UUID for data isolation
 	Signature  string                 `json:"signature"`
-	AckID      string                 `json:"ack_id,omitempty"` // Track ack ID for completion
-	Ctx        context.Context        `json:"-"`                // Request context for propagation
+	AckID      string                 `json:"ack_id,omitempty"`     // Track ack ID for completion
+	Attributes map[string]string      `json:"attributes,omitempty"` // Extra envelope attributes (e.g. OTLP resource/scope)
+	Ctx        context.Context        `json:"-"`                    // Request context for propagation
 }
 
 type IngestionStats struct {
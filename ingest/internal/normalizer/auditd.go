@@ -0,0 +1,447 @@
+package normalizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/system"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/objects"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+var auditdProduct = ocsf.Product{Name: "auditd", Vendor: "Linux"}
+
+// auditUnset is the uid auditd writes when no login uid is set.
+const auditUnset = "4294967295"
+
+// auditRecordPattern matches the header of an audit record, with the node
+// name auditd adds when name_format is set.
+var auditRecordPattern = regexp.MustCompile(`^(?:node=(\S+) )?type=(\S+) msg=audit\((\d+)\.(\d+):(\d+)\):\s*(.*)$`)
+
+// auditFieldPattern matches key=value pairs: double-quoted strings,
+// single-quoted nested messages or bare (possibly hex-encoded) values.
+var auditFieldPattern = regexp.MustCompile(`([\w\[\]-]+)=("[^"]*"|'[^']*'|\S*)`)
+
+// auditSyscalls names the syscall numbers the normalizer maps, by audit
+// architecture. Records in the enriched log format carry the name already.
+var auditSyscalls = map[string]map[string]string{
+	"c000003e": { // x86_64
+		"2": "open", "59": "execve", "76": "truncate", "77": "ftruncate", "82": "rename",
+		"83": "mkdir", "84": "rmdir", "85": "creat", "87": "unlink", "90": "chmod",
+		"91": "fchmod", "92": "chown", "93": "fchown", "94": "lchown", "188": "setxattr",
+		"257": "openat", "258": "mkdirat", "260": "fchownat", "263": "unlinkat",
+		"264": "renameat", "268": "fchmodat", "316": "renameat2", "322": "execveat",
+	},
+	"c00000b7": { // aarch64
+		"34": "mkdirat", "35": "unlinkat", "38": "renameat", "45": "truncate",
+		"46": "ftruncate", "52": "fchmod", "53": "fchmodat", "54": "fchownat",
+		"55": "fchown", "56": "openat", "221": "execve", "276": "renameat2", "281": "execveat",
+	},
+}
+
+// auditFileActivities maps file syscalls to File System Activity
+// activities. Opens that create the file are reported as Create.
+var auditFileActivities = map[string]int{
+	"open":      system.FileActivityActivityOpen,
+	"openat":    system.FileActivityActivityOpen,
+	"creat":     system.FileActivityActivityCreate,
+	"mkdir":     system.FileActivityActivityCreate,
+	"mkdirat":   system.FileActivityActivityCreate,
+	"unlink":    system.FileActivityActivityDelete,
+	"unlinkat":  system.FileActivityActivityDelete,
+	"rmdir":     system.FileActivityActivityDelete,
+	"rename":    system.FileActivityActivityRename,
+	"renameat":  system.FileActivityActivityRename,
+	"renameat2": system.FileActivityActivityRename,
+	"truncate":  system.FileActivityActivityUpdate,
+	"ftruncate": system.FileActivityActivityUpdate,
+	"chmod":     system.FileActivityActivitySetSecurity,
+	"fchmod":    system.FileActivityActivitySetSecurity,
+	"fchmodat":  system.FileActivityActivitySetSecurity,
+	"chown":     system.FileActivityActivitySetSecurity,
+	"fchown":    system.FileActivityActivitySetSecurity,
+	"lchown":    system.FileActivityActivitySetSecurity,
+	"fchownat":  system.FileActivityActivitySetSecurity,
+	"setxattr":  system.FileActivityActivitySetAttributes,
+}
+
+// auditRecord is one parsed audit record. Enriched holds the names auditd
+// appends after a group separator in the enriched log format (UID="root").
+type auditRecord struct {
+	Type     string
+	Node     string
+	Time     time.Time
+	Serial   string
+	Fields   map[string]string
+	Enriched map[string]string
+}
+
+// AuditdNormalizer converts Linux audit records into OCSF events. The records
+// of one audit event share a serial and are assembled into one event:
+// execve calls (SYSCALL, EXECVE, CWD, PATH and PROCTITLE records) become
+// Process Activity: Launch, file syscalls with PATH records become File
+// System Activity, and other records are stored as base events.
+//
+// The payload is the audit log text, either raw or in the "message" or
+// "event" field of a JSON payload. Records of an event must arrive together;
+// for the raw endpoint, a parsing profile with merge_key
+// 'msg=audit\(\d+\.\d+:(\d+)\)' groups them by serial. A payload with
+// records of more than one serial is rejected, so it goes to the DLQ whole
+// rather than losing all but one event. Rule keys that carry ATT&CK
+// technique IDs (key="T1059_Command_Line") become attacks.
+type AuditdNormalizer struct{}
+
+// Supports matches JSON payloads with sourcetypes linux:audit, linux_audit
+// and auditd.
+func (AuditdNormalizer) Supports(format, sourceType string) bool {
+	if format != "json" {
+		return false
+	}
+	switch strings.ToLower(sourceType) {
+	case "linux:audit", "linux_audit", "auditd":
+		return true
+	}
+	return false
+}
+
+// Normalize assembles the records of the audit event in the payload.
+func (AuditdNormalizer) Normalize(ctx context.Context, envelope *models.RawEventEnvelope) (*ocsf.Event, error) {
+	_ = ctx
+	records := parseAuditRecords(auditText(envelope.Payload))
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no audit records in payload")
+	}
+	if serials := auditSerials(records); len(serials) > 1 {
+		return nil, fmt.Errorf("payload holds %d audit events (serials %s); send one event per payload, e.g. with a merge_key parsing profile",
+			len(serials), strings.Join(serials, ", "))
+	}
+	first := records[0]
+	raw := map[string]interface{}{"serial": first.Serial, "records": auditRawRecords(records)}
+
+	byType := make(map[string]*auditRecord)
+	var paths []*auditRecord
+	for _, record := range records {
+		if record.Type == "PATH" {
+			paths = append(paths, record)
+		} else if byType[record.Type] == nil {
+			byType[record.Type] = record
+		}
+	}
+
+	var event *ocsf.Event
+	syscall := byType["SYSCALL"]
+	name := auditSyscallName(syscall)
+	switch {
+	case syscall != nil && (byType["EXECVE"] != nil || name == "execve" || name == "execveat"):
+		event = &system.NewProcessActivity(system.ProcessActivityActivityLaunch).Event
+		initVendorEvent(event, envelope, raw, auditdProduct, first.Time)
+		event.Process = auditProcess(syscall, byType["EXECVE"], byType["PROCTITLE"], byType["CWD"])
+		event.Process.CreatedTime = first.Time.UnixMilli()
+		event.Actor = &objects.Actor{Process: event.Process.ParentProcess, User: auditLoginUser(syscall)}
+	case syscall != nil && auditFileActivities[name] != 0 && len(paths) > 0:
+		event = auditFileEvent(envelope, raw, first.Time, name, syscall, paths)
+		event.Actor = &objects.Actor{
+			Process: auditProcess(syscall, nil, byType["PROCTITLE"], byType["CWD"]),
+			User:    auditLoginUser(syscall),
+		}
+	default:
+		event = unsupportedVendorLog(envelope, raw, auditdProduct, first.Time, first.Type)
+		event.Properties["serial"] = first.Serial
+		return event, nil
+	}
+
+	setSeverity(event, ocsf.SeverityInformational)
+	if syscall.Fields["success"] == "no" {
+		setStatus(event, ocsf.StatusFailure, syscall.Fields["exit"])
+	} else {
+		setStatus(event, ocsf.StatusSuccess, "")
+	}
+
+	hostname := first.Node
+	if hostname == "" {
+		hostname = envelope.Attributes["host"]
+	}
+	event.Device = &objects.Device{
+		Hostname: hostname,
+		Os:       &objects.Os{Name: "Linux", Type: "Linux", TypeId: 200},
+	}
+	event.Properties["serial"] = first.Serial
+	event.Properties["syscall"] = name
+	if key := auditValue(syscall, "key"); key != "" && key != "(null)" {
+		event.Properties["key"] = key
+		if attacks := techniqueAttacks(key); len(attacks) > 0 {
+			event.ClassAttributes["attacks"] = attacks
+			hoistAttacks(event, attacks)
+		}
+	}
+	return event, nil
+}
+
+// auditText returns the audit log text of a payload: the raw bytes, or the
+// message or event field of a JSON payload.
+func auditText(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return string(data)
+	}
+	payload, err := decodeJSONObject(trimmed)
+	if err != nil {
+		return string(data)
+	}
+	if text := getString(payload, "message"); text != "" {
+		return text
+	}
+	if text := getString(payload, "event"); text != "" {
+		return text
+	}
+	return getString(payload, "event.message")
+}
+
+// parseAuditRecords parses each audit record line, skipping other lines.
+func parseAuditRecords(text string) []*auditRecord {
+	var records []*auditRecord
+	for _, line := range strings.Split(text, "\n") {
+		m := auditRecordPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		seconds, _ := strconv.ParseInt(m[3], 10, 64)
+		millis, _ := strconv.ParseInt(m[4], 10, 64)
+		record := &auditRecord{
+			Node:   m[1],
+			Type:   m[2],
+			Time:   time.Unix(seconds, millis*int64(time.Millisecond)).UTC(),
+			Serial: m[5],
+		}
+		body, enriched, _ := strings.Cut(m[6], "\x1d")
+		record.Fields = parseAuditFields(body)
+		if enriched != "" {
+			record.Enriched = parseAuditFields(enriched)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// parseAuditFields parses key=value pairs. The nested msg='...' of user
+// space records is parsed into the same map.
+func parseAuditFields(body string) map[string]string {
+	fields := make(map[string]string)
+	for _, m := range auditFieldPattern.FindAllStringSubmatch(body, -1) {
+		key, value := m[1], m[2]
+		if strings.HasPrefix(value, "'") {
+			for k, v := range parseAuditFields(strings.Trim(value, "'")) {
+				fields[k] = v
+			}
+			continue
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+// auditSerials returns the distinct serials of records in order of first
+// appearance.
+func auditSerials(records []*auditRecord) []string {
+	seen := make(map[string]bool)
+	var serials []string
+	for _, record := range records {
+		if !seen[record.Serial] {
+			seen[record.Serial] = true
+			serials = append(serials, record.Serial)
+		}
+	}
+	return serials
+}
+
+// auditValue returns a field with quotes removed and hex-encoded values,
+// which auditd writes for strings containing spaces or control characters,
+// decoded.
+func auditValue(record *auditRecord, key string) string {
+	if record == nil {
+		return ""
+	}
+	value, ok := record.Fields[key]
+	if !ok {
+		return ""
+	}
+	if len(value) >= 2 && value[0] == '"' {
+		return strings.Trim(value, `"`)
+	}
+	if auditEncodedFields(key) && value != "(null)" && len(value)%2 == 0 {
+		if decoded, err := hex.DecodeString(value); err == nil {
+			return strings.ReplaceAll(strings.TrimRight(string(decoded), "\x00"), "\x00", " ")
+		}
+	}
+	return value
+}
+
+// auditEncodedFields reports whether auditd may hex-encode a field.
+func auditEncodedFields(key string) bool {
+	switch key {
+	case "proctitle", "comm", "exe", "cwd", "name", "key", "data", "cmd":
+		return true
+	}
+	return len(key) > 1 && key[0] == 'a' && strings.Trim(key[1:], "0123456789[]") == ""
+}
+
+// auditArgument returns an EXECVE argument. Long arguments are split into
+// a<i>[0], a<i>[1], ... fields of one hex-encoded value.
+func auditArgument(execve *auditRecord, i int) string {
+	key := fmt.Sprintf("a%d", i)
+	if _, ok := execve.Fields[key]; ok {
+		return auditValue(execve, key)
+	}
+	var encoded strings.Builder
+	for part := 0; ; part++ {
+		chunk, ok := execve.Fields[fmt.Sprintf("%s[%d]", key, part)]
+		if !ok {
+			break
+		}
+		encoded.WriteString(chunk)
+	}
+	if decoded, err := hex.DecodeString(encoded.String()); err == nil {
+		return string(decoded)
+	}
+	return encoded.String()
+}
+
+// auditSyscallName returns the name of the SYSCALL record's syscall.
+func auditSyscallName(syscall *auditRecord) string {
+	if syscall == nil {
+		return ""
+	}
+	if name := syscall.Enriched["SYSCALL"]; name != "" {
+		return strings.Trim(name, `"`)
+	}
+	number := syscall.Fields["syscall"]
+	if name, ok := auditSyscalls[syscall.Fields["arch"]][number]; ok {
+		return name
+	}
+	if _, err := strconv.Atoi(number); err != nil {
+		return number // Already interpreted (ausearch -i)
+	}
+	return ""
+}
+
+// auditUser builds a user from a uid field and its enriched name.
+func auditUser(record *auditRecord, field string) *objects.User {
+	uid := record.Fields[field]
+	if uid == "" || uid == auditUnset {
+		return nil
+	}
+	return &objects.User{Uid: uid, Name: strings.Trim(record.Enriched[strings.ToUpper(field)], `"`)}
+}
+
+// auditLoginUser returns the user who logged in (auid), which survives
+// sudo and su.
+func auditLoginUser(syscall *auditRecord) *objects.User {
+	return auditUser(syscall, "auid")
+}
+
+// auditProcess builds the process a SYSCALL record describes, taking the
+// command line from EXECVE arguments or, failing that, the PROCTITLE record.
+func auditProcess(syscall, execve, proctitle, cwd *auditRecord) *objects.Process {
+	pid, _ := strconv.Atoi(syscall.Fields["pid"])
+	process := &objects.Process{
+		Pid:              pid,
+		Name:             auditValue(syscall, "comm"),
+		File:             pathFile(auditValue(syscall, "exe")),
+		User:             auditUser(syscall, "uid"),
+		WorkingDirectory: auditValue(cwd, "cwd"),
+	}
+	if execve != nil {
+		argc, _ := strconv.Atoi(execve.Fields["argc"])
+		args := make([]string, 0, argc)
+		for i := 0; i < argc; i++ {
+			args = append(args, auditArgument(execve, i))
+		}
+		process.CmdLine = strings.Join(args, " ")
+	}
+	if process.CmdLine == "" {
+		process.CmdLine = auditValue(proctitle, "proctitle")
+	}
+	if session := syscall.Fields["ses"]; session != "" && session != auditUnset {
+		process.Session = &objects.Session{Uid: session, Terminal: syscall.Fields["tty"]}
+	}
+	if ppid, err := strconv.Atoi(syscall.Fields["ppid"]); err == nil {
+		process.ParentProcess = &objects.Process{Pid: ppid}
+	}
+	return process
+}
+
+// auditFileEvent maps a file syscall, using PATH records' nametype to tell
+// created, deleted and renamed files apart.
+func auditFileEvent(envelope *models.RawEventEnvelope, raw map[string]interface{}, eventTime time.Time, name string, syscall *auditRecord, paths []*auditRecord) *ocsf.Event {
+	activity := auditFileActivities[name]
+	var target, created, deleted *auditRecord
+	for _, path := range paths {
+		switch path.Fields["nametype"] {
+		case "CREATE":
+			created = path
+		case "DELETE":
+			deleted = path
+		case "NORMAL":
+			target = path
+		}
+	}
+	switch {
+	case activity == system.FileActivityActivityRename:
+		target = created
+	case activity == system.FileActivityActivityDelete:
+		target = deleted
+	case created != nil:
+		activity, target = system.FileActivityActivityCreate, created
+	}
+	if target == nil {
+		target = paths[len(paths)-1]
+	}
+
+	event := &system.NewFileActivity(activity).Event
+	initVendorEvent(event, envelope, raw, auditdProduct, eventTime)
+	event.File = auditPathFile(target)
+	if activity == system.FileActivityActivityRename && deleted != nil {
+		event.ClassAttributes["file_result"] = event.File
+		event.File = auditPathFile(deleted)
+	}
+	return event
+}
+
+// auditPathFile builds the file a PATH record names. Relative names are
+// relative to the working directory, which is not always logged, so they are
+// kept as written.
+func auditPathFile(path *auditRecord) *objects.File {
+	file := pathFile(auditValue(path, "name"))
+	if file == nil {
+		return nil
+	}
+	file.Uid = path.Fields["inode"]
+	if owner := auditUser(path, "ouid"); owner != nil {
+		file.Owner = owner
+	}
+	if strings.HasPrefix(path.Fields["mode"], "040") {
+		file.Type, file.TypeId = "Folder", 2
+	}
+	return file
+}
+
+// auditRawRecords keeps the parsed records for raw.data.
+func auditRawRecords(records []*auditRecord) []map[string]string {
+	raw := make([]map[string]string, len(records))
+	for i, record := range records {
+		fields := make(map[string]string, len(record.Fields)+1)
+		for k, v := range record.Fields {
+			fields[k] = v
+		}
+		fields["type"] = record.Type
+		raw[i] = fields
+	}
+	return raw
+}
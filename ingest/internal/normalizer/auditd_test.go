@@ -0,0 +1,162 @@
+package normalizer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+// loadAuditEvents splits the auditd fixture into the events a merge_key
+// parsing profile would produce.
+func loadAuditEvents(t *testing.T) []string {
+	t.Helper()
+	data, err := os.ReadFile("testdata/auditd.log")
+	if err != nil {
+		t.Fatalf("open fixture: %v", err)
+	}
+	var events []string
+	serial := ""
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		records := parseAuditRecords(line)
+		if len(records) == 1 && records[0].Serial == serial {
+			events[len(events)-1] += "\n" + line
+			continue
+		}
+		if len(records) == 1 {
+			serial = records[0].Serial
+		}
+		events = append(events, line)
+	}
+	return events
+}
+
+func TestAuditdNormalizer_Execve(t *testing.T) {
+	events := loadAuditEvents(t)
+	if len(events) != 3 {
+		t.Fatalf("expected 3 fixture events, got %d", len(events))
+	}
+	doc := normalizeDocument(t, AuditdNormalizer{}, "linux:audit", events[0])
+
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":                  float64(1007),
+		"activity_id":                float64(1),
+		"time":                       "2026-03-01T10:00:00.123Z",
+		"metadata.product.name":      "auditd",
+		"device.os.type_id":          float64(200),
+		"process.pid":                float64(1234),
+		"process.name":               "curl",
+		"process.file.path":          "/usr/bin/curl",
+		"process.cmd_line":           "curl -o /tmp/x http://example.com/a b",
+		"process.working_directory":  "/root",
+		"process.user.name":          "root",
+		"process.session.uid":        "3",
+		"process.parent_process.pid": float64(1000),
+		"actor.user.name":            "alice",
+		"actor.user.uid":             "1000",
+		"status_id":                  float64(1),
+		"properties.serial":          "4242",
+		"properties.syscall":         "execve",
+		"attack_technique_uid":       "T1105",
+		"attack_technique":           "Ingress Tool Transfer",
+	})
+}
+
+func TestAuditdNormalizer_FileDelete(t *testing.T) {
+	doc := normalizeDocument(t, AuditdNormalizer{}, "linux:audit", loadAuditEvents(t)[1])
+
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":              float64(1001),
+		"activity_id":            float64(4),
+		"file.path":              "/etc/shadow",
+		"file.parent_folder":     "/etc",
+		"actor.process.cmd_line": "rm /etc/shadow",
+		"actor.user.uid":         "1000",
+		"status_id":              float64(2),
+		"status_detail":          "-13",
+		"properties.syscall":     "unlinkat",
+		"properties.key":         "delete",
+		"time":                   "2026-03-01T10:00:01.5Z",
+	})
+}
+
+func TestAuditdNormalizer_Rename(t *testing.T) {
+	doc := normalizeDocument(t, AuditdNormalizer{}, "auditd", strings.Join([]string{
+		`node=web-01 type=SYSCALL msg=audit(1772359203.000:4250): arch=c000003e syscall=82 success=yes exit=0 items=4 ppid=1 pid=77 auid=1000 uid=1000 comm="mv" exe="/usr/bin/mv" key=(null)`,
+		`node=web-01 type=PATH msg=audit(1772359203.000:4250): item=0 name="/tmp/" nametype=PARENT`,
+		`node=web-01 type=PATH msg=audit(1772359203.000:4250): item=1 name="/var/www/" nametype=PARENT`,
+		`node=web-01 type=PATH msg=audit(1772359203.000:4250): item=2 name="/tmp/shell.php" inode=9 nametype=DELETE`,
+		`node=web-01 type=PATH msg=audit(1772359203.000:4250): item=3 name="/var/www/shell.php" inode=9 nametype=CREATE`,
+	}, "\n"))
+
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":        float64(1001),
+		"activity_id":      float64(5),
+		"file.path":        "/tmp/shell.php",
+		"file_result.path": "/var/www/shell.php",
+		"device.hostname":  "web-01",
+	})
+}
+
+func TestAuditdNormalizer_JSONMessage(t *testing.T) {
+	payload, err := json.Marshal(map[string]interface{}{"message": loadAuditEvents(t)[0], "time": 1772359200.123})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := normalizeDocument(t, AuditdNormalizer{}, "linux_audit", string(payload))
+
+	expectFields(t, doc, map[string]interface{}{"class_uid": float64(1007), "process.pid": float64(1234)})
+}
+
+func TestAuditdNormalizer_UnmappedRecord(t *testing.T) {
+	doc := normalizeDocument(t, AuditdNormalizer{}, "linux:audit", loadAuditEvents(t)[2])
+
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":           float64(0),
+		"properties.log_name": "USER_LOGIN",
+		"properties.serial":   "4244",
+	})
+}
+
+func TestAuditdNormalizer_MultipleSerials(t *testing.T) {
+	events := loadAuditEvents(t)
+	_, err := AuditdNormalizer{}.Normalize(context.Background(), &models.RawEventEnvelope{
+		Format:     "json",
+		SourceType: "linux:audit",
+		Payload:    []byte(events[0] + "\n" + events[1]),
+		ReceivedAt: time.Now(),
+	})
+	if err == nil {
+		t.Fatal("expected an error for a payload with two audit events")
+	}
+	if !strings.Contains(err.Error(), "serials 4242, 4243") {
+		t.Errorf("error should name both serials, got %v", err)
+	}
+}
+
+func TestAuditValue(t *testing.T) {
+	record := &auditRecord{Fields: map[string]string{
+		"comm":      `"bash"`,
+		"proctitle": "2F62696E2F7368002D63006964",
+		"a1":        "0",
+		"key":       "(null)",
+		"ses":       "12",
+	}}
+	tests := map[string]string{
+		"comm":      "bash",
+		"proctitle": "/bin/sh -c id",
+		"a1":        "0",
+		"key":       "(null)",
+		"ses":       "12",
+		"missing":   "",
+	}
+	for key, want := range tests {
+		if got := auditValue(record, key); got != want {
+			t.Errorf("%s: expected %q, got %q", key, want, got)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package normalizer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/application"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/iam"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/objects"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+var cloudTrailProduct = ocsf.Product{Name: "CloudTrail", Vendor: "AWS"}
+
+// cloudTrailAccountChanges maps IAM user operations to Account Change
+// activities.
+var cloudTrailAccountChanges = map[string]int{
+	"CreateUser":          iam.AccountChangeActivityCreate,
+	"DeleteUser":          iam.AccountChangeActivityDelete,
+	"ChangePassword":      iam.AccountChangeActivityPasswordChange,
+	"CreateLoginProfile":  iam.AccountChangeActivityPasswordChange,
+	"UpdateLoginProfile":  iam.AccountChangeActivityPasswordReset,
+	"AttachUserPolicy":    iam.AccountChangeActivityAttachPolicy,
+	"PutUserPolicy":       iam.AccountChangeActivityAttachPolicy,
+	"DetachUserPolicy":    iam.AccountChangeActivityDetachPolicy,
+	"DeleteUserPolicy":    iam.AccountChangeActivityDetachPolicy,
+	"EnableMFADevice":     iam.AccountChangeActivityMFAFactorEnable,
+	"DeactivateMFADevice": iam.AccountChangeActivityMFAFactorDisable,
+}
+
+// cloudTrailGroupChanges maps IAM group operations to Group Management
+// activities.
+var cloudTrailGroupChanges = map[string]int{
+	"CreateGroup":         iam.GroupManagementActivityCreate,
+	"DeleteGroup":         iam.GroupManagementActivityDelete,
+	"AddUserToGroup":      iam.GroupManagementActivityAddUser,
+	"RemoveUserFromGroup": iam.GroupManagementActivityRemoveUser,
+	"AttachGroupPolicy":   iam.GroupManagementActivityAssignPrivileges,
+	"PutGroupPolicy":      iam.GroupManagementActivityAssignPrivileges,
+	"DetachGroupPolicy":   iam.GroupManagementActivityRevokePrivileges,
+	"DeleteGroupPolicy":   iam.GroupManagementActivityRevokePrivileges,
+}
+
+// CloudTrailNormalizer converts AWS CloudTrail records into OCSF events:
+// console sign-ins to Authentication, IAM user and group changes to Account
+// Change and Group Management, and every other call to API Activity. All of
+// them carry the actor, src_endpoint, cloud, api and resources.
+//
+// Each record is one event; CloudTrail log files ({"Records": [...]}) are
+// split into records by ingest/pkg/exports before they are sent.
+type CloudTrailNormalizer struct{}
+
+// Supports matches JSON payloads with sourcetype aws:cloudtrail.
+func (CloudTrailNormalizer) Supports(format, sourceType string) bool {
+	return format == "json" && sourceType == "aws:cloudtrail"
+}
+
+// Normalize maps a CloudTrail record according to its event name.
+func (CloudTrailNormalizer) Normalize(ctx context.Context, envelope *models.RawEventEnvelope) (*ocsf.Event, error) {
+	_ = ctx
+	payload, err := decodeVendorPayload(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode cloudtrail payload: %w", err)
+	}
+	if getString(payload, "eventName") == "" {
+		return nil, fmt.Errorf("cloudtrail record has no eventName")
+	}
+
+	eventName := getString(payload, "eventName")
+	eventTime, _ := time.Parse(time.RFC3339, getString(payload, "eventTime"))
+	iamCall := getString(payload, "eventSource") == "iam.amazonaws.com"
+	actor := cloudTrailActor(payload)
+
+	var event *ocsf.Event
+	switch {
+	case getString(payload, "eventType") == "AwsConsoleSignIn":
+		event = cloudTrailSignIn(envelope, payload, eventTime, actor)
+	case iamCall && cloudTrailAccountChanges[eventName] != 0:
+		event = &iam.NewAccountChange(cloudTrailAccountChanges[eventName]).Event
+		initVendorEvent(event, envelope, payload, cloudTrailProduct, eventTime)
+		event.ClassAttributes["user"] = cloudTrailTargetUser(payload, actor)
+		if policy := cloudTrailPolicy(payload); policy != nil {
+			event.ClassAttributes["policy"] = policy
+		}
+	case iamCall && cloudTrailGroupChanges[eventName] != 0:
+		event = &iam.NewGroupManagement(cloudTrailGroupChanges[eventName]).Event
+		initVendorEvent(event, envelope, payload, cloudTrailProduct, eventTime)
+		event.ClassAttributes["group"] = &objects.Group{
+			Name: getString(payload, "requestParameters.groupName"),
+			Uid:  getString(payload, "responseElements.group.arn"),
+		}
+		if userName := getString(payload, "requestParameters.userName"); userName != "" {
+			event.ClassAttributes["user"] = &objects.User{Name: userName}
+		}
+		if policy := cloudTrailPolicy(payload); policy != nil {
+			event.ClassAttributes["privileges"] = []string{policy.Uid}
+		}
+	default:
+		activity := apiActivityID(eventName)
+		if readOnly, ok := getBool(payload, "readOnly"); ok && readOnly {
+			activity = application.ApiActivityActivityRead
+		}
+		event = &application.NewApiActivity(activity).Event
+		initVendorEvent(event, envelope, payload, cloudTrailProduct, eventTime)
+	}
+
+	event.Actor = actor
+	event.SrcEndpoint = cloudTrailSource(getString(payload, "sourceIPAddress"))
+	if event.StatusID == 0 {
+		if errorCode := getString(payload, "errorCode"); errorCode != "" {
+			setStatus(event, ocsf.StatusFailure, errorCode)
+		} else {
+			setStatus(event, ocsf.StatusSuccess, "")
+		}
+	}
+	if event.SeverityID == 0 {
+		setSeverity(event, ocsf.SeverityInformational)
+	}
+
+	api := &objects.Api{
+		Operation: eventName,
+		Service:   &objects.Service{Name: getString(payload, "eventSource")},
+		Request:   &objects.Request{Uid: getString(payload, "requestID")},
+	}
+	if errorCode := getString(payload, "errorCode"); errorCode != "" {
+		api.Response = &objects.Response{Error: errorCode, ErrorMessage: getString(payload, "errorMessage")}
+	}
+	event.ClassAttributes["api"] = api
+	event.ClassAttributes["cloud"] = &objects.Cloud{
+		Provider: "AWS",
+		Region:   getString(payload, "awsRegion"),
+		Account:  &objects.Account{Uid: getString(payload, "recipientAccountId"), Type: "AWS Account", TypeId: 10},
+	}
+	if userAgent := getString(payload, "userAgent"); userAgent != "" {
+		event.ClassAttributes["http_request"] = &objects.HttpRequest{UserAgent: userAgent}
+	}
+	if resources := cloudTrailResources(payload); len(resources) > 0 {
+		event.ClassAttributes["resources"] = resources
+	}
+	event.Properties["event_uid"] = getString(payload, "eventID")
+	return event, nil
+}
+
+// cloudTrailSignIn maps console sign-in events, whose outcome is in the
+// response rather than errorCode, to Authentication.
+func cloudTrailSignIn(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time, actor *objects.Actor) *ocsf.Event {
+	activity := 99
+	switch getString(payload, "eventName") {
+	case "ConsoleLogin":
+		activity = iam.AuthenticationActivityLogon
+	case "SwitchRole":
+		activity = iam.AuthenticationActivityAccountSwitch
+	}
+
+	event := &iam.NewAuthentication(activity).Event
+	initVendorEvent(event, envelope, payload, cloudTrailProduct, eventTime)
+	event.ClassAttributes["user"] = actor.User
+	event.ClassAttributes["is_mfa"] = getString(payload, "additionalEventData.MFAUsed") == "Yes"
+	event.ClassAttributes["service"] = &objects.Service{Name: "AWS Management Console"}
+
+	if getString(payload, "responseElements.ConsoleLogin") == "Failure" || getString(payload, "errorMessage") != "" {
+		setStatus(event, ocsf.StatusFailure, getString(payload, "errorMessage"))
+		setSeverity(event, ocsf.SeverityLow)
+	}
+	return event
+}
+
+// cloudTrailActor builds the actor from userIdentity. Assumed roles are named
+// after the role that issued the session.
+func cloudTrailActor(payload map[string]interface{}) *objects.Actor {
+	identityType := getString(payload, "userIdentity.type")
+	user := &objects.User{
+		Name:          getString(payload, "userIdentity.userName"),
+		Uid:           getString(payload, "userIdentity.principalId"),
+		UidAlt:        getString(payload, "userIdentity.arn"),
+		Type:          identityType,
+		CredentialUid: getString(payload, "userIdentity.accessKeyId"),
+	}
+	if user.Name == "" {
+		user.Name = getString(payload, "userIdentity.sessionContext.sessionIssuer.userName")
+	}
+	if user.Name == "" && identityType == "Root" {
+		user.Name = "root"
+	}
+	if accountID := getString(payload, "userIdentity.accountId"); accountID != "" {
+		user.Account = &objects.Account{Uid: accountID, Type: "AWS Account", TypeId: 10}
+	}
+
+	actor := &objects.Actor{User: user, InvokedBy: getString(payload, "userIdentity.invokedBy")}
+	if issuer := getString(payload, "userIdentity.sessionContext.sessionIssuer.arn"); issuer != "" || lookup(payload, "userIdentity.sessionContext.attributes") != nil {
+		session := &objects.Session{
+			Issuer: issuer,
+			IsMfa:  getString(payload, "userIdentity.sessionContext.attributes.mfaAuthenticated") == "true",
+		}
+		if created, err := time.Parse(time.RFC3339, getString(payload, "userIdentity.sessionContext.attributes.creationDate")); err == nil {
+			session.CreatedTime = created.UnixMilli()
+		}
+		actor.Session = session
+	}
+	return actor
+}
+
+// cloudTrailSource maps sourceIPAddress, which is an AWS service name for
+// calls made by a service on the caller's behalf.
+func cloudTrailSource(address string) *objects.NetworkEndpoint {
+	if address == "" {
+		return nil
+	}
+	if net.ParseIP(address) != nil {
+		return &objects.NetworkEndpoint{Ip: address}
+	}
+	return &objects.NetworkEndpoint{Domain: address}
+}
+
+// cloudTrailTargetUser returns the IAM user an operation changes; operations
+// such as ChangePassword act on the caller.
+func cloudTrailTargetUser(payload map[string]interface{}, actor *objects.Actor) *objects.User {
+	if userName := getString(payload, "requestParameters.userName"); userName != "" {
+		return &objects.User{Name: userName, Uid: getString(payload, "responseElements.user.userId"), UidAlt: getString(payload, "responseElements.user.arn")}
+	}
+	return actor.User
+}
+
+func cloudTrailPolicy(payload map[string]interface{}) *objects.Policy {
+	arn, name := getString(payload, "requestParameters.policyArn"), getString(payload, "requestParameters.policyName")
+	if arn == "" && name == "" {
+		return nil
+	}
+	if arn == "" {
+		arn = name
+	}
+	return &objects.Policy{Uid: arn, Name: name}
+}
+
+func cloudTrailResources(payload map[string]interface{}) []map[string]interface{} {
+	items, _ := payload["resources"].([]interface{})
+	var resources []map[string]interface{}
+	for _, item := range items {
+		r, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		details := resource(getString(r, "ARN"), "", getString(r, "type"))
+		if accountID := getString(r, "accountId"); accountID != "" {
+			details["owner"] = map[string]interface{}{"account": map[string]interface{}{"uid": accountID}}
+		}
+		resources = append(resources, details)
+	}
+	return resources
+}
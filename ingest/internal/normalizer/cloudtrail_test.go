@@ -0,0 +1,89 @@
+package normalizer
+
+import "testing"
+
+func TestCloudTrailNormalizer_Export(t *testing.T) {
+	records := loadExport(t, "cloudtrail.json")
+	if len(records) != 5 {
+		t.Fatalf("expected 5 records, got %d", len(records))
+	}
+
+	t.Run("console login", func(t *testing.T) {
+		doc := normalizeDocument(t, CloudTrailNormalizer{}, "aws:cloudtrail", records[0])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":                    float64(3002),
+			"activity_id":                  float64(1),
+			"activity":                     "Logon",
+			"time":                         "2026-03-01T10:00:00Z",
+			"status":                       "Failure",
+			"status_detail":                "Failed authentication",
+			"is_mfa":                       false,
+			"user.name":                    "alice",
+			"actor.user.uid_alt":           "arn:aws:iam::123456789012:user/alice",
+			"actor.user.account.uid":       "123456789012",
+			"src_endpoint.ip":              "203.0.113.10",
+			"cloud.provider":               "AWS",
+			"cloud.region":                 "us-east-1",
+			"cloud.account.uid":            "123456789012",
+			"api.operation":                "ConsoleLogin",
+			"api.service.name":             "signin.amazonaws.com",
+			"http_request.user_agent":      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)",
+			"metadata.product.vendor_name": "AWS",
+		})
+	})
+
+	t.Run("attach user policy", func(t *testing.T) {
+		doc := normalizeDocument(t, CloudTrailNormalizer{}, "aws:cloudtrail", records[1])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":                 float64(3001),
+			"activity":                  "Attach Policy",
+			"status":                    "Success",
+			"user.name":                 "mallory",
+			"policy.uid":                "arn:aws:iam::aws:policy/AdministratorAccess",
+			"actor.user.name":           "Admin",
+			"actor.user.type":           "AssumedRole",
+			"actor.user.credential_uid": "ASIAEXAMPLEKEY",
+			"actor.session.issuer":      "arn:aws:iam::123456789012:role/Admin",
+			"actor.session.is_mfa":      true,
+			"api.request.uid":           "9a8b7c6d-0000-4000-8000-000000000002",
+		})
+	})
+
+	t.Run("add user to group", func(t *testing.T) {
+		doc := normalizeDocument(t, CloudTrailNormalizer{}, "aws:cloudtrail", records[2])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":       float64(3006),
+			"activity":        "Add User",
+			"group.name":      "Admins",
+			"user.name":       "mallory",
+			"actor.user.name": "bob",
+		})
+	})
+
+	t.Run("service data event", func(t *testing.T) {
+		doc := normalizeDocument(t, CloudTrailNormalizer{}, "aws:cloudtrail", records[3])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":           float64(6003),
+			"activity":            "Update",
+			"actor.invoked_by":    "cloudtrail.amazonaws.com",
+			"src_endpoint.domain": "cloudtrail.amazonaws.com",
+		})
+		resources, _ := doc["resources"].([]interface{})
+		if len(resources) != 2 || field(resources[1].(map[string]interface{}), "uid") != "arn:aws:s3:::org-trail" || field(resources[1].(map[string]interface{}), "type") != "AWS::S3::Bucket" {
+			t.Errorf("unexpected resources: %v", doc["resources"])
+		}
+	})
+
+	t.Run("denied read", func(t *testing.T) {
+		doc := normalizeDocument(t, CloudTrailNormalizer{}, "aws:cloudtrail", records[4])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":                  float64(6003),
+			"activity":                   "Read",
+			"status":                     "Failure",
+			"status_detail":              "Client.UnauthorizedOperation",
+			"api.response.error":         "Client.UnauthorizedOperation",
+			"api.response.error_message": "You are not authorized to perform this operation.",
+			"cloud.region":               "eu-west-1",
+		})
+	})
+}
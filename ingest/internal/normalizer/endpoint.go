@@ -0,0 +1,53 @@
+package normalizer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/objects"
+)
+
+// Helpers shared by the endpoint telemetry normalizers (Sysmon and auditd).
+
+// pathFile builds a file object from a Windows or POSIX path.
+func pathFile(path string) *objects.File {
+	if path == "" {
+		return nil
+	}
+	file := &objects.File{Path: path, Name: path, Type: "Regular File", TypeId: 1}
+	if i := strings.LastIndexAny(path, `/\`); i >= 0 {
+		file.Name = path[i+1:]
+		file.ParentFolder = path[:i]
+		if file.ParentFolder == "" {
+			file.ParentFolder = path[:i+1]
+		}
+	}
+	if i := strings.LastIndex(file.Name, "."); i > 0 {
+		file.Ext = file.Name[i+1:]
+	}
+	return file
+}
+
+// techniquePattern matches ATT&CK technique IDs as rule sets write them in
+// Sysmon rule names ("technique_id=T1059.001,technique_name=PowerShell") and
+// auditd keys ("T1087_Account_Discovery").
+var techniquePattern = regexp.MustCompile(`\b(T\d{4}(?:\.\d{3})?)(?:[_,]technique_name=([^,]+)|_([A-Za-z][\w-]*))?`)
+
+// techniqueAttacks returns an attack for each technique a rule tag names.
+// Tags name techniques only, so the attacks have no tactic.
+func techniqueAttacks(tag string) []*objects.Attack {
+	var attacks []*objects.Attack
+	seen := make(map[string]bool)
+	for _, m := range techniquePattern.FindAllStringSubmatch(tag, -1) {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		name := m[2]
+		if name == "" {
+			name = strings.ReplaceAll(m[3], "_", " ")
+		}
+		attacks = append(attacks, &objects.Attack{Technique: &objects.Technique{Uid: m[1], Name: strings.TrimSpace(name)}})
+	}
+	return attacks
+}
@@ -0,0 +1,185 @@
+package normalizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/application"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/iam"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/objects"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+var gitHubProduct = ocsf.Product{Name: "GitHub Audit Log", Vendor: "GitHub"}
+
+// gitHubAuthentications maps sign-in actions to Authentication activities.
+var gitHubAuthentications = map[string]int{
+	"user.login":        iam.AuthenticationActivityLogon,
+	"user.failed_login": iam.AuthenticationActivityLogon,
+	"user.logout":       iam.AuthenticationActivityLogoff,
+}
+
+// gitHubAccountChanges maps user account actions to Account Change
+// activities.
+var gitHubAccountChanges = map[string]int{
+	"user.create":                         iam.AccountChangeActivityCreate,
+	"user.destroy":                        iam.AccountChangeActivityDelete,
+	"user.delete":                         iam.AccountChangeActivityDelete,
+	"user.suspend":                        iam.AccountChangeActivityDisable,
+	"user.unsuspend":                      iam.AccountChangeActivityEnable,
+	"user.change_password":                iam.AccountChangeActivityPasswordChange,
+	"user.forgot_password":                iam.AccountChangeActivityPasswordReset,
+	"two_factor_authentication.enabled":   iam.AccountChangeActivityMFAFactorEnable,
+	"two_factor_authentication.disabled":  iam.AccountChangeActivityMFAFactorDisable,
+	"two_factor_authentication.recovered": iam.AccountChangeActivityMFAFactorDisable,
+}
+
+// gitHubGroupChanges maps team and organization membership actions to Group
+// Management activities.
+var gitHubGroupChanges = map[string]int{
+	"team.create":             iam.GroupManagementActivityCreate,
+	"team.destroy":            iam.GroupManagementActivityDelete,
+	"team.add_member":         iam.GroupManagementActivityAddUser,
+	"team.remove_member":      iam.GroupManagementActivityRemoveUser,
+	"team.promote_maintainer": iam.GroupManagementActivityAssignPrivileges,
+	"team.demote_maintainer":  iam.GroupManagementActivityRevokePrivileges,
+	"org.add_member":          iam.GroupManagementActivityAddUser,
+	"org.remove_member":       iam.GroupManagementActivityRemoveUser,
+	"org.update_member":       iam.GroupManagementActivityAssignPrivileges,
+}
+
+// gitHubOperationTypes maps the audit log's operation_type to API Activity
+// activities.
+var gitHubOperationTypes = map[string]int{
+	"create": application.ApiActivityActivityCreate,
+	"access": application.ApiActivityActivityRead,
+	"modify": application.ApiActivityActivityUpdate,
+	"remove": application.ApiActivityActivityDelete,
+}
+
+// GitHubNormalizer converts GitHub organization and enterprise audit log
+// events into OCSF events: sign-ins to Authentication, user account changes
+// to Account Change, team and organization membership to Group Management,
+// and every other action to API Activity.
+type GitHubNormalizer struct{}
+
+// Supports matches JSON payloads with sourcetypes github:audit and
+// github:enterprise:audit.
+func (GitHubNormalizer) Supports(format, sourceType string) bool {
+	return format == "json" && (sourceType == "github:audit" || sourceType == "github:enterprise:audit")
+}
+
+// Normalize maps an audit log event according to its action.
+func (GitHubNormalizer) Normalize(ctx context.Context, envelope *models.RawEventEnvelope) (*ocsf.Event, error) {
+	_ = ctx
+	payload, err := decodeVendorPayload(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode github payload: %w", err)
+	}
+	action := getString(payload, "action")
+	if action == "" {
+		return nil, fmt.Errorf("github audit event has no action")
+	}
+	eventTime := gitHubTime(payload)
+
+	var event *ocsf.Event
+	switch {
+	case gitHubAuthentications[action] != 0:
+		event = &iam.NewAuthentication(gitHubAuthentications[action]).Event
+		initVendorEvent(event, envelope, payload, gitHubProduct, eventTime)
+		event.ClassAttributes["user"] = &objects.User{Name: getString(payload, "actor"), Uid: getString(payload, "actor_id")}
+		event.ClassAttributes["service"] = &objects.Service{Name: "GitHub"}
+	case gitHubAccountChanges[action] != 0:
+		event = &iam.NewAccountChange(gitHubAccountChanges[action]).Event
+		initVendorEvent(event, envelope, payload, gitHubProduct, eventTime)
+		event.ClassAttributes["user"] = gitHubTargetUser(payload)
+	case gitHubGroupChanges[action] != 0:
+		event = &iam.NewGroupManagement(gitHubGroupChanges[action]).Event
+		initVendorEvent(event, envelope, payload, gitHubProduct, eventTime)
+		if user := getString(payload, "user"); user != "" {
+			event.ClassAttributes["user"] = &objects.User{Name: user, Uid: getString(payload, "user_id")}
+		}
+		if team := getString(payload, "team"); team != "" && strings.HasPrefix(action, "team.") {
+			event.ClassAttributes["group"] = &objects.Group{Name: team, Type: "Team"}
+		} else {
+			event.ClassAttributes["group"] = &objects.Group{Name: getString(payload, "org"), Uid: getString(payload, "org_id"), Type: "Organization"}
+		}
+	default:
+		activity, ok := gitHubOperationTypes[getString(payload, "operation_type")]
+		if !ok {
+			segments := strings.Split(action, ".")
+			activity = apiActivityID(segments[len(segments)-1])
+		}
+		event = &application.NewApiActivity(activity).Event
+		initVendorEvent(event, envelope, payload, gitHubProduct, eventTime)
+	}
+
+	event.Actor = &objects.Actor{User: &objects.User{Name: getString(payload, "actor"), Uid: getString(payload, "actor_id")}}
+	if ip := getString(payload, "actor_ip"); ip != "" {
+		event.SrcEndpoint = &objects.NetworkEndpoint{Ip: ip}
+		if country := getString(payload, "actor_location.country_code"); country != "" {
+			event.SrcEndpoint.Location = &objects.Location{Country: country}
+		}
+	}
+	if action == "user.failed_login" {
+		setStatus(event, ocsf.StatusFailure, "")
+		setSeverity(event, ocsf.SeverityLow)
+	} else {
+		setStatus(event, ocsf.StatusSuccess, "")
+		setSeverity(event, ocsf.SeverityInformational)
+	}
+
+	event.ClassAttributes["api"] = &objects.Api{
+		Operation: action,
+		Service:   &objects.Service{Name: "GitHub"},
+		Request:   &objects.Request{Uid: getString(payload, "request_id")},
+	}
+	if userAgent := getString(payload, "user_agent"); userAgent != "" {
+		event.ClassAttributes["http_request"] = &objects.HttpRequest{UserAgent: userAgent}
+	}
+	if resources := gitHubResources(payload); len(resources) > 0 {
+		event.ClassAttributes["resources"] = resources
+	}
+	event.Properties["event_uid"] = getString(payload, "_document_id")
+	return event, nil
+}
+
+// gitHubTime reads @timestamp, or created_at in older exports; both are epoch
+// milliseconds, though created_at is sometimes an RFC 3339 string.
+func gitHubTime(payload map[string]interface{}) time.Time {
+	for _, key := range []string{"@timestamp", "created_at"} {
+		if ms, ok := getFloat(payload, key); ok {
+			return time.UnixMilli(int64(ms)).UTC()
+		}
+		if t, err := time.Parse(time.RFC3339, getString(payload, key)); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// gitHubTargetUser returns the account an action changes, which is the actor
+// for self-service actions such as enabling two-factor authentication.
+func gitHubTargetUser(payload map[string]interface{}) *objects.User {
+	if user := getString(payload, "user"); user != "" {
+		return &objects.User{Name: user, Uid: getString(payload, "user_id")}
+	}
+	return &objects.User{Name: getString(payload, "actor"), Uid: getString(payload, "actor_id")}
+}
+
+func gitHubResources(payload map[string]interface{}) []map[string]interface{} {
+	var resources []map[string]interface{}
+	if org := getString(payload, "org"); org != "" {
+		resources = append(resources, resource(getString(payload, "org_id"), org, "Organization"))
+	}
+	if repo := getString(payload, "repo"); repo != "" {
+		resources = append(resources, resource(getString(payload, "repo_id"), repo, "Repository"))
+	}
+	if team := getString(payload, "team"); team != "" {
+		resources = append(resources, resource("", team, "Team"))
+	}
+	return resources
+}
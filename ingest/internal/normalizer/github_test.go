@@ -0,0 +1,70 @@
+package normalizer
+
+import "testing"
+
+func TestGitHubNormalizer_Export(t *testing.T) {
+	records := loadExport(t, "github.ndjson")
+	if len(records) != 5 {
+		t.Fatalf("expected 5 records, got %d", len(records))
+	}
+
+	t.Run("failed login", func(t *testing.T) {
+		doc := normalizeDocument(t, GitHubNormalizer{}, "github:audit", records[0])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":                     float64(3002),
+			"activity":                      "Logon",
+			"time":                          "2026-03-01T10:00:00Z",
+			"status":                        "Failure",
+			"user.name":                     "octocat",
+			"actor.user.uid":                "583231",
+			"src_endpoint.ip":               "203.0.113.10",
+			"src_endpoint.location.country": "US",
+			"http_request.user_agent":       "Mozilla/5.0",
+		})
+	})
+
+	t.Run("two-factor disabled", func(t *testing.T) {
+		doc := normalizeDocument(t, GitHubNormalizer{}, "github:audit", records[1])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid": float64(3001),
+			"activity":  "MFA Factor Disable",
+			"user.name": "octocat",
+		})
+	})
+
+	t.Run("team member added", func(t *testing.T) {
+		doc := normalizeDocument(t, GitHubNormalizer{}, "github:enterprise:audit", records[2])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":       float64(3006),
+			"activity":        "Add User",
+			"group.name":      "example-org/security",
+			"group.type":      "Team",
+			"user.name":       "mallory",
+			"actor.user.name": "admin",
+		})
+	})
+
+	t.Run("repository deleted", func(t *testing.T) {
+		doc := normalizeDocument(t, GitHubNormalizer{}, "github:audit", records[3])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":        float64(6003),
+			"activity":         "Delete",
+			"api.operation":    "repo.destroy",
+			"api.service.name": "GitHub",
+			"api.request.uid":  "ABCD:1234:5678",
+		})
+		resources, _ := doc["resources"].([]interface{})
+		if len(resources) != 2 || field(resources[1].(map[string]interface{}), "name") != "example-org/secrets" {
+			t.Errorf("unexpected resources: %v", doc["resources"])
+		}
+	})
+
+	t.Run("clone from created_at", func(t *testing.T) {
+		doc := normalizeDocument(t, GitHubNormalizer{}, "github:audit", records[4])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid": float64(6003),
+			"activity":  "Read",
+			"time":      "2026-03-01T10:04:00Z",
+		})
+	})
+}
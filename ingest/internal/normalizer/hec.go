@@ -13,9 +13,10 @@ import (
 // HECNormalizer converts Splunk HEC style payloads into generic OCSF events.
 type HECNormalizer struct{}
 
-// Supports indicates HEC normalizer should handle JSON payloads with sourceType hec.
+// Supports indicates HEC normalizer should handle JSON payloads with sourceType
+// hec, and OTLP log records that were not routed to a specific sourcetype.
 func (HECNormalizer) Supports(format, sourceType string) bool {
-	return format == "json" && sourceType == "hec"
+	return format == "json" && (sourceType == "hec" || sourceType == "otlp")
 }
 
 // Normalize performs a minimal mapping from HEC event envelope to OCSF Event.
@@ -28,6 +28,12 @@ func TestHECNormalizer_Supports(t *testing.T) {
 			sourceType: "hec",
 			expected:   true,
 		},
+		{
+			name:       "json format with otlp sourceType",
+			format:     "json",
+			sourceType: "otlp",
+			expected:   true,
+		},
 		{
 			name:       "json format with non-hec sourceType",
 			format:     "json",
@@ -0,0 +1,129 @@
+package normalizer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/mapping"
+)
+
+// MappingNormalizer applies the declarative mappings in a directory and
+// reloads them when the directory changes. A reload that fails validation
+// keeps the previous mappings.
+type MappingNormalizer struct {
+	dir      string
+	mappings atomic.Pointer[mapping.Set]
+
+	mu          sync.Mutex // Serializes reloads
+	fingerprint string     // Files of the loaded mappings
+	failed      string     // Files of the last rejected reload, not retried
+}
+
+// NewMappingNormalizer loads the mappings in dir.
+func NewMappingNormalizer(dir string) (*MappingNormalizer, error) {
+	n := &MappingNormalizer{dir: dir}
+	if _, err := n.Reload(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Supports reports whether a loaded mapping matches the envelope.
+func (n *MappingNormalizer) Supports(format, sourceType string) bool {
+	return n.mappings.Load().Find(format, sourceType) != nil
+}
+
+// Normalize maps the payload with the first matching mapping.
+func (n *MappingNormalizer) Normalize(ctx context.Context, envelope *models.RawEventEnvelope) (*ocsf.Event, error) {
+	_ = ctx
+	m := n.mappings.Load().Find(envelope.Format, envelope.SourceType)
+	if m == nil {
+		return nil, fmt.Errorf("no mapping for sourcetype %s", envelope.SourceType)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(envelope.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+	return m.Apply(payload, mapping.Meta{
+		Source:     envelope.Source,
+		SourceType: envelope.SourceType,
+		Format:     envelope.Format,
+		ReceivedAt: envelope.ReceivedAt,
+	})
+}
+
+// Len returns the number of loaded mappings.
+func (n *MappingNormalizer) Len() int {
+	return n.mappings.Load().Len()
+}
+
+// Reload reloads the mappings if any file changed since the last load and
+// reports whether it did. Files that failed to load are not retried until
+// they change again.
+func (n *MappingNormalizer) Reload() (bool, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	fingerprint, err := n.dirFingerprint()
+	if err != nil {
+		return false, err
+	}
+	if (fingerprint == n.fingerprint && n.mappings.Load() != nil) || fingerprint == n.failed {
+		return false, nil
+	}
+	set, err := mapping.LoadDir(n.dir)
+	if err != nil {
+		n.failed = fingerprint
+		return false, err
+	}
+	n.mappings.Store(set)
+	n.fingerprint = fingerprint
+	return true, nil
+}
+
+// Watch polls the directory every interval until ctx is done.
+func (n *MappingNormalizer) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := n.Reload()
+			if err != nil {
+				log.Printf("Normalizer mappings: reload of %s failed, keeping %d loaded mappings: %v", n.dir, n.Len(), err)
+			} else if reloaded {
+				log.Printf("Normalizer mappings: reloaded %d mappings from %s", n.Len(), n.dir)
+			}
+		}
+	}
+}
+
+// dirFingerprint summarizes the name, size and modification time of each
+// mapping file.
+func (n *MappingNormalizer) dirFingerprint() (string, error) {
+	files, err := mapping.Files(n.dir)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,104 @@
+package normalizer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+const testMapping = `
+name: vpn
+sourcetypes: ["vpn:*"]
+class_uid: 3002
+activity_id: 1
+fields:
+  - {op: copy, from: user, to: actor.user.name}
+`
+
+func writeMapping(t *testing.T, dir, content string) {
+	t.Helper()
+	path := filepath.Join(dir, "vpn.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Make sure the fingerprint changes even on coarse mtime filesystems
+	future := time.Now().Add(time.Duration(len(content)) * time.Second)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMappingNormalizer_Normalize(t *testing.T) {
+	dir := t.TempDir()
+	writeMapping(t, dir, testMapping)
+
+	n, err := NewMappingNormalizer(dir)
+	if err != nil {
+		t.Fatalf("NewMappingNormalizer failed: %v", err)
+	}
+	if !n.Supports("json", "vpn:gateway") || n.Supports("json", "syslog") {
+		t.Fatalf("unexpected Supports result")
+	}
+
+	event, err := n.Normalize(context.Background(), &models.RawEventEnvelope{
+		Format:     "json",
+		SourceType: "vpn:gateway",
+		Source:     "gw1",
+		Payload:    []byte(`{"user": "alice"}`),
+		ReceivedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	if event.ClassUID != 3002 || event.Actor == nil || event.Actor.User.Name != "alice" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+}
+
+func TestMappingNormalizer_Reload(t *testing.T) {
+	dir := t.TempDir()
+	writeMapping(t, dir, testMapping)
+
+	n, err := NewMappingNormalizer(dir)
+	if err != nil {
+		t.Fatalf("NewMappingNormalizer failed: %v", err)
+	}
+
+	if reloaded, err := n.Reload(); err != nil || reloaded {
+		t.Fatalf("Reload() = %v, %v; want no reload when nothing changed", reloaded, err)
+	}
+
+	// An invalid mapping is rejected and the loaded ones stay in place
+	writeMapping(t, dir, testMapping+"  - {op: copy, from: x, to: actor.user.nickname}\n")
+	if _, err := n.Reload(); err == nil {
+		t.Fatal("expected reload of an invalid mapping to fail")
+	}
+	if !n.Supports("json", "vpn:gateway") {
+		t.Error("previous mappings should be kept after a failed reload")
+	}
+	if reloaded, err := n.Reload(); err != nil || reloaded {
+		t.Errorf("Reload() = %v, %v; want unchanged invalid files to be skipped", reloaded, err)
+	}
+
+	writeMapping(t, dir, `
+name: vpn
+sourcetypes: ["openvpn"]
+class_uid: 3002
+`)
+	if reloaded, err := n.Reload(); err != nil || !reloaded {
+		t.Fatalf("Reload() = %v, %v; want reload", reloaded, err)
+	}
+	if n.Supports("json", "vpn:gateway") || !n.Supports("json", "openvpn") {
+		t.Error("expected the new mapping to replace the old one")
+	}
+}
+
+func TestNewMappingNormalizer_InvalidDir(t *testing.T) {
+	if _, err := NewMappingNormalizer(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for a missing directory")
+	}
+}
@@ -0,0 +1,190 @@
+package normalizer
+
+import (
+	"net"
+	"strings"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/objects"
+)
+
+// Helpers for the network sensor normalizers (Zeek, Suricata).
+
+// durationMillis converts fractional seconds to OCSF's millisecond duration.
+func durationMillis(m map[string]interface{}, path string) (int64, bool) {
+	seconds, ok := getFloat(m, path)
+	if !ok {
+		return 0, false
+	}
+	return int64(seconds * 1000), true
+}
+
+// endpoint builds a network endpoint, or nil without an IP.
+func endpoint(ip string, port int64) *objects.NetworkEndpoint {
+	if ip == "" {
+		return nil
+	}
+	return &objects.NetworkEndpoint{Ip: ip, Port: int(port)}
+}
+
+// protocolNumbers maps transport names to IANA protocol numbers.
+var protocolNumbers = map[string]int{
+	"icmp":      1,
+	"tcp":       6,
+	"udp":       17,
+	"gre":       47,
+	"icmp6":     58,
+	"ipv6-icmp": 58,
+	"sctp":      132,
+}
+
+// connectionInfo builds OCSF connection_info from a transport protocol name,
+// the source IP (for the IP version), a community ID and a sensor flow ID.
+func connectionInfo(proto, srcIP, communityID, uid string) *objects.NetworkConnectionInfo {
+	proto = strings.ToLower(proto)
+	info := &objects.NetworkConnectionInfo{
+		ProtocolName: proto,
+		ProtocolNum:  protocolNumbers[proto],
+		CommunityUid: communityID,
+		Uid:          uid,
+		Direction:    "Unknown",
+	}
+	if ip := net.ParseIP(srcIP); ip != nil {
+		if ip.To4() != nil {
+			info.ProtocolVerId = 4
+		} else {
+			info.ProtocolVerId = 6
+		}
+	}
+	return info
+}
+
+// setDirection sets the connection direction from whether each side is on a
+// local network.
+func setDirection(info *objects.NetworkConnectionInfo, localSrc, localDst bool) {
+	switch {
+	case localSrc && localDst:
+		info.DirectionId, info.Direction = 3, "Lateral"
+	case localSrc:
+		info.DirectionId, info.Direction = 2, "Outbound"
+	case localDst:
+		info.DirectionId, info.Direction = 1, "Inbound"
+	}
+}
+
+// traffic builds OCSF traffic counters, where "out" is sent by the source
+// endpoint; nil when the sensor reported none.
+func traffic(bytesOut, bytesIn, packetsOut, packetsIn int64) *objects.NetworkTraffic {
+	if bytesOut == 0 && bytesIn == 0 && packetsOut == 0 && packetsIn == 0 {
+		return nil
+	}
+	return &objects.NetworkTraffic{
+		Bytes:      bytesOut + bytesIn,
+		BytesOut:   bytesOut,
+		BytesIn:    bytesIn,
+		Packets:    packetsOut + packetsIn,
+		PacketsOut: packetsOut,
+		PacketsIn:  packetsIn,
+	}
+}
+
+// dnsRcodes lists the OCSF rcode names by rcode_id.
+var dnsRcodes = []string{"NoError", "FormError", "ServError", "NXDomain", "NotImp", "Refused", "YXDomain", "YXRRSet", "NXRRSet", "NotAuth", "NotZone"}
+
+// dnsRcode returns the OCSF rcode and rcode_id of a numeric code, or of a
+// name such as "NXDOMAIN" when code is negative.
+func dnsRcode(code int64, name string) (string, int) {
+	if code >= 0 && code < int64(len(dnsRcodes)) {
+		return dnsRcodes[code], int(code)
+	}
+	for id, rcode := range dnsRcodes {
+		if strings.EqualFold(rcode, name) {
+			return rcode, id
+		}
+	}
+	return name, 99
+}
+
+// dnsQuery builds the OCSF query object. objects.DnsQuery has no type or
+// class, which detection rules filter on, so a map is used instead.
+func dnsQuery(hostname, qtype, qclass, packetUID string) map[string]interface{} {
+	query := map[string]interface{}{"hostname": hostname}
+	if qtype != "" {
+		query["type"] = qtype
+	}
+	if qclass != "" {
+		query["class"] = qclass
+	}
+	if packetUID != "" {
+		query["packet_uid"] = packetUID
+	}
+	return query
+}
+
+// httpActivities maps request methods to HTTP Activity activity IDs.
+var httpActivities = map[string]int{
+	"CONNECT": 1,
+	"DELETE":  2,
+	"GET":     3,
+	"HEAD":    4,
+	"OPTIONS": 5,
+	"POST":    6,
+	"PUT":     7,
+	"TRACE":   8,
+	"PATCH":   9,
+}
+
+func httpActivityID(method string) int {
+	if id, ok := httpActivities[strings.ToUpper(method)]; ok {
+		return id
+	}
+	return 99
+}
+
+// httpURL builds the request URL from the Host header and request target.
+func httpURL(host, target string, port int64) *objects.Url {
+	u := &objects.Url{Hostname: host, Port: int(port)}
+	u.Path, u.QueryString, _ = strings.Cut(target, "?")
+	if host != "" {
+		u.Scheme = "http"
+		u.UrlString = "http://" + host + target
+	} else {
+		u.UrlString = target
+	}
+	return u
+}
+
+// tlsVersion normalizes "TLSv12" (Zeek) and "TLS 1.2" (Suricata) to "1.2"
+// so rules match either sensor; other versions such as "SSLv3" are kept.
+func tlsVersion(version string) string {
+	switch v := strings.TrimPrefix(strings.TrimPrefix(version, "TLSv"), "TLS "); {
+	case v == version:
+		return version
+	case len(v) == 2 && !strings.Contains(v, "."):
+		return v[:1] + "." + v[1:]
+	default:
+		return v
+	}
+}
+
+// fingerprint builds a hash fingerprint, or nil for an empty value.
+func fingerprint(algorithmID int, algorithm, value string) *objects.Fingerprint {
+	if value == "" {
+		return nil
+	}
+	return &objects.Fingerprint{AlgorithmId: algorithmID, Algorithm: algorithm, Value: value}
+}
+
+// fileHashes collects the MD5, SHA-1 and SHA-256 hashes of a file record.
+func fileHashes(m map[string]interface{}, md5, sha1, sha256 string) []*objects.Fingerprint {
+	var hashes []*objects.Fingerprint
+	for _, h := range []*objects.Fingerprint{
+		fingerprint(1, "MD5", getString(m, md5)),
+		fingerprint(2, "SHA-1", getString(m, sha1)),
+		fingerprint(3, "SHA-256", getString(m, sha256)),
+	} {
+		if h != nil {
+			hashes = append(hashes, h)
+		}
+	}
+	return hashes
+}
@@ -0,0 +1,243 @@
+package normalizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/application"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/iam"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/objects"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+var oktaProduct = ocsf.Product{Name: "Okta System Log", Vendor: "Okta"}
+
+// oktaAccountChanges maps user lifecycle, credential and factor event types
+// to Account Change activities.
+var oktaAccountChanges = map[string]int{
+	"user.lifecycle.create":           iam.AccountChangeActivityCreate,
+	"user.lifecycle.activate":         iam.AccountChangeActivityEnable,
+	"user.lifecycle.reactivate":       iam.AccountChangeActivityEnable,
+	"user.lifecycle.unsuspend":        iam.AccountChangeActivityEnable,
+	"user.lifecycle.deactivate":       iam.AccountChangeActivityDisable,
+	"user.lifecycle.suspend":          iam.AccountChangeActivityDisable,
+	"user.lifecycle.delete.initiated": iam.AccountChangeActivityDelete,
+	"user.lifecycle.delete.completed": iam.AccountChangeActivityDelete,
+	"user.account.update_password":    iam.AccountChangeActivityPasswordChange,
+	"user.account.reset_password":     iam.AccountChangeActivityPasswordReset,
+	"user.account.lock":               iam.AccountChangeActivityLock,
+	"user.account.lock.limit":         iam.AccountChangeActivityLock,
+	"user.account.unlock":             iam.AccountChangeActivityUnlock,
+	"user.account.unlock_by_admin":    iam.AccountChangeActivityUnlock,
+	"user.mfa.factor.activate":        iam.AccountChangeActivityMFAFactorEnable,
+	"user.mfa.factor.deactivate":      iam.AccountChangeActivityMFAFactorDisable,
+	"user.mfa.factor.reset_all":       iam.AccountChangeActivityMFAFactorDisable,
+	"user.account.privilege.grant":    iam.AccountChangeActivityAttachPolicy,
+	"user.account.privilege.revoke":   iam.AccountChangeActivityDetachPolicy,
+}
+
+// oktaGroupChanges maps group event types to Group Management activities.
+var oktaGroupChanges = map[string]int{
+	"group.user_membership.add":    iam.GroupManagementActivityAddUser,
+	"group.user_membership.remove": iam.GroupManagementActivityRemoveUser,
+	"group.lifecycle.create":       iam.GroupManagementActivityCreate,
+	"group.lifecycle.delete":       iam.GroupManagementActivityDelete,
+	"group.privilege.grant":        iam.GroupManagementActivityAssignPrivileges,
+	"group.privilege.revoke":       iam.GroupManagementActivityRevokePrivileges,
+}
+
+// oktaSeverities maps System Log severities to OCSF severity IDs.
+var oktaSeverities = map[string]int{
+	"DEBUG": ocsf.SeverityInformational,
+	"INFO":  ocsf.SeverityInformational,
+	"WARN":  ocsf.SeverityMedium,
+	"ERROR": ocsf.SeverityHigh,
+}
+
+// OktaNormalizer converts Okta System Log events into OCSF events: sign-ins
+// and sign-outs to Authentication, user lifecycle, credential and factor
+// changes to Account Change, group changes to Group Management, and
+// everything else to API Activity.
+type OktaNormalizer struct{}
+
+// Supports matches JSON payloads with sourcetypes okta:system, okta:log and
+// OktaIM2:log, case-insensitively.
+func (OktaNormalizer) Supports(format, sourceType string) bool {
+	if format != "json" {
+		return false
+	}
+	switch strings.ToLower(sourceType) {
+	case "okta:system", "okta:log", "oktaim2:log":
+		return true
+	}
+	return false
+}
+
+// Normalize maps a System Log event according to its eventType.
+func (OktaNormalizer) Normalize(ctx context.Context, envelope *models.RawEventEnvelope) (*ocsf.Event, error) {
+	_ = ctx
+	payload, err := decodeVendorPayload(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode okta payload: %w", err)
+	}
+	eventType := getString(payload, "eventType")
+	if eventType == "" {
+		return nil, fmt.Errorf("okta event has no eventType")
+	}
+	eventTime, _ := time.Parse(time.RFC3339, getString(payload, "published"))
+	targets := oktaTargets(payload)
+
+	var event *ocsf.Event
+	switch {
+	case eventType == "user.session.start" || strings.HasPrefix(eventType, "user.authentication."):
+		event = &iam.NewAuthentication(iam.AuthenticationActivityLogon).Event
+		initVendorEvent(event, envelope, payload, oktaProduct, eventTime)
+		oktaAuthentication(event, payload, targets)
+	case eventType == "user.session.end":
+		event = &iam.NewAuthentication(iam.AuthenticationActivityLogoff).Event
+		initVendorEvent(event, envelope, payload, oktaProduct, eventTime)
+		oktaAuthentication(event, payload, targets)
+	case oktaAccountChanges[eventType] != 0:
+		event = &iam.NewAccountChange(oktaAccountChanges[eventType]).Event
+		initVendorEvent(event, envelope, payload, oktaProduct, eventTime)
+		if user := oktaTargetUser(targets); user != nil {
+			event.ClassAttributes["user"] = user
+		}
+	case oktaGroupChanges[eventType] != 0:
+		event = &iam.NewGroupManagement(oktaGroupChanges[eventType]).Event
+		initVendorEvent(event, envelope, payload, oktaProduct, eventTime)
+		if user := oktaTargetUser(targets); user != nil {
+			event.ClassAttributes["user"] = user
+		}
+		if group := oktaTarget(targets, "UserGroup"); group != nil {
+			event.ClassAttributes["group"] = &objects.Group{Uid: getString(group, "id"), Name: getString(group, "displayName")}
+		}
+	default:
+		segments := strings.Split(eventType, ".")
+		event = &application.NewApiActivity(apiActivityID(segments[len(segments)-1])).Event
+		initVendorEvent(event, envelope, payload, oktaProduct, eventTime)
+	}
+
+	event.Actor = &objects.Actor{
+		User: &objects.User{
+			Uid:      getString(payload, "actor.id"),
+			Name:     getString(payload, "actor.alternateId"),
+			FullName: getString(payload, "actor.displayName"),
+			Type:     getString(payload, "actor.type"),
+		},
+	}
+	if sessionID := getString(payload, "authenticationContext.externalSessionId"); sessionID != "" {
+		event.Actor.Session = &objects.Session{Uid: sessionID}
+	}
+	event.SrcEndpoint = oktaClient(payload)
+
+	switch getString(payload, "outcome.result") {
+	case "SUCCESS", "ALLOW":
+		setStatus(event, ocsf.StatusSuccess, getString(payload, "outcome.reason"))
+	case "FAILURE", "DENY":
+		setStatus(event, ocsf.StatusFailure, getString(payload, "outcome.reason"))
+	case "":
+	default:
+		setStatus(event, ocsf.StatusOther, getString(payload, "outcome.reason"))
+	}
+	if severityID, ok := oktaSeverities[strings.ToUpper(getString(payload, "severity"))]; ok {
+		setSeverity(event, severityID)
+	} else {
+		setSeverity(event, ocsf.SeverityInformational)
+	}
+
+	event.ClassAttributes["api"] = &objects.Api{
+		Operation: eventType,
+		Service:   &objects.Service{Name: "Okta"},
+		Request:   &objects.Request{Uid: getString(payload, "transaction.id")},
+	}
+	if userAgent := getString(payload, "client.userAgent.rawUserAgent"); userAgent != "" {
+		event.ClassAttributes["http_request"] = &objects.HttpRequest{UserAgent: userAgent}
+	}
+	if message := getString(payload, "displayMessage"); message != "" {
+		event.ClassAttributes["message"] = message
+	}
+	if len(targets) > 0 {
+		resources := make([]map[string]interface{}, 0, len(targets))
+		for _, target := range targets {
+			resources = append(resources, resource(getString(target, "id"), getString(target, "displayName"), getString(target, "type")))
+		}
+		event.ClassAttributes["resources"] = resources
+	}
+	event.Properties["event_uid"] = getString(payload, "uuid")
+	return event, nil
+}
+
+// oktaAuthentication adds the authenticated user, the application signed in
+// to and whether a factor was verified.
+func oktaAuthentication(event *ocsf.Event, payload map[string]interface{}, targets []map[string]interface{}) {
+	event.ClassAttributes["user"] = &objects.User{
+		Uid:      getString(payload, "actor.id"),
+		Name:     getString(payload, "actor.alternateId"),
+		FullName: getString(payload, "actor.displayName"),
+	}
+	event.ClassAttributes["is_mfa"] = strings.Contains(getString(payload, "eventType"), "mfa")
+	if app := oktaTarget(targets, "AppInstance"); app != nil {
+		event.ClassAttributes["service"] = &objects.Service{Uid: getString(app, "id"), Name: getString(app, "displayName")}
+	}
+}
+
+// oktaClient maps the client IP, its geolocation and the ISP.
+func oktaClient(payload map[string]interface{}) *objects.NetworkEndpoint {
+	ip := getString(payload, "client.ipAddress")
+	if ip == "" {
+		return nil
+	}
+	endpoint := &objects.NetworkEndpoint{Ip: ip, Isp: getString(payload, "securityContext.isp")}
+	if geo := getMap(payload, "client.geographicalContext"); geo != nil {
+		location := &objects.Location{
+			City:       getString(geo, "city"),
+			Country:    getString(geo, "country"),
+			Region:     getString(geo, "state"),
+			PostalCode: getString(geo, "postalCode"),
+		}
+		lat, latOK := getFloat(geo, "geolocation.lat")
+		lon, lonOK := getFloat(geo, "geolocation.lon")
+		if latOK && lonOK {
+			location.Coordinates = []float64{lon, lat}
+		}
+		endpoint.Location = location
+	}
+	return endpoint
+}
+
+func oktaTargets(payload map[string]interface{}) []map[string]interface{} {
+	items, _ := payload["target"].([]interface{})
+	targets := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if target, ok := item.(map[string]interface{}); ok {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// oktaTarget returns the first target of the given type.
+func oktaTarget(targets []map[string]interface{}, targetType string) map[string]interface{} {
+	for _, target := range targets {
+		if getString(target, "type") == targetType {
+			return target
+		}
+	}
+	return nil
+}
+
+func oktaTargetUser(targets []map[string]interface{}) *objects.User {
+	target := oktaTarget(targets, "User")
+	if target == nil {
+		return nil
+	}
+	return &objects.User{
+		Uid:      getString(target, "id"),
+		Name:     getString(target, "alternateId"),
+		FullName: getString(target, "displayName"),
+	}
+}
@@ -0,0 +1,79 @@
+package normalizer
+
+import "testing"
+
+func TestOktaNormalizer_Export(t *testing.T) {
+	records := loadExport(t, "okta.json")
+	if len(records) != 5 {
+		t.Fatalf("expected 5 records, got %d", len(records))
+	}
+
+	t.Run("failed sign-in", func(t *testing.T) {
+		doc := normalizeDocument(t, OktaNormalizer{}, "OktaIM2:log", records[0])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":                     float64(3002),
+			"activity":                      "Logon",
+			"time":                          "2026-03-01T10:00:00.123Z",
+			"status":                        "Failure",
+			"status_detail":                 "INVALID_CREDENTIALS",
+			"severity":                      "Informational",
+			"user.name":                     "alice@example.com",
+			"actor.user.uid":                "00u1alice",
+			"actor.user.full_name":          "Alice Example",
+			"actor.session.uid":             "102sessionAlice",
+			"src_endpoint.ip":               "203.0.113.10",
+			"src_endpoint.isp":              "deutsche telekom ag",
+			"src_endpoint.location.city":    "Berlin",
+			"src_endpoint.location.country": "Germany",
+			"http_request.user_agent":       "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
+			"api.operation":                 "user.session.start",
+			"api.request.uid":               "YtxAbCdEfG1",
+			"message":                       "User login to Okta",
+			"metadata.product.vendor_name":  "Okta",
+		})
+	})
+
+	t.Run("mfa to app", func(t *testing.T) {
+		doc := normalizeDocument(t, OktaNormalizer{}, "okta:system", records[1])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":    float64(3002),
+			"is_mfa":       true,
+			"status":       "Success",
+			"service.name": "Salesforce.com",
+		})
+	})
+
+	t.Run("factor reset", func(t *testing.T) {
+		doc := normalizeDocument(t, OktaNormalizer{}, "okta:log", records[2])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":       float64(3001),
+			"activity":        "MFA Factor Disable",
+			"severity":        "Medium",
+			"user.name":       "bob@example.com",
+			"actor.user.name": "admin@example.com",
+		})
+	})
+
+	t.Run("group membership", func(t *testing.T) {
+		doc := normalizeDocument(t, OktaNormalizer{}, "okta:system", records[3])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":  float64(3006),
+			"activity":   "Add User",
+			"group.uid":  "00g1admins",
+			"group.name": "Super Admins",
+			"user.uid":   "00u1bob",
+		})
+		if resources, _ := doc["resources"].([]interface{}); len(resources) != 2 {
+			t.Errorf("expected two resources, got %v", doc["resources"])
+		}
+	})
+
+	t.Run("api activity", func(t *testing.T) {
+		doc := normalizeDocument(t, OktaNormalizer{}, "okta:system", records[4])
+		expectFields(t, doc, map[string]interface{}{
+			"class_uid":        float64(6003),
+			"activity":         "Update",
+			"api.service.name": "Okta",
+		})
+	})
+}
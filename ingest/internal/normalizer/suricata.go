@@ -0,0 +1,353 @@
+package normalizer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/findings"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/network"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/objects"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+var suricataProduct = ocsf.Product{Name: "Suricata", Vendor: "OISF"}
+
+// suricataTimeLayout is the EVE timestamp format, whose zone offset has no
+// colon.
+const suricataTimeLayout = "2006-01-02T15:04:05.999999-0700"
+
+// SuricataNormalizer converts Suricata EVE JSON records into OCSF events:
+// alert to Detection Finding with ATT&CK metadata, flow to Network Activity,
+// dns to DNS Activity, http to HTTP Activity, tls to Network Activity with
+// TLS details and fileinfo to Network File Activity. Other event types are
+// stored as base events.
+//
+// The event type is taken from event_type, falling back to the sourcetype
+// ("suricata:alert").
+type SuricataNormalizer struct{}
+
+// Supports matches JSON payloads with sourcetype suricata or suricata:<type>.
+func (SuricataNormalizer) Supports(format, sourceType string) bool {
+	return format == "json" && (sourceType == "suricata" || strings.HasPrefix(sourceType, "suricata:"))
+}
+
+// Normalize maps an EVE record according to its event type.
+func (SuricataNormalizer) Normalize(ctx context.Context, envelope *models.RawEventEnvelope) (*ocsf.Event, error) {
+	_ = ctx
+	payload, err := decodeVendorPayload(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode suricata payload: %w", err)
+	}
+
+	eventType := getString(payload, "event_type")
+	if eventType == "" {
+		eventType = strings.TrimPrefix(envelope.SourceType, "suricata:")
+	}
+	eventTime := suricataTime(getString(payload, "timestamp"))
+
+	var event *ocsf.Event
+	switch eventType {
+	case "alert":
+		event = suricataAlert(envelope, payload, eventTime)
+	case "flow":
+		event = suricataFlow(envelope, payload, eventTime)
+	case "dns":
+		event = suricataDNS(envelope, payload, eventTime)
+	case "http":
+		event = suricataHTTP(envelope, payload, eventTime)
+	case "tls":
+		event = suricataTLS(envelope, payload, eventTime)
+	case "fileinfo":
+		event = suricataFileinfo(envelope, payload, eventTime)
+	default:
+		return unsupportedVendorLog(envelope, payload, suricataProduct, eventTime, eventType), nil
+	}
+	event.Properties["log_name"] = eventType
+	if sensor := getString(payload, "host"); sensor != "" {
+		event.Properties["sensor"] = sensor
+	}
+	return event, nil
+}
+
+func suricataTime(value string) time.Time {
+	for _, layout := range []string{suricataTimeLayout, time.RFC3339Nano} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// suricataNetwork fills the endpoints and the connection_info and traffic
+// class attributes every EVE record of a flow shares.
+func suricataNetwork(event *ocsf.Event, payload map[string]interface{}) {
+	srcIP := getString(payload, "src_ip")
+	event.SrcEndpoint = endpoint(srcIP, getInt(payload, "src_port"))
+	event.DstEndpoint = endpoint(getString(payload, "dest_ip"), getInt(payload, "dest_port"))
+	if event.DstEndpoint != nil {
+		event.DstEndpoint.SvcName = getString(payload, "app_proto")
+	}
+
+	event.ClassAttributes["connection_info"] = connectionInfo(getString(payload, "proto"), srcIP, getString(payload, "community_id"), getString(payload, "flow_id"))
+	if t := traffic(getInt(payload, "flow.bytes_toserver"), getInt(payload, "flow.bytes_toclient"), getInt(payload, "flow.pkts_toserver"), getInt(payload, "flow.pkts_toclient")); t != nil {
+		event.ClassAttributes["traffic"] = t
+	}
+}
+
+// suricataSeverity maps alert severities, where 1 is the most severe, to
+// OCSF severities.
+func suricataSeverity(severity int64) int {
+	switch severity {
+	case 1:
+		return ocsf.SeverityHigh
+	case 2:
+		return ocsf.SeverityMedium
+	case 3:
+		return ocsf.SeverityLow
+	default:
+		return ocsf.SeverityInformational
+	}
+}
+
+// suricataAttacks reads the ATT&CK metadata that rule sets such as ET Open
+// add to signatures, pairing the nth technique with the nth tactic.
+func suricataAttacks(metadata map[string]interface{}) []*objects.Attack {
+	tacticIDs, tacticNames := getStrings(metadata, "mitre_tactic_id"), getStrings(metadata, "mitre_tactic_name")
+	techniqueIDs, techniqueNames := getStrings(metadata, "mitre_technique_id"), getStrings(metadata, "mitre_technique_name")
+
+	var attacks []*objects.Attack
+	for i := 0; i < len(techniqueIDs) || i < len(tacticIDs); i++ {
+		attack := &objects.Attack{}
+		if i < len(tacticIDs) {
+			attack.Tactic = &objects.Tactic{Uid: tacticIDs[i], Name: attackName(tacticNames, i)}
+		} else if len(tacticIDs) > 0 {
+			attack.Tactic = &objects.Tactic{Uid: tacticIDs[0], Name: attackName(tacticNames, 0)}
+		}
+		if i < len(techniqueIDs) {
+			attack.Technique = &objects.Technique{Uid: techniqueIDs[i], Name: attackName(techniqueNames, i)}
+		}
+		attacks = append(attacks, attack)
+	}
+	return attacks
+}
+
+// attackName returns the ith ATT&CK name with the underscores rule metadata
+// uses for spaces replaced.
+func attackName(names []string, i int) string {
+	if i >= len(names) {
+		return ""
+	}
+	return strings.ReplaceAll(names[i], "_", " ")
+}
+
+func suricataAlert(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	event := &findings.NewDetectionFinding(1).Event
+	initVendorEvent(event, envelope, payload, suricataProduct, eventTime)
+	setSeverity(event, suricataSeverity(getInt(payload, "alert.severity")))
+	suricataNetwork(event, payload)
+
+	signature := getString(payload, "alert.signature")
+	sid := getString(payload, "alert.signature_id")
+	category := getString(payload, "alert.category")
+	attacks := suricataAttacks(getMap(payload, "alert.metadata"))
+
+	info := &objects.FindingInfo{
+		Uid:   fmt.Sprintf("%d:%s", getInt(payload, "alert.gid"), sid),
+		Title: signature,
+		Analytic: &objects.Analytic{
+			Name:     signature,
+			Uid:      sid,
+			Type:     "Rule",
+			TypeId:   1,
+			Category: category,
+			Version:  getString(payload, "alert.rev"),
+		},
+		Attacks: attacks,
+	}
+	if category != "" {
+		info.Types = []string{category}
+	}
+	event.ClassAttributes["finding_info"] = info
+	event.ClassAttributes["is_alert"] = true
+	if len(attacks) > 0 {
+		event.ClassAttributes["attacks"] = attacks
+		hoistAttacks(event, attacks)
+	}
+
+	switch getString(payload, "alert.action") {
+	case "allowed":
+		event.ClassAttributes["action"], event.ClassAttributes["action_id"] = "Allowed", 1
+	case "blocked":
+		event.ClassAttributes["action"], event.ClassAttributes["action_id"] = "Denied", 2
+	}
+	return event
+}
+
+// suricataFlowActivity maps the flow state to a Network Activity activity.
+func suricataFlowActivity(payload map[string]interface{}) int {
+	if rst, _ := getBool(payload, "tcp.rst"); rst {
+		return network.NetworkActivityActivityReset
+	}
+	switch getString(payload, "flow.state") {
+	case "new":
+		return network.NetworkActivityActivityOpen
+	case "closed":
+		return network.NetworkActivityActivityClose
+	default:
+		return network.NetworkActivityActivityTraffic
+	}
+}
+
+func suricataFlow(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	event := &network.NewNetworkActivity(suricataFlowActivity(payload)).Event
+	initVendorEvent(event, envelope, payload, suricataProduct, eventTime)
+	setSeverity(event, ocsf.SeverityInformational)
+	suricataNetwork(event, payload)
+
+	info := event.ClassAttributes["connection_info"].(*objects.NetworkConnectionInfo)
+	if flags, err := strconv.ParseInt(getString(payload, "tcp.tcp_flags"), 16, 64); err == nil {
+		info.TcpFlags = int(flags)
+	}
+	if duration, ok := durationMillis(payload, "flow.age"); ok {
+		event.ClassAttributes["duration"] = duration
+	}
+	return event
+}
+
+func suricataDNS(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	dns := getMap(payload, "dns")
+	activity := network.DnsActivityActivityQuery
+	if t := getString(dns, "type"); t == "answer" || t == "response" {
+		activity = network.DnsActivityActivityResponse
+	}
+
+	event := &network.NewDnsActivity(activity).Event
+	initVendorEvent(event, envelope, payload, suricataProduct, eventTime)
+	setSeverity(event, ocsf.SeverityInformational)
+	suricataNetwork(event, payload)
+
+	// EVE version 3 lists the queries; earlier versions log one per record
+	hostname, qtype := getString(dns, "rrname"), getString(dns, "rrtype")
+	if queries, _ := dns["queries"].([]interface{}); len(queries) > 0 {
+		if query, ok := queries[0].(map[string]interface{}); ok {
+			hostname, qtype = getString(query, "rrname"), getString(query, "rrtype")
+		}
+	}
+	event.ClassAttributes["query"] = dnsQuery(hostname, qtype, "", getString(dns, "id"))
+
+	var answers []*objects.DnsAnswer
+	if items, _ := dns["answers"].([]interface{}); len(items) > 0 {
+		for _, item := range items {
+			if answer, ok := item.(map[string]interface{}); ok {
+				answers = append(answers, &objects.DnsAnswer{
+					Rdata: getString(answer, "rdata"),
+					Type:  getString(answer, "rrtype"),
+					Ttl:   int(getInt(answer, "ttl")),
+				})
+			}
+		}
+	} else if rdata := getString(dns, "rdata"); rdata != "" {
+		// EVE version 1 logs each answer as its own record
+		answers = append(answers, &objects.DnsAnswer{Rdata: rdata, Type: qtype, Ttl: int(getInt(dns, "ttl"))})
+	}
+	if len(answers) > 0 {
+		event.ClassAttributes["answers"] = answers
+	}
+	if name := getString(dns, "rcode"); name != "" {
+		rcode, rcodeID := dnsRcode(-1, name)
+		event.ClassAttributes["rcode"] = rcode
+		event.ClassAttributes["rcode_id"] = rcodeID
+	}
+	return event
+}
+
+func suricataHTTP(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	method := getString(payload, "http.http_method")
+	event := &network.NewHttpActivity(httpActivityID(method)).Event
+	initVendorEvent(event, envelope, payload, suricataProduct, eventTime)
+	setSeverity(event, ocsf.SeverityInformational)
+	suricataNetwork(event, payload)
+
+	host := getString(payload, "http.hostname")
+	if event.DstEndpoint != nil {
+		event.DstEndpoint.Hostname = host
+	}
+	port := getInt(payload, "http.http_port")
+	if port == 0 {
+		port = getInt(payload, "dest_port")
+	}
+	event.ClassAttributes["http_request"] = &objects.HttpRequest{
+		HttpMethod: method,
+		Url:        httpURL(host, getString(payload, "http.url"), port),
+		UserAgent:  getString(payload, "http.http_user_agent"),
+		Referrer:   getString(payload, "http.http_refer"),
+		Version:    getString(payload, "http.protocol"),
+	}
+	if code := getInt(payload, "http.status"); code != 0 {
+		event.ClassAttributes["http_response"] = &objects.HttpResponse{
+			Code:        int(code),
+			Length:      int(getInt(payload, "http.length")),
+			ContentType: getString(payload, "http.http_content_type"),
+		}
+		event.ClassAttributes["http_status"] = code
+	}
+	return event
+}
+
+func suricataTLS(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	// Suricata logs TLS once the handshake completes
+	event := &network.NewNetworkActivity(network.NetworkActivityActivityOpen).Event
+	initVendorEvent(event, envelope, payload, suricataProduct, eventTime)
+	setSeverity(event, ocsf.SeverityInformational)
+	suricataNetwork(event, payload)
+
+	sni := getString(payload, "tls.sni")
+	if event.DstEndpoint != nil {
+		event.DstEndpoint.Hostname = sni
+	}
+	tls := &objects.Tls{
+		Version:  tlsVersion(getString(payload, "tls.version")),
+		Sni:      sni,
+		Ja3Hash:  fingerprint(1, "MD5", getString(payload, "tls.ja3.hash")),
+		Ja3sHash: fingerprint(1, "MD5", getString(payload, "tls.ja3s.hash")),
+	}
+	if subject, issuer := getString(payload, "tls.subject"), getString(payload, "tls.issuerdn"); subject != "" || issuer != "" {
+		tls.Certificate = &objects.Certificate{
+			Subject:      subject,
+			Issuer:       issuer,
+			SerialNumber: getString(payload, "tls.serial"),
+		}
+		if fp := fingerprint(2, "SHA-1", getString(payload, "tls.fingerprint")); fp != nil {
+			tls.Certificate.Fingerprints = []*objects.Fingerprint{fp}
+		}
+	}
+	event.ClassAttributes["tls"] = tls
+	return event
+}
+
+func suricataFileinfo(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	activity := network.NetworkFileActivityActivityDownload
+	switch strings.ToUpper(getString(payload, "http.http_method")) {
+	case "POST", "PUT":
+		activity = network.NetworkFileActivityActivityUpload
+	}
+
+	event := &network.NewNetworkFileActivity(activity).Event
+	initVendorEvent(event, envelope, payload, suricataProduct, eventTime)
+	setSeverity(event, ocsf.SeverityInformational)
+	suricataNetwork(event, payload)
+
+	event.File = &objects.File{
+		Name:   getString(payload, "fileinfo.filename"),
+		Size:   getInt(payload, "fileinfo.size"),
+		Desc:   getString(payload, "fileinfo.magic"),
+		Hashes: fileHashes(getMap(payload, "fileinfo"), "md5", "sha1", "sha256"),
+		TypeId: 1,
+		Type:   "Regular File",
+	}
+	return event
+}
@@ -0,0 +1,128 @@
+package normalizer
+
+import "testing"
+
+func TestSuricataNormalizer_Alert(t *testing.T) {
+	doc := normalizeDocument(t, SuricataNormalizer{}, "suricata:eve", `{
+		"timestamp": "2026-03-01T10:00:00.123456+0000", "flow_id": 1805461738637437, "event_type": "alert",
+		"src_ip": "203.0.113.7", "src_port": 44123, "dest_ip": "10.0.0.5", "dest_port": 80, "proto": "TCP",
+		"app_proto": "http", "community_id": "1:Fz6nO5jBlMNWMxTjXyN0B+CEJxU=",
+		"alert": {
+			"action": "allowed", "gid": 1, "signature_id": 2024897, "rev": 3,
+			"signature": "ET EXPLOIT Apache Struts RCE", "category": "Attempted Administrator Privilege Gain",
+			"severity": 1,
+			"metadata": {
+				"mitre_tactic_id": ["TA0001"], "mitre_tactic_name": ["Initial_Access"],
+				"mitre_technique_id": ["T1190"], "mitre_technique_name": ["Exploit_Public-Facing_Application"]
+			}
+		},
+		"flow": {"pkts_toserver": 4, "pkts_toclient": 3, "bytes_toserver": 900, "bytes_toclient": 400}
+	}`)
+
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":                     float64(2004),
+		"activity":                      "Create",
+		"time":                          "2026-03-01T10:00:00.123456Z",
+		"severity_id":                   float64(4),
+		"severity":                      "High",
+		"metadata.product.name":         "Suricata",
+		"finding_info.title":            "ET EXPLOIT Apache Struts RCE",
+		"finding_info.uid":              "1:2024897",
+		"finding_info.analytic.uid":     "2024897",
+		"attack_tactic":                 "Initial Access",
+		"attack_tactic_uid":             "TA0001",
+		"attack_technique":              "Exploit Public-Facing Application",
+		"attack_technique_uid":          "T1190",
+		"action":                        "Allowed",
+		"src_endpoint.ip":               "203.0.113.7",
+		"dst_endpoint.port":             float64(80),
+		"dst_endpoint.svc_name":         "http",
+		"connection_info.protocol_name": "tcp",
+		"connection_info.protocol_num":  float64(6),
+		"connection_info.community_uid": "1:Fz6nO5jBlMNWMxTjXyN0B+CEJxU=",
+		"connection_info.uid":           "1805461738637437",
+		"traffic.bytes_out":             float64(900),
+		"traffic.bytes_in":              float64(400),
+		"properties.log_name":           "alert",
+	})
+	if attacks, _ := doc["attacks"].([]interface{}); len(attacks) != 1 {
+		t.Errorf("expected one attack, got %v", doc["attacks"])
+	}
+}
+
+func TestSuricataNormalizer_Flow(t *testing.T) {
+	doc := normalizeDocument(t, SuricataNormalizer{}, "suricata", `{
+		"timestamp": "2026-03-01T10:00:00.000000+0000", "flow_id": 1, "event_type": "flow",
+		"src_ip": "10.0.0.5", "src_port": 5353, "dest_ip": "10.0.0.1", "dest_port": 53, "proto": "UDP",
+		"flow": {"pkts_toserver": 1, "pkts_toclient": 1, "bytes_toserver": 80, "bytes_toclient": 120, "age": 2, "state": "closed"}
+	}`)
+
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":                     float64(4001),
+		"activity":                      "Close",
+		"connection_info.protocol_name": "udp",
+		"connection_info.protocol_num":  float64(17),
+		"traffic.bytes":                 float64(200),
+		"duration":                      float64(2000),
+	})
+}
+
+func TestSuricataNormalizer_DNS(t *testing.T) {
+	doc := normalizeDocument(t, SuricataNormalizer{}, "suricata:dns", `{
+		"timestamp": "2026-03-01T10:00:00.000000+0000", "event_type": "dns",
+		"src_ip": "10.0.0.1", "src_port": 53, "dest_ip": "10.0.0.5", "dest_port": 5353, "proto": "UDP",
+		"dns": {"version": 2, "type": "answer", "id": 4242, "rrname": "example.com", "rrtype": "A", "rcode": "NXDOMAIN"}
+	}`)
+
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":      float64(4003),
+		"activity":       "Response",
+		"query.hostname": "example.com",
+		"query.type":     "A",
+		"rcode":          "NXDomain",
+		"rcode_id":       float64(3),
+	})
+}
+
+func TestSuricataNormalizer_HTTPAndTLS(t *testing.T) {
+	doc := normalizeDocument(t, SuricataNormalizer{}, "suricata", `{
+		"timestamp": "2026-03-01T10:00:00.000000+0000", "event_type": "http",
+		"src_ip": "10.0.0.5", "src_port": 51000, "dest_ip": "93.184.216.34", "dest_port": 80, "proto": "TCP",
+		"http": {"hostname": "example.com", "url": "/login", "http_user_agent": "curl/8.0",
+			"http_method": "POST", "protocol": "HTTP/1.1", "status": 302, "length": 0}
+	}`)
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":                   float64(4002),
+		"activity":                    "Post",
+		"http_request.url.url_string": "http://example.com/login",
+		"http_response.code":          float64(302),
+	})
+
+	doc = normalizeDocument(t, SuricataNormalizer{}, "suricata", `{
+		"timestamp": "2026-03-01T10:00:00.000000+0000", "event_type": "tls",
+		"src_ip": "10.0.0.5", "src_port": 51234, "dest_ip": "93.184.216.34", "dest_port": 443, "proto": "TCP",
+		"tls": {"subject": "CN=example.com", "issuerdn": "CN=Example CA", "sni": "example.com",
+			"version": "TLS 1.2", "ja3": {"hash": "e7d705a3286e19ea42f587b344ee6865"}}
+	}`)
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":              float64(4001),
+		"tls.version":            "1.2",
+		"tls.sni":                "example.com",
+		"tls.certificate.issuer": "CN=Example CA",
+		"tls.ja3_hash.value":     "e7d705a3286e19ea42f587b344ee6865",
+	})
+}
+
+func TestTLSVersion(t *testing.T) {
+	tests := map[string]string{
+		"TLSv12":  "1.2",
+		"TLSv13":  "1.3",
+		"TLS 1.2": "1.2",
+		"SSLv3":   "SSLv3",
+	}
+	for version, want := range tests {
+		if got := tlsVersion(version); got != want {
+			t.Errorf("tlsVersion(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
@@ -0,0 +1,463 @@
+package normalizer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/network"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/system"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/objects"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+var sysmonProduct = ocsf.Product{Name: "Sysmon", Vendor: "Microsoft"}
+
+// Registry classes from the OCSF Windows extension, which common/ocsf does
+// not generate.
+const (
+	classRegistryKeyActivity   = 201001
+	classRegistryValueActivity = 201002
+)
+
+// sysmonRegistryActivities maps the EventType of Sysmon registry events
+// (IDs 12-14) to a registry class and activity.
+var sysmonRegistryActivities = map[string]struct {
+	classUID   int
+	activityID int
+	activity   string
+}{
+	"CreateKey":   {classRegistryKeyActivity, 1, "Create"},
+	"DeleteKey":   {classRegistryKeyActivity, 4, "Delete"},
+	"RenameKey":   {classRegistryKeyActivity, 5, "Rename"},
+	"CreateValue": {classRegistryValueActivity, 2, "Set"},
+	"SetValue":    {classRegistryValueActivity, 2, "Set"},
+	"DeleteValue": {classRegistryValueActivity, 4, "Delete"},
+}
+
+// SysmonNormalizer converts Sysmon events into OCSF process, network, module,
+// file, registry and DNS events with the process, parent process, user and
+// device populated:
+//
+//	1   Process Create        Process Activity: Launch
+//	3   Network connection    Network Activity: Open
+//	7   Image loaded          Module Activity: Load
+//	8   CreateRemoteThread    Process Activity: Inject
+//	10  ProcessAccess         Process Activity: Open
+//	11  FileCreate            File System Activity: Create
+//	12-14 Registry events     Registry Key / Registry Value Activity
+//	22  DNSEvent              DNS Activity: Response
+//
+// Events arrive as Winlogbeat documents (winlog.event_data), Windows XML
+// rendered to JSON (Event.System, Event.EventData.Data) or flat JSON with the
+// event data fields at the top level. Rule names that carry ATT&CK technique
+// IDs become attacks.
+type SysmonNormalizer struct{}
+
+// Supports matches JSON payloads with sourcetype sysmon, sysmon:<anything>,
+// or a Windows event log sourcetype for the Sysmon channel, such as
+// XmlWinEventLog:Microsoft-Windows-Sysmon/Operational.
+func (SysmonNormalizer) Supports(format, sourceType string) bool {
+	if format != "json" {
+		return false
+	}
+	sourceType = strings.ToLower(sourceType)
+	return sourceType == "sysmon" || strings.HasPrefix(sourceType, "sysmon:") || strings.Contains(sourceType, "microsoft-windows-sysmon")
+}
+
+// Normalize maps a Sysmon event according to its event ID.
+func (SysmonNormalizer) Normalize(ctx context.Context, envelope *models.RawEventEnvelope) (*ocsf.Event, error) {
+	_ = ctx
+	payload, err := decodeVendorPayload(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode sysmon payload: %w", err)
+	}
+	eventID, computer, data := sysmonFields(payload)
+	if eventID == 0 {
+		return nil, fmt.Errorf("sysmon event has no event ID")
+	}
+	eventTime := sysmonTime(data["UtcTime"])
+	if eventTime.IsZero() {
+		eventTime, _ = time.Parse(time.RFC3339Nano, getString(payload, "@timestamp"))
+	}
+
+	var event *ocsf.Event
+	switch eventID {
+	case 1:
+		event = sysmonProcessCreate(envelope, payload, data, eventTime)
+	case 3:
+		event = sysmonNetworkConnection(envelope, payload, data, eventTime)
+	case 7:
+		event = &system.NewModuleActivity(system.ModuleActivityActivityLoad).Event
+		initVendorEvent(event, envelope, payload, sysmonProduct, eventTime)
+		event.Actor = sysmonActor(data, "")
+		event.ClassAttributes["module"] = &objects.Module{File: sysmonImageFile(data, "ImageLoaded"), Type: "Shared Library"}
+	case 8:
+		event = &system.NewProcessActivity(system.ProcessActivityActivityInject).Event
+		initVendorEvent(event, envelope, payload, sysmonProduct, eventTime)
+		event.Actor = sysmonActor(data, "Source")
+		event.Process = sysmonProcess(data, "Target")
+		event.ClassAttributes["injection_type"] = "Remote Thread"
+		event.ClassAttributes["injection_type_id"] = 1
+		event.ClassAttributes["module"] = &objects.Module{
+			File:         pathFile(sysmonString(data, "StartModule")),
+			FunctionName: sysmonString(data, "StartFunction"),
+			StartAddress: sysmonString(data, "StartAddress"),
+		}
+	case 10:
+		event = &system.NewProcessActivity(system.ProcessActivityActivityOpen).Event
+		initVendorEvent(event, envelope, payload, sysmonProduct, eventTime)
+		event.Actor = sysmonActor(data, "Source")
+		event.Process = sysmonProcess(data, "Target")
+		if access, err := strconv.ParseInt(sysmonString(data, "GrantedAccess"), 0, 64); err == nil {
+			event.ClassAttributes["requested_permissions"] = access
+		}
+		if callTrace := sysmonString(data, "CallTrace"); callTrace != "" {
+			event.Properties["call_trace"] = callTrace
+		}
+	case 11:
+		event = &system.NewFileActivity(system.FileActivityActivityCreate).Event
+		initVendorEvent(event, envelope, payload, sysmonProduct, eventTime)
+		event.Actor = sysmonActor(data, "")
+		event.File = pathFile(sysmonString(data, "TargetFilename"))
+		if event.File != nil {
+			if created := sysmonTime(data["CreationUtcTime"]); !created.IsZero() {
+				event.File.CreatedTime = created.UnixMilli()
+			}
+		}
+	case 12, 13, 14:
+		event = sysmonRegistry(envelope, payload, data, eventTime)
+	case 22:
+		event = sysmonDNS(envelope, payload, data, eventTime)
+	default:
+		event = unsupportedVendorLog(envelope, payload, sysmonProduct, eventTime, fmt.Sprintf("event_id_%d", eventID))
+		event.Properties["event_id"] = strconv.FormatInt(eventID, 10)
+		return event, nil
+	}
+
+	if event.SeverityID == 0 {
+		setSeverity(event, ocsf.SeverityInformational)
+	}
+	event.Device = &objects.Device{
+		Hostname: computer,
+		Os:       &objects.Os{Name: "Windows", Type: "Windows", TypeId: 100},
+	}
+	event.Properties["event_id"] = strconv.FormatInt(eventID, 10)
+	if ruleName := sysmonString(data, "RuleName"); ruleName != "" && ruleName != "-" {
+		event.Properties["rule_name"] = ruleName
+		if attacks := techniqueAttacks(ruleName); len(attacks) > 0 {
+			event.ClassAttributes["attacks"] = attacks
+			hoistAttacks(event, attacks)
+		}
+	}
+	return event, nil
+}
+
+// sysmonFields returns the event ID, computer name and event data of the
+// supported JSON renderings of a Sysmon event.
+func sysmonFields(payload map[string]interface{}) (int64, string, map[string]interface{}) {
+	if winlog := getMap(payload, "winlog"); winlog != nil {
+		return getInt(winlog, "event_id"), getString(winlog, "computer_name"), getMap(winlog, "event_data")
+	}
+	if xml := getMap(payload, "Event"); xml != nil {
+		eventID := getInt(xml, "System.EventID")
+		if eventID == 0 {
+			eventID = getInt(xml, "System.EventID.#text")
+		}
+		return eventID, getString(xml, "System.Computer"), sysmonXMLData(lookup(xml, "EventData.Data"))
+	}
+
+	data := getMap(payload, "EventData")
+	if data == nil {
+		data = getMap(payload, "event_data")
+	}
+	if data == nil {
+		data = payload
+	}
+	eventID := getInt(payload, "EventID")
+	if eventID == 0 {
+		eventID = getInt(payload, "event_id")
+	}
+	computer := getString(payload, "Computer")
+	if computer == "" {
+		computer = getString(payload, "Hostname")
+	}
+	if computer == "" {
+		computer = getString(payload, "host.name")
+	}
+	return eventID, computer, data
+}
+
+// sysmonXMLData flattens <Data Name="...">value</Data> elements, rendered
+// as {"@Name": ..., "#text": ...} or {"Name": ..., "Value": ...}.
+func sysmonXMLData(value interface{}) map[string]interface{} {
+	items, _ := value.([]interface{})
+	data := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		element, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name := getString(element, "@Name")
+		if name == "" {
+			name = getString(element, "Name")
+		}
+		text := element["#text"]
+		if text == nil {
+			text = element["Value"]
+		}
+		if name != "" && text != nil {
+			data[name] = text
+		}
+	}
+	return data
+}
+
+// sysmonString returns an event data field, where Sysmon writes "-" for
+// values it does not have.
+func sysmonString(data map[string]interface{}, field string) string {
+	value := getString(data, field)
+	if value == "-" {
+		return ""
+	}
+	return value
+}
+
+// sysmonTime parses Sysmon's UTC timestamps ("2026-03-01 10:00:00.123").
+func sysmonTime(value interface{}) time.Time {
+	s, _ := value.(string)
+	t, err := time.Parse("2006-01-02 15:04:05.999999999", s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// sysmonUser splits a DOMAIN\name account.
+func sysmonUser(account string) *objects.User {
+	if account == "" || account == "-" {
+		return nil
+	}
+	if domain, name, found := strings.Cut(account, `\`); found {
+		return &objects.User{Name: name, Domain: domain}
+	}
+	return &objects.User{Name: account}
+}
+
+// sysmonProcess builds the process whose event data fields start with
+// prefix (Parent, Source or Target). Process access events spell the GUID
+// field ProcessGUID.
+func sysmonProcess(data map[string]interface{}, prefix string) *objects.Process {
+	image := sysmonString(data, prefix+"Image")
+	guid := sysmonString(data, prefix+"ProcessGuid")
+	if guid == "" {
+		guid = sysmonString(data, prefix+"ProcessGUID")
+	}
+	pid := getInt(data, prefix+"ProcessId")
+	if image == "" && guid == "" && pid == 0 {
+		return nil
+	}
+	process := &objects.Process{
+		Uid:     guid,
+		Pid:     int(pid),
+		CmdLine: sysmonString(data, prefix+"CommandLine"),
+		File:    pathFile(image),
+		User:    sysmonUser(sysmonString(data, prefix+"User")),
+	}
+	if process.File != nil {
+		process.Name = process.File.Name
+	}
+	return process
+}
+
+// sysmonActor returns the process an event is attributed to, with its user.
+func sysmonActor(data map[string]interface{}, prefix string) *objects.Actor {
+	process := sysmonProcess(data, prefix)
+	if process == nil {
+		return nil
+	}
+	return &objects.Actor{Process: process, User: process.User}
+}
+
+// sysmonImageFile builds the file at an image path field with its hashes and
+// version information.
+func sysmonImageFile(data map[string]interface{}, field string) *objects.File {
+	file := pathFile(sysmonString(data, field))
+	if file == nil {
+		return nil
+	}
+	file.Hashes = sysmonHashes(sysmonString(data, "Hashes"))
+	file.CompanyName = sysmonString(data, "Company")
+	file.Version = sysmonString(data, "FileVersion")
+	file.Desc = sysmonString(data, "Description")
+	file.InternalName = sysmonString(data, "OriginalFileName")
+	if signer := sysmonString(data, "Signature"); signer != "" {
+		file.Signature = &objects.DigitalSignature{Certificate: &objects.Certificate{Subject: signer}}
+		if sysmonString(data, "SignatureStatus") == "Valid" {
+			file.Signature.State, file.Signature.StateId = "Valid", 1
+		}
+	}
+	return file
+}
+
+// sysmonHashes parses the Hashes field ("SHA1=...,MD5=...,SHA256=...,IMPHASH=...").
+func sysmonHashes(hashes string) []*objects.Fingerprint {
+	var fingerprints []*objects.Fingerprint
+	for _, pair := range strings.Split(hashes, ",") {
+		algorithm, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || value == "" {
+			continue
+		}
+		switch strings.ToUpper(algorithm) {
+		case "MD5":
+			fingerprints = append(fingerprints, fingerprint(1, "MD5", value))
+		case "SHA1":
+			fingerprints = append(fingerprints, fingerprint(2, "SHA-1", value))
+		case "SHA256":
+			fingerprints = append(fingerprints, fingerprint(3, "SHA-256", value))
+		default:
+			fingerprints = append(fingerprints, fingerprint(99, strings.ToUpper(algorithm), value))
+		}
+	}
+	return fingerprints
+}
+
+func sysmonProcessCreate(envelope *models.RawEventEnvelope, payload, data map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	event := &system.NewProcessActivity(system.ProcessActivityActivityLaunch).Event
+	initVendorEvent(event, envelope, payload, sysmonProduct, eventTime)
+
+	process := sysmonProcess(data, "")
+	if process == nil {
+		process = &objects.Process{}
+	}
+	process.File = sysmonImageFile(data, "Image")
+	process.WorkingDirectory = sysmonString(data, "CurrentDirectory")
+	process.Integrity = sysmonString(data, "IntegrityLevel")
+	process.CreatedTime = eventTime.UnixMilli()
+	if logonID := sysmonString(data, "LogonId"); logonID != "" {
+		process.Session = &objects.Session{Uid: logonID}
+	}
+	process.ParentProcess = sysmonProcess(data, "Parent")
+	event.Process = process
+
+	event.Actor = &objects.Actor{Process: process.ParentProcess, User: process.User}
+	return event
+}
+
+func sysmonNetworkConnection(envelope *models.RawEventEnvelope, payload, data map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	event := &network.NewNetworkActivity(network.NetworkActivityActivityOpen).Event
+	initVendorEvent(event, envelope, payload, sysmonProduct, eventTime)
+	event.Actor = sysmonActor(data, "")
+
+	event.SrcEndpoint = endpoint(sysmonString(data, "SourceIp"), getInt(data, "SourcePort"))
+	if event.SrcEndpoint != nil {
+		event.SrcEndpoint.Hostname = sysmonString(data, "SourceHostname")
+	}
+	event.DstEndpoint = endpoint(sysmonString(data, "DestinationIp"), getInt(data, "DestinationPort"))
+	if event.DstEndpoint != nil {
+		event.DstEndpoint.Hostname = sysmonString(data, "DestinationHostname")
+		event.DstEndpoint.SvcName = sysmonString(data, "DestinationPortName")
+	}
+
+	info := connectionInfo(sysmonString(data, "Protocol"), sysmonString(data, "SourceIp"), "", "")
+	if initiated := sysmonString(data, "Initiated"); initiated == "true" {
+		setDirection(info, true, false)
+	} else if initiated == "false" {
+		setDirection(info, false, true)
+	}
+	event.ClassAttributes["connection_info"] = info
+	return event
+}
+
+func sysmonRegistry(envelope *models.RawEventEnvelope, payload, data map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	eventType := sysmonString(data, "EventType")
+	mapping, ok := sysmonRegistryActivities[eventType]
+	if !ok {
+		return unsupportedVendorLog(envelope, payload, sysmonProduct, eventTime, "registry_"+eventType)
+	}
+
+	event := &ocsf.Event{
+		CategoryUID: ocsf.CategorySystemActivity,
+		ClassUID:    mapping.classUID,
+		ActivityID:  mapping.activityID,
+		TypeUID:     mapping.classUID*100 + mapping.activityID,
+		Category:    "system",
+		Class:       "registry_key_activity",
+		Metadata:    ocsf.Metadata{Version: "1.1.0"},
+	}
+	initVendorEvent(event, envelope, payload, sysmonProduct, eventTime)
+	event.Activity = mapping.activity
+	event.Actor = sysmonActor(data, "")
+
+	path := sysmonString(data, "TargetObject")
+	if mapping.classUID == classRegistryValueActivity {
+		event.Class = "registry_value_activity"
+		key, name := path, ""
+		if i := strings.LastIndex(path, `\`); i >= 0 {
+			key, name = path[:i], path[i+1:]
+		}
+		value := map[string]interface{}{"path": path, "name": name}
+		if details := sysmonString(data, "Details"); details != "" {
+			value["data"] = details
+		}
+		event.ClassAttributes["reg_value"] = value
+		event.ClassAttributes["reg_key"] = map[string]interface{}{"path": key}
+	} else {
+		event.ClassAttributes["reg_key"] = map[string]interface{}{"path": path}
+		if newName := sysmonString(data, "NewName"); newName != "" {
+			event.ClassAttributes["prev_reg_key"] = map[string]interface{}{"path": path}
+			event.ClassAttributes["reg_key"] = map[string]interface{}{"path": newName}
+		}
+	}
+	return event
+}
+
+func sysmonDNS(envelope *models.RawEventEnvelope, payload, data map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	event := &network.NewDnsActivity(network.DnsActivityActivityResponse).Event
+	initVendorEvent(event, envelope, payload, sysmonProduct, eventTime)
+	event.Actor = sysmonActor(data, "")
+	event.ClassAttributes["query"] = dnsQuery(sysmonString(data, "QueryName"), "", "", "")
+
+	// QueryStatus is a Windows error code; DNS_ERROR_RCODE_* codes are 9000
+	// plus the response code
+	status := getInt(data, "QueryStatus")
+	switch {
+	case status == 0:
+		event.ClassAttributes["rcode"], event.ClassAttributes["rcode_id"] = dnsRcode(0, "")
+		setStatus(event, ocsf.StatusSuccess, "")
+	case status > 9000 && status < 9000+int64(len(dnsRcodes)):
+		event.ClassAttributes["rcode"], event.ClassAttributes["rcode_id"] = dnsRcode(status-9000, "")
+		setStatus(event, ocsf.StatusFailure, strconv.FormatInt(status, 10))
+	default:
+		setStatus(event, ocsf.StatusFailure, strconv.FormatInt(status, 10))
+	}
+
+	// QueryResults lists CNAMEs as "type:  5 name" and addresses, which are
+	// IPv4-mapped for A records, separated by semicolons
+	var answers []*objects.DnsAnswer
+	for _, result := range strings.Split(sysmonString(data, "QueryResults"), ";") {
+		result = strings.TrimSpace(result)
+		if result == "" {
+			continue
+		}
+		answer := &objects.DnsAnswer{Rdata: strings.TrimPrefix(result, "::ffff:")}
+		if rest, ok := strings.CutPrefix(result, "type:"); ok {
+			fields := strings.Fields(rest)
+			if len(fields) < 2 {
+				continue
+			}
+			answer.Rdata = fields[1]
+			if fields[0] == "5" {
+				answer.Type = "CNAME"
+			}
+		}
+		answers = append(answers, answer)
+	}
+	if len(answers) > 0 {
+		event.ClassAttributes["answers"] = answers
+	}
+	return event
+}
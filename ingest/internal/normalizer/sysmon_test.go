@@ -0,0 +1,169 @@
+package normalizer
+
+import "testing"
+
+const sysmonSourceType = "XmlWinEventLog:Microsoft-Windows-Sysmon/Operational"
+
+func TestSysmonNormalizer_Supports(t *testing.T) {
+	n := SysmonNormalizer{}
+	for _, sourceType := range []string{"sysmon", "sysmon:linux", sysmonSourceType, "WinEventLog:microsoft-windows-sysmon/operational"} {
+		if !n.Supports("json", sourceType) {
+			t.Errorf("expected %s to be supported", sourceType)
+		}
+	}
+	if n.Supports("json", "WinEventLog:Security") {
+		t.Error("expected WinEventLog:Security to be unsupported")
+	}
+}
+
+func TestSysmonNormalizer_Fixture(t *testing.T) {
+	records := loadExport(t, "sysmon.ndjson")
+	if len(records) != 10 {
+		t.Fatalf("expected 10 fixture records, got %d", len(records))
+	}
+
+	tests := []struct {
+		name string
+		want map[string]interface{}
+	}{
+		{"process create", map[string]interface{}{
+			"class_uid":                       float64(1007),
+			"activity_id":                     float64(1),
+			"time":                            "2026-03-01T10:00:00.123Z",
+			"metadata.product.name":           "Sysmon",
+			"device.hostname":                 "WKS-01.corp.example.com",
+			"device.os.type_id":               float64(100),
+			"process.pid":                     float64(4242),
+			"process.uid":                     "{5770385f-c22a-43e0-bf4c-06f5698ffbd9}",
+			"process.cmd_line":                "powershell.exe -nop -w hidden -enc SQBFAFgA",
+			"process.file.name":               "powershell.exe",
+			"process.file.internal_name":      "PowerShell.EXE",
+			"process.integrity":               "High",
+			"process.user.name":               "alice",
+			"process.user.domain":             "CORP",
+			"process.session.uid":             "0x3e7",
+			"process.parent_process.pid":      float64(1000),
+			"process.parent_process.cmd_line": "cmd.exe /c run.bat",
+			"actor.process.file.path":         `C:\Windows\System32\cmd.exe`,
+			"attack_technique_uid":            "T1059.001",
+			"attack_technique":                "PowerShell",
+			"properties.event_id":             "1",
+		}},
+		{"network connection", map[string]interface{}{
+			"class_uid":                 float64(4001),
+			"activity_id":               float64(1),
+			"src_endpoint.ip":           "10.0.0.5",
+			"src_endpoint.port":         float64(51000),
+			"dst_endpoint.ip":           "203.0.113.50",
+			"dst_endpoint.port":         float64(443),
+			"connection_info.direction": "Outbound",
+			"actor.process.pid":         float64(4242),
+			"time":                      "2026-03-01T10:00:01Z",
+		}},
+		{"image loaded", map[string]interface{}{
+			"class_uid":                   float64(1005),
+			"module.file.path":            `C:\Windows\System32\amsi.dll`,
+			"module.file.signature.state": "Valid",
+			"device.hostname":             "WKS-01.corp.example.com",
+			"properties.event_id":         "7",
+		}},
+		{"create remote thread", map[string]interface{}{
+			"class_uid":            float64(1007),
+			"activity_id":          float64(4),
+			"actor.process.pid":    float64(4242),
+			"process.pid":          float64(672),
+			"process.user.name":    "SYSTEM",
+			"injection_type_id":    float64(1),
+			"attack_technique_uid": "T1055",
+		}},
+		{"process access", map[string]interface{}{
+			"class_uid":             float64(1007),
+			"activity_id":           float64(3),
+			"process.file.name":     "lsass.exe",
+			"requested_permissions": float64(0x1010),
+			"attack_technique_uid":  "T1003.001",
+		}},
+		{"file create", map[string]interface{}{
+			"class_uid":          float64(1001),
+			"activity_id":        float64(1),
+			"file.name":          "payload.exe",
+			"file.parent_folder": `C:\Users\alice\AppData\Local\Temp`,
+			"actor.user.name":    "alice",
+		}},
+		{"registry value set", map[string]interface{}{
+			"class_uid":            float64(201002),
+			"activity_id":          float64(2),
+			"reg_value.name":       "Updater",
+			"reg_value.data":       `C:\Users\alice\AppData\Local\Temp\payload.exe`,
+			"reg_key.path":         `HKU\S-1-5-21-1-2-3-1001\Software\Microsoft\Windows\CurrentVersion\Run`,
+			"attack_technique_uid": "T1547.001",
+		}},
+		{"registry key create", map[string]interface{}{
+			"class_uid":    float64(201001),
+			"activity_id":  float64(1),
+			"reg_key.path": `HKLM\SOFTWARE\Example`,
+		}},
+		{"dns query", map[string]interface{}{
+			"class_uid":      float64(4003),
+			"activity_id":    float64(2),
+			"query.hostname": "update.example.net",
+			"rcode_id":       float64(0),
+			"status_id":      float64(1),
+		}},
+		{"unmapped event", map[string]interface{}{
+			"class_uid":           float64(0),
+			"properties.log_name": "event_id_5",
+		}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := normalizeDocument(t, SysmonNormalizer{}, sysmonSourceType, records[i])
+			expectFields(t, doc, tt.want)
+		})
+	}
+}
+
+func TestSysmonNormalizer_ProcessHashes(t *testing.T) {
+	doc := normalizeDocument(t, SysmonNormalizer{}, sysmonSourceType, loadExport(t, "sysmon.ndjson")[0])
+
+	hashes, _ := field(doc, "process.file.hashes").([]interface{})
+	if len(hashes) != 4 {
+		t.Fatalf("expected 4 hashes, got %v", field(doc, "process.file.hashes"))
+	}
+	expectFields(t, hashes[2].(map[string]interface{}), map[string]interface{}{
+		"algorithm_id": float64(3),
+		"value":        "DE96A6E69944335375DC1AC238336066889D9FFC7D73628EF4FE1B1B160AB32C",
+	})
+}
+
+func TestSysmonNormalizer_DNSAnswers(t *testing.T) {
+	doc := normalizeDocument(t, SysmonNormalizer{}, sysmonSourceType, loadExport(t, "sysmon.ndjson")[8])
+
+	answers, _ := field(doc, "answers").([]interface{})
+	if len(answers) != 2 {
+		t.Fatalf("expected 2 answers, got %v", field(doc, "answers"))
+	}
+	expectFields(t, answers[0].(map[string]interface{}), map[string]interface{}{"type": "CNAME", "rdata": "cdn.example.net"})
+	expectFields(t, answers[1].(map[string]interface{}), map[string]interface{}{"rdata": "203.0.113.50"})
+}
+
+func TestTechniqueAttacks(t *testing.T) {
+	tests := map[string][2]string{
+		"technique_id=T1059.001,technique_name=PowerShell": {"T1059.001", "PowerShell"},
+		"T1087_Account_Discovery":                          {"T1087", "Account Discovery"},
+		"T1548.003":                                        {"T1548.003", ""},
+	}
+	for tag, want := range tests {
+		attacks := techniqueAttacks(tag)
+		if len(attacks) != 1 {
+			t.Errorf("%s: expected 1 attack, got %d", tag, len(attacks))
+			continue
+		}
+		if attacks[0].Technique.Uid != want[0] || attacks[0].Technique.Name != want[1] {
+			t.Errorf("%s: expected %v, got %+v", tag, want, attacks[0].Technique)
+		}
+	}
+	if attacks := techniqueAttacks("-"); attacks != nil {
+		t.Errorf("expected no attacks, got %v", attacks)
+	}
+}
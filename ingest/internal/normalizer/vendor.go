@@ -0,0 +1,262 @@
+package normalizer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/application"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/objects"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+// Shared helpers for the hand-written vendor normalizers, which build events
+// with the class constructors in common/ocsf/events and keep the attributes
+// ocsf.Event has no field for in ClassAttributes.
+
+// decodeVendorPayload decodes a JSON payload keeping numbers exact, since
+// IDs such as Suricata's flow_id exceed float64 precision. Events sent to the
+// HEC event endpoint arrive wrapped in the HEC envelope; the vendor record is
+// its "event", which may also be a JSON string.
+func decodeVendorPayload(data []byte) (map[string]interface{}, error) {
+	payload, err := decodeJSONObject(data)
+	if err != nil {
+		return nil, err
+	}
+	switch event := payload["event"].(type) {
+	case map[string]interface{}:
+		return event, nil
+	case string:
+		if record, err := decodeJSONObject([]byte(event)); err == nil {
+			return record, nil
+		}
+	}
+	return payload, nil
+}
+
+func decodeJSONObject(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var payload map[string]interface{}
+	if err := dec.Decode(&payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// initVendorEvent fills the base fields shared by every event a vendor
+// normalizer builds on top of a class constructor.
+func initVendorEvent(event *ocsf.Event, envelope *models.RawEventEnvelope, payload map[string]interface{}, product ocsf.Product, eventTime time.Time) {
+	event.Activity = ocsf.ActivityName(event.ClassUID, event.ActivityID)
+	if event.ActivityID == 99 {
+		event.Activity = "Other"
+	} else if event.ClassUID == ocsf.ClassDetectionFinding && event.ActivityID == 1 {
+		event.Activity = "Create"
+	}
+	if eventTime.IsZero() {
+		eventTime = envelope.ReceivedAt
+	}
+	event.Time = eventTime
+	event.ObservedTime = envelope.ReceivedAt
+	event.Metadata.Product = product
+	event.Raw = ocsf.RawDescriptor{Format: envelope.Format, Data: payload}
+	event.Properties = map[string]string{
+		"source":      envelope.Source,
+		"source_type": envelope.SourceType,
+	}
+	event.ClassAttributes = make(map[string]interface{})
+}
+
+// setSeverity sets the severity ID and its name.
+func setSeverity(event *ocsf.Event, severityID int) {
+	event.SeverityID = severityID
+	event.Severity = ocsf.SeverityName(severityID)
+}
+
+// lookup returns the value at a dotted path. Zeek writes keys such as
+// "id.orig_h" literally while shippers often nest them, so a literal key
+// wins over walking nested objects.
+func lookup(m map[string]interface{}, path string) interface{} {
+	if v, ok := m[path]; ok {
+		return v
+	}
+	head, rest, found := strings.Cut(path, ".")
+	if !found {
+		return nil
+	}
+	child, ok := m[head].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return lookup(child, rest)
+}
+
+func getString(m map[string]interface{}, path string) string {
+	switch v := lookup(m, path).(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+func getInt(m map[string]interface{}, path string) int64 {
+	return toInt(lookup(m, path))
+}
+
+func toInt(value interface{}) int64 {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return int64(f)
+		}
+	case float64:
+		return int64(v)
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return int64(f)
+		}
+	}
+	return 0
+}
+
+func getFloat(m map[string]interface{}, path string) (float64, bool) {
+	switch v := lookup(m, path).(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func getBool(m map[string]interface{}, path string) (value, ok bool) {
+	value, ok = lookup(m, path).(bool)
+	return value, ok
+}
+
+func getStrings(m map[string]interface{}, path string) []string {
+	items, _ := lookup(m, path).([]interface{})
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			out = append(out, v)
+		case json.Number:
+			out = append(out, v.String())
+		}
+	}
+	return out
+}
+
+func getMap(m map[string]interface{}, path string) map[string]interface{} {
+	child, _ := lookup(m, path).(map[string]interface{})
+	return child
+}
+
+// epochTime converts fractional epoch seconds to a time.
+func epochTime(seconds float64) time.Time {
+	whole := int64(seconds)
+	return time.Unix(whole, int64((seconds-float64(whole))*1e9)).UTC()
+}
+
+// hoistAttacks copies attacks[0] to the root level attack fields, as
+// required by the package documentation.
+func hoistAttacks(event *ocsf.Event, attacks []*objects.Attack) {
+	if len(attacks) == 0 {
+		return
+	}
+	if attacks[0].Tactic != nil {
+		event.AttackTactic = attacks[0].Tactic.Name
+		event.AttackTacticUID = attacks[0].Tactic.Uid
+	}
+	if attacks[0].Technique != nil {
+		event.AttackTechnique = attacks[0].Technique.Name
+		event.AttackTechniqueUID = attacks[0].Technique.Uid
+	}
+}
+
+// unsupportedVendorLog falls back to the generic HEC mapping for vendor logs
+// that have no class mapping, so they are still stored and searchable.
+func unsupportedVendorLog(envelope *models.RawEventEnvelope, payload map[string]interface{}, product ocsf.Product, eventTime time.Time, logName string) *ocsf.Event {
+	event := &ocsf.Event{
+		CategoryUID: ocsf.CategoryOther,
+		TypeUID:     ocsf.ComputeTypeUID(ocsf.CategoryOther, 0, 0),
+		SeverityID:  ocsf.SeverityUnknown,
+		Class:       "base_event",
+		Category:    "other",
+		Severity:    "Unknown",
+		Status:      "Unknown",
+		Metadata:    ocsf.Metadata{Version: "1.1.0"},
+	}
+	initVendorEvent(event, envelope, payload, product, eventTime)
+	event.Activity = fmt.Sprintf("ingest:%s", envelope.SourceType)
+	event.Properties["log_name"] = logName
+	event.ClassAttributes = nil
+	return event
+}
+
+// setStatus sets the status ID and name, and the status detail if any.
+func setStatus(event *ocsf.Event, statusID int, detail string) {
+	event.StatusID = statusID
+	event.Status = ocsf.StatusName(statusID)
+	if detail != "" {
+		event.ClassAttributes["status_detail"] = detail
+	}
+}
+
+// resource builds an OCSF resource_details object. objects.ResourceDetails
+// has no uid or type, which rules match resources on, so a map is used
+// instead.
+func resource(uid, name, resourceType string) map[string]interface{} {
+	r := map[string]interface{}{"type": resourceType}
+	if uid != "" {
+		r["uid"] = uid
+	}
+	if name != "" {
+		r["name"] = name
+	}
+	return r
+}
+
+// apiVerbs maps the leading verb of an API operation to an API Activity
+// activity, checked in order.
+var apiVerbs = []struct {
+	activity int
+	prefixes []string
+}{
+	{application.ApiActivityActivityRead, []string{"get", "list", "describe", "lookup", "head", "search", "read", "access", "view", "download", "clone", "batchget"}},
+	{application.ApiActivityActivityCreate, []string{"create", "add", "run", "allocate", "import", "register", "generate", "copy"}},
+	{application.ApiActivityActivityDelete, []string{"delete", "remove", "destroy", "terminate", "deregister", "release", "purge"}},
+	{application.ApiActivityActivityUpdate, []string{"update", "modify", "put", "set", "attach", "detach", "enable", "disable", "change", "associate", "disassociate", "start", "stop", "reboot", "rename", "transfer", "tag", "untag", "edit", "grant", "revoke", "activate", "deactivate", "reset", "restore", "archive", "unarchive"}},
+}
+
+// apiActivityID classifies an operation such as "DescribeInstances" or
+// "destroy" by its leading verb, falling back to Other.
+func apiActivityID(operation string) int {
+	op := strings.ToLower(operation)
+	for _, verb := range apiVerbs {
+		for _, prefix := range verb.prefixes {
+			if strings.HasPrefix(op, prefix) {
+				return verb.activity
+			}
+		}
+	}
+	return 99
+}
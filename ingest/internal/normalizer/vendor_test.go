@@ -0,0 +1,68 @@
+package normalizer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/application"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/exports"
+)
+
+// loadExport reads the records of a sample export in testdata.
+func loadExport(t *testing.T, name string) []string {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("open fixture: %v", err)
+	}
+	defer f.Close()
+
+	var records []string
+	if err := exports.Read(f, func(r exports.Record) error {
+		records = append(records, string(r.Event))
+		return nil
+	}); err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return records
+}
+
+func TestDecodeVendorPayload_HECEnvelope(t *testing.T) {
+	tests := map[string]string{
+		"bare":         `{"eventName": "CreateUser"}`,
+		"hec event":    `{"time": 1772359200, "sourcetype": "aws:cloudtrail", "event": {"eventName": "CreateUser"}}`,
+		"string event": `{"time": 1772359200, "event": "{\"eventName\": \"CreateUser\"}"}`,
+	}
+	for name, payload := range tests {
+		t.Run(name, func(t *testing.T) {
+			record, err := decodeVendorPayload([]byte(payload))
+			if err != nil {
+				t.Fatalf("decode failed: %v", err)
+			}
+			if getString(record, "eventName") != "CreateUser" {
+				t.Errorf("expected the vendor record, got %v", record)
+			}
+		})
+	}
+
+	doc := normalizeDocument(t, CloudTrailNormalizer{}, "aws:cloudtrail",
+		`{"sourcetype": "aws:cloudtrail", "event": {"eventName": "ListBuckets", "eventSource": "s3.amazonaws.com", "readOnly": true}}`)
+	expectFields(t, doc, map[string]interface{}{"class_uid": float64(6003), "api.operation": "ListBuckets"})
+}
+
+func TestAPIActivityID(t *testing.T) {
+	tests := map[string]int{
+		"DescribeInstances":  application.ApiActivityActivityRead,
+		"RunInstances":       application.ApiActivityActivityCreate,
+		"TerminateInstances": application.ApiActivityActivityDelete,
+		"PutBucketPolicy":    application.ApiActivityActivityUpdate,
+		"destroy":            application.ApiActivityActivityDelete,
+		"AssumeRole":         99,
+	}
+	for operation, want := range tests {
+		if got := apiActivityID(operation); got != want {
+			t.Errorf("apiActivityID(%q) = %d, want %d", operation, got, want)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package normalizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/findings"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/network"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/objects"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+var zeekProduct = ocsf.Product{Name: "Zeek", Vendor: "Zeek"}
+
+// ZeekNormalizer converts Zeek JSON logs into OCSF events: conn to Network
+// Activity, dns to DNS Activity, http to HTTP Activity, ssl to Network
+// Activity with TLS details, files to Network File Activity and notice to
+// Detection Finding. Other logs are stored as base events.
+//
+// The log is taken from the sourcetype ("zeek:conn") or, for the plain
+// "zeek" sourcetype, from the _path field Zeek adds to each record.
+type ZeekNormalizer struct{}
+
+// Supports matches JSON payloads with sourcetype zeek or zeek:<log>.
+func (ZeekNormalizer) Supports(format, sourceType string) bool {
+	return format == "json" && (sourceType == "zeek" || strings.HasPrefix(sourceType, "zeek:"))
+}
+
+// Normalize maps a Zeek log record according to its log.
+func (ZeekNormalizer) Normalize(ctx context.Context, envelope *models.RawEventEnvelope) (*ocsf.Event, error) {
+	_ = ctx
+	payload, err := decodeVendorPayload(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode zeek payload: %w", err)
+	}
+
+	logName := strings.TrimPrefix(envelope.SourceType, "zeek:")
+	if envelope.SourceType == "zeek" {
+		logName = getString(payload, "_path")
+	}
+	eventTime := zeekTime(payload)
+
+	var event *ocsf.Event
+	switch logName {
+	case "conn":
+		event = zeekConn(envelope, payload, eventTime)
+	case "dns":
+		event = zeekDNS(envelope, payload, eventTime)
+	case "http":
+		event = zeekHTTP(envelope, payload, eventTime)
+	case "ssl":
+		event = zeekSSL(envelope, payload, eventTime)
+	case "files":
+		event = zeekFiles(envelope, payload, eventTime)
+	case "notice":
+		event = zeekNotice(envelope, payload, eventTime)
+	default:
+		return unsupportedVendorLog(envelope, payload, zeekProduct, eventTime, logName), nil
+	}
+	event.Properties["log_name"] = logName
+	return event, nil
+}
+
+// zeekTime parses ts, written as epoch seconds or, with
+// LogAscii::json_timestamps set, as ISO 8601.
+func zeekTime(payload map[string]interface{}) time.Time {
+	if seconds, ok := getFloat(payload, "ts"); ok {
+		return epochTime(seconds)
+	}
+	if t, err := time.Parse(time.RFC3339Nano, getString(payload, "ts")); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// zeekEndpoints returns the endpoints of the connection a record belongs to.
+func zeekEndpoints(event *ocsf.Event, payload map[string]interface{}) {
+	event.SrcEndpoint = endpoint(getString(payload, "id.orig_h"), getInt(payload, "id.orig_p"))
+	event.DstEndpoint = endpoint(getString(payload, "id.resp_h"), getInt(payload, "id.resp_p"))
+}
+
+// zeekConnectionInfo builds connection_info from the fields every
+// connection-oriented log shares. Logs of TCP-only protocols such as http
+// and ssl have no proto field, so they pass defaultProto.
+func zeekConnectionInfo(payload map[string]interface{}, defaultProto string) *objects.NetworkConnectionInfo {
+	proto := getString(payload, "proto")
+	if proto == "" {
+		proto = defaultProto
+	}
+	info := connectionInfo(proto, getString(payload, "id.orig_h"), getString(payload, "community_id"), getString(payload, "uid"))
+	localOrig, _ := getBool(payload, "local_orig")
+	localResp, _ := getBool(payload, "local_resp")
+	setDirection(info, localOrig, localResp)
+	return info
+}
+
+// zeekConnActivity maps conn_state to a Network Activity activity.
+func zeekConnActivity(state string) int {
+	switch {
+	case state == "REJ":
+		return network.NetworkActivityActivityRefuse
+	case strings.HasPrefix(state, "RST"):
+		return network.NetworkActivityActivityReset
+	case state == "S0":
+		return network.NetworkActivityActivityFail
+	case state == "SF":
+		return network.NetworkActivityActivityClose
+	default:
+		return network.NetworkActivityActivityTraffic
+	}
+}
+
+func zeekConn(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	event := &network.NewNetworkActivity(zeekConnActivity(getString(payload, "conn_state"))).Event
+	initVendorEvent(event, envelope, payload, zeekProduct, eventTime)
+	setSeverity(event, ocsf.SeverityInformational)
+	zeekEndpoints(event, payload)
+	if event.DstEndpoint != nil {
+		event.DstEndpoint.SvcName = getString(payload, "service")
+	}
+
+	info := zeekConnectionInfo(payload, "")
+	info.FlagHistory = getString(payload, "history")
+	event.ClassAttributes["connection_info"] = info
+	if t := traffic(getInt(payload, "orig_bytes"), getInt(payload, "resp_bytes"), getInt(payload, "orig_pkts"), getInt(payload, "resp_pkts")); t != nil {
+		t.BytesMissed = getInt(payload, "missed_bytes")
+		event.ClassAttributes["traffic"] = t
+	}
+	if duration, ok := durationMillis(payload, "duration"); ok {
+		event.ClassAttributes["duration"] = duration
+	}
+	return event
+}
+
+func zeekDNS(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	answers := getStrings(payload, "answers")
+	_, hasRcode := payload["rcode"]
+	activity := network.DnsActivityActivityQuery
+	if hasRcode || len(answers) > 0 {
+		activity = network.DnsActivityActivityResponse
+	}
+
+	event := &network.NewDnsActivity(activity).Event
+	initVendorEvent(event, envelope, payload, zeekProduct, eventTime)
+	setSeverity(event, ocsf.SeverityInformational)
+	zeekEndpoints(event, payload)
+
+	event.ClassAttributes["connection_info"] = zeekConnectionInfo(payload, "")
+	event.ClassAttributes["query"] = dnsQuery(getString(payload, "query"), getString(payload, "qtype_name"), getString(payload, "qclass_name"), getString(payload, "trans_id"))
+	if len(answers) > 0 {
+		ttls, _ := lookup(payload, "TTLs").([]interface{})
+		dnsAnswers := make([]*objects.DnsAnswer, len(answers))
+		for i, rdata := range answers {
+			dnsAnswers[i] = &objects.DnsAnswer{Rdata: rdata}
+			if i < len(ttls) {
+				dnsAnswers[i].Ttl = int(toInt(ttls[i]))
+			}
+		}
+		event.ClassAttributes["answers"] = dnsAnswers
+	}
+	if hasRcode {
+		rcode, rcodeID := dnsRcode(getInt(payload, "rcode"), getString(payload, "rcode_name"))
+		event.ClassAttributes["rcode"] = rcode
+		event.ClassAttributes["rcode_id"] = rcodeID
+	}
+	return event
+}
+
+func zeekHTTP(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	method := getString(payload, "method")
+	event := &network.NewHttpActivity(httpActivityID(method)).Event
+	initVendorEvent(event, envelope, payload, zeekProduct, eventTime)
+	setSeverity(event, ocsf.SeverityInformational)
+	zeekEndpoints(event, payload)
+
+	host := getString(payload, "host")
+	if event.DstEndpoint != nil {
+		event.DstEndpoint.Hostname = host
+	}
+	event.ClassAttributes["connection_info"] = zeekConnectionInfo(payload, "tcp")
+	event.ClassAttributes["http_request"] = &objects.HttpRequest{
+		HttpMethod: method,
+		Url:        httpURL(host, getString(payload, "uri"), getInt(payload, "id.resp_p")),
+		UserAgent:  getString(payload, "user_agent"),
+		Referrer:   getString(payload, "referrer"),
+		Version:    getString(payload, "version"),
+		Length:     int(getInt(payload, "request_body_len")),
+	}
+	if code := getInt(payload, "status_code"); code != 0 {
+		response := &objects.HttpResponse{
+			Code:    int(code),
+			Message: getString(payload, "status_msg"),
+			Length:  int(getInt(payload, "response_body_len")),
+		}
+		if mimeTypes := getStrings(payload, "resp_mime_types"); len(mimeTypes) > 0 {
+			response.ContentType = mimeTypes[0]
+		}
+		event.ClassAttributes["http_response"] = response
+		event.ClassAttributes["http_status"] = code
+	}
+	return event
+}
+
+func zeekSSL(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	activity := network.NetworkActivityActivityTraffic
+	if established, ok := getBool(payload, "established"); ok {
+		activity = network.NetworkActivityActivityFail
+		if established {
+			activity = network.NetworkActivityActivityOpen
+		}
+	}
+
+	event := &network.NewNetworkActivity(activity).Event
+	initVendorEvent(event, envelope, payload, zeekProduct, eventTime)
+	setSeverity(event, ocsf.SeverityInformational)
+	zeekEndpoints(event, payload)
+
+	sni := getString(payload, "server_name")
+	if event.DstEndpoint != nil {
+		event.DstEndpoint.Hostname = sni
+		event.DstEndpoint.SvcName = "ssl"
+	}
+	tls := &objects.Tls{
+		Version:  tlsVersion(getString(payload, "version")),
+		Cipher:   getString(payload, "cipher"),
+		Sni:      sni,
+		Ja3Hash:  fingerprint(1, "MD5", getString(payload, "ja3")),
+		Ja3sHash: fingerprint(1, "MD5", getString(payload, "ja3s")),
+	}
+	if subject, issuer := getString(payload, "subject"), getString(payload, "issuer"); subject != "" || issuer != "" {
+		tls.Certificate = &objects.Certificate{Subject: subject, Issuer: issuer}
+	}
+	event.ClassAttributes["connection_info"] = zeekConnectionInfo(payload, "tcp")
+	event.ClassAttributes["tls"] = tls
+	return event
+}
+
+func zeekFiles(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	// is_orig is set when the connection originator sent the file
+	isOrig, _ := getBool(payload, "is_orig")
+	activity := network.NetworkFileActivityActivityDownload
+	if isOrig {
+		activity = network.NetworkFileActivityActivityUpload
+	}
+
+	event := &network.NewNetworkFileActivity(activity).Event
+	initVendorEvent(event, envelope, payload, zeekProduct, eventTime)
+	setSeverity(event, ocsf.SeverityInformational)
+	zeekEndpoints(event, payload)
+	if event.SrcEndpoint == nil {
+		// Zeek before 5.0 logs the hosts that sent and received the file
+		// rather than the connection
+		senders, receivers := getStrings(payload, "tx_hosts"), getStrings(payload, "rx_hosts")
+		if !isOrig {
+			senders, receivers = receivers, senders
+		}
+		if len(senders) > 0 {
+			event.SrcEndpoint = endpoint(senders[0], 0)
+		}
+		if len(receivers) > 0 {
+			event.DstEndpoint = endpoint(receivers[0], 0)
+		}
+	}
+
+	size := getInt(payload, "total_bytes")
+	if size == 0 {
+		size = getInt(payload, "seen_bytes")
+	}
+	event.File = &objects.File{
+		Name:     getString(payload, "filename"),
+		Uid:      getString(payload, "fuid"),
+		MimeType: getString(payload, "mime_type"),
+		Size:     size,
+		Hashes:   fileHashes(payload, "md5", "sha1", "sha256"),
+		TypeId:   1,
+		Type:     "Regular File",
+	}
+
+	uid := getString(payload, "uid")
+	if uids := getStrings(payload, "conn_uids"); uid == "" && len(uids) > 0 {
+		uid = uids[0]
+	}
+	info := zeekConnectionInfo(payload, "")
+	info.Uid = uid
+	event.ClassAttributes["connection_info"] = info
+	return event
+}
+
+func zeekNotice(envelope *models.RawEventEnvelope, payload map[string]interface{}, eventTime time.Time) *ocsf.Event {
+	event := &findings.NewDetectionFinding(1).Event
+	initVendorEvent(event, envelope, payload, zeekProduct, eventTime)
+	// Zeek notices carry no severity of their own
+	setSeverity(event, ocsf.SeverityMedium)
+
+	src := getString(payload, "src")
+	if src == "" {
+		src = getString(payload, "id.orig_h")
+	}
+	dst := getString(payload, "dst")
+	if dst == "" {
+		dst = getString(payload, "id.resp_h")
+	}
+	port := getInt(payload, "p")
+	if port == 0 {
+		port = getInt(payload, "id.resp_p")
+	}
+	event.SrcEndpoint = endpoint(src, getInt(payload, "id.orig_p"))
+	event.DstEndpoint = endpoint(dst, port)
+
+	note := getString(payload, "note")
+	uid := getString(payload, "uid")
+	if uid == "" {
+		uid = getString(payload, "fuid")
+	}
+	event.ClassAttributes["finding_info"] = &objects.FindingInfo{
+		Uid:   uid,
+		Title: note,
+		Desc:  getString(payload, "msg"),
+		Types: []string{"Zeek Notice"},
+		Analytic: &objects.Analytic{
+			Name:   note,
+			Uid:    note,
+			Type:   "Rule",
+			TypeId: 1,
+			Desc:   getString(payload, "sub"),
+		},
+	}
+	event.ClassAttributes["is_alert"] = true
+	if uid != "" {
+		event.ClassAttributes["connection_info"] = zeekConnectionInfo(payload, "")
+	}
+	return event
+}
@@ -0,0 +1,188 @@
+package normalizer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+)
+
+// normalizeDocument normalizes a payload and returns the stored document.
+func normalizeDocument(t *testing.T, n Normalizer, sourceType, payload string) map[string]interface{} {
+	t.Helper()
+	if !n.Supports("json", sourceType) {
+		t.Fatalf("sourcetype %s not supported", sourceType)
+	}
+	event, err := n.Normalize(context.Background(), &models.RawEventEnvelope{
+		Format:     "json",
+		SourceType: sourceType,
+		Source:     "sensor1",
+		Payload:    []byte(payload),
+		ReceivedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	doc, err := event.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	return doc
+}
+
+// field returns the document value at a dotted path.
+func field(doc map[string]interface{}, path string) interface{} {
+	return lookup(doc, path)
+}
+
+func expectFields(t *testing.T, doc map[string]interface{}, want map[string]interface{}) {
+	t.Helper()
+	for path, value := range want {
+		if got := field(doc, path); got != value {
+			t.Errorf("%s: expected %v (%T), got %v (%T)", path, value, value, got, got)
+		}
+	}
+}
+
+func TestZeekNormalizer_Conn(t *testing.T) {
+	doc := normalizeDocument(t, ZeekNormalizer{}, "zeek", `{
+		"_path": "conn", "ts": 1772359200.5, "uid": "CHhAvVGS1DHFjwGM9",
+		"id.orig_h": "10.0.0.5", "id.orig_p": 51234, "id.resp_h": "93.184.216.34", "id.resp_p": 443,
+		"proto": "tcp", "service": "ssl", "duration": 1.25, "orig_bytes": 512, "resp_bytes": 4096,
+		"conn_state": "SF", "local_orig": true, "local_resp": false, "history": "ShADadFf",
+		"orig_pkts": 6, "resp_pkts": 8, "community_id": "1:LQU9qZlK+B5F3KDmev6m5PMibrg="
+	}`)
+
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":                       float64(4001),
+		"activity_id":                     float64(2),
+		"activity":                        "Close",
+		"time":                            "2026-03-01T10:00:00.5Z",
+		"metadata.product.name":           "Zeek",
+		"src_endpoint.ip":                 "10.0.0.5",
+		"src_endpoint.port":               float64(51234),
+		"dst_endpoint.ip":                 "93.184.216.34",
+		"dst_endpoint.port":               float64(443),
+		"dst_endpoint.svc_name":           "ssl",
+		"connection_info.protocol_name":   "tcp",
+		"connection_info.protocol_num":    float64(6),
+		"connection_info.protocol_ver_id": float64(4),
+		"connection_info.community_uid":   "1:LQU9qZlK+B5F3KDmev6m5PMibrg=",
+		"connection_info.uid":             "CHhAvVGS1DHFjwGM9",
+		"connection_info.direction":       "Outbound",
+		"traffic.bytes_out":               float64(512),
+		"traffic.bytes_in":                float64(4096),
+		"traffic.bytes":                   float64(4608),
+		"traffic.packets":                 float64(14),
+		"duration":                        float64(1250),
+		"properties.log_name":             "conn",
+	})
+}
+
+func TestZeekNormalizer_DNS(t *testing.T) {
+	doc := normalizeDocument(t, ZeekNormalizer{}, "zeek:dns", `{
+		"ts": 1772359200, "uid": "C1", "id.orig_h": "10.0.0.5", "id.orig_p": 5353,
+		"id.resp_h": "10.0.0.1", "id.resp_p": 53, "proto": "udp", "trans_id": 4242,
+		"query": "example.com", "qclass_name": "C_INTERNET", "qtype_name": "A",
+		"rcode": 0, "rcode_name": "NOERROR", "answers": ["93.184.216.34"], "TTLs": [300.0]
+	}`)
+
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":                    float64(4003),
+		"activity":                     "Response",
+		"query.hostname":               "example.com",
+		"query.type":                   "A",
+		"query.packet_uid":             "4242",
+		"rcode":                        "NoError",
+		"rcode_id":                     float64(0),
+		"connection_info.protocol_num": float64(17),
+		"dst_endpoint.port":            float64(53),
+	})
+	answers, _ := doc["answers"].([]interface{})
+	if len(answers) != 1 || field(answers[0].(map[string]interface{}), "rdata") != "93.184.216.34" || field(answers[0].(map[string]interface{}), "ttl") != float64(300) {
+		t.Errorf("unexpected answers: %v", doc["answers"])
+	}
+}
+
+func TestZeekNormalizer_HTTP(t *testing.T) {
+	doc := normalizeDocument(t, ZeekNormalizer{}, "zeek:http", `{
+		"ts": 1772359200, "uid": "C2", "id.orig_h": "10.0.0.5", "id.orig_p": 51000,
+		"id.resp_h": "93.184.216.34", "id.resp_p": 80, "method": "GET", "host": "example.com",
+		"uri": "/index.html?q=1", "user_agent": "curl/8.0", "status_code": 200, "status_msg": "OK",
+		"response_body_len": 1256, "resp_mime_types": ["text/html"]
+	}`)
+
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":                     float64(4002),
+		"activity":                      "Get",
+		"http_request.http_method":      "GET",
+		"http_request.url.hostname":     "example.com",
+		"http_request.url.path":         "/index.html",
+		"http_request.url.query_string": "q=1",
+		"http_request.url.url_string":   "http://example.com/index.html?q=1",
+		"http_request.user_agent":       "curl/8.0",
+		"http_response.code":            float64(200),
+		"http_response.content_type":    "text/html",
+		"http_status":                   float64(200),
+		"connection_info.protocol_name": "tcp",
+	})
+}
+
+func TestZeekNormalizer_SSL(t *testing.T) {
+	doc := normalizeDocument(t, ZeekNormalizer{}, "zeek:ssl", `{
+		"ts": 1772359200, "uid": "C3", "id.orig_h": "10.0.0.5", "id.orig_p": 51234,
+		"id.resp_h": "93.184.216.34", "id.resp_p": 443, "version": "TLSv13",
+		"cipher": "TLS_AES_128_GCM_SHA256", "server_name": "example.com", "established": true,
+		"ja3": "771,4865-4866,0-23,29-23,0"
+	}`)
+
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":             float64(4001),
+		"activity":              "Open",
+		"tls.version":           "1.3",
+		"tls.sni":               "example.com",
+		"tls.cipher":            "TLS_AES_128_GCM_SHA256",
+		"tls.ja3_hash.value":    "771,4865-4866,0-23,29-23,0",
+		"dst_endpoint.hostname": "example.com",
+	})
+}
+
+func TestZeekNormalizer_FilesAndNotice(t *testing.T) {
+	doc := normalizeDocument(t, ZeekNormalizer{}, "zeek:files", `{
+		"ts": 1772359200, "fuid": "F1", "tx_hosts": ["93.184.216.34"], "rx_hosts": ["10.0.0.5"],
+		"conn_uids": ["C4"], "is_orig": false, "mime_type": "application/x-dosexec",
+		"filename": "setup.exe", "total_bytes": 1024, "md5": "d41d8cd98f00b204e9800998ecf8427e"
+	}`)
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":           float64(4010),
+		"activity":            "Download",
+		"file.name":           "setup.exe",
+		"file.size":           float64(1024),
+		"src_endpoint.ip":     "10.0.0.5",
+		"dst_endpoint.ip":     "93.184.216.34",
+		"connection_info.uid": "C4",
+	})
+
+	doc = normalizeDocument(t, ZeekNormalizer{}, "zeek:notice", `{
+		"ts": 1772359200, "note": "Scan::Port_Scan", "msg": "10.0.0.9 scanned at least 15 unique ports",
+		"src": "10.0.0.9", "dst": "10.0.0.5", "p": 22
+	}`)
+	expectFields(t, doc, map[string]interface{}{
+		"class_uid":          float64(2004),
+		"activity":           "Create",
+		"finding_info.title": "Scan::Port_Scan",
+		"is_alert":           true,
+		"src_endpoint.ip":    "10.0.0.9",
+		"dst_endpoint.port":  float64(22),
+	})
+}
+
+func TestZeekNormalizer_UnsupportedLog(t *testing.T) {
+	doc := normalizeDocument(t, ZeekNormalizer{}, "zeek", `{"_path": "weird", "ts": 1772359200, "name": "bad_TCP_checksum"}`)
+	expectFields(t, doc, map[string]interface{}{
+		"class":                 "base_event",
+		"metadata.product.name": "Zeek",
+		"properties.log_name":   "weird",
+	})
+}
@@ -0,0 +1,262 @@
+// Package otlp decodes OTLP/HTTP log exports and flattens them into records
+// the ingest pipeline can normalize.
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strconv"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Supported OTLP/HTTP content types.
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+// DefaultRouteAttribute is the log attribute whose value selects the sourcetype.
+const DefaultRouteAttribute = "event.domain"
+
+// DefaultSourceType is used when a record has no routing attribute.
+const DefaultSourceType = "otlp"
+
+// ErrUnsupportedContentType is returned for requests that are neither protobuf nor JSON.
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// LogRecord is a single OTLP log record flattened for the ingest pipeline.
+type LogRecord struct {
+	Timestamp  time.Time
+	Host       string                 // resource host.name
+	Source     string                 // resource service.name, falling back to the scope name
+	SourceType string                 // value of the routing attribute, or the default sourcetype
+	Payload    map[string]interface{} // body, record attributes and severity/trace fields
+	Attributes map[string]string      // resource.* and scope.* envelope attributes
+}
+
+// Converter maps OTLP log exports to LogRecords.
+type Converter struct {
+	// RouteAttribute names the record (or resource) attribute whose value is
+	// used as the sourcetype, which selects the normalizer.
+	RouteAttribute string
+	// DefaultSourceType is used when the routing attribute is absent.
+	DefaultSourceType string
+}
+
+// NewConverter creates a converter, applying defaults for empty settings.
+func NewConverter(routeAttribute, defaultSourceType string) *Converter {
+	if routeAttribute == "" {
+		routeAttribute = DefaultRouteAttribute
+	}
+	if defaultSourceType == "" {
+		defaultSourceType = DefaultSourceType
+	}
+	return &Converter{
+		RouteAttribute:    routeAttribute,
+		DefaultSourceType: defaultSourceType,
+	}
+}
+
+// MediaType returns the media type of a Content-Type header without parameters.
+func MediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mediaType
+}
+
+// DecodeLogs parses an ExportLogsServiceRequest encoded as protobuf or JSON.
+func DecodeLogs(body []byte, contentType string) (*collogspb.ExportLogsServiceRequest, error) {
+	req := &collogspb.ExportLogsServiceRequest{}
+	switch MediaType(contentType) {
+	case ContentTypeProtobuf:
+		if err := proto.Unmarshal(body, req); err != nil {
+			return nil, fmt.Errorf("decode protobuf: %w", err)
+		}
+	case ContentTypeJSON:
+		if err := protojson.Unmarshal(body, req); err != nil {
+			return nil, fmt.Errorf("decode json: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+	return req, nil
+}
+
+// EncodeResponse marshals a response message in the request's content type.
+func EncodeResponse(msg proto.Message, contentType string) ([]byte, error) {
+	if MediaType(contentType) == ContentTypeJSON {
+		return protojson.Marshal(msg)
+	}
+	return proto.Marshal(msg)
+}
+
+// Convert flattens every log record in the request.
+func (c *Converter) Convert(req *collogspb.ExportLogsServiceRequest) []LogRecord {
+	var records []LogRecord
+	for _, rl := range req.GetResourceLogs() {
+		resourceAttrs := rl.GetResource().GetAttributes()
+		for _, sl := range rl.GetScopeLogs() {
+			scope := sl.GetScope()
+			envelopeAttrs := make(map[string]string, len(resourceAttrs)+len(scope.GetAttributes())+2)
+			for _, kv := range resourceAttrs {
+				envelopeAttrs["resource."+kv.GetKey()] = stringValue(kv.GetValue())
+			}
+			if scope.GetName() != "" {
+				envelopeAttrs["scope.name"] = scope.GetName()
+			}
+			if scope.GetVersion() != "" {
+				envelopeAttrs["scope.version"] = scope.GetVersion()
+			}
+			for _, kv := range scope.GetAttributes() {
+				envelopeAttrs["scope."+kv.GetKey()] = stringValue(kv.GetValue())
+			}
+
+			source := lookup(resourceAttrs, "service.name")
+			if source == "" {
+				source = scope.GetName()
+			}
+			host := lookup(resourceAttrs, "host.name")
+
+			for _, lr := range sl.GetLogRecords() {
+				records = append(records, c.convertRecord(lr, resourceAttrs, envelopeAttrs, host, source))
+			}
+		}
+	}
+	return records
+}
+
+func (c *Converter) convertRecord(lr *logspb.LogRecord, resourceAttrs []*commonpb.KeyValue, envelopeAttrs map[string]string, host, source string) LogRecord {
+	timestamp := time.Now()
+	if lr.GetTimeUnixNano() > 0 {
+		timestamp = time.Unix(0, int64(lr.GetTimeUnixNano()))
+	} else if lr.GetObservedTimeUnixNano() > 0 {
+		timestamp = time.Unix(0, int64(lr.GetObservedTimeUnixNano()))
+	}
+
+	// Structured bodies become top-level fields; anything else is the message
+	payload := make(map[string]interface{})
+	if body, ok := anyValue(lr.GetBody()).(map[string]interface{}); ok {
+		for k, v := range body {
+			payload[k] = v
+		}
+	} else if lr.GetBody() != nil {
+		payload["message"] = anyValue(lr.GetBody())
+	}
+	for _, kv := range lr.GetAttributes() {
+		payload[kv.GetKey()] = anyValue(kv.GetValue())
+	}
+
+	payload["time"] = float64(timestamp.UnixNano()) / 1e9
+	if lr.GetSeverityText() != "" {
+		payload["severity_text"] = lr.GetSeverityText()
+	}
+	if lr.GetSeverityNumber() != logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED {
+		payload["severity_number"] = int(lr.GetSeverityNumber())
+	}
+	if len(lr.GetTraceId()) > 0 {
+		payload["trace_id"] = hex.EncodeToString(lr.GetTraceId())
+	}
+	if len(lr.GetSpanId()) > 0 {
+		payload["span_id"] = hex.EncodeToString(lr.GetSpanId())
+	}
+
+	// Record attributes take precedence over resource attributes for routing
+	sourceType := lookup(lr.GetAttributes(), c.RouteAttribute)
+	if sourceType == "" {
+		sourceType = lookup(resourceAttrs, c.RouteAttribute)
+	}
+	if sourceType == "" {
+		sourceType = c.DefaultSourceType
+	}
+
+	attrs := make(map[string]string, len(envelopeAttrs))
+	for k, v := range envelopeAttrs {
+		attrs[k] = v
+	}
+
+	return LogRecord{
+		Timestamp:  timestamp,
+		Host:       host,
+		Source:     source,
+		SourceType: sourceType,
+		Payload:    payload,
+		Attributes: attrs,
+	}
+}
+
+func lookup(attrs []*commonpb.KeyValue, key string) string {
+	for _, kv := range attrs {
+		if kv.GetKey() == key {
+			return stringValue(kv.GetValue())
+		}
+	}
+	return ""
+}
+
+// anyValue converts an OTLP AnyValue into plain Go values for JSON encoding.
+func anyValue(v *commonpb.AnyValue) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch val := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return val.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return val.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return val.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return val.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(val.BytesValue)
+	case *commonpb.AnyValue_ArrayValue:
+		values := val.ArrayValue.GetValues()
+		out := make([]interface{}, len(values))
+		for i, item := range values {
+			out[i] = anyValue(item)
+		}
+		return out
+	case *commonpb.AnyValue_KvlistValue:
+		out := make(map[string]interface{}, len(val.KvlistValue.GetValues()))
+		for _, kv := range val.KvlistValue.GetValues() {
+			out[kv.GetKey()] = anyValue(kv.GetValue())
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// stringValue renders an AnyValue as a string for envelope attributes.
+func stringValue(v *commonpb.AnyValue) string {
+	switch val := anyValue(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool:
+		return strconv.FormatBool(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,122 @@
+package otlp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/otlp"
+)
+
+func strAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func testRequest() *collogspb.ExportLogsServiceRequest {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+				strAttr("service.name", "billing"),
+				strAttr("host.name", "web-01"),
+				{Key: "process.pid", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 42}}},
+			}},
+			ScopeLogs: []*logspb.ScopeLogs{{
+				Scope: &commonpb.InstrumentationScope{Name: "auth-logger", Version: "1.2.0"},
+				LogRecords: []*logspb.LogRecord{
+					{
+						TimeUnixNano:   uint64(ts.UnixNano()),
+						SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+						SeverityText:   "WARN",
+						Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{
+							Values: []*commonpb.KeyValue{strAttr("user", "alice"), strAttr("status", "failure")},
+						}}},
+						Attributes: []*commonpb.KeyValue{strAttr("event.domain", "authentication")},
+						TraceId:    []byte{0x01, 0x02},
+					},
+					{
+						Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "plain text line"}},
+					},
+				},
+			}},
+		}},
+	}
+}
+
+func TestConverter_Convert(t *testing.T) {
+	converter := otlp.NewConverter("", "")
+	records := converter.Convert(testRequest())
+
+	require.Len(t, records, 2)
+
+	structured := records[0]
+	assert.Equal(t, "authentication", structured.SourceType, "routed by event.domain")
+	assert.Equal(t, "billing", structured.Source)
+	assert.Equal(t, "web-01", structured.Host)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), structured.Timestamp.UTC())
+	assert.Equal(t, "alice", structured.Payload["user"])
+	assert.Equal(t, "failure", structured.Payload["status"])
+	assert.Equal(t, "WARN", structured.Payload["severity_text"])
+	assert.Equal(t, 13, structured.Payload["severity_number"])
+	assert.Equal(t, "0102", structured.Payload["trace_id"])
+	assert.Equal(t, "billing", structured.Attributes["resource.service.name"])
+	assert.Equal(t, "42", structured.Attributes["resource.process.pid"])
+	assert.Equal(t, "auth-logger", structured.Attributes["scope.name"])
+	assert.Equal(t, "1.2.0", structured.Attributes["scope.version"])
+
+	plain := records[1]
+	assert.Equal(t, otlp.DefaultSourceType, plain.SourceType)
+	assert.Equal(t, "plain text line", plain.Payload["message"])
+	assert.False(t, plain.Timestamp.IsZero())
+}
+
+func TestConverter_Convert_RoutesByResourceAttribute(t *testing.T) {
+	req := testRequest()
+	req.ResourceLogs[0].Resource.Attributes = append(req.ResourceLogs[0].Resource.Attributes, strAttr("log.type", "dns"))
+
+	records := otlp.NewConverter("log.type", "generic").Convert(req)
+
+	require.Len(t, records, 2)
+	assert.Equal(t, "dns", records[0].SourceType)
+	assert.Equal(t, "dns", records[1].SourceType)
+}
+
+func TestDecodeLogs(t *testing.T) {
+	req := testRequest()
+
+	t.Run("protobuf", func(t *testing.T) {
+		body, err := proto.Marshal(req)
+		require.NoError(t, err)
+
+		decoded, err := otlp.DecodeLogs(body, "application/x-protobuf")
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(req, decoded))
+	})
+
+	t.Run("json with charset", func(t *testing.T) {
+		body, err := protojson.Marshal(req)
+		require.NoError(t, err)
+
+		decoded, err := otlp.DecodeLogs(body, "application/json; charset=utf-8")
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(req, decoded))
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		_, err := otlp.DecodeLogs([]byte("x"), "text/plain")
+		assert.ErrorIs(t, err, otlp.ErrUnsupportedContentType)
+	})
+
+	t.Run("malformed protobuf", func(t *testing.T) {
+		_, err := otlp.DecodeLogs([]byte{0xff, 0xff, 0xff}, "application/x-protobuf")
+		assert.Error(t, err)
+	})
+}
@@ -53,5 +53,9 @@ func MarshalResult(event *ocsf.Event) ([]byte, error) {
 	if event == nil {
 		return nil, fmt.Errorf("nil event")
 	}
-	return json.Marshal(event)
+	doc, err := event.Document()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
 }
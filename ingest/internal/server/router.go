@@ -9,16 +9,80 @@ import (
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/handlers"
 )
 
+// RouterConfig holds the handlers served by the ingest router.
+type RouterConfig struct {
+	HEC *handlers.HECHandler
+	DLQ *handlers.DLQHandler // Optional: DLQ management API
+	// Tenants serves tenant onboarding; set only with per-client index routing.
+	Tenants *handlers.TenantHandler
+	// TransformRules serves per-client transform rule management; set only
+	// when transforms are enabled.
+	TransformRules *handlers.TransformRuleHandler
+	// OTLPLogs serves the OTLP/HTTP logs receiver at /v1/logs.
+	OTLPLogs bool
+	// Bulk serves the Elasticsearch-compatible bulk API at /_bulk and /{index}/_bulk.
+	Bulk bool
+}
+
 // NewRouter constructs a ServeMux with ingest API routes registered.
 func NewRouter(h *handlers.HECHandler) http.Handler {
+	return NewRouterWithConfig(RouterConfig{HEC: h})
+}
+
+// NewRouterWithConfig constructs a ServeMux with HEC routes and, when
+// configured, the DLQ management API.
+func NewRouterWithConfig(cfg RouterConfig) http.Handler {
 	mux := http.NewServeMux()
+	h := cfg.HEC
 
-	// Splunk HEC endpoints
+	// Splunk HEC endpoints, including the aliases Splunk serves them under
+	mux.HandleFunc("/services/collector", h.HandleEvent)
 	mux.HandleFunc("/services/collector/event", h.HandleEvent)
+	mux.HandleFunc("/services/collector/event/1.0", h.HandleEvent)
 	mux.HandleFunc("/services/collector/raw", h.HandleRaw)
+	mux.HandleFunc("/services/collector/raw/1.0", h.HandleRaw)
 	mux.HandleFunc("/services/collector/health", h.Health)
+	mux.HandleFunc("/services/collector/health/1.0", h.Health)
 	mux.HandleFunc("/services/collector/ack", h.Ack)
 
+	// OpenTelemetry OTLP/HTTP logs receiver (HEC token auth)
+	if cfg.OTLPLogs {
+		mux.HandleFunc("/v1/logs", h.HandleOTLPLogs)
+	}
+
+	// Elasticsearch-compatible bulk API for Filebeat/Logstash/Vector (HEC token auth)
+	if cfg.Bulk {
+		mux.HandleFunc("POST /_bulk", h.HandleBulk)
+		mux.HandleFunc("PUT /_bulk", h.HandleBulk)
+		mux.HandleFunc("POST /{index}/_bulk", h.HandleBulk)
+		mux.HandleFunc("PUT /{index}/_bulk", h.HandleBulk)
+		mux.HandleFunc("GET /{$}", h.BulkInfo)
+		mux.HandleFunc("GET /_cluster/health", h.BulkClusterHealth)
+	}
+
+	// DLQ inspection and replay (admin access token required)
+	if cfg.DLQ != nil {
+		mux.HandleFunc("GET /api/v1/dlq", cfg.DLQ.List)
+		mux.HandleFunc("DELETE /api/v1/dlq", cfg.DLQ.Purge)
+		mux.HandleFunc("GET /api/v1/dlq/stats", cfg.DLQ.Stats)
+		mux.HandleFunc("POST /api/v1/dlq/replay", cfg.DLQ.Replay)
+		mux.HandleFunc("GET /api/v1/dlq/{id}", cfg.DLQ.Show)
+	}
+
+	// Tenant onboarding for per-client index routing (admin access token required)
+	if cfg.Tenants != nil {
+		mux.HandleFunc("POST /api/v1/tenants/{client_id}/onboard", cfg.Tenants.Onboard)
+	}
+
+	// Per-client transform rules (admin access token required)
+	if cfg.TransformRules != nil {
+		mux.HandleFunc("GET /api/v1/clients/{client_id}/transform-rules", cfg.TransformRules.List)
+		mux.HandleFunc("POST /api/v1/clients/{client_id}/transform-rules", cfg.TransformRules.Create)
+		mux.HandleFunc("GET /api/v1/clients/{client_id}/transform-rules/{rule_id}", cfg.TransformRules.Show)
+		mux.HandleFunc("PUT /api/v1/clients/{client_id}/transform-rules/{rule_id}", cfg.TransformRules.Update)
+		mux.HandleFunc("DELETE /api/v1/clients/{client_id}/transform-rules/{rule_id}", cfg.TransformRules.Delete)
+	}
+
 	// Health endpoints
 	mux.HandleFunc("/healthz", h.Health)
 	mux.HandleFunc("/readyz", h.Ready)
@@ -8,20 +8,29 @@ import (
 
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/handlers"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/otlp"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/service"
 )
 
 // Mock service for testing
 type mockIngestService struct{}
 
-func (m *mockIngestService) IngestEvent(event *models.HECEvent, sourceIP string, tokenInfo *service.TokenInfo) (string, error) {
+func (m *mockIngestService) IngestEvent(ctx context.Context, event *models.HECEvent, sourceIP string, tokenInfo *service.TokenInfo) (string, error) {
 	return "", nil
 }
 
-func (m *mockIngestService) IngestRaw(data []byte, sourceIP string, tokenInfo *service.TokenInfo, source, sourceType, host string) (string, error) {
+func (m *mockIngestService) IngestRaw(ctx context.Context, data []byte, sourceIP string, tokenInfo *service.TokenInfo, source, sourceType, host string) (string, error) {
 	return "", nil
 }
 
+func (m *mockIngestService) IngestOTLPLog(ctx context.Context, record *otlp.LogRecord, sourceIP string, tokenInfo *service.TokenInfo) (string, error) {
+	return "", nil
+}
+
+func (m *mockIngestService) IngestDocuments(ctx context.Context, docs []service.Document, sourceIP string, tokenInfo *service.TokenInfo) []error {
+	return make([]error, len(docs))
+}
+
 func (m *mockIngestService) ValidateHECToken(ctx context.Context, token string) (*service.TokenInfo, error) {
 	return &service.TokenInfo{}, nil
 }
@@ -61,6 +70,27 @@ func TestRouter_EventEndpoint(t *testing.T) {
 	}
 }
 
+func TestRouter_SplunkAliases(t *testing.T) {
+	mockService := &mockIngestService{}
+	handler := handlers.NewHECHandler(mockService, nil, nil)
+	router := NewRouter(handler)
+
+	for _, path := range []string{
+		"/services/collector",
+		"/services/collector/event/1.0",
+		"/services/collector/raw/1.0",
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code == http.StatusNotFound {
+			t.Errorf("%s endpoint not registered", path)
+		}
+	}
+}
+
 func TestRouter_RawEndpoint(t *testing.T) {
 	mockService := &mockIngestService{}
 	handler := handlers.NewHECHandler(mockService, nil, nil)
@@ -188,3 +218,98 @@ func TestRouter_RequestIDMiddleware(t *testing.T) {
 		t.Error("X-Request-ID header not set by middleware")
 	}
 }
+
+func TestRouter_DLQEndpoints(t *testing.T) {
+	handler := handlers.NewHECHandler(&mockIngestService{}, nil, nil)
+	dlqHandler := handlers.NewDLQHandler(service.NewDLQService(nil, nil), nil)
+	router := NewRouterWithConfig(RouterConfig{HEC: handler, DLQ: dlqHandler})
+
+	endpoints := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/v1/dlq"},
+		{http.MethodDelete, "/api/v1/dlq"},
+		{http.MethodGet, "/api/v1/dlq/stats"},
+		{http.MethodPost, "/api/v1/dlq/replay"},
+		{http.MethodGet, "/api/v1/dlq/some-id"},
+	}
+
+	for _, ep := range endpoints {
+		req := httptest.NewRequest(ep.method, ep.path, nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		// No bearer token: routed requests are rejected by the handler
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s: expected status 401, got %d", ep.method, ep.path, rr.Code)
+		}
+	}
+}
+
+func TestRouter_DLQEndpointsNotRegisteredWithoutHandler(t *testing.T) {
+	router := NewRouter(handlers.NewHECHandler(&mockIngestService{}, nil, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/dlq", nil)
+	rr := httptest.NewRecorder()
+
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestRouter_OTLPLogsEndpoint(t *testing.T) {
+	handler := handlers.NewHECHandler(&mockIngestService{}, nil, nil)
+
+	enabled := NewRouterWithConfig(RouterConfig{HEC: handler, OTLPLogs: true})
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs", nil)
+	rr := httptest.NewRecorder()
+	enabled.ServeHTTP(rr, req)
+
+	if rr.Code == http.StatusNotFound {
+		t.Error("/v1/logs endpoint not registered")
+	}
+
+	disabled := NewRouterWithConfig(RouterConfig{HEC: handler})
+	req = httptest.NewRequest(http.MethodPost, "/v1/logs", nil)
+	rr = httptest.NewRecorder()
+	disabled.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected /v1/logs to be absent when disabled, got %d", rr.Code)
+	}
+}
+
+func TestRouter_BulkEndpoints(t *testing.T) {
+	handler := handlers.NewHECHandler(&mockIngestService{}, nil, nil)
+
+	paths := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/_bulk"},
+		{http.MethodPut, "/_bulk"},
+		{http.MethodPost, "/logs-app/_bulk"},
+		{http.MethodGet, "/"},
+		{http.MethodGet, "/_cluster/health"},
+	}
+
+	enabled := NewRouterWithConfig(RouterConfig{HEC: handler, Bulk: true})
+	disabled := NewRouterWithConfig(RouterConfig{HEC: handler})
+	for _, p := range paths {
+		rr := httptest.NewRecorder()
+		enabled.ServeHTTP(rr, httptest.NewRequest(p.method, p.path, nil))
+		if rr.Code == http.StatusNotFound {
+			t.Errorf("%s %s not registered", p.method, p.path)
+		}
+
+		rr = httptest.NewRecorder()
+		disabled.ServeHTTP(rr, httptest.NewRequest(p.method, p.path, nil))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected %s %s to be absent when disabled, got %d", p.method, p.path, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/dlq"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/metrics"
+)
+
+// ErrDLQDisabled is returned by DLQService when no DLQ backend is configured.
+var ErrDLQDisabled = errors.New("dlq not enabled")
+
+// Replay outcome statuses.
+const (
+	ReplayStatusReplayed    = "replayed"
+	ReplayStatusFailed      = "failed"
+	ReplayStatusWouldReplay = "would_replay"
+	ReplayStatusWouldFail   = "would_fail"
+	ReplayStatusSkipped     = "skipped"
+	ReplayStatusNotFound    = "not_found"
+)
+
+// ReplayRequest selects DLQ entries to reprocess. IDs take precedence over
+// Filter, except that Filter.ClientID still scopes the IDs to one client.
+type ReplayRequest struct {
+	IDs    []string
+	Filter dlq.Filter
+	DryRun bool
+	Force  bool // Also replay entries that were already replayed successfully
+}
+
+// ReplayOutcome is the per-entry result of a replay.
+type ReplayOutcome struct {
+	ID            string                 `json:"id"`
+	Status        string                 `json:"status"`
+	Error         string                 `json:"error,omitempty"`
+	PreviousError string                 `json:"previous_error,omitempty"`
+	Event         map[string]interface{} `json:"event,omitempty"` // Full normalized output (dry run only)
+	Diff          []dlq.FieldChange      `json:"diff,omitempty"`  // Against the last recorded output (dry run only)
+	// DiffBase is when the output Diff compares against was recorded. It is
+	// unset when no earlier replay produced output, so there is no diff and
+	// Event is the only result.
+	DiffBase *time.Time `json:"diff_base,omitempty"`
+}
+
+// ReplayReport summarizes a replay run.
+type ReplayReport struct {
+	DryRun    bool            `json:"dry_run"`
+	Total     int             `json:"total"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+	Skipped   int             `json:"skipped"`
+	Outcomes  []ReplayOutcome `json:"outcomes"`
+}
+
+// DLQService exposes DLQ inspection and replays failed events through the
+// current normalization pipeline and storage.
+type DLQService struct {
+	store  dlq.Store
+	ingest *IngestService
+}
+
+// NewDLQService creates a DLQ service. store may be nil when the DLQ is disabled.
+func NewDLQService(store dlq.Store, ingest *IngestService) *DLQService {
+	return &DLQService{
+		store:  store,
+		ingest: ingest,
+	}
+}
+
+func (s *DLQService) enabled() bool {
+	return s != nil && s.store != nil
+}
+
+// List returns DLQ entries matching the filter, oldest first.
+func (s *DLQService) List(ctx context.Context, filter dlq.Filter) ([]dlq.FailedEvent, error) {
+	if !s.enabled() {
+		return nil, ErrDLQDisabled
+	}
+	return s.store.Query(ctx, filter)
+}
+
+// Get returns a single DLQ entry.
+func (s *DLQService) Get(ctx context.Context, id string) (*dlq.FailedEvent, error) {
+	if !s.enabled() {
+		return nil, ErrDLQDisabled
+	}
+	return s.store.Get(ctx, id)
+}
+
+// Stats returns backend metrics.
+func (s *DLQService) Stats(ctx context.Context) map[string]interface{} {
+	if !s.enabled() {
+		return map[string]interface{}{"enabled": false}
+	}
+	return s.store.Info(ctx)
+}
+
+// Purge deletes entries matching the filter.
+func (s *DLQService) Purge(ctx context.Context, filter dlq.Filter) (int, error) {
+	if !s.enabled() {
+		return 0, ErrDLQDisabled
+	}
+	return s.store.PurgeMatching(ctx, filter)
+}
+
+// Replay re-runs the selected entries through pipeline.Process and, unless
+// DryRun is set, stores the result. Every attempt is recorded on the entry;
+// dry runs report the normalized output and a diff against the previous
+// attempt that produced one.
+func (s *DLQService) Replay(ctx context.Context, req ReplayRequest) (*ReplayReport, error) {
+	if !s.enabled() {
+		return nil, ErrDLQDisabled
+	}
+
+	report := &ReplayReport{DryRun: req.DryRun}
+
+	var entries []*dlq.FailedEvent
+	if len(req.IDs) > 0 {
+		found, err := s.store.Query(ctx, dlq.Filter{IDs: req.IDs, ClientID: req.Filter.ClientID})
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]*dlq.FailedEvent, len(found))
+		for i := range found {
+			byID[found[i].ID] = &found[i]
+		}
+		for _, id := range req.IDs {
+			entry, ok := byID[id]
+			if !ok {
+				outcome := ReplayOutcome{ID: id, Status: ReplayStatusNotFound, Error: dlq.ErrNotFound.Error()}
+				report.Outcomes = append(report.Outcomes, outcome)
+				report.Skipped++
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	} else {
+		filter := req.Filter
+		if filter.Status == "" && !req.Force {
+			filter.Status = dlq.StatusPending
+		}
+		found, err := s.store.Query(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for i := range found {
+			entries = append(entries, &found[i])
+		}
+	}
+
+	for _, entry := range entries {
+		outcome := s.replayOne(ctx, entry, req)
+		report.Outcomes = append(report.Outcomes, outcome)
+		switch outcome.Status {
+		case ReplayStatusReplayed, ReplayStatusWouldReplay:
+			report.Succeeded++
+		case ReplayStatusSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+	}
+	report.Total = len(report.Outcomes)
+
+	log.Printf("DLQ: replay finished (dry_run=%t total=%d succeeded=%d failed=%d skipped=%d)",
+		report.DryRun, report.Total, report.Succeeded, report.Failed, report.Skipped)
+
+	return report, nil
+}
+
+func (s *DLQService) replayOne(ctx context.Context, entry *dlq.FailedEvent, req ReplayRequest) ReplayOutcome {
+	outcome := ReplayOutcome{ID: entry.ID, PreviousError: entry.Error}
+
+	if entry.Status == dlq.StatusReplayed && !req.Force {
+		outcome.Status = ReplayStatusSkipped
+		outcome.Error = "already replayed"
+		return outcome
+	}
+	if entry.Envelope == nil {
+		outcome.Status = ReplayStatusFailed
+		outcome.Error = "entry has no envelope"
+		return outcome
+	}
+
+	procCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	eventMap, err := s.ingest.normalizeEnvelope(procCtx, entry.Envelope)
+	cancel()
+	if err != nil {
+		err = fmt.Errorf("normalization failed: %w", err)
+	} else if !req.DryRun {
+		err = s.ingest.forwardToStorage(ctx, entry.Envelope.SourceType, eventMap)
+	}
+
+	attempt := dlq.ReplayAttempt{
+		At:      time.Now().UTC(),
+		DryRun:  req.DryRun,
+		Success: err == nil,
+		Event:   eventMap,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		outcome.Error = err.Error()
+	}
+
+	if req.DryRun {
+		if eventMap != nil {
+			outcome.Event = eventMap
+			// The entry failed before it was ever normalized, so the first
+			// replay has nothing to compare against
+			if last := entry.LastOutput(); last != nil {
+				at := last.At
+				outcome.Diff = dlq.Diff(last.Event, eventMap)
+				outcome.DiffBase = &at
+			}
+		}
+		outcome.Status = ReplayStatusWouldReplay
+		if err != nil {
+			outcome.Status = ReplayStatusWouldFail
+		}
+	} else {
+		outcome.Status = ReplayStatusReplayed
+		if err != nil {
+			outcome.Status = ReplayStatusFailed
+		}
+		metrics.DLQReplaysTotal.WithLabelValues(outcome.Status).Inc()
+	}
+
+	entry.RecordReplay(attempt)
+	if updateErr := s.store.Update(ctx, entry); updateErr != nil {
+		log.Printf("DLQ: failed to record replay outcome for %s: %v", entry.ID, updateErr)
+	}
+
+	return outcome
+}
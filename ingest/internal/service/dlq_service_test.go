@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/dlq"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/normalizer"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/pipeline"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/storageclient"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/validator"
+)
+
+type mockStorageClient struct {
+	err       error
+	conflicts []int // Positions reported as create conflicts
+	calls     int
+	events    []map[string]interface{}
+}
+
+func (m *mockStorageClient) Ingest(ctx context.Context, events []map[string]interface{}) (*storageclient.IngestResponse, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.events = append(m.events, events...)
+	return &storageclient.IngestResponse{Indexed: len(events) - len(m.conflicts), Conflicts: m.conflicts}, nil
+}
+
+func newTestIngestService(t *testing.T, storage StorageClient) (*IngestService, *dlq.Queue) {
+	t.Helper()
+
+	queue, err := dlq.NewQueue(t.TempDir())
+	require.NoError(t, err)
+
+	pipe := pipeline.New(
+		normalizer.NewRegistry(&normalizer.HECNormalizer{}),
+		validator.NewChain(&validator.BasicValidator{}),
+	)
+	ingest := NewIngestService(pipe, queue, storage, nil)
+	t.Cleanup(ingest.Stop)
+
+	return ingest, queue
+}
+
+func newTestDLQService(t *testing.T, storage *mockStorageClient) (*DLQService, *dlq.Queue) {
+	t.Helper()
+
+	ingest, queue := newTestIngestService(t, storage)
+	return NewDLQService(queue, ingest), queue
+}
+
+func writeFailed(t *testing.T, queue *dlq.Queue, sourceType string) string {
+	t.Helper()
+	envelope := &models.RawEventEnvelope{
+		ID:         "evt-1",
+		Format:     "json",
+		SourceType: sourceType,
+		Source:     "test",
+		Payload:    []byte(`{"event":"login","host":"web-01"}`),
+		Attributes: map[string]string{"client_id": "client-1", "hec_token_id": "tok-1"},
+		ReceivedAt: time.Now(),
+	}
+	require.NoError(t, queue.Write(context.Background(), envelope, errors.New("no normalizer"), "normalization_failed"))
+
+	events, err := queue.Query(context.Background(), dlq.Filter{SourceType: sourceType})
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+	return events[len(events)-1].ID
+}
+
+func TestDLQService_Disabled(t *testing.T) {
+	svc := NewDLQService(nil, nil)
+
+	_, err := svc.List(context.Background(), dlq.Filter{})
+	assert.ErrorIs(t, err, ErrDLQDisabled)
+	_, err = svc.Replay(context.Background(), ReplayRequest{})
+	assert.ErrorIs(t, err, ErrDLQDisabled)
+	assert.Equal(t, false, svc.Stats(context.Background())["enabled"])
+}
+
+func TestDLQService_Replay(t *testing.T) {
+	storage := &mockStorageClient{}
+	svc, queue := newTestDLQService(t, storage)
+	ctx := context.Background()
+	id := writeFailed(t, queue, "hec")
+
+	report, err := svc.Replay(ctx, ReplayRequest{IDs: []string{id}})
+	require.NoError(t, err)
+	require.Len(t, report.Outcomes, 1)
+	assert.Equal(t, ReplayStatusReplayed, report.Outcomes[0].Status)
+	assert.Equal(t, "no normalizer", report.Outcomes[0].PreviousError)
+	assert.Equal(t, 1, report.Succeeded)
+	require.Len(t, storage.events, 1)
+	assert.Equal(t, "client-1", storage.events[0]["client_id"])
+
+	entry, err := queue.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, dlq.StatusReplayed, entry.Status)
+
+	t.Run("already replayed entries are skipped", func(t *testing.T) {
+		report, err := svc.Replay(ctx, ReplayRequest{IDs: []string{id}})
+		require.NoError(t, err)
+		assert.Equal(t, ReplayStatusSkipped, report.Outcomes[0].Status)
+		assert.Equal(t, 1, report.Skipped)
+		assert.Len(t, storage.events, 1)
+	})
+
+	t.Run("force replays again", func(t *testing.T) {
+		report, err := svc.Replay(ctx, ReplayRequest{IDs: []string{id}, Force: true})
+		require.NoError(t, err)
+		assert.Equal(t, ReplayStatusReplayed, report.Outcomes[0].Status)
+		assert.Len(t, storage.events, 2)
+	})
+
+	t.Run("unknown id is reported", func(t *testing.T) {
+		report, err := svc.Replay(ctx, ReplayRequest{IDs: []string{"missing"}})
+		require.NoError(t, err)
+		assert.Equal(t, ReplayStatusNotFound, report.Outcomes[0].Status)
+	})
+
+	t.Run("another client's id is not found", func(t *testing.T) {
+		report, err := svc.Replay(ctx, ReplayRequest{IDs: []string{id}, Filter: dlq.Filter{ClientID: "client-2"}, Force: true})
+		require.NoError(t, err)
+		assert.Equal(t, ReplayStatusNotFound, report.Outcomes[0].Status)
+		assert.Len(t, storage.events, 2)
+	})
+}
+
+func TestDLQService_Replay_DryRun(t *testing.T) {
+	storage := &mockStorageClient{}
+	svc, queue := newTestDLQService(t, storage)
+	ctx := context.Background()
+	id := writeFailed(t, queue, "hec")
+
+	report, err := svc.Replay(ctx, ReplayRequest{DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, report.Outcomes, 1)
+
+	outcome := report.Outcomes[0]
+	assert.True(t, report.DryRun)
+	assert.Equal(t, ReplayStatusWouldReplay, outcome.Status)
+	assert.NotEmpty(t, outcome.Event)
+	assert.Empty(t, outcome.Diff, "first dry run has no earlier output to diff against")
+	assert.Nil(t, outcome.DiffBase)
+	assert.Empty(t, storage.events, "dry run must not store events")
+
+	entry, err := queue.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, dlq.StatusPending, entry.Status)
+	assert.Equal(t, 1, entry.Attempts)
+	assert.Len(t, entry.Replays, 1)
+
+	t.Run("later dry runs diff against the recorded output", func(t *testing.T) {
+		report, err := svc.Replay(ctx, ReplayRequest{IDs: []string{id}, DryRun: true})
+		require.NoError(t, err)
+		require.Len(t, report.Outcomes, 1)
+
+		outcome := report.Outcomes[0]
+		require.NotNil(t, outcome.DiffBase)
+		assert.Equal(t, entry.Replays[0].At, *outcome.DiffBase)
+		// Time fields aside, the pipeline is unchanged, so the output is too
+		for _, change := range outcome.Diff {
+			assert.NotEqual(t, "added", change.Op, "unexpected added field %s", change.Path)
+		}
+	})
+}
+
+func TestDLQService_Replay_Failures(t *testing.T) {
+	storage := &mockStorageClient{err: errors.New("storage down")}
+	svc, queue := newTestDLQService(t, storage)
+	ctx := context.Background()
+
+	writeFailed(t, queue, "hec")
+	writeFailed(t, queue, "unknown")
+
+	report, err := svc.Replay(ctx, ReplayRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 2, report.Failed)
+	for _, outcome := range report.Outcomes {
+		assert.Equal(t, ReplayStatusFailed, outcome.Status)
+		assert.NotEmpty(t, outcome.Error)
+	}
+
+	pending, err := svc.List(ctx, dlq.Filter{Status: dlq.StatusPending})
+	require.NoError(t, err)
+	assert.Len(t, pending, 2, "failed replays stay pending")
+}
@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -15,8 +18,21 @@ import (
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/dlq"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/metrics"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/otlp"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/pipeline"
 	"github.com/telhawk-systems/telhawk-stack/ingest/internal/storageclient"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/parsing"
+)
+
+var (
+	// ErrInvalidDocument is returned for documents that are not JSON objects.
+	ErrInvalidDocument = errors.New("invalid document")
+	// ErrNormalizationFailed is returned when an event fails normalization.
+	ErrNormalizationFailed = errors.New("normalization failed")
+	// ErrStorageFailed is returned when the storage service rejects an event.
+	ErrStorageFailed = errors.New("storage failed")
+	// ErrDocumentExists is returned when a created document's ID is taken.
+	ErrDocumentExists = errors.New("document already exists")
 )
 
 type IngestService struct {
@@ -30,6 +46,9 @@ type IngestService struct {
 	storageClient StorageClient
 	authClient    AuthClient
 	ackManager    *ack.Manager
+	tail          TailPublisher
+	parsers       *parsing.Set
+	transforms    Transformer
 	queueCapacity int
 }
 
@@ -37,6 +56,20 @@ type StorageClient interface {
 	Ingest(ctx context.Context, events []map[string]interface{}) (*storageclient.IngestResponse, error)
 }
 
+// TailPublisher receives stored events for live tail. Publish must not block.
+type TailPublisher interface {
+	Publish(sourceType string, event map[string]interface{})
+}
+
+// Transformer applies ingest transform rules to a normalized event, possibly
+// modifying it, and reports whether the event should be stored.
+type Transformer interface {
+	Apply(sourceType string, event map[string]interface{}) bool
+	// RedactRaw redacts a payload that could not be normalized before it is
+	// kept, e.g. in the DLQ.
+	RedactRaw(clientID, sourceType, hecTokenID string, payload []byte) []byte
+}
+
 type AuthClient interface {
 	ValidateHECToken(ctx context.Context, token string) (*authclient.ValidateHECTokenResponse, error)
 }
@@ -71,6 +104,23 @@ func (s *IngestService) SetAckManager(manager *ack.Manager) {
 	s.ackManager = manager
 }
 
+// SetTailPublisher configures live tail publishing of stored events
+func (s *IngestService) SetTailPublisher(publisher TailPublisher) {
+	s.tail = publisher
+}
+
+// SetParsingProfiles configures how raw payloads are split into events, per
+// sourcetype
+func (s *IngestService) SetParsingProfiles(parsers *parsing.Set) {
+	s.parsers = parsers
+}
+
+// SetTransforms configures the transform rules applied to normalized events
+// before storage
+func (s *IngestService) SetTransforms(transforms Transformer) {
+	s.transforms = transforms
+}
+
 func (s *IngestService) IngestEvent(ctx context.Context, event *models.HECEvent, sourceIP string, tokenInfo *TokenInfo) (string, error) {
 	// Determine source_type with fallback to default
 	sourceType := event.SourceType
@@ -111,30 +161,7 @@ func (s *IngestService) IngestEvent(ctx context.Context, event *models.HECEvent,
 	// Sign event for nonrepudiation
 	internalEvent.Signature = s.signEvent(internalEvent)
 
-	// Create ack if manager is configured (before queueing)
-	var ackID string
-	if s.ackManager != nil {
-		ackID = s.ackManager.Create([]string{internalEvent.ID})
-		internalEvent.AckID = ackID
-	}
-
-	// Queue for processing
-	select {
-	case s.eventQueue <- internalEvent:
-		s.updateStats(len(raw), true)
-		metrics.EventsTotal.WithLabelValues("event", "accepted").Inc()
-		metrics.EventBytesTotal.Add(float64(len(raw)))
-		metrics.QueueDepth.Set(float64(len(s.eventQueue)))
-		return ackID, nil
-	default:
-		// If queue is full, fail the ack
-		if s.ackManager != nil && ackID != "" {
-			s.ackManager.Fail(ackID)
-		}
-		s.updateStats(0, false)
-		metrics.EventsTotal.WithLabelValues("event", "queue_full").Inc()
-		return "", fmt.Errorf("event queue full")
-	}
+	return s.enqueue(internalEvent, "event")
 }
 
 func (s *IngestService) IngestRaw(ctx context.Context, data []byte, sourceIP string, tokenInfo *TokenInfo, source, sourceType, host string) (string, error) {
@@ -145,6 +172,10 @@ func (s *IngestService) IngestRaw(ctx context.Context, data []byte, sourceIP str
 		clientID = tokenInfo.ClientID
 	}
 
+	if parser := s.parsers.Get(sourceType); parser != nil {
+		return s.ingestParsed(ctx, parser, data, sourceIP, hecTokenID, clientID, source, sourceType, host)
+	}
+
 	event := &models.Event{
 		ID:         uuid.New().String(),
 		Timestamp:  time.Now(),
@@ -162,18 +193,306 @@ func (s *IngestService) IngestRaw(ctx context.Context, data []byte, sourceIP str
 
 	event.Signature = s.signEvent(event)
 
+	return s.enqueue(event, "raw")
+}
+
+// ingestParsed splits a raw payload with the sourcetype's parsing profile and
+// queues each event as a flat JSON payload (extracted fields, message and
+// time). Like a HEC batch, the events share one ack ID.
+func (s *IngestService) ingestParsed(ctx context.Context, parser *parsing.Parser, data []byte, sourceIP, hecTokenID, clientID, source, sourceType, host string) (string, error) {
+	events := parser.Parse(data)
+	if len(events) == 0 {
+		return "", fmt.Errorf("no events parsed from raw payload")
+	}
+
+	ctx, batch := NewAckBatch(ctx)
+	defer batch.Seal()
+
+	var lastErr error
+	accepted := 0
+	for _, parsed := range events {
+		payload := parsed.Payload()
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		event := &models.Event{
+			ID:         uuid.New().String(),
+			Timestamp:  time.Now(),
+			Source:     source,
+			SourceType: sourceType,
+			Host:       host,
+			SourceIP:   sourceIP,
+			Index:      "main",
+			Event:      payload,
+			Raw:        raw,
+			HECTokenID: hecTokenID,
+			ClientID:   clientID,
+			Ctx:        ctx,
+		}
+		event.Signature = s.signEvent(event)
+
+		if _, err := s.enqueue(event, "raw"); err != nil {
+			lastErr = err
+			continue
+		}
+		accepted++
+	}
+
+	if accepted == 0 {
+		return "", lastErr
+	}
+	if accepted < len(events) {
+		log.Printf("raw payload partially failed: %d/%d parsed events queued: %v", accepted, len(events), lastErr)
+	}
+	return batch.Seal(), nil
+}
+
+// IngestOTLPLog queues a single OTLP log record. The record's payload is
+// normalized like a HEC event; resource and scope attributes travel in the
+// envelope attributes.
+func (s *IngestService) IngestOTLPLog(ctx context.Context, record *otlp.LogRecord, sourceIP string, tokenInfo *TokenInfo) (string, error) {
+	var hecTokenID, clientID string
+	if tokenInfo != nil {
+		hecTokenID = tokenInfo.TokenID
+		clientID = tokenInfo.ClientID
+	}
+
+	raw, err := json.Marshal(record.Payload)
+	if err != nil {
+		return "", err
+	}
+
+	event := &models.Event{
+		ID:         uuid.New().String(),
+		Timestamp:  record.Timestamp,
+		Host:       record.Host,
+		Source:     record.Source,
+		SourceType: record.SourceType,
+		SourceIP:   sourceIP,
+		Index:      "main",
+		Event:      record.Payload,
+		Raw:        raw,
+		HECTokenID: hecTokenID,
+		ClientID:   clientID,
+		Attributes: record.Attributes,
+		Ctx:        ctx,
+	}
+
+	event.Signature = s.signEvent(event)
+
+	return s.enqueue(event, "otlp")
+}
+
+// Document is one document of an Elasticsearch bulk request.
+type Document struct {
+	ID         string // Bulk action _id; the stored document ID is derived from it and the client
+	Create     bool   // Bulk create action: fail with ErrDocumentExists instead of overwriting
+	Index      string
+	SourceType string
+	Source     []byte
+}
+
+// IngestDocuments normalizes JSON documents and stores them synchronously in
+// one storage request, for callers that report a result per document (the
+// Elasticsearch bulk API). It returns one error per document: documents that
+// are not JSON objects return ErrInvalidDocument, documents that fail
+// normalization are written to the DLQ and return ErrNormalizationFailed,
+// created documents whose ID is taken return ErrDocumentExists, and storage
+// failures return ErrStorageFailed for every document of the request and are
+// safe to retry.
+func (s *IngestService) IngestDocuments(ctx context.Context, docs []Document, sourceIP string, tokenInfo *TokenInfo) []error {
+	var hecTokenID, clientID string
+	if tokenInfo != nil {
+		hecTokenID = tokenInfo.TokenID
+		clientID = tokenInfo.ClientID
+	}
+
+	errs := make([]error, len(docs))
+	var batch []storedEvent
+	var batchDocs []int
+	for i, d := range docs {
+		var doc map[string]interface{}
+		if err := json.Unmarshal(d.Source, &doc); err != nil || doc == nil {
+			s.updateStats(0, false)
+			metrics.EventsTotal.WithLabelValues("bulk", "invalid").Inc()
+			errs[i] = fmt.Errorf("%w: source is not a JSON object", ErrInvalidDocument)
+			continue
+		}
+
+		id := d.ID
+		if id == "" {
+			id = uuid.New().String()
+		}
+		event := &models.Event{
+			ID:         id,
+			Timestamp:  documentTime(doc),
+			Host:       documentHost(doc),
+			Source:     d.Index,
+			SourceType: d.SourceType,
+			SourceIP:   sourceIP,
+			Index:      d.Index,
+			Event:      doc,
+			Raw:        d.Source,
+			HECTokenID: hecTokenID,
+			ClientID:   clientID,
+			Ctx:        ctx,
+		}
+		event.Signature = s.signEvent(event)
+
+		startTime := time.Now()
+		normalizedEvent, err := s.normalizeEvent(event)
+		metrics.NormalizationDuration.Observe(time.Since(startTime).Seconds())
+		if err != nil {
+			metrics.NormalizationErrors.Inc()
+			s.updateStats(len(d.Source), false)
+			metrics.EventsTotal.WithLabelValues("bulk", "normalization_failed").Inc()
+			errs[i] = err
+			continue
+		}
+
+		// Retried documents overwrite their earlier copy instead of duplicating it
+		normalizedEvent[storageclient.IDField] = storedDocumentID(clientID, id)
+		if d.Create {
+			normalizedEvent[storageclient.CreateField] = true
+		}
+		batch = append(batch, storedEvent{sourceType: d.SourceType, event: normalizedEvent})
+		batchDocs = append(batchDocs, i)
+	}
+
+	if len(batch) == 0 {
+		return errs
+	}
+
+	startTime := time.Now()
+	conflicts, err := s.forwardBatchToStorage(ctx, batch)
+	metrics.StorageDuration.Observe(time.Since(startTime).Seconds())
+	for j, i := range batchDocs {
+		size := len(docs[i].Source)
+		if conflicts[j] {
+			s.updateStats(size, false)
+			metrics.EventsTotal.WithLabelValues("bulk", "conflict").Inc()
+			errs[i] = fmt.Errorf("%w: [%s]", ErrDocumentExists, docs[i].ID)
+			continue
+		}
+		if err != nil {
+			metrics.StorageErrors.Inc()
+			s.updateStats(size, false)
+			metrics.EventsTotal.WithLabelValues("bulk", "storage_failed").Inc()
+			errs[i] = fmt.Errorf("%w: %w", ErrStorageFailed, err)
+			continue
+		}
+		s.updateStats(size, true)
+		metrics.EventsTotal.WithLabelValues("bulk", "accepted").Inc()
+		metrics.EventBytesTotal.Add(float64(size))
+	}
+	return errs
+}
+
+// storedDocumentID scopes a shipper-chosen document ID to the client, so
+// clients sharing an index series cannot overwrite each other's documents.
+func storedDocumentID(clientID, id string) string {
+	sum := sha256.Sum256([]byte(clientID + "\x00" + id))
+	return hex.EncodeToString(sum[:])
+}
+
+// documentTime reads the ECS @timestamp of a shipped document.
+func documentTime(doc map[string]interface{}) time.Time {
+	if ts, ok := doc["@timestamp"].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
+
+// documentHost reads the ECS host.name (or a plain host string) of a shipped document.
+func documentHost(doc map[string]interface{}) string {
+	switch host := doc["host"].(type) {
+	case string:
+		return host
+	case map[string]interface{}:
+		if name, ok := host["name"].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// ackBatchKey is the context key of a request's AckBatch
+type ackBatchKey struct{}
+
+// AckBatch gives the events of one request a single ack ID, so the ack
+// reports whether the whole request was indexed.
+type AckBatch struct {
+	mu      sync.Mutex
+	manager *ack.Manager
+	id      string
+}
+
+// NewAckBatch returns a context under which ingested events share one ack.
+// Call Seal once all the request's events are ingested.
+func NewAckBatch(ctx context.Context) (context.Context, *AckBatch) {
+	batch := &AckBatch{}
+	return context.WithValue(ctx, ackBatchKey{}, batch), batch
+}
+
+// join adds an event to the batch, creating its ack on first use.
+func (b *AckBatch) join(manager *ack.Manager, eventID string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.id == "" {
+		b.manager = manager
+		b.id = manager.CreateBatch()
+	}
+	manager.Add(b.id, eventID)
+	return b.id
+}
+
+// Seal closes the batch and returns its ack ID, which is empty if no event
+// joined it (or acks are disabled). Sealing twice is harmless.
+func (b *AckBatch) Seal() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.manager != nil {
+		b.manager.Seal(b.id)
+	}
+	return b.id
+}
+
+// ackBatchFrom returns the AckBatch of a context, if any.
+func ackBatchFrom(ctx context.Context) *AckBatch {
+	if ctx == nil {
+		return nil
+	}
+	batch, _ := ctx.Value(ackBatchKey{}).(*AckBatch)
+	return batch
+}
+
+// enqueue creates the event's ack (if acks are enabled) and queues it for
+// processing. Events ingested under NewAckBatch share the batch's ack.
+// endpoint labels the events_total metric.
+func (s *IngestService) enqueue(event *models.Event, endpoint string) (string, error) {
 	// Create ack if manager is configured (before queueing)
 	var ackID string
 	if s.ackManager != nil {
-		ackID = s.ackManager.Create([]string{event.ID})
+		if batch := ackBatchFrom(event.Ctx); batch != nil {
+			ackID = batch.join(s.ackManager, event.ID)
+		} else {
+			ackID = s.ackManager.Create([]string{event.ID})
+		}
 		event.AckID = ackID
 	}
 
 	select {
 	case s.eventQueue <- event:
-		s.updateStats(len(data), true)
-		metrics.EventsTotal.WithLabelValues("raw", "accepted").Inc()
-		metrics.EventBytesTotal.Add(float64(len(data)))
+		s.updateStats(len(event.Raw), true)
+		metrics.EventsTotal.WithLabelValues(endpoint, "accepted").Inc()
+		metrics.EventBytesTotal.Add(float64(len(event.Raw)))
 		metrics.QueueDepth.Set(float64(len(s.eventQueue)))
 		return ackID, nil
 	default:
@@ -182,7 +501,7 @@ func (s *IngestService) IngestRaw(ctx context.Context, data []byte, sourceIP str
 			s.ackManager.Fail(ackID)
 		}
 		s.updateStats(0, false)
-		metrics.EventsTotal.WithLabelValues("raw", "queue_full").Inc()
+		metrics.EventsTotal.WithLabelValues(endpoint, "queue_full").Inc()
 		return "", fmt.Errorf("event queue full")
 	}
 }
@@ -214,7 +533,7 @@ func (s *IngestService) processEvents() {
 
 			// Forward to Storage service
 			startTime = time.Now()
-			err = s.forwardToStorage(event.Ctx, normalizedEvent)
+			err = s.forwardToStorage(event.Ctx, event.SourceType, normalizedEvent)
 			metrics.StorageDuration.Observe(time.Since(startTime).Seconds())
 
 			if err != nil {
@@ -254,7 +573,8 @@ func (s *IngestService) normalizeEvent(event *models.Event) (map[string]interfac
 	ctx, cancel := context.WithTimeout(parent, 5*time.Second)
 	defer cancel()
 
-	// Create raw event envelope for pipeline
+	// Create raw event envelope for pipeline. The ingestion context travels
+	// in the attributes so DLQ replays can restore it.
 	envelope := &models.RawEventEnvelope{
 		ID:         event.ID,
 		Source:     event.Source,
@@ -262,23 +582,49 @@ func (s *IngestService) normalizeEvent(event *models.Event) (map[string]interfac
 		Format:     "json",
 		Payload:    event.Raw, // Raw JSON bytes, not base64 encoded
 		Attributes: map[string]string{
-			"host":      event.Host,
-			"index":     event.Index,
-			"source_ip": event.SourceIP,
+			"host":         event.Host,
+			"index":        event.Index,
+			"source_ip":    event.SourceIP,
+			"client_id":    event.ClientID,
+			"hec_token_id": event.HECTokenID,
 		},
 		ReceivedAt: event.Timestamp,
 	}
+	for key, value := range event.Attributes {
+		if _, reserved := envelope.Attributes[key]; !reserved {
+			envelope.Attributes[key] = value
+		}
+	}
 
-	// Process through normalization pipeline
-	normalizedEvent, err := s.pipeline.Process(ctx, envelope)
+	eventMap, err := s.normalizeEnvelope(ctx, envelope)
 	if err != nil {
-		// Write to DLQ if available
+		// Write to DLQ if available, with the client's raw redactions applied
+		// so the DLQ never holds what storage would not have.
 		if s.dlq != nil {
-			if dlqErr := s.dlq.Write(ctx, envelope, err, "normalization_failed"); dlqErr != nil {
+			failed := envelope
+			if s.transforms != nil {
+				redacted := *envelope
+				redacted.Payload = s.transforms.RedactRaw(event.ClientID, event.SourceType, event.HECTokenID, envelope.Payload)
+				failed = &redacted
+			}
+			if dlqErr := s.dlq.Write(ctx, failed, err, "normalization_failed"); dlqErr != nil {
 				log.Printf("failed to write to DLQ for event %s: %v", event.ID, dlqErr)
 			}
 		}
-		return nil, fmt.Errorf("normalization failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrNormalizationFailed, err)
+	}
+
+	// log.Printf("event %s normalized via pipeline", event.ID)
+	return eventMap, nil
+}
+
+// normalizeEnvelope runs an envelope through the normalization pipeline and
+// converts the result into a storage document with ingestion context.
+func (s *IngestService) normalizeEnvelope(ctx context.Context, envelope *models.RawEventEnvelope) (map[string]interface{}, error) {
+	// Process through normalization pipeline
+	normalizedEvent, err := s.pipeline.Process(ctx, envelope)
+	if err != nil {
+		return nil, err
 	}
 
 	// Convert OCSF event to map for storage
@@ -292,39 +638,60 @@ func (s *IngestService) normalizeEvent(event *models.Event) (map[string]interfac
 	// - client_id: Multi-tenant data isolation
 	// - hec_token_id: Audit trail / nonrepudiation (which token ingested this event)
 	// - ingest_source_ip: Source IP of the ingestion request (not the event's src_endpoint)
-	if event.ClientID != "" {
-		eventMap["client_id"] = event.ClientID
+	if clientID := envelope.Attributes["client_id"]; clientID != "" {
+		eventMap["client_id"] = clientID
 	}
-	if event.HECTokenID != "" {
-		eventMap["hec_token_id"] = event.HECTokenID
+	if tokenID := envelope.Attributes["hec_token_id"]; tokenID != "" {
+		eventMap["hec_token_id"] = tokenID
 	}
-	if event.SourceIP != "" {
-		eventMap["ingest_source_ip"] = event.SourceIP
+	if sourceIP := envelope.Attributes["source_ip"]; sourceIP != "" {
+		eventMap["ingest_source_ip"] = sourceIP
 	}
 
-	// log.Printf("event %s normalized via pipeline", event.ID)
 	return eventMap, nil
 }
 
-// ocsfEventToMap converts an OCSF event to a map for storage
+// ocsfEventToMap converts an OCSF event, including its class-specific
+// attributes, to a map for storage
 func (s *IngestService) ocsfEventToMap(event *ocsf.Event) (map[string]interface{}, error) {
-	data, err := json.Marshal(event)
-	if err != nil {
-		return nil, err
-	}
+	return event.Document()
+}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, err
-	}
+// forwardToStorage applies transform rules to a normalized event, stores it
+// and, once stored, offers it to live tail. Events dropped by a rule count as
+// handled.
+func (s *IngestService) forwardToStorage(parent context.Context, sourceType string, event map[string]interface{}) error {
+	_, err := s.forwardBatchToStorage(parent, []storedEvent{{sourceType: sourceType, event: event}})
+	return err
+}
 
-	return result, nil
+// storedEvent is a normalized event with the sourcetype it was ingested as.
+type storedEvent struct {
+	sourceType string
+	event      map[string]interface{}
 }
 
-func (s *IngestService) forwardToStorage(parent context.Context, event map[string]interface{}) error {
+// forwardBatchToStorage is forwardToStorage for several events, stored in a
+// single storage request. Any failure fails the whole batch. conflicts is
+// true at the position of each created event whose document already existed
+// and that was therefore not stored.
+func (s *IngestService) forwardBatchToStorage(parent context.Context, batch []storedEvent) (conflicts []bool, err error) {
+	conflicts = make([]bool, len(batch))
+	kept := batch[:0:0]
+	keptPos := []int{}
+	for i, e := range batch {
+		if s.transforms == nil || s.transforms.Apply(e.sourceType, e.event) {
+			kept = append(kept, e)
+			keptPos = append(keptPos, i)
+		}
+	}
+	if len(kept) == 0 {
+		return conflicts, nil
+	}
+
 	if s.storageClient == nil {
 		log.Println("storage client not configured; skipping storage")
-		return nil
+		return conflicts, nil
 	}
 
 	if parent == nil {
@@ -333,21 +700,41 @@ func (s *IngestService) forwardToStorage(parent context.Context, event map[strin
 	ctx, cancel := context.WithTimeout(parent, 10*time.Second)
 	defer cancel()
 
-	events := []map[string]interface{}{event}
+	events := make([]map[string]interface{}, len(kept))
+	for i, e := range kept {
+		events[i] = e.event
+	}
 	resp, err := s.storageClient.Ingest(ctx, events)
 	if err != nil {
-		return fmt.Errorf("storage ingest failed: %w", err)
+		return conflicts, fmt.Errorf("storage ingest failed: %w", err)
 	}
 
 	if resp == nil {
-		return fmt.Errorf("storage returned nil response")
+		return conflicts, fmt.Errorf("storage returned nil response")
 	}
 
 	if resp.Failed > 0 {
-		return fmt.Errorf("storage reported %d failures: %v", resp.Failed, resp.Errors)
+		return conflicts, fmt.Errorf("storage reported %d failures: %v", resp.Failed, resp.Errors)
+	}
+
+	for _, pos := range resp.Conflicts {
+		if pos >= 0 && pos < len(kept) {
+			conflicts[keptPos[pos]] = true
+		}
+	}
+
+	if s.tail != nil {
+		for i, e := range kept {
+			if conflicts[keptPos[i]] {
+				continue
+			}
+			delete(e.event, storageclient.IDField)
+			delete(e.event, storageclient.CreateField)
+			s.tail.Publish(e.sourceType, e.event)
+		}
 	}
 
-	return nil
+	return conflicts, nil
 }
 
 func (s *IngestService) eventToMap(event *models.Event) map[string]interface{} {
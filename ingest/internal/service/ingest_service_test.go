@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/ack"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/dlq"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/otlp"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/storageclient"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/transform"
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/parsing"
+	"github.com/telhawk-systems/telhawk-stack/search/pkg/model"
+)
+
+// syncStorageClient is a storage mock safe for use from the event processor goroutine.
+type syncStorageClient struct {
+	mu     sync.Mutex
+	events []map[string]interface{}
+}
+
+func (m *syncStorageClient) Ingest(ctx context.Context, events []map[string]interface{}) (*storageclient.IngestResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, events...)
+	return &storageclient.IngestResponse{Indexed: len(events)}, nil
+}
+
+func (m *syncStorageClient) stored() []map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]map[string]interface{}(nil), m.events...)
+}
+
+func otlpRecord(sourceType string) *otlp.LogRecord {
+	return &otlp.LogRecord{
+		Timestamp:  time.Now(),
+		Host:       "web-01",
+		Source:     "billing",
+		SourceType: sourceType,
+		Payload:    map[string]interface{}{"message": "user login", "time": float64(time.Now().Unix())},
+		Attributes: map[string]string{
+			"resource.service.name": "billing",
+			"scope.name":            "auth-logger",
+			"client_id":             "spoofed", // Must not override the token's client
+		},
+	}
+}
+
+func TestIngestOTLPLog_NormalizesAndStores(t *testing.T) {
+	storage := &syncStorageClient{}
+	ingest, _ := newTestIngestService(t, storage)
+
+	tokenInfo := &TokenInfo{TokenID: "tok-1", ClientID: "client-1"}
+	_, err := ingest.IngestOTLPLog(context.Background(), otlpRecord("otlp"), "10.0.0.1", tokenInfo)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(storage.stored()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	event := storage.stored()[0]
+	assert.Equal(t, "client-1", event["client_id"])
+	assert.Equal(t, "tok-1", event["hec_token_id"])
+}
+
+func TestIngestOTLPLog_UnroutedRecordGoesToDLQWithAttributes(t *testing.T) {
+	ingest, queue := newTestIngestService(t, &syncStorageClient{})
+
+	tokenInfo := &TokenInfo{TokenID: "tok-1", ClientID: "client-1"}
+	_, err := ingest.IngestOTLPLog(context.Background(), otlpRecord("no-such-normalizer"), "10.0.0.1", tokenInfo)
+	require.NoError(t, err)
+
+	var entries []dlq.FailedEvent
+	require.Eventually(t, func() bool {
+		entries, _ = queue.Query(context.Background(), dlq.Filter{})
+		return len(entries) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	entry := entries[0]
+	assert.Equal(t, "no-such-normalizer", entry.SourceType())
+	assert.Equal(t, "billing", entry.Attribute("resource.service.name"))
+	assert.Equal(t, "auth-logger", entry.Attribute("scope.name"))
+	assert.Equal(t, "client-1", entry.Attribute("client_id"))
+	assert.Equal(t, "tok-1", entry.Attribute("hec_token_id"))
+}
+
+// ingestDocument ingests a single bulk document from 10.0.0.1.
+func ingestDocument(ingest *IngestService, source []byte, sourceType, index string, tokenInfo *TokenInfo) error {
+	docs := []Document{{Index: index, SourceType: sourceType, Source: source}}
+	return ingest.IngestDocuments(context.Background(), docs, "10.0.0.1", tokenInfo)[0]
+}
+
+func TestIngestDocuments_Batch(t *testing.T) {
+	storage := &mockStorageClient{}
+	ingest, queue := newTestIngestService(t, storage)
+	ctx := context.Background()
+
+	errs := ingest.IngestDocuments(ctx, []Document{
+		{ID: "doc-1", Index: "logs", SourceType: "hec", Source: []byte(`{"message":"first"}`)},
+		{Index: "logs", SourceType: "no-such-normalizer", Source: []byte(`{"message":"unparseable"}`)},
+		{Index: "logs", SourceType: "hec", Source: []byte(`"not an object"`)},
+		{ID: "doc-4", Index: "logs", SourceType: "hec", Source: []byte(`{"message":"fourth"}`)},
+	}, "10.0.0.1", &TokenInfo{ClientID: "client-1"})
+
+	require.Len(t, errs, 4)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrNormalizationFailed)
+	assert.ErrorIs(t, errs[2], ErrInvalidDocument)
+	assert.NoError(t, errs[3])
+
+	assert.Equal(t, 1, storage.calls, "stored documents must share one storage request")
+	require.Len(t, storage.events, 2)
+	assert.Equal(t, storedDocumentID("client-1", "doc-1"), storage.events[0][storageclient.IDField])
+	assert.Equal(t, storedDocumentID("client-1", "doc-4"), storage.events[1][storageclient.IDField])
+	assert.NotContains(t, storage.events[0], storageclient.CreateField)
+
+	entries, err := queue.Query(ctx, dlq.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.NotEmpty(t, entries[0].Envelope.ID)
+
+	t.Run("storage failure fails every stored document", func(t *testing.T) {
+		ingest, _ := newTestIngestService(t, &mockStorageClient{err: errors.New("storage down")})
+		errs := ingest.IngestDocuments(ctx, []Document{
+			{Index: "logs", SourceType: "hec", Source: []byte(`{"message":"a"}`)},
+			{Index: "logs", SourceType: "hec", Source: []byte(`[]`)},
+			{Index: "logs", SourceType: "hec", Source: []byte(`{"message":"b"}`)},
+		}, "10.0.0.1", nil)
+		assert.ErrorIs(t, errs[0], ErrStorageFailed)
+		assert.ErrorIs(t, errs[1], ErrInvalidDocument)
+		assert.ErrorIs(t, errs[2], ErrStorageFailed)
+	})
+}
+
+func TestIngestDocuments_IDsAreScopedToTheClient(t *testing.T) {
+	storage := &mockStorageClient{}
+	ingest, _ := newTestIngestService(t, storage)
+	ctx := context.Background()
+
+	doc := []Document{{ID: "fingerprint-1", Index: "logs", SourceType: "hec", Source: []byte(`{"message":"x"}`)}}
+	require.NoError(t, ingest.IngestDocuments(ctx, doc, "10.0.0.1", &TokenInfo{ClientID: "acme"})[0])
+	require.NoError(t, ingest.IngestDocuments(ctx, doc, "10.0.0.1", &TokenInfo{ClientID: "globex"})[0])
+
+	require.Len(t, storage.events, 2)
+	acme, globex := storage.events[0][storageclient.IDField], storage.events[1][storageclient.IDField]
+	assert.NotEqual(t, "fingerprint-1", acme, "shipper IDs must not be stored as-is")
+	assert.NotEqual(t, acme, globex, "clients sending the same _id must not overwrite each other")
+}
+
+func TestIngestDocuments_CreateConflict(t *testing.T) {
+	storage := &mockStorageClient{conflicts: []int{0}}
+	ingest, _ := newTestIngestService(t, storage)
+	tail := &recordingTailPublisher{}
+	ingest.SetTailPublisher(tail)
+
+	errs := ingest.IngestDocuments(context.Background(), []Document{
+		{ID: "doc-1", Create: true, Index: "logs", SourceType: "hec", Source: []byte(`{"message":"dup"}`)},
+		{ID: "doc-2", Create: true, Index: "logs", SourceType: "hec", Source: []byte(`{"message":"new"}`)},
+	}, "10.0.0.1", &TokenInfo{ClientID: "client-1"})
+
+	assert.ErrorIs(t, errs[0], ErrDocumentExists)
+	assert.NoError(t, errs[1])
+	require.Len(t, storage.events, 2)
+	assert.Equal(t, true, storage.events[0][storageclient.CreateField])
+	require.Len(t, tail.events, 1, "only the created document is published")
+	assert.NotContains(t, tail.events[0], storageclient.IDField)
+}
+
+func TestIngestDocument(t *testing.T) {
+	ctx := context.Background()
+	tokenInfo := &TokenInfo{TokenID: "tok-1", ClientID: "client-1"}
+
+	t.Run("stores normalized document", func(t *testing.T) {
+		storage := &mockStorageClient{}
+		ingest, _ := newTestIngestService(t, storage)
+
+		err := ingestDocument(ingest, []byte(`{"@timestamp":"2026-01-02T03:04:05Z","host":{"name":"web-01"},"message":"user login"}`), "hec", "filebeat-8", tokenInfo)
+		require.NoError(t, err)
+		require.Len(t, storage.events, 1)
+		assert.Equal(t, "client-1", storage.events[0]["client_id"])
+		assert.Equal(t, "10.0.0.1", storage.events[0]["ingest_source_ip"])
+	})
+
+	t.Run("rejects non-object source", func(t *testing.T) {
+		ingest, _ := newTestIngestService(t, &mockStorageClient{})
+
+		err := ingestDocument(ingest, []byte(`["not","an","object"]`), "hec", "logs", tokenInfo)
+		assert.ErrorIs(t, err, ErrInvalidDocument)
+	})
+
+	t.Run("normalization failure goes to DLQ", func(t *testing.T) {
+		ingest, queue := newTestIngestService(t, &mockStorageClient{})
+
+		err := ingestDocument(ingest, []byte(`{"message":"x"}`), "no-such-normalizer", "custom-index", tokenInfo)
+		assert.ErrorIs(t, err, ErrNormalizationFailed)
+
+		entries, err := queue.Query(ctx, dlq.Filter{})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "custom-index", entries[0].Attribute("index"))
+		assert.Equal(t, "client-1", entries[0].Attribute("client_id"))
+	})
+
+	t.Run("storage failure is retryable and not dead-lettered", func(t *testing.T) {
+		ingest, queue := newTestIngestService(t, &mockStorageClient{err: errors.New("storage down")})
+
+		err := ingestDocument(ingest, []byte(`{"message":"x"}`), "hec", "logs", tokenInfo)
+		assert.ErrorIs(t, err, ErrStorageFailed)
+
+		entries, err := queue.Query(ctx, dlq.Filter{})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+type recordingTailPublisher struct {
+	mu          sync.Mutex
+	sourceTypes []string
+	events      []map[string]interface{}
+}
+
+func (p *recordingTailPublisher) Publish(sourceType string, event map[string]interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sourceTypes = append(p.sourceTypes, sourceType)
+	p.events = append(p.events, event)
+}
+
+func TestIngestDocument_PublishesStoredEventsToTail(t *testing.T) {
+	tokenInfo := &TokenInfo{TokenID: "tok-1", ClientID: "client-1"}
+
+	t.Run("stored event is published", func(t *testing.T) {
+		ingest, _ := newTestIngestService(t, &mockStorageClient{})
+		tail := &recordingTailPublisher{}
+		ingest.SetTailPublisher(tail)
+
+		err := ingestDocument(ingest, []byte(`{"message":"user login"}`), "hec", "logs", tokenInfo)
+		require.NoError(t, err)
+		require.Len(t, tail.events, 1)
+		assert.Equal(t, "hec", tail.sourceTypes[0])
+		assert.Equal(t, "client-1", tail.events[0]["client_id"])
+	})
+
+	t.Run("failed store is not published", func(t *testing.T) {
+		ingest, _ := newTestIngestService(t, &mockStorageClient{err: errors.New("storage down")})
+		tail := &recordingTailPublisher{}
+		ingest.SetTailPublisher(tail)
+
+		err := ingestDocument(ingest, []byte(`{"message":"x"}`), "hec", "logs", tokenInfo)
+		assert.ErrorIs(t, err, ErrStorageFailed)
+		assert.Empty(t, tail.events)
+	})
+}
+
+// dropTransformer drops the events of one client.
+type dropTransformer struct {
+	clientID string
+}
+
+func (d dropTransformer) Apply(sourceType string, event map[string]interface{}) bool {
+	event["transformed"] = true
+	return event["client_id"] != d.clientID
+}
+
+func (d dropTransformer) RedactRaw(clientID, sourceType, hecTokenID string, payload []byte) []byte {
+	return payload
+}
+
+func TestIngestDocument_AppliesTransforms(t *testing.T) {
+	storage := &syncStorageClient{}
+	ingest, _ := newTestIngestService(t, storage)
+	ingest.SetTransforms(dropTransformer{clientID: "noisy"})
+
+	require.NoError(t, ingestDocument(ingest, []byte(`{"message":"kept"}`), "hec", "logs", &TokenInfo{ClientID: "client-1"}))
+	require.NoError(t, ingestDocument(ingest, []byte(`{"message":"dropped"}`), "hec", "logs", &TokenInfo{ClientID: "noisy"}))
+
+	stored := storage.stored()
+	require.Len(t, stored, 1, "dropped events must not be stored")
+	assert.Equal(t, true, stored[0]["transformed"])
+}
+
+func TestIngestDocument_RedactsRawBeforeDLQ(t *testing.T) {
+	ingest, queue := newTestIngestService(t, &syncStorageClient{})
+	transforms := transform.NewEngine(transform.NewMemoryStore(), "")
+	require.NoError(t, transforms.Create(context.Background(), "client-1", transform.Rule{
+		ID:     "cards",
+		Filter: &model.FilterExpr{Field: ".severity_id", Operator: "gte", Value: 4},
+		Action: transform.ActionRedact,
+		Redactions: []transform.Redaction{
+			{Pattern: `\d{4} \d{4} \d{4} \d{4}`, Method: transform.MethodMask},
+			{Field: "message", Method: transform.MethodRedact},
+		},
+	}))
+	ingest.SetTransforms(transforms)
+
+	source := []byte(`{"message":"paid with 4111 1111 1111 1111"}`)
+	err := ingestDocument(ingest, source, "no-such-normalizer", "logs", &TokenInfo{ClientID: "client-1"})
+	require.ErrorIs(t, err, ErrNormalizationFailed)
+
+	// The rule's filter cannot be evaluated without a normalized event, so
+	// its raw redactions apply anyway
+	entries, err := queue.Query(context.Background(), dlq.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.JSONEq(t, `{"message":"paid with ***************1111"}`, string(entries[0].Envelope.Payload))
+	assert.Contains(t, string(source), "4111 1111 1111 1111", "the caller's payload must not be modified")
+}
+
+func TestIngestRaw_AppliesParsingProfile(t *testing.T) {
+	storage := &syncStorageClient{}
+	ingest, _ := newTestIngestService(t, storage)
+
+	parsers, err := parsing.NewSet([]parsing.Profile{{
+		SourceType:      "hec",
+		ShouldLineMerge: true,
+		BreakOnlyBefore: `^\d{4}-`,
+		TimeFormat:      "%Y-%m-%d %H:%M:%S",
+		Extractions:     []string{`^\S+ \S+ (?P<level>[A-Z]+)`},
+	}})
+	require.NoError(t, err)
+	ingest.SetParsingProfiles(parsers)
+
+	data := []byte("2026-03-01 09:15:30 ERROR boom\n\tat Handler.handle\n2026-03-01 09:15:31 INFO ok\n")
+	_, err = ingest.IngestRaw(context.Background(), data, "10.0.0.1", &TokenInfo{ClientID: "client-1"}, "app.log", "hec", "web-01")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(storage.stored()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	var messages []string
+	for _, event := range storage.stored() {
+		raw := event["raw"].(map[string]interface{})["data"].(map[string]interface{})
+		messages = append(messages, raw["message"].(string))
+		if raw["level"] == "ERROR" {
+			assert.Equal(t, "2026-03-01T09:15:30Z", event["time"])
+		}
+	}
+	assert.ElementsMatch(t, []string{"2026-03-01 09:15:30 ERROR boom\n\tat Handler.handle", "2026-03-01 09:15:31 INFO ok"}, messages)
+}
+
+func TestIngestRaw_ParsedEventsShareAck(t *testing.T) {
+	ingest, _ := newTestIngestService(t, &syncStorageClient{})
+	acks := ack.NewManager(time.Minute) // Closed by the service's Stop
+	ingest.SetAckManager(acks)
+
+	parsers, err := parsing.NewSet([]parsing.Profile{{SourceType: "hec"}})
+	require.NoError(t, err)
+	ingest.SetParsingProfiles(parsers)
+
+	ackID, err := ingest.IngestRaw(context.Background(), []byte("one\ntwo\nthree\n"), "10.0.0.1", nil, "app.log", "hec", "web-01")
+	require.NoError(t, err)
+	require.NotEmpty(t, ackID)
+
+	require.Eventually(t, func() bool { return ingest.QueryAcks([]string{ackID})[ackID] }, 2*time.Second, 10*time.Millisecond)
+	assert.Len(t, acks.Query([]string{ackID}), 1)
+	assert.Zero(t, acks.GetPending())
+}
+
+func TestIngestRaw_WithoutProfileKeepsPayload(t *testing.T) {
+	ingest, queue := newTestIngestService(t, &syncStorageClient{})
+
+	_, err := ingest.IngestRaw(context.Background(), []byte("line one\nline two"), "10.0.0.1", nil, "app.log", "unprofiled", "web-01")
+	require.NoError(t, err)
+
+	// No profile and no normalizer: the body stays one event and lands in the DLQ
+	var entries []dlq.FailedEvent
+	require.Eventually(t, func() bool {
+		entries, _ = queue.Query(context.Background(), dlq.Filter{})
+		return len(entries) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "line one\nline two", string(entries[0].Envelope.Payload))
+}
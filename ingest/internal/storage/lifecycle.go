@@ -0,0 +1,307 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/telhawk-systems/telhawk-stack/common/indices"
+)
+
+const (
+	// defaultPolicyPriority and clientPolicyPriority order the ISM templates so
+	// a client's own policy wins over the default for its index series.
+	defaultPolicyPriority = 100
+	clientPolicyPriority  = 200
+)
+
+// LifecycleConfig configures the tiers indices move through between rollover
+// and deletion. Ages are measured from index creation.
+type LifecycleConfig struct {
+	Warm            WarmTier
+	Cold            ColdTier
+	Snapshot        SnapshotConfig
+	ClientRetention []ClientRetention
+}
+
+// WarmTier moves indices off hot storage: fewer replicas, force-merged and
+// read-only.
+type WarmTier struct {
+	AfterDays          int // 0 disables the warm tier
+	Replicas           int
+	ForceMergeSegments int // 0 skips the force merge
+}
+
+// ColdTier allocates read-only indices to nodes carrying a node attribute,
+// e.g. node.attr.temp=cold.
+type ColdTier struct {
+	AfterDays      int // 0 disables the cold tier
+	NodeAttribute  string
+	AttributeValue string
+	Replicas       int
+}
+
+// SnapshotConfig snapshots each index to a registered repository before it is
+// deleted.
+type SnapshotConfig struct {
+	Repository string // Empty deletes without a snapshot
+}
+
+// ClientRetention overrides RetentionDays for one client's index series.
+// Clients only have their own series in client routing mode.
+type ClientRetention struct {
+	ClientID      string
+	RetentionDays int
+}
+
+// createISMPolicies creates or updates the default ISM policy and one policy
+// per client retention override.
+func (c *Client) createISMPolicies(ctx context.Context) error {
+	// In shared mode every client's events live in the default series, so an
+	// override would create a policy for indices that are never written
+	if len(c.config.Lifecycle.ClientRetention) > 0 && c.config.RoutingMode != indices.RoutingClient {
+		return fmt.Errorf("client retention overrides require routing mode %q", indices.RoutingClient)
+	}
+
+	if repo := c.config.Lifecycle.Snapshot.Repository; repo != "" {
+		if err := c.checkSnapshotRepository(ctx, repo); err != nil {
+			return err
+		}
+	}
+
+	policy, err := c.buildISMPolicy(c.config.RetentionDays, c.config.IndexPrefix+"-*", defaultPolicyPriority)
+	if err != nil {
+		return err
+	}
+	if err := c.putISMPolicy(ctx, c.config.IndexPrefix+"-policy", policy); err != nil {
+		return err
+	}
+
+	for _, override := range c.config.Lifecycle.ClientRetention {
+		if override.ClientID == "" {
+			return fmt.Errorf("client retention override is missing client_id")
+		}
+		if err := c.putClientISMPolicy(ctx, override.ClientID); err != nil {
+			return fmt.Errorf("client %s: %w", override.ClientID, err)
+		}
+	}
+	return nil
+}
+
+// putClientISMPolicy creates or updates the policy for a client's own index
+// series, using the client's retention override if it has one.
+func (c *Client) putClientISMPolicy(ctx context.Context, clientID string) error {
+	retentionDays := c.config.RetentionDays
+	for _, override := range c.config.Lifecycle.ClientRetention {
+		if override.ClientID == clientID {
+			retentionDays = override.RetentionDays
+		}
+	}
+	prefix, err := c.ClientIndexPrefix(clientID)
+	if err != nil {
+		return err
+	}
+	policy, err := c.buildISMPolicy(retentionDays, prefix+"-*", clientPolicyPriority)
+	if err != nil {
+		return err
+	}
+	return c.putISMPolicy(ctx, prefix+"-policy", policy)
+}
+
+// buildISMPolicy builds the hot -> warm -> cold -> delete policy for indices
+// matching pattern. Tiers that would start after retentionDays are skipped.
+func (c *Client) buildISMPolicy(retentionDays int, pattern string, priority int) (map[string]interface{}, error) {
+	lc := c.config.Lifecycle
+	if retentionDays <= 0 {
+		return nil, fmt.Errorf("retention days must be positive, got %d", retentionDays)
+	}
+	if lc.Warm.AfterDays > 0 && lc.Cold.AfterDays > 0 && lc.Cold.AfterDays <= lc.Warm.AfterDays {
+		return nil, fmt.Errorf("cold tier (%dd) must start after warm tier (%dd)", lc.Cold.AfterDays, lc.Warm.AfterDays)
+	}
+	if lc.Cold.AfterDays > 0 && (lc.Cold.NodeAttribute == "" || lc.Cold.AttributeValue == "") {
+		return nil, fmt.Errorf("cold tier requires node_attribute and attribute_value")
+	}
+
+	type tier struct {
+		name      string
+		afterDays int
+		actions   []map[string]interface{}
+	}
+	var tiers []tier
+
+	if lc.Warm.AfterDays > 0 && lc.Warm.AfterDays < retentionDays {
+		actions := []map[string]interface{}{
+			{"replica_count": map[string]interface{}{"number_of_replicas": lc.Warm.Replicas}},
+		}
+		if lc.Warm.ForceMergeSegments > 0 {
+			actions = append(actions, map[string]interface{}{
+				"force_merge": map[string]interface{}{"max_num_segments": lc.Warm.ForceMergeSegments},
+			})
+		}
+		actions = append(actions, map[string]interface{}{"read_only": map[string]interface{}{}})
+		tiers = append(tiers, tier{"warm", lc.Warm.AfterDays, actions})
+	}
+
+	if lc.Cold.AfterDays > 0 && lc.Cold.AfterDays < retentionDays {
+		actions := []map[string]interface{}{
+			{"replica_count": map[string]interface{}{"number_of_replicas": lc.Cold.Replicas}},
+			{"allocation": map[string]interface{}{
+				"require":  map[string]interface{}{lc.Cold.NodeAttribute: lc.Cold.AttributeValue},
+				"wait_for": true,
+			}},
+		}
+		// Indices may skip warm (or warm may be disabled); cold is always read-only
+		if len(tiers) == 0 {
+			actions = append(actions, map[string]interface{}{"read_only": map[string]interface{}{}})
+		}
+		tiers = append(tiers, tier{"cold", lc.Cold.AfterDays, actions})
+	}
+
+	deleteActions := []map[string]interface{}{{"delete": map[string]interface{}{}}}
+	if lc.Snapshot.Repository != "" {
+		deleteActions = append([]map[string]interface{}{{
+			"snapshot": map[string]interface{}{
+				"repository": lc.Snapshot.Repository,
+				"snapshot":   "{{ctx.index}}",
+			},
+		}}, deleteActions...)
+	}
+	tiers = append(tiers, tier{"delete", retentionDays, deleteActions})
+
+	transitionTo := func(t tier) []map[string]interface{} {
+		return []map[string]interface{}{{
+			"state_name": t.name,
+			"conditions": map[string]interface{}{"min_index_age": fmt.Sprintf("%dd", t.afterDays)},
+		}}
+	}
+
+	states := []map[string]interface{}{{
+		"name": "hot",
+		"actions": []map[string]interface{}{{
+			"rollover": map[string]interface{}{
+				"min_size":      fmt.Sprintf("%dGB", c.config.RolloverSizeGB),
+				"min_index_age": formatDurationForOpenSearch(c.config.RolloverAge),
+			},
+		}},
+		"transitions": transitionTo(tiers[0]),
+	}}
+	for i, t := range tiers {
+		state := map[string]interface{}{
+			"name":        t.name,
+			"actions":     t.actions,
+			"transitions": []map[string]interface{}{},
+		}
+		if i+1 < len(tiers) {
+			state["transitions"] = transitionTo(tiers[i+1])
+		}
+		states = append(states, state)
+	}
+
+	return map[string]interface{}{
+		"policy": map[string]interface{}{
+			"description":   "TelHawk events index lifecycle policy",
+			"default_state": "hot",
+			"states":        states,
+			"ism_template": []map[string]interface{}{{
+				"index_patterns": []string{pattern},
+				"priority":       priority,
+			}},
+		},
+	}, nil
+}
+
+// checkSnapshotRepository verifies a snapshot repository is registered, so
+// indices are not left stuck in the delete state by a failing snapshot.
+func (c *Client) checkSnapshotRepository(ctx context.Context, repository string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "/_snapshot/"+repository, http.NoBody)
+	if err != nil {
+		return err
+	}
+	res, err := c.osClient.Transport.Perform(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("snapshot repository %q is not registered", repository)
+	}
+	if res.StatusCode >= 400 {
+		bodyBytes, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("failed to check snapshot repository: %d - %s", res.StatusCode, string(bodyBytes))
+	}
+	return nil
+}
+
+// putISMPolicy creates the named policy or updates it if it already exists.
+func (c *Client) putISMPolicy(ctx context.Context, policyName string, policy map[string]interface{}) error {
+	body, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	// Check if policy exists
+	req := c.osClient.Transport.Perform
+	checkReq, err := http.NewRequestWithContext(ctx, "GET", "/_plugins/_ism/policies/"+policyName, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	checkRes, err := req(checkReq)
+	if err != nil {
+		return err
+	}
+	checkBody, err := io.ReadAll(checkRes.Body)
+	if err != nil {
+		checkRes.Body.Close()
+		return fmt.Errorf("failed to read ISM policy check response: %w", err)
+	}
+	checkRes.Body.Close()
+
+	// If policy exists (200), update it. Otherwise create it (404)
+	method := "PUT"
+	url := "/_plugins/_ism/policies/" + policyName
+	if checkRes.StatusCode == 200 {
+		// Policy exists; parse current _seq_no and _primary_term for optimistic concurrency control
+		var existing map[string]interface{}
+		if err := json.Unmarshal(checkBody, &existing); err != nil {
+			return fmt.Errorf("failed to parse ISM policy response: %w", err)
+		}
+		seqNo := existing["_seq_no"]
+		primaryTerm := existing["_primary_term"]
+		url += fmt.Sprintf("?if_seq_no=%v&if_primary_term=%v", seqNo, primaryTerm)
+	}
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		method,
+		url,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	res, err := req(httpReq)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// Accept 200 (updated), 201 (created), or 409 (already exists with same content)
+	if res.StatusCode >= 400 && res.StatusCode != 409 {
+		bodyBytes, err := io.ReadAll(res.Body)
+		if err != nil {
+			return fmt.Errorf("failed to create ISM policy: %d (could not read body: %v)", res.StatusCode, err)
+		}
+		return fmt.Errorf("failed to create ISM policy: %d - %s", res.StatusCode, string(bodyBytes))
+	}
+
+	log.Printf("ISM policy %s created/updated successfully", policyName)
+	return nil
+}
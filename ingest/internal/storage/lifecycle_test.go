@@ -0,0 +1,347 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telhawk-systems/telhawk-stack/common/indices"
+)
+
+// fakeOpenSearch is a local stand-in for the OpenSearch endpoints the client
+// uses during Initialize.
+type fakeOpenSearch struct {
+	mu           sync.Mutex
+	templates    map[string]map[string]interface{}
+	policies     map[string]map[string]interface{}
+	policyWrites map[string]string // policy -> last write URL
+	repositories map[string]bool
+	indices      map[string]bool
+	bulked       []string // target index of each bulk-indexed document
+	bulkedIDs    []string // _id of each bulk-indexed document ("" if generated)
+	bulkedDocs   []string // source of each bulk-indexed document
+}
+
+func newFakeOpenSearch(t *testing.T) (*fakeOpenSearch, *httptest.Server) {
+	f := &fakeOpenSearch{
+		templates:    make(map[string]map[string]interface{}),
+		policies:     make(map[string]map[string]interface{}),
+		policyWrites: make(map[string]string),
+		repositories: make(map[string]bool),
+		indices:      make(map[string]bool),
+	}
+	server := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeOpenSearch) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	decode := func() map[string]interface{} {
+		var body map[string]interface{}
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		return body
+	}
+	path := r.URL.Path
+
+	switch {
+	case path == "/" && r.Method == http.MethodGet:
+		_, _ = io.WriteString(w, `{"version": {"distribution": "opensearch", "number": "2.11.0"}}`)
+
+	case strings.HasPrefix(path, "/_index_template/") && r.Method == http.MethodPut:
+		f.templates[strings.TrimPrefix(path, "/_index_template/")] = decode()
+		_, _ = io.WriteString(w, `{"acknowledged": true}`)
+
+	case strings.HasPrefix(path, "/_plugins/_ism/policies/"):
+		name := strings.TrimPrefix(path, "/_plugins/_ism/policies/")
+		switch r.Method {
+		case http.MethodGet:
+			if _, ok := f.policies[name]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = io.WriteString(w, `{"_id": "`+name+`", "_seq_no": 7, "_primary_term": 1}`)
+		case http.MethodPut:
+			f.policies[name] = decode()
+			f.policyWrites[name] = r.URL.String()
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"_id": "`+name+`"}`)
+		}
+
+	case strings.HasPrefix(path, "/_snapshot/") && r.Method == http.MethodGet:
+		name := strings.TrimPrefix(path, "/_snapshot/")
+		if !f.repositories[name] {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error": {"type": "repository_missing_exception"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"`+name+`": {"type": "fs"}}`)
+
+	case strings.HasSuffix(path, "/_bulk") && r.Method == http.MethodPost:
+		f.serveBulk(w, r)
+
+	case path == "/_aliases" && r.Method == http.MethodPost:
+		_, _ = io.WriteString(w, `{"acknowledged": true}`)
+
+	case r.Method == http.MethodHead:
+		if !f.indices[strings.TrimPrefix(path, "/")] {
+			w.WriteHeader(http.StatusNotFound)
+		}
+
+	case r.Method == http.MethodPut:
+		f.indices[strings.TrimPrefix(path, "/")] = true
+		_, _ = io.WriteString(w, `{"acknowledged": true}`)
+
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"error": "unexpected request `+r.Method+` `+path+`"}`)
+	}
+}
+
+// serveBulk records the target of each action in an NDJSON bulk request,
+// falling back to the index in the URL, and reports every item indexed, or a
+// 409 conflict for a create of an ID already bulked.
+func (f *fakeOpenSearch) serveBulk(w http.ResponseWriter, r *http.Request) {
+	defaultIndex := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/_bulk")
+	var items []map[string]interface{}
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var action map[string]map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+			continue
+		}
+		name := "index"
+		if action["create"] != nil {
+			name = "create"
+		}
+		meta := action[name]
+		if meta == nil {
+			continue
+		}
+		index, _ := meta["_index"].(string)
+		if index == "" {
+			index = defaultIndex
+		}
+		id, _ := meta["_id"].(string)
+		scanner.Scan() // document line
+		if name == "create" && slices.Contains(f.bulkedIDs, id) {
+			items = append(items, map[string]interface{}{name: map[string]interface{}{
+				"_index": index, "status": 409,
+				"error": map[string]interface{}{"type": "version_conflict_engine_exception", "reason": "document already exists"},
+			}})
+			continue
+		}
+		f.bulked = append(f.bulked, index)
+		f.bulkedIDs = append(f.bulkedIDs, id)
+		f.bulkedDocs = append(f.bulkedDocs, scanner.Text())
+		items = append(items, map[string]interface{}{name: map[string]interface{}{"_index": index, "status": 201}})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"errors": false, "items": items})
+}
+
+func (f *fakeOpenSearch) policy(name string) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.policies[name]
+}
+
+func newTestClient(t *testing.T, url string, lc LifecycleConfig) *Client {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.URL = url
+	cfg.RetentionDays = 395
+	cfg.Lifecycle = lc
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+	return client
+}
+
+// policyStates returns each state's actions (by action name) and its
+// transition target, keyed by state name.
+func policyStates(t *testing.T, policy map[string]interface{}) (names []string, actions map[string][]string, next map[string]string) {
+	t.Helper()
+	actions = make(map[string][]string)
+	next = make(map[string]string)
+	states := policy["policy"].(map[string]interface{})["states"].([]interface{})
+	for _, s := range states {
+		state := s.(map[string]interface{})
+		name := state["name"].(string)
+		names = append(names, name)
+		for _, a := range state["actions"].([]interface{}) {
+			for action := range a.(map[string]interface{}) {
+				actions[name] = append(actions[name], action)
+			}
+		}
+		if transitions := state["transitions"].([]interface{}); len(transitions) > 0 {
+			tr := transitions[0].(map[string]interface{})
+			next[name] = tr["state_name"].(string) + "@" + tr["conditions"].(map[string]interface{})["min_index_age"].(string)
+		}
+	}
+	return names, actions, next
+}
+
+func TestInitialize_CreatesTieredPolicy(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+	fake.repositories["telhawk-archive"] = true
+
+	client := newTestClient(t, server.URL, LifecycleConfig{
+		Warm:     WarmTier{AfterDays: 7, Replicas: 0, ForceMergeSegments: 1},
+		Cold:     ColdTier{AfterDays: 30, NodeAttribute: "temp", AttributeValue: "cold"},
+		Snapshot: SnapshotConfig{Repository: "telhawk-archive"},
+	})
+	require.NoError(t, client.Initialize(context.Background()))
+
+	policy := fake.policy("telhawk-events-policy")
+	require.NotNil(t, policy)
+
+	names, actions, next := policyStates(t, policy)
+	assert.Equal(t, []string{"hot", "warm", "cold", "delete"}, names)
+	assert.Equal(t, []string{"rollover"}, actions["hot"])
+	assert.Equal(t, []string{"replica_count", "force_merge", "read_only"}, actions["warm"])
+	assert.Equal(t, []string{"replica_count", "allocation"}, actions["cold"])
+	assert.Equal(t, []string{"snapshot", "delete"}, actions["delete"])
+	assert.Equal(t, map[string]string{"hot": "warm@7d", "warm": "cold@30d", "cold": "delete@395d"}, next)
+
+	cold := policy["policy"].(map[string]interface{})["states"].([]interface{})[2].(map[string]interface{})
+	allocation := cold["actions"].([]interface{})[1].(map[string]interface{})["allocation"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"temp": "cold"}, allocation["require"])
+
+	ismTemplate := policy["policy"].(map[string]interface{})["ism_template"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{"telhawk-events-*"}, ismTemplate["index_patterns"])
+
+	settings := fake.templates["telhawk-events-template"]["template"].(map[string]interface{})["settings"].(map[string]interface{})
+	assert.Equal(t, "telhawk-events-write", settings["plugins.index_state_management.rollover_alias"])
+}
+
+func TestInitialize_DefaultPolicyIsHotThenDelete(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+
+	client := newTestClient(t, server.URL, LifecycleConfig{})
+	require.NoError(t, client.Initialize(context.Background()))
+
+	names, actions, next := policyStates(t, fake.policy("telhawk-events-policy"))
+	assert.Equal(t, []string{"hot", "delete"}, names)
+	assert.Equal(t, []string{"delete"}, actions["delete"])
+	assert.Equal(t, map[string]string{"hot": "delete@395d"}, next)
+}
+
+func TestInitialize_ClientRetentionOverrides(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+
+	client := newTestClient(t, server.URL, LifecycleConfig{
+		Warm: WarmTier{AfterDays: 7},
+		Cold: ColdTier{AfterDays: 30, NodeAttribute: "temp", AttributeValue: "cold"},
+		ClientRetention: []ClientRetention{
+			{ClientID: "acme_corp", RetentionDays: 730},
+			{ClientID: "short", RetentionDays: 14},
+		},
+	})
+	client.config.RoutingMode = indices.RoutingClient
+	require.NoError(t, client.Initialize(context.Background()))
+
+	acme := fake.policy("telhawk-events-acme_corp.-policy")
+	require.NotNil(t, acme)
+	_, _, next := policyStates(t, acme)
+	assert.Equal(t, "delete@730d", next["cold"])
+
+	ismTemplate := acme["policy"].(map[string]interface{})["ism_template"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{"telhawk-events-acme_corp.-*"}, ismTemplate["index_patterns"])
+	assert.EqualValues(t, clientPolicyPriority, ismTemplate["priority"])
+
+	// Tiers that would start after retention are skipped; warm stays read-only
+	names, actions, next := policyStates(t, fake.policy("telhawk-events-short.-policy"))
+	assert.Equal(t, []string{"hot", "warm", "delete"}, names)
+	assert.Contains(t, actions["warm"], "read_only")
+	assert.Equal(t, "delete@14d", next["warm"])
+}
+
+func TestInitialize_ClientRetentionRequiresClientRouting(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+
+	client := newTestClient(t, server.URL, LifecycleConfig{
+		ClientRetention: []ClientRetention{{ClientID: "acme", RetentionDays: 730}},
+	})
+	err := client.Initialize(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `client retention overrides require routing mode "client"`)
+	assert.Nil(t, fake.policy("telhawk-events-acme.-policy"))
+}
+
+func TestInitialize_UpdatesExistingPolicy(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+	fake.policies["telhawk-events-policy"] = map[string]interface{}{}
+
+	client := newTestClient(t, server.URL, LifecycleConfig{})
+	require.NoError(t, client.Initialize(context.Background()))
+
+	assert.Equal(t, "/_plugins/_ism/policies/telhawk-events-policy?if_seq_no=7&if_primary_term=1",
+		fake.policyWrites["telhawk-events-policy"])
+}
+
+func TestInitialize_UnregisteredSnapshotRepository(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+
+	client := newTestClient(t, server.URL, LifecycleConfig{Snapshot: SnapshotConfig{Repository: "missing"}})
+	err := client.Initialize(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `snapshot repository "missing" is not registered`)
+	assert.Nil(t, fake.policy("telhawk-events-policy"))
+}
+
+func TestBuildISMPolicy_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		lifecycle LifecycleConfig
+		retention int
+		wantErr   string
+	}{
+		{"zero retention", LifecycleConfig{}, 0, "retention days must be positive"},
+		{"cold before warm", LifecycleConfig{
+			Warm: WarmTier{AfterDays: 30},
+			Cold: ColdTier{AfterDays: 7, NodeAttribute: "temp", AttributeValue: "cold"},
+		}, 90, "cold tier (7d) must start after warm tier (30d)"},
+		{"cold without attribute", LifecycleConfig{Cold: ColdTier{AfterDays: 30}}, 90, "cold tier requires node_attribute"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, "http://localhost:9200", tt.lifecycle)
+			_, err := client.buildISMPolicy(tt.retention, "telhawk-events-*", defaultPolicyPriority)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestBuildISMPolicy_ColdWithoutWarmIsReadOnly(t *testing.T) {
+	client := newTestClient(t, "http://localhost:9200", LifecycleConfig{
+		Cold: ColdTier{AfterDays: 30, NodeAttribute: "temp", AttributeValue: "cold"},
+	})
+	policy, err := client.buildISMPolicy(90, "telhawk-events-*", defaultPolicyPriority)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(policy)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	names, actions, _ := policyStates(t, decoded)
+	assert.Equal(t, []string{"hot", "cold", "delete"}, names)
+	assert.Equal(t, []string{"replica_count", "allocation", "read_only"}, actions["cold"])
+}
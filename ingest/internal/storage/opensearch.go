@@ -31,6 +31,10 @@ type Config struct {
 	RetentionDays   int
 	RolloverSizeGB  int
 	RolloverAge     time.Duration
+	Lifecycle       LifecycleConfig
+	// RoutingMode is indices.RoutingShared (default) or indices.RoutingClient,
+	// which writes each client's events to its own alias and index series.
+	RoutingMode string
 }
 
 // DefaultConfig returns sensible defaults for OpenSearch configuration
@@ -57,6 +61,10 @@ type Client struct {
 	initialized   bool
 	bulkIndexer   opensearchutil.BulkIndexer
 	bulkIndexerMu sync.Mutex
+
+	clientsMu sync.Mutex
+	onboarded map[string]bool // Client IDs with an index series (client routing)
+	routed    map[string]bool // Prefixes of routed index series that exist
 }
 
 // NewClient creates a new direct OpenSearch client
@@ -82,8 +90,10 @@ func NewClient(cfg Config) (*Client, error) {
 	}
 
 	return &Client{
-		osClient: client,
-		config:   cfg,
+		osClient:  client,
+		config:    cfg,
+		onboarded: make(map[string]bool),
+		routed:    make(map[string]bool),
 	}, nil
 }
 
@@ -106,15 +116,15 @@ func (c *Client) Initialize(ctx context.Context) error {
 
 	log.Println("Connected to OpenSearch successfully")
 
-	if err := c.createIndexTemplate(ctx); err != nil {
+	if err := c.putIndexTemplate(ctx, c.config.IndexPrefix, c.GetWriteAlias(), sharedTemplatePriority); err != nil {
 		return fmt.Errorf("failed to create index template: %w", err)
 	}
 
-	if err := c.createISMPolicy(ctx); err != nil {
+	if err := c.createISMPolicies(ctx); err != nil {
 		return fmt.Errorf("failed to create ISM policy: %w", err)
 	}
 
-	if err := c.createInitialIndex(ctx); err != nil {
+	if err := c.createInitialIndex(ctx, c.config.IndexPrefix, c.GetWriteAlias()); err != nil {
 		return fmt.Errorf("failed to create initial index: %w", err)
 	}
 
@@ -166,7 +176,11 @@ func (c *Client) Close() error {
 	return nil
 }
 
-// Ingest implements the storageclient interface for direct OpenSearch indexing
+// Ingest implements the storageclient interface for direct OpenSearch indexing.
+// Events with an IDField are stored by one bulk request before Ingest
+// returns, so their results (including create conflicts) are in the
+// response; other events go through the shared bulk indexer. The caller's
+// event maps keep their IDField and CreateField.
 func (c *Client) Ingest(ctx context.Context, events []map[string]interface{}) (*storageclient.IngestResponse, error) {
 	if c.osClient == nil {
 		return nil, fmt.Errorf("opensearch client not initialized")
@@ -182,8 +196,26 @@ func (c *Client) Ingest(ctx context.Context, events []map[string]interface{}) (*
 
 	resp := &storageclient.IngestResponse{}
 	var respMu sync.Mutex
+	var identified []identifiedDoc
+
+	for pos, event := range events {
+		docID, _ := event[storageclient.IDField].(string)
+		create, _ := event[storageclient.CreateField].(bool)
+		if docID != "" || create {
+			event = withoutFields(event, storageclient.IDField, storageclient.CreateField)
+		}
+
+		// With client routing, events go to their client's write alias, and
+		// transform rules may route them to another series
+		index, err := c.indexFor(ctx, event)
+		if err != nil {
+			respMu.Lock()
+			resp.Failed++
+			resp.Errors = append(resp.Errors, err.Error())
+			respMu.Unlock()
+			continue
+		}
 
-	for _, event := range events {
 		data, err := json.Marshal(event)
 		if err != nil {
 			respMu.Lock()
@@ -198,8 +230,18 @@ func (c *Client) Ingest(ctx context.Context, events []map[string]interface{}) (*
 			log.Printf("=== DEBUG: JSON being indexed to OpenSearch ===\n%s\n=== END DEBUG ===", string(data))
 		}
 
+		if docID != "" {
+			action := "index"
+			if create {
+				action = "create"
+			}
+			identified = append(identified, identifiedDoc{pos: pos, action: action, index: index, id: docID, body: data})
+			continue
+		}
+
 		err = bi.Add(ctx, opensearchutil.BulkIndexerItem{
 			Action: "index",
+			Index:  index,
 			Body:   bytes.NewReader(data),
 			OnSuccess: func(ctx context.Context, item opensearchutil.BulkIndexerItem, res opensearchutil.BulkIndexerResponseItem) {
 				respMu.Lock()
@@ -226,11 +268,94 @@ func (c *Client) Ingest(ctx context.Context, events []map[string]interface{}) (*
 		}
 	}
 
+	if len(identified) > 0 {
+		respMu.Lock()
+		c.bulkIdentified(ctx, identified, resp)
+		respMu.Unlock()
+	}
+
 	// Note: We don't close the bulk indexer here - it flushes automatically
 	// based on FlushBytes and FlushInterval settings
 	return resp, nil
 }
 
+// identifiedDoc is an event stored under a caller-chosen document ID.
+type identifiedDoc struct {
+	pos    int // Position in the ingested events
+	action string
+	index  string // Empty for the default write alias
+	id     string
+	body   []byte
+}
+
+// bulkIdentified stores docs in one bulk request and records each result in
+// resp. A create of an existing document is a conflict rather than a failure.
+func (c *Client) bulkIdentified(ctx context.Context, docs []identifiedDoc, resp *storageclient.IngestResponse) {
+	var buf bytes.Buffer
+	for _, d := range docs {
+		meta := map[string]interface{}{"_id": d.id}
+		if d.index != "" {
+			meta["_index"] = d.index
+		}
+		line, _ := json.Marshal(map[string]interface{}{d.action: meta})
+		buf.Write(line)
+		buf.WriteByte('\n')
+		buf.Write(d.body)
+		buf.WriteByte('\n')
+	}
+
+	fail := func(err string) {
+		resp.Failed += len(docs)
+		resp.Errors = append(resp.Errors, err)
+	}
+
+	res, err := c.osClient.Bulk(&buf,
+		c.osClient.Bulk.WithContext(ctx),
+		c.osClient.Bulk.WithIndex(c.GetWriteAlias()),
+	)
+	if err != nil {
+		fail(fmt.Sprintf("bulk request failed: %v", err))
+		return
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		fail(fmt.Sprintf("bulk request failed: %s", res.Status()))
+		return
+	}
+
+	var body struct {
+		Items []map[string]opensearchutil.BulkIndexerResponseItem `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil || len(body.Items) != len(docs) {
+		fail("bulk response does not match the request")
+		return
+	}
+	for i, d := range docs {
+		item := body.Items[i][d.action]
+		switch {
+		case item.Status >= 200 && item.Status < 300:
+			resp.Indexed++
+		case item.Status == http.StatusConflict && d.action == "create":
+			resp.Conflicts = append(resp.Conflicts, d.pos)
+		default:
+			resp.Failed++
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %s", item.Error.Type, item.Error.Reason))
+		}
+	}
+}
+
+// withoutFields returns a shallow copy of event without the given keys.
+func withoutFields(event map[string]interface{}, keys ...string) map[string]interface{} {
+	out := make(map[string]interface{}, len(event))
+	for k, v := range event {
+		out[k] = v
+	}
+	for _, k := range keys {
+		delete(out, k)
+	}
+	return out
+}
+
 // GetWriteAlias returns the write alias for the index
 func (c *Client) GetWriteAlias() string {
 	return c.config.IndexPrefix + "-write"
@@ -238,23 +363,31 @@ func (c *Client) GetWriteAlias() string {
 
 // GetCurrentWriteIndex returns the current write index name
 func (c *Client) GetCurrentWriteIndex() string {
+	return currentWriteIndex(c.config.IndexPrefix)
+}
+
+func currentWriteIndex(prefix string) string {
 	timestamp := time.Now().Format("2006.01.02")
-	return fmt.Sprintf("%s-%s-000001", c.config.IndexPrefix, timestamp)
+	return fmt.Sprintf("%s-%s-000001", prefix, timestamp)
 }
 
-func (c *Client) createIndexTemplate(ctx context.Context) error {
+// putIndexTemplate creates or updates the template for the index series
+// under prefix, whose rollover target is writeAlias.
+func (c *Client) putIndexTemplate(ctx context.Context, prefix, writeAlias string, priority int) error {
 	template := map[string]interface{}{
-		"index_patterns": []string{c.config.IndexPrefix + "-*"},
+		"index_patterns": []string{prefix + "-*"},
 		"template": map[string]interface{}{
 			"settings": map[string]interface{}{
 				"number_of_shards":   c.config.ShardCount,
 				"number_of_replicas": c.config.ReplicaCount,
 				"refresh_interval":   c.config.RefreshInterval,
 				"codec":              "best_compression",
+				// Lets the ISM rollover action find the write alias
+				"plugins.index_state_management.rollover_alias": writeAlias,
 			},
 			"mappings": c.getOCSFMappings(),
 		},
-		"priority": 100,
+		"priority": priority,
 	}
 
 	body, err := json.Marshal(template)
@@ -263,8 +396,9 @@ func (c *Client) createIndexTemplate(ctx context.Context) error {
 	}
 
 	res, err := c.osClient.Indices.PutIndexTemplate(
-		c.config.IndexPrefix+"-template",
+		prefix+"-template",
 		bytes.NewReader(body),
+		c.osClient.Indices.PutIndexTemplate.WithContext(ctx),
 	)
 	if err != nil {
 		return err
@@ -276,7 +410,7 @@ func (c *Client) createIndexTemplate(ctx context.Context) error {
 		return fmt.Errorf("failed to create index template: %s - %s", res.Status(), string(bodyBytes))
 	}
 
-	log.Println("Index template created/updated successfully")
+	log.Printf("Index template %s-template created/updated successfully", prefix)
 	return nil
 }
 
@@ -585,115 +719,10 @@ func (c *Client) getOCSFMappings() map[string]interface{} {
 	}
 }
 
-func (c *Client) createISMPolicy(ctx context.Context) error {
-	policy := map[string]interface{}{
-		"policy": map[string]interface{}{
-			"description":   "TelHawk events index lifecycle policy",
-			"default_state": "hot",
-			"states": []map[string]interface{}{
-				{
-					"name": "hot",
-					"actions": []map[string]interface{}{
-						{
-							"rollover": map[string]interface{}{
-								"min_size":      fmt.Sprintf("%dGB", c.config.RolloverSizeGB),
-								"min_index_age": formatDurationForOpenSearch(c.config.RolloverAge),
-							},
-						},
-					},
-					"transitions": []map[string]interface{}{
-						{
-							"state_name": "delete",
-							"conditions": map[string]interface{}{
-								"min_index_age": fmt.Sprintf("%dd", c.config.RetentionDays),
-							},
-						},
-					},
-				},
-				{
-					"name": "delete",
-					"actions": []map[string]interface{}{
-						{
-							"delete": map[string]interface{}{},
-						},
-					},
-				},
-			},
-		},
-	}
-
-	body, err := json.Marshal(policy)
-	if err != nil {
-		return err
-	}
-
-	policyName := c.config.IndexPrefix + "-policy"
-
-	// Check if policy exists
-	req := c.osClient.Transport.Perform
-	checkReq, err := http.NewRequestWithContext(ctx, "GET", "/_plugins/_ism/policies/"+policyName, http.NoBody)
-	if err != nil {
-		return err
-	}
-
-	checkRes, err := req(checkReq)
-	if err != nil {
-		return err
-	}
-	checkBody, err := io.ReadAll(checkRes.Body)
-	if err != nil {
-		checkRes.Body.Close()
-		return fmt.Errorf("failed to read ISM policy check response: %w", err)
-	}
-	checkRes.Body.Close()
-
-	// If policy exists (200), update it. Otherwise create it (404)
-	method := "PUT"
-	url := "/_plugins/_ism/policies/" + policyName
-	if checkRes.StatusCode == 200 {
-		// Policy exists; parse current _seq_no and _primary_term for optimistic concurrency control
-		var existing map[string]interface{}
-		if err := json.Unmarshal(checkBody, &existing); err != nil {
-			return fmt.Errorf("failed to parse ISM policy response: %w", err)
-		}
-		seqNo := existing["_seq_no"]
-		primaryTerm := existing["_primary_term"]
-		url += fmt.Sprintf("?if_seq_no=%v&if_primary_term=%v", seqNo, primaryTerm)
-	}
-
-	httpReq, err := http.NewRequestWithContext(
-		ctx,
-		method,
-		url,
-		bytes.NewReader(body),
-	)
-	if err != nil {
-		return err
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	res, err := req(httpReq)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-
-	// Accept 200 (updated), 201 (created), or 409 (already exists with same content)
-	if res.StatusCode >= 400 && res.StatusCode != 409 {
-		bodyBytes, err := io.ReadAll(res.Body)
-		if err != nil {
-			return fmt.Errorf("failed to create ISM policy: %d (could not read body: %v)", res.StatusCode, err)
-		}
-		return fmt.Errorf("failed to create ISM policy: %d - %s", res.StatusCode, string(bodyBytes))
-	}
-
-	log.Println("ISM policy created/updated successfully")
-	return nil
-}
-
-func (c *Client) createInitialIndex(ctx context.Context) error {
-	indexName := c.GetCurrentWriteIndex()
-	writeAlias := c.config.IndexPrefix + "-write"
+// createInitialIndex creates today's first index under prefix and points
+// writeAlias at it.
+func (c *Client) createInitialIndex(ctx context.Context, prefix, writeAlias string) error {
+	indexName := currentWriteIndex(prefix)
 
 	exists, err := c.osClient.Indices.Exists([]string{indexName})
 	if err != nil {
@@ -723,7 +752,7 @@ func (c *Client) createInitialIndex(ctx context.Context) error {
 		"actions": []map[string]interface{}{
 			{
 				"remove": map[string]interface{}{
-					"index": c.config.IndexPrefix + "-*",
+					"index": prefix + "-*",
 					"alias": writeAlias,
 				},
 			},
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/telhawk-systems/telhawk-stack/common/indices"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/storageclient"
+)
+
+const (
+	// sharedTemplatePriority and clientTemplatePriority order the index
+	// templates; only the highest priority match applies to a new index, so a
+	// client's template replaces the shared one for its own series.
+	sharedTemplatePriority = 100
+	clientTemplatePriority = 200
+	routedTemplatePriority = 300
+)
+
+// ClientIndexPrefix returns the index prefix for a client's own index series,
+// or an error if the client ID cannot name one.
+func (c *Client) ClientIndexPrefix(clientID string) (string, error) {
+	return indices.ClientPrefix(c.config.IndexPrefix, clientID)
+}
+
+// ClientWriteAlias returns the write alias for a client's own index series.
+func (c *Client) ClientWriteAlias(clientID string) (string, error) {
+	prefix, err := c.ClientIndexPrefix(clientID)
+	if err != nil {
+		return "", err
+	}
+	return prefix + "-write", nil
+}
+
+// OnboardClient creates a client's index template, ISM policy, first index
+// and write alias. It is safe to call for a client that is already
+// onboarded, and ingest calls it on a client's first event when client
+// routing is enabled.
+func (c *Client) OnboardClient(ctx context.Context, clientID string) error {
+	if clientID == "" {
+		return fmt.Errorf("client ID is required")
+	}
+
+	c.clientsMu.Lock()
+	defer c.clientsMu.Unlock()
+	return c.onboardLocked(ctx, clientID)
+}
+
+func (c *Client) onboardLocked(ctx context.Context, clientID string) error {
+	prefix, err := c.ClientIndexPrefix(clientID)
+	if err != nil {
+		return err
+	}
+	writeAlias := prefix + "-write"
+
+	if err := c.putIndexTemplate(ctx, prefix, writeAlias, clientTemplatePriority); err != nil {
+		return fmt.Errorf("failed to create index template for client %s: %w", clientID, err)
+	}
+	if err := c.putClientISMPolicy(ctx, clientID); err != nil {
+		return fmt.Errorf("failed to create ISM policy for client %s: %w", clientID, err)
+	}
+	if err := c.createInitialIndex(ctx, prefix, writeAlias); err != nil {
+		return fmt.Errorf("failed to create index for client %s: %w", clientID, err)
+	}
+
+	c.onboarded[clientID] = true
+	log.Printf("Onboarded client %s with write alias %s", clientID, writeAlias)
+	return nil
+}
+
+// indexFor returns the index an event is written to and removes the
+// transform route from the event. An empty result uses the bulk indexer's
+// default, the shared write alias.
+func (c *Client) indexFor(ctx context.Context, event map[string]interface{}) (string, error) {
+	route, _ := event[storageclient.RouteField].(string)
+	delete(event, storageclient.RouteField)
+
+	clientID, _ := event["client_id"].(string)
+	clientRouted := c.config.RoutingMode == indices.RoutingClient && clientID != ""
+	if !clientRouted && route == "" {
+		return "", nil
+	}
+
+	c.clientsMu.Lock()
+	defer c.clientsMu.Unlock()
+	base := c.config.IndexPrefix
+	if clientRouted {
+		if !c.onboarded[clientID] {
+			if err := c.onboardLocked(ctx, clientID); err != nil {
+				return "", err
+			}
+		}
+		prefix, err := c.ClientIndexPrefix(clientID)
+		if err != nil {
+			return "", err
+		}
+		if route == "" {
+			return prefix + "-write", nil
+		}
+		base = prefix
+	}
+	return c.routeLocked(ctx, base, route)
+}
+
+// routeLocked returns the write alias of the series route under base,
+// creating its template, first index and alias on first use. The series
+// falls under base's ISM policy, since that policy matches base-*.
+func (c *Client) routeLocked(ctx context.Context, base, route string) (string, error) {
+	prefix := indices.RoutePrefix(base, route)
+	writeAlias := prefix + "-write"
+	if c.routed[prefix] {
+		return writeAlias, nil
+	}
+
+	if err := c.putIndexTemplate(ctx, prefix, writeAlias, routedTemplatePriority); err != nil {
+		return "", fmt.Errorf("failed to create index template for route %s: %w", route, err)
+	}
+	if err := c.createInitialIndex(ctx, prefix, writeAlias); err != nil {
+		return "", fmt.Errorf("failed to create index for route %s: %w", route, err)
+	}
+
+	c.routed[prefix] = true
+	log.Printf("Created routed index series with write alias %s", writeAlias)
+	return writeAlias, nil
+}
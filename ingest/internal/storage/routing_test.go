@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telhawk-systems/telhawk-stack/common/indices"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/storageclient"
+)
+
+func TestClientIndexPrefix(t *testing.T) {
+	client := newTestClient(t, "http://localhost:9200", LifecycleConfig{})
+
+	prefix, err := client.ClientIndexPrefix("acme")
+	require.NoError(t, err)
+	assert.Equal(t, "telhawk-events-acme.", prefix)
+
+	prefix, err = client.ClientIndexPrefix("6f1c2a7e-0b1d-4e0b-9d52-8a2c1e5f9a10")
+	require.NoError(t, err)
+	assert.Equal(t, "telhawk-events-6f1c2a7e-0b1d-4e0b-9d52-8a2c1e5f9a10.", prefix)
+
+	alias, err := client.ClientWriteAlias("acme")
+	require.NoError(t, err)
+	assert.Equal(t, "telhawk-events-acme.-write", alias)
+
+	_, err = client.ClientIndexPrefix("ACME Corp/*")
+	assert.Error(t, err, "IDs that would need rewriting are rejected")
+}
+
+func TestOnboardClient(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+	client := newTestClient(t, server.URL, LifecycleConfig{
+		ClientRetention: []ClientRetention{{ClientID: "acme", RetentionDays: 90}},
+	})
+
+	require.NoError(t, client.OnboardClient(context.Background(), "acme"))
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	template := fake.templates["telhawk-events-acme.-template"]
+	require.NotNil(t, template, "client index template not created")
+	assert.Equal(t, []interface{}{"telhawk-events-acme.-*"}, template["index_patterns"])
+	assert.EqualValues(t, clientTemplatePriority, template["priority"])
+	settings := template["template"].(map[string]interface{})["settings"].(map[string]interface{})
+	assert.Equal(t, "telhawk-events-acme.-write", settings["plugins.index_state_management.rollover_alias"])
+
+	policy := fake.policies["telhawk-events-acme.-policy"]
+	require.NotNil(t, policy, "client ISM policy not created")
+	_, _, next := policyStates(t, policy)
+	assert.Equal(t, "delete@90d", next["hot"])
+
+	assert.True(t, fake.indices[currentWriteIndex("telhawk-events-acme.")])
+}
+
+func TestOnboardClient_RequiresClientID(t *testing.T) {
+	client := newTestClient(t, "http://localhost:9200", LifecycleConfig{})
+	assert.Error(t, client.OnboardClient(context.Background(), ""))
+}
+
+func TestIngest_RoutesByClient(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+	cfg := DefaultConfig()
+	cfg.URL = server.URL
+	cfg.RoutingMode = indices.RoutingClient
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+
+	resp, err := client.Ingest(context.Background(), []map[string]interface{}{
+		{"class_uid": 3002, "client_id": "acme"},
+		{"class_uid": 3002, "client_id": "acme"},
+		{"class_uid": 3002},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Close())
+	assert.Equal(t, 0, resp.Failed, resp.Errors)
+	assert.Equal(t, 3, resp.Indexed)
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.ElementsMatch(t, []string{
+		"telhawk-events-acme.-write",
+		"telhawk-events-acme.-write",
+		"telhawk-events-write",
+	}, fake.bulked)
+	assert.Contains(t, fake.templates, "telhawk-events-acme.-template")
+}
+
+func TestIngest_RoutesClientsWhoseIDsSharePrefix(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+	cfg := DefaultConfig()
+	cfg.URL = server.URL
+	cfg.RoutingMode = indices.RoutingClient
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+
+	resp, err := client.Ingest(context.Background(), []map[string]interface{}{
+		{"class_uid": 3002, "client_id": "acme"},
+		{"class_uid": 3002, "client_id": "acme-eu"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Close())
+	assert.Equal(t, 0, resp.Failed, resp.Errors)
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.ElementsMatch(t, []string{
+		"telhawk-events-acme.-write",
+		"telhawk-events-acme-eu.-write",
+	}, fake.bulked)
+
+	// Each client's template, ISM policy and search pattern must leave the
+	// other's indices alone; OpenSearch rejects two overlapping templates of
+	// equal priority.
+	acmePattern, err := indices.SearchPattern(cfg.IndexPrefix, indices.RoutingClient, "acme")
+	require.NoError(t, err)
+	euPattern, err := indices.SearchPattern(cfg.IndexPrefix, indices.RoutingClient, "acme-eu")
+	require.NoError(t, err)
+	for _, c := range []struct {
+		name, pattern, other string
+	}{
+		{"acme", acmePattern, currentWriteIndex("telhawk-events-acme-eu.")},
+		{"acme-eu", euPattern, currentWriteIndex("telhawk-events-acme.")},
+	} {
+		prefix := "telhawk-events-" + c.name + "."
+		require.Contains(t, fake.indices, currentWriteIndex(prefix))
+		template := fake.templates[prefix+"-template"]
+		require.NotNil(t, template, c.name)
+		assert.Equal(t, []interface{}{c.pattern}, template["index_patterns"])
+		policy := fake.policies[prefix+"-policy"]
+		require.NotNil(t, policy, c.name)
+		ismTemplate := policy["policy"].(map[string]interface{})["ism_template"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, []interface{}{c.pattern}, ismTemplate["index_patterns"])
+
+		matched, err := path.Match(c.pattern, c.other)
+		require.NoError(t, err)
+		assert.False(t, matched, "%s pattern %q matches %q", c.name, c.pattern, c.other)
+	}
+}
+
+func TestIngest_SharedModeIgnoresClient(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+	client := newTestClient(t, server.URL, LifecycleConfig{})
+
+	_, err := client.Ingest(context.Background(), []map[string]interface{}{
+		{"class_uid": 3002, "client_id": "acme"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Close())
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Equal(t, []string{"telhawk-events-write"}, fake.bulked)
+	assert.NotContains(t, fake.templates, "telhawk-events-acme.-template")
+}
+
+func TestIngest_DocumentID(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+	client := newTestClient(t, server.URL, LifecycleConfig{})
+
+	identified := map[string]interface{}{"class_uid": 3002, storageclient.IDField: "doc-1"}
+	resp, err := client.Ingest(context.Background(), []map[string]interface{}{
+		identified,
+		{"class_uid": 3002},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Indexed, "documents with an ID are stored before Ingest returns")
+	require.NoError(t, client.Close())
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.ElementsMatch(t, []string{"doc-1", ""}, fake.bulkedIDs)
+	for i, id := range fake.bulkedIDs {
+		if id == "doc-1" {
+			assert.NotContains(t, fake.bulkedDocs[i], storageclient.IDField, "document ID must not be indexed")
+		}
+	}
+	assert.Equal(t, "doc-1", identified[storageclient.IDField], "the caller's event must not be modified")
+}
+
+func TestIngest_CreateConflict(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+	client := newTestClient(t, server.URL, LifecycleConfig{})
+
+	resp, err := client.Ingest(context.Background(), []map[string]interface{}{
+		{"class_uid": 3002, storageclient.IDField: "doc-1", storageclient.CreateField: true},
+		{"class_uid": 3002, storageclient.IDField: "doc-2", storageclient.CreateField: true},
+		{"class_uid": 3002, storageclient.IDField: "doc-1", storageclient.CreateField: true},
+		{"class_uid": 3002, storageclient.IDField: "doc-1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.Failed, resp.Errors)
+	assert.Equal(t, 3, resp.Indexed, "an index action overwrites the existing document")
+	assert.Equal(t, []int{2}, resp.Conflicts)
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.NotContains(t, fake.bulkedDocs[0], storageclient.CreateField)
+}
+
+func TestIngest_TransformRoute(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+	cfg := DefaultConfig()
+	cfg.URL = server.URL
+	cfg.RoutingMode = indices.RoutingClient
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+
+	routed := map[string]interface{}{"class_uid": 3002, "client_id": "acme", storageclient.RouteField: "audit"}
+	resp, err := client.Ingest(context.Background(), []map[string]interface{}{
+		routed,
+		{"class_uid": 3002, storageclient.RouteField: "audit"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Close())
+	assert.Equal(t, 0, resp.Failed, resp.Errors)
+	assert.NotContains(t, routed, storageclient.RouteField, "route must not be indexed")
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.ElementsMatch(t, []string{
+		"telhawk-events-acme.-_audit.-write",
+		"telhawk-events-_audit.-write",
+	}, fake.bulked)
+	template := fake.templates["telhawk-events-acme.-_audit.-template"]
+	require.NotNil(t, template, "routed index template not created")
+	assert.EqualValues(t, routedTemplatePriority, template["priority"])
+	assert.Contains(t, fake.templates, "telhawk-events-acme.-template", "client must be onboarded too")
+	assert.True(t, fake.indices[currentWriteIndex("telhawk-events-acme.-_audit.")])
+}
+
+func TestIngest_RejectsUnroutableClientID(t *testing.T) {
+	fake, server := newFakeOpenSearch(t)
+	cfg := DefaultConfig()
+	cfg.URL = server.URL
+	cfg.RoutingMode = indices.RoutingClient
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+
+	resp, err := client.Ingest(context.Background(), []map[string]interface{}{
+		{"class_uid": 3002, "client_id": "ACME Corp"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Close())
+	assert.Equal(t, 1, resp.Failed)
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Empty(t, fake.bulked)
+	assert.NotContains(t, fake.templates, "telhawk-events-acme_corp.-template")
+}
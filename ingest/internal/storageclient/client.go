@@ -23,14 +23,30 @@ func New(baseURL string, timeout time.Duration) *Client {
 	}
 }
 
+// RouteField names the index series an event is routed to by an ingest
+// transform rule. Storage removes it before indexing and writes the event to
+// that series instead of the default one.
+const RouteField = "_telhawk_route"
+
+// IDField sets the document ID an event is indexed under, so that retrying
+// a request overwrites the earlier copy. Storage removes it before indexing;
+// events without it get a generated ID.
+const IDField = "_telhawk_id"
+
+// CreateField, set to true on an event with an IDField, creates the document
+// instead of overwriting it: an existing document under that ID is reported
+// in IngestResponse.Conflicts and left as it is.
+const CreateField = "_telhawk_create"
+
 type IngestRequest struct {
 	Events []map[string]interface{} `json:"events"`
 }
 
 type IngestResponse struct {
-	Indexed int      `json:"indexed"`
-	Failed  int      `json:"failed"`
-	Errors  []string `json:"errors,omitempty"`
+	Indexed   int      `json:"indexed"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+	Conflicts []int    `json:"conflicts,omitempty"` // Positions of created events whose document already exists
 }
 
 func (c *Client) Ingest(ctx context.Context, events []map[string]interface{}) (*IngestResponse, error) {
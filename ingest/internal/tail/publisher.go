@@ -0,0 +1,185 @@
+// Package tail publishes stored events to NATS so analysts can watch them
+// arrive (live tail). Publishing is best effort: it never blocks ingestion,
+// and events are dropped when NATS cannot keep up.
+package tail
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/messaging"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/metrics"
+	"github.com/telhawk-systems/telhawk-stack/search/pkg/model"
+)
+
+const (
+	// DefaultBufferSize is how many events may wait for publishing.
+	DefaultBufferSize = 10000
+	// maxBatchSize is the most events sent in one NATS message.
+	maxBatchSize = 100
+	// flushInterval bounds how long an event waits for its batch to fill.
+	flushInterval  = 250 * time.Millisecond
+	publishTimeout = 5 * time.Second
+)
+
+// Config controls which stored events are published.
+type Config struct {
+	SampleRate  float64        // Fraction of events published; <= 0 or >= 1 publishes all
+	SourceTypes []string       // Only publish these sourcetypes (empty = all)
+	Filter      *model.Matcher // Only publish events matching this filter (nil = all)
+	BufferSize  int
+}
+
+// Batch is the message published on a client's tail subject.
+type Batch struct {
+	ClientID string                   `json:"client_id,omitempty"`
+	Events   []map[string]interface{} `json:"events"`
+}
+
+type queuedEvent struct {
+	clientID string
+	event    map[string]interface{}
+}
+
+// Publisher batches stored events per client and publishes them to
+// messaging.IngestTailSubject.
+type Publisher struct {
+	publisher   messaging.Publisher
+	sampleRate  float64
+	sourceTypes map[string]struct{}
+	filter      *model.Matcher
+
+	queue     chan queuedEvent
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewPublisher creates a Publisher and starts its background sender.
+func NewPublisher(publisher messaging.Publisher, cfg Config) *Publisher {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultBufferSize
+	}
+	p := &Publisher{
+		publisher:  publisher,
+		sampleRate: cfg.SampleRate,
+		filter:     cfg.Filter,
+		queue:      make(chan queuedEvent, cfg.BufferSize),
+		stop:       make(chan struct{}),
+	}
+	if len(cfg.SourceTypes) > 0 {
+		p.sourceTypes = make(map[string]struct{}, len(cfg.SourceTypes))
+		for _, st := range cfg.SourceTypes {
+			p.sourceTypes[st] = struct{}{}
+		}
+	}
+
+	p.wg.Add(1)
+	go p.run()
+	return p
+}
+
+// Publish queues a stored event for live tail. It never blocks: events are
+// skipped when sampled or filtered out and dropped when the queue is full.
+func (p *Publisher) Publish(sourceType string, event map[string]interface{}) {
+	if p.sampleRate > 0 && p.sampleRate < 1 && rand.Float64() >= p.sampleRate {
+		metrics.TailEventsTotal.WithLabelValues("sampled_out").Inc()
+		return
+	}
+	if p.sourceTypes != nil {
+		if _, ok := p.sourceTypes[sourceType]; !ok {
+			metrics.TailEventsTotal.WithLabelValues("filtered").Inc()
+			return
+		}
+	}
+	if p.filter != nil && !p.filter.Match(event) {
+		metrics.TailEventsTotal.WithLabelValues("filtered").Inc()
+		return
+	}
+
+	clientID, _ := event["client_id"].(string)
+	select {
+	case p.queue <- queuedEvent{clientID: clientID, event: event}:
+	default:
+		metrics.TailEventsTotal.WithLabelValues("dropped").Inc()
+	}
+}
+
+// Close flushes queued events and stops the background sender.
+func (p *Publisher) Close() {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+		p.wg.Wait()
+	})
+}
+
+func (p *Publisher) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	pending := make(map[string][]map[string]interface{})
+	add := func(q queuedEvent) {
+		pending[q.clientID] = append(pending[q.clientID], q.event)
+		if len(pending[q.clientID]) >= maxBatchSize {
+			p.send(q.clientID, pending[q.clientID])
+			delete(pending, q.clientID)
+		}
+	}
+	flush := func() {
+		for clientID, events := range pending {
+			p.send(clientID, events)
+			delete(pending, clientID)
+		}
+	}
+
+	for {
+		select {
+		case q := <-p.queue:
+			add(q)
+		case <-ticker.C:
+			flush()
+		case <-p.stop:
+			for {
+				select {
+				case q := <-p.queue:
+					add(q)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *Publisher) send(clientID string, events []map[string]interface{}) {
+	subject, err := messaging.IngestTailSubject(clientID)
+	if err != nil {
+		log.Printf("not publishing live tail batch: %v", err)
+		metrics.TailEventsTotal.WithLabelValues("dropped").Add(float64(len(events)))
+		return
+	}
+
+	data, err := json.Marshal(Batch{ClientID: clientID, Events: events})
+	if err != nil {
+		log.Printf("failed to encode live tail batch: %v", err)
+		metrics.TailEventsTotal.WithLabelValues("dropped").Add(float64(len(events)))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	if err := p.publisher.Publish(ctx, subject, data); err != nil {
+		log.Printf("failed to publish live tail batch: %v", err)
+		metrics.TailEventsTotal.WithLabelValues("dropped").Add(float64(len(events)))
+		return
+	}
+	metrics.TailEventsTotal.WithLabelValues("published").Add(float64(len(events)))
+}
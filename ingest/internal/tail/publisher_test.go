@@ -0,0 +1,159 @@
+package tail
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telhawk-systems/telhawk-stack/common/messaging"
+	"github.com/telhawk-systems/telhawk-stack/search/pkg/model"
+)
+
+type fakePublisher struct {
+	messaging.Publisher // Only Publish is used
+
+	mu      sync.Mutex
+	batches map[string][]Batch
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, subject string, data []byte) error {
+	var batch Batch
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.batches == nil {
+		p.batches = make(map[string][]Batch)
+	}
+	p.batches[subject] = append(p.batches[subject], batch)
+	return nil
+}
+
+func (p *fakePublisher) events(subject string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, batch := range p.batches[subject] {
+		n += len(batch.Events)
+	}
+	return n
+}
+
+func tailSubject(t *testing.T, clientID string) string {
+	t.Helper()
+	subject, err := messaging.IngestTailSubject(clientID)
+	require.NoError(t, err)
+	return subject
+}
+
+func TestPublisher_BatchesPerClient(t *testing.T) {
+	fake := &fakePublisher{}
+	p := NewPublisher(fake, Config{})
+
+	for i := 0; i < 150; i++ {
+		p.Publish("hec", map[string]interface{}{"client_id": "acme", "n": i})
+	}
+	p.Publish("hec", map[string]interface{}{"client_id": "globex"})
+	p.Publish("hec", map[string]interface{}{"message": "no client"})
+	p.Close()
+
+	assert.Equal(t, 150, fake.events(tailSubject(t, "acme")))
+	assert.Equal(t, 1, fake.events(tailSubject(t, "globex")))
+	assert.Equal(t, 1, fake.events(tailSubject(t, "")))
+	for _, batch := range fake.batches[tailSubject(t, "acme")] {
+		assert.LessOrEqual(t, len(batch.Events), maxBatchSize)
+		assert.Equal(t, "acme", batch.ClientID)
+	}
+}
+
+func TestPublisher_SkipsUnsafeClientIDs(t *testing.T) {
+	fake := &fakePublisher{}
+	p := NewPublisher(fake, Config{})
+
+	p.Publish("hec", map[string]interface{}{"client_id": "acme.*"})
+	p.Publish("hec", map[string]interface{}{"client_id": "acme"})
+	p.Close()
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Len(t, fake.batches, 1, "a client ID that is not subject-safe must not be published")
+	assert.Contains(t, fake.batches, tailSubject(t, "acme"))
+}
+
+func TestPublisher_FlushesOnInterval(t *testing.T) {
+	fake := &fakePublisher{}
+	p := NewPublisher(fake, Config{})
+	defer p.Close()
+
+	p.Publish("hec", map[string]interface{}{"client_id": "acme"})
+	require.Eventually(t, func() bool {
+		return fake.events(tailSubject(t, "acme")) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestPublisher_Filters(t *testing.T) {
+	filter, err := model.CompileFilter(&model.FilterExpr{Field: ".severity_id", Operator: model.OpGte, Value: float64(3)})
+	require.NoError(t, err)
+
+	fake := &fakePublisher{}
+	p := NewPublisher(fake, Config{SourceTypes: []string{"zeek"}, Filter: filter})
+
+	p.Publish("zeek", map[string]interface{}{"client_id": "acme", "severity_id": float64(4)})
+	p.Publish("zeek", map[string]interface{}{"client_id": "acme", "severity_id": float64(1)})
+	p.Publish("hec", map[string]interface{}{"client_id": "acme", "severity_id": float64(5)})
+	p.Close()
+
+	assert.Equal(t, 1, fake.events(tailSubject(t, "acme")))
+}
+
+func TestPublisher_Samples(t *testing.T) {
+	fake := &fakePublisher{}
+	p := NewPublisher(fake, Config{SampleRate: 0.1})
+
+	for i := 0; i < 2000; i++ {
+		p.Publish("hec", map[string]interface{}{"client_id": "acme"})
+	}
+	p.Close()
+
+	published := fake.events(tailSubject(t, "acme"))
+	assert.Greater(t, published, 100)
+	assert.Less(t, published, 300)
+}
+
+func TestPublisher_DropsWhenQueueFull(t *testing.T) {
+	blocked := make(chan struct{})
+	fake := &blockingPublisher{release: blocked}
+	p := NewPublisher(fake, Config{BufferSize: 1})
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			p.Publish("hec", map[string]interface{}{"client_id": "acme"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Publish blocked while NATS was stalled")
+	}
+	close(blocked)
+	p.Close()
+}
+
+type blockingPublisher struct {
+	messaging.Publisher
+	release chan struct{}
+}
+
+func (p *blockingPublisher) Publish(ctx context.Context, subject string, data []byte) error {
+	<-p.release
+	return nil
+}
@@ -0,0 +1,304 @@
+package transform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/metrics"
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/storageclient"
+)
+
+var (
+	// ErrNotFound is returned for a rule ID the client does not have.
+	ErrNotFound = errors.New("transform rule not found")
+	// ErrConflict is returned when creating a rule whose ID is taken.
+	ErrConflict = errors.New("transform rule already exists")
+)
+
+// Engine applies the stored rules of each client to its events. Rules are
+// cached in memory and refreshed from the store periodically, so changes
+// made through another replica apply after at most one refresh interval;
+// changes made through this engine apply immediately.
+type Engine struct {
+	store    Store
+	redactor redactor
+
+	rules atomic.Pointer[map[string][]*compiledRule] // By client ID
+
+	mu           sync.Mutex        // Serializes installs
+	fingerprints map[string]string // JSON of each client's installed rules
+}
+
+// NewEngine creates an engine over store. hashKey, if set, keys the HMAC
+// used by hash redactions so hashed values cannot be brute-forced without
+// it.
+func NewEngine(store Store, hashKey string) *Engine {
+	e := &Engine{
+		store:        store,
+		redactor:     redactor{hashKey: []byte(hashKey)},
+		fingerprints: make(map[string]string),
+	}
+	empty := make(map[string][]*compiledRule)
+	e.rules.Store(&empty)
+	return e
+}
+
+// Apply runs the rules of the event's client against a normalized event,
+// modifying it in place, and reports whether the event should be stored.
+func (e *Engine) Apply(sourceType string, event map[string]interface{}) bool {
+	clientID, _ := event["client_id"].(string)
+	for _, rule := range (*e.rules.Load())[clientID] {
+		if rule.Disabled || !rule.matches(sourceType, event) {
+			continue
+		}
+
+		result := ""
+		switch rule.Action {
+		case ActionDrop:
+			metrics.TransformRuleEventsTotal.WithLabelValues(clientID, rule.ID, "dropped").Inc()
+			return false
+		case ActionSample:
+			if (atomic.AddUint64(&rule.seen, 1)-1)%uint64(rule.SampleRate) != 0 {
+				metrics.TransformRuleEventsTotal.WithLabelValues(clientID, rule.ID, "sampled_out").Inc()
+				return false
+			}
+			result = "sampled_in"
+		case ActionRedact:
+			result = "unchanged"
+			for _, redaction := range rule.redactions {
+				if e.redactor.apply(redaction, event) {
+					result = "redacted"
+				}
+			}
+		case ActionRoute:
+			event[storageclient.RouteField] = rule.Index
+			result = "routed"
+		}
+		metrics.TransformRuleEventsTotal.WithLabelValues(clientID, rule.ID, result).Inc()
+	}
+	return true
+}
+
+// RedactRaw applies the raw payload redactions of a client's redact rules to
+// a payload that could not be normalized, such as one headed for the DLQ,
+// and returns the redacted copy. A rule's filter needs a normalized event, so
+// every enabled redact rule whose sourcetype and HEC token conditions match
+// applies regardless of its filter: more is redacted rather than less. JSON
+// payloads are redacted string by string like a normalized event's raw data;
+// any other payload is redacted as one string.
+func (e *Engine) RedactRaw(clientID, sourceType, hecTokenID string, payload []byte) []byte {
+	var redactions []compiledRedaction
+	for _, rule := range (*e.rules.Load())[clientID] {
+		if rule.Disabled || rule.Action != ActionRedact || !rule.matchesSource(sourceType, hecTokenID) {
+			continue
+		}
+		for _, redaction := range rule.redactions {
+			if redaction.field == nil {
+				redactions = append(redactions, redaction)
+			}
+		}
+	}
+	if len(redactions) == 0 {
+		return payload
+	}
+
+	// Numbers are kept as written: a float64 round trip would alter large IDs.
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil || dec.More() {
+		text := string(payload)
+		for _, redaction := range redactions {
+			text, _ = e.redactor.redactString(redaction, text)
+		}
+		return []byte(text)
+	}
+	for _, redaction := range redactions {
+		value, _ = e.redactor.walk(redaction, value)
+	}
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return []byte(Redacted)
+	}
+	return redacted
+}
+
+// Rules returns a client's rules in evaluation order.
+func (e *Engine) Rules(ctx context.Context, clientID string) ([]Rule, error) {
+	all, err := e.store.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := all[clientID]
+	if rules == nil {
+		rules = []Rule{}
+	}
+	return rules, nil
+}
+
+// Rule returns one of a client's rules.
+func (e *Engine) Rule(ctx context.Context, clientID, id string) (*Rule, error) {
+	rules, err := e.Rules(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if i := indexOf(rules, id); i >= 0 {
+		return &rules[i], nil
+	}
+	return nil, ErrNotFound
+}
+
+// Create validates a rule and appends it to the client's rules.
+func (e *Engine) Create(ctx context.Context, clientID string, rule Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	return e.modify(ctx, clientID, func(rules []Rule) ([]Rule, error) {
+		if indexOf(rules, rule.ID) >= 0 {
+			return nil, ErrConflict
+		}
+		return append(rules, rule), nil
+	})
+}
+
+// Update validates a rule and replaces the client's rule with the same ID,
+// keeping its position.
+func (e *Engine) Update(ctx context.Context, clientID string, rule Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	return e.modify(ctx, clientID, func(rules []Rule) ([]Rule, error) {
+		i := indexOf(rules, rule.ID)
+		if i < 0 {
+			return nil, ErrNotFound
+		}
+		rules[i] = rule
+		return rules, nil
+	})
+}
+
+// Delete removes one of a client's rules.
+func (e *Engine) Delete(ctx context.Context, clientID, id string) error {
+	return e.modify(ctx, clientID, func(rules []Rule) ([]Rule, error) {
+		i := indexOf(rules, id)
+		if i < 0 {
+			return nil, ErrNotFound
+		}
+		return append(rules[:i], rules[i+1:]...), nil
+	})
+}
+
+func (e *Engine) modify(ctx context.Context, clientID string, fn func(rules []Rule) ([]Rule, error)) error {
+	rules, err := e.store.Modify(ctx, clientID, fn)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	current := *e.rules.Load()
+	next := make(map[string][]*compiledRule, len(current)+1)
+	for id, compiled := range current {
+		next[id] = compiled
+	}
+	e.installLocked(next, clientID, rules)
+	e.rules.Store(&next)
+	return nil
+}
+
+// Refresh reloads every client's rules from the store. Rules that did not
+// change keep their sample counters.
+func (e *Engine) Refresh(ctx context.Context) error {
+	all, err := e.store.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	current := *e.rules.Load()
+	next := make(map[string][]*compiledRule, len(all))
+	for clientID, rules := range all {
+		next[clientID] = current[clientID]
+		e.installLocked(next, clientID, rules)
+	}
+	for clientID := range e.fingerprints {
+		if _, ok := all[clientID]; !ok {
+			delete(e.fingerprints, clientID)
+		}
+	}
+	e.rules.Store(&next)
+	return nil
+}
+
+// installLocked compiles a client's rules into next unless they are
+// unchanged. Rules that fail to compile are logged and skipped; the store
+// only holds validated rules, so this means another version wrote them.
+func (e *Engine) installLocked(next map[string][]*compiledRule, clientID string, rules []Rule) {
+	if len(rules) == 0 {
+		delete(next, clientID)
+		delete(e.fingerprints, clientID)
+		return
+	}
+	data, _ := json.Marshal(rules)
+	if fingerprint := string(data); e.fingerprints[clientID] == fingerprint && next[clientID] != nil {
+		return
+	}
+
+	compiled := make([]*compiledRule, 0, len(rules))
+	for i := range rules {
+		rule, err := compile(&rules[i])
+		if err != nil {
+			log.Printf("Transform rules: skipping rule of client %s: %v", clientID, err)
+			continue
+		}
+		compiled = append(compiled, rule)
+	}
+	next[clientID] = compiled
+	e.fingerprints[clientID] = string(data)
+}
+
+// Watch refreshes the rules every interval until ctx is done.
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Refresh(ctx); err != nil {
+				log.Printf("Transform rules: refresh failed, keeping loaded rules: %v", err)
+			}
+		}
+	}
+}
+
+func indexOf(rules []Rule, id string) int {
+	for i := range rules {
+		if rules[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// ValidationError wraps rule validation failures so callers can tell them
+// from store errors.
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid transform rule: %v", e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
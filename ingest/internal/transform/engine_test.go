@@ -0,0 +1,219 @@
+package transform
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/internal/storageclient"
+	"github.com/telhawk-systems/telhawk-stack/search/pkg/model"
+)
+
+func newEvent(clientID string) map[string]interface{} {
+	return map[string]interface{}{
+		"client_id":    clientID,
+		"hec_token_id": "tok-1",
+		"severity_id":  float64(1),
+		"actor": map[string]interface{}{
+			"user": map[string]interface{}{"name": "alice", "email_addr": "alice@example.com"},
+		},
+		"raw": map[string]interface{}{
+			"format": "json",
+			"data":   map[string]interface{}{"card": "4111 1111 1111 1111", "note": "paid with 4111 1111 1111 1111"},
+		},
+	}
+}
+
+func newTestEngine(t *testing.T, clientID string, rules ...Rule) *Engine {
+	t.Helper()
+	e := NewEngine(NewMemoryStore(), "")
+	for _, rule := range rules {
+		require.NoError(t, e.Create(context.Background(), clientID, rule))
+	}
+	return e
+}
+
+func TestApply_Drop(t *testing.T) {
+	e := newTestEngine(t, "acme", Rule{
+		ID:          "drop-debug",
+		SourceTypes: []string{"app:*"},
+		Filter:      &model.FilterExpr{Field: ".severity_id", Operator: "lte", Value: 1},
+		Action:      ActionDrop,
+	})
+
+	assert.False(t, e.Apply("app:web", newEvent("acme")))
+	assert.True(t, e.Apply("syslog", newEvent("acme")), "sourcetype does not match")
+	assert.True(t, e.Apply("app:web", newEvent("globex")), "rules are per client")
+
+	important := newEvent("acme")
+	important["severity_id"] = float64(4)
+	assert.True(t, e.Apply("app:web", important), "filter does not match")
+}
+
+func TestApply_Sample(t *testing.T) {
+	e := newTestEngine(t, "acme", Rule{ID: "sample", HECTokenIDs: []string{"tok-1"}, Action: ActionSample, SampleRate: 3})
+
+	kept := 0
+	for i := 0; i < 9; i++ {
+		if e.Apply("app", newEvent("acme")) {
+			kept++
+		}
+	}
+	assert.Equal(t, 3, kept)
+
+	other := newEvent("acme")
+	other["hec_token_id"] = "tok-2"
+	assert.True(t, e.Apply("app", other), "other tokens are not sampled")
+}
+
+func TestApply_Redact(t *testing.T) {
+	e := newTestEngine(t, "acme", Rule{
+		ID:     "pii",
+		Action: ActionRedact,
+		Redactions: []Redaction{
+			{Field: "actor.user.email_addr", Method: MethodHash},
+			{Field: ".actor.user.name", Method: MethodRedact},
+			{Pattern: `\d{4} \d{4} \d{4} \d{4}`, Method: MethodMask},
+			{Field: "missing.field", Method: MethodRedact},
+		},
+	})
+
+	event := newEvent("acme")
+	require.True(t, e.Apply("app", event))
+
+	user := event["actor"].(map[string]interface{})["user"].(map[string]interface{})
+	assert.Equal(t, Redacted, user["name"])
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, user["email_addr"])
+	data := event["raw"].(map[string]interface{})["data"].(map[string]interface{})
+	assert.Equal(t, "***************1111", data["card"])
+	assert.Equal(t, "paid with ***************1111", data["note"])
+	assert.NotContains(t, event, "missing")
+}
+
+func TestRedactRaw(t *testing.T) {
+	e := newTestEngine(t, "acme",
+		Rule{
+			ID:          "cards",
+			HECTokenIDs: []string{"tok-1"},
+			Filter:      &model.FilterExpr{Field: ".severity_id", Operator: "gte", Value: 4},
+			Action:      ActionRedact,
+			Redactions: []Redaction{
+				{Pattern: `\d{4} \d{4} \d{4} \d{4}`, Method: MethodMask},
+				{Field: "actor.user.name", Method: MethodRedact},
+			},
+		},
+		Rule{ID: "users", Disabled: true, Action: ActionRedact, Redactions: []Redaction{{Pattern: "alice", Method: MethodRedact}}},
+	)
+
+	payload := []byte(`{"card":"4111 1111 1111 1111","n":12345678901234567890,"user":{"name":"alice"}}`)
+	assert.JSONEq(t, `{"card":"***************1111","n":12345678901234567890,"user":{"name":"alice"}}`,
+		string(e.RedactRaw("acme", "app", "tok-1", payload)), "rule filters and field redactions do not apply")
+	assert.Contains(t, string(e.RedactRaw("acme", "app", "tok-1", payload)), `"n":12345678901234567890`, "numbers are kept as written")
+	assert.Contains(t, string(payload), "4111 1111 1111 1111", "the payload must not be modified")
+
+	text := []byte("paid with 4111 1111 1111 1111\n")
+	assert.Equal(t, "paid with ***************1111\n", string(e.RedactRaw("acme", "app", "tok-1", text)))
+
+	assert.Equal(t, text, e.RedactRaw("acme", "app", "tok-2", text), "token does not match")
+	assert.Equal(t, text, e.RedactRaw("globex", "app", "tok-1", text), "rules are per client")
+}
+
+func TestApply_Route(t *testing.T) {
+	e := newTestEngine(t, "acme",
+		Rule{ID: "audit", SourceTypes: []string{"audit"}, Action: ActionRoute, Index: "audit"},
+		Rule{ID: "disabled", Disabled: true, Action: ActionDrop},
+	)
+
+	event := newEvent("acme")
+	assert.True(t, e.Apply("audit", event))
+	assert.Equal(t, "audit", event[storageclient.RouteField])
+
+	event = newEvent("acme")
+	assert.True(t, e.Apply("app", event))
+	assert.NotContains(t, event, storageclient.RouteField)
+}
+
+func TestRedactor_Hash(t *testing.T) {
+	plain := redactor{}.hash("alice")
+	keyed := redactor{hashKey: []byte("secret")}.hash("alice")
+
+	assert.Equal(t, plain, redactor{}.hash("alice"), "hashes are stable so values can still be correlated")
+	assert.NotEqual(t, plain, keyed)
+	assert.Regexp(t, `^hmac-sha256:`, keyed)
+	assert.Equal(t, "****", mask("1234"))
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]Rule{
+		"missing id":         {Action: ActionDrop},
+		"bad id":             {ID: "a b", Action: ActionDrop},
+		"unknown action":     {ID: "r", Action: "archive"},
+		"bad sourcetype":     {ID: "r", SourceTypes: []string{"["}, Action: ActionDrop},
+		"bad filter":         {ID: "r", Filter: &model.FilterExpr{Field: ".x", Operator: "near", Value: 1}, Action: ActionDrop},
+		"low sample rate":    {ID: "r", Action: ActionSample, SampleRate: 1},
+		"no redactions":      {ID: "r", Action: ActionRedact},
+		"bad method":         {ID: "r", Action: ActionRedact, Redactions: []Redaction{{Field: "x", Method: "shred"}}},
+		"empty redaction":    {ID: "r", Action: ActionRedact, Redactions: []Redaction{{Method: MethodMask}}},
+		"bad pattern":        {ID: "r", Action: ActionRedact, Redactions: []Redaction{{Pattern: "(", Method: MethodMask}}},
+		"missing index":      {ID: "r", Action: ActionRoute},
+		"invalid index name": {ID: "r", Action: ActionRoute, Index: "Audit-Logs"},
+	}
+	for name, rule := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := rule.Validate()
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+		})
+	}
+}
+
+func TestEngine_CRUD(t *testing.T) {
+	ctx := context.Background()
+	e := newTestEngine(t, "acme", Rule{ID: "a", Action: ActionDrop}, Rule{ID: "b", Action: ActionRoute, Index: "audit"})
+
+	assert.ErrorIs(t, e.Create(ctx, "acme", Rule{ID: "a", Action: ActionDrop}), ErrConflict)
+	assert.ErrorIs(t, e.Update(ctx, "acme", Rule{ID: "c", Action: ActionDrop}), ErrNotFound)
+	assert.ErrorIs(t, e.Delete(ctx, "acme", "c"), ErrNotFound)
+
+	require.NoError(t, e.Update(ctx, "acme", Rule{ID: "a", Disabled: true, Action: ActionDrop}))
+	rules, err := e.Rules(ctx, "acme")
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "a", rules[0].ID, "updates keep the rule's position")
+	assert.True(t, rules[0].Disabled)
+	assert.True(t, e.Apply("app", newEvent("acme")))
+
+	require.NoError(t, e.Delete(ctx, "acme", "a"))
+	require.NoError(t, e.Delete(ctx, "acme", "b"))
+	rules, err = e.Rules(ctx, "acme")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	_, err = e.Rule(ctx, "acme", "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestEngine_Refresh(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	writer := NewEngine(store, "")
+	reader := NewEngine(store, "")
+
+	require.NoError(t, writer.Create(ctx, "acme", Rule{ID: "sample", Action: ActionSample, SampleRate: 2}))
+	assert.True(t, reader.Apply("app", newEvent("acme")), "not refreshed yet")
+
+	require.NoError(t, reader.Refresh(ctx))
+	assert.True(t, reader.Apply("app", newEvent("acme")))
+	assert.False(t, reader.Apply("app", newEvent("acme")))
+
+	// Unchanged rules keep their sample counter across refreshes
+	require.NoError(t, reader.Refresh(ctx))
+	assert.True(t, reader.Apply("app", newEvent("acme")))
+
+	require.NoError(t, writer.Delete(ctx, "acme", "sample"))
+	require.NoError(t, reader.Refresh(ctx))
+	assert.True(t, reader.Apply("app", newEvent("acme")))
+	assert.True(t, reader.Apply("app", newEvent("acme")))
+}
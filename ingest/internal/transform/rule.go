@@ -0,0 +1,190 @@
+// Package transform applies per-client ingest rules to normalized events
+// before they are stored. A rule matches events by sourcetype, HEC token and
+// a search filter, and then drops them, keeps 1 in N, redacts fields or
+// patterns, or routes them to a different index series.
+//
+// Rules are evaluated in order. A drop, or a sample that discards the event,
+// ends evaluation; redact and route rules let later rules run.
+package transform
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+
+	"github.com/telhawk-systems/telhawk-stack/search/pkg/model"
+)
+
+// Rule actions.
+const (
+	ActionDrop   = "drop"   // Discard the event
+	ActionSample = "sample" // Keep 1 in SampleRate events
+	ActionRedact = "redact" // Apply Redactions
+	ActionRoute  = "route"  // Store in the Index series
+)
+
+// Redaction methods.
+const (
+	MethodRedact = "redact" // Replace with "[REDACTED]"
+	MethodMask   = "mask"   // Replace with "*", keeping the last 4 characters
+	MethodHash   = "hash"   // Replace with a SHA-256 (HMAC with a hash key) digest
+)
+
+// Redacted replaces values removed with MethodRedact.
+const Redacted = "[REDACTED]"
+
+var (
+	ruleIDPattern    = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+	routeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+)
+
+// Rule is one transform rule of a client.
+type Rule struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+
+	// Match conditions; an event must satisfy all of those that are set.
+	SourceTypes []string          `json:"sourcetypes,omitempty"`   // Glob patterns
+	HECTokenIDs []string          `json:"hec_token_ids,omitempty"` // Ingesting HEC token IDs
+	Filter      *model.FilterExpr `json:"filter,omitempty"`        // Query filter on the normalized event
+
+	Action     string      `json:"action"`
+	SampleRate int         `json:"sample_rate,omitempty"` // ActionSample: keep 1 in N
+	Redactions []Redaction `json:"redactions,omitempty"`  // ActionRedact
+	Index      string      `json:"index,omitempty"`       // ActionRoute: index series name
+}
+
+// Redaction removes sensitive data from an event. With Field set it applies
+// to that field of the normalized event; otherwise it applies to every string
+// in the raw payload. With Pattern set only the matches are replaced.
+type Redaction struct {
+	Field   string `json:"field,omitempty"`   // Dotted path, e.g. actor.user.email_addr
+	Pattern string `json:"pattern,omitempty"` // Go regular expression
+	Method  string `json:"method"`
+}
+
+// compiledRule is a validated rule ready to apply.
+type compiledRule struct {
+	Rule
+	filter     *model.Matcher
+	redactions []compiledRedaction
+	seen       uint64 // Sample counter, accessed atomically
+}
+
+type compiledRedaction struct {
+	field   []string
+	pattern *regexp.Regexp
+	method  string
+}
+
+// Validate reports the first problem with the rule as a *ValidationError.
+func (r *Rule) Validate() error {
+	if _, err := compile(r); err != nil {
+		return &ValidationError{Err: err}
+	}
+	return nil
+}
+
+func compile(r *Rule) (*compiledRule, error) {
+	if !ruleIDPattern.MatchString(r.ID) {
+		return nil, fmt.Errorf("rule id %q must be 1-64 letters, digits, '.', '_' or '-'", r.ID)
+	}
+	for _, pattern := range r.SourceTypes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("rule %s: invalid sourcetype pattern %q", r.ID, pattern)
+		}
+	}
+
+	c := &compiledRule{Rule: *r}
+	if r.Filter != nil {
+		matcher, err := model.CompileFilter(r.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid filter: %w", r.ID, err)
+		}
+		c.filter = matcher
+	}
+
+	switch r.Action {
+	case ActionDrop:
+	case ActionSample:
+		if r.SampleRate < 2 {
+			return nil, fmt.Errorf("rule %s: sample_rate must be at least 2", r.ID)
+		}
+	case ActionRedact:
+		if len(r.Redactions) == 0 {
+			return nil, fmt.Errorf("rule %s: redact requires redactions", r.ID)
+		}
+		for i, red := range r.Redactions {
+			compiled, err := compileRedaction(red)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: redaction %d: %w", r.ID, i+1, err)
+			}
+			c.redactions = append(c.redactions, compiled)
+		}
+	case ActionRoute:
+		if !routeNamePattern.MatchString(r.Index) {
+			return nil, fmt.Errorf("rule %s: index %q must be up to 32 lowercase letters, digits or '_', starting with a letter", r.ID, r.Index)
+		}
+	default:
+		return nil, fmt.Errorf("rule %s: unknown action %q", r.ID, r.Action)
+	}
+	return c, nil
+}
+
+func compileRedaction(r Redaction) (compiledRedaction, error) {
+	switch r.Method {
+	case MethodRedact, MethodMask, MethodHash:
+	default:
+		return compiledRedaction{}, fmt.Errorf("unknown method %q", r.Method)
+	}
+	if r.Field == "" && r.Pattern == "" {
+		return compiledRedaction{}, fmt.Errorf("field or pattern is required")
+	}
+
+	c := compiledRedaction{method: r.Method}
+	if r.Field != "" {
+		c.field = splitField(r.Field)
+	}
+	if r.Pattern != "" {
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return compiledRedaction{}, fmt.Errorf("invalid pattern: %w", err)
+		}
+		c.pattern = pattern
+	}
+	return c, nil
+}
+
+// matches reports whether the event satisfies the rule's conditions.
+func (c *compiledRule) matches(sourceType string, event map[string]interface{}) bool {
+	tokenID, _ := event["hec_token_id"].(string)
+	return c.matchesSource(sourceType, tokenID) && (c.filter == nil || c.filter.Match(event))
+}
+
+// matchesSource reports whether the rule's sourcetype and HEC token
+// conditions hold, leaving out its filter.
+func (c *compiledRule) matchesSource(sourceType, tokenID string) bool {
+	if len(c.SourceTypes) > 0 && !matchAny(c.SourceTypes, sourceType) {
+		return false
+	}
+	return len(c.HECTokenIDs) == 0 || contains(c.HECTokenIDs, tokenID)
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
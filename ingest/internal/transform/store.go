@@ -0,0 +1,160 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store persists each client's ordered rule list.
+type Store interface {
+	// All returns the rules of every client that has any.
+	All(ctx context.Context) (map[string][]Rule, error)
+	// Modify replaces a client's rules with the result of fn, atomically
+	// with respect to other Modify calls, and returns the new rules.
+	Modify(ctx context.Context, clientID string, fn func(rules []Rule) ([]Rule, error)) ([]Rule, error)
+}
+
+// MemoryStore keeps rules in process memory; they are lost on restart and
+// not shared between ingest replicas.
+type MemoryStore struct {
+	mu    sync.Mutex
+	rules map[string][]Rule
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{rules: make(map[string][]Rule)}
+}
+
+func (s *MemoryStore) All(ctx context.Context) (map[string][]Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string][]Rule, len(s.rules))
+	for clientID, rules := range s.rules {
+		out[clientID] = append([]Rule(nil), rules...)
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) Modify(ctx context.Context, clientID string, fn func(rules []Rule) ([]Rule, error)) ([]Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules, err := fn(append([]Rule(nil), s.rules[clientID]...))
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		delete(s.rules, clientID)
+	} else {
+		s.rules[clientID] = rules
+	}
+	return rules, nil
+}
+
+// Redis Key Structure:
+//
+//	ingest:transform_rules - Hash of client ID -> JSON rule list
+const rulesKey = "ingest:transform_rules"
+
+// maxModifyAttempts bounds optimistic-locking retries when several replicas
+// modify rules at once.
+const maxModifyAttempts = 10
+
+// RedisStore keeps rules in Redis so every ingest replica applies the same
+// rules.
+type RedisStore struct {
+	redis *redis.Client
+}
+
+// NewRedisStore creates a Redis-backed store.
+func NewRedisStore(redisURL string) (*RedisStore, error) {
+	opt, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opt)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("redis connection failed: %w", err)
+	}
+
+	return &RedisStore{redis: client}, nil
+}
+
+func (s *RedisStore) All(ctx context.Context) (map[string][]Rule, error) {
+	values, err := s.redis.HGetAll(ctx, rulesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transform rules: %w", err)
+	}
+
+	out := make(map[string][]Rule, len(values))
+	for clientID, data := range values {
+		var rules []Rule
+		if err := json.Unmarshal([]byte(data), &rules); err != nil {
+			return nil, fmt.Errorf("failed to decode transform rules of client %s: %w", clientID, err)
+		}
+		out[clientID] = rules
+	}
+	return out, nil
+}
+
+func (s *RedisStore) Modify(ctx context.Context, clientID string, fn func(rules []Rule) ([]Rule, error)) ([]Rule, error) {
+	var updated []Rule
+	modify := func(tx *redis.Tx) error {
+		var rules []Rule
+		data, err := tx.HGet(ctx, rulesKey, clientID).Bytes()
+		switch {
+		case errors.Is(err, redis.Nil):
+		case err != nil:
+			return fmt.Errorf("failed to get transform rules: %w", err)
+		default:
+			if err := json.Unmarshal(data, &rules); err != nil {
+				return fmt.Errorf("failed to decode transform rules: %w", err)
+			}
+		}
+
+		rules, err = fn(rules)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if len(rules) == 0 {
+				pipe.HDel(ctx, rulesKey, clientID)
+				return nil
+			}
+			data, err := json.Marshal(rules)
+			if err != nil {
+				return fmt.Errorf("failed to encode transform rules: %w", err)
+			}
+			pipe.HSet(ctx, rulesKey, clientID, data)
+			return nil
+		})
+		updated = rules
+		return err
+	}
+
+	for attempt := 0; attempt < maxModifyAttempts; attempt++ {
+		err := s.redis.Watch(ctx, modify, rulesKey)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return updated, nil
+	}
+	return nil, fmt.Errorf("failed to update transform rules: too much contention")
+}
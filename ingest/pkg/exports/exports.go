@@ -0,0 +1,179 @@
+// Package exports reads historical audit log exports and datasets so they
+// can be loaded through HEC without a live connection to the source.
+//
+// An export may be a JSON array of events, newline-delimited or concatenated
+// JSON objects, or CloudTrail log files ({"Records": [...]}), optionally
+// gzip-compressed. Each event is returned unchanged with the time it occurred,
+// so the ingest normalizers see the same record the live integration sends.
+// CSV files and raw text split by a parsing profile are read into the same
+// records (see ReadCSV and ReadRaw).
+package exports
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Record is one event from an export.
+type Record struct {
+	// Event is the raw JSON object.
+	Event json.RawMessage
+	// Time is when the event occurred, or zero when the record has no
+	// recognised timestamp.
+	Time time.Time
+}
+
+// timeFields are the timestamp fields checked in order: CloudTrail eventTime,
+// Okta published, GitHub @timestamp/created_at (epoch milliseconds) and
+// Splunk _time.
+var timeFields = []string{"eventTime", "published", "@timestamp", "created_at", "_time", "timestamp", "time"}
+
+// timeLayouts are the string timestamp layouts accepted besides epochs.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999Z07:00", "2006-01-02 15:04:05.999999999"}
+
+// Read streams the records of an export to fn, stopping at the first error
+// fn returns.
+func Read(r io.Reader, fn func(Record) error) error {
+	br, err := decompress(r)
+	if err != nil {
+		return err
+	}
+
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(br)
+	if first == '[' {
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("read export array: %w", err)
+		}
+		for i := 0; dec.More(); i++ {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return fmt.Errorf("read export record %d: %w", i, err)
+			}
+			if err := emit(raw, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := 0; ; i++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("read export record %d: %w", i, err)
+		}
+		if err := emit(raw, fn); err != nil {
+			return err
+		}
+	}
+}
+
+// emit passes a decoded object to fn, expanding CloudTrail log files into
+// their records.
+func emit(raw json.RawMessage, fn func(Record) error) error {
+	var envelope struct {
+		Records []json.RawMessage `json:"Records"`
+	}
+	if bytes.Contains(raw, []byte(`"Records"`)) && json.Unmarshal(raw, &envelope) == nil && envelope.Records != nil {
+		for _, record := range envelope.Records {
+			if err := fn(Record{Event: record, Time: recordTime(record)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fn(Record{Event: raw, Time: recordTime(raw)})
+}
+
+// recordTime returns the event time from the first timestamp field present,
+// accepting RFC 3339 and similar strings and epoch seconds or milliseconds.
+func recordTime(raw json.RawMessage) time.Time {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return time.Time{}
+	}
+	for _, name := range timeFields {
+		value, ok := fields[name]
+		if !ok {
+			continue
+		}
+		var s string
+		if json.Unmarshal(value, &s) == nil {
+			if t := parseTime(s); !t.IsZero() {
+				return t
+			}
+			continue
+		}
+		var epoch float64
+		if json.Unmarshal(value, &epoch) == nil {
+			return epochTime(epoch)
+		}
+	}
+	return time.Time{}
+}
+
+// parseTime parses a timestamp string, or returns zero.
+func parseTime(s string) time.Time {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	if epoch, err := strconv.ParseFloat(s, 64); err == nil {
+		return epochTime(epoch)
+	}
+	return time.Time{}
+}
+
+// epochTime converts epoch seconds, or milliseconds for values too large to
+// be seconds (after the year 5138).
+func epochTime(epoch float64) time.Time {
+	if epoch >= 1e11 {
+		return time.UnixMilli(int64(epoch)).UTC()
+	}
+	return time.UnixMilli(int64(epoch * 1000)).UTC()
+}
+
+// decompress returns a reader of r, gunzipping it if it is gzip-compressed.
+func decompress(r io.Reader) (*bufio.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("open gzip export: %w", err)
+		}
+		return bufio.NewReader(gz), nil
+	}
+	return br, nil
+}
+
+// peekNonSpace skips leading whitespace and returns the next byte without
+// consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, br.UnreadByte()
+	}
+}
@@ -0,0 +1,82 @@
+package exports
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, data []byte) []Record {
+	t.Helper()
+	var records []Record
+	require.NoError(t, Read(bytes.NewReader(data), func(r Record) error {
+		records = append(records, r)
+		return nil
+	}))
+	return records
+}
+
+func TestRead_Array(t *testing.T) {
+	records := readAll(t, []byte(` [
+		{"uuid": "a", "published": "2026-03-01T10:00:00.123Z"},
+		{"uuid": "b", "published": "2026-03-01T10:00:01Z"}
+	]`))
+
+	require.Len(t, records, 2)
+	assert.JSONEq(t, `{"uuid": "a", "published": "2026-03-01T10:00:00.123Z"}`, string(records[0].Event))
+	assert.Equal(t, time.Date(2026, 3, 1, 10, 0, 0, 123000000, time.UTC), records[0].Time.UTC())
+}
+
+func TestRead_NDJSON(t *testing.T) {
+	records := readAll(t, []byte(`{"action": "repo.create", "@timestamp": 1772359200000}
+{"action": "repo.destroy", "created_at": "1772359201000"}
+{"action": "org.update_member"}
+`))
+
+	require.Len(t, records, 3)
+	assert.Equal(t, time.UnixMilli(1772359200000).UTC(), records[0].Time)
+	assert.Equal(t, time.UnixMilli(1772359201000).UTC(), records[1].Time)
+	assert.True(t, records[2].Time.IsZero())
+}
+
+func TestRead_CloudTrailLogFiles(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(`{"Records": [
+		{"eventName": "ConsoleLogin", "eventTime": "2026-03-01T10:00:00Z"},
+		{"eventName": "CreateUser", "eventTime": "2026-03-01T10:05:00Z"}
+	]}
+	{"Records": [{"eventName": "DeleteUser", "eventTime": "2026-03-01T10:10:00Z"}]}`))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	records := readAll(t, buf.Bytes())
+
+	require.Len(t, records, 3)
+	assert.Contains(t, string(records[1].Event), "CreateUser")
+	assert.Equal(t, time.Date(2026, 3, 1, 10, 10, 0, 0, time.UTC), records[2].Time)
+}
+
+func TestRead_Empty(t *testing.T) {
+	assert.Empty(t, readAll(t, []byte("  \n")))
+}
+
+func TestRead_Errors(t *testing.T) {
+	err := Read(strings.NewReader(`{"a": 1} {"b": `), func(Record) error { return nil })
+	assert.ErrorContains(t, err, "record 1")
+
+	stop := errors.New("stop")
+	calls := 0
+	err = Read(strings.NewReader(`[{"a": 1}, {"a": 2}]`), func(Record) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
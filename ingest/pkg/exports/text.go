@@ -0,0 +1,149 @@
+package exports
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/parsing"
+)
+
+// Format is the layout of an export file.
+type Format string
+
+const (
+	// FormatJSON is a JSON array, NDJSON or CloudTrail log files (Read).
+	FormatJSON Format = "json"
+	// FormatCSV is CSV with a header row (ReadCSV).
+	FormatCSV Format = "csv"
+	// FormatRaw is text split into events by a parsing profile (ReadRaw).
+	FormatRaw Format = "raw"
+)
+
+// rawChunkSize is how much raw text is parsed at a time.
+var rawChunkSize = 1 << 20
+
+// DetectFormat guesses the format of a file from its extension (ignoring
+// .gz) or, failing that, from whether its content starts with a JSON object
+// or array. Content is read from r, so callers rewind it afterwards.
+func DetectFormat(name string, r io.Reader) (Format, error) {
+	switch strings.ToLower(filepath.Ext(strings.TrimSuffix(strings.ToLower(name), ".gz"))) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json", ".jsonl", ".ndjson":
+		return FormatJSON, nil
+	}
+
+	br, err := decompress(r)
+	if err != nil {
+		return "", err
+	}
+	first, err := peekNonSpace(br)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if first == '{' || first == '[' {
+		return FormatJSON, nil
+	}
+	return FormatRaw, nil
+}
+
+// ReadCSV streams the rows of a CSV file with a header row to fn, each as a
+// JSON object keyed by column name. Empty cells are omitted.
+func ReadCSV(r io.Reader, fn func(Record) error) error {
+	br, err := decompress(r)
+	if err != nil {
+		return err
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read csv header: %w", err)
+	}
+
+	for i := 0; ; i++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read csv record %d: %w", i, err)
+		}
+		fields := make(map[string]string, len(header))
+		for j, value := range row {
+			if j < len(header) && value != "" {
+				fields[header[j]] = value
+			}
+		}
+		raw, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		if err := fn(Record{Event: raw, Time: recordTime(raw)}); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadRaw streams raw text split into events by a parsing profile to fn.
+// Each event is the flat JSON payload ingest builds for profiled raw data
+// (extracted fields, message and time). The text is parsed a chunk of lines
+// at a time; the last event of each chunk is parsed again with the next
+// chunk, since it may continue there.
+func ReadRaw(r io.Reader, parser *parsing.Parser, fn func(Record) error) error {
+	br, err := decompress(r)
+	if err != nil {
+		return err
+	}
+
+	var carry string
+	for {
+		chunk, err := readLines(br, rawChunkSize)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		done := err == io.EOF
+
+		events := parser.Parse([]byte(carry + chunk))
+		carry = ""
+		if !done && len(events) > 0 {
+			carry = events[len(events)-1].Text + "\n"
+			events = events[:len(events)-1]
+		}
+		for _, event := range events {
+			raw, err := json.Marshal(event.Payload())
+			if err != nil {
+				return err
+			}
+			if err := fn(Record{Event: raw, Time: event.Time}); err != nil {
+				return err
+			}
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// readLines reads whole lines until at least size bytes have been read,
+// returning io.EOF with the final chunk.
+func readLines(br *bufio.Reader, size int) (string, error) {
+	var chunk strings.Builder
+	for chunk.Len() < size {
+		line, err := br.ReadString('\n')
+		chunk.WriteString(line)
+		if err != nil {
+			return chunk.String(), err
+		}
+	}
+	return chunk.String(), nil
+}
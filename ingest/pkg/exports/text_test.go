@@ -0,0 +1,89 @@
+package exports
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telhawk-systems/telhawk-stack/ingest/pkg/parsing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, _ = w.Write([]byte(`  {"a": 1}`))
+	require.NoError(t, w.Close())
+
+	tests := []struct {
+		name    string
+		content string
+		want    Format
+	}{
+		{"users.csv", "name,uid\n", FormatCSV},
+		{"users.CSV.gz", "", FormatCSV},
+		{"audit.ndjson", "not json", FormatJSON},
+		{"okta-export", `[{"uuid": "a"}]`, FormatJSON},
+		{"events.gz", gz.String(), FormatJSON},
+		{"windows-sysmon.log", "<Event xmlns=...>", FormatRaw},
+		{"empty.log", "", FormatRaw},
+	}
+	for _, tt := range tests {
+		format, err := DetectFormat(tt.name, strings.NewReader(tt.content))
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, format, tt.name)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	var records []Record
+	err := ReadCSV(strings.NewReader("_time,user,action,note\n2026-03-01T10:00:00Z,alice,login,\n1772359260,bob,logout,\"a, b\"\n"), func(r Record) error {
+		records = append(records, r)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, records, 2)
+	assert.JSONEq(t, `{"_time": "2026-03-01T10:00:00Z", "user": "alice", "action": "login"}`, string(records[0].Event))
+	assert.Equal(t, time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), records[0].Time.UTC())
+	assert.Equal(t, time.Date(2026, 3, 1, 10, 1, 0, 0, time.UTC), records[1].Time)
+	assert.Contains(t, string(records[1].Event), `"note":"a, b"`)
+}
+
+func TestReadRaw_ChunksKeepMultiLineEvents(t *testing.T) {
+	defer func(size int) { rawChunkSize = size }(rawChunkSize)
+	rawChunkSize = 16
+
+	parser, err := parsing.Compile(parsing.Profile{
+		SourceType:      "java:app",
+		ShouldLineMerge: true,
+		BreakOnlyBefore: `^\d{4}-`,
+		TimeFormat:      "%Y-%m-%d %H:%M:%S",
+	})
+	require.NoError(t, err)
+
+	text := "2026-03-01 09:15:30 ERROR boom\n\tat Handler.handle\n\tat Server.serve\n2026-03-01 09:15:31 INFO ok\n2026-03-01 09:15:32 INFO done"
+	var records []Record
+	require.NoError(t, ReadRaw(strings.NewReader(text), parser, func(r Record) error {
+		records = append(records, r)
+		return nil
+	}))
+
+	require.Len(t, records, 3)
+	var first map[string]interface{}
+	require.NoError(t, json.Unmarshal(records[0].Event, &first))
+	assert.Equal(t, "2026-03-01 09:15:30 ERROR boom\n\tat Handler.handle\n\tat Server.serve", first["message"])
+	assert.Equal(t, time.Date(2026, 3, 1, 9, 15, 30, 0, time.UTC), records[0].Time.UTC())
+	assert.Equal(t, time.Date(2026, 3, 1, 9, 15, 32, 0, time.UTC), records[2].Time.UTC())
+}
+
+func TestRecordTime_Epochs(t *testing.T) {
+	assert.Equal(t, time.Unix(1772359200, 500000000).UTC(), recordTime(json.RawMessage(`{"time": 1772359200.5}`)))
+	assert.Equal(t, time.UnixMilli(1772359200123).UTC(), recordTime(json.RawMessage(`{"timestamp": 1772359200123}`)))
+	assert.Equal(t, time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), recordTime(json.RawMessage(`{"_time": "2026-03-01 10:00:00"}`)))
+}
@@ -36,11 +36,19 @@ func ValidateEventFormat(event interface{}) error {
 	return nil
 }
 
+// Splunk HEC status codes (https://docs.splunk.com/Documentation/Splunk/latest/Data/TroubleshootHTTPEventCollector).
+// Clients switch on the code, so these must match Splunk exactly.
 var (
-	ErrInvalidEvent = &HECError{Code: 6, Text: "Invalid data format"}
-	ErrNoData       = &HECError{Code: 5, Text: "No data"}
-	ErrUnauthorized = &HECError{Code: 4, Text: "Invalid authorization"}
-	ErrServerBusy   = &HECError{Code: 9, Text: "Server is busy"}
+	ErrTokenRequired           = &HECError{Code: 2, Text: "Token is required"}
+	ErrUnauthorized            = &HECError{Code: 3, Text: "Invalid authorization"}
+	ErrInvalidToken            = &HECError{Code: 4, Text: "Invalid token"}
+	ErrNoData                  = &HECError{Code: 5, Text: "No data"}
+	ErrInvalidEvent            = &HECError{Code: 6, Text: "Invalid data format"}
+	ErrServerBusy              = &HECError{Code: 9, Text: "Server is busy"}
+	ErrEventRequired           = &HECError{Code: 12, Text: "Event field is required"}
+	ErrEventBlank              = &HECError{Code: 13, Text: "Event field cannot be blank"}
+	ErrIndexedFields           = &HECError{Code: 15, Text: "Error in handling indexed fields"}
+	ErrQueryStringAuthDisabled = &HECError{Code: 16, Text: "Query string authorization is not enabled"}
 )
 
 type HECError struct {
@@ -186,6 +186,11 @@ func TestHECErrorCodes(t *testing.T) {
 		{
 			name:     "Unauthorized code",
 			hecError: ErrUnauthorized,
+			code:     3,
+		},
+		{
+			name:     "Invalid token code",
+			hecError: ErrInvalidToken,
 			code:     4,
 		},
 		{
@@ -193,6 +198,31 @@ func TestHECErrorCodes(t *testing.T) {
 			hecError: ErrServerBusy,
 			code:     9,
 		},
+		{
+			name:     "Token required code",
+			hecError: ErrTokenRequired,
+			code:     2,
+		},
+		{
+			name:     "Event required code",
+			hecError: ErrEventRequired,
+			code:     12,
+		},
+		{
+			name:     "Event blank code",
+			hecError: ErrEventBlank,
+			code:     13,
+		},
+		{
+			name:     "Indexed fields code",
+			hecError: ErrIndexedFields,
+			code:     15,
+		},
+		{
+			name:     "Query string auth disabled code",
+			hecError: ErrQueryStringAuthDisabled,
+			code:     16,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,104 @@
+package mapping
+
+import (
+	"reflect"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/application"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/discovery"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/findings"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/iam"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/network"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/remediation"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/system"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf/events/unmanned_systems"
+)
+
+// classes maps each OCSF class_uid to the common/ocsf constructor for that
+// class, so mapped events start with the same base fields as generated ones.
+// Each returns a pointer to the class struct, which embeds ocsf.Event and
+// declares the class attributes.
+var classes = map[int]func(activityID int) interface{}{
+	1001: func(a int) interface{} { return system.NewFileActivity(a) },
+	1002: func(a int) interface{} { return system.NewKernelExtensionActivity(a) },
+	1003: func(a int) interface{} { return system.NewKernelActivity(a) },
+	1004: func(a int) interface{} { return system.NewMemoryActivity(a) },
+	1005: func(a int) interface{} { return system.NewModuleActivity(a) },
+	1006: func(a int) interface{} { return system.NewScheduledJobActivity(a) },
+	1007: func(a int) interface{} { return system.NewProcessActivity(a) },
+	1008: func(a int) interface{} { return system.NewEventLogActvity(a) },
+	1009: func(a int) interface{} { return system.NewScriptActivity(a) },
+	1010: func(a int) interface{} { return system.NewPeripheralActivity(a) },
+	2000: func(a int) interface{} { return findings.NewFinding(a) },
+	2001: func(a int) interface{} { return findings.NewSecurityFinding(a) },
+	2002: func(a int) interface{} { return findings.NewVulnerabilityFinding(a) },
+	2003: func(a int) interface{} { return findings.NewComplianceFinding(a) },
+	2004: func(a int) interface{} { return findings.NewDetectionFinding(a) },
+	2005: func(a int) interface{} { return findings.NewIncidentFinding(a) },
+	2006: func(a int) interface{} { return findings.NewDataSecurityFinding(a) },
+	2007: func(a int) interface{} { return findings.NewApplicationSecurityPostureFinding(a) },
+	2008: func(a int) interface{} { return findings.NewIamAnalysisFinding(a) },
+	3001: func(a int) interface{} { return iam.NewAccountChange(a) },
+	3002: func(a int) interface{} { return iam.NewAuthentication(a) },
+	3003: func(a int) interface{} { return iam.NewAuthorizeSession(a) },
+	3004: func(a int) interface{} { return iam.NewEntityManagement(a) },
+	3005: func(a int) interface{} { return iam.NewUserAccess(a) },
+	3006: func(a int) interface{} { return iam.NewGroupManagement(a) },
+	4001: func(a int) interface{} { return network.NewNetworkActivity(a) },
+	4002: func(a int) interface{} { return network.NewHttpActivity(a) },
+	4003: func(a int) interface{} { return network.NewDnsActivity(a) },
+	4004: func(a int) interface{} { return network.NewDhcpActivity(a) },
+	4005: func(a int) interface{} { return network.NewRdpActivity(a) },
+	4006: func(a int) interface{} { return network.NewSmbActivity(a) },
+	4007: func(a int) interface{} { return network.NewSshActivity(a) },
+	4008: func(a int) interface{} { return network.NewFtpActivity(a) },
+	4009: func(a int) interface{} { return network.NewEmailActivity(a) },
+	4010: func(a int) interface{} { return network.NewNetworkFileActivity(a) },
+	4011: func(a int) interface{} { return network.NewEmailFileActivity(a) },
+	4012: func(a int) interface{} { return network.NewEmailUrlActivity(a) },
+	4013: func(a int) interface{} { return network.NewNtpActivity(a) },
+	4014: func(a int) interface{} { return network.NewTunnelActivity(a) },
+	5000: func(a int) interface{} { return discovery.NewDiscoveryResult(a) },
+	5001: func(a int) interface{} { return discovery.NewInventoryInfo(a) },
+	5002: func(a int) interface{} { return discovery.NewConfigState(a) },
+	5003: func(a int) interface{} { return discovery.NewUserInventory(a) },
+	5004: func(a int) interface{} { return discovery.NewPatchState(a) },
+	5006: func(a int) interface{} { return discovery.NewKernelObjectQuery(a) },
+	5007: func(a int) interface{} { return discovery.NewFileQuery(a) },
+	5008: func(a int) interface{} { return discovery.NewFolderQuery(a) },
+	5009: func(a int) interface{} { return discovery.NewAdminGroupQuery(a) },
+	5010: func(a int) interface{} { return discovery.NewJobQuery(a) },
+	5011: func(a int) interface{} { return discovery.NewModuleQuery(a) },
+	5012: func(a int) interface{} { return discovery.NewNetworkConnectionQuery(a) },
+	5013: func(a int) interface{} { return discovery.NewNetworksQuery(a) },
+	5014: func(a int) interface{} { return discovery.NewPeripheralDeviceQuery(a) },
+	5015: func(a int) interface{} { return discovery.NewProcessQuery(a) },
+	5016: func(a int) interface{} { return discovery.NewServiceQuery(a) },
+	5017: func(a int) interface{} { return discovery.NewSessionQuery(a) },
+	5018: func(a int) interface{} { return discovery.NewUserQuery(a) },
+	5019: func(a int) interface{} { return discovery.NewDeviceConfigStateChange(a) },
+	5020: func(a int) interface{} { return discovery.NewSoftwareInfo(a) },
+	5021: func(a int) interface{} { return discovery.NewOsintInventoryInfo(a) },
+	5022: func(a int) interface{} { return discovery.NewStartupItemQuery(a) },
+	5023: func(a int) interface{} { return discovery.NewCloudResourcesInventoryInfo(a) },
+	5040: func(a int) interface{} { return discovery.NewEvidenceInfo(a) },
+	6001: func(a int) interface{} { return application.NewWebResourcesActivity(a) },
+	6002: func(a int) interface{} { return application.NewApplicationLifecycle(a) },
+	6003: func(a int) interface{} { return application.NewApiActivity(a) },
+	6004: func(a int) interface{} { return application.NewWebResourceAccessActivity(a) },
+	6005: func(a int) interface{} { return application.NewDatastoreActivity(a) },
+	6006: func(a int) interface{} { return application.NewFileHosting(a) },
+	6007: func(a int) interface{} { return application.NewScanActivity(a) },
+	6008: func(a int) interface{} { return application.NewApplicationError(a) },
+	7001: func(a int) interface{} { return remediation.NewRemediationActivity(a) },
+	7002: func(a int) interface{} { return remediation.NewFileRemediationActivity(a) },
+	7003: func(a int) interface{} { return remediation.NewProcessRemediationActivity(a) },
+	7004: func(a int) interface{} { return remediation.NewNetworkRemediationActivity(a) },
+	8001: func(a int) interface{} { return unmanned_systems.NewDroneFlightsActivity(a) },
+	8002: func(a int) interface{} { return unmanned_systems.NewAirborneBroadcastActivity(a) },
+}
+
+// baseEvent returns the ocsf.Event embedded in a class struct.
+func baseEvent(class interface{}) *ocsf.Event {
+	return reflect.ValueOf(class).Elem().FieldByName("Event").Addr().Interface().(*ocsf.Event)
+}
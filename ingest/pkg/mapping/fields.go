@@ -0,0 +1,373 @@
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+)
+
+var (
+	eventType = reflect.TypeOf(ocsf.Event{})
+	timeType  = reflect.TypeOf(time.Time{})
+)
+
+// reservedTargets are derived from the definition's class and cannot be
+// mapped.
+var reservedTargets = map[string]bool{
+	"category_uid": true,
+	"class_uid":    true,
+	"type_uid":     true,
+	"category":     true,
+	"class":        true,
+	"raw":          true,
+}
+
+// kind is the type of value a target field holds.
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindFloat
+	kindBool
+	kindTime
+	kindStrings
+)
+
+func (k kind) String() string {
+	return [...]string{"string", "int", "float", "bool", "timestamp", "string list"}[k]
+}
+
+// target is a field of a class struct resolved from its JSON path, e.g.
+// "src_endpoint.ip", "properties.vendor_id" or, for Authentication,
+// "auth_protocol".
+type target struct {
+	path   string
+	fields []int  // Struct field indices from the class struct to the leaf
+	key    string // Map key, when the leaf is a map[string]string entry
+	isMap  bool
+	kind   kind
+
+	// classField is the top-level class attribute the path starts in, or
+	// empty when it starts in the embedded ocsf.Event
+	classField string
+}
+
+// resolveTarget validates a JSON path against a class struct type, which
+// embeds ocsf.Event. Paths resolve against ocsf.Event first, as its fields
+// win over class attributes of the same name when the event is stored.
+func resolveTarget(classType reflect.Type, path string) (*target, error) {
+	if path == "" {
+		return nil, fmt.Errorf("target is required")
+	}
+	if reservedTargets[path] || strings.HasPrefix(path, "raw.") {
+		return nil, fmt.Errorf("target %s is set from class_uid and cannot be mapped", path)
+	}
+
+	t := &target{path: path}
+	typ := classType
+	parts := strings.Split(path, ".")
+	for i, name := range parts {
+		if i == 0 {
+			if field, ok := fieldByJSONName(eventType, name); ok {
+				event, _ := classType.FieldByName("Event")
+				t.fields = append(t.fields, event.Index[0], field.Index[0])
+				typ = field.Type
+				continue
+			}
+		}
+
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() == reflect.Map {
+			if typ.Key().Kind() != reflect.String || typ.Elem().Kind() != reflect.String || i != len(parts)-1 {
+				return nil, fmt.Errorf("target %s: unsupported map field", path)
+			}
+			t.key, t.isMap, t.kind = name, true, kindString
+			return t, nil
+		}
+		if typ.Kind() != reflect.Struct || typ == timeType {
+			return nil, fmt.Errorf("target %s: %s is not an object", path, strings.Join(parts[:i], "."))
+		}
+		field, ok := fieldByJSONName(typ, name)
+		if !ok {
+			return nil, fmt.Errorf("target %s: no OCSF field %s", path, strings.Join(parts[:i+1], "."))
+		}
+		if i == 0 {
+			t.classField = name
+		}
+		t.fields = append(t.fields, field.Index[0])
+		typ = field.Type
+	}
+
+	switch {
+	case typ == timeType:
+		t.kind = kindTime
+	case typ.Kind() == reflect.String:
+		t.kind = kindString
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Int64:
+		t.kind = kindInt
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		t.kind = kindFloat
+	case typ.Kind() == reflect.Bool:
+		t.kind = kindBool
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.String:
+		t.kind = kindStrings
+	case typ.Kind() == reflect.Map:
+		return nil, fmt.Errorf("target %s: map fields need a key (e.g. %s.name)", path, path)
+	default:
+		return nil, fmt.Errorf("target %s: %s fields cannot be mapped directly", path, typ)
+	}
+	return t, nil
+}
+
+// fieldByJSONName finds the struct field whose JSON name is name.
+func fieldByJSONName(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// set coerces value to the target's type and stores it in class, a pointer
+// to a class struct, allocating nested objects as needed.
+func (t *target) set(class interface{}, value interface{}) error {
+	converted, err := coerce(value, t.kind)
+	if err != nil {
+		return fmt.Errorf("field %s: %w", t.path, err)
+	}
+
+	v := reflect.ValueOf(class).Elem()
+	for _, index := range t.fields {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(index)
+	}
+	if t.isMap {
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(reflect.ValueOf(t.key), reflect.ValueOf(converted))
+		return nil
+	}
+	v.Set(reflect.ValueOf(converted).Convert(v.Type()))
+	return nil
+}
+
+// isSet reports whether the target holds a non-zero value.
+func (t *target) isSet(class interface{}) bool {
+	v := reflect.ValueOf(class).Elem()
+	for _, index := range t.fields {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(index)
+	}
+	if t.isMap {
+		return !v.IsNil() && v.MapIndex(reflect.ValueOf(t.key)).IsValid()
+	}
+	return !v.IsZero()
+}
+
+// coerce converts a decoded JSON value (or a value from a definition) to the
+// Go type for k.
+func coerce(value interface{}, k kind) (interface{}, error) {
+	switch k {
+	case kindString:
+		return toString(value), nil
+	case kindInt:
+		f, err := toFloat(value)
+		if err != nil {
+			return nil, err
+		}
+		if f != math.Trunc(f) {
+			return nil, fmt.Errorf("cannot convert %v to int", value)
+		}
+		return int64(f), nil
+	case kindFloat:
+		return toFloat(value)
+	case kindBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %q to bool", v)
+			}
+			return b, nil
+		case float64:
+			return v != 0, nil
+		case int:
+			return v != 0, nil
+		}
+		return nil, fmt.Errorf("cannot convert %v to bool", value)
+	case kindTime:
+		return parseTime(value, "")
+	case kindStrings:
+		if items, ok := value.([]interface{}); ok {
+			out := make([]string, len(items))
+			for i, item := range items {
+				out[i] = toString(item)
+			}
+			return out, nil
+		}
+		return []string{toString(value)}, nil
+	}
+	return nil, fmt.Errorf("unsupported field type")
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("cannot convert %q to a number", v)
+		}
+		return f, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("cannot convert %v to a number", value)
+}
+
+// timeLayouts are tried in order for string timestamps without a layout.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// parseTime converts epoch numbers or strings to a time. layout is
+// "epoch", "epoch_ms", "epoch_us", "epoch_ns", a Go time layout, or empty to
+// guess: numbers are epoch seconds (or milliseconds when too large for
+// seconds) and strings are tried against common layouts.
+func parseTime(value interface{}, layout string) (time.Time, error) {
+	if t, ok := value.(time.Time); ok {
+		return t, nil
+	}
+
+	unit := 0.0 // Nanoseconds per unit of an epoch number
+	switch layout {
+	case "epoch":
+		unit = 1e9
+	case "epoch_ms":
+		unit = 1e6
+	case "epoch_us":
+		unit = 1e3
+	case "epoch_ns":
+		unit = 1
+	case "":
+		if f, err := toFloat(value); err == nil {
+			unit = 1e9
+			if math.Abs(f) >= 1e12 {
+				unit = 1e6
+			}
+		}
+	}
+	if unit != 0 {
+		f, err := toFloat(value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cannot convert %v to a timestamp", value)
+		}
+		whole, frac := math.Modf(f)
+		return time.Unix(0, int64(whole)*int64(unit)+int64(math.Round(frac*unit))).UTC(), nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("cannot convert %v to a timestamp", value)
+	}
+	layouts := timeLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	for _, l := range layouts {
+		if t, err := time.Parse(l, strings.TrimSpace(s)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse timestamp %q", s)
+}
+
+// lookupPath returns the value at a dotted path in a decoded JSON payload.
+// A key containing dots matches before the path is split, and numeric
+// segments index arrays.
+func lookupPath(value interface{}, path string) (interface{}, bool) {
+	for path != "" {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if found, ok := v[path]; ok {
+				return found, true
+			}
+			head, rest, _ := strings.Cut(path, ".")
+			next, ok := v[head]
+			if !ok {
+				return nil, false
+			}
+			value, path = next, rest
+		case []interface{}:
+			head, rest, _ := strings.Cut(path, ".")
+			i, err := strconv.Atoi(head)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value, path = v[i], rest
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,530 @@
+// Package mapping maps JSON payloads to OCSF events using declarative
+// definitions loaded at runtime, so a source can be added or fixed by
+// editing YAML instead of regenerating the generated normalizers.
+//
+// A definition matches sourcetypes, names the OCSF class and lists field
+// rules that are applied in order:
+//
+//	name: okta-auth
+//	sourcetypes: ["okta:auth", "okta:system*"]
+//	class_uid: 3002
+//	activity_id: 1
+//	fields:
+//	  - {op: copy, from: actor.alternateId, to: actor.user.name}
+//	  - {op: rename, from: client.ipAddress, to: src_endpoint.ip}
+//	  - {op: convert, from: published, to: time, type: timestamp}
+//	  - {op: lookup, from: outcome.result, to: status_id, values: {SUCCESS: 1, FAILURE: 2}, fallback: 99}
+//	  - {op: default, to: device.hostname, value: okta.com}
+//	  - {op: template, to: metadata.product.name, template: Okta}
+//	  - {op: template, to: properties.summary, template: "{{eventType}} by {{actor.alternateId}}"}
+//
+// Targets are JSON paths into the class's OCSF type, ocsf.Event plus the
+// class attributes (e.g. auth_protocol for Authentication), and are checked
+// against it when the definition is compiled. Class attributes are stored in
+// the event's ClassAttributes.
+package mapping
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule operations.
+const (
+	OpCopy     = "copy"     // Copy from to to, coercing to the target type
+	OpRename   = "rename"   // Copy, and leave the source out of unmapped fields
+	OpConvert  = "convert"  // Copy, converting with type (and layout for timestamps)
+	OpLookup   = "lookup"   // Map the source value through values
+	OpDefault  = "default"  // Set value when the target is still empty
+	OpTemplate = "template" // Render template with {{path}} placeholders
+)
+
+// Definition is one mapping as written in YAML.
+type Definition struct {
+	Name        string   `yaml:"name"`
+	SourceTypes []string `yaml:"sourcetypes"` // Glob patterns (path.Match syntax)
+	Format      string   `yaml:"format"`      // Envelope format; defaults to json
+	ClassUID    int      `yaml:"class_uid"`
+	ActivityID  int      `yaml:"activity_id"`
+
+	// KeepUnmapped copies payload fields that no rule renamed into
+	// properties, flattened to dotted keys.
+	KeepUnmapped bool   `yaml:"keep_unmapped"`
+	Fields       []Rule `yaml:"fields"`
+}
+
+// Rule is one field operation.
+type Rule struct {
+	Op       string                 `yaml:"op"`
+	From     string                 `yaml:"from"`
+	To       string                 `yaml:"to"`
+	Type     string                 `yaml:"type"`     // convert: string, int, float, bool, timestamp
+	Layout   string                 `yaml:"layout"`   // convert timestamp: epoch, epoch_ms, epoch_us, epoch_ns or a Go layout
+	Values   map[string]interface{} `yaml:"values"`   // lookup: source value -> target value (case-insensitive)
+	Fallback interface{}            `yaml:"fallback"` // lookup: value when nothing matches
+	Value    interface{}            `yaml:"value"`    // default
+	Template string                 `yaml:"template"` // template
+}
+
+// Meta describes where a payload came from.
+type Meta struct {
+	Source     string
+	SourceType string
+	Format     string
+	ReceivedAt time.Time
+}
+
+// Mapping is a compiled Definition.
+type Mapping struct {
+	def         Definition
+	newClass    func(activityID int) interface{}
+	rules       []compiledRule
+	renamed     map[string]bool
+	explicit    map[string]bool // Targets set by rules
+	classFields map[string]int  // Class attributes set by rules -> class struct field index
+}
+
+type compiledRule struct {
+	Rule
+	target   *target
+	convert  kind
+	lookup   map[string]interface{}
+	fallback interface{}
+	value    interface{}
+	segments []templateSegment
+}
+
+type templateSegment struct {
+	text string
+	path string // Placeholder when non-empty
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// Compile validates a definition against the OCSF types.
+func Compile(def Definition) (*Mapping, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("mapping is missing name")
+	}
+	errorf := func(format string, args ...interface{}) error {
+		return fmt.Errorf("mapping %s: %s", def.Name, fmt.Sprintf(format, args...))
+	}
+	if len(def.SourceTypes) == 0 {
+		return nil, errorf("sourcetypes is required")
+	}
+	for _, pattern := range def.SourceTypes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errorf("sourcetype pattern %q: %v", pattern, err)
+		}
+	}
+	if def.Format == "" {
+		def.Format = "json"
+	}
+	newClass, ok := classes[def.ClassUID]
+	if !ok {
+		return nil, errorf("unknown OCSF class_uid %d", def.ClassUID)
+	}
+	if def.ActivityID != 0 && def.ActivityID != 99 && ocsf.ActivityName(def.ClassUID, def.ActivityID) == "" {
+		return nil, errorf("activity_id %d is not defined for class %s", def.ActivityID, ocsf.ClassName(def.ClassUID))
+	}
+
+	m := &Mapping{
+		def:         def,
+		newClass:    newClass,
+		renamed:     make(map[string]bool),
+		explicit:    make(map[string]bool),
+		classFields: make(map[string]int),
+	}
+	classType := reflect.TypeOf(newClass(0)).Elem()
+	for i, rule := range def.Fields {
+		compiled, err := compileRule(classType, rule)
+		if err != nil {
+			return nil, errorf("fields[%d]: %v", i, err)
+		}
+		if name := compiled.target.classField; name != "" {
+			m.classFields[name] = compiled.target.fields[0]
+		}
+		if rule.Op == OpRename {
+			m.renamed[rule.From] = true
+		}
+		m.explicit[rule.To] = true
+		m.rules = append(m.rules, compiled)
+	}
+	return m, nil
+}
+
+func compileRule(classType reflect.Type, rule Rule) (compiledRule, error) {
+	c := compiledRule{Rule: rule}
+	var err error
+	if c.target, err = resolveTarget(classType, rule.To); err != nil {
+		return c, err
+	}
+
+	switch rule.Op {
+	case OpCopy, OpRename, OpConvert, OpLookup:
+		if rule.From == "" {
+			return c, fmt.Errorf("%s to %s requires from", rule.Op, rule.To)
+		}
+	}
+
+	switch rule.Op {
+	case OpCopy, OpRename:
+	case OpConvert:
+		kinds := map[string]kind{"string": kindString, "int": kindInt, "float": kindFloat, "bool": kindBool, "timestamp": kindTime}
+		k, ok := kinds[rule.Type]
+		if !ok {
+			return c, fmt.Errorf("convert type %q must be one of string, int, float, bool, timestamp", rule.Type)
+		}
+		if !convertible(k, c.target.kind) {
+			return c, fmt.Errorf("cannot store %s in %s (%s)", k, rule.To, c.target.kind)
+		}
+		if rule.Layout != "" && k != kindTime {
+			return c, fmt.Errorf("layout only applies to timestamp conversions")
+		}
+		c.convert = k
+	case OpLookup:
+		if len(rule.Values) == 0 {
+			return c, fmt.Errorf("lookup to %s requires values", rule.To)
+		}
+		c.lookup = make(map[string]interface{}, len(rule.Values))
+		for key, value := range rule.Values {
+			converted, err := coerce(value, c.target.kind)
+			if err != nil {
+				return c, fmt.Errorf("lookup value for %q: %v for %s", key, err, rule.To)
+			}
+			c.lookup[strings.ToLower(key)] = converted
+		}
+		if rule.Fallback != nil {
+			if c.fallback, err = coerce(rule.Fallback, c.target.kind); err != nil {
+				return c, fmt.Errorf("lookup fallback: %v for %s", err, rule.To)
+			}
+		}
+	case OpDefault:
+		if rule.Value == nil {
+			return c, fmt.Errorf("default for %s requires value", rule.To)
+		}
+		if c.value, err = coerce(rule.Value, c.target.kind); err != nil {
+			return c, fmt.Errorf("default value: %v for %s", err, rule.To)
+		}
+	case OpTemplate:
+		if rule.Template == "" {
+			return c, fmt.Errorf("template for %s requires template", rule.To)
+		}
+		if c.segments, err = compileTemplate(rule.Template); err != nil {
+			return c, err
+		}
+	case "":
+		return c, fmt.Errorf("op is required for %s", rule.To)
+	default:
+		return c, fmt.Errorf("unknown op %q", rule.Op)
+	}
+	return c, nil
+}
+
+// convertible reports whether a converted value of kind from can be stored
+// in a field of kind to.
+func convertible(from, to kind) bool {
+	switch {
+	case from == to, to == kindString, to == kindStrings:
+		return true
+	case from == kindTime || to == kindTime:
+		return false
+	case from == kindString:
+		return true
+	case to == kindBool || from == kindBool:
+		return false
+	}
+	return true // int <-> float
+}
+
+func compileTemplate(tmpl string) ([]templateSegment, error) {
+	var segments []templateSegment
+	last := 0
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(tmpl, -1) {
+		if text := tmpl[last:m[0]]; text != "" {
+			segments = append(segments, templateSegment{text: text})
+		}
+		segments = append(segments, templateSegment{path: tmpl[m[2]:m[3]]})
+		last = m[1]
+	}
+	if rest := tmpl[last:]; rest != "" {
+		segments = append(segments, templateSegment{text: rest})
+	}
+	for _, s := range segments {
+		if strings.Contains(s.text, "{{") || strings.Contains(s.text, "}}") {
+			return nil, fmt.Errorf("template %q has an invalid placeholder", tmpl)
+		}
+	}
+	return segments, nil
+}
+
+// Name returns the definition name.
+func (m *Mapping) Name() string {
+	return m.def.Name
+}
+
+// Matches reports whether the mapping handles envelopes with this format and
+// sourcetype.
+func (m *Mapping) Matches(format, sourceType string) bool {
+	if format != m.def.Format {
+		return false
+	}
+	for _, pattern := range m.def.SourceTypes {
+		if ok, _ := path.Match(pattern, sourceType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply maps a decoded JSON payload to an OCSF event.
+func (m *Mapping) Apply(payload map[string]interface{}, meta Meta) (*ocsf.Event, error) {
+	class := m.newClass(m.def.ActivityID)
+	event := baseEvent(class)
+	event.Time = meta.ReceivedAt
+	event.ObservedTime = meta.ReceivedAt
+	event.Metadata.LogProvider = meta.Source
+	event.Raw = ocsf.RawDescriptor{Format: meta.Format, Data: payload}
+
+	if m.def.KeepUnmapped {
+		event.Properties = make(map[string]string)
+		flatten(payload, "", func(key string, value interface{}) {
+			if !m.renamed[key] {
+				event.Properties[key] = toString(value)
+			}
+		})
+	}
+
+	for _, rule := range m.rules {
+		if err := rule.apply(class, payload); err != nil {
+			return nil, fmt.Errorf("mapping %s: %w", m.def.Name, err)
+		}
+	}
+
+	// Class attributes have no ocsf.Event field; carry the mapped ones over
+	classValue := reflect.ValueOf(class).Elem()
+	for name, index := range m.classFields {
+		if field := classValue.Field(index); !field.IsZero() {
+			if event.ClassAttributes == nil {
+				event.ClassAttributes = make(map[string]interface{})
+			}
+			event.ClassAttributes[name] = field.Interface()
+		}
+	}
+
+	// Keep the derived fields consistent with mapped IDs
+	event.TypeUID = ocsf.ComputeTypeUID(event.CategoryUID, event.ClassUID, event.ActivityID)
+	if !m.explicit["activity"] {
+		event.Activity = ocsf.ActivityName(event.ClassUID, event.ActivityID)
+	}
+	if !m.explicit["severity"] {
+		event.Severity = ocsf.SeverityName(event.SeverityID)
+	}
+	if !m.explicit["status"] && event.StatusID != ocsf.StatusUnknown {
+		event.Status = ocsf.StatusName(event.StatusID)
+	}
+	return event, nil
+}
+
+func (r *compiledRule) apply(class interface{}, payload map[string]interface{}) error {
+	switch r.Op {
+	case OpCopy, OpRename:
+		if value, ok := lookupPath(payload, r.From); ok && value != nil {
+			return r.target.set(class, value)
+		}
+	case OpConvert:
+		value, ok := lookupPath(payload, r.From)
+		if !ok || value == nil {
+			return nil
+		}
+		var converted interface{}
+		var err error
+		if r.convert == kindTime {
+			converted, err = parseTime(value, r.Layout)
+		} else {
+			converted, err = coerce(value, r.convert)
+		}
+		if err != nil {
+			return fmt.Errorf("field %s: %w", r.To, err)
+		}
+		return r.target.set(class, converted)
+	case OpLookup:
+		value, ok := lookupPath(payload, r.From)
+		if ok && value != nil {
+			if mapped, found := r.lookup[strings.ToLower(toString(value))]; found {
+				return r.target.set(class, mapped)
+			}
+		}
+		if r.fallback != nil {
+			return r.target.set(class, r.fallback)
+		}
+	case OpDefault:
+		if !r.target.isSet(class) {
+			return r.target.set(class, r.value)
+		}
+	case OpTemplate:
+		var b strings.Builder
+		for _, s := range r.segments {
+			if s.path == "" {
+				b.WriteString(s.text)
+			} else if value, ok := lookupPath(payload, s.path); ok {
+				b.WriteString(toString(value))
+			}
+		}
+		return r.target.set(class, b.String())
+	}
+	return nil
+}
+
+// flatten calls fn for each scalar in value with its dotted path.
+func flatten(value interface{}, prefix string, fn func(string, interface{})) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flatten(child, key, fn)
+		}
+	case nil:
+	default:
+		fn(prefix, v)
+	}
+}
+
+// Set is an ordered list of compiled mappings.
+type Set struct {
+	mappings []*Mapping
+}
+
+// NewSet compiles definitions, rejecting duplicate names. Earlier
+// definitions take precedence when several match.
+func NewSet(defs []Definition) (*Set, error) {
+	set := &Set{}
+	names := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		m, err := Compile(def)
+		if err != nil {
+			return nil, err
+		}
+		if names[def.Name] {
+			return nil, fmt.Errorf("duplicate mapping name %s", def.Name)
+		}
+		names[def.Name] = true
+		set.mappings = append(set.mappings, m)
+	}
+	return set, nil
+}
+
+// Find returns the first mapping that matches, or nil.
+func (s *Set) Find(format, sourceType string) *Mapping {
+	if s == nil {
+		return nil
+	}
+	for _, m := range s.mappings {
+		if m.Matches(format, sourceType) {
+			return m
+		}
+	}
+	return nil
+}
+
+// Get returns the mapping with the given name, or nil.
+func (s *Set) Get(name string) *Mapping {
+	if s == nil {
+		return nil
+	}
+	for _, m := range s.mappings {
+		if m.def.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// Mappings returns the mappings in precedence order.
+func (s *Set) Mappings() []*Mapping {
+	if s == nil {
+		return nil
+	}
+	return s.mappings
+}
+
+// Len returns the number of mappings.
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.mappings)
+}
+
+// Parse decodes the definitions in a YAML stream. Multiple definitions are
+// separated by "---".
+func Parse(data []byte) ([]Definition, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var defs []Definition
+	for {
+		var def Definition
+		err := dec.Decode(&def)
+		if errors.Is(err, io.EOF) {
+			return defs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+}
+
+// Files returns the mapping files in dir (*.yaml and *.yml) in name order.
+func Files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// LoadDir parses and compiles the mapping files in dir.
+func LoadDir(dir string) (*Set, error) {
+	files, err := Files(dir)
+	if err != nil {
+		return nil, err
+	}
+	var defs []Definition
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileDefs, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		defs = append(defs, fileDefs...)
+	}
+	return NewSet(defs)
+}
@@ -0,0 +1,227 @@
+package mapping
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/telhawk-systems/telhawk-stack/common/ocsf"
+)
+
+const oktaMapping = `
+name: okta-auth
+sourcetypes: ["okta:auth", "okta:system*"]
+class_uid: 3002
+activity_id: 1
+keep_unmapped: true
+fields:
+  - {op: rename, from: actor.alternateId, to: actor.user.name}
+  - {op: copy, from: client.ipAddress, to: src_endpoint.ip}
+  - {op: convert, from: client.port, to: src_endpoint.port, type: int}
+  - {op: convert, from: published, to: time, type: timestamp}
+  - {op: lookup, from: outcome.result, to: status_id, values: {SUCCESS: 1, FAILURE: 2}, fallback: 99}
+  - {op: lookup, from: severity, to: severity_id, values: {INFO: 1, WARN: 3, ERROR: 4}}
+  - {op: default, to: device.hostname, value: okta.com}
+  - {op: template, to: metadata.product.name, template: Okta}
+  - {op: template, to: properties.summary, template: "{{eventType}} by {{actor.alternateId}} ({{missing}})"}
+`
+
+func decode(t *testing.T, payload string) map[string]interface{} {
+	t.Helper()
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(payload), &out))
+	return out
+}
+
+func loadOne(t *testing.T, doc string) *Mapping {
+	t.Helper()
+	defs, err := Parse([]byte(doc))
+	require.NoError(t, err)
+	require.Len(t, defs, 1)
+	m, err := Compile(defs[0])
+	require.NoError(t, err)
+	return m
+}
+
+func TestApply(t *testing.T) {
+	m := loadOne(t, oktaMapping)
+	received := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	event, err := m.Apply(decode(t, `{
+		"eventType": "user.session.start",
+		"published": "2026-03-01T10:00:00.250Z",
+		"actor": {"alternateId": "alice@example.com"},
+		"client": {"ipAddress": "203.0.113.9", "port": "44321"},
+		"outcome": {"result": "failure"},
+		"severity": "WARN"
+	}`), Meta{Source: "okta", SourceType: "okta:auth", Format: "json", ReceivedAt: received})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3002, event.ClassUID)
+	assert.Equal(t, 3, event.CategoryUID)
+	assert.Equal(t, "authentication", event.Class)
+	assert.Equal(t, ocsf.ComputeTypeUID(3, 3002, 1), event.TypeUID)
+	assert.Equal(t, "Logon", event.Activity)
+	assert.Equal(t, "alice@example.com", event.Actor.User.Name)
+	assert.Equal(t, "203.0.113.9", event.SrcEndpoint.Ip)
+	assert.Equal(t, 44321, event.SrcEndpoint.Port)
+	assert.Equal(t, time.Date(2026, 3, 1, 10, 0, 0, 250e6, time.UTC), event.Time)
+	assert.Equal(t, received, event.ObservedTime)
+	assert.Equal(t, 2, event.StatusID)
+	assert.Equal(t, "Failure", event.Status)
+	assert.Equal(t, 3, event.SeverityID)
+	assert.Equal(t, "Medium", event.Severity)
+	assert.Equal(t, "Okta", event.Metadata.Product.Name)
+	assert.Equal(t, "okta.com", event.Device.Hostname)
+	assert.Equal(t, "okta", event.Metadata.LogProvider)
+	assert.Equal(t, "user.session.start by alice@example.com ()", event.Properties["summary"])
+
+	// Renamed fields are left out of the unmapped properties
+	assert.Equal(t, "203.0.113.9", event.Properties["client.ipAddress"])
+	assert.NotContains(t, event.Properties, "actor.alternateId")
+}
+
+func TestApply_ClassAttributes(t *testing.T) {
+	m := loadOne(t, `
+name: sso-auth
+sourcetypes: ["sso"]
+class_uid: 3002
+activity_id: 1
+fields:
+  - {op: copy, from: user, to: user.name}
+  - {op: copy, from: protocol, to: auth_protocol}
+  - {op: convert, from: mfa, to: is_mfa, type: bool}
+  - {op: copy, from: ip, to: src_endpoint.ip}
+  - {op: default, to: logon_type_id, value: 3}
+`)
+
+	event, err := m.Apply(decode(t, `{"user": "alice", "protocol": "SAML", "mfa": "true", "ip": "203.0.113.9"}`), Meta{Format: "json"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "203.0.113.9", event.SrcEndpoint.Ip)
+	assert.Equal(t, "SAML", event.ClassAttributes["auth_protocol"])
+	assert.Equal(t, true, event.ClassAttributes["is_mfa"])
+	assert.EqualValues(t, 3, event.ClassAttributes["logon_type_id"])
+	assert.NotContains(t, event.ClassAttributes, "is_remote", "unmapped class attributes stay unset")
+
+	doc, err := event.Document()
+	require.NoError(t, err)
+	assert.Equal(t, "SAML", doc["auth_protocol"])
+	assert.Equal(t, "alice", doc["user"].(map[string]interface{})["name"])
+}
+
+func TestApply_LookupFallbackAndMissingFields(t *testing.T) {
+	m := loadOne(t, oktaMapping)
+
+	event, err := m.Apply(decode(t, `{"outcome": {"result": "ALLOW"}}`), Meta{Format: "json"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 99, event.StatusID)
+	assert.Nil(t, event.Actor)
+	assert.Nil(t, event.SrcEndpoint)
+	assert.Equal(t, "okta.com", event.Device.Hostname)
+}
+
+func TestApply_ConversionError(t *testing.T) {
+	m := loadOne(t, oktaMapping)
+
+	_, err := m.Apply(decode(t, `{"client": {"port": "https"}}`), Meta{Format: "json"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "src_endpoint.port")
+}
+
+func TestMatches(t *testing.T) {
+	m := loadOne(t, oktaMapping)
+
+	assert.True(t, m.Matches("json", "okta:auth"))
+	assert.True(t, m.Matches("json", "okta:system_log"))
+	assert.False(t, m.Matches("json", "okta"))
+	assert.False(t, m.Matches("raw", "okta:auth"))
+}
+
+func TestCompile_Errors(t *testing.T) {
+	base := func(rules ...Rule) Definition {
+		return Definition{Name: "m", SourceTypes: []string{"x"}, ClassUID: 3002, Fields: rules}
+	}
+	tests := map[string]Definition{
+		"missing name":         {SourceTypes: []string{"x"}, ClassUID: 3002},
+		"missing sourcetypes":  {Name: "m", ClassUID: 3002},
+		"bad pattern":          {Name: "m", SourceTypes: []string{"["}, ClassUID: 3002},
+		"unknown class":        {Name: "m", SourceTypes: []string{"x"}, ClassUID: 9999},
+		"unknown activity":     {Name: "m", SourceTypes: []string{"x"}, ClassUID: 3002, ActivityID: 42},
+		"unknown field":        base(Rule{Op: OpCopy, From: "a", To: "actor.user.nickname"}),
+		"reserved field":       base(Rule{Op: OpCopy, From: "a", To: "class_uid"}),
+		"object target":        base(Rule{Op: OpCopy, From: "a", To: "actor.user"}),
+		"map without key":      base(Rule{Op: OpCopy, From: "a", To: "properties"}),
+		"missing from":         base(Rule{Op: OpCopy, To: "actor.user.name"}),
+		"unknown op":           base(Rule{Op: "move", From: "a", To: "actor.user.name"}),
+		"bad convert type":     base(Rule{Op: OpConvert, From: "a", To: "src_endpoint.port", Type: "ip"}),
+		"incompatible convert": base(Rule{Op: OpConvert, From: "a", To: "src_endpoint.port", Type: "timestamp"}),
+		"bad lookup value":     base(Rule{Op: OpLookup, From: "a", To: "status_id", Values: map[string]interface{}{"ok": "yes"}}),
+		"bad default":          base(Rule{Op: OpDefault, To: "severity_id", Value: "high"}),
+		"bad template":         base(Rule{Op: OpTemplate, To: "message", Template: "{{a"}),
+		"other class field":    base(Rule{Op: OpCopy, From: "a", To: "query.hostname"}),
+	}
+	for name, def := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Compile(def)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParse_RejectsUnknownKeys(t *testing.T) {
+	_, err := Parse([]byte("name: m\nsourcetype: x\n"))
+	assert.Error(t, err)
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "10-okta.yaml"), []byte(oktaMapping), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "20-catchall.yml"), []byte(`
+name: okta-any
+sourcetypes: ["okta:*"]
+class_uid: 6003
+---
+name: app
+sourcetypes: [app]
+class_uid: 1007
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o644))
+
+	set, err := LoadDir(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, set.Len())
+	assert.Equal(t, "okta-auth", set.Find("json", "okta:auth").Name())
+	assert.Equal(t, "okta-any", set.Find("json", "okta:user").Name())
+	assert.NotNil(t, set.Get("app"))
+	assert.Nil(t, set.Find("json", "zeek"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "30-dup.yaml"), []byte("name: app\nsourcetypes: [b]\nclass_uid: 1007\n"), 0o644))
+	_, err = LoadDir(dir)
+	assert.Error(t, err)
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		value  interface{}
+		layout string
+		want   time.Time
+	}{
+		{1772000000.5, "", time.Unix(1772000000, 500e6).UTC()},
+		{1772000000123.0, "", time.Unix(1772000000, 123e6).UTC()},
+		{"1772000000", "epoch", time.Unix(1772000000, 0).UTC()},
+		{"2026-03-01 10:00:00", "", time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{"01/Mar/2026:10:00:00 +0000", "02/Jan/2006:15:04:05 -0700", time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseTime(tt.value, tt.layout)
+		require.NoError(t, err, tt.value)
+		assert.True(t, tt.want.Equal(got), "%v: got %v", tt.value, got)
+	}
+}
@@ -0,0 +1,338 @@
+// Package parsing splits raw payloads into events and extracts their
+// timestamps and fields, per sourcetype, in the spirit of Splunk's
+// props.conf and transforms.conf.
+package parsing
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLineBreaker breaks events on runs of newlines (Splunk's
+	// LINE_BREAKER default).
+	DefaultLineBreaker = `([\r\n]+)`
+
+	// DefaultMaxTimestampLookahead is how many characters after the time
+	// prefix are searched for a timestamp.
+	DefaultMaxTimestampLookahead = 128
+
+	// DefaultMaxEventLines caps how many lines are merged into one event.
+	DefaultMaxEventLines = 256
+)
+
+// keyValuePattern matches key=value and key="quoted value" pairs.
+var keyValuePattern = regexp.MustCompile(`([A-Za-z_][\w.\-]*)=("(?:[^"\\]|\\.)*"|[^\s,;"]*)`)
+
+// Profile describes how raw payloads of one sourcetype become events.
+type Profile struct {
+	SourceType string `mapstructure:"sourcetype" yaml:"sourcetype"`
+
+	// LineBreaker splits the payload. Text matched by its first capture group
+	// is discarded; text before it ends one event and text after it starts
+	// the next.
+	LineBreaker string `mapstructure:"line_breaker" yaml:"line_breaker"`
+
+	// ShouldLineMerge merges lines back into multi-line events, starting a
+	// new event only at lines matching BreakOnlyBefore (e.g. stack traces).
+	ShouldLineMerge bool   `mapstructure:"should_linemerge" yaml:"should_linemerge"`
+	BreakOnlyBefore string `mapstructure:"break_only_before" yaml:"break_only_before"`
+	MaxEventLines   int    `mapstructure:"max_event_lines" yaml:"max_event_lines"`
+
+	// MergeKey merges consecutive lines whose first capture group matches
+	// the same value, such as the serial auditd writes on every record of an
+	// event. Lines it does not match join the current event.
+	MergeKey string `mapstructure:"merge_key" yaml:"merge_key"`
+
+	// TimePrefix is a regexp that precedes the timestamp; the timestamp is
+	// searched for in the MaxTimestampLookahead characters after it.
+	// TimeFormat is strptime-style (%Y-%m-%d %H:%M:%S.%3N, or %s for epoch
+	// seconds). Timezone applies to timestamps without an offset.
+	TimePrefix            string `mapstructure:"time_prefix" yaml:"time_prefix"`
+	TimeFormat            string `mapstructure:"time_format" yaml:"time_format"`
+	Timezone              string `mapstructure:"timezone" yaml:"timezone"`
+	MaxTimestampLookahead int    `mapstructure:"max_timestamp_lookahead" yaml:"max_timestamp_lookahead"`
+
+	// Extractions are regexps whose named capture groups become fields.
+	// KeyValue also extracts key=value pairs; named captures win.
+	Extractions []string `mapstructure:"extractions" yaml:"extractions"`
+	KeyValue    bool     `mapstructure:"key_value" yaml:"key_value"`
+}
+
+// Event is one event parsed from a raw payload.
+type Event struct {
+	Text   string
+	Time   time.Time // Zero when no timestamp was found
+	Fields map[string]string
+}
+
+// Payload returns the event as a flat JSON object for the normalizers:
+// extracted fields, the event text as "message" and, when found, the
+// timestamp as "time" in epoch seconds.
+func (e Event) Payload() map[string]interface{} {
+	payload := make(map[string]interface{}, len(e.Fields)+2)
+	for k, v := range e.Fields {
+		payload[k] = v
+	}
+	payload["message"] = e.Text
+	if !e.Time.IsZero() {
+		payload["time"] = float64(e.Time.UnixNano()) / 1e9
+	}
+	return payload
+}
+
+// Parser applies a compiled Profile.
+type Parser struct {
+	profile         Profile
+	lineBreaker     *regexp.Regexp
+	breakOnlyBefore *regexp.Regexp
+	mergeKey        *regexp.Regexp
+	timePrefix      *regexp.Regexp
+	timeFormat      *timeFormat
+	location        *time.Location
+	extractions     []*regexp.Regexp
+	now             func() time.Time
+}
+
+// Compile validates a profile and compiles its expressions.
+func Compile(p Profile) (*Parser, error) {
+	if p.SourceType == "" {
+		return nil, fmt.Errorf("parsing profile is missing sourcetype")
+	}
+	if p.LineBreaker == "" {
+		p.LineBreaker = DefaultLineBreaker
+	}
+	if p.MaxEventLines <= 0 {
+		p.MaxEventLines = DefaultMaxEventLines
+	}
+	if p.MaxTimestampLookahead <= 0 {
+		p.MaxTimestampLookahead = DefaultMaxTimestampLookahead
+	}
+
+	parser := &Parser{profile: p, location: time.UTC, now: time.Now}
+	errorf := func(format string, args ...interface{}) error {
+		return fmt.Errorf("sourcetype %s: %s", p.SourceType, fmt.Sprintf(format, args...))
+	}
+
+	var err error
+	if parser.lineBreaker, err = regexp.Compile(p.LineBreaker); err != nil {
+		return nil, errorf("line_breaker: %v", err)
+	}
+	if p.ShouldLineMerge {
+		if p.BreakOnlyBefore == "" {
+			return nil, errorf("should_linemerge requires break_only_before")
+		}
+		if parser.breakOnlyBefore, err = regexp.Compile(p.BreakOnlyBefore); err != nil {
+			return nil, errorf("break_only_before: %v", err)
+		}
+	}
+	if p.MergeKey != "" {
+		if p.ShouldLineMerge {
+			return nil, errorf("merge_key and should_linemerge are mutually exclusive")
+		}
+		if parser.mergeKey, err = regexp.Compile(p.MergeKey); err != nil {
+			return nil, errorf("merge_key: %v", err)
+		}
+		if parser.mergeKey.NumSubexp() == 0 {
+			return nil, errorf("merge_key %q has no capture group", p.MergeKey)
+		}
+	}
+	if p.TimePrefix != "" {
+		if parser.timePrefix, err = regexp.Compile(p.TimePrefix); err != nil {
+			return nil, errorf("time_prefix: %v", err)
+		}
+	}
+	if p.TimeFormat != "" {
+		if parser.timeFormat, err = compileTimeFormat(p.TimeFormat); err != nil {
+			return nil, errorf("%v", err)
+		}
+	}
+	if p.Timezone != "" {
+		if parser.location, err = time.LoadLocation(p.Timezone); err != nil {
+			return nil, errorf("timezone: %v", err)
+		}
+	}
+	for _, expr := range p.Extractions {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errorf("extraction %q: %v", expr, err)
+		}
+		named := false
+		for _, name := range re.SubexpNames() {
+			named = named || name != ""
+		}
+		if !named {
+			return nil, errorf("extraction %q has no named capture groups", expr)
+		}
+		parser.extractions = append(parser.extractions, re)
+	}
+	return parser, nil
+}
+
+// Profile returns the profile with defaults applied.
+func (p *Parser) Profile() Profile {
+	return p.profile
+}
+
+// Parse splits data into events and extracts each event's timestamp and
+// fields. Blank events are dropped.
+func (p *Parser) Parse(data []byte) []Event {
+	now := p.now()
+	var events []Event
+	for _, text := range p.merge(p.split(string(data))) {
+		event := Event{Text: text, Fields: p.extract(text)}
+		if t, ok := p.timestamp(text, now); ok {
+			event.Time = t
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// split breaks text at each LineBreaker match.
+func (p *Parser) split(text string) []string {
+	var chunks []string
+	start := 0
+	for _, m := range p.lineBreaker.FindAllStringSubmatchIndex(text, -1) {
+		breakStart, breakEnd := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			breakStart, breakEnd = m[2], m[3]
+		}
+		if breakEnd == start && breakStart == start {
+			continue // Empty match
+		}
+		chunks = append(chunks, text[start:breakStart])
+		start = breakEnd
+	}
+	return append(chunks, text[start:])
+}
+
+// merge joins chunks into multi-line events when line merging or a merge key
+// is configured and drops blank events.
+func (p *Parser) merge(chunks []string) []string {
+	var events []string
+	if p.breakOnlyBefore == nil && p.mergeKey == nil {
+		for _, chunk := range chunks {
+			if text := strings.TrimSpace(chunk); text != "" {
+				events = append(events, text)
+			}
+		}
+		return events
+	}
+
+	var current []string
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(current, "\n")); text != "" {
+			events = append(events, text)
+		}
+		current = current[:0]
+	}
+	var currentKey string
+	for _, chunk := range chunks {
+		if strings.TrimSpace(chunk) == "" {
+			continue
+		}
+		var newEvent bool
+		if p.mergeKey != nil {
+			if m := p.mergeKey.FindStringSubmatch(chunk); m != nil {
+				newEvent = m[1] != currentKey
+				currentKey = m[1]
+			}
+		} else {
+			newEvent = p.breakOnlyBefore.MatchString(chunk)
+		}
+		if len(current) > 0 && (newEvent || len(current) >= p.profile.MaxEventLines) {
+			flush()
+		}
+		current = append(current, chunk)
+	}
+	flush()
+	return events
+}
+
+// timestamp finds the event time after the time prefix, if configured.
+func (p *Parser) timestamp(text string, now time.Time) (time.Time, bool) {
+	if p.timeFormat == nil {
+		return time.Time{}, false
+	}
+	start := 0
+	if p.timePrefix != nil {
+		loc := p.timePrefix.FindStringIndex(text)
+		if loc == nil {
+			return time.Time{}, false
+		}
+		start = loc[1]
+	}
+	end := start + p.profile.MaxTimestampLookahead
+	if end > len(text) {
+		end = len(text)
+	}
+	return p.timeFormat.find(text[start:end], p.location, now)
+}
+
+// extract returns key=value pairs (if enabled) overlaid with the first match
+// of each extraction's named groups.
+func (p *Parser) extract(text string) map[string]string {
+	fields := make(map[string]string)
+	if p.profile.KeyValue {
+		for _, m := range keyValuePattern.FindAllStringSubmatch(text, -1) {
+			value := m[2]
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			fields[m[1]] = value
+		}
+	}
+	for _, re := range p.extractions {
+		m := re.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		for i, name := range re.SubexpNames() {
+			if name != "" && m[i] != "" {
+				fields[name] = m[i]
+			}
+		}
+	}
+	return fields
+}
+
+// Set holds the parsers for each configured sourcetype.
+type Set struct {
+	parsers map[string]*Parser
+}
+
+// NewSet compiles profiles, rejecting duplicate sourcetypes.
+func NewSet(profiles []Profile) (*Set, error) {
+	set := &Set{parsers: make(map[string]*Parser, len(profiles))}
+	for _, profile := range profiles {
+		parser, err := Compile(profile)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := set.parsers[profile.SourceType]; dup {
+			return nil, fmt.Errorf("duplicate parsing profile for sourcetype %s", profile.SourceType)
+		}
+		set.parsers[profile.SourceType] = parser
+	}
+	return set, nil
+}
+
+// Get returns the parser for sourceType, or nil if it has no profile.
+func (s *Set) Get(sourceType string) *Parser {
+	if s == nil {
+		return nil
+	}
+	return s.parsers[sourceType]
+}
+
+// Len returns the number of profiles.
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.parsers)
+}
@@ -0,0 +1,214 @@
+package parsing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustCompile(t *testing.T, p Profile) *Parser {
+	t.Helper()
+	parser, err := Compile(p)
+	require.NoError(t, err)
+	parser.now = func() time.Time { return time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC) }
+	return parser
+}
+
+func TestParse_DefaultLineBreaker(t *testing.T) {
+	parser := mustCompile(t, Profile{SourceType: "app"})
+
+	events := parser.Parse([]byte("first line\r\nsecond line\n\n\nthird line\n"))
+
+	require.Len(t, events, 3)
+	assert.Equal(t, "first line", events[0].Text)
+	assert.Equal(t, "third line", events[2].Text)
+	assert.True(t, events[0].Time.IsZero())
+}
+
+func TestParse_LineBreakerCaptureGroup(t *testing.T) {
+	// Only newlines followed by a date end an event; the date stays with
+	// the next event
+	parser := mustCompile(t, Profile{
+		SourceType:  "app",
+		LineBreaker: `([\r\n]+)\d{4}-\d{2}-\d{2}`,
+	})
+
+	events := parser.Parse([]byte("2026-03-01 one\n  continued\n2026-03-02 two"))
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "2026-03-01 one\n  continued", events[0].Text)
+	assert.Equal(t, "2026-03-02 two", events[1].Text)
+}
+
+func TestParse_LineMergeStackTrace(t *testing.T) {
+	parser := mustCompile(t, Profile{
+		SourceType:      "java",
+		ShouldLineMerge: true,
+		BreakOnlyBefore: `^\d{4}-\d{2}-\d{2}`,
+		TimeFormat:      "%Y-%m-%d %H:%M:%S,%3N",
+		Timezone:        "America/New_York",
+		Extractions:     []string{`^\S+ \S+ (?P<level>[A-Z]+) \[(?P<thread>[^\]]+)\]`},
+	})
+
+	input := `2026-03-01 09:15:30,123 ERROR [main] Request failed
+java.lang.IllegalStateException: boom
+	at com.example.Handler.handle(Handler.java:42)
+	at com.example.Server.run(Server.java:7)
+2026-03-01 09:15:31,004 INFO [worker-1] Recovered`
+
+	events := parser.Parse([]byte(input))
+
+	require.Len(t, events, 2)
+	assert.Contains(t, events[0].Text, "at com.example.Server.run")
+	assert.Equal(t, map[string]string{"level": "ERROR", "thread": "main"}, events[0].Fields)
+	assert.Equal(t, "worker-1", events[1].Fields["thread"])
+
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2026, 3, 1, 9, 15, 30, 123e6, ny).Equal(events[0].Time), events[0].Time)
+}
+
+func TestParse_MaxEventLines(t *testing.T) {
+	parser := mustCompile(t, Profile{
+		SourceType:      "app",
+		ShouldLineMerge: true,
+		BreakOnlyBefore: `^START`,
+		MaxEventLines:   2,
+	})
+
+	events := parser.Parse([]byte("START\na\nb\nc"))
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "START\na", events[0].Text)
+	assert.Equal(t, "b\nc", events[1].Text)
+}
+
+func TestParse_MergeKey(t *testing.T) {
+	// auditd writes every record of an event with the same serial
+	parser := mustCompile(t, Profile{
+		SourceType: "linux:audit",
+		MergeKey:   `msg=audit\(\d+\.\d+:(\d+)\)`,
+	})
+
+	input := `type=SYSCALL msg=audit(1772359200.123:100): syscall=59 exe="/usr/bin/id"
+type=EXECVE msg=audit(1772359200.123:100): argc=1 a0="id"
+type=EOE msg=audit(1772359200.123:100):
+type=USER_LOGIN msg=audit(1772359201.000:101): pid=1 res=success
+type=SYSCALL msg=audit(1772359202.000:102): syscall=2`
+
+	events := parser.Parse([]byte(input))
+
+	require.Len(t, events, 3)
+	assert.Equal(t, 3, len(strings.Split(events[0].Text, "\n")))
+	assert.True(t, strings.HasPrefix(events[1].Text, "type=USER_LOGIN"))
+	assert.True(t, strings.HasPrefix(events[2].Text, "type=SYSCALL"))
+}
+
+func TestParse_TimePrefixAndLookahead(t *testing.T) {
+	parser := mustCompile(t, Profile{
+		SourceType:            "app",
+		TimePrefix:            `ts=`,
+		TimeFormat:            "%Y-%m-%dT%H:%M:%S%:z",
+		MaxTimestampLookahead: 25,
+	})
+
+	events := parser.Parse([]byte(
+		"created=2020-01-01T00:00:00Z ts=2026-03-01T10:00:00+02:00 msg=ok\n" +
+			"ts=          2026-03-01T10:00:00+02:00\n"))
+
+	require.Len(t, events, 2)
+	assert.True(t, time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC).Equal(events[0].Time), events[0].Time)
+	// The timestamp starts beyond the lookahead window
+	assert.True(t, events[1].Time.IsZero())
+}
+
+func TestParse_SyslogTimestampWithoutYear(t *testing.T) {
+	parser := mustCompile(t, Profile{SourceType: "syslog", TimeFormat: "%b %e %H:%M:%S"})
+
+	events := parser.Parse([]byte("Mar  9 23:59:01 host sshd[1]: ok\nDec 31 23:00:00 host sshd[1]: old"))
+
+	require.Len(t, events, 2)
+	assert.Equal(t, time.Date(2026, 3, 9, 23, 59, 1, 0, time.UTC), events[0].Time)
+	// December would be in the future, so it belongs to last year
+	assert.Equal(t, time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC), events[1].Time)
+}
+
+func TestParse_EpochTimestamp(t *testing.T) {
+	parser := mustCompile(t, Profile{SourceType: "app", TimePrefix: `^`, TimeFormat: "%s"})
+
+	events := parser.Parse([]byte("1772000000.25 something happened"))
+
+	require.Len(t, events, 1)
+	assert.Equal(t, int64(1772000000), events[0].Time.Unix())
+	assert.Equal(t, 250*time.Millisecond, time.Duration(events[0].Time.Nanosecond()))
+}
+
+func TestParse_KeyValue(t *testing.T) {
+	parser := mustCompile(t, Profile{
+		SourceType:  "fw",
+		KeyValue:    true,
+		Extractions: []string{`action=(?P<action>\w+)`},
+	})
+
+	events := parser.Parse([]byte(`src=10.0.0.1 dst=10.0.0.2, user="alice smith" action=ALLOW`))
+
+	require.Len(t, events, 1)
+	assert.Equal(t, map[string]string{
+		"src":    "10.0.0.1",
+		"dst":    "10.0.0.2",
+		"user":   "alice smith",
+		"action": "ALLOW",
+	}, events[0].Fields)
+}
+
+func TestEvent_Payload(t *testing.T) {
+	event := Event{
+		Text:   "login ok",
+		Time:   time.Unix(1772000000, 500e6),
+		Fields: map[string]string{"user": "alice"},
+	}
+
+	payload := event.Payload()
+
+	assert.Equal(t, "login ok", payload["message"])
+	assert.Equal(t, "alice", payload["user"])
+	assert.InDelta(t, 1772000000.5, payload["time"], 1e-6)
+	assert.NotContains(t, Event{Text: "x"}.Payload(), "time")
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := map[string]Profile{
+		"missing sourcetype":       {},
+		"bad line breaker":         {SourceType: "a", LineBreaker: "("},
+		"merge without pattern":    {SourceType: "a", ShouldLineMerge: true},
+		"unknown directive":        {SourceType: "a", TimeFormat: "%Q"},
+		"epoch in format":          {SourceType: "a", TimeFormat: "%s.%3N"},
+		"bad timezone":             {SourceType: "a", Timezone: "Mars/Olympus"},
+		"unnamed extraction":       {SourceType: "a", Extractions: []string{`(\d+)`}},
+		"merge key without group":  {SourceType: "a", MergeKey: `\d+`},
+		"merge key with linemerge": {SourceType: "a", MergeKey: `(\d+)`, ShouldLineMerge: true, BreakOnlyBefore: "^x"},
+	}
+	for name, profile := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Compile(profile)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewSet(t *testing.T) {
+	set, err := NewSet([]Profile{{SourceType: "a"}, {SourceType: "b"}})
+	require.NoError(t, err)
+	assert.NotNil(t, set.Get("a"))
+	assert.Nil(t, set.Get("c"))
+	assert.Equal(t, 2, set.Len())
+
+	var empty *Set
+	assert.Nil(t, empty.Get("a"))
+
+	_, err = NewSet([]Profile{{SourceType: "a"}, {SourceType: "a"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package parsing
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeFormat is a strptime-style TIME_FORMAT compiled to a Go layout and a
+// regexp that finds candidate timestamps in event text.
+type timeFormat struct {
+	layout  string
+	re      *regexp.Regexp
+	epoch   bool // %s: seconds since the epoch, optionally fractional
+	hasYear bool
+}
+
+// strptimeDirectives maps each supported directive to its Go layout element
+// and the text it matches.
+var strptimeDirectives = map[string]struct{ layout, pattern string }{
+	"Y":  {"2006", `\d{4}`},
+	"y":  {"06", `\d{2}`},
+	"m":  {"1", `\d{1,2}`},
+	"d":  {"2", `\d{1,2}`},
+	"e":  {"_2", ` ?\d{1,2}`},
+	"j":  {"002", `\d{3}`},
+	"H":  {"15", `\d{1,2}`},
+	"I":  {"3", `\d{1,2}`},
+	"M":  {"04", `\d{2}`},
+	"S":  {"05", `\d{2}`},
+	"p":  {"PM", `[AaPp][Mm]`},
+	"b":  {"Jan", `[A-Za-z]{3}`},
+	"h":  {"Jan", `[A-Za-z]{3}`},
+	"B":  {"January", `[A-Za-z]+`},
+	"a":  {"Mon", `[A-Za-z]{3}`},
+	"A":  {"Monday", `[A-Za-z]+`},
+	"z":  {"Z0700", `(?:Z|[+-]\d{4})`},
+	":z": {"Z07:00", `(?:Z|[+-]\d{2}:\d{2})`},
+	"Z":  {"MST", `[A-Z]{2,5}`},
+	"3N": {"000", `\d{3}`},
+	"6N": {"000000", `\d{6}`},
+	"9N": {"000000000", `\d{9}`},
+	"N":  {"999999999", `\d+`},
+	"f":  {"999999999", `\d+`},
+	"T":  {"15:04:05", `\d{1,2}:\d{2}:\d{2}`},
+	"F":  {"2006-01-02", `\d{4}-\d{2}-\d{2}`},
+	"%":  {"%", `%`},
+}
+
+// compileTimeFormat converts a strptime format (e.g. "%Y-%m-%d %H:%M:%S.%3N")
+// to a Go layout. %s alone parses epoch seconds.
+func compileTimeFormat(format string) (*timeFormat, error) {
+	if format == "%s" {
+		return &timeFormat{re: regexp.MustCompile(`\d{9,10}(?:\.\d+)?`), epoch: true, hasYear: true}, nil
+	}
+
+	var layout, pattern strings.Builder
+	tf := &timeFormat{}
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			layout.WriteByte(c)
+			pattern.WriteString(regexp.QuoteMeta(string(c)))
+			continue
+		}
+		if i+1 >= len(format) {
+			return nil, fmt.Errorf("time format %q ends with %%", format)
+		}
+
+		// Directives are one character, or two for %:z and %3N/%6N/%9N
+		name := format[i+1 : i+2]
+		if i+2 < len(format) && (name == ":" || strings.ContainsRune("369", rune(name[0]))) {
+			name = format[i+1 : i+3]
+		}
+		if name == "s" {
+			return nil, fmt.Errorf("time format %q: %%s must be the whole format", format)
+		}
+		d, ok := strptimeDirectives[name]
+		if !ok {
+			return nil, fmt.Errorf("time format %q: unsupported directive %%%s", format, name)
+		}
+		i += len(name)
+
+		if name == "Y" || name == "y" || name == "F" {
+			tf.hasYear = true
+		}
+		layout.WriteString(d.layout)
+		pattern.WriteString(d.pattern)
+	}
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("time format %q: %w", format, err)
+	}
+	tf.layout = layout.String()
+	tf.re = re
+	return tf, nil
+}
+
+// find returns the first timestamp in text. Formats without a year get the
+// year that puts the timestamp closest to, and not after, now.
+func (tf *timeFormat) find(text string, loc *time.Location, now time.Time) (time.Time, bool) {
+	match := tf.re.FindString(text)
+	if match == "" {
+		return time.Time{}, false
+	}
+
+	if tf.epoch {
+		secs, err := strconv.ParseFloat(match, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		whole, frac := math.Modf(secs)
+		return time.Unix(int64(whole), int64(frac*1e9)).In(loc), true
+	}
+
+	t, err := time.ParseInLocation(tf.layout, match, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if !tf.hasYear {
+		now = now.In(loc)
+		t = t.AddDate(now.Year()-t.Year(), 0, 0)
+		if t.After(now.Add(24 * time.Hour)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+	}
+	return t, true
+}
@@ -16,6 +16,7 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/telhawk-systems/telhawk-stack/common/config"
+	"github.com/telhawk-systems/telhawk-stack/common/instrumentation"
 	natsclient "github.com/telhawk-systems/telhawk-stack/common/messaging/nats"
 	"github.com/telhawk-systems/telhawk-stack/respond/internal/auth"
 	"github.com/telhawk-systems/telhawk-stack/respond/internal/handlers"
@@ -36,6 +37,11 @@ func main() {
 	config.MustLoad("respond")
 	cfg := config.GetConfig()
 
+	shutdownTracing, err := instrumentation.Setup(context.Background(), "respond", cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+
 	// Build PostgreSQL connection string
 	connString := fmt.Sprintf(
 		"postgres://%s:%s@%s:%d/%s?sslmode=%s",
@@ -185,6 +191,9 @@ func main() {
 		repo.Close()                                     //nolint:errcheck // closing on fatal
 		log.Fatalf("Server forced to shutdown: %v", err) //nolint:gocritic // repo.Close() called explicitly above
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("Warning: Error flushing traces: %v", err)
+	}
 	cancel()
 	repo.Close()
 
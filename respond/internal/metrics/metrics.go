@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Alert metrics
+	AlertsCreatedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telhawk_respond_alerts_created_total",
+			Help: "Total number of alerts created from correlation results",
+		},
+		[]string{"severity"},
+	)
+
+	// Correlation scheduler metrics
+	SchedulerLag = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "telhawk_respond_scheduler_lag_seconds",
+			Help:    "Delay between a scheduled correlation run and the start of its execution",
+			Buckets: []float64{.001, .01, .1, .5, 1, 5, 15, 30, 60, 300},
+		},
+	)
+
+	CorrelationJobsRequestedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telhawk_respond_correlation_jobs_requested_total",
+			Help: "Total number of correlation jobs requested from the search service",
+		},
+		[]string{"status"}, // ok, error
+	)
+)
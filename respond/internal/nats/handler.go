@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/telhawk-systems/telhawk-stack/common/messaging"
 	natsclient "github.com/telhawk-systems/telhawk-stack/common/messaging/nats"
+	"github.com/telhawk-systems/telhawk-stack/respond/internal/metrics"
 	"github.com/telhawk-systems/telhawk-stack/respond/internal/repository"
 )
 
@@ -120,6 +121,7 @@ func (h *Handler) handleCorrelationResult(ctx context.Context, msg *messaging.Me
 			log.Printf("Failed to publish alert created event: %v", err)
 			// Continue processing other matches
 		} else {
+			metrics.AlertsCreatedTotal.WithLabelValues(severity).Inc()
 			log.Printf("Published alert %s for schema %s (matched %d events)",
 				alertID.String(), result.SchemaID, match.EventCount)
 		}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/telhawk-systems/telhawk-stack/respond/internal/metrics"
 	"github.com/telhawk-systems/telhawk-stack/respond/internal/models"
 	respondnats "github.com/telhawk-systems/telhawk-stack/respond/internal/nats"
 	"github.com/telhawk-systems/telhawk-stack/respond/internal/repository"
@@ -50,7 +51,8 @@ func (s *Scheduler) Start(ctx context.Context) {
 
 	for {
 		select {
-		case <-ticker.C:
+		case tick := <-ticker.C:
+			metrics.SchedulerLag.Observe(time.Since(tick).Seconds())
 			s.runCorrelations(ctx)
 		case <-s.stop:
 			log.Println("Correlation scheduler stopped")
@@ -145,7 +147,12 @@ func (s *Scheduler) runSchemaCorrelation(ctx context.Context, schema *models.Det
 		Threshold:      threshold,
 	}
 
-	return s.publisher.RequestCorrelation(ctx, req)
+	if err := s.publisher.RequestCorrelation(ctx, req); err != nil {
+		metrics.CorrelationJobsRequestedTotal.WithLabelValues("error").Inc()
+		return err
+	}
+	metrics.CorrelationJobsRequestedTotal.WithLabelValues("ok").Inc()
+	return nil
 }
 
 // getStringFromMap safely extracts a string value from a map.
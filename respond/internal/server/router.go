@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/telhawk-systems/telhawk-stack/common/instrumentation"
 	"github.com/telhawk-systems/telhawk-stack/common/middleware"
 	"github.com/telhawk-systems/telhawk-stack/respond/internal/handlers"
 )
@@ -41,7 +42,10 @@ func NewRouterWithConfig(cfg RouterConfig) http.Handler {
 	mux.HandleFunc("/api/v1/alerts", cfg.AlertsHandler.AlertsRoute)
 	mux.HandleFunc("/api/v1/alerts/", cfg.AlertsHandler.AlertsRoute)
 
-	return middleware.RequestID(mux)
+	// Prometheus metrics
+	mux.Handle("GET /metrics", instrumentation.MetricsHandler())
+
+	return middleware.RequestID(instrumentation.Middleware("respond", mux))
 }
 
 // schemaRouteHandler routes /schemas/{id}/* requests to appropriate handlers
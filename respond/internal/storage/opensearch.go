@@ -20,7 +20,10 @@ import (
 // OpenSearchStorage provides access to alerts stored in OpenSearch.
 type OpenSearchStorage struct {
 	client *opensearch.Client
-	index  string // Base index pattern (e.g., "telhawk-alerts")
+	// Base index pattern (e.g., "telhawk-alerts-*"). Alerts are not routed
+	// per client, so every caller reads the shared series and queries filter
+	// on client_id.
+	index string
 }
 
 // NewOpenSearchStorage creates a new OpenSearch storage client.
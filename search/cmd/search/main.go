@@ -17,6 +17,7 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/telhawk-systems/telhawk-stack/common/config"
+	"github.com/telhawk-systems/telhawk-stack/common/instrumentation"
 	"github.com/telhawk-systems/telhawk-stack/common/logging"
 	natsclient "github.com/telhawk-systems/telhawk-stack/common/messaging/nats"
 	sauth "github.com/telhawk-systems/telhawk-stack/search/internal/auth"
@@ -47,6 +48,11 @@ func main() {
 	).With(logging.Service("search"))
 	logging.SetDefault(logger)
 
+	shutdownTracing, err := instrumentation.Setup(context.Background(), "search", cfg.Tracing)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %v", err)
+	}
+
 	slog.Info("Starting Search service",
 		slog.Int("port", cfg.Search.Server.Port),
 		slog.String("log_level", cfg.Logging.Level),
@@ -91,7 +97,12 @@ func main() {
 	}
 	authClient := sauth.NewClient(cfg.Search.AuthURL)
 
-	svc := service.NewSearchService("0.1.0", osClient).WithDependencies(repo, authClient)
+	if cfg.Search.Pagination.CursorSecret == "" {
+		slog.Warn("No search.pagination.cursor_secret set; query cursors only work on this replica until it restarts")
+	}
+	svc := service.NewSearchService("0.1.0", osClient).
+		WithDependencies(repo, authClient).
+		WithPagination(cfg.Search.Pagination.CursorSecret, cfg.Search.Pagination.KeepAlive)
 	h := handlers.New(svc)
 
 	var alertScheduler *scheduler.Scheduler
@@ -193,6 +204,9 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Printf("graceful shutdown failed: %v", err)
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("trace export shutdown failed: %v", err)
+	}
 }
 
 func buildNotificationChannel(cfg *config.Config) notification.Channel {
@@ -7,21 +7,24 @@ import (
 
 	"github.com/opensearch-project/opensearch-go/v2"
 	"github.com/telhawk-systems/telhawk-stack/common/config"
+	"github.com/telhawk-systems/telhawk-stack/common/indices"
+	"github.com/telhawk-systems/telhawk-stack/common/instrumentation"
 )
 
 type OpenSearchClient struct {
-	client *opensearch.Client
-	index  string
+	client      *opensearch.Client
+	index       string
+	routingMode string
 }
 
 func NewOpenSearchClient() (*OpenSearchClient, error) {
 	cfg := config.GetConfig()
 	httpClient := &http.Client{
-		Transport: &http.Transport{
+		Transport: instrumentation.Transport(&http.Transport{
 			TLSClientConfig: &tls.Config{
 				InsecureSkipVerify: cfg.OpenSearch.Insecure,
 			},
-		},
+		}),
 	}
 
 	osCfg := opensearch.Config{
@@ -47,11 +50,17 @@ func NewOpenSearchClient() (*OpenSearchClient, error) {
 	}
 
 	return &OpenSearchClient{
-		client: client,
-		index:  cfg.OpenSearch.Index,
+		client:      client,
+		index:       cfg.OpenSearch.Index,
+		routingMode: cfg.OpenSearch.RoutingMode,
 	}, nil
 }
 
+// NewOpenSearchClientFrom wraps an existing client without pinging it.
+func NewOpenSearchClientFrom(client *opensearch.Client, index, routingMode string) *OpenSearchClient {
+	return &OpenSearchClient{client: client, index: index, routingMode: routingMode}
+}
+
 func (c *OpenSearchClient) Client() *opensearch.Client {
 	return c.client
 }
@@ -59,3 +68,10 @@ func (c *OpenSearchClient) Client() *opensearch.Client {
 func (c *OpenSearchClient) Index() string {
 	return c.index
 }
+
+// IndexPattern returns the event indices a caller from clientID may search.
+// An empty clientID (platform users) searches every client's events. With
+// client routing it fails for a client ID that cannot name an index series.
+func (c *OpenSearchClient) IndexPattern(clientID string) (string, error) {
+	return indices.SearchPattern(c.index, c.routingMode, clientID)
+}
@@ -0,0 +1,111 @@
+package cursor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/telhawk-systems/telhawk-stack/search/pkg/model"
+)
+
+func newTestCodec(now time.Time) *Codec {
+	c := NewCodec([]byte("test-key"))
+	c.now = func() time.Time { return now }
+	return c
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	codec := newTestCodec(now)
+
+	token, err := codec.Encode(&Cursor{
+		After:     []interface{}{int64(9007199254740993), "high", 42},
+		PITID:     "pit-1",
+		ClientID:  "client-a",
+		Query:     "q",
+		ExpiresAt: now.Add(time.Minute),
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, token, "pit-1", "cursor should be opaque")
+
+	cur, err := codec.Decode(token, "client-a", "q")
+	require.NoError(t, err)
+	assert.Equal(t, "pit-1", cur.PITID)
+	assert.Equal(t, []interface{}{json.Number("9007199254740993"), "high", json.Number("42")}, cur.After)
+}
+
+func TestCodec_RejectsTampering(t *testing.T) {
+	now := time.Now()
+	codec := newTestCodec(now)
+	token, err := codec.Encode(&Cursor{After: []interface{}{1}, PITID: "pit-1", ExpiresAt: now.Add(time.Minute)})
+	require.NoError(t, err)
+
+	payload, sig, _ := strings.Cut(token, ".")
+	forged, err := codec.Encode(&Cursor{After: []interface{}{1}, PITID: "pit-2", ExpiresAt: now.Add(time.Minute)})
+	require.NoError(t, err)
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	for name, token := range map[string]string{
+		"swapped payload": forgedPayload + "." + sig,
+		"no signature":    payload,
+		"bad encoding":    "!!!." + sig,
+		"empty":           "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := codec.Decode(token, "", "")
+			assert.ErrorIs(t, err, ErrInvalid)
+		})
+	}
+
+	_, err = NewCodec([]byte("other-key")).Decode(token, "", "")
+	assert.ErrorIs(t, err, ErrInvalid, "cursor signed with another key")
+}
+
+func TestCodec_RejectsOtherClient(t *testing.T) {
+	now := time.Now()
+	codec := newTestCodec(now)
+	token, err := codec.Encode(&Cursor{PITID: "pit-1", ClientID: "client-a", ExpiresAt: now.Add(time.Minute)})
+	require.NoError(t, err)
+
+	_, err = codec.Decode(token, "client-b", "")
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = codec.Decode(token, "", "")
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestCodec_RejectsExpired(t *testing.T) {
+	now := time.Now()
+	codec := newTestCodec(now)
+	token, err := codec.Encode(&Cursor{PITID: "pit-1", ExpiresAt: now.Add(time.Minute)})
+	require.NoError(t, err)
+
+	codec.now = func() time.Time { return now.Add(time.Minute) }
+	_, err = codec.Decode(token, "", "")
+	assert.ErrorIs(t, err, ErrExpired)
+}
+
+func TestCodec_RejectsOtherQuery(t *testing.T) {
+	now := time.Now()
+	codec := newTestCodec(now)
+
+	byTime, err := QueryHash(&model.Query{Sort: []model.SortSpec{{Field: ".time", Order: "desc"}}, Limit: 2})
+	require.NoError(t, err)
+	bySeverity, err := QueryHash(&model.Query{Sort: []model.SortSpec{{Field: ".severity_id", Order: "desc"}}, Limit: 2})
+	require.NoError(t, err)
+	require.NotEqual(t, byTime, bySeverity)
+
+	token, err := codec.Encode(&Cursor{PITID: "pit-1", Query: byTime, ExpiresAt: now.Add(time.Minute)})
+	require.NoError(t, err)
+
+	// Later pages pass the cursor in the query, which the hash leaves out
+	paged, err := QueryHash(&model.Query{Sort: []model.SortSpec{{Field: ".time", Order: "desc"}}, Limit: 2, Cursor: token})
+	require.NoError(t, err)
+	_, err = codec.Decode(token, "", paged)
+	assert.NoError(t, err)
+
+	_, err = codec.Decode(token, "", bySeverity)
+	assert.ErrorIs(t, err, ErrInvalid)
+}
@@ -103,26 +103,10 @@ func (h *Handler) EventsByAction(w http.ResponseWriter, r *http.Request) {
 			h.writeJSONAPIError(w, http.StatusBadRequest, "invalid_type", "data.type must be 'event-query'")
 			return
 		}
-		// Inject client_id filter for data isolation
-		if uc.ClientID != "" {
-			clientFilter := &model.FilterExpr{
-				Field:    ".client_id",
-				Operator: model.OpEq,
-				Value:    uc.ClientID,
-			}
-			if q.Filter == nil {
-				q.Filter = clientFilter
-			} else {
-				// Wrap existing filter with AND condition
-				q.Filter = &model.FilterExpr{
-					Type:       model.FilterTypeAnd,
-					Conditions: []model.FilterExpr{*q.Filter, *clientFilter},
-				}
-			}
-		}
-		resp, err := h.svc.ExecuteQuery(r.Context(), &q)
+		// ExecuteQuery scopes the query to the caller's client for data isolation
+		resp, err := h.svc.ExecuteQuery(r.Context(), &q, uc.ClientID)
 		if err != nil {
-			h.writeJSONAPIError(w, http.StatusInternalServerError, "events_query_failed", err.Error())
+			h.writeQueryError(w, err, "events_query_failed")
 			return
 		}
 		data := make([]jsonAPIResource, 0, len(resp.Results))
@@ -142,8 +126,8 @@ func (h *Handler) EventsByAction(w http.ResponseWriter, r *http.Request) {
 			meta["aggregations"] = resp.Aggregations
 		}
 		links := map[string]interface{}{"self": r.URL.RequestURI()}
-		if resp.SearchAfter != nil && len(resp.Results) > 0 {
-			meta["next_cursor"] = resp.SearchAfter
+		if resp.NextCursor != "" {
+			meta["next_cursor"] = resp.NextCursor
 		}
 		w.Header().Set("Content-Type", "application/vnd.api+json")
 		w.WriteHeader(http.StatusOK)
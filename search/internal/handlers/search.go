@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/telhawk-systems/telhawk-stack/search/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/search/internal/service"
 	"github.com/telhawk-systems/telhawk-stack/search/pkg/model"
 )
 
@@ -68,7 +70,8 @@ func (h *Handler) Query(w http.ResponseWriter, r *http.Request) {
 		h.writeJSONAPIError(w, http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be application/vnd.api+json")
 		return
 	}
-	if _, ok := h.requireUser(r); !ok {
+	uc, ok := h.requireUserContext(r)
+	if !ok {
 		h.writeJSONAPIUnauthorized(w)
 		return
 	}
@@ -82,9 +85,9 @@ func (h *Handler) Query(w http.ResponseWriter, r *http.Request) {
 		h.writeJSONAPIError(w, http.StatusBadRequest, "invalid_type", "data.type must be 'query'")
 		return
 	}
-	resp, err := h.svc.ExecuteQuery(r.Context(), &q)
+	resp, err := h.svc.ExecuteQuery(r.Context(), &q, uc.ClientID)
 	if err != nil {
-		h.writeJSONAPIError(w, http.StatusInternalServerError, "query_failed", err.Error())
+		h.writeQueryError(w, err, "query_failed")
 		return
 	}
 	attrs := map[string]interface{}{
@@ -98,9 +101,27 @@ func (h *Handler) Query(w http.ResponseWriter, r *http.Request) {
 	if resp.SearchAfter != nil {
 		attrs["search_after"] = resp.SearchAfter
 	}
+	if resp.NextCursor != "" {
+		attrs["next_cursor"] = resp.NextCursor
+	}
 	h.writeJSONAPIResourceGeneric(w, http.StatusOK, "search-result", resp.RequestID, attrs, nil)
 }
 
+// writeQueryError writes an ExecuteQuery error: 400 for invalid queries and
+// cursors, and 500 with code otherwise.
+func (h *Handler) writeQueryError(w http.ResponseWriter, err error, code string) {
+	switch {
+	case errors.Is(err, service.ErrCursorExpired):
+		h.writeJSONAPIError(w, http.StatusBadRequest, "cursor_expired", "Cursor expired; run the query again from the first page")
+	case errors.Is(err, service.ErrInvalidCursor):
+		h.writeJSONAPIError(w, http.StatusBadRequest, "invalid_cursor", err.Error())
+	case errors.Is(err, service.ErrValidationFailed):
+		h.writeJSONAPIError(w, http.StatusBadRequest, "validation_failed", err.Error())
+	default:
+		h.writeJSONAPIError(w, http.StatusInternalServerError, code, err.Error())
+	}
+}
+
 // Export handles POST /api/v1/export requests.
 func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
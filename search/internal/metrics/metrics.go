@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Correlation job metrics
+	CorrelationJobsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telhawk_search_correlation_jobs_total",
+			Help: "Total number of correlation jobs evaluated",
+		},
+		[]string{"status"}, // triggered, no_match, error
+	)
+
+	CorrelationJobDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "telhawk_search_correlation_job_duration_seconds",
+			Help:    "Correlation job evaluation duration in seconds",
+			Buckets: []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		},
+	)
+
+	// Alert scheduler metrics
+	SchedulerLag = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "telhawk_search_scheduler_lag_seconds",
+			Help:    "Delay between an alert's scheduled run and the start of its execution",
+			Buckets: []float64{.001, .01, .1, .5, 1, 5, 15, 30, 60, 300},
+		},
+	)
+
+	AlertExecutionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "telhawk_search_alert_executions_total",
+			Help: "Total number of scheduled alert executions",
+		},
+		[]string{"status"}, // triggered, no_match, error
+	)
+)
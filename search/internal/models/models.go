@@ -42,6 +42,7 @@ type SearchResponse struct {
 	TotalMatches    int                      `json:"total_matches,omitempty"`
 	Results         []map[string]interface{} `json:"results"`
 	SearchAfter     []interface{}            `json:"search_after,omitempty"`
+	NextCursor      string                   `json:"next_cursor,omitempty"` // Signed cursor of the next page of a canonical query
 	Aggregations    map[string]interface{}   `json:"aggregations,omitempty"`
 	OpenSearchQuery string                   `json:"-"` // Not serialized, used for debug header
 }
@@ -5,20 +5,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/telhawk-systems/telhawk-stack/common/messaging"
 	natsclient "github.com/telhawk-systems/telhawk-stack/common/messaging/nats"
+	"github.com/telhawk-systems/telhawk-stack/search/internal/metrics"
 	"github.com/telhawk-systems/telhawk-stack/search/internal/models"
 	"github.com/telhawk-systems/telhawk-stack/search/internal/service"
 )
 
+// resultPageSize is how many events are published per result page.
+const resultPageSize = 500
+
 // Handler processes NATS messages for search operations.
 type Handler struct {
-	client *natsclient.Client
-	svc    *service.SearchService
-	subs   []messaging.Subscription
-	logger *slog.Logger
+	client  *natsclient.Client
+	svc     *service.SearchService
+	subs    []messaging.Subscription
+	logger  *slog.Logger
+	running sync.Map // job ID -> context.CancelFunc for searches in progress
 }
 
 // NewHandler creates a new NATS handler for search operations.
@@ -55,6 +61,14 @@ func (h *Handler) Start(ctx context.Context) error {
 	}
 	h.subs = append(h.subs, sub2)
 
+	// Subscribe to cancellations without a queue group: the job may be
+	// running on any worker
+	sub3, err := h.client.Subscribe(messaging.SubjectSearchJobsCancel, h.handleCancel)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to search cancellations: %w", err)
+	}
+	h.subs = append(h.subs, sub3)
+
 	h.logger.Info("NATS handler started",
 		slog.String("search_subject", messaging.SubjectSearchJobsQuery),
 		slog.String("correlate_subject", messaging.SubjectSearchJobsCorrelate),
@@ -95,10 +109,19 @@ func (h *Handler) handleSearchJob(ctx context.Context, msg *messaging.Message) e
 
 	start := time.Now()
 
+	// Track the search so a cancel message can stop it
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if req.JobID != "" {
+		h.running.Store(req.JobID, cancel)
+		defer h.running.Delete(req.JobID)
+	}
+
 	// Convert NATS request to internal search request
 	searchReq := &models.SearchRequest{
-		Query: req.Query,
-		Limit: req.Limit,
+		Query:    req.Query,
+		Limit:    req.Limit,
+		ClientID: req.ClientID, // CRITICAL: data isolation for the submitter's client
 	}
 	if !req.TimeRange.From.IsZero() || !req.TimeRange.To.IsZero() {
 		searchReq.TimeRange = &models.TimeRange{
@@ -115,6 +138,11 @@ func (h *Handler) handleSearchJob(ctx context.Context, msg *messaging.Message) e
 		TookMs: time.Since(start).Milliseconds(),
 	}
 
+	if ctx.Err() == context.Canceled {
+		h.logger.Info("Search job cancelled", slog.String("job_id", req.JobID))
+		return nil
+	}
+
 	if err != nil {
 		resp.Success = false
 		resp.Error = err.Error()
@@ -140,9 +168,50 @@ func (h *Handler) handleSearchJob(ctx context.Context, msg *messaging.Message) e
 		}
 	}
 
-	// Also publish to job-specific result subject for async consumers (web backend)
+	// Also publish to job-specific result subject for async consumers (web
+	// backend), one page at a time
 	resultSubject := messaging.SearchQueryResultSubject(req.JobID)
-	return h.client.PublishJSON(ctx, resultSubject, resp)
+	for _, page := range paginateResponse(resp, resultPageSize) {
+		if err := h.client.PublishJSON(ctx, resultSubject, page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// paginateResponse splits a search response into pages of at most pageSize
+// events. Failed and empty responses are a single page.
+func paginateResponse(resp SearchJobResponse, pageSize int) []SearchJobResponse {
+	total := (len(resp.Events) + pageSize - 1) / pageSize
+	if total == 0 || !resp.Success {
+		resp.Page, resp.TotalPages = 1, 1
+		return []SearchJobResponse{resp}
+	}
+
+	pages := make([]SearchJobResponse, 0, total)
+	for i := 0; i < total; i++ {
+		page := resp
+		page.Events = resp.Events[i*pageSize : min((i+1)*pageSize, len(resp.Events))]
+		page.Page, page.TotalPages = i+1, total
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// handleCancel stops a search running on this worker, if any.
+func (h *Handler) handleCancel(ctx context.Context, msg *messaging.Message) error {
+	var req SearchJobCancel
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		h.logger.Error("Failed to unmarshal search cancel request",
+			slog.String("error", err.Error()))
+		return err
+	}
+
+	if cancel, ok := h.running.Load(req.JobID); ok {
+		cancel.(context.CancelFunc)()
+		h.logger.Info("Cancelling search job", slog.String("job_id", req.JobID))
+	}
+	return nil
 }
 
 // handleCorrelationJob processes correlation evaluation requests from NATS.
@@ -183,7 +252,10 @@ func (h *Handler) handleCorrelationJob(ctx context.Context, msg *messaging.Messa
 		TookMs:          time.Since(start).Milliseconds(),
 	}
 
+	metrics.CorrelationJobDuration.Observe(time.Since(start).Seconds())
+
 	if err != nil {
+		metrics.CorrelationJobsTotal.WithLabelValues("error").Inc()
 		resp.Success = false
 		resp.Error = err.Error()
 		h.logger.Error("Correlation job failed",
@@ -196,6 +268,11 @@ func (h *Handler) handleCorrelationJob(ctx context.Context, msg *messaging.Messa
 		resp.Triggered = len(matches) > 0
 		resp.MatchCount = len(matches)
 		resp.Matches = matches
+		if resp.Triggered {
+			metrics.CorrelationJobsTotal.WithLabelValues("triggered").Inc()
+		} else {
+			metrics.CorrelationJobsTotal.WithLabelValues("no_match").Inc()
+		}
 		h.logger.Info("Correlation job completed",
 			slog.String("job_id", req.JobID),
 			slog.Bool("triggered", resp.Triggered),
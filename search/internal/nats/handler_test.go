@@ -0,0 +1,35 @@
+package nats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginateResponse(t *testing.T) {
+	events := make([]map[string]interface{}, 5)
+	for i := range events {
+		events[i] = map[string]interface{}{"n": i}
+	}
+
+	pages := paginateResponse(SearchJobResponse{JobID: "job-1", Success: true, TotalHits: 5, Events: events}, 2)
+	assert.Len(t, pages, 3)
+	for i, page := range pages {
+		assert.Equal(t, i+1, page.Page)
+		assert.Equal(t, 3, page.TotalPages)
+		assert.Equal(t, int64(5), page.TotalHits)
+	}
+	assert.Len(t, pages[2].Events, 1)
+	assert.Equal(t, 4, pages[2].Events[0]["n"])
+}
+
+func TestPaginateResponse_SinglePage(t *testing.T) {
+	empty := paginateResponse(SearchJobResponse{JobID: "job-1", Success: true}, 2)
+	assert.Len(t, empty, 1)
+	assert.Equal(t, 1, empty[0].TotalPages)
+
+	failed := paginateResponse(SearchJobResponse{JobID: "job-1", Error: "boom"}, 2)
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "boom", failed[0].Error)
+	assert.Equal(t, 1, failed[0].Page)
+}
@@ -7,6 +7,7 @@ import "time"
 // It represents a request to execute an ad-hoc search query.
 type SearchJobRequest struct {
 	JobID     string                 `json:"job_id"`
+	ClientID  string                 `json:"client_id,omitempty"` // Submitter's client; empty searches all clients
 	Query     string                 `json:"query"`
 	TimeRange TimeRange              `json:"time_range"`
 	Filters   map[string]interface{} `json:"filters,omitempty"`
@@ -22,13 +23,22 @@ type TimeRange struct {
 
 // SearchJobResponse is the message format for search results.
 // It is published to reply subjects after processing a SearchJobRequest.
+// On the job's result subject, results are split into pages: Page is 1-based
+// and TotalPages is the number of pages published for the job.
 type SearchJobResponse struct {
-	JobID     string                   `json:"job_id"`
-	Success   bool                     `json:"success"`
-	Error     string                   `json:"error,omitempty"`
-	TotalHits int64                    `json:"total_hits"`
-	Events    []map[string]interface{} `json:"events"`
-	TookMs    int64                    `json:"took_ms"`
+	JobID      string                   `json:"job_id"`
+	Success    bool                     `json:"success"`
+	Error      string                   `json:"error,omitempty"`
+	TotalHits  int64                    `json:"total_hits"`
+	Events     []map[string]interface{} `json:"events"`
+	TookMs     int64                    `json:"took_ms"`
+	Page       int                      `json:"page,omitempty"`
+	TotalPages int                      `json:"total_pages,omitempty"`
+}
+
+// SearchJobCancel is the message format for search.jobs.cancel subject.
+type SearchJobCancel struct {
+	JobID string `json:"job_id"`
 }
 
 // CorrelationJobRequest is the message format for search.jobs.correlate subject.
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/telhawk-systems/telhawk-stack/search/internal/metrics"
 	"github.com/telhawk-systems/telhawk-stack/search/internal/models"
 	"github.com/telhawk-systems/telhawk-stack/search/internal/notification"
 )
@@ -207,7 +208,8 @@ func (s *Scheduler) runAlert(ctx context.Context, timer *alertTimer) {
 			return
 		case <-timer.stopChan:
 			return
-		case <-timer.ticker.C:
+		case tick := <-timer.ticker.C:
+			metrics.SchedulerLag.Observe(time.Since(tick).Seconds())
 			s.executeAlert(ctx, timer.alertID)
 		}
 	}
@@ -266,9 +268,11 @@ func (s *Scheduler) executeAlert(ctx context.Context, alertID string) {
 	}
 
 	if resp.ResultCount == 0 {
+		metrics.AlertExecutionsTotal.WithLabelValues("no_match").Inc()
 		return
 	}
 
+	metrics.AlertExecutionsTotal.WithLabelValues("triggered").Inc()
 	log.Printf("alert %s (%s) triggered with %d results", alert.ID, alert.Name, resp.ResultCount)
 
 	s.metrics.mu.Lock()
@@ -293,6 +297,7 @@ func (s *Scheduler) executeAlert(ctx context.Context, alertID string) {
 }
 
 func (s *Scheduler) incrementErrors() {
+	metrics.AlertExecutionsTotal.WithLabelValues("error").Inc()
 	s.metrics.mu.Lock()
 	s.metrics.AlertErrors++
 	s.metrics.mu.Unlock()
@@ -3,6 +3,7 @@ package server
 import (
 	"net/http"
 
+	"github.com/telhawk-systems/telhawk-stack/common/instrumentation"
 	"github.com/telhawk-systems/telhawk-stack/common/middleware"
 
 	"github.com/telhawk-systems/telhawk-stack/search/internal/handlers"
@@ -24,5 +25,9 @@ func NewRouter(h *handlers.Handler) http.Handler {
 	mux.HandleFunc("/api/v1/saved-searches/", h.SavedSearchByID)
 	mux.HandleFunc("/api/v1/export", h.Export)
 	mux.HandleFunc("/healthz", h.Health)
-	return middleware.RequestID(mux)
+
+	// Prometheus metrics
+	mux.Handle("GET /metrics", instrumentation.MetricsHandler())
+
+	return middleware.RequestID(instrumentation.Middleware("search", mux))
 }
@@ -260,22 +260,7 @@ func (s *SearchService) RunSavedSearch(ctx context.Context, id string, clientID
 	}
 
 	// CRITICAL: Inject client_id filter for data isolation (multi-tenant security)
-	if clientID != "" {
-		clientFilter := &model.FilterExpr{
-			Field:    ".client_id",
-			Operator: model.OpEq,
-			Value:    clientID,
-		}
-		if query.Filter == nil {
-			query.Filter = clientFilter
-		} else {
-			// Wrap existing filter with AND condition
-			query.Filter = &model.FilterExpr{
-				Type:       model.FilterTypeAnd,
-				Conditions: []model.FilterExpr{*query.Filter, *clientFilter},
-			}
-		}
-	}
+	scopeQueryToClient(&query, clientID)
 
 	// Translate Phase 2 query to OpenSearch DSL
 	translator := translator.NewOpenSearchTranslator()
@@ -284,6 +269,11 @@ func (s *SearchService) RunSavedSearch(ctx context.Context, id string, clientID
 		return nil, fmt.Errorf("translate saved query: %w", err)
 	}
 
+	index, err := s.osClient.IndexPattern(clientID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Execute using OpenSearch client
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(osQuery); err != nil {
@@ -291,7 +281,7 @@ func (s *SearchService) RunSavedSearch(ctx context.Context, id string, clientID
 	}
 	res, err := s.osClient.Client().Search(
 		s.osClient.Client().Search.WithContext(ctx),
-		s.osClient.Client().Search.WithIndex(s.osClient.Index()+"*"),
+		s.osClient.Client().Search.WithIndex(index),
 		s.osClient.Client().Search.WithBody(&buf),
 		s.osClient.Client().Search.WithTrackTotalHits(true),
 	)
@@ -4,15 +4,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"time"
 
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+	"github.com/telhawk-systems/telhawk-stack/search/internal/cursor"
 	"github.com/telhawk-systems/telhawk-stack/search/internal/models"
 	"github.com/telhawk-systems/telhawk-stack/search/internal/translator"
 	"github.com/telhawk-systems/telhawk-stack/search/internal/validator"
 	"github.com/telhawk-systems/telhawk-stack/search/pkg/model"
 )
 
+// maxPageSize is the most hits one page of a canonical query returns
+// (OpenSearch's default index.max_result_window).
+const maxPageSize = 10000
+
 // ExecuteSearch executes a search query against OpenSearch.
 func (s *SearchService) ExecuteSearch(ctx context.Context, req *models.SearchRequest) (*models.SearchResponse, error) {
 	startTime := time.Now()
@@ -27,6 +36,11 @@ func (s *SearchService) ExecuteSearch(ctx context.Context, req *models.SearchReq
 
 	query := s.buildOpenSearchQuery(req)
 
+	index, err := s.osClient.IndexPattern(req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(query); err != nil {
 		return nil, fmt.Errorf("encode query: %w", err)
@@ -34,7 +48,7 @@ func (s *SearchService) ExecuteSearch(ctx context.Context, req *models.SearchReq
 
 	res, err := s.osClient.Client().Search(
 		s.osClient.Client().Search.WithContext(ctx),
-		s.osClient.Client().Search.WithIndex(s.osClient.Index()+"*"),
+		s.osClient.Client().Search.WithIndex(index),
 		s.osClient.Client().Search.WithBody(&buf),
 		s.osClient.Client().Search.WithSize(limit),
 		s.osClient.Client().Search.WithTrackTotalHits(true),
@@ -106,9 +120,39 @@ func (s *SearchService) ExecuteSearch(ctx context.Context, req *models.SearchReq
 }
 
 // ExecuteQuery executes a canonical JSON query and returns search results.
-func (s *SearchService) ExecuteQuery(ctx context.Context, q *model.Query) (*models.SearchResponse, error) {
+// A non-empty clientID restricts the query to that client's events.
+//
+// A full first page of a query without an offset is read again from a new
+// point in time and returns NextCursor, the signed position after its last
+// hit; passing it back as the query's cursor returns the next page, read
+// from the same point in time so pages see the same events while ingestion
+// continues. Other queries, such as aggregation-only ones, never open a point
+// in time. Cursors fail with ErrInvalidCursor if tampered with, used by
+// another client or with a different query, and with ErrCursorExpired once
+// their point in time has expired.
+func (s *SearchService) ExecuteQuery(ctx context.Context, q *model.Query, clientID string) (*models.SearchResponse, error) {
 	startTime := time.Now()
 
+	// Hash the query as sent, before the client filter is added
+	queryHash, err := cursor.QueryHash(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var page *cursor.Cursor
+	if q.Cursor != "" {
+		if page, err = s.cursors.Decode(q.Cursor, clientID, queryHash); err != nil {
+			return nil, err
+		}
+	}
+
+	scopeQueryToClient(q, clientID)
+
+	index, err := s.osClient.IndexPattern(clientID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate the query
 	validator := validator.NewQueryValidator()
 	if err := validator.Validate(q); err != nil {
@@ -121,43 +165,43 @@ func (s *SearchService) ExecuteQuery(ctx context.Context, q *model.Query) (*mode
 	if err != nil {
 		return nil, fmt.Errorf("query translation failed: %w", err)
 	}
-
-	// Execute the query
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(osQuery); err != nil {
-		return nil, fmt.Errorf("encode query: %w", err)
+	if size, ok := osQuery["size"].(int); ok && size > maxPageSize {
+		osQuery["size"] = maxPageSize
 	}
+	size, _ := osQuery["size"].(int)
 
-	res, err := s.osClient.Client().Search(
-		s.osClient.Client().Search.WithContext(ctx),
-		s.osClient.Client().Search.WithIndex(s.osClient.Index()+"*"),
-		s.osClient.Client().Search.WithBody(&buf),
-		s.osClient.Client().Search.WithTrackTotalHits(true),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("search request: %w", err)
+	pitID := ""
+	if page != nil {
+		pitID = page.PITID
+		translator.Paginate(osQuery, pitID, s.keepAlive, page.After)
 	}
-	defer res.Body.Close()
-
-	if res.IsError() {
-		return nil, fmt.Errorf("search error: %s", res.String())
+	searchResult, err := s.searchQuery(ctx, index, pitID, osQuery)
+	if errors.Is(err, errPITNotFound) && page != nil {
+		// The point in time expired before the cursor did
+		return nil, ErrCursorExpired
 	}
-
-	var searchResult struct {
-		Hits struct {
-			Total struct {
-				Value int `json:"value"`
-			} `json:"total"`
-			Hits []struct {
-				Source map[string]interface{} `json:"_source"`
-				Sort   []interface{}          `json:"sort"`
-			} `json:"hits"`
-		} `json:"hits"`
-		Aggregations map[string]interface{} `json:"aggregations,omitempty"`
+	if err != nil {
+		return nil, err
 	}
 
-	if err := json.NewDecoder(res.Body).Decode(&searchResult); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	// More pages may follow a full first page: read it again from a point in
+	// time the next pages can continue from
+	if page == nil && q.Offset == 0 && size > 0 && len(searchResult.Hits.Hits) == size {
+		if pitID, err = s.openPIT(ctx, index); err != nil {
+			return nil, err
+		}
+		translator.Paginate(osQuery, pitID, s.keepAlive, nil)
+		if searchResult, err = s.searchQuery(ctx, index, pitID, osQuery); err != nil {
+			s.closePIT(pitID)
+			return nil, err
+		}
+	}
+	// On failure, release a point in time opened for this query; one from a
+	// cursor stays open so the page can be retried
+	abort := func() {
+		if page == nil {
+			s.closePIT(pitID)
+		}
 	}
 
 	results := make([]map[string]interface{}, 0, len(searchResult.Hits.Hits))
@@ -171,6 +215,7 @@ func (s *SearchService) ExecuteQuery(ctx context.Context, q *model.Query) (*mode
 	// Serialize the OpenSearch query for debugging
 	osQueryJSON, err := json.Marshal(osQuery)
 	if err != nil {
+		abort()
 		return nil, fmt.Errorf("marshal opensearch query: %w", err)
 	}
 
@@ -183,11 +228,36 @@ func (s *SearchService) ExecuteQuery(ctx context.Context, q *model.Query) (*mode
 		OpenSearchQuery: string(osQueryJSON),
 	}
 
-	var nextCursor []interface{}
-	if len(results) == q.Limit && len(searchResult.Hits.Hits) > 0 {
-		nextCursor = searchResult.Hits.Hits[len(searchResult.Hits.Hits)-1].Sort
+	if size > 0 && len(results) == size {
+		last := searchResult.Hits.Hits[len(searchResult.Hits.Hits)-1]
+		sortValues := make([]interface{}, len(last.Sort))
+		for i, v := range last.Sort {
+			sortValues[i] = v
+		}
+		response.SearchAfter = sortValues
+
+		if pitID != "" {
+			// OpenSearch may return a new ID for the point in time
+			if searchResult.PITID != "" {
+				pitID = searchResult.PITID
+			}
+			next, err := s.cursors.Encode(&cursor.Cursor{
+				After:     sortValues,
+				PITID:     pitID,
+				ClientID:  clientID,
+				Query:     queryHash,
+				ExpiresAt: time.Now().Add(s.keepAlive),
+			})
+			if err != nil {
+				abort()
+				return nil, err
+			}
+			response.NextCursor = next
+		}
+	} else {
+		// Last page: release the point in time rather than wait for it to expire
+		s.closePIT(pitID)
 	}
-	response.SearchAfter = nextCursor
 
 	if searchResult.Aggregations != nil && len(searchResult.Aggregations) > 0 {
 		response.Aggregations = searchResult.Aggregations
@@ -196,6 +266,124 @@ func (s *SearchService) ExecuteQuery(ctx context.Context, q *model.Query) (*mode
 	return response, nil
 }
 
+// errPITNotFound is returned by searchQuery when its point in time no longer
+// exists.
+var errPITNotFound = errors.New("point in time not found")
+
+// queryResult is the part of an OpenSearch search response ExecuteQuery uses.
+type queryResult struct {
+	PITID string `json:"pit_id"`
+	Hits  struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			Source map[string]interface{} `json:"_source"`
+			Sort   []json.RawMessage      `json:"sort"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]interface{} `json:"aggregations,omitempty"`
+}
+
+// searchQuery runs a translated query against pitID, or against index when
+// pitID is empty.
+func (s *SearchService) searchQuery(ctx context.Context, index, pitID string, osQuery map[string]interface{}) (*queryResult, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(osQuery); err != nil {
+		return nil, fmt.Errorf("encode query: %w", err)
+	}
+
+	search := s.osClient.Client().Search
+	opts := []func(*opensearchapi.SearchRequest){
+		search.WithContext(ctx),
+		search.WithBody(&buf),
+		search.WithTrackTotalHits(true),
+	}
+	if pitID == "" {
+		opts = append(opts, search.WithIndex(index))
+	}
+	res, err := search(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("search request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if pitID != "" && res.StatusCode == http.StatusNotFound {
+			return nil, errPITNotFound
+		}
+		return nil, fmt.Errorf("search error: %s", res.String())
+	}
+
+	var result queryResult
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &result, nil
+}
+
+// openPIT opens a point in time over the indices matching index.
+func (s *SearchService) openPIT(ctx context.Context, index string) (string, error) {
+	create := s.osClient.Client().PointInTime.Create
+	res, pit, err := create(
+		create.WithContext(ctx),
+		create.WithIndex(index),
+		create.WithKeepAlive(s.keepAlive),
+	)
+	if err != nil {
+		return "", fmt.Errorf("create point in time: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() || pit == nil || pit.PitID == "" {
+		return "", fmt.Errorf("create point in time: %s", res.String())
+	}
+	return pit.PitID, nil
+}
+
+// closePIT deletes a point in time. Failures are only logged, since the
+// point in time expires on its own.
+func (s *SearchService) closePIT(pitID string) {
+	if pitID == "" {
+		return
+	}
+	del := s.osClient.Client().PointInTime.Delete
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, _, err := del(del.WithContext(ctx), del.WithPitID(pitID))
+	if err != nil {
+		slog.Warn("Failed to delete point in time", slog.String("error", err.Error()))
+		return
+	}
+	defer res.Body.Close()
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		slog.Warn("Failed to delete point in time", slog.String("status", res.Status()))
+	}
+}
+
+// scopeQueryToClient adds a client_id condition to a canonical query.
+// CRITICAL: this is the data isolation filter for multi-tenant security. It
+// applies even with per-client index routing, since events indexed before
+// routing was enabled remain in the shared indices.
+func scopeQueryToClient(q *model.Query, clientID string) {
+	if clientID == "" {
+		return
+	}
+	clientFilter := &model.FilterExpr{
+		Field:    ".client_id",
+		Operator: model.OpEq,
+		Value:    clientID,
+	}
+	if q.Filter == nil {
+		q.Filter = clientFilter
+		return
+	}
+	// Wrap existing filter with AND condition
+	q.Filter = &model.FilterExpr{
+		Type:       model.FilterTypeAnd,
+		Conditions: []model.FilterExpr{*q.Filter, *clientFilter},
+	}
+}
+
 // buildOpenSearchQuery constructs an OpenSearch query from a SearchRequest.
 func (s *SearchService) buildOpenSearchQuery(req *models.SearchRequest) map[string]interface{} {
 	query := make(map[string]interface{})
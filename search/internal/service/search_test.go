@@ -1,13 +1,20 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/opensearch-project/opensearch-go/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/telhawk-systems/telhawk-stack/search/internal/client"
 	"github.com/telhawk-systems/telhawk-stack/search/internal/models"
+	"github.com/telhawk-systems/telhawk-stack/search/pkg/model"
 )
 
 func TestExecuteSearch_LimitConstraints(t *testing.T) {
@@ -594,3 +601,200 @@ func TestIncludeFieldsFiltering(t *testing.T) {
 	assert.NotContains(t, filtered, "user")
 	assert.NotContains(t, filtered, "ip")
 }
+
+// fakePITSearch is an OpenSearch stub serving docs sorted by time desc from
+// indices or points in time.
+type fakePITSearch struct {
+	docs    []string
+	opened  []string // Indices points in time were opened on
+	deleted []string
+	paths   []string // Paths of search requests
+	bodies  []map[string]interface{}
+	expired bool
+}
+
+func (f *fakePITSearch) handler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_search/point_in_time") && r.Method == http.MethodPost:
+			f.opened = append(f.opened, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/_search/point_in_time"))
+			_, _ = w.Write([]byte(`{"pit_id":"pit-1"}`))
+		case r.URL.Path == "/_search/point_in_time" && r.Method == http.MethodDelete:
+			var body struct {
+				PitID []string `json:"pit_id"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			f.deleted = append(f.deleted, body.PitID...)
+			_, _ = w.Write([]byte(`{"pits":[]}`))
+		case strings.HasSuffix(r.URL.Path, "/_search"):
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			f.paths = append(f.paths, r.URL.Path)
+			f.bodies = append(f.bodies, body)
+			if f.expired {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":{"type":"search_context_missing_exception"}}`))
+				return
+			}
+			// Searches of a point in time sort by _shard_doc last
+			start := 0
+			if after, ok := body["search_after"].([]interface{}); ok {
+				start = int(after[len(after)-1].(float64)) + 1
+			}
+			_, pit := body["pit"]
+			end := min(start+int(body["size"].(float64)), len(f.docs))
+			hits := []map[string]interface{}{}
+			for i := start; i < end; i++ {
+				sort := []interface{}{1000 - i}
+				if pit {
+					sort = append(sort, i)
+				}
+				hits = append(hits, map[string]interface{}{
+					"_id":     f.docs[i],
+					"_source": map[string]interface{}{"id": f.docs[i]},
+					"sort":    sort,
+				})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"pit_id": "pit-1",
+				"hits": map[string]interface{}{
+					"total": map[string]interface{}{"value": len(f.docs)},
+					"hits":  hits,
+				},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+}
+
+func newPITTestService(t *testing.T, fake *fakePITSearch) *SearchService {
+	server := httptest.NewServer(fake.handler(t))
+	t.Cleanup(server.Close)
+	os, err := opensearch.NewClient(opensearch.Config{Addresses: []string{server.URL}})
+	require.NoError(t, err)
+	return NewSearchService("test", client.NewOpenSearchClientFrom(os, "telhawk-events", "shared")).
+		WithPagination("test-secret", time.Minute)
+}
+
+func TestExecuteQuery_CursorPagination(t *testing.T) {
+	fake := &fakePITSearch{docs: []string{"d0", "d1", "d2", "d3", "d4"}}
+	svc := newPITTestService(t, fake)
+
+	var ids []interface{}
+	cursor := ""
+	pages := 0
+	for {
+		resp, err := svc.ExecuteQuery(context.Background(), &model.Query{Limit: 2, Cursor: cursor}, "client-a")
+		require.NoError(t, err)
+		pages++
+		for _, result := range resp.Results {
+			ids = append(ids, result["id"])
+		}
+		assert.Equal(t, 5, resp.TotalMatches)
+		if resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []interface{}{"d0", "d1", "d2", "d3", "d4"}, ids)
+	assert.Len(t, fake.opened, 1, "one point in time for all pages")
+	assert.Equal(t, []string{"pit-1"}, fake.deleted, "last page releases the point in time")
+
+	// The full first page is read again from the point in time
+	require.Len(t, fake.bodies, 4)
+	assert.NotContains(t, fake.bodies[0], "pit")
+	assert.Equal(t, map[string]interface{}{"id": "pit-1", "keep_alive": "60000ms"}, fake.bodies[1]["pit"])
+
+	// Later pages read from the point in time after the previous page's last hit
+	second := fake.bodies[2]
+	assert.Equal(t, map[string]interface{}{"id": "pit-1", "keep_alive": "60000ms"}, second["pit"])
+	assert.Equal(t, []interface{}{float64(999), float64(1)}, second["search_after"])
+	assert.Equal(t, map[string]interface{}{"order": "asc"}, second["sort"].([]interface{})[1].(map[string]interface{})["_shard_doc"])
+}
+
+func TestExecuteQuery_PartialFirstPageSkipsPointInTime(t *testing.T) {
+	fake := &fakePITSearch{docs: []string{"d0", "d1", "d2"}}
+	svc := newPITTestService(t, fake)
+
+	resp, err := svc.ExecuteQuery(context.Background(), &model.Query{
+		Limit:        5,
+		Aggregations: []model.Aggregation{{Type: "terms", Field: ".severity", Name: "by_severity", Size: 5}},
+	}, "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, resp.ResultCount)
+	assert.Empty(t, fake.opened)
+	assert.Empty(t, fake.deleted)
+	assert.Equal(t, []string{"/telhawk-events*/_search"}, fake.paths)
+	assert.Empty(t, resp.NextCursor)
+}
+
+func TestExecuteQuery_CursorRejectedForOtherQuery(t *testing.T) {
+	svc := newPITTestService(t, &fakePITSearch{docs: []string{"d0", "d1", "d2"}})
+
+	byTime := []model.SortSpec{{Field: ".time", Order: "desc"}}
+	resp, err := svc.ExecuteQuery(context.Background(), &model.Query{Limit: 2, Sort: byTime}, "")
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.NextCursor)
+
+	_, err = svc.ExecuteQuery(context.Background(), &model.Query{
+		Limit:  2,
+		Sort:   []model.SortSpec{{Field: ".severity_id", Order: "desc"}},
+		Cursor: resp.NextCursor,
+	}, "")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = svc.ExecuteQuery(context.Background(), &model.Query{Limit: 2, Sort: byTime, Cursor: resp.NextCursor}, "")
+	assert.NoError(t, err)
+}
+
+func TestExecuteQuery_CursorRejectedForOtherClient(t *testing.T) {
+	svc := newPITTestService(t, &fakePITSearch{docs: []string{"d0", "d1", "d2"}})
+
+	resp, err := svc.ExecuteQuery(context.Background(), &model.Query{Limit: 2}, "client-a")
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.NextCursor)
+
+	_, err = svc.ExecuteQuery(context.Background(), &model.Query{Limit: 2, Cursor: resp.NextCursor}, "client-b")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = svc.ExecuteQuery(context.Background(), &model.Query{Limit: 2, Cursor: resp.NextCursor + "x"}, "client-a")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestExecuteQuery_CursorExpired(t *testing.T) {
+	fake := &fakePITSearch{docs: []string{"d0", "d1", "d2"}}
+	svc := newPITTestService(t, fake)
+
+	resp, err := svc.ExecuteQuery(context.Background(), &model.Query{Limit: 2}, "")
+	require.NoError(t, err)
+
+	// The point in time expired on the cluster
+	fake.expired = true
+	_, err = svc.ExecuteQuery(context.Background(), &model.Query{Limit: 2, Cursor: resp.NextCursor}, "")
+	assert.ErrorIs(t, err, ErrCursorExpired)
+
+	// The cursor itself expired
+	svc.WithPagination("test-secret", time.Nanosecond)
+	fake.expired = false
+	resp, err = svc.ExecuteQuery(context.Background(), &model.Query{Limit: 2}, "")
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, err = svc.ExecuteQuery(context.Background(), &model.Query{Limit: 2, Cursor: resp.NextCursor}, "")
+	assert.ErrorIs(t, err, ErrCursorExpired)
+}
+
+func TestExecuteQuery_OffsetSkipsPointInTime(t *testing.T) {
+	fake := &fakePITSearch{docs: []string{"d0", "d1", "d2"}}
+	svc := newPITTestService(t, fake)
+
+	resp, err := svc.ExecuteQuery(context.Background(), &model.Query{Limit: 2, Offset: 1}, "")
+	require.NoError(t, err)
+	assert.Empty(t, fake.opened)
+	assert.Equal(t, []string{"/telhawk-events*/_search"}, fake.paths)
+	assert.NotContains(t, fake.bodies[0], "pit")
+	assert.Empty(t, resp.NextCursor)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/telhawk-systems/telhawk-stack/search/internal/auth"
 	"github.com/telhawk-systems/telhawk-stack/search/internal/client"
+	"github.com/telhawk-systems/telhawk-stack/search/internal/cursor"
 	"github.com/telhawk-systems/telhawk-stack/search/internal/models"
 	"github.com/telhawk-systems/telhawk-stack/search/internal/repository"
 )
@@ -18,8 +19,14 @@ var (
 	ErrDashboardNotFound = errors.New("dashboard not found")
 	ErrSearchDisabled    = errors.New("search_disabled")
 	ErrValidationFailed  = errors.New("validation failed")
+	ErrInvalidCursor     = cursor.ErrInvalid
+	ErrCursorExpired     = cursor.ErrExpired
 )
 
+// defaultKeepAlive is how long a point in time stays open between pages
+// unless WithPagination sets it.
+const defaultKeepAlive = time.Minute
+
 // SearchService provides implementations for the search API surface.
 type SearchService struct {
 	mu         sync.RWMutex
@@ -29,6 +36,10 @@ type SearchService struct {
 	dashboards map[string]models.Dashboard
 	osClient   *client.OpenSearchClient
 
+	// cursor pagination of canonical queries
+	cursors   *cursor.Codec
+	keepAlive time.Duration
+
 	// saved searches backing store + auth
 	repo       *repository.PostgresRepository
 	authClient *auth.Client
@@ -41,6 +52,8 @@ func NewSearchService(version string, osClient *client.OpenSearchClient) *Search
 		startedAt: now,
 		version:   version,
 		osClient:  osClient,
+		cursors:   cursor.NewCodec(nil),
+		keepAlive: defaultKeepAlive,
 		alerts: map[string]models.Alert{
 			"a1b6e360-3c35-4d63-87fd-03b27ef77d1f": {
 				ID:          "a1b6e360-3c35-4d63-87fd-03b27ef77d1f",
@@ -93,6 +106,19 @@ func (s *SearchService) WithDependencies(repo *repository.PostgresRepository, au
 	return s
 }
 
+// WithPagination sets the key signing query cursors and how long their
+// points in time stay open between pages. An empty secret keeps the random
+// per-process key, and a zero keepAlive the default.
+func (s *SearchService) WithPagination(secret string, keepAlive time.Duration) *SearchService {
+	if secret != "" {
+		s.cursors = cursor.NewCodec([]byte(secret))
+	}
+	if keepAlive > 0 {
+		s.keepAlive = keepAlive
+	}
+	return s
+}
+
 // UserContext holds authenticated user information including data isolation context.
 type UserContext struct {
 	UserID         string
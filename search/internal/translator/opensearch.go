@@ -82,12 +82,8 @@ func (t *OpenSearchTranslator) Translate(q *model.Query) (map[string]interface{}
 		query["from"] = q.Offset
 	}
 
-	// Add cursor-based pagination (search_after)
-	if q.Cursor != "" {
-		// Cursor would be decoded to search_after values
-		// For now, this is a placeholder for future implementation
-		// query["search_after"] = decodeCursor(q.Cursor)
-	}
+	// Cursor pagination (search_after over a point in time) is added by
+	// Paginate, since decoding a cursor needs the service's signing key
 
 	// Add aggregations
 	if len(q.Aggregations) > 0 {
@@ -101,6 +97,32 @@ func (t *OpenSearchTranslator) Translate(q *model.Query) (map[string]interface{}
 	return query, nil
 }
 
+// TieBreakerField is the unique field appended to the sort of paginated
+// queries, so hits with equal sort values keep a stable order across pages.
+// _shard_doc is only defined for searches of a point in time, and unlike _id
+// needs no field data.
+const TieBreakerField = "_shard_doc"
+
+// Paginate turns a translated query into a page read from a point in time:
+// it searches pitID (renewing it for keepAlive) instead of an index, sorts
+// by the tie-breaker last and, when after is set, starts after that
+// position. Queries with a point in time must not name an index.
+func (t *OpenSearchTranslator) Paginate(query map[string]interface{}, pitID string, keepAlive time.Duration, after []interface{}) {
+	query["pit"] = map[string]interface{}{
+		"id":         pitID,
+		"keep_alive": fmt.Sprintf("%dms", keepAlive.Milliseconds()),
+	}
+	if sorts, ok := query["sort"].([]map[string]interface{}); ok {
+		query["sort"] = append(sorts, map[string]interface{}{
+			TieBreakerField: map[string]interface{}{"order": "asc"},
+		})
+	}
+	if len(after) > 0 {
+		query["search_after"] = after
+	}
+	delete(query, "from")
+}
+
 // buildBoolQuery constructs the OpenSearch bool query from filter and time range.
 func (t *OpenSearchTranslator) buildBoolQuery(filter *model.FilterExpr, timeRange *model.TimeRangeDef) (map[string]interface{}, error) {
 	boolQuery := make(map[string]interface{})
@@ -432,6 +432,56 @@ func TestTranslatePagination(t *testing.T) {
 	}
 }
 
+func TestPaginate(t *testing.T) {
+	translator := NewOpenSearchTranslator()
+
+	result, err := translator.Translate(&model.Query{
+		Sort:  []model.SortSpec{{Field: ".severity_id", Order: "desc"}},
+		Limit: 50,
+	})
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	translator.Paginate(result, "pit-1", time.Minute, []interface{}{4, 17})
+
+	pit, ok := result["pit"].(map[string]interface{})
+	if !ok || pit["id"] != "pit-1" || pit["keep_alive"] != "60000ms" {
+		t.Errorf("Expected pit pit-1 kept alive 60000ms, got %v", result["pit"])
+	}
+	sorts := result["sort"].([]map[string]interface{})
+	if len(sorts) != 2 {
+		t.Fatalf("Expected requested sort plus tie-breaker, got %v", sorts)
+	}
+	if _, ok := sorts[1][TieBreakerField]; !ok {
+		t.Errorf("Expected tie-breaker sort last, got %v", sorts[1])
+	}
+	after, _ := json.Marshal(result["search_after"])
+	if string(after) != `[4,17]` {
+		t.Errorf("Expected search_after [4,17], got %s", after)
+	}
+	if result["size"] != 50 {
+		t.Errorf("Expected size=50, got %v", result["size"])
+	}
+}
+
+func TestPaginate_FirstPage(t *testing.T) {
+	translator := NewOpenSearchTranslator()
+
+	result, err := translator.Translate(&model.Query{})
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	translator.Paginate(result, "pit-1", time.Minute, nil)
+
+	if _, ok := result["search_after"]; ok {
+		t.Errorf("First page should not have search_after, got %v", result["search_after"])
+	}
+	sorts := result["sort"].([]map[string]interface{})
+	if len(sorts) != 2 || sorts[0]["time"] == nil {
+		t.Errorf("Expected default time sort plus tie-breaker, got %v", sorts)
+	}
+}
+
 func TestTranslateComplexQuery(t *testing.T) {
 	translator := NewOpenSearchTranslator()
 
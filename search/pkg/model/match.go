@@ -0,0 +1,271 @@
+package model
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Matcher evaluates a filter against events in memory, for consumers that see
+// events without going through OpenSearch (such as live tail). Semantics
+// follow the OpenSearch translation: string comparisons are case-sensitive,
+// regex patterns must match the whole value, and a condition on an array
+// field matches if any element matches.
+type Matcher struct {
+	match matchFunc
+}
+
+type matchFunc func(event map[string]interface{}) bool
+
+// CompileFilter validates a filter and compiles it into a Matcher. A nil
+// filter matches every event.
+func CompileFilter(filter *FilterExpr) (*Matcher, error) {
+	if filter == nil {
+		return &Matcher{match: func(map[string]interface{}) bool { return true }}, nil
+	}
+	match, err := compileFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return &Matcher{match: match}, nil
+}
+
+// Match reports whether the event satisfies the filter.
+func (m *Matcher) Match(event map[string]interface{}) bool {
+	return m.match(event)
+}
+
+func compileFilter(filter *FilterExpr) (matchFunc, error) {
+	if filter.IsSimpleCondition() {
+		return compileCondition(filter)
+	}
+
+	switch filter.Type {
+	case FilterTypeAnd, FilterTypeOr:
+		if len(filter.Conditions) == 0 {
+			return nil, fmt.Errorf("%s filter requires at least one condition", filter.Type)
+		}
+		children := make([]matchFunc, len(filter.Conditions))
+		for i := range filter.Conditions {
+			child, err := compileFilter(&filter.Conditions[i])
+			if err != nil {
+				return nil, err
+			}
+			children[i] = child
+		}
+		if filter.Type == FilterTypeAnd {
+			return func(event map[string]interface{}) bool {
+				for _, child := range children {
+					if !child(event) {
+						return false
+					}
+				}
+				return true
+			}, nil
+		}
+		return func(event map[string]interface{}) bool {
+			for _, child := range children {
+				if child(event) {
+					return true
+				}
+			}
+			return false
+		}, nil
+
+	case FilterTypeNot:
+		if filter.Condition == nil {
+			return nil, fmt.Errorf("not filter requires a condition")
+		}
+		child, err := compileFilter(filter.Condition)
+		if err != nil {
+			return nil, err
+		}
+		return func(event map[string]interface{}) bool { return !child(event) }, nil
+
+	case "":
+		return nil, fmt.Errorf("filter must be either a simple or compound condition")
+	default:
+		return nil, fmt.Errorf("unsupported filter type: %s", filter.Type)
+	}
+}
+
+func compileCondition(filter *FilterExpr) (matchFunc, error) {
+	field, value := filter.Field, filter.Value
+	if value == nil && filter.Operator != OpExists {
+		return nil, fmt.Errorf("value cannot be nil for operator %s", filter.Operator)
+	}
+
+	// anyValue applies a test to the field's value, or to each element of an array
+	anyValue := func(test func(v interface{}) bool) matchFunc {
+		return func(event map[string]interface{}) bool {
+			v, ok := lookupField(event, field)
+			if !ok {
+				return false
+			}
+			if values, isArray := v.([]interface{}); isArray {
+				for _, elem := range values {
+					if test(elem) {
+						return true
+					}
+				}
+				return false
+			}
+			return test(v)
+		}
+	}
+	stringTest := func(test func(s, want string) bool) matchFunc {
+		want := fmt.Sprint(value)
+		return anyValue(func(v interface{}) bool {
+			s, ok := v.(string)
+			return ok && test(s, want)
+		})
+	}
+
+	switch filter.Operator {
+	case OpEq:
+		return anyValue(func(v interface{}) bool { return valuesEqual(v, value) }), nil
+	case OpNe:
+		eq := anyValue(func(v interface{}) bool { return valuesEqual(v, value) })
+		return func(event map[string]interface{}) bool { return !eq(event) }, nil
+	case OpGt:
+		return anyValue(func(v interface{}) bool { c, ok := compareValues(v, value); return ok && c > 0 }), nil
+	case OpGte:
+		return anyValue(func(v interface{}) bool { c, ok := compareValues(v, value); return ok && c >= 0 }), nil
+	case OpLt:
+		return anyValue(func(v interface{}) bool { c, ok := compareValues(v, value); return ok && c < 0 }), nil
+	case OpLte:
+		return anyValue(func(v interface{}) bool { c, ok := compareValues(v, value); return ok && c <= 0 }), nil
+	case OpIn:
+		candidates, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("value for 'in' operator must be an array")
+		}
+		return anyValue(func(v interface{}) bool {
+			for _, candidate := range candidates {
+				if valuesEqual(v, candidate) {
+					return true
+				}
+			}
+			return false
+		}), nil
+	case OpContains:
+		return stringTest(strings.Contains), nil
+	case OpStartsWith:
+		return stringTest(strings.HasPrefix), nil
+	case OpEndsWith:
+		return stringTest(strings.HasSuffix), nil
+	case OpRegex:
+		pattern, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("regex pattern must be a string")
+		}
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex pattern: %w", err)
+		}
+		return anyValue(func(v interface{}) bool {
+			s, ok := v.(string)
+			return ok && re.MatchString(s)
+		}), nil
+	case OpExists:
+		want, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("value for 'exists' operator must be a boolean")
+		}
+		return func(event map[string]interface{}) bool {
+			v, found := lookupField(event, field)
+			return (found && v != nil) == want
+		}, nil
+	case OpCIDR:
+		cidr, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("CIDR value must be a string")
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR notation: %w", err)
+		}
+		return anyValue(func(v interface{}) bool {
+			s, ok := v.(string)
+			if !ok {
+				return false
+			}
+			ip := net.ParseIP(s)
+			return ip != nil && network.Contains(ip)
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", filter.Operator)
+	}
+}
+
+// lookupField resolves an OCSF field path (".actor.user.name") in an event.
+func lookupField(event map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = event
+	for _, part := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func valuesEqual(a, b interface{}) bool {
+	if af, ok := toFloat64(a); ok {
+		bf, ok := toFloat64(b)
+		return ok && af == bf
+	}
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	}
+	return false
+}
+
+// compareValues orders numbers numerically and strings lexically (which also
+// orders RFC 3339 timestamps). ok is false for values that cannot be ordered.
+func compareValues(a, b interface{}) (int, bool) {
+	if af, ok := toFloat64(a); ok {
+		bf, ok := toFloat64(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case af < bf:
+			return -1, true
+		case af > bf:
+			return 1, true
+		}
+		return 0, true
+	}
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if !aok || !bok {
+		return 0, false
+	}
+	return strings.Compare(as, bs), true
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var matchTestEvent = map[string]interface{}{
+	"class_uid": float64(3002),
+	"status_id": float64(2),
+	"actor": map[string]interface{}{
+		"user": map[string]interface{}{"name": "alice"},
+	},
+	"src_endpoint": map[string]interface{}{"ip": "10.1.2.3"},
+	"observables":  []interface{}{"evil.example.com", "10.1.2.3"},
+	"message":      "Failed password for alice",
+}
+
+func compileTestFilter(t *testing.T, data string) *Matcher {
+	t.Helper()
+	var filter FilterExpr
+	require.NoError(t, json.Unmarshal([]byte(data), &filter))
+	matcher, err := CompileFilter(&filter)
+	require.NoError(t, err)
+	return matcher
+}
+
+func TestMatcher_Conditions(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{`{"field": ".class_uid", "operator": "eq", "value": 3002}`, true},
+		{`{"field": ".class_uid", "operator": "eq", "value": "3002"}`, false},
+		{`{"field": ".actor.user.name", "operator": "eq", "value": "alice"}`, true},
+		{`{"field": ".actor.user.name", "operator": "ne", "value": "alice"}`, false},
+		{`{"field": ".missing", "operator": "ne", "value": "alice"}`, true},
+		{`{"field": ".status_id", "operator": "gt", "value": 1}`, true},
+		{`{"field": ".status_id", "operator": "lte", "value": 1}`, false},
+		{`{"field": ".class_uid", "operator": "in", "value": [3001, 3002]}`, true},
+		{`{"field": ".message", "operator": "contains", "value": "password"}`, true},
+		{`{"field": ".message", "operator": "contains", "value": "PASSWORD"}`, false},
+		{`{"field": ".message", "operator": "startsWith", "value": "Failed"}`, true},
+		{`{"field": ".message", "operator": "endsWith", "value": "bob"}`, false},
+		{`{"field": ".actor.user.name", "operator": "regex", "value": "al.*"}`, true},
+		{`{"field": ".actor.user.name", "operator": "regex", "value": "al"}`, false},
+		{`{"field": ".actor.user.name", "operator": "exists", "value": true}`, true},
+		{`{"field": ".actor.user.uid", "operator": "exists", "value": false}`, true},
+		{`{"field": ".src_endpoint.ip", "operator": "cidr", "value": "10.0.0.0/8"}`, true},
+		{`{"field": ".src_endpoint.ip", "operator": "cidr", "value": "192.168.0.0/16"}`, false},
+		{`{"field": ".observables", "operator": "eq", "value": "evil.example.com"}`, true},
+		{`{"field": ".actor", "operator": "eq", "value": "alice"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			assert.Equal(t, tt.want, compileTestFilter(t, tt.filter).Match(matchTestEvent))
+		})
+	}
+}
+
+func TestMatcher_Compound(t *testing.T) {
+	matcher := compileTestFilter(t, `{"type": "and", "conditions": [
+		{"field": ".class_uid", "operator": "eq", "value": 3002},
+		{"type": "or", "conditions": [
+			{"field": ".actor.user.name", "operator": "eq", "value": "bob"},
+			{"type": "not", "condition": {"field": ".status_id", "operator": "eq", "value": 1}}
+		]}
+	]}`)
+	assert.True(t, matcher.Match(matchTestEvent))
+	assert.False(t, matcher.Match(map[string]interface{}{"class_uid": float64(3002), "status_id": float64(1)}))
+}
+
+func TestCompileFilter_Nil(t *testing.T) {
+	matcher, err := CompileFilter(nil)
+	require.NoError(t, err)
+	assert.True(t, matcher.Match(matchTestEvent))
+}
+
+func TestCompileFilter_Invalid(t *testing.T) {
+	for _, data := range []string{
+		`{"field": ".a", "operator": "like", "value": "x"}`,
+		`{"field": ".a", "operator": "eq"}`,
+		`{"field": ".a", "operator": "in", "value": "x"}`,
+		`{"field": ".a", "operator": "regex", "value": "("}`,
+		`{"field": ".a", "operator": "cidr", "value": "10.0.0.0"}`,
+		`{"field": ".a", "operator": "exists", "value": "yes"}`,
+		`{"type": "and", "conditions": []}`,
+		`{"type": "not"}`,
+		`{"type": "xor", "conditions": [{"field": ".a", "operator": "eq", "value": 1}]}`,
+		`{}`,
+	} {
+		var filter FilterExpr
+		require.NoError(t, json.Unmarshal([]byte(data), &filter))
+		_, err := CompileFilter(&filter)
+		assert.Error(t, err, data)
+	}
+}
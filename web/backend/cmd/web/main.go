@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/telhawk-systems/telhawk-stack/common/config"
 	"github.com/telhawk-systems/telhawk-stack/common/hecstats"
+	"github.com/telhawk-systems/telhawk-stack/common/instrumentation"
 	"github.com/telhawk-systems/telhawk-stack/common/messaging"
 	"github.com/telhawk-systems/telhawk-stack/common/messaging/nats"
 	"github.com/telhawk-systems/telhawk-stack/common/middleware"
@@ -17,6 +19,7 @@ import (
 	webmiddleware "github.com/telhawk-systems/telhawk-stack/web/backend/internal/middleware"
 	webnats "github.com/telhawk-systems/telhawk-stack/web/backend/internal/nats"
 	"github.com/telhawk-systems/telhawk-stack/web/backend/internal/proxy"
+	"github.com/telhawk-systems/telhawk-stack/web/backend/internal/queryresults"
 	"github.com/telhawk-systems/telhawk-stack/web/backend/internal/server"
 )
 
@@ -70,6 +73,18 @@ func main() {
 	// Load web-specific config from environment (until WebConfig is fully implemented)
 	cfg := loadConfig()
 
+	shutdownTracing, err := instrumentation.Setup(context.Background(), "web", globalCfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Error flushing traces: %v", err)
+		}
+	}()
+
 	authClient := auth.NewClient(cfg.AuthenticateServiceURL)
 	authMiddleware := auth.NewMiddleware(authClient, cfg.CookieDomain, cfg.CookieSecure)
 
@@ -104,6 +119,7 @@ func main() {
 	var natsClient messaging.Client
 	var asyncQueryHandler *handlers.AsyncQueryHandler
 	var resultSubscriber *webnats.ResultSubscriber
+	var liveTailHandler *handlers.LiveTailHandler
 
 	natsURL := globalCfg.NATS.URL
 	if natsURL == "" {
@@ -121,10 +137,13 @@ func main() {
 			log.Printf("Async query support will be disabled")
 		} else {
 			log.Printf("Connected to NATS at %s", natsURL)
-			asyncQueryHandler = handlers.NewAsyncQueryHandler(natsClient, messaging.SubjectSearchJobsQuery)
+			resultStore, sharedStore := newQueryResultStore(redisURL, globalCfg.Redis.Enabled)
+			asyncQueryHandler = handlers.NewAsyncQueryHandler(natsClient, messaging.SubjectSearchJobsQuery, resultStore)
+
+			liveTailHandler = handlers.NewLiveTailHandler(natsClient)
 
 			// Start result subscriber to receive search results
-			resultSubscriber = webnats.NewResultSubscriber(natsClient, asyncQueryHandler)
+			resultSubscriber = webnats.NewResultSubscriber(natsClient, asyncQueryHandler, sharedStore)
 			if err := resultSubscriber.Start(); err != nil {
 				log.Printf("Warning: Failed to start result subscriber: %v", err)
 			} else {
@@ -138,6 +157,7 @@ func main() {
 		DashboardHandler:  dashboardHandler,
 		AsyncQueryHandler: asyncQueryHandler,
 		HECStatsHandler:   hecStatsHandler,
+		LiveTailHandler:   liveTailHandler,
 		AuthMiddleware:    authMiddleware,
 		AuthenticateProxy: authenticateProxy,
 		SearchProxy:       searchProxy,
@@ -216,3 +236,20 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// newQueryResultStore returns the store for async query results and whether
+// it is shared by all web replicas. Redis lets any web replica serve any
+// query; without it results are kept in process and only the replica that
+// created a query can serve it.
+func newQueryResultStore(redisURL string, redisEnabled bool) (queryresults.Store, bool) {
+	if redisURL != "" && redisEnabled {
+		store, err := queryresults.NewRedisStore(redisURL, queryresults.DefaultResultTTL, queryresults.DefaultHistorySize)
+		if err == nil {
+			log.Printf("Storing async query results in Redis")
+			return store, true
+		}
+		log.Printf("Warning: Failed to connect to Redis for async query results: %v", err)
+	}
+	log.Printf("Storing async query results in memory (single replica only)")
+	return queryresults.NewMemoryStore(queryresults.DefaultResultTTL, queryresults.DefaultHistorySize), false
+}
@@ -27,9 +27,10 @@ type LoginResponse struct {
 }
 
 type ValidateResponse struct {
-	Valid  bool     `json:"valid"`
-	UserID string   `json:"user_id"`
-	Roles  []string `json:"roles"`
+	Valid    bool     `json:"valid"`
+	UserID   string   `json:"user_id"`
+	Roles    []string `json:"roles"`
+	ClientID string   `json:"client_id,omitempty"` // Primary client for data isolation
 }
 
 type RefreshRequest struct {
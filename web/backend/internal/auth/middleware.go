@@ -9,8 +9,9 @@ import (
 type contextKey string
 
 const (
-	UserIDKey contextKey = "user_id"
-	RolesKey  contextKey = "roles"
+	UserIDKey   contextKey = "user_id"
+	RolesKey    contextKey = "roles"
+	ClientIDKey contextKey = "client_id"
 )
 
 type Middleware struct {
@@ -86,6 +87,7 @@ func (m *Middleware) Protect(next http.Handler) http.Handler {
 
 		ctx := context.WithValue(r.Context(), UserIDKey, validateResp.UserID)
 		ctx = context.WithValue(ctx, RolesKey, validateResp.Roles)
+		ctx = context.WithValue(ctx, ClientIDKey, validateResp.ClientID)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -147,6 +149,15 @@ func GetUserID(ctx context.Context) string {
 	return ""
 }
 
+// GetClientID returns the client the user's data is isolated to, or "" for
+// users not scoped to a single client.
+func GetClientID(ctx context.Context) string {
+	if clientID, ok := ctx.Value(ClientIDKey).(string); ok {
+		return clientID
+	}
+	return ""
+}
+
 func GetRoles(ctx context.Context) []string {
 	if roles, ok := ctx.Value(RolesKey).([]string); ok {
 		return roles
@@ -74,9 +74,10 @@ func TestMiddleware_Protect_ValidAccessToken(t *testing.T) {
 				return http.StatusOK, &ValidateResponse{Valid: false}
 			}
 			return http.StatusOK, &ValidateResponse{
-				Valid:  true,
-				UserID: "user-123",
-				Roles:  []string{"admin", "user"},
+				Valid:    true,
+				UserID:   "user-123",
+				Roles:    []string{"admin", "user"},
+				ClientID: "client-1",
 			}
 		},
 		nil,
@@ -98,6 +99,10 @@ func TestMiddleware_Protect_ValidAccessToken(t *testing.T) {
 			t.Errorf("Expected roles ['admin', 'user'], got %v", roles)
 		}
 
+		if clientID := GetClientID(r.Context()); clientID != "client-1" {
+			t.Errorf("Expected clientID 'client-1', got '%s'", clientID)
+		}
+
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("success"))
 	})
@@ -386,6 +391,15 @@ func TestGetUserID(t *testing.T) {
 	}
 }
 
+func TestGetClientID(t *testing.T) {
+	if clientID := GetClientID(context.WithValue(context.Background(), ClientIDKey, "client-1")); clientID != "client-1" {
+		t.Errorf("Expected 'client-1', got '%s'", clientID)
+	}
+	if clientID := GetClientID(context.Background()); clientID != "" {
+		t.Errorf("Expected '', got '%s'", clientID)
+	}
+}
+
 func TestGetRoles(t *testing.T) {
 	tests := []struct {
 		name     string
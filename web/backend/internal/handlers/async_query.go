@@ -4,46 +4,45 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
-	"sync"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/telhawk-systems/telhawk-stack/common/messaging"
+	"github.com/telhawk-systems/telhawk-stack/web/backend/internal/auth"
+	"github.com/telhawk-systems/telhawk-stack/web/backend/internal/queryresults"
 )
 
-// QueryStatus represents the status of an async query job.
-type QueryStatus string
-
 const (
-	QueryStatusPending  QueryStatus = "pending"
-	QueryStatusComplete QueryStatus = "complete"
-	QueryStatusFailed   QueryStatus = "failed"
-	queryResultCacheTTL             = 5 * time.Minute
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = queryresults.DefaultHistorySize
+	// streamKeepAlive is how often an idle result stream sends a comment so
+	// proxies do not close the connection.
+	streamKeepAlive = 15 * time.Second
 )
 
+// errJobFinished aborts a job modification when the job already reached a
+// terminal status.
+var errJobFinished = errors.New("query already finished")
+
 // AsyncQueryHandler handles async query submission and result retrieval.
+// Jobs and result pages live in a queryresults.Store so every web replica can
+// serve status, stream and history requests for any job.
 type AsyncQueryHandler struct {
 	publisher messaging.Publisher
 	subject   string
-
-	// In-memory cache for query results
-	resultsMu sync.RWMutex
-	results   map[string]*queryResult
-}
-
-type queryResult struct {
-	Status    QueryStatus     `json:"status"`
-	Data      json.RawMessage `json:"data,omitempty"`
-	Error     string          `json:"error,omitempty"`
-	CreatedAt time.Time       `json:"created_at"`
+	store     queryresults.Store
 }
 
 // SubmitQueryRequest is the request body for submitting a query.
 type SubmitQueryRequest struct {
 	Query     string `json:"query"`
-	TimeRange string `json:"time_range,omitempty"`
+	TimeRange string `json:"time_range,omitempty"` // Relative range, e.g. "15m", "24h", "7d"
 	Limit     int    `json:"limit,omitempty"`
 }
 
@@ -54,34 +53,67 @@ type SubmitQueryResponse struct {
 
 // QueryStatusResponse is the response for checking query status.
 type QueryStatusResponse struct {
-	QueryID string          `json:"query_id"`
-	Status  QueryStatus     `json:"status"`
-	Data    json.RawMessage `json:"data,omitempty"`
-	Error   string          `json:"error,omitempty"`
+	QueryID    string              `json:"query_id"`
+	Status     queryresults.Status `json:"status"`
+	Data       *QueryResultData    `json:"data,omitempty"`
+	Error      string              `json:"error,omitempty"`
+	Pages      int                 `json:"pages"`
+	TotalPages int                 `json:"total_pages"`
+}
+
+// QueryResultData holds the combined results of a completed query.
+type QueryResultData struct {
+	TotalHits int64                    `json:"total_hits"`
+	Events    []map[string]interface{} `json:"events"`
+	TookMs    int64                    `json:"took_ms"`
+}
+
+// QueryHistoryResponse lists a user's recent queries, newest first.
+type QueryHistoryResponse struct {
+	Queries []*queryresults.Job `json:"queries"`
 }
 
 // QueryJobMessage is the message published to NATS for query execution.
 // NOTE: Uses "job_id" to match the search service's expected format.
 type QueryJobMessage struct {
-	JobID     string `json:"job_id"`
-	Query     string `json:"query"`
-	TimeRange string `json:"time_range,omitempty"`
-	Limit     int    `json:"limit,omitempty"`
-	ReplyTo   string `json:"reply_to,omitempty"`
+	JobID     string          `json:"job_id"`
+	ClientID  string          `json:"client_id,omitempty"` // Scopes the search to the submitter's client
+	Query     string          `json:"query"`
+	TimeRange *QueryTimeRange `json:"time_range,omitempty"`
+	Limit     int             `json:"limit,omitempty"`
+	ReplyTo   string          `json:"reply_to,omitempty"`
+}
+
+// QueryTimeRange is the absolute time range sent to the search service.
+type QueryTimeRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// QueryCancelMessage is published to NATS to stop a running query.
+type QueryCancelMessage struct {
+	JobID string `json:"job_id"`
+}
+
+// QueryResultPage is one page of results published by the search service.
+type QueryResultPage struct {
+	QueryID    string
+	Success    bool
+	Error      string
+	TotalHits  int64
+	TookMs     int64
+	Page       int // 1-based; 0 from search services that do not paginate
+	TotalPages int
+	Events     []map[string]interface{}
 }
 
 // NewAsyncQueryHandler creates a new AsyncQueryHandler.
-func NewAsyncQueryHandler(publisher messaging.Publisher, subject string) *AsyncQueryHandler {
-	h := &AsyncQueryHandler{
+func NewAsyncQueryHandler(publisher messaging.Publisher, subject string, store queryresults.Store) *AsyncQueryHandler {
+	return &AsyncQueryHandler{
 		publisher: publisher,
 		subject:   subject,
-		results:   make(map[string]*queryResult),
+		store:     store,
 	}
-
-	// Start background cleanup goroutine
-	go h.cleanupExpiredResults()
-
-	return h
 }
 
 // SubmitQuery handles POST /api/async-query/submit
@@ -98,114 +130,411 @@ func (h *AsyncQueryHandler) SubmitQuery(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Generate query ID
-	queryID := uuid.New().String()
+	now := time.Now()
+	var timeRange *QueryTimeRange
+	if req.TimeRange != "" {
+		d, err := parseRelativeRange(req.TimeRange)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid time_range: %v", err), http.StatusBadRequest)
+			return
+		}
+		timeRange = &QueryTimeRange{From: now.Add(-d), To: now}
+	}
 
-	// Create job message
-	jobMsg := QueryJobMessage{
-		JobID:     queryID,
+	job := &queryresults.Job{
+		ID:        uuid.New().String(),
+		UserID:    auth.GetUserID(r.Context()),
 		Query:     req.Query,
 		TimeRange: req.TimeRange,
 		Limit:     req.Limit,
+		Status:    queryresults.StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// Store the job before publishing so results can never arrive for an
+	// unknown job
+	if err := h.store.Create(r.Context(), job); err != nil {
+		http.Error(w, fmt.Sprintf("failed to store query: %v", err), http.StatusInternalServerError)
+		return
 	}
 
-	// Serialize message
-	data, err := json.Marshal(jobMsg)
+	data, err := json.Marshal(QueryJobMessage{
+		JobID:     job.ID,
+		ClientID:  auth.GetClientID(r.Context()),
+		Query:     req.Query,
+		TimeRange: timeRange,
+		Limit:     req.Limit,
+	})
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to serialize query job: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Publish to NATS
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
 	if err := h.publisher.Publish(ctx, h.subject, data); err != nil {
+		h.finish(job.ID, queryresults.StatusFailed, "failed to submit query")
 		http.Error(w, fmt.Sprintf("failed to submit query: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Store pending result in cache
-	h.resultsMu.Lock()
-	h.results[queryID] = &queryResult{
-		Status:    QueryStatusPending,
-		CreatedAt: time.Now(),
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(SubmitQueryResponse{QueryID: job.ID})
+}
+
+// GetQueryStatus handles GET /api/async-query/status/{id}
+// It returns the status and, once complete, the combined results for a query.
+func (h *AsyncQueryHandler) GetQueryStatus(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.getOwnedJob(w, r)
+	if !ok {
+		return
+	}
+
+	resp := QueryStatusResponse{
+		QueryID:    job.ID,
+		Status:     job.Status,
+		Error:      job.Error,
+		Pages:      job.Pages,
+		TotalPages: job.TotalPages,
 	}
-	h.resultsMu.Unlock()
 
-	// Return query ID
-	resp := SubmitQueryResponse{
-		QueryID: queryID,
+	if job.Status == queryresults.StatusComplete {
+		pages, err := h.store.Pages(r.Context(), job.ID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to load results: %v", err), http.StatusInternalServerError)
+			return
+		}
+		resp.Data = &QueryResultData{
+			TotalHits: job.TotalHits,
+			Events:    make([]map[string]interface{}, 0),
+			TookMs:    job.TookMs,
+		}
+		for _, page := range pages {
+			resp.Data.Events = append(resp.Data.Events, page.Events...)
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(resp)
 }
 
-// GetQueryStatus handles GET /api/async-query/status/{id}
-// It returns the status and results (if available) for a query.
-func (h *AsyncQueryHandler) GetQueryStatus(w http.ResponseWriter, r *http.Request) {
-	queryID := r.PathValue("id")
-	if queryID == "" {
-		http.Error(w, "query ID is required", http.StatusBadRequest)
+// StreamQuery handles GET /api/async-query/stream/{id}
+// It streams the query as server-sent events: a "status" event whenever the
+// job changes and a "page" event for each result page. Pages stored before
+// the client connected are replayed first. The stream ends after the job
+// reaches a terminal status.
+func (h *AsyncQueryHandler) StreamQuery(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.getOwnedJob(w, r)
+	if !ok {
 		return
 	}
 
-	h.resultsMu.RLock()
-	result, exists := h.results[queryID]
-	h.resultsMu.RUnlock()
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// Subscribe before taking the snapshot so no update falls in between;
+	// pages seen in both are deduplicated by number
+	updates, err := h.store.Subscribe(ctx, job.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to subscribe to query: %v", err), http.StatusInternalServerError)
+		return
+	}
+	job, err = h.store.Get(ctx, job.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to load query: %v", err), http.StatusInternalServerError)
+		return
+	}
+	pages, err := h.store.Pages(ctx, job.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to load results: %v", err), http.StatusInternalServerError)
+		return
+	}
 
-	if !exists {
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		http.Error(w, fmt.Sprintf("failed to start stream: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sent := make(map[int]bool)
+	send := func(event string, v interface{}) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+	sendPage := func(page *queryresults.Page) bool {
+		if sent[page.Number] {
+			return true
+		}
+		sent[page.Number] = true
+		return send("page", page)
+	}
+
+	if !send("status", job) {
+		return
+	}
+	for _, page := range pages {
+		if !sendPage(page) {
+			return
+		}
+	}
+	if job.Status.Terminal() {
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			switch update.Type {
+			case queryresults.UpdatePage:
+				if update.Page != nil && !sendPage(update.Page) {
+					return
+				}
+			case queryresults.UpdateStatus:
+				if update.Job == nil {
+					continue
+				}
+				if !send("status", update.Job) || update.Job.Status.Terminal() {
+					return
+				}
+			}
+		}
+	}
+}
+
+// CancelQuery handles POST /api/async-query/cancel/{id}
+// It marks the query cancelled and asks the search service to stop it.
+// Results that arrive afterwards are discarded.
+func (h *AsyncQueryHandler) CancelQuery(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.getOwnedJob(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.store.Modify(r.Context(), job.ID, func(job *queryresults.Job) error {
+		if job.Status.Terminal() {
+			return errJobFinished
+		}
+		now := time.Now()
+		job.Status = queryresults.StatusCancelled
+		job.CompletedAt = &now
+		return nil
+	})
+	if errors.Is(err, errJobFinished) {
+		http.Error(w, "query already finished", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, queryresults.ErrNotFound) {
 		http.Error(w, "query not found", http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to cancel query: %v", err), http.StatusInternalServerError)
+		return
+	}
 
-	resp := QueryStatusResponse{
-		QueryID: queryID,
-		Status:  result.Status,
-		Data:    result.Data,
-		Error:   result.Error,
+	// Best effort: the job is already cancelled from the user's point of view
+	if data, err := json.Marshal(QueryCancelMessage{JobID: job.ID}); err == nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		if err := h.publisher.Publish(ctx, messaging.SubjectSearchJobsCancel, data); err != nil {
+			log.Printf("Failed to publish cancellation for query %s: %v", job.ID, err)
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(job)
 }
 
-// SetQueryResult updates the result for a query (called when results arrive).
-// This method is exposed for the result subscriber to call.
-func (h *AsyncQueryHandler) SetQueryResult(queryID string, status QueryStatus, data json.RawMessage, errMsg string) {
-	h.resultsMu.Lock()
-	defer h.resultsMu.Unlock()
+// GetQueryHistory handles GET /api/async-query/history
+// It returns the current user's most recent queries, newest first.
+func (h *AsyncQueryHandler) GetQueryHistory(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
 
-	if result, exists := h.results[queryID]; exists {
-		result.Status = status
-		result.Data = data
-		result.Error = errMsg
-	} else {
-		// Result arrived before or without initial submit (edge case)
-		h.results[queryID] = &queryResult{
-			Status:    status,
-			Data:      data,
-			Error:     errMsg,
-			CreatedAt: time.Now(),
+	limit := defaultHistoryLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
 		}
+		limit = min(n, maxHistoryLimit)
 	}
+
+	jobs, err := h.store.History(r.Context(), userID, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to load query history: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if jobs == nil {
+		jobs = make([]*queryresults.Job, 0)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(QueryHistoryResponse{Queries: jobs})
 }
 
-// cleanupExpiredResults periodically removes old results from the cache.
-func (h *AsyncQueryHandler) cleanupExpiredResults() {
-	ticker := time.NewTicker(time.Minute)
-	defer ticker.Stop()
+// RecordResult stores a result page from the search service and advances the
+// job's status. The job completes once all of its pages are stored, whichever
+// replicas stored them. Results for unknown, expired or finished (e.g.
+// cancelled) jobs are discarded.
+func (h *AsyncQueryHandler) RecordResult(ctx context.Context, result QueryResultPage) error {
+	existing, err := h.store.Get(ctx, result.QueryID)
+	if errors.Is(err, queryresults.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.Status.Terminal() {
+		return nil
+	}
 
-	for range ticker.C {
-		h.resultsMu.Lock()
+	if !result.Success {
+		return h.finishWith(ctx, result.QueryID, queryresults.StatusFailed, result.Error)
+	}
+
+	pageNumber, totalPages := result.Page, result.TotalPages
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	if totalPages < pageNumber {
+		totalPages = pageNumber
+	}
+
+	events := result.Events
+	if events == nil {
+		events = make([]map[string]interface{}, 0)
+	}
+	stored, err := h.store.AddPage(ctx, result.QueryID, &queryresults.Page{Number: pageNumber, Events: events})
+	if err != nil {
+		return err
+	}
+
+	_, err = h.store.Modify(ctx, result.QueryID, func(job *queryresults.Job) error {
+		if job.Status.Terminal() {
+			return errJobFinished
+		}
+		job.TotalHits = result.TotalHits
+		job.TookMs = result.TookMs
+		job.TotalPages = totalPages
+		// Replicas may store pages concurrently; never move backwards
+		job.Pages = max(job.Pages, stored)
+		job.Status = queryresults.StatusRunning
+		if job.Pages >= job.TotalPages {
+			now := time.Now()
+			job.Status = queryresults.StatusComplete
+			job.CompletedAt = &now
+		}
+		return nil
+	})
+	if errors.Is(err, errJobFinished) || errors.Is(err, queryresults.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// getOwnedJob loads the job named in the request path. Jobs owned by other
+// users are reported as not found. It writes the error response and returns
+// false on failure.
+func (h *AsyncQueryHandler) getOwnedJob(w http.ResponseWriter, r *http.Request) (*queryresults.Job, bool) {
+	queryID := r.PathValue("id")
+	if queryID == "" {
+		http.Error(w, "query ID is required", http.StatusBadRequest)
+		return nil, false
+	}
+
+	job, err := h.store.Get(r.Context(), queryID)
+	if errors.Is(err, queryresults.ErrNotFound) {
+		http.Error(w, "query not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to load query: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if job.UserID != "" && job.UserID != auth.GetUserID(r.Context()) {
+		http.Error(w, "query not found", http.StatusNotFound)
+		return nil, false
+	}
+	return job, true
+}
+
+// finish moves a job to a terminal status outside of a request context.
+func (h *AsyncQueryHandler) finish(id string, status queryresults.Status, errMsg string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.finishWith(ctx, id, status, errMsg); err != nil {
+		log.Printf("Failed to mark query %s %s: %v", id, status, err)
+	}
+}
+
+func (h *AsyncQueryHandler) finishWith(ctx context.Context, id string, status queryresults.Status, errMsg string) error {
+	_, err := h.store.Modify(ctx, id, func(job *queryresults.Job) error {
+		if job.Status.Terminal() {
+			return errJobFinished
+		}
 		now := time.Now()
-		for id, result := range h.results {
-			if now.Sub(result.CreatedAt) > queryResultCacheTTL {
-				delete(h.results, id)
-			}
+		job.Status = status
+		job.Error = errMsg
+		job.CompletedAt = &now
+		return nil
+	})
+	if errors.Is(err, errJobFinished) {
+		return nil
+	}
+	return err
+}
+
+// parseRelativeRange parses a look-back duration such as "15m", "24h" or "7d".
+func parseRelativeRange(s string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
 		}
-		h.resultsMu.Unlock()
 	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %q must be positive", s)
+	}
+	return d, nil
 }
@@ -0,0 +1,408 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/messaging"
+	"github.com/telhawk-systems/telhawk-stack/web/backend/internal/auth"
+	"github.com/telhawk-systems/telhawk-stack/web/backend/internal/queryresults"
+)
+
+type publishedMessage struct {
+	subject string
+	data    []byte
+}
+
+type fakePublisher struct {
+	mu       sync.Mutex
+	messages []publishedMessage
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, subject string, data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, publishedMessage{subject: subject, data: data})
+	return nil
+}
+
+func (p *fakePublisher) PublishMsg(ctx context.Context, msg *messaging.Message) error {
+	return p.Publish(ctx, msg.Subject, msg.Data)
+}
+
+func (p *fakePublisher) Request(ctx context.Context, subject string, data []byte, timeout time.Duration) (*messaging.Message, error) {
+	return nil, nil
+}
+
+func (p *fakePublisher) Close() error { return nil }
+
+func (p *fakePublisher) published(subject string) []publishedMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []publishedMessage
+	for _, m := range p.messages {
+		if m.subject == subject {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func newTestAsyncQueryHandler() (*AsyncQueryHandler, *fakePublisher) {
+	pub := &fakePublisher{}
+	store := queryresults.NewMemoryStore(time.Hour, 10)
+	return NewAsyncQueryHandler(pub, messaging.SubjectSearchJobsQuery, store), pub
+}
+
+func asUser(req *http.Request, userID string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, userID))
+}
+
+func submitTestQuery(t *testing.T, h *AsyncQueryHandler, userID, body string) string {
+	t.Helper()
+	req := asUser(httptest.NewRequest(http.MethodPost, "/api/async-query/submit", strings.NewReader(body)), userID)
+	w := httptest.NewRecorder()
+	h.SubmitQuery(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("submit: expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp SubmitQueryResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("submit: failed to decode response: %v", err)
+	}
+	return resp.QueryID
+}
+
+func getTestStatus(t *testing.T, h *AsyncQueryHandler, userID, queryID string) (int, QueryStatusResponse) {
+	t.Helper()
+	req := asUser(httptest.NewRequest(http.MethodGet, "/api/async-query/status/"+queryID, nil), userID)
+	req.SetPathValue("id", queryID)
+	w := httptest.NewRecorder()
+	h.GetQueryStatus(w, req)
+	var resp QueryStatusResponse
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("status: failed to decode response: %v", err)
+		}
+	}
+	return w.Code, resp
+}
+
+func resultPage(queryID string, page, totalPages int, events ...string) QueryResultPage {
+	result := QueryResultPage{
+		QueryID:    queryID,
+		Success:    true,
+		TotalHits:  3,
+		TookMs:     12,
+		Page:       page,
+		TotalPages: totalPages,
+	}
+	for _, id := range events {
+		result.Events = append(result.Events, map[string]interface{}{"id": id})
+	}
+	return result
+}
+
+func TestAsyncQueryHandler_SubmitPublishesTimeRange(t *testing.T) {
+	h, pub := newTestAsyncQueryHandler()
+	queryID := submitTestQuery(t, h, "user-1", `{"query": "*", "time_range": "7d", "limit": 10}`)
+
+	msgs := pub.published(messaging.SubjectSearchJobsQuery)
+	if len(msgs) != 1 {
+		t.Fatalf("Expected 1 published job, got %d", len(msgs))
+	}
+	var job QueryJobMessage
+	if err := json.Unmarshal(msgs[0].data, &job); err != nil {
+		t.Fatalf("Failed to decode job message: %v", err)
+	}
+	if job.JobID != queryID {
+		t.Errorf("Expected job ID %s, got %s", queryID, job.JobID)
+	}
+	if job.TimeRange == nil {
+		t.Fatal("Expected absolute time range in job message")
+	}
+	if got := job.TimeRange.To.Sub(job.TimeRange.From); got != 7*24*time.Hour {
+		t.Errorf("Expected 7d time range, got %v", got)
+	}
+
+	code, status := getTestStatus(t, h, "user-1", queryID)
+	if code != http.StatusOK || status.Status != queryresults.StatusPending {
+		t.Errorf("Expected pending query, got %d %s", code, status.Status)
+	}
+}
+
+func TestAsyncQueryHandler_SubmitScopesToClient(t *testing.T) {
+	h, pub := newTestAsyncQueryHandler()
+	req := httptest.NewRequest(http.MethodPost, "/api/async-query/submit", strings.NewReader(`{"query": "*"}`))
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, "user-1")
+	ctx = context.WithValue(ctx, auth.ClientIDKey, "client-a")
+	w := httptest.NewRecorder()
+	h.SubmitQuery(w, req.WithContext(ctx))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", w.Code)
+	}
+
+	msgs := pub.published(messaging.SubjectSearchJobsQuery)
+	if len(msgs) != 1 {
+		t.Fatalf("Expected 1 published job, got %d", len(msgs))
+	}
+	var job QueryJobMessage
+	if err := json.Unmarshal(msgs[0].data, &job); err != nil {
+		t.Fatalf("Failed to decode job message: %v", err)
+	}
+	if job.ClientID != "client-a" {
+		t.Errorf("Expected job scoped to client-a, got %q", job.ClientID)
+	}
+}
+
+func TestAsyncQueryHandler_SubmitInvalidTimeRange(t *testing.T) {
+	h, _ := newTestAsyncQueryHandler()
+	req := httptest.NewRequest(http.MethodPost, "/api/async-query/submit", strings.NewReader(`{"query": "*", "time_range": "soon"}`))
+	w := httptest.NewRecorder()
+	h.SubmitQuery(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestAsyncQueryHandler_CompletesWhenAllPagesArrive(t *testing.T) {
+	h, _ := newTestAsyncQueryHandler()
+	ctx := context.Background()
+	queryID := submitTestQuery(t, h, "user-1", `{"query": "*"}`)
+
+	// Pages may arrive out of order and more than once
+	for _, result := range []QueryResultPage{
+		resultPage(queryID, 2, 2, "c"),
+		resultPage(queryID, 2, 2, "c"),
+	} {
+		if err := h.RecordResult(ctx, result); err != nil {
+			t.Fatalf("RecordResult failed: %v", err)
+		}
+	}
+	_, status := getTestStatus(t, h, "user-1", queryID)
+	if status.Status != queryresults.StatusRunning || status.Pages != 1 || status.Data != nil {
+		t.Fatalf("Expected running query with 1 page and no data, got %+v", status)
+	}
+
+	if err := h.RecordResult(ctx, resultPage(queryID, 1, 2, "a", "b")); err != nil {
+		t.Fatalf("RecordResult failed: %v", err)
+	}
+	_, status = getTestStatus(t, h, "user-1", queryID)
+	if status.Status != queryresults.StatusComplete {
+		t.Fatalf("Expected complete query, got %s", status.Status)
+	}
+	if status.Data == nil || status.Data.TotalHits != 3 || len(status.Data.Events) != 3 {
+		t.Fatalf("Expected 3 events in results, got %+v", status.Data)
+	}
+	for i, want := range []string{"a", "b", "c"} {
+		if got := status.Data.Events[i]["id"]; got != want {
+			t.Errorf("Event %d: expected %s, got %v", i, want, got)
+		}
+	}
+}
+
+func TestAsyncQueryHandler_UnpaginatedResult(t *testing.T) {
+	h, _ := newTestAsyncQueryHandler()
+	queryID := submitTestQuery(t, h, "user-1", `{"query": "*"}`)
+
+	if err := h.RecordResult(context.Background(), resultPage(queryID, 0, 0, "a")); err != nil {
+		t.Fatalf("RecordResult failed: %v", err)
+	}
+	_, status := getTestStatus(t, h, "user-1", queryID)
+	if status.Status != queryresults.StatusComplete || len(status.Data.Events) != 1 {
+		t.Errorf("Expected complete query with 1 event, got %+v", status)
+	}
+}
+
+func TestAsyncQueryHandler_FailedResult(t *testing.T) {
+	h, _ := newTestAsyncQueryHandler()
+	queryID := submitTestQuery(t, h, "user-1", `{"query": "*"}`)
+
+	err := h.RecordResult(context.Background(), QueryResultPage{QueryID: queryID, Error: "bad query"})
+	if err != nil {
+		t.Fatalf("RecordResult failed: %v", err)
+	}
+	_, status := getTestStatus(t, h, "user-1", queryID)
+	if status.Status != queryresults.StatusFailed || status.Error != "bad query" {
+		t.Errorf("Expected failed query, got %+v", status)
+	}
+}
+
+func TestAsyncQueryHandler_StatusHidesOtherUsersQueries(t *testing.T) {
+	h, _ := newTestAsyncQueryHandler()
+	queryID := submitTestQuery(t, h, "user-1", `{"query": "*"}`)
+
+	if code, _ := getTestStatus(t, h, "user-2", queryID); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another user's query, got %d", code)
+	}
+	if code, _ := getTestStatus(t, h, "user-1", "missing"); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown query, got %d", code)
+	}
+}
+
+func TestAsyncQueryHandler_Cancel(t *testing.T) {
+	h, pub := newTestAsyncQueryHandler()
+	queryID := submitTestQuery(t, h, "user-1", `{"query": "*"}`)
+
+	cancel := func() int {
+		req := asUser(httptest.NewRequest(http.MethodPost, "/api/async-query/cancel/"+queryID, nil), "user-1")
+		req.SetPathValue("id", queryID)
+		w := httptest.NewRecorder()
+		h.CancelQuery(w, req)
+		return w.Code
+	}
+
+	if code := cancel(); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	msgs := pub.published(messaging.SubjectSearchJobsCancel)
+	if len(msgs) != 1 || !strings.Contains(string(msgs[0].data), queryID) {
+		t.Fatalf("Expected cancellation published for %s, got %v", queryID, msgs)
+	}
+
+	// Late results are discarded
+	if err := h.RecordResult(context.Background(), resultPage(queryID, 1, 1, "a")); err != nil {
+		t.Fatalf("RecordResult failed: %v", err)
+	}
+	_, status := getTestStatus(t, h, "user-1", queryID)
+	if status.Status != queryresults.StatusCancelled || status.Pages != 0 {
+		t.Errorf("Expected cancelled query without pages, got %+v", status)
+	}
+
+	if code := cancel(); code != http.StatusConflict {
+		t.Errorf("Expected status 409 cancelling a finished query, got %d", code)
+	}
+}
+
+func TestAsyncQueryHandler_History(t *testing.T) {
+	h, _ := newTestAsyncQueryHandler()
+	first := submitTestQuery(t, h, "user-1", `{"query": "first"}`)
+	second := submitTestQuery(t, h, "user-1", `{"query": "second"}`)
+	submitTestQuery(t, h, "user-2", `{"query": "other"}`)
+
+	req := asUser(httptest.NewRequest(http.MethodGet, "/api/async-query/history?limit=5", nil), "user-1")
+	w := httptest.NewRecorder()
+	h.GetQueryHistory(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var resp QueryHistoryResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Queries) != 2 {
+		t.Fatalf("Expected 2 queries, got %d", len(resp.Queries))
+	}
+	if resp.Queries[0].ID != second || resp.Queries[1].ID != first {
+		t.Errorf("Expected newest first, got %s, %s", resp.Queries[0].ID, resp.Queries[1].ID)
+	}
+
+	req = asUser(httptest.NewRequest(http.MethodGet, "/api/async-query/history?limit=0", nil), "user-1")
+	w = httptest.NewRecorder()
+	h.GetQueryHistory(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid limit, got %d", w.Code)
+	}
+}
+
+func TestAsyncQueryHandler_Stream(t *testing.T) {
+	h, _ := newTestAsyncQueryHandler()
+	ctx := context.Background()
+	queryID := submitTestQuery(t, h, "user-1", `{"query": "*"}`)
+
+	// One page is stored before the client connects
+	if err := h.RecordResult(ctx, resultPage(queryID, 1, 2, "a")); err != nil {
+		t.Fatalf("RecordResult failed: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/async-query/stream/{id}", func(w http.ResponseWriter, r *http.Request) {
+		h.StreamQuery(w, asUser(r, "user-1"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/async-query/stream/" + queryID)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %s", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() (string, string) {
+		t.Helper()
+		var event, data string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("Failed to read stream: %v", err)
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case line == "" && event != "":
+				return event, data
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}
+
+	if event, data := readEvent(); event != "status" || !strings.Contains(data, `"status":"running"`) {
+		t.Fatalf("Expected running status event, got %s %s", event, data)
+	}
+	if event, data := readEvent(); event != "page" || !strings.Contains(data, `"page":1`) {
+		t.Fatalf("Expected replayed page 1, got %s %s", event, data)
+	}
+
+	if err := h.RecordResult(ctx, resultPage(queryID, 2, 2, "b")); err != nil {
+		t.Fatalf("RecordResult failed: %v", err)
+	}
+
+	if event, data := readEvent(); event != "page" || !strings.Contains(data, `"page":2`) {
+		t.Fatalf("Expected live page 2, got %s %s", event, data)
+	}
+	if event, data := readEvent(); event != "status" || !strings.Contains(data, `"status":"complete"`) {
+		t.Fatalf("Expected complete status event, got %s %s", event, data)
+	}
+	if _, err := reader.ReadString('\n'); err == nil {
+		t.Error("Expected stream to end after the query completed")
+	}
+}
+
+func TestParseRelativeRange(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"15m", 15 * time.Minute, false},
+		{"24h", 24 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"-1h", 0, true},
+		{"xd", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseRelativeRange(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRelativeRange(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseRelativeRange(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
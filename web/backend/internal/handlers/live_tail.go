@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/messaging"
+	"github.com/telhawk-systems/telhawk-stack/search/pkg/model"
+	"github.com/telhawk-systems/telhawk-stack/web/backend/internal/auth"
+)
+
+const (
+	// DefaultTailRate is the events per second streamed when the client does
+	// not ask for a rate.
+	DefaultTailRate = 100
+	// MaxTailRate caps the events per second a client may request.
+	MaxTailRate = 1000
+	// MaxTailStreamsPerUser limits concurrent live tail streams per user.
+	MaxTailStreamsPerUser = 5
+
+	// tailBuffer is how many matched events may wait for a slow client
+	// before further events are dropped.
+	tailBuffer = 1000
+	// tailStatsInterval is how often dropped-event counts are reported.
+	tailStatsInterval = time.Second
+)
+
+// LiveTailHandler streams events as ingest stores them. Events arrive on the
+// tail subjects ingest publishes to NATS, are scoped to the user's client,
+// filtered server-side, rate limited per stream and dropped (and counted)
+// when the client cannot keep up.
+type LiveTailHandler struct {
+	subscriber messaging.Subscriber
+
+	mu      sync.Mutex
+	streams map[string]int // user ID -> open streams
+}
+
+// TailBatch is the message ingest publishes on a tail subject.
+type TailBatch struct {
+	ClientID string                   `json:"client_id,omitempty"`
+	Events   []map[string]interface{} `json:"events"`
+}
+
+// TailReady is sent when a live tail stream starts.
+type TailReady struct {
+	Rate  int `json:"rate"`
+	Limit int `json:"limit,omitempty"`
+}
+
+// TailDropped reports events that matched but were not delivered since the
+// previous report.
+type TailDropped struct {
+	RateLimited  int64 `json:"rate_limited"` // Over the stream's events-per-second rate
+	Backpressure int64 `json:"backpressure"` // The client was not reading fast enough
+}
+
+// NewLiveTailHandler creates a new LiveTailHandler.
+func NewLiveTailHandler(subscriber messaging.Subscriber) *LiveTailHandler {
+	return &LiveTailHandler{
+		subscriber: subscriber,
+		streams:    make(map[string]int),
+	}
+}
+
+// Stream handles GET /api/live-tail/stream
+// Query parameters:
+//   - query: canonical JSON query (model.Query). filter selects events,
+//     select projects fields and limit ends the stream after that many events.
+//   - rate: maximum events per second (default DefaultTailRate, max MaxTailRate)
+//
+// The response is a server-sent event stream of "ready", "event" and
+// "dropped" events.
+func (h *LiveTailHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserID(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query, matcher, err := parseTailQuery(r.URL.Query().Get("query"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid query: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	rate := DefaultTailRate
+	if raw := r.URL.Query().Get("rate"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			http.Error(w, "rate must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		rate = min(n, MaxTailRate)
+	}
+
+	if !h.acquire(userID) {
+		http.Error(w, fmt.Sprintf("too many live tail streams (max %d)", MaxTailStreamsPerUser), http.StatusTooManyRequests)
+		return
+	}
+	defer h.release(userID)
+
+	// Scope to the user's client. Users without a client see every tenant,
+	// matching how search applies data isolation.
+	subject := messaging.SubjectIngestEventsTail + ".>"
+	clientID := auth.GetClientID(r.Context())
+	if clientID != "" {
+		if subject, err = messaging.IngestTailSubject(clientID); err != nil {
+			http.Error(w, "live tail is not available for this client", http.StatusForbidden)
+			return
+		}
+	}
+
+	events := make(chan map[string]interface{}, tailBuffer)
+	var rateLimited, backpressure atomic.Int64
+	limiter := newTokenBucket(rate)
+
+	// NATS delivers a subscription's messages one at a time, so the limiter
+	// is only used from one goroutine
+	sub, err := h.subscriber.Subscribe(subject, func(ctx context.Context, msg *messaging.Message) error {
+		var batch TailBatch
+		if err := json.Unmarshal(msg.Data, &batch); err != nil {
+			return err
+		}
+		// The subject already scopes the stream; a batch for another
+		// client is never shown, whatever subject it arrived on.
+		if clientID != "" && batch.ClientID != clientID {
+			return nil
+		}
+		for _, event := range batch.Events {
+			if !matcher.Match(event) {
+				continue
+			}
+			if !limiter.allow(time.Now()) {
+				rateLimited.Add(1)
+				continue
+			}
+			select {
+			case events <- event:
+			default:
+				backpressure.Add(1)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to start live tail: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+	defer func() {
+		if err := sub.Unsubscribe(); err != nil {
+			log.Printf("Failed to unsubscribe live tail: %v", err)
+		}
+	}()
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		http.Error(w, fmt.Sprintf("failed to start stream: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, v interface{}) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if !send("ready", TailReady{Rate: rate, Limit: query.Limit}) {
+		return
+	}
+
+	stats := time.NewTicker(tailStatsInterval)
+	defer stats.Stop()
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	sent := 0
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			if !send("event", project(event, query.Select)) {
+				return
+			}
+			sent++
+			if query.Limit > 0 && sent >= query.Limit {
+				return
+			}
+		case <-stats.C:
+			dropped := TailDropped{RateLimited: rateLimited.Swap(0), Backpressure: backpressure.Swap(0)}
+			if dropped.RateLimited > 0 || dropped.Backpressure > 0 {
+				if !send("dropped", dropped) {
+					return
+				}
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *LiveTailHandler) acquire(userID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.streams[userID] >= MaxTailStreamsPerUser {
+		return false
+	}
+	h.streams[userID]++
+	return true
+}
+
+func (h *LiveTailHandler) release(userID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.streams[userID]--; h.streams[userID] <= 0 {
+		delete(h.streams, userID)
+	}
+}
+
+// parseTailQuery decodes and compiles a live tail query. Only filter, select
+// and limit apply to a stream of individual events.
+func parseTailQuery(raw string) (*model.Query, *model.Matcher, error) {
+	query := &model.Query{}
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), query); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(query.Aggregations) > 0 {
+		return nil, nil, fmt.Errorf("aggregations are not supported by live tail")
+	}
+	if query.Limit < 0 {
+		return nil, nil, fmt.Errorf("limit must not be negative")
+	}
+	matcher, err := model.CompileFilter(query.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return query, matcher, nil
+}
+
+// project returns only the selected OCSF field paths of an event, keeping
+// their nesting. An empty selection returns the whole event.
+func project(event map[string]interface{}, fields []string) map[string]interface{} {
+	if len(fields) == 0 {
+		return event
+	}
+	out := make(map[string]interface{})
+	for _, field := range fields {
+		parts := strings.Split(strings.TrimPrefix(field, "."), ".")
+		var value interface{} = event
+		found := true
+		for _, part := range parts {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				found = false
+				break
+			}
+			if value, ok = m[part]; !ok {
+				found = false
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		dst := out
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := dst[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				dst[part] = next
+			}
+			dst = next
+		}
+		dst[parts[len(parts)-1]] = value
+	}
+	return out
+}
+
+// tokenBucket allows up to rate events per second with bursts of one second.
+type tokenBucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate int) *tokenBucket {
+	return &tokenBucket{rate: float64(rate), tokens: float64(rate), last: time.Now()}
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.tokens = min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/telhawk-systems/telhawk-stack/common/messaging"
+	"github.com/telhawk-systems/telhawk-stack/web/backend/internal/auth"
+)
+
+type fakeSubscription struct {
+	subject string
+	sub     *fakeSubscriber
+}
+
+func (s *fakeSubscription) Unsubscribe() error {
+	s.sub.mu.Lock()
+	defer s.sub.mu.Unlock()
+	delete(s.sub.handlers, s.subject)
+	return nil
+}
+
+func (s *fakeSubscription) Subject() string { return s.subject }
+func (s *fakeSubscription) IsValid() bool   { return true }
+
+// fakeSubscriber records subscriptions and delivers messages sent to an
+// exact subscribed subject.
+type fakeSubscriber struct {
+	mu         sync.Mutex
+	handlers   map[string]messaging.MessageHandler
+	subscribed chan string
+}
+
+func newFakeSubscriber() *fakeSubscriber {
+	return &fakeSubscriber{
+		handlers:   make(map[string]messaging.MessageHandler),
+		subscribed: make(chan string, 10),
+	}
+}
+
+func (s *fakeSubscriber) Subscribe(subject string, handler messaging.MessageHandler) (messaging.Subscription, error) {
+	s.mu.Lock()
+	s.handlers[subject] = handler
+	s.mu.Unlock()
+	s.subscribed <- subject
+	return &fakeSubscription{subject: subject, sub: s}, nil
+}
+
+func (s *fakeSubscriber) QueueSubscribe(subject, queue string, handler messaging.MessageHandler) (messaging.Subscription, error) {
+	return s.Subscribe(subject, handler)
+}
+
+func (s *fakeSubscriber) Close() error { return nil }
+
+func (s *fakeSubscriber) deliver(t *testing.T, subject, clientID string, events ...map[string]interface{}) {
+	t.Helper()
+	data, err := json.Marshal(TailBatch{ClientID: clientID, Events: events})
+	if err != nil {
+		t.Fatalf("Failed to encode batch: %v", err)
+	}
+	s.mu.Lock()
+	handler := s.handlers[subject]
+	s.mu.Unlock()
+	if handler == nil {
+		t.Fatalf("No subscription on %s", subject)
+	}
+	if err := handler(context.Background(), &messaging.Message{Subject: subject, Data: data}); err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+}
+
+func (s *fakeSubscriber) subscriptions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.handlers)
+}
+
+func asClientUser(req *http.Request, userID, clientID string) *http.Request {
+	ctx := context.WithValue(req.Context(), auth.UserIDKey, userID)
+	ctx = context.WithValue(ctx, auth.ClientIDKey, clientID)
+	return req.WithContext(ctx)
+}
+
+func newLiveTailServer(h *LiveTailHandler, userID, clientID string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.Stream(w, asClientUser(r, userID, clientID))
+	}))
+}
+
+type sseReader struct {
+	t      *testing.T
+	reader *bufio.Reader
+}
+
+func (r *sseReader) next() (string, string) {
+	r.t.Helper()
+	var event, data string
+	for {
+		line, err := r.reader.ReadString('\n')
+		if err != nil {
+			r.t.Fatalf("Failed to read stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && event != "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func openLiveTail(t *testing.T, server *httptest.Server, params url.Values) (*http.Response, *sseReader) {
+	t.Helper()
+	resp, err := http.Get(server.URL + "?" + params.Encode())
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	return resp, &sseReader{t: t, reader: bufio.NewReader(resp.Body)}
+}
+
+func waitForSubscription(t *testing.T, sub *fakeSubscriber) string {
+	t.Helper()
+	select {
+	case subject := <-sub.subscribed:
+		return subject
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for subscription")
+		return ""
+	}
+}
+
+func TestLiveTailHandler_StreamsFilteredEvents(t *testing.T) {
+	sub := newFakeSubscriber()
+	server := newLiveTailServer(NewLiveTailHandler(sub), "user-1", "client-a")
+	defer server.Close()
+
+	query := `{"filter": {"field": ".class_uid", "operator": "eq", "value": 3002}, "select": [".actor.user.name"], "limit": 2}`
+	resp, stream := openLiveTail(t, server, url.Values{"query": {query}, "rate": {"50"}})
+	defer resp.Body.Close()
+
+	subject := waitForSubscription(t, sub)
+	if want, _ := messaging.IngestTailSubject("client-a"); subject != want {
+		t.Fatalf("Expected client-scoped subject, got %s", subject)
+	}
+	if event, data := stream.next(); event != "ready" || data != `{"rate":50,"limit":2}` {
+		t.Fatalf("Expected ready event, got %s %s", event, data)
+	}
+
+	sub.deliver(t, subject, "client-a",
+		map[string]interface{}{"class_uid": 3001, "actor": map[string]interface{}{"user": map[string]interface{}{"name": "skipped"}}},
+		map[string]interface{}{"class_uid": 3002, "actor": map[string]interface{}{"user": map[string]interface{}{"name": "alice"}}, "message": "x"},
+		map[string]interface{}{"class_uid": 3002, "actor": map[string]interface{}{"user": map[string]interface{}{"name": "bob"}}},
+	)
+
+	if event, data := stream.next(); event != "event" || data != `{"actor":{"user":{"name":"alice"}}}` {
+		t.Fatalf("Expected projected alice event, got %s %s", event, data)
+	}
+	if event, data := stream.next(); event != "event" || !strings.Contains(data, "bob") {
+		t.Fatalf("Expected bob event, got %s %s", event, data)
+	}
+	if _, err := stream.reader.ReadString('\n'); err == nil {
+		t.Error("Expected stream to end after the limit")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for sub.subscriptions() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected subscription to be removed when the stream ended")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLiveTailHandler_UnscopedUserSeesAllClients(t *testing.T) {
+	sub := newFakeSubscriber()
+	server := newLiveTailServer(NewLiveTailHandler(sub), "admin", "")
+	defer server.Close()
+
+	resp, _ := openLiveTail(t, server, url.Values{})
+	defer resp.Body.Close()
+
+	if subject := waitForSubscription(t, sub); subject != messaging.SubjectIngestEventsTail+".>" {
+		t.Fatalf("Expected wildcard subject, got %s", subject)
+	}
+}
+
+func TestLiveTailHandler_DropsOtherClientsBatches(t *testing.T) {
+	sub := newFakeSubscriber()
+	server := newLiveTailServer(NewLiveTailHandler(sub), "user-1", "client-a")
+	defer server.Close()
+
+	resp, stream := openLiveTail(t, server, url.Values{})
+	defer resp.Body.Close()
+
+	subject := waitForSubscription(t, sub)
+	stream.next() // ready
+
+	sub.deliver(t, subject, "client-b", map[string]interface{}{"owner": "client-b"})
+	sub.deliver(t, subject, "client-a", map[string]interface{}{"owner": "client-a"})
+
+	if event, data := stream.next(); event != "event" || data != `{"owner":"client-a"}` {
+		t.Fatalf("Expected only client-a's event, got %s %s", event, data)
+	}
+}
+
+func TestLiveTailHandler_RejectsUnsafeClientID(t *testing.T) {
+	sub := newFakeSubscriber()
+	h := NewLiveTailHandler(sub)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/live-tail/stream", nil)
+	w := httptest.NewRecorder()
+	h.Stream(w, asClientUser(req, "user-1", "acme.*"))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+	if sub.subscriptions() != 0 {
+		t.Error("Expected no subscription for a client ID that is not subject-safe")
+	}
+}
+
+func TestLiveTailHandler_ReportsRateLimitedEvents(t *testing.T) {
+	sub := newFakeSubscriber()
+	server := newLiveTailServer(NewLiveTailHandler(sub), "user-1", "client-a")
+	defer server.Close()
+
+	resp, stream := openLiveTail(t, server, url.Values{"rate": {"1"}})
+	defer resp.Body.Close()
+
+	subject := waitForSubscription(t, sub)
+	stream.next() // ready
+
+	sub.deliver(t, subject, "client-a", map[string]interface{}{"n": 1}, map[string]interface{}{"n": 2}, map[string]interface{}{"n": 3})
+
+	if event, _ := stream.next(); event != "event" {
+		t.Fatalf("Expected one event within the rate, got %s", event)
+	}
+	if event, data := stream.next(); event != "dropped" || data != `{"rate_limited":2,"backpressure":0}` {
+		t.Fatalf("Expected dropped report, got %s %s", event, data)
+	}
+}
+
+func TestLiveTailHandler_RejectsInvalidRequests(t *testing.T) {
+	h := NewLiveTailHandler(newFakeSubscriber())
+
+	tests := []struct {
+		name   string
+		params url.Values
+	}{
+		{"malformed query", url.Values{"query": {"{"}}},
+		{"aggregations", url.Values{"query": {`{"aggregations": [{"type": "count", "name": "n"}]}`}}},
+		{"invalid filter", url.Values{"query": {`{"filter": {"field": ".a", "operator": "like", "value": "x"}}`}}},
+		{"invalid rate", url.Values{"rate": {"0"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/live-tail/stream?"+tt.params.Encode(), nil)
+			w := httptest.NewRecorder()
+			h.Stream(w, asClientUser(req, "user-1", "client-a"))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestLiveTailHandler_LimitsStreamsPerUser(t *testing.T) {
+	h := NewLiveTailHandler(newFakeSubscriber())
+	for i := 0; i < MaxTailStreamsPerUser; i++ {
+		if !h.acquire("user-1") {
+			t.Fatalf("Expected stream %d to be allowed", i+1)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/live-tail/stream", nil)
+	w := httptest.NewRecorder()
+	h.Stream(w, asClientUser(req, "user-1", "client-a"))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", w.Code)
+	}
+
+	h.release("user-1")
+	if !h.acquire("user-1") {
+		t.Error("Expected a stream to be allowed after one closed")
+	}
+	if !h.acquire("user-2") {
+		t.Error("Expected other users to be unaffected")
+	}
+}
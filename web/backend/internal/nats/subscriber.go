@@ -12,39 +12,60 @@ import (
 
 // SearchResultMessage is the message format received from the search service.
 type SearchResultMessage struct {
-	JobID     string                   `json:"job_id"`
-	Success   bool                     `json:"success"`
-	Error     string                   `json:"error,omitempty"`
-	TotalHits int64                    `json:"total_hits"`
-	Events    []map[string]interface{} `json:"events"`
-	TookMs    int64                    `json:"took_ms"`
+	JobID      string                   `json:"job_id"`
+	Success    bool                     `json:"success"`
+	Error      string                   `json:"error,omitempty"`
+	TotalHits  int64                    `json:"total_hits"`
+	Events     []map[string]interface{} `json:"events"`
+	TookMs     int64                    `json:"took_ms"`
+	Page       int                      `json:"page,omitempty"`
+	TotalPages int                      `json:"total_pages,omitempty"`
 }
 
 // ResultSubscriber subscribes to search results and updates the async query handler.
 type ResultSubscriber struct {
 	client       messaging.Client
 	queryHandler *handlers.AsyncQueryHandler
+	sharedStore  bool
 	sub          messaging.Subscription
 }
 
-// NewResultSubscriber creates a new result subscriber.
-func NewResultSubscriber(client messaging.Client, queryHandler *handlers.AsyncQueryHandler) *ResultSubscriber {
+// NewResultSubscriber creates a new result subscriber. sharedStore reports
+// whether the query handler's result store is shared by all web replicas.
+func NewResultSubscriber(client messaging.Client, queryHandler *handlers.AsyncQueryHandler, sharedStore bool) *ResultSubscriber {
 	return &ResultSubscriber{
 		client:       client,
 		queryHandler: queryHandler,
+		sharedStore:  sharedStore,
 	}
 }
 
 // Start subscribes to search result subjects.
 func (s *ResultSubscriber) Start() error {
-	// Subscribe to all query results using wildcard
+	// Subscribe to all query results using wildcard.
 	// Subject pattern: search.results.query.{job_id}
-	sub, err := s.client.Subscribe("search.results.query.>", s.handleResult)
+	const subject = "search.results.query.>"
+
+	// With a shared store each page only needs handling once, so replicas
+	// split results through a queue group. With an in-process store only
+	// the replica that created a job can record its results, so every
+	// replica receives every page and ignores jobs it does not know.
+	if !s.sharedStore {
+		sub, err := s.client.Subscribe(subject, s.handleResult)
+		if err != nil {
+			return err
+		}
+		s.sub = sub
+		log.Printf("Subscribed to %s", subject)
+		return nil
+	}
+
+	sub, err := s.client.QueueSubscribe(subject, messaging.QueueWebWorkers, s.handleResult)
 	if err != nil {
 		return err
 	}
 	s.sub = sub
-	log.Printf("Subscribed to search.results.query.>")
+	log.Printf("Subscribed to %s (queue: %s)", subject, messaging.QueueWebWorkers)
 	return nil
 }
 
@@ -64,30 +85,22 @@ func (s *ResultSubscriber) handleResult(ctx context.Context, msg *messaging.Mess
 		return err
 	}
 
-	// Determine status
-	status := handlers.QueryStatusComplete
-	errMsg := ""
-	if !result.Success {
-		status = handlers.QueryStatusFailed
-		errMsg = result.Error
-	}
-
-	// Build result data for the cache
-	data, err := json.Marshal(map[string]interface{}{
-		"total_hits": result.TotalHits,
-		"events":     result.Events,
-		"took_ms":    result.TookMs,
-	})
-	if err != nil {
-		log.Printf("Failed to marshal result data: %v", err)
+	if err := s.queryHandler.RecordResult(ctx, handlers.QueryResultPage{
+		QueryID:    result.JobID,
+		Success:    result.Success,
+		Error:      result.Error,
+		TotalHits:  result.TotalHits,
+		TookMs:     result.TookMs,
+		Page:       result.Page,
+		TotalPages: result.TotalPages,
+		Events:     result.Events,
+	}); err != nil {
+		log.Printf("Failed to record result for query %s: %v", result.JobID, err)
 		return err
 	}
 
-	// Update the handler's cache
-	s.queryHandler.SetQueryResult(result.JobID, status, data, errMsg)
-
-	log.Printf("Received result for query %s (success=%v, hits=%d)",
-		result.JobID, result.Success, result.TotalHits)
+	log.Printf("Received result for query %s (success=%v, hits=%d, page=%d/%d)",
+		result.JobID, result.Success, result.TotalHits, result.Page, result.TotalPages)
 
 	return nil
 }
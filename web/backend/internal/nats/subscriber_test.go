@@ -0,0 +1,60 @@
+package nats
+
+import (
+	"testing"
+
+	"github.com/telhawk-systems/telhawk-stack/common/messaging"
+)
+
+type fakeSubscription struct {
+	subject string
+}
+
+func (s *fakeSubscription) Unsubscribe() error { return nil }
+func (s *fakeSubscription) Subject() string    { return s.subject }
+func (s *fakeSubscription) IsValid() bool      { return true }
+
+// fakeClient records the queue group of each subscription
+type fakeClient struct {
+	messaging.Client
+	queues map[string]string
+}
+
+func (c *fakeClient) Subscribe(subject string, handler messaging.MessageHandler) (messaging.Subscription, error) {
+	c.queues[subject] = ""
+	return &fakeSubscription{subject: subject}, nil
+}
+
+func (c *fakeClient) QueueSubscribe(subject, queue string, handler messaging.MessageHandler) (messaging.Subscription, error) {
+	c.queues[subject] = queue
+	return &fakeSubscription{subject: subject}, nil
+}
+
+func TestResultSubscriber_Start(t *testing.T) {
+	tests := []struct {
+		name        string
+		sharedStore bool
+		wantQueue   string
+	}{
+		{name: "shared store splits results across replicas", sharedStore: true, wantQueue: messaging.QueueWebWorkers},
+		{name: "in-process store receives every result", sharedStore: false, wantQueue: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{queues: make(map[string]string)}
+			sub := NewResultSubscriber(client, nil, tt.sharedStore)
+			if err := sub.Start(); err != nil {
+				t.Fatalf("Start: %v", err)
+			}
+
+			queue, ok := client.queues["search.results.query.>"]
+			if !ok {
+				t.Fatal("expected a subscription to search.results.query.>")
+			}
+			if queue != tt.wantQueue {
+				t.Errorf("queue = %q, want %q", queue, tt.wantQueue)
+			}
+		})
+	}
+}
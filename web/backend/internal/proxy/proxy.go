@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/telhawk-systems/telhawk-stack/common/instrumentation"
 	"github.com/telhawk-systems/telhawk-stack/web/backend/internal/auth"
 )
 
@@ -20,7 +21,8 @@ func NewProxy(targetURL string, authClient *auth.Client) *Proxy {
 		targetURL:  targetURL,
 		authClient: authClient,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: instrumentation.Transport(nil),
 		},
 	}
 }
@@ -32,7 +34,8 @@ func (p *Proxy) Handler() http.Handler {
 			targetURL += "?" + r.URL.RawQuery
 		}
 
-		proxyReq, err := http.NewRequest(r.Method, targetURL, r.Body)
+		// Keep the request context so the upstream call joins the caller's trace
+		proxyReq, err := http.NewRequestWithContext(r.Context(), r.Method, targetURL, r.Body)
 		if err != nil {
 			log.Printf("Proxy request creation error: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
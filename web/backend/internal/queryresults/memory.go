@@ -0,0 +1,192 @@
+package queryresults
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many updates a slow subscriber may fall behind
+// before updates to it are dropped.
+const subscriberBuffer = 64
+
+// MemoryStore keeps jobs in process. It is used when Redis is unavailable,
+// which limits async queries to a single web replica.
+type MemoryStore struct {
+	ttl         time.Duration
+	historySize int
+
+	mu          sync.Mutex
+	jobs        map[string]*memoryJob
+	history     map[string][]string // user ID -> job IDs, oldest first
+	subscribers map[string]map[chan Update]struct{}
+}
+
+type memoryJob struct {
+	job   Job
+	pages map[int]*Page
+}
+
+// NewMemoryStore creates an in-process store. Expired jobs are removed by a
+// background goroutine.
+func NewMemoryStore(ttl time.Duration, historySize int) *MemoryStore {
+	if ttl <= 0 {
+		ttl = DefaultResultTTL
+	}
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	s := &MemoryStore{
+		ttl:         ttl,
+		historySize: historySize,
+		jobs:        make(map[string]*memoryJob),
+		history:     make(map[string][]string),
+		subscribers: make(map[string]map[chan Update]struct{}),
+	}
+	go s.cleanupExpired()
+	return s
+}
+
+func (s *MemoryStore) Create(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[job.ID] = &memoryJob{job: *job, pages: make(map[int]*Page)}
+	if job.UserID != "" {
+		ids := append(s.history[job.UserID], job.ID)
+		if len(ids) > s.historySize {
+			ids = ids[len(ids)-s.historySize:]
+		}
+		s.history[job.UserID] = ids
+	}
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	job := stored.job
+	return &job, nil
+}
+
+func (s *MemoryStore) Modify(ctx context.Context, id string, fn func(job *Job) error) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	job := stored.job
+	if err := fn(&job); err != nil {
+		return nil, err
+	}
+	job.UpdatedAt = time.Now()
+	stored.job = job
+
+	snapshot := job
+	s.publishLocked(id, Update{Type: UpdateStatus, Job: &snapshot})
+	return &job, nil
+}
+
+func (s *MemoryStore) AddPage(ctx context.Context, id string, page *Page) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.jobs[id]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	stored.pages[page.Number] = page
+	s.publishLocked(id, Update{Type: UpdatePage, Page: page})
+	return len(stored.pages), nil
+}
+
+func (s *MemoryStore) Pages(ctx context.Context, id string) ([]*Page, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	pages := make([]*Page, 0, len(stored.pages))
+	for _, page := range stored.pages {
+		pages = append(pages, page)
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Number < pages[j].Number })
+	return pages, nil
+}
+
+func (s *MemoryStore) History(ctx context.Context, userID string, limit int) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := s.history[userID]
+	var jobs []*Job
+	for i := len(ids) - 1; i >= 0 && (limit <= 0 || len(jobs) < limit); i-- {
+		if stored, ok := s.jobs[ids[i]]; ok {
+			job := stored.job
+			jobs = append(jobs, &job)
+		}
+	}
+	return jobs, nil
+}
+
+func (s *MemoryStore) Subscribe(ctx context.Context, id string) (<-chan Update, error) {
+	ch := make(chan Update, subscriberBuffer)
+
+	s.mu.Lock()
+	if s.subscribers[id] == nil {
+		s.subscribers[id] = make(map[chan Update]struct{})
+	}
+	s.subscribers[id][ch] = struct{}{}
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		delete(s.subscribers[id], ch)
+		if len(s.subscribers[id]) == 0 {
+			delete(s.subscribers, id)
+		}
+		s.mu.Unlock()
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+// publishLocked delivers an update to a job's subscribers. s.mu must be held.
+func (s *MemoryStore) publishLocked(id string, update Update) {
+	for ch := range s.subscribers[id] {
+		select {
+		case ch <- update:
+		default:
+			// Subscriber is not keeping up; it can resync from Get and Pages
+		}
+	}
+}
+
+// cleanupExpired periodically removes jobs older than the TTL.
+func (s *MemoryStore) cleanupExpired() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.mu.Lock()
+		now := time.Now()
+		for id, stored := range s.jobs {
+			if now.Sub(stored.job.CreatedAt) > s.ttl {
+				delete(s.jobs, id)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
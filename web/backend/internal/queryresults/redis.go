@@ -0,0 +1,282 @@
+package queryresults
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis Key Structure:
+//
+//	query:job:{id}          - JSON job record (expires after the TTL)
+//	query:pages:{id}        - Hash of page number -> JSON page (expires after the TTL)
+//	query:history:{user_id} - Sorted set of job IDs scored by creation time
+//	query:updates:{id}      - Pub/sub channel carrying JSON updates
+const (
+	jobKeyPrefix     = "query:job:"
+	pagesKeyPrefix   = "query:pages:"
+	historyKeyPrefix = "query:history:"
+	updatesPrefix    = "query:updates:"
+)
+
+// RedisStore keeps jobs in Redis so every web replica sees the same results.
+type RedisStore struct {
+	redis       *redis.Client
+	ttl         time.Duration
+	historySize int
+}
+
+// NewRedisStore creates a Redis-backed store.
+func NewRedisStore(redisURL string, ttl time.Duration, historySize int) (*RedisStore, error) {
+	opt, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opt)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("redis connection failed: %w", err)
+	}
+
+	return NewRedisStoreFromClient(client, ttl, historySize), nil
+}
+
+// NewRedisStoreFromClient creates a store from an existing Redis connection.
+func NewRedisStoreFromClient(client *redis.Client, ttl time.Duration, historySize int) *RedisStore {
+	if ttl <= 0 {
+		ttl = DefaultResultTTL
+	}
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	return &RedisStore{redis: client, ttl: ttl, historySize: historySize}
+}
+
+func (s *RedisStore) Create(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	pipe := s.redis.TxPipeline()
+	pipe.Set(ctx, jobKeyPrefix+job.ID, data, s.ttl)
+	if job.UserID != "" {
+		historyKey := historyKeyPrefix + job.UserID
+		pipe.ZAdd(ctx, historyKey, redis.Z{Score: float64(job.CreatedAt.UnixNano()), Member: job.ID})
+		// Keep only the newest historySize entries
+		pipe.ZRemRangeByRank(ctx, historyKey, 0, int64(-s.historySize-1))
+		pipe.Expire(ctx, historyKey, s.ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store job: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Get(ctx context.Context, id string) (*Job, error) {
+	data, err := s.redis.Get(ctx, jobKeyPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job: %w", err)
+	}
+	return &job, nil
+}
+
+// maxModifyAttempts bounds optimistic-locking retries when several replicas
+// modify the same job at once.
+const maxModifyAttempts = 10
+
+func (s *RedisStore) Modify(ctx context.Context, id string, fn func(job *Job) error) (*Job, error) {
+	key := jobKeyPrefix + id
+
+	var updated *Job
+	modify := func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get job: %w", err)
+		}
+
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return fmt.Errorf("failed to decode job: %w", err)
+		}
+		if err := fn(&job); err != nil {
+			return err
+		}
+		job.UpdatedAt = time.Now()
+
+		encoded, err := json.Marshal(&job)
+		if err != nil {
+			return fmt.Errorf("failed to encode job: %w", err)
+		}
+		// The transaction fails if the job changed since WATCH
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.SetArgs(ctx, key, encoded, redis.SetArgs{KeepTTL: true})
+			return nil
+		})
+		if err == nil {
+			updated = &job
+		}
+		return err
+	}
+
+	for attempt := 0; attempt < maxModifyAttempts; attempt++ {
+		err := s.redis.Watch(ctx, modify, key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := s.publish(ctx, id, Update{Type: UpdateStatus, Job: updated}); err != nil {
+			return nil, err
+		}
+		return updated, nil
+	}
+	return nil, fmt.Errorf("job %s is being modified concurrently", id)
+}
+
+func (s *RedisStore) AddPage(ctx context.Context, id string, page *Page) (int, error) {
+	data, err := json.Marshal(page)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode page: %w", err)
+	}
+
+	pagesKey := pagesKeyPrefix + id
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(ctx, pagesKey, strconv.Itoa(page.Number), data)
+	pipe.Expire(ctx, pagesKey, s.ttl)
+	count := pipe.HLen(ctx, pagesKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to store page: %w", err)
+	}
+
+	if err := s.publish(ctx, id, Update{Type: UpdatePage, Page: page}); err != nil {
+		return 0, err
+	}
+	return int(count.Val()), nil
+}
+
+func (s *RedisStore) Pages(ctx context.Context, id string) ([]*Page, error) {
+	raw, err := s.redis.HGetAll(ctx, pagesKeyPrefix+id).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pages: %w", err)
+	}
+
+	pages := make([]*Page, 0, len(raw))
+	for _, data := range raw {
+		var page Page
+		if err := json.Unmarshal([]byte(data), &page); err != nil {
+			return nil, fmt.Errorf("failed to decode page: %w", err)
+		}
+		pages = append(pages, &page)
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Number < pages[j].Number })
+	return pages, nil
+}
+
+func (s *RedisStore) History(ctx context.Context, userID string, limit int) ([]*Job, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(limit - 1)
+	}
+	ids, err := s.redis.ZRevRange(ctx, historyKeyPrefix+userID, 0, stop).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = jobKeyPrefix + id
+	}
+	values, err := s.redis.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history jobs: %w", err)
+	}
+
+	jobs := make([]*Job, 0, len(values))
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // Expired
+		}
+		var job Job
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			continue
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+func (s *RedisStore) Subscribe(ctx context.Context, id string) (<-chan Update, error) {
+	pubsub := s.redis.Subscribe(ctx, updatesPrefix+id)
+	// Wait for the subscription to be confirmed so no later update is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to job updates: %w", err)
+	}
+
+	ch := make(chan Update, subscriberBuffer)
+	go func() {
+		defer close(ch)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var update Update
+				if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+					continue
+				}
+				select {
+				case ch <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// publish sends an update to a job's subscribers on any replica.
+func (s *RedisStore) publish(ctx context.Context, id string, update Update) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("failed to encode update: %w", err)
+	}
+	if err := s.redis.Publish(ctx, updatesPrefix+id, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish update: %w", err)
+	}
+	return nil
+}
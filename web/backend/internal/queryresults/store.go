@@ -0,0 +1,104 @@
+// Package queryresults stores async query jobs and their result pages in a
+// backend shared by all web replicas, and fans out job updates so whichever
+// replica a client is connected to can stream them.
+package queryresults
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Status is the lifecycle state of an async query job.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running" // At least one result page has arrived
+	StatusComplete  Status = "complete"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Terminal reports whether no further updates will follow this status.
+func (s Status) Terminal() bool {
+	return s == StatusComplete || s == StatusFailed || s == StatusCancelled
+}
+
+const (
+	// DefaultResultTTL is how long jobs and their result pages are kept.
+	DefaultResultTTL = time.Hour
+	// DefaultHistorySize is how many jobs are kept in each user's history.
+	DefaultHistorySize = 100
+)
+
+// ErrNotFound is returned when a job does not exist or has expired.
+var ErrNotFound = errors.New("query job not found")
+
+// Job is an async query and its progress.
+type Job struct {
+	ID          string     `json:"query_id"`
+	UserID      string     `json:"user_id,omitempty"`
+	Query       string     `json:"query"`
+	TimeRange   string     `json:"time_range,omitempty"`
+	Limit       int        `json:"limit,omitempty"`
+	Status      Status     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	TotalHits   int64      `json:"total_hits"`
+	TookMs      int64      `json:"took_ms"`
+	Pages       int        `json:"pages"`       // Result pages stored so far
+	TotalPages  int        `json:"total_pages"` // Result pages search will publish (0 until known)
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Page is one page of query results.
+type Page struct {
+	Number int                      `json:"page"`
+	Events []map[string]interface{} `json:"events"`
+}
+
+// UpdateType identifies what changed in an Update.
+type UpdateType string
+
+const (
+	UpdateStatus UpdateType = "status"
+	UpdatePage   UpdateType = "page"
+)
+
+// Update is a change to a job, delivered to subscribers.
+type Update struct {
+	Type UpdateType `json:"type"`
+	Job  *Job       `json:"job,omitempty"`
+	Page *Page      `json:"page,omitempty"`
+}
+
+// Store persists jobs and result pages and publishes their updates.
+type Store interface {
+	// Create stores a new job and adds it to its user's history.
+	Create(ctx context.Context, job *Job) error
+
+	// Get returns a job, or ErrNotFound.
+	Get(ctx context.Context, id string) (*Job, error)
+
+	// Modify atomically applies fn to a stored job, saves it and publishes a
+	// status update. If fn returns an error the job is left unchanged and
+	// the error is returned. Returns ErrNotFound if the job does not exist.
+	Modify(ctx context.Context, id string, fn func(job *Job) error) (*Job, error)
+
+	// AddPage stores a result page, publishes it and returns the number of
+	// distinct pages stored for the job.
+	AddPage(ctx context.Context, id string, page *Page) (int, error)
+
+	// Pages returns the stored result pages in page order.
+	Pages(ctx context.Context, id string) ([]*Page, error)
+
+	// History returns a user's most recent jobs, newest first.
+	History(ctx context.Context, userID string, limit int) ([]*Job, error)
+
+	// Subscribe delivers updates for a job until ctx is done, then closes
+	// the channel. Updates published before Subscribe returns are not
+	// delivered.
+	Subscribe(ctx context.Context, id string) (<-chan Update, error)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/telhawk-systems/telhawk-stack/common/instrumentation"
 	"github.com/telhawk-systems/telhawk-stack/common/middleware"
 
 	"github.com/telhawk-systems/telhawk-stack/web/backend/internal/auth"
@@ -17,6 +18,7 @@ type RouterConfig struct {
 	DashboardHandler  *handlers.DashboardHandler
 	AsyncQueryHandler *handlers.AsyncQueryHandler // Optional: nil if NATS unavailable
 	HECStatsHandler   *handlers.HECStatsHandler   // Optional: nil if Redis unavailable
+	LiveTailHandler   *handlers.LiveTailHandler   // Optional: nil if NATS unavailable
 	AuthMiddleware    *auth.Middleware
 	AuthenticateProxy *proxy.Proxy
 	SearchProxy       *proxy.Proxy
@@ -42,6 +44,14 @@ func NewRouter(cfg RouterConfig) http.Handler {
 	if cfg.AsyncQueryHandler != nil {
 		mux.Handle("POST /api/async-query/submit", cfg.AuthMiddleware.Protect(http.HandlerFunc(cfg.AsyncQueryHandler.SubmitQuery)))
 		mux.Handle("GET /api/async-query/status/{id}", cfg.AuthMiddleware.Protect(http.HandlerFunc(cfg.AsyncQueryHandler.GetQueryStatus)))
+		mux.Handle("GET /api/async-query/stream/{id}", cfg.AuthMiddleware.Protect(http.HandlerFunc(cfg.AsyncQueryHandler.StreamQuery)))
+		mux.Handle("POST /api/async-query/cancel/{id}", cfg.AuthMiddleware.Protect(http.HandlerFunc(cfg.AsyncQueryHandler.CancelQuery)))
+		mux.Handle("GET /api/async-query/history", cfg.AuthMiddleware.Protect(http.HandlerFunc(cfg.AsyncQueryHandler.GetQueryHistory)))
+	}
+
+	// Live tail endpoint (protected, only if NATS is available)
+	if cfg.LiveTailHandler != nil {
+		mux.Handle("GET /api/live-tail/stream", cfg.AuthMiddleware.Protect(http.HandlerFunc(cfg.LiveTailHandler.Stream)))
 	}
 
 	// HEC stats endpoints (protected, only if Redis is available)
@@ -82,9 +92,12 @@ func NewRouter(cfg RouterConfig) http.Handler {
 		fmt.Fprintf(w, `{"status":"ok","service":"web"}`)
 	})
 
+	// Prometheus metrics
+	mux.Handle("GET /metrics", instrumentation.MetricsHandler())
+
 	// Serve React static files (must be last)
 	fs := http.FileServer(http.Dir(cfg.StaticDir))
 	mux.Handle("/", handlers.NewSPAHandler(cfg.StaticDir, fs))
 
-	return middleware.RequestID(mux)
+	return middleware.RequestID(instrumentation.Middleware("web", mux))
 }
@@ -538,3 +538,39 @@ func TestNewRouter_ConfigComplete(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRouter_Metrics(t *testing.T) {
+	authServer, authClient := createMockAuthServer()
+	defer authServer.Close()
+
+	staticDir, cleanup := setupTestStaticDir(t)
+	defer cleanup()
+
+	queryServer := createMockBackendServer("query response")
+	defer queryServer.Close()
+
+	cfg := RouterConfig{
+		AuthHandler:       handlers.NewAuthHandler(authClient, "localhost", false),
+		DashboardHandler:  handlers.NewDashboardHandler(queryServer.URL, ""),
+		AuthMiddleware:    auth.NewMiddleware(authClient, "localhost", false),
+		AuthenticateProxy: proxy.NewProxy(authServer.URL, authClient),
+		SearchProxy:       proxy.NewProxy(queryServer.URL, authClient),
+		CoreProxy:         proxy.NewProxy(queryServer.URL, authClient),
+		RespondProxy:      proxy.NewProxy(queryServer.URL, authClient),
+		StaticDir:         staticDir,
+	}
+
+	router := NewRouter(cfg)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/health", nil))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rr.Code)
+	}
+	expected := `telhawk_http_requests_total{code="200",method="GET",route="/api/health",service="web"}`
+	if !strings.Contains(rr.Body.String(), expected) {
+		t.Errorf("Expected /metrics to contain %s", expected)
+	}
+}